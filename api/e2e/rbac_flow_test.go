@@ -111,7 +111,7 @@ func TestE2E_RBACFlow(t *testing.T) {
 	roleUUID, _ := uuid.Parse(roleID)
 
 	// Get role service
-	roleService := role.NewService(roleRepo, permissionRepo)
+	roleService := role.NewService(roleRepo, permissionRepo, nil, nil)
 	err = roleService.AssignPermissionToRole(context.Background(), roleUUID, permissionUUID)
 	require.NoError(t, err)
 
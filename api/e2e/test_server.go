@@ -38,7 +38,7 @@ func setupTestServerWithAuth(db *sql.DB, cacheClient *cache.Cache, loginService
 	// Setup services
 	userService := user.NewService(postgres.NewUserRepository(db), postgres.NewCredentialRepository(db), postgres.NewRefreshTokenRepository(db))
 	tenantService := tenant.NewService(tenantRepo)
-	roleService := role.NewService(roleRepo, permissionRepo)
+	roleService := role.NewService(roleRepo, permissionRepo, nil, nil)
 	permissionService := permission.NewService(permissionRepo)
 
 	// Setup handlers
@@ -125,7 +125,7 @@ func setupTestServer(db *sql.DB, cacheClient *cache.Cache) (*httptest.Server, *g
 	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
 	userService := user.NewService(userRepo, credentialRepo, refreshTokenRepo)
 	tenantService := tenant.NewService(tenantRepo)
-	roleService := role.NewService(roleRepo, permissionRepo)
+	roleService := role.NewService(roleRepo, permissionRepo, nil, nil)
 	permissionService := permission.NewService(permissionRepo)
 
 	// Setup handlers
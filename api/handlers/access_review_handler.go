@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/auth/claims"
+	"github.com/arauth-identity/iam/identity/accessreview"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AccessReviewHandler handles access review campaign HTTP requests
+type AccessReviewHandler struct {
+	reviewService accessreview.ServiceInterface
+	auditService  audit.ServiceInterface
+}
+
+// NewAccessReviewHandler creates a new access review handler
+func NewAccessReviewHandler(reviewService accessreview.ServiceInterface, auditService audit.ServiceInterface) *AccessReviewHandler {
+	return &AccessReviewHandler{
+		reviewService: reviewService,
+		auditService:  auditService,
+	}
+}
+
+// Create handles POST /api/v1/access-reviews
+func (h *AccessReviewHandler) Create(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	var req accessreview.CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+	req.TenantID = tenantID
+	req.CreatedBy = &actor.UserID
+
+	campaign, items, err := h.reviewService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondWithAccessReviewError(c, "create_failed", err)
+		return
+	}
+
+	h.logAccessReviewEvent(c, actor, models.EventTypeAccessReviewCreated, campaign, map[string]interface{}{
+		"scope_type":    campaign.ScopeType,
+		"reviewer_type": campaign.ReviewerType,
+		"item_count":    len(items),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"campaign":   campaign,
+		"item_count": len(items),
+	})
+}
+
+// List handles GET /api/v1/access-reviews
+func (h *AccessReviewHandler) List(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var status *string
+	if s := c.Query("status"); s != "" {
+		status = &s
+	}
+
+	campaigns, err := h.reviewService.List(c.Request.Context(), tenantID, status)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if campaigns == nil {
+		campaigns = []*models.AccessReviewCampaign{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"campaigns": campaigns,
+		"count":     len(campaigns),
+	})
+}
+
+// GetByID handles GET /api/v1/access-reviews/:id
+func (h *AccessReviewHandler) GetByID(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := accessReviewIDParam(c)
+	if !ok {
+		return
+	}
+
+	campaign, err := h.reviewService.GetByID(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithAccessReviewError(c, "get_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// ListItems handles GET /api/v1/access-reviews/:id/items
+func (h *AccessReviewHandler) ListItems(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := accessReviewIDParam(c)
+	if !ok {
+		return
+	}
+
+	filters := &interfaces.AccessReviewItemFilters{}
+	if decision := c.Query("decision"); decision != "" {
+		filters.Decision = &decision
+	}
+	if reviewerIDStr := c.Query("reviewer_id"); reviewerIDStr != "" {
+		reviewerID, err := uuid.Parse(reviewerIDStr)
+		if err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_reviewer_id",
+				"Invalid reviewer ID format", nil)
+			return
+		}
+		filters.ReviewerID = &reviewerID
+	}
+
+	items, err := h.reviewService.ListItems(c.Request.Context(), tenantID, id, filters)
+	if err != nil {
+		respondWithAccessReviewError(c, "list_failed", err)
+		return
+	}
+	respondWithAccessReviewItems(c, items)
+}
+
+// MyItems handles GET /api/v1/access-reviews/my-items.
+// Returns the undecided items waiting on the caller in active campaigns.
+func (h *AccessReviewHandler) MyItems(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	items, err := h.reviewService.ListReviewerItems(c.Request.Context(), tenantID, actor.UserID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	respondWithAccessReviewItems(c, items)
+}
+
+// Decide handles POST /api/v1/access-reviews/items/:item_id/decision.
+// The assigned reviewer decides; holders of access_reviews:manage may decide any item.
+func (h *AccessReviewHandler) Decide(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_item_id",
+			"Invalid access review item ID format", nil)
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	var req accessreview.DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	item, err := h.reviewService.Decide(c.Request.Context(), tenantID, itemID, actor.UserID, canManageAccessReviews(c), &req)
+	if err != nil {
+		respondWithAccessReviewError(c, "decision_failed", err)
+		return
+	}
+
+	metadata := map[string]interface{}{"decision": req.Decision}
+	if req.Comment != nil {
+		metadata["comment"] = *req.Comment
+	}
+	h.logAccessReviewItemEvent(c, actor, models.EventTypeAccessReviewDecided, item, metadata)
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Close handles POST /api/v1/access-reviews/:id/close.
+// Revocations are applied and each one is recorded in the audit log.
+func (h *AccessReviewHandler) Close(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := accessReviewIDParam(c)
+	if !ok {
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	result, err := h.reviewService.Close(c.Request.Context(), tenantID, id, actor.UserID)
+	if err != nil {
+		respondWithAccessReviewError(c, "close_failed", err)
+		return
+	}
+
+	for _, item := range result.Revoked {
+		h.logAccessReviewItemEvent(c, actor, models.EventTypeAccessReviewRevoked, item, map[string]interface{}{
+			"access": item.Access,
+		})
+	}
+	h.logAccessReviewEvent(c, actor, models.EventTypeAccessReviewClosed, result.Campaign, map[string]interface{}{
+		"revoked_count": len(result.Revoked),
+		"failed_count":  len(result.Failed),
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Cancel handles POST /api/v1/access-reviews/:id/cancel
+func (h *AccessReviewHandler) Cancel(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := accessReviewIDParam(c)
+	if !ok {
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	campaign, err := h.reviewService.Cancel(c.Request.Context(), tenantID, id, actor.UserID)
+	if err != nil {
+		respondWithAccessReviewError(c, "cancel_failed", err)
+		return
+	}
+
+	h.logAccessReviewEvent(c, actor, models.EventTypeAccessReviewCancelled, campaign, nil)
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// Remind handles POST /api/v1/access-reviews/:id/remind
+func (h *AccessReviewHandler) Remind(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := accessReviewIDParam(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.reviewService.SendReminders(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithAccessReviewError(c, "remind_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reviewers_reminded": count})
+}
+
+// Export handles GET /api/v1/access-reviews/:id/report?format=json|csv.
+// The SHA-256 of the body and a JWS over it are returned in headers so the file
+// itself stays unchanged.
+func (h *AccessReviewHandler) Export(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := accessReviewIDParam(c)
+	if !ok {
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	format := c.DefaultQuery("format", accessreview.ReportFormatJSON)
+	report, err := h.reviewService.ExportReport(c.Request.Context(), tenantID, id, format)
+	if err != nil {
+		respondWithAccessReviewError(c, "export_failed", err)
+		return
+	}
+
+	campaign := &models.AccessReviewCampaign{ID: id, TenantID: tenantID}
+	h.logAccessReviewEvent(c, actor, models.EventTypeAccessReviewExported, campaign, map[string]interface{}{
+		"format":        format,
+		"report_sha256": report.SHA256,
+	})
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=access-review-%s.%s", id, format))
+	c.Header("X-Report-SHA256", report.SHA256)
+	if report.Signature != "" {
+		c.Header("X-Report-Signature", report.Signature)
+	}
+	c.Data(http.StatusOK, report.ContentType, report.Body)
+}
+
+// accessReviewIDParam parses the :id path parameter.
+// It writes the error response and returns false when the ID is malformed.
+func accessReviewIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid access review ID format", nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func respondWithAccessReviewItems(c *gin.Context, items []*models.AccessReviewItem) {
+	if items == nil {
+		items = []*models.AccessReviewItem{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items": items,
+		"count": len(items),
+	})
+}
+
+// canManageAccessReviews reports whether the caller holds access_reviews:manage
+func canManageAccessReviews(c *gin.Context) bool {
+	claimsObj, exists := c.Get("user_claims")
+	if !exists {
+		return false
+	}
+	userClaims, ok := claimsObj.(*claims.Claims)
+	return ok && userClaims.HasPermission("access_reviews", "manage")
+}
+
+// respondWithAccessReviewError maps access review service errors to HTTP statuses
+func respondWithAccessReviewError(c *gin.Context, code string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", msg, nil)
+	case strings.Contains(msg, "not the reviewer"), strings.Contains(msg, "cannot review your own"):
+		middleware.RespondWithError(c, http.StatusForbidden, "access_denied", msg, nil)
+	case strings.Contains(msg, "not active"):
+		middleware.RespondWithError(c, http.StatusConflict, "access_review_conflict", msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusBadRequest, code, msg, nil)
+	}
+}
+
+// logAccessReviewEvent records an audit event about a campaign
+func (h *AccessReviewHandler) logAccessReviewEvent(c *gin.Context, actor models.AuditActor, eventType string, campaign *models.AccessReviewCampaign, metadata map[string]interface{}) {
+	h.logEvent(c, actor, eventType, campaign.TenantID, &models.AuditTarget{
+		Type:       "access_review",
+		ID:         campaign.ID,
+		Identifier: campaign.Name,
+	}, metadata)
+}
+
+// logAccessReviewItemEvent records an audit event about an item. The target is
+// the user or OAuth client whose access was reviewed.
+func (h *AccessReviewHandler) logAccessReviewItemEvent(c *gin.Context, actor models.AuditActor, eventType string, item *models.AccessReviewItem, metadata map[string]interface{}) {
+	target := &models.AuditTarget{Type: "user", Identifier: item.Subject}
+	if item.UserID != nil {
+		target.ID = *item.UserID
+	}
+	if item.ClientID != nil {
+		target.Type = "oauth_client"
+		target.ID = *item.ClientID
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["access_review_id"] = item.CampaignID.String()
+	metadata["item_id"] = item.ID.String()
+	if item.RoleID != nil {
+		metadata["role_id"] = item.RoleID.String()
+	}
+	h.logEvent(c, actor, eventType, item.TenantID, target, metadata)
+}
+
+func (h *AccessReviewHandler) logEvent(c *gin.Context, actor models.AuditActor, eventType string, tenantID uuid.UUID, target *models.AuditTarget, metadata map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target:    target,
+		TenantID:  &tenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata:  metadata,
+		Result:    models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
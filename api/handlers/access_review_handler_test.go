@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/accessreview"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccessReviewService is a mock implementation of accessreview.ServiceInterface
+type MockAccessReviewService struct {
+	mock.Mock
+}
+
+func (m *MockAccessReviewService) Create(ctx context.Context, req *accessreview.CreateCampaignRequest) (*models.AccessReviewCampaign, []*models.AccessReviewItem, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	items, _ := args.Get(1).([]*models.AccessReviewItem)
+	return args.Get(0).(*models.AccessReviewCampaign), items, args.Error(2)
+}
+
+func (m *MockAccessReviewService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.AccessReviewCampaign, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AccessReviewCampaign), args.Error(1)
+}
+
+func (m *MockAccessReviewService) List(ctx context.Context, tenantID uuid.UUID, status *string) ([]*models.AccessReviewCampaign, error) {
+	args := m.Called(ctx, tenantID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AccessReviewCampaign), args.Error(1)
+}
+
+func (m *MockAccessReviewService) ListItems(ctx context.Context, tenantID, id uuid.UUID, filters *interfaces.AccessReviewItemFilters) ([]*models.AccessReviewItem, error) {
+	args := m.Called(ctx, tenantID, id, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AccessReviewItem), args.Error(1)
+}
+
+func (m *MockAccessReviewService) ListReviewerItems(ctx context.Context, tenantID, reviewerID uuid.UUID) ([]*models.AccessReviewItem, error) {
+	args := m.Called(ctx, tenantID, reviewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AccessReviewItem), args.Error(1)
+}
+
+func (m *MockAccessReviewService) Decide(ctx context.Context, tenantID, itemID, actorID uuid.UUID, canManage bool, req *accessreview.DecisionRequest) (*models.AccessReviewItem, error) {
+	args := m.Called(ctx, tenantID, itemID, actorID, canManage, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AccessReviewItem), args.Error(1)
+}
+
+func (m *MockAccessReviewService) Close(ctx context.Context, tenantID, id, actorID uuid.UUID) (*accessreview.CloseResult, error) {
+	args := m.Called(ctx, tenantID, id, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*accessreview.CloseResult), args.Error(1)
+}
+
+func (m *MockAccessReviewService) Cancel(ctx context.Context, tenantID, id, actorID uuid.UUID) (*models.AccessReviewCampaign, error) {
+	args := m.Called(ctx, tenantID, id, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AccessReviewCampaign), args.Error(1)
+}
+
+func (m *MockAccessReviewService) ExportReport(ctx context.Context, tenantID, id uuid.UUID, format string) (*accessreview.Report, error) {
+	args := m.Called(ctx, tenantID, id, format)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*accessreview.Report), args.Error(1)
+}
+
+func (m *MockAccessReviewService) SendReminders(ctx context.Context, tenantID, id uuid.UUID) (int, error) {
+	args := m.Called(ctx, tenantID, id)
+	return args.Int(0), args.Error(1)
+}
+
+func TestAccessReviewHandler_Create(t *testing.T) {
+	mockService := new(MockAccessReviewService)
+	handler := NewAccessReviewHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	router := newClaimsTestRouter(tenantID, userID, "access_reviews:manage")
+	router.POST("/api/v1/access-reviews", handler.Create)
+
+	created := &models.AccessReviewCampaign{ID: uuid.New(), TenantID: tenantID, Name: "Q3", Status: models.AccessReviewStatusActive}
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(req *accessreview.CreateCampaignRequest) bool {
+		return req.TenantID == tenantID && *req.CreatedBy == userID && req.ScopeType == models.AccessReviewScopeRole
+	})).Return(created, []*models.AccessReviewItem{{}, {}}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":          "Q3",
+		"scope_type":    "role",
+		"reviewer_type": "role_owner",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/access-reviews", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"item_count":2`)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccessReviewHandler_Create_InvalidScope(t *testing.T) {
+	mockService := new(MockAccessReviewService)
+	handler := NewAccessReviewHandler(mockService, nil)
+
+	router := newClaimsTestRouter(uuid.New(), uuid.New())
+	router.POST("/api/v1/access-reviews", handler.Create)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":          "Q3",
+		"scope_type":    "group",
+		"reviewer_type": "role_owner",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/access-reviews", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Create")
+}
+
+func TestAccessReviewHandler_Decide_NotReviewer(t *testing.T) {
+	mockService := new(MockAccessReviewService)
+	handler := NewAccessReviewHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	itemID := uuid.New()
+	router := newClaimsTestRouter(tenantID, userID)
+	router.POST("/api/v1/access-reviews/items/:item_id/decision", handler.Decide)
+
+	mockService.On("Decide", mock.Anything, tenantID, itemID, userID, false, mock.MatchedBy(func(req *accessreview.DecisionRequest) bool {
+		return req.Decision == models.AccessReviewDecisionRevoke
+	})).Return(nil, fmt.Errorf("not the reviewer for this access review item"))
+
+	body, _ := json.Marshal(map[string]string{"decision": "revoke"})
+	req, _ := http.NewRequest("POST", "/api/v1/access-reviews/items/"+itemID.String()+"/decision", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccessReviewHandler_Decide_Manager(t *testing.T) {
+	mockService := new(MockAccessReviewService)
+	handler := NewAccessReviewHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	itemID := uuid.New()
+	router := newClaimsTestRouter(tenantID, userID, "access_reviews:manage")
+	router.POST("/api/v1/access-reviews/items/:item_id/decision", handler.Decide)
+
+	decision := models.AccessReviewDecisionKeep
+	mockService.On("Decide", mock.Anything, tenantID, itemID, userID, true, mock.Anything).
+		Return(&models.AccessReviewItem{ID: itemID, TenantID: tenantID, Decision: &decision}, nil)
+
+	body, _ := json.Marshal(map[string]string{"decision": "keep", "comment": "still on call"})
+	req, _ := http.NewRequest("POST", "/api/v1/access-reviews/items/"+itemID.String()+"/decision", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccessReviewHandler_Close_NotActive(t *testing.T) {
+	mockService := new(MockAccessReviewService)
+	handler := NewAccessReviewHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	id := uuid.New()
+	router := newClaimsTestRouter(tenantID, userID, "access_reviews:manage")
+	router.POST("/api/v1/access-reviews/:id/close", handler.Close)
+
+	mockService.On("Close", mock.Anything, tenantID, id, userID).Return(nil, fmt.Errorf("access review is not active"))
+
+	req, _ := http.NewRequest("POST", "/api/v1/access-reviews/"+id.String()+"/close", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccessReviewHandler_Export(t *testing.T) {
+	mockService := new(MockAccessReviewService)
+	handler := NewAccessReviewHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	id := uuid.New()
+	router := newClaimsTestRouter(tenantID, uuid.New(), "access_reviews:read")
+	router.GET("/api/v1/access-reviews/:id/report", handler.Export)
+
+	mockService.On("ExportReport", mock.Anything, tenantID, id, "csv").Return(&accessreview.Report{
+		Body:        []byte("item_id\n"),
+		ContentType: "text/csv",
+		SHA256:      "abc123",
+		Signature:   "header.payload.sig",
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/access-reviews/"+id.String()+"/report?format=csv", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "abc123", w.Header().Get("X-Report-SHA256"))
+	assert.Equal(t, "header.payload.sig", w.Header().Get("X-Report-Signature"))
+	assert.Equal(t, "item_id\n", w.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/arauth-identity/iam/auth/claims"
 	"github.com/arauth-identity/iam/identity/audit"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/auditchain"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -17,13 +18,15 @@ import (
 
 // AuditHandler handles audit event-related HTTP requests
 type AuditHandler struct {
-	auditService audit.ServiceInterface
+	auditService  audit.ServiceInterface
+	chainVerifier audit.ChainVerifier
 }
 
 // NewAuditHandler creates a new audit handler
-func NewAuditHandler(auditService audit.ServiceInterface) *AuditHandler {
+func NewAuditHandler(auditService audit.ServiceInterface, chainVerifier audit.ChainVerifier) *AuditHandler {
 	return &AuditHandler{
-		auditService: auditService,
+		auditService:  auditService,
+		chainVerifier: chainVerifier,
 	}
 }
 
@@ -157,7 +160,7 @@ func (h *AuditHandler) GetEvent(c *gin.Context) {
 	c.JSON(http.StatusOK, event)
 }
 
-// ExportEvents handles GET /api/v1/audit/export
+// ExportEvents handles GET /api/v1/audit/export?format=csv|json
 func (h *AuditHandler) ExportEvents(c *gin.Context) {
 	// 1. Get Filters (Reuse extraction logic or refactor, currently duplicating slightly for clarity)
 	var tenantID *uuid.UUID
@@ -199,16 +202,75 @@ func (h *AuditHandler) ExportEvents(c *gin.Context) {
 		}
 	}
 
+	format := c.DefaultQuery("format", audit.ExportFormatCSV)
+	contentType := "text/csv"
+	switch format {
+	case audit.ExportFormatCSV:
+	case audit.ExportFormatJSON:
+		contentType = "application/json"
+	default:
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_format",
+			"Export format must be csv or json", nil)
+		return
+	}
+
 	// 2. Call Service
-	data, filename, err := h.auditService.ExportEvents(c.Request.Context(), filters)
+	data, filename, err := h.auditService.ExportEvents(c.Request.Context(), filters, format)
 	if err != nil {
 		middleware.RespondWithError(c, http.StatusInternalServerError, "export_failed", fmt.Sprintf("Failed to export audit events: %v", err), nil)
 		return
 	}
 
-	// 3. Return CSV
+	// 3. Return the export
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	c.Data(http.StatusOK, "text/csv", data)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// VerifyChains handles GET /system/audit/verify.
+// It checks the tenant_id chain ("system" for system events), or every
+// chain when no tenant is given. Tampering is reported in the body with
+// valid set to false, not as an error status.
+func (h *AuditHandler) VerifyChains(c *gin.Context) {
+	ctx := c.Request.Context()
+	var reports []*auditchain.Report
+
+	switch tenantParam := c.Query("tenant_id"); tenantParam {
+	case "":
+		all, err := h.chainVerifier.VerifyAll(ctx)
+		if err != nil {
+			middleware.RespondWithError(c, http.StatusInternalServerError, "verification_failed", err.Error(), nil)
+			return
+		}
+		reports = all
+	default:
+		var tenantID *uuid.UUID
+		if tenantParam != "system" {
+			id, err := uuid.Parse(tenantParam)
+			if err != nil {
+				middleware.RespondWithError(c, http.StatusBadRequest, "invalid_tenant_id",
+					"tenant_id must be a tenant ID or \"system\"", nil)
+				return
+			}
+			tenantID = &id
+		}
+		report, err := h.chainVerifier.Verify(ctx, tenantID)
+		if err != nil {
+			middleware.RespondWithError(c, http.StatusInternalServerError, "verification_failed", err.Error(), nil)
+			return
+		}
+		reports = []*auditchain.Report{report}
+	}
+
+	valid := true
+	for _, report := range reports {
+		valid = valid && report.Valid
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":       valid,
+		"chains":      reports,
+		"verified_at": time.Now().UTC(),
+	})
 }
 
 // extractActorFromContext extracts actor information from Gin context
@@ -244,13 +306,9 @@ func extractActorFromContext(c *gin.Context) (models.AuditActor, error) {
 
 // extractSourceInfo extracts source IP and user agent from request
 func extractSourceInfo(c *gin.Context) (sourceIP, userAgent string) {
-	// Get source IP
+	// Get source IP. ClientIP only honours X-Forwarded-For / X-Real-IP
+	// when the request came through a configured trusted proxy.
 	sourceIP = c.ClientIP()
-	if forwardedFor := c.GetHeader("X-Forwarded-For"); forwardedFor != "" {
-		sourceIP = forwardedFor
-	} else if realIP := c.GetHeader("X-Real-IP"); realIP != "" {
-		sourceIP = realIP
-	}
 
 	// Get user agent
 	userAgent = c.GetHeader("User-Agent")
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/arauth-identity/iam/internal/auditchain"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -25,7 +28,7 @@ func TestAuditHandler_ExportEvents(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockService := &MockAuditService{}
-		handler := NewAuditHandler(mockService)
+		handler := NewAuditHandler(mockService, nil)
 
 		router := gin.New()
 		router.Use(func(c *gin.Context) {
@@ -39,7 +42,7 @@ func TestAuditHandler_ExportEvents(t *testing.T) {
 
 		mockService.On("ExportEvents", mock.Anything, mock.MatchedBy(func(filters *interfaces.AuditEventFilters) bool {
 			return filters.TenantID != nil && *filters.TenantID == tenantID
-		})).Return(csvData, filename, nil)
+		}), "csv").Return(csvData, filename, nil)
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/api/v1/audit/export?event_type=login", nil)
@@ -53,7 +56,7 @@ func TestAuditHandler_ExportEvents(t *testing.T) {
 
 	t.Run("export_failure", func(t *testing.T) {
 		mockService := &MockAuditService{}
-		handler := NewAuditHandler(mockService)
+		handler := NewAuditHandler(mockService, nil)
 
 		router := gin.New()
 		router.Use(func(c *gin.Context) {
@@ -62,7 +65,7 @@ func TestAuditHandler_ExportEvents(t *testing.T) {
 		})
 		router.GET("/api/v1/audit/export", handler.ExportEvents)
 
-		mockService.On("ExportEvents", mock.Anything, mock.Anything).Return([]byte(nil), "", assert.AnError)
+		mockService.On("ExportEvents", mock.Anything, mock.Anything, mock.Anything).Return([]byte(nil), "", assert.AnError)
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/api/v1/audit/export", nil)
@@ -70,4 +73,123 @@ func TestAuditHandler_ExportEvents(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
+
+	t.Run("json", func(t *testing.T) {
+		mockService := &MockAuditService{}
+		handler := NewAuditHandler(mockService, nil)
+
+		router := gin.New()
+		router.GET("/api/v1/audit/export", handler.ExportEvents)
+
+		mockService.On("ExportEvents", mock.Anything, mock.Anything, "json").Return([]byte(`{"events":[]}`), "export.json", nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/audit/export?format=json", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "export.json")
+	})
+
+	t.Run("unknown_format", func(t *testing.T) {
+		mockService := &MockAuditService{}
+		handler := NewAuditHandler(mockService, nil)
+
+		router := gin.New()
+		router.GET("/api/v1/audit/export", handler.ExportEvents)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/audit/export?format=xml", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ExportEvents", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// mockChainVerifier returns canned verification reports
+type mockChainVerifier struct {
+	mock.Mock
+}
+
+func (m *mockChainVerifier) Verify(ctx context.Context, tenantID *uuid.UUID) (*auditchain.Report, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auditchain.Report), args.Error(1)
+}
+
+func (m *mockChainVerifier) VerifyAll(ctx context.Context) ([]*auditchain.Report, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*auditchain.Report), args.Error(1)
+}
+
+func TestAuditHandler_VerifyChains(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tenantID := uuid.New()
+
+	serve := func(verifier *mockChainVerifier, query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		handler := NewAuditHandler(&MockAuditService{}, verifier)
+		router := gin.New()
+		router.GET("/system/audit/verify", handler.VerifyChains)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/system/audit/verify"+query, nil)
+		router.ServeHTTP(w, req)
+
+		var body map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+
+	t.Run("all chains", func(t *testing.T) {
+		verifier := &mockChainVerifier{}
+		verifier.On("VerifyAll", mock.Anything).Return([]*auditchain.Report{
+			{Valid: true},
+			{TenantID: &tenantID, Valid: false, Problems: []auditchain.Problem{{Kind: auditchain.ProblemGap, Seq: 4}}},
+		}, nil)
+
+		w, body := serve(verifier, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, false, body["valid"])
+		assert.Len(t, body["chains"], 2)
+	})
+
+	t.Run("one tenant", func(t *testing.T) {
+		verifier := &mockChainVerifier{}
+		verifier.On("Verify", mock.Anything, mock.MatchedBy(func(id *uuid.UUID) bool {
+			return id != nil && *id == tenantID
+		})).Return(&auditchain.Report{TenantID: &tenantID, Valid: true}, nil)
+
+		w, body := serve(verifier, "?tenant_id="+tenantID.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, true, body["valid"])
+	})
+
+	t.Run("system chain", func(t *testing.T) {
+		verifier := &mockChainVerifier{}
+		verifier.On("Verify", mock.Anything, (*uuid.UUID)(nil)).Return(&auditchain.Report{Valid: true}, nil)
+
+		w, _ := serve(verifier, "?tenant_id=system")
+		assert.Equal(t, http.StatusOK, w.Code)
+		verifier.AssertExpectations(t)
+	})
+
+	t.Run("invalid tenant", func(t *testing.T) {
+		w, _ := serve(&mockChainVerifier{}, "?tenant_id=nope")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("verification error", func(t *testing.T) {
+		verifier := &mockChainVerifier{}
+		verifier.On("VerifyAll", mock.Anything).Return(nil, assert.AnError)
+
+		w, _ := serve(verifier, "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
 }
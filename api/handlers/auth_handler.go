@@ -69,6 +69,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// For SYSTEM users, tenant_id will remain uuid.Nil
 	// Login service will handle SYSTEM users (no tenant_id required)
 
+	// Client metadata is recorded on the issued session, never taken from the body
+	req.IPAddress, req.UserAgent = extractSourceInfo(c)
+
 	resp, err := h.loginService.Login(c.Request.Context(), &req)
 	if err != nil {
 		// Log login failure
@@ -190,7 +193,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.refreshService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	resp, err := h.refreshService.RefreshToken(c.Request.Context(), req.RefreshToken, clientInfoFromContext(c))
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "token_refresh_failed",
@@ -229,18 +232,33 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 }
 
 // RevokeToken handles POST /api/v1/auth/revoke
+// This is the end-user logout endpoint; OAuth clients should use the
+// client-authenticated RFC 7009 endpoint at /api/v1/oauth/revoke instead.
 func (h *AuthHandler) RevokeToken(c *gin.Context) {
 	var req struct {
-		Token     string `json:"token"`
-		TokenType string `json:"token_type_hint,omitempty"` // "access_token" or "refresh_token"
+		Token     string `json:"token" form:"token"`
+		TokenType string `json:"token_type_hint,omitempty" form:"token_type_hint"` // "access_token" or "refresh_token"
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	// Support both JSON and form-encoded requests (RFC 7009 uses form encoding)
+	var err error
+	if c.ContentType() == "application/x-www-form-urlencoded" {
+		err = c.ShouldBind(&req)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
 		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
 			"Request validation failed", middleware.FormatValidationErrors(err))
 		return
 	}
 
+	if req.TokenType != "" && req.TokenType != "access_token" && req.TokenType != "refresh_token" {
+		middleware.RespondWithError(c, http.StatusBadRequest, "unsupported_token_type",
+			"token_type_hint must be access_token or refresh_token", nil)
+		return
+	}
+
 	// Determine token type
 	if req.TokenType == "refresh_token" || req.TokenType == "" {
 		// Try to revoke as refresh token
@@ -327,3 +345,9 @@ func (h *AuthHandler) RevokeToken(c *gin.Context) {
 		"message": "Token revoked successfully",
 	})
 }
+
+// clientInfoFromContext returns the device/session metadata of the calling client
+func clientInfoFromContext(c *gin.Context) *token.ClientInfo {
+	sourceIP, userAgent := extractSourceInfo(c)
+	return &token.ClientInfo{IPAddress: sourceIP, UserAgent: userAgent}
+}
@@ -218,7 +218,7 @@ func (m *MockAuditService) LogMFADisabled(ctx context.Context, actor models.Audi
 func (m *MockAuditService) LogMFAEnrolled(ctx context.Context, actor models.AuditActor, tenantID *uuid.UUID, ip, userAgent string) error {
 	return nil
 }
-func (m *MockAuditService) LogMFAReset(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, ip, userAgent string) error {
+func (m *MockAuditService) LogMFAReset(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, ip, userAgent string, metadata map[string]interface{}) error {
 	return nil
 }
 
@@ -306,8 +306,8 @@ func (m *MockAuditService) QueryEvents(ctx context.Context, filters *interfaces.
 	return args.Get(0).([]*models.AuditEvent), args.Int(1), args.Error(2)
 }
 
-func (m *MockAuditService) ExportEvents(ctx context.Context, filters *interfaces.AuditEventFilters) ([]byte, string, error) {
-	args := m.Called(ctx, filters)
+func (m *MockAuditService) ExportEvents(ctx context.Context, filters *interfaces.AuditEventFilters, format string) ([]byte, string, error) {
+	args := m.Called(ctx, filters, format)
 	return args.Get(0).([]byte), args.String(1), args.Error(2)
 }
 
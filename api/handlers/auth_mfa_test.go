@@ -65,6 +65,42 @@ func (m *MockAuthMFAService) CreateSession(ctx context.Context, userID, tenantID
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockAuthMFAService) GetStatus(ctx context.Context, userID uuid.UUID) (*mfa.Status, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mfa.Status), args.Error(1)
+}
+
+func (m *MockAuthMFAService) HasVerifiedFactor(ctx context.Context, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthMFAService) RemoveFactor(ctx context.Context, req *mfa.RemoveFactorRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthMFAService) Reset(ctx context.Context, userID uuid.UUID) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthMFAService) Disable(ctx context.Context, req *mfa.VerifyRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthMFAService) RegenerateRecoveryCodes(ctx context.Context, req *mfa.VerifyRequest) ([]string, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 // MockAuthAuditService satisfies audit.ServiceInterface
 type MockAuthAuditService struct {
 	mock.Mock
@@ -128,8 +164,8 @@ func (m *MockAuthAuditService) LogPermissionDeleted(ctx context.Context, actor m
 	return nil
 }
 
-func (m *MockAuthAuditService) ExportEvents(ctx context.Context, filters *interfaces.AuditEventFilters) ([]byte, string, error) {
-	args := m.Called(ctx, filters)
+func (m *MockAuthAuditService) ExportEvents(ctx context.Context, filters *interfaces.AuditEventFilters, format string) ([]byte, string, error) {
+	args := m.Called(ctx, filters, format)
 	return args.Get(0).([]byte), args.String(1), args.Error(2)
 }
 func (m *MockAuthAuditService) LogMFAEnrolled(ctx context.Context, actor models.AuditActor, tenantID *uuid.UUID, sourceIP, userAgent string) error {
@@ -141,7 +177,7 @@ func (m *MockAuthAuditService) LogMFAVerified(ctx context.Context, actor models.
 func (m *MockAuthAuditService) LogMFADisabled(ctx context.Context, actor models.AuditActor, tenantID *uuid.UUID, sourceIP, userAgent string) error {
 	return nil
 }
-func (m *MockAuthAuditService) LogMFAReset(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, sourceIP, userAgent string) error {
+func (m *MockAuthAuditService) LogMFAReset(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, sourceIP, userAgent string, metadata map[string]interface{}) error {
 	return nil
 }
 func (m *MockAuthAuditService) LogTenantCreated(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, sourceIP, userAgent string) error {
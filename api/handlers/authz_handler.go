@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/authz"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AuthzHandler answers authorization decision requests from downstream services
+type AuthzHandler struct {
+	authzService authz.ServiceInterface
+}
+
+// NewAuthzHandler creates a new authorization decision handler
+func NewAuthzHandler(authzService authz.ServiceInterface) *AuthzHandler {
+	return &AuthzHandler{authzService: authzService}
+}
+
+// BatchCheckRequest represents a batch of authorization checks
+type BatchCheckRequest struct {
+	Checks []*authz.CheckRequest `json:"checks" binding:"required"`
+}
+
+// Check handles POST /api/v1/authz/check
+func (h *AuthzHandler) Check(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req authz.CheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Invalid request body", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	if !checkTenantMatches(c, tenantID, req.TenantID) {
+		return
+	}
+
+	decision, err := h.authzService.Check(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		respondWithAuthzError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// CheckBatch handles POST /api/v1/authz/check/batch
+func (h *AuthzHandler) CheckBatch(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req BatchCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Invalid request body", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	for _, check := range req.Checks {
+		if check != nil && !checkTenantMatches(c, tenantID, check.TenantID) {
+			return
+		}
+	}
+
+	decisions, err := h.authzService.CheckBatch(c.Request.Context(), tenantID, req.Checks)
+	if err != nil {
+		respondWithAuthzError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": decisions,
+		"count":   len(decisions),
+	})
+}
+
+// Explain handles POST /api/v1/authz/explain.
+// It takes the same body as Check and returns the evaluation trace with the decision.
+func (h *AuthzHandler) Explain(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req authz.CheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Invalid request body", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	if !checkTenantMatches(c, tenantID, req.TenantID) {
+		return
+	}
+
+	explanation, err := h.authzService.Explain(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		respondWithAuthzError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+// WhatIf handles POST /api/v1/authz/what-if.
+// The changes are only simulated; nothing is written.
+func (h *AuthzHandler) WhatIf(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req authz.WhatIfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Invalid request body", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	result, err := h.authzService.WhatIf(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		respondWithAuthzError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// checkTenantMatches rejects checks for a tenant other than the caller's
+func checkTenantMatches(c *gin.Context, tenantID uuid.UUID, requested *uuid.UUID) bool {
+	if requested != nil && *requested != tenantID {
+		middleware.RespondWithError(c, http.StatusForbidden, "tenant_mismatch",
+			"Authorization checks can only be made for the caller's tenant", nil)
+		return false
+	}
+	return true
+}
+
+// respondWithAuthzError maps authorization service errors to HTTP responses
+func respondWithAuthzError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "required"),
+		strings.Contains(msg, "subject must have"),
+		strings.Contains(msg, "at most"),
+		strings.Contains(msg, "unknown role"),
+		strings.Contains(msg, "invalid permission"):
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request", msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusInternalServerError, "authz_error",
+			"Failed to evaluate authorization request", nil)
+	}
+}
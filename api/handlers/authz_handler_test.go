@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/authz"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuthzService is a mock implementation of authz.ServiceInterface
+type MockAuthzService struct {
+	mock.Mock
+}
+
+func (m *MockAuthzService) Check(ctx context.Context, tenantID uuid.UUID, req *authz.CheckRequest) (*authz.Decision, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authz.Decision), args.Error(1)
+}
+
+func (m *MockAuthzService) CheckBatch(ctx context.Context, tenantID uuid.UUID, reqs []*authz.CheckRequest) ([]*authz.Decision, error) {
+	args := m.Called(ctx, tenantID, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*authz.Decision), args.Error(1)
+}
+
+func (m *MockAuthzService) Explain(ctx context.Context, tenantID uuid.UUID, req *authz.CheckRequest) (*authz.Explanation, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authz.Explanation), args.Error(1)
+}
+
+func (m *MockAuthzService) WhatIf(ctx context.Context, tenantID uuid.UUID, req *authz.WhatIfRequest) (*authz.WhatIfResult, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authz.WhatIfResult), args.Error(1)
+}
+
+func (m *MockAuthzService) InvalidateUser(ctx context.Context, tenantID, userID uuid.UUID) error {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthzService) InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	args := m.Called(ctx, tenantID)
+	return args.Error(0)
+}
+
+func setupAuthzRouter(handler *AuthzHandler, tenantID uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Next()
+	})
+	router.POST("/authz/check", handler.Check)
+	router.POST("/authz/check/batch", handler.CheckBatch)
+	router.POST("/authz/explain", handler.Explain)
+	router.POST("/authz/what-if", handler.WhatIf)
+	return router
+}
+
+func postJSON(router *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthzHandler_Check(t *testing.T) {
+	mockService := new(MockAuthzService)
+	handler := NewAuthzHandler(mockService)
+	tenantID, userID := uuid.New(), uuid.New()
+
+	mockService.On("Check", mock.Anything, tenantID, mock.MatchedBy(func(req *authz.CheckRequest) bool {
+		return *req.Subject.UserID == userID && req.Resource == "documents" && req.Action == "read"
+	})).Return(&authz.Decision{Allowed: true, Reason: authz.ReasonPermissionGranted, MatchedRole: "editor", MatchedPermission: "documents:read"}, nil)
+
+	w := postJSON(setupAuthzRouter(handler, tenantID), "/authz/check", map[string]interface{}{
+		"subject":  map[string]string{"user_id": userID.String()},
+		"resource": "documents",
+		"action":   "read",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, true, response["allowed"])
+	assert.Equal(t, "editor", response["matched_role"])
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthzHandler_Check_OtherTenant(t *testing.T) {
+	mockService := new(MockAuthzService)
+	handler := NewAuthzHandler(mockService)
+
+	w := postJSON(setupAuthzRouter(handler, uuid.New()), "/authz/check", map[string]interface{}{
+		"subject":   map[string]string{"user_id": uuid.New().String()},
+		"tenant_id": uuid.New().String(),
+		"resource":  "documents",
+		"action":    "read",
+	})
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "tenant_mismatch")
+	mockService.AssertNotCalled(t, "Check", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthzHandler_CheckBatch_InvalidRequest(t *testing.T) {
+	mockService := new(MockAuthzService)
+	handler := NewAuthzHandler(mockService)
+	tenantID := uuid.New()
+
+	mockService.On("CheckBatch", mock.Anything, tenantID, mock.Anything).
+		Return(nil, errors.New("check 0: resource and action are required"))
+
+	w := postJSON(setupAuthzRouter(handler, tenantID), "/authz/check/batch", map[string]interface{}{
+		"checks": []map[string]interface{}{{"subject": map[string]string{"token": "abc"}}},
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "resource and action are required")
+}
+
+func TestAuthzHandler_Explain(t *testing.T) {
+	mockService := new(MockAuthzService)
+	handler := NewAuthzHandler(mockService)
+	tenantID, userID := uuid.New(), uuid.New()
+
+	mockService.On("Explain", mock.Anything, tenantID, mock.MatchedBy(func(req *authz.CheckRequest) bool {
+		return *req.Subject.UserID == userID && req.Resource == "documents"
+	})).Return(&authz.Explanation{
+		Decision:           &authz.Decision{Allowed: false, Reason: authz.ReasonNoMatchingGrant},
+		RequiredPermission: "documents:delete",
+		Roles:              []authz.RoleTrace{{RoleName: "editor", Source: authz.RoleSourceDirect}},
+		Permissions:        []authz.PermissionTrace{},
+		DecidingRule:       "no considered role grants documents:delete",
+	}, nil)
+
+	w := postJSON(setupAuthzRouter(handler, tenantID), "/authz/explain", map[string]interface{}{
+		"subject":  map[string]string{"user_id": userID.String()},
+		"resource": "documents",
+		"action":   "delete",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "no considered role grants documents:delete", response["deciding_rule"])
+	assert.Len(t, response["roles"], 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthzHandler_WhatIf(t *testing.T) {
+	mockService := new(MockAuthzService)
+	handler := NewAuthzHandler(mockService)
+	tenantID, roleID := uuid.New(), uuid.New()
+
+	mockService.On("WhatIf", mock.Anything, tenantID, mock.MatchedBy(func(req *authz.WhatIfRequest) bool {
+		return len(req.RemoveRoles) == 1 && req.RemoveRoles[0] == roleID
+	})).Return(&authz.WhatIfResult{UsersEvaluated: 3, Changes: []*authz.AccessChange{
+		{UserID: uuid.New(), Username: "bob", Gained: []string{}, Lost: []string{"documents:read"}},
+	}}, nil)
+
+	w := postJSON(setupAuthzRouter(handler, tenantID), "/authz/what-if", map[string]interface{}{
+		"remove_roles": []string{roleID.String()},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"users_evaluated":3`)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthzHandler_WhatIf_UnknownRole(t *testing.T) {
+	mockService := new(MockAuthzService)
+	handler := NewAuthzHandler(mockService)
+	tenantID := uuid.New()
+
+	mockService.On("WhatIf", mock.Anything, tenantID, mock.Anything).
+		Return(nil, errors.New("unknown role 00000000-0000-0000-0000-000000000001"))
+
+	w := postJSON(setupAuthzRouter(handler, tenantID), "/authz/what-if", map[string]interface{}{
+		"remove_roles": []string{"00000000-0000-0000-0000-000000000001"},
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
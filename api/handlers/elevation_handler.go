@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/auth/claims"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/elevation"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ElevationHandler handles just-in-time role elevation HTTP requests
+type ElevationHandler struct {
+	elevationService elevation.ServiceInterface
+	auditService     audit.ServiceInterface
+}
+
+// NewElevationHandler creates a new elevation handler
+func NewElevationHandler(elevationService elevation.ServiceInterface, auditService audit.ServiceInterface) *ElevationHandler {
+	return &ElevationHandler{
+		elevationService: elevationService,
+		auditService:     auditService,
+	}
+}
+
+// Request handles POST /api/v1/roles/elevations
+func (h *ElevationHandler) Request(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	var req elevation.CreateElevationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	// Users always request for themselves
+	req.TenantID = tenantID
+	req.UserID = actor.UserID
+
+	created, err := h.elevationService.Request(c.Request.Context(), &req)
+	if err != nil {
+		respondWithElevationError(c, "request_failed", err)
+		return
+	}
+
+	h.logElevationEvent(c, actor, models.EventTypeRoleElevationRequested, created, map[string]interface{}{
+		"reason": created.Reason,
+	})
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// List handles GET /api/v1/roles/elevations.
+// Approvers see every request in the tenant; other users see their own.
+func (h *ElevationHandler) List(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	filters := &interfaces.ElevationRequestFilters{}
+	if status := c.Query("status"); status != "" {
+		filters.Status = &status
+	}
+	if roleIDStr := c.Query("role_id"); roleIDStr != "" {
+		roleID, err := uuid.Parse(roleIDStr)
+		if err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_role_id",
+				"Invalid role ID format", nil)
+			return
+		}
+		filters.RoleID = &roleID
+	}
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_user_id",
+				"Invalid user ID format", nil)
+			return
+		}
+		filters.UserID = &userID
+	}
+	if !canApproveElevations(c) {
+		filters.UserID = &actor.UserID
+	}
+
+	requests, err := h.elevationService.List(c.Request.Context(), tenantID, filters)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if requests == nil {
+		requests = []*models.ElevationRequest{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"elevations": requests,
+		"count":      len(requests),
+	})
+}
+
+// GetByID handles GET /api/v1/roles/elevations/:id
+func (h *ElevationHandler) GetByID(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	req, ok := h.elevationParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil || (req.UserID != actor.UserID && !canApproveElevations(c)) {
+		middleware.RespondWithError(c, http.StatusNotFound, "elevation_not_found",
+			"Elevation request not found", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// Approve handles POST /api/v1/roles/elevations/:id/approve
+func (h *ElevationHandler) Approve(c *gin.Context) {
+	h.decide(c, true)
+}
+
+// Deny handles POST /api/v1/roles/elevations/:id/deny
+func (h *ElevationHandler) Deny(c *gin.Context) {
+	h.decide(c, false)
+}
+
+// Cancel handles POST /api/v1/roles/elevations/:id/cancel
+func (h *ElevationHandler) Cancel(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid elevation request ID format", nil)
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	cancelled, err := h.elevationService.Cancel(c.Request.Context(), tenantID, id, actor.UserID)
+	if err != nil {
+		respondWithElevationError(c, "cancel_failed", err)
+		return
+	}
+
+	h.logElevationEvent(c, actor, models.EventTypeRoleElevationCancelled, cancelled, nil)
+
+	c.JSON(http.StatusOK, cancelled)
+}
+
+// decide approves or denies the request named by the :id path parameter
+func (h *ElevationHandler) decide(c *gin.Context, approve bool) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid elevation request ID format", nil)
+		return
+	}
+
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User claims not found", nil)
+		return
+	}
+
+	// Body is optional; it only carries the approver's comment
+	var body elevation.DecisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+				"Request validation failed", middleware.FormatValidationErrors(err))
+			return
+		}
+	}
+
+	var decided *models.ElevationRequest
+	eventType := models.EventTypeRoleElevationApproved
+	if approve {
+		decided, err = h.elevationService.Approve(c.Request.Context(), tenantID, id, actor.UserID, body.Comment)
+	} else {
+		eventType = models.EventTypeRoleElevationDenied
+		decided, err = h.elevationService.Deny(c.Request.Context(), tenantID, id, actor.UserID, body.Comment)
+	}
+	if err != nil {
+		respondWithElevationError(c, "decision_failed", err)
+		return
+	}
+
+	metadata := map[string]interface{}{}
+	if body.Comment != nil {
+		metadata["comment"] = *body.Comment
+	}
+	if decided.ExpiresAt != nil {
+		metadata["expires_at"] = decided.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	h.logElevationEvent(c, actor, eventType, decided, metadata)
+
+	c.JSON(http.StatusOK, decided)
+}
+
+// elevationParam loads the request named by the :id path parameter within the tenant.
+// It writes the error response and returns false when the request cannot be used.
+func (h *ElevationHandler) elevationParam(c *gin.Context, tenantID uuid.UUID) (*models.ElevationRequest, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid elevation request ID format", nil)
+		return nil, false
+	}
+
+	req, err := h.elevationService.GetByID(c.Request.Context(), tenantID, id)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusNotFound, "elevation_not_found",
+			"Elevation request not found", nil)
+		return nil, false
+	}
+
+	return req, true
+}
+
+// canApproveElevations reports whether the caller holds roles:approve
+func canApproveElevations(c *gin.Context) bool {
+	claimsObj, exists := c.Get("user_claims")
+	if !exists {
+		return false
+	}
+	userClaims, ok := claimsObj.(*claims.Claims)
+	return ok && userClaims.HasPermission("roles", "approve")
+}
+
+// respondWithElevationError maps elevation service errors to HTTP statuses
+func respondWithElevationError(c *gin.Context, code string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", msg, nil)
+	case strings.Contains(msg, "cannot decide your own"), strings.Contains(msg, "only the requester"):
+		middleware.RespondWithError(c, http.StatusForbidden, "access_denied", msg, nil)
+	case strings.Contains(msg, "separation of duties violation"):
+		middleware.RespondWithError(c, http.StatusConflict, "sod_violation", msg, nil)
+	case strings.Contains(msg, "pending"), strings.Contains(msg, "no longer"), strings.Contains(msg, "already holds"):
+		middleware.RespondWithError(c, http.StatusConflict, "elevation_conflict", msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusBadRequest, code, msg, nil)
+	}
+}
+
+// logElevationEvent records an audit event for a step in an elevation request's life.
+// The target is the user the role is (or would be) granted to.
+func (h *ElevationHandler) logElevationEvent(c *gin.Context, actor models.AuditActor, eventType string, req *models.ElevationRequest, metadata map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["elevation_request_id"] = req.ID.String()
+	metadata["role_id"] = req.RoleID.String()
+	metadata["duration_seconds"] = req.DurationSeconds
+
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target: &models.AuditTarget{
+			Type: "user",
+			ID:   req.UserID,
+		},
+		TenantID:  &req.TenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata:  metadata,
+		Result:    models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
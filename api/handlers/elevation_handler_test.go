@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/elevation"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockElevationService is a mock implementation of elevation.ServiceInterface
+type MockElevationService struct {
+	mock.Mock
+}
+
+func (m *MockElevationService) Request(ctx context.Context, req *elevation.CreateElevationRequest) (*models.ElevationRequest, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ElevationRequest), args.Error(1)
+}
+
+func (m *MockElevationService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.ElevationRequest, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ElevationRequest), args.Error(1)
+}
+
+func (m *MockElevationService) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.ElevationRequestFilters) ([]*models.ElevationRequest, error) {
+	args := m.Called(ctx, tenantID, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ElevationRequest), args.Error(1)
+}
+
+func (m *MockElevationService) Approve(ctx context.Context, tenantID, id, approverID uuid.UUID, comment *string) (*models.ElevationRequest, error) {
+	args := m.Called(ctx, tenantID, id, approverID, comment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ElevationRequest), args.Error(1)
+}
+
+func (m *MockElevationService) Deny(ctx context.Context, tenantID, id, approverID uuid.UUID, comment *string) (*models.ElevationRequest, error) {
+	args := m.Called(ctx, tenantID, id, approverID, comment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ElevationRequest), args.Error(1)
+}
+
+func (m *MockElevationService) Cancel(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.ElevationRequest, error) {
+	args := m.Called(ctx, tenantID, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ElevationRequest), args.Error(1)
+}
+
+func (m *MockElevationService) ExpireDue(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestElevationHandler_Request(t *testing.T) {
+	mockService := new(MockElevationService)
+	handler := NewElevationHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	roleID := uuid.New()
+
+	router := newClaimsTestRouter(tenantID, userID)
+	router.POST("/api/v1/roles/elevations", handler.Request)
+
+	created := &models.ElevationRequest{ID: uuid.New(), TenantID: tenantID, UserID: userID, RoleID: roleID, Status: models.ElevationStatusPending}
+	mockService.On("Request", mock.Anything, mock.MatchedBy(func(req *elevation.CreateElevationRequest) bool {
+		return req.TenantID == tenantID && req.UserID == userID && req.RoleID == roleID && req.DurationMinutes == 60
+	})).Return(created, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"role_id":          roleID,
+		"reason":           "deploy hotfix",
+		"duration_minutes": 60,
+		"user_id":          uuid.New(), // ignored: users request for themselves
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/roles/elevations", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestElevationHandler_List_OwnRequestsOnly(t *testing.T) {
+	mockService := new(MockElevationService)
+	handler := NewElevationHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+
+	router := newClaimsTestRouter(tenantID, userID)
+	router.GET("/api/v1/roles/elevations", handler.List)
+
+	mockService.On("List", mock.Anything, tenantID, mock.MatchedBy(func(f *interfaces.ElevationRequestFilters) bool {
+		return f.UserID != nil && *f.UserID == userID && *f.Status == models.ElevationStatusPending
+	})).Return(nil, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/roles/elevations?status=pending&user_id="+uuid.New().String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+	mockService.AssertExpectations(t)
+}
+
+func TestElevationHandler_List_Approver(t *testing.T) {
+	mockService := new(MockElevationService)
+	handler := NewElevationHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	router := newClaimsTestRouter(tenantID, uuid.New(), "roles:approve")
+	router.GET("/api/v1/roles/elevations", handler.List)
+
+	mockService.On("List", mock.Anything, tenantID, mock.MatchedBy(func(f *interfaces.ElevationRequestFilters) bool {
+		return f.UserID == nil
+	})).Return([]*models.ElevationRequest{{ID: uuid.New()}}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/roles/elevations", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+	mockService.AssertExpectations(t)
+}
+
+func TestElevationHandler_Approve_OwnRequest(t *testing.T) {
+	mockService := new(MockElevationService)
+	handler := NewElevationHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	id := uuid.New()
+
+	router := newClaimsTestRouter(tenantID, userID, "roles:approve")
+	router.POST("/api/v1/roles/elevations/:id/approve", handler.Approve)
+
+	mockService.On("Approve", mock.Anything, tenantID, id, userID, (*string)(nil)).
+		Return(nil, fmt.Errorf("cannot decide your own elevation request"))
+
+	req, _ := http.NewRequest("POST", "/api/v1/roles/elevations/"+id.String()+"/approve", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestElevationHandler_Deny_NotPending(t *testing.T) {
+	mockService := new(MockElevationService)
+	handler := NewElevationHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	approverID := uuid.New()
+	id := uuid.New()
+
+	router := newClaimsTestRouter(tenantID, approverID, "roles:approve")
+	router.POST("/api/v1/roles/elevations/:id/deny", handler.Deny)
+
+	mockService.On("Deny", mock.Anything, tenantID, id, approverID, mock.MatchedBy(func(comment *string) bool {
+		return comment != nil && *comment == "not needed"
+	})).Return(nil, fmt.Errorf("elevation request is not pending"))
+
+	body, _ := json.Marshal(map[string]string{"comment": "not needed"})
+	req, _ := http.NewRequest("POST", "/api/v1/roles/elevations/"+id.String()+"/deny", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/group"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GroupHandler handles group-related HTTP requests
+type GroupHandler struct {
+	groupService group.ServiceInterface
+	auditService audit.ServiceInterface
+}
+
+// NewGroupHandler creates a new group handler
+func NewGroupHandler(groupService group.ServiceInterface, auditService audit.ServiceInterface) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+		auditService: auditService,
+	}
+}
+
+// Create handles POST /api/v1/groups
+func (h *GroupHandler) Create(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req group.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	// Set tenant ID from context
+	req.TenantID = tenantID
+
+	created, err := h.groupService.Create(c.Request.Context(), &req)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "creation_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logGroupEvent(c, models.EventTypeGroupCreated, created, tenantID, nil)
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetByID handles GET /api/v1/groups/:id
+func (h *GroupHandler) GetByID(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	g, ok := h.tenantGroupParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, g)
+}
+
+// Update handles PUT /api/v1/groups/:id
+func (h *GroupHandler) Update(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	g, ok := h.tenantGroupParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	var req group.UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	updated, err := h.groupService.Update(c.Request.Context(), g.ID, &req)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "update_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logGroupEvent(c, models.EventTypeGroupUpdated, updated, tenantID, nil)
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete handles DELETE /api/v1/groups/:id
+func (h *GroupHandler) Delete(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	g, ok := h.tenantGroupParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.Delete(c.Request.Context(), g.ID); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "deletion_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logGroupEvent(c, models.EventTypeGroupDeleted, g, tenantID, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+// List handles GET /api/v1/groups
+func (h *GroupHandler) List(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	filters := &interfaces.GroupFilters{
+		Page:     1,
+		PageSize: 20,
+	}
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			filters.Page = page
+		}
+	}
+
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil && pageSize > 0 && pageSize <= 100 {
+			filters.PageSize = pageSize
+		}
+	}
+
+	if search := c.Query("search"); search != "" {
+		filters.Search = &search
+	}
+
+	groups, err := h.groupService.List(c.Request.Context(), tenantID, filters)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if groups == nil {
+		groups = []*models.Group{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"groups":    groups,
+		"page":      filters.Page,
+		"page_size": filters.PageSize,
+	})
+}
+
+// ListMembers handles GET /api/v1/groups/:id/members
+func (h *GroupHandler) ListMembers(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	g, ok := h.tenantGroupParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	members, err := h.groupService.ListMembers(c.Request.Context(), g.ID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	groups, err := h.groupService.ListMemberGroups(c.Request.Context(), g.ID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if members == nil {
+		members = []*models.GroupMember{}
+	}
+	if groups == nil {
+		groups = []*models.Group{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": members, "groups": groups})
+}
+
+// AddMember handles POST /api/v1/groups/:id/members/:user_id
+func (h *GroupHandler) AddMember(c *gin.Context) {
+	h.changeMembership(c, "user_id", "Invalid user ID format", h.groupService.AddMember,
+		models.EventTypeGroupMemberAdded, "Member added successfully")
+}
+
+// RemoveMember handles DELETE /api/v1/groups/:id/members/:user_id
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	h.changeMembership(c, "user_id", "Invalid user ID format", h.groupService.RemoveMember,
+		models.EventTypeGroupMemberRemoved, "Member removed successfully")
+}
+
+// AddMemberGroup handles POST /api/v1/groups/:id/groups/:member_group_id
+func (h *GroupHandler) AddMemberGroup(c *gin.Context) {
+	h.changeMembership(c, "member_group_id", "Invalid group ID format", h.groupService.AddMemberGroup,
+		models.EventTypeGroupMemberAdded, "Member group added successfully")
+}
+
+// RemoveMemberGroup handles DELETE /api/v1/groups/:id/groups/:member_group_id
+func (h *GroupHandler) RemoveMemberGroup(c *gin.Context) {
+	h.changeMembership(c, "member_group_id", "Invalid group ID format", h.groupService.RemoveMemberGroup,
+		models.EventTypeGroupMemberRemoved, "Member group removed successfully")
+}
+
+// GetRoles handles GET /api/v1/groups/:id/roles
+func (h *GroupHandler) GetRoles(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	g, ok := h.tenantGroupParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	roles, err := h.groupService.GetGroupRoles(c.Request.Context(), g.ID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if roles == nil {
+		roles = []*models.Role{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// AssignRole handles POST /api/v1/groups/:id/roles/:role_id
+func (h *GroupHandler) AssignRole(c *gin.Context) {
+	h.changeMembership(c, "role_id", "Invalid role ID format", h.groupService.AssignRole,
+		models.EventTypeGroupRoleAssigned, "Role assigned successfully")
+}
+
+// RemoveRole handles DELETE /api/v1/groups/:id/roles/:role_id
+func (h *GroupHandler) RemoveRole(c *gin.Context) {
+	h.changeMembership(c, "role_id", "Invalid role ID format", h.groupService.RemoveRole,
+		models.EventTypeGroupRoleRemoved, "Role removed successfully")
+}
+
+// changeMembership runs a group relationship change named by the :id and param path parameters
+func (h *GroupHandler) changeMembership(c *gin.Context, param, invalidMsg string,
+	change func(ctx context.Context, groupID, otherID uuid.UUID) error, eventType, message string) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	g, ok := h.tenantGroupParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	otherID, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id", invalidMsg, nil)
+		return
+	}
+
+	if err := change(c.Request.Context(), g.ID, otherID); err != nil {
+		if strings.Contains(err.Error(), "cycle") {
+			middleware.RespondWithError(c, http.StatusConflict, "group_nesting_cycle",
+				err.Error(), nil)
+			return
+		}
+		if strings.Contains(err.Error(), "separation of duties violation") {
+			middleware.RespondWithError(c, http.StatusConflict, "sod_violation",
+				err.Error(), nil)
+			return
+		}
+		middleware.RespondWithError(c, http.StatusBadRequest, "update_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logGroupEvent(c, eventType, g, tenantID, map[string]interface{}{param: otherID.String()})
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// tenantGroupParam loads the group named by the :id path parameter and checks it belongs to the tenant.
+// It writes the error response and returns false when the group cannot be used.
+func (h *GroupHandler) tenantGroupParam(c *gin.Context, tenantID uuid.UUID) (*models.Group, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid group ID format", nil)
+		return nil, false
+	}
+
+	g, err := h.groupService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusNotFound, "group_not_found",
+			err.Error(), nil)
+		return nil, false
+	}
+	if g.TenantID != tenantID {
+		// Don't reveal groups of other tenants
+		middleware.RespondWithError(c, http.StatusNotFound, "group_not_found",
+			"Group not found", nil)
+		return nil, false
+	}
+
+	return g, true
+}
+
+// logGroupEvent records an audit event for a group change
+func (h *GroupHandler) logGroupEvent(c *gin.Context, eventType string, g *models.Group, tenantID uuid.UUID, metadata map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target: &models.AuditTarget{
+			Type:       "group",
+			ID:         g.ID,
+			Identifier: g.Name,
+		},
+		TenantID:  &tenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata:  metadata,
+		Result:    models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
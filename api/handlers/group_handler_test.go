@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/group"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockGroupService is a mock implementation of group.ServiceInterface
+type MockGroupService struct {
+	mock.Mock
+}
+
+func (m *MockGroupService) Create(ctx context.Context, req *group.CreateGroupRequest) (*models.Group, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockGroupService) GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockGroupService) Lock(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) GetByExternalID(ctx context.Context, tenantID uuid.UUID, externalID string) (*models.Group, error) {
+	args := m.Called(ctx, tenantID, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockGroupService) Update(ctx context.Context, id uuid.UUID, req *group.UpdateGroupRequest) (*models.Group, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockGroupService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) ([]*models.Group, error) {
+	args := m.Called(ctx, tenantID, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Group), args.Error(1)
+}
+
+func (m *MockGroupService) Count(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) (int, error) {
+	args := m.Called(ctx, tenantID, filters)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockGroupService) AddMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	args := m.Called(ctx, groupID, userID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	args := m.Called(ctx, groupID, userID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) ListMembers(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMember, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.GroupMember), args.Error(1)
+}
+
+func (m *MockGroupService) SetMembers(ctx context.Context, groupID uuid.UUID, userIDs, memberGroupIDs []uuid.UUID) error {
+	args := m.Called(ctx, groupID, userIDs, memberGroupIDs)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]*models.Group, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Group), args.Error(1)
+}
+
+func (m *MockGroupService) AddMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error {
+	args := m.Called(ctx, groupID, memberGroupID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) RemoveMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error {
+	args := m.Called(ctx, groupID, memberGroupID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) ListMemberGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Group), args.Error(1)
+}
+
+func (m *MockGroupService) AssignRole(ctx context.Context, groupID, roleID uuid.UUID) error {
+	args := m.Called(ctx, groupID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) RemoveRole(ctx context.Context, groupID, roleID uuid.UUID) error {
+	args := m.Called(ctx, groupID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) GetGroupRoles(ctx context.Context, groupID uuid.UUID) ([]*models.Role, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Role), args.Error(1)
+}
+
+func TestGroupHandler_AddMemberGroup_Cycle(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	sre := &models.Group{ID: uuid.New(), TenantID: tenantID, Name: "sre"}
+	engineeringID := uuid.New()
+
+	router := newTenantTestRouter(tenantID)
+	router.POST("/api/v1/groups/:id/groups/:member_group_id", handler.AddMemberGroup)
+
+	mockService.On("GetByID", mock.Anything, sre.ID).Return(sre, nil)
+	mockService.On("AddMemberGroup", mock.Anything, sre.ID, engineeringID).
+		Return(fmt.Errorf("group nesting cycle: engineering already contains sre"))
+
+	req, _ := http.NewRequest("POST", "/api/v1/groups/"+sre.ID.String()+"/groups/"+engineeringID.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "group_nesting_cycle")
+	mockService.AssertExpectations(t)
+}
+
+func TestGroupHandler_GetByID_OtherTenant(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, nil)
+
+	foreign := &models.Group{ID: uuid.New(), TenantID: uuid.New(), Name: "foreign"}
+
+	router := newTenantTestRouter(uuid.New())
+	router.GET("/api/v1/groups/:id", handler.GetByID)
+
+	mockService.On("GetByID", mock.Anything, foreign.ID).Return(foreign, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/groups/"+foreign.ID.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "foreign")
+}
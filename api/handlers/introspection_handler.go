@@ -2,55 +2,171 @@ package handlers
 
 import (
 	"net/http"
+	"net/url"
 
-	"github.com/gin-gonic/gin"
-	"github.com/arauth-identity/iam/api/middleware"
 	"github.com/arauth-identity/iam/auth/introspection"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/oauthclient"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-// IntrospectionHandler handles token introspection requests (RFC 7662)
+// IntrospectionHandler handles token introspection (RFC 7662) and revocation (RFC 7009) requests
 type IntrospectionHandler struct {
 	introspectionService introspection.ServiceInterface
+	clientService        oauthclient.ServiceInterface
+	auditService         audit.ServiceInterface
 }
 
 // NewIntrospectionHandler creates a new token introspection handler
-func NewIntrospectionHandler(introspectionService introspection.ServiceInterface) *IntrospectionHandler {
+func NewIntrospectionHandler(introspectionService introspection.ServiceInterface, clientService oauthclient.ServiceInterface, auditService audit.ServiceInterface) *IntrospectionHandler {
 	return &IntrospectionHandler{
 		introspectionService: introspectionService,
+		clientService:        clientService,
+		auditService:         auditService,
 	}
 }
 
-// IntrospectToken handles POST /api/v1/introspect (RFC 7662)
-func (h *IntrospectionHandler) IntrospectToken(c *gin.Context) {
-	var req struct {
-		Token         string `json:"token" form:"token" binding:"required"`
-		TokenTypeHint string `json:"token_type_hint,omitempty" form:"token_type_hint"`
+// tokenEndpointRequest is the body shared by the introspection and revocation endpoints
+type tokenEndpointRequest struct {
+	Token         string `json:"token" form:"token" binding:"required"`
+	TokenTypeHint string `json:"token_type_hint,omitempty" form:"token_type_hint"`
+	ClientID      string `json:"client_id,omitempty" form:"client_id"`
+	ClientSecret  string `json:"client_secret,omitempty" form:"client_secret"`
+}
+
+// respondOAuthError sends an RFC 6749 Section 5.2 error response
+func respondOAuthError(c *gin.Context, statusCode int, errorCode, description string) {
+	if statusCode == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Basic realm="arauth", charset="UTF-8"`)
 	}
+	c.JSON(statusCode, gin.H{
+		"error":             errorCode,
+		"error_description": description,
+	})
+}
+
+// bindTokenEndpointRequest binds a form-encoded or JSON token endpoint request
+func bindTokenEndpointRequest(c *gin.Context) (*tokenEndpointRequest, bool) {
+	var req tokenEndpointRequest
 
-	// Support both JSON and form-encoded requests (RFC 7662 allows both)
+	// Support both JSON and form-encoded requests (RFC 7662/7009 use form encoding)
+	var err error
 	if c.ContentType() == "application/x-www-form-urlencoded" {
-		if err := c.ShouldBind(&req); err != nil {
-			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
-				"Request validation failed", middleware.FormatValidationErrors(err))
-			return
-		}
+		err = c.ShouldBind(&req)
 	} else {
-		if err := c.ShouldBindJSON(&req); err != nil {
-			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
-				"Request validation failed", middleware.FormatValidationErrors(err))
-			return
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		respondOAuthError(c, http.StatusBadRequest, "invalid_request", "The token parameter is required")
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// authenticateClient authenticates the calling OAuth client using
+// client_secret_basic (Authorization header) or client_secret_post (request body)
+func (h *IntrospectionHandler) authenticateClient(c *gin.Context, req *tokenEndpointRequest) (*oauthclient.Client, bool) {
+	clientID, clientSecret := req.ClientID, req.ClientSecret
+
+	if basicID, basicSecret, ok := c.Request.BasicAuth(); ok {
+		// Only one authentication method may be used per request (RFC 6749 Section 2.3)
+		if req.ClientSecret != "" {
+			respondOAuthError(c, http.StatusBadRequest, "invalid_request", "Multiple client authentication methods are not allowed")
+			return nil, false
+		}
+		// Basic credentials are form-urlencoded (RFC 6749 Section 2.3.1)
+		var err error
+		if clientID, err = url.QueryUnescape(basicID); err != nil {
+			respondOAuthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+			return nil, false
+		}
+		if clientSecret, err = url.QueryUnescape(basicSecret); err != nil {
+			respondOAuthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+			return nil, false
 		}
 	}
 
+	client, err := h.clientService.AuthenticateClient(c.Request.Context(), clientID, clientSecret)
+	if err != nil {
+		respondOAuthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		return nil, false
+	}
+
+	return client, true
+}
+
+// IntrospectToken handles POST /api/v1/introspect (RFC 7662)
+func (h *IntrospectionHandler) IntrospectToken(c *gin.Context) {
+	req, ok := bindTokenEndpointRequest(c)
+	if !ok {
+		return
+	}
+
+	client, ok := h.authenticateClient(c, req)
+	if !ok {
+		return
+	}
+
+	// Only confidential clients (resource servers) may introspect tokens
+	if !client.IsConfidential {
+		respondOAuthError(c, http.StatusUnauthorized, "invalid_client", "Public clients cannot introspect tokens")
+		return
+	}
+
 	// Introspect the token
-	tokenInfo, err := h.introspectionService.IntrospectToken(c.Request.Context(), req.Token, req.TokenTypeHint)
+	tokenInfo, err := h.introspectionService.IntrospectToken(c.Request.Context(), client.TenantID, req.Token, req.TokenTypeHint)
 	if err != nil {
-		middleware.RespondWithError(c, http.StatusInternalServerError, "introspection_error",
-			"Failed to introspect token", nil)
+		respondOAuthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", "Failed to introspect token")
 		return
 	}
 
 	// Return token info (RFC 7662 response)
+	c.Header("Cache-Control", "no-store")
 	c.JSON(http.StatusOK, tokenInfo)
 }
 
+// RevokeToken handles POST /api/v1/oauth/revoke (RFC 7009)
+func (h *IntrospectionHandler) RevokeToken(c *gin.Context) {
+	req, ok := bindTokenEndpointRequest(c)
+	if !ok {
+		return
+	}
+
+	client, ok := h.authenticateClient(c, req)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.introspectionService.RevokeToken(c.Request.Context(), client.TenantID, req.Token, req.TokenTypeHint)
+	if err != nil {
+		c.Header("Retry-After", "5")
+		respondOAuthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", "Failed to revoke token")
+		return
+	}
+
+	// Log token revocation against the token's subject
+	if revoked != nil && h.auditService != nil {
+		if userID, err := uuid.Parse(revoked.Subject); err == nil {
+			sourceIP, userAgent := extractSourceInfo(c)
+			actor := models.AuditActor{
+				UserID:        userID,
+				Username:      revoked.Username,
+				PrincipalType: revoked.PrincipalType,
+			}
+			var tenantID *uuid.UUID
+			if tid, err := uuid.Parse(revoked.TenantID); err == nil {
+				tenantID = &tid
+			}
+			_ = h.auditService.LogTokenRevoked(c.Request.Context(), actor, tenantID, sourceIP, userAgent, map[string]interface{}{
+				"token_type": revoked.TokenType,
+				"client_id":  client.ClientID,
+			})
+		}
+	}
+
+	// Invalid and unknown tokens also get 200 OK (RFC 7009 Section 2.2)
+	c.Status(http.StatusOK)
+}
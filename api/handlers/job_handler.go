@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/jobs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// JobHandler handles background job HTTP requests
+type JobHandler struct {
+	scheduler    jobs.SchedulerInterface
+	auditService audit.ServiceInterface
+}
+
+// NewJobHandler creates a new background job handler
+func NewJobHandler(scheduler jobs.SchedulerInterface, auditService audit.ServiceInterface) *JobHandler {
+	return &JobHandler{
+		scheduler:    scheduler,
+		auditService: auditService,
+	}
+}
+
+// ListJobs handles GET /system/jobs
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	list, err := h.scheduler.List(c.Request.Context())
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if list == nil {
+		list = []*models.ScheduledJob{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":  list,
+		"count": len(list),
+	})
+}
+
+// GetJob handles GET /system/jobs/:name
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, err := h.scheduler.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondWithJobError(c, "get_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ListJobRuns handles GET /system/jobs/:name/runs
+func (h *JobHandler) ListJobRuns(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+			if limit > 500 {
+				limit = 500
+			}
+		}
+	}
+
+	runs, err := h.scheduler.Runs(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		respondWithJobError(c, "list_failed", err)
+		return
+	}
+	if runs == nil {
+		runs = []*models.JobRun{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":  runs,
+		"count": len(runs),
+		"limit": limit,
+	})
+}
+
+// TriggerJob handles POST /system/jobs/:name/run.
+// The job runs on whichever replica polls next.
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.scheduler.Trigger(c.Request.Context(), name); err != nil {
+		respondWithJobError(c, "trigger_failed", err)
+		return
+	}
+
+	job, err := h.scheduler.Get(c.Request.Context(), name)
+	if err != nil {
+		respondWithJobError(c, "get_failed", err)
+		return
+	}
+
+	h.logJobTriggered(c, job)
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// respondWithJobError maps scheduler errors to HTTP statuses
+func respondWithJobError(c *gin.Context, code string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", msg, nil)
+	case strings.Contains(msg, "already running"):
+		middleware.RespondWithError(c, http.StatusConflict, "job_running", msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusInternalServerError, code, msg, nil)
+	}
+}
+
+// logJobTriggered records an audit event for a manually triggered job
+func (h *JobHandler) logJobTriggered(c *gin.Context, job *models.ScheduledJob) {
+	if h.auditService == nil {
+		return
+	}
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: models.EventTypeJobTriggered,
+		Actor:     actor,
+		Target: &models.AuditTarget{
+			Type:       "job",
+			ID:         uuid.Nil, // Jobs are identified by name
+			Identifier: job.Name,
+		},
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata: map[string]interface{}{
+			"schedule": job.Schedule,
+		},
+		Result: models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockScheduler
+type MockScheduler struct {
+	mock.Mock
+}
+
+func (m *MockScheduler) List(ctx context.Context) ([]*models.ScheduledJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ScheduledJob), args.Error(1)
+}
+
+func (m *MockScheduler) Get(ctx context.Context, name string) (*models.ScheduledJob, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ScheduledJob), args.Error(1)
+}
+
+func (m *MockScheduler) Runs(ctx context.Context, name string, limit int) ([]*models.JobRun, error) {
+	args := m.Called(ctx, name, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.JobRun), args.Error(1)
+}
+
+func (m *MockScheduler) Trigger(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func TestJobHandler_ListJobs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	scheduler := &MockScheduler{}
+	handler := NewJobHandler(scheduler, nil)
+
+	router := gin.New()
+	router.GET("/system/jobs", handler.ListJobs)
+
+	scheduler.On("List", mock.Anything).Return([]*models.ScheduledJob{
+		{Name: "webhooks.retry", Schedule: "@every 30s", Enabled: true},
+		{Name: "security_events.purge", Schedule: "15 3 * * *", Enabled: true},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/system/jobs", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Jobs  []*models.ScheduledJob `json:"jobs"`
+		Count int                    `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 2, body.Count)
+	assert.Equal(t, "webhooks.retry", body.Jobs[0].Name)
+}
+
+func TestJobHandler_ListJobRuns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		scheduler := &MockScheduler{}
+		handler := NewJobHandler(scheduler, nil)
+
+		router := gin.New()
+		router.GET("/system/jobs/:name/runs", handler.ListJobRuns)
+
+		scheduler.On("Runs", mock.Anything, "webhooks.retry", 500).Return([]*models.JobRun{
+			{JobName: "webhooks.retry", Status: models.JobRunSucceeded, Processed: 3},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/system/jobs/webhooks.retry/runs?limit=1000", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		scheduler.AssertExpectations(t)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		scheduler := &MockScheduler{}
+		handler := NewJobHandler(scheduler, nil)
+
+		router := gin.New()
+		router.GET("/system/jobs/:name/runs", handler.ListJobRuns)
+
+		scheduler.On("Runs", mock.Anything, "missing", 50).Return(nil, errors.New("scheduled job not found"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/system/jobs/missing/runs", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestJobHandler_TriggerJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		scheduler := &MockScheduler{}
+		handler := NewJobHandler(scheduler, nil)
+
+		router := gin.New()
+		router.POST("/system/jobs/:name/run", handler.TriggerJob)
+
+		scheduler.On("Trigger", mock.Anything, "invitations.purge").Return(nil)
+		scheduler.On("Get", mock.Anything, "invitations.purge").Return(&models.ScheduledJob{
+			Name: "invitations.purge", Schedule: "30 3 * * *", Enabled: true,
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/system/jobs/invitations.purge/run", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		scheduler.AssertExpectations(t)
+	})
+
+	t.Run("already_running", func(t *testing.T) {
+		scheduler := &MockScheduler{}
+		handler := NewJobHandler(scheduler, nil)
+
+		router := gin.New()
+		router.POST("/system/jobs/:name/run", handler.TriggerJob)
+
+		scheduler.On("Trigger", mock.Anything, "scim_connectors.reconcile").
+			Return(errors.New("scheduled job is already running"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/system/jobs/scim_connectors.reconcile/run", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -0,0 +1,509 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/auth/mfa"
+	"github.com/arauth-identity/iam/identity/account"
+	auditevent "github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/linking"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MeHandler handles self-service requests for the authenticated user.
+// Every operation is scoped to the JWT subject and needs no extra permissions.
+type MeHandler struct {
+	accountService account.ServiceInterface
+	mfaService     mfa.ServiceInterface
+	linkingService linking.ServiceInterface
+	auditService   auditevent.ServiceInterface
+}
+
+// NewMeHandler creates a new self-service handler
+func NewMeHandler(
+	accountService account.ServiceInterface,
+	mfaService mfa.ServiceInterface,
+	linkingService linking.ServiceInterface,
+	auditService auditevent.ServiceInterface,
+) *MeHandler {
+	return &MeHandler{
+		accountService: accountService,
+		mfaService:     mfaService,
+		linkingService: linkingService,
+		auditService:   auditService,
+	}
+}
+
+// mfaCodeRequest carries a TOTP code or recovery code for step-up verification
+type mfaCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// GetProfile handles GET /api/v1/me
+func (h *MeHandler) GetProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.accountService.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found",
+			"User not found", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile handles PUT /api/v1/me
+func (h *MeHandler) UpdateProfile(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req account.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	u, err := h.accountService.UpdateProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "is not editable") {
+			middleware.RespondWithError(c, http.StatusForbidden, "field_not_editable",
+				err.Error(), nil)
+			return
+		}
+		middleware.RespondWithError(c, http.StatusBadRequest, "update_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logSelfServiceUpdate(c, u, tenantID, "profile_updated")
+
+	c.JSON(http.StatusOK, u)
+}
+
+// ChangePassword handles POST /api/v1/me/password
+func (h *MeHandler) ChangePassword(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req account.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	if err := h.accountService.ChangePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "current password is incorrect"):
+			middleware.RespondWithError(c, http.StatusUnauthorized, "invalid_current_password",
+				"Current password is incorrect", nil)
+		case strings.Contains(err.Error(), "account is locked"):
+			middleware.RespondWithError(c, http.StatusForbidden, "account_locked",
+				"Account is locked", nil)
+		case strings.Contains(err.Error(), "password validation failed"),
+			strings.Contains(err.Error(), "must be different"):
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_password",
+				err.Error(), nil)
+		default:
+			middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error",
+				"Failed to change password", nil)
+		}
+		return
+	}
+
+	h.logSelfServiceUpdate(c, &models.User{ID: userID}, tenantID, "password_changed")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password changed successfully. All sessions have been revoked.",
+	})
+}
+
+// GetMFAStatus handles GET /api/v1/me/mfa
+func (h *MeHandler) GetMFAStatus(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(c.Request.Context(), userID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error",
+			"Failed to get MFA status", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// EnrollMFA handles POST /api/v1/me/mfa/enroll
+func (h *MeHandler) EnrollMFA(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Body is optional; it carries a display name for the new factor and,
+	// once a factor is verified, a current code from it as a step-up check
+	var body struct {
+		Name string `json:"name"`
+		Code string `json:"code"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+				"Request validation failed", middleware.FormatValidationErrors(err))
+			return
+		}
+	}
+
+	verifyReq := newMFAVerifyRequest(userID, body.Code)
+	resp, err := h.mfaService.Enroll(c.Request.Context(), &mfa.EnrollRequest{
+		UserID:       userID,
+		Name:         body.Name,
+		TOTPCode:     verifyReq.TOTPCode,
+		RecoveryCode: verifyReq.RecoveryCode,
+	})
+	if err != nil {
+		if isMFAStepUpError(err) {
+			respondWithMFAManageError(c, err)
+			return
+		}
+		middleware.RespondWithError(c, http.StatusBadRequest, "enrollment_failed",
+			err.Error(), nil)
+		return
+	}
+
+	actor, _ := extractActorFromContext(c)
+	sourceIP, userAgent := extractSourceInfo(c)
+	_ = h.auditService.LogMFAEnrolled(c.Request.Context(), actor, &tenantID, sourceIP, userAgent)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// VerifyMFA handles POST /api/v1/me/mfa/verify
+func (h *MeHandler) VerifyMFA(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var body mfaCodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	valid, err := h.mfaService.Verify(c.Request.Context(), newMFAVerifyRequest(userID, body.Code))
+
+	actor, _ := extractActorFromContext(c)
+	sourceIP, userAgent := extractSourceInfo(c)
+	_ = h.auditService.LogMFAVerified(c.Request.Context(), actor, &tenantID, sourceIP, userAgent, err == nil && valid)
+
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "verification_failed",
+			err.Error(), nil)
+		return
+	}
+	if !valid {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "invalid_code",
+			"Invalid TOTP code or recovery code", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"verified": true,
+	})
+}
+
+// DisableMFA handles POST /api/v1/me/mfa/disable
+func (h *MeHandler) DisableMFA(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var body mfaCodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	if err := h.accountService.DisableMFA(c.Request.Context(), newMFAVerifyRequest(userID, body.Code)); err != nil {
+		respondWithMFAManageError(c, err)
+		return
+	}
+
+	actor, _ := extractActorFromContext(c)
+	sourceIP, userAgent := extractSourceInfo(c)
+	_ = h.auditService.LogMFADisabled(c.Request.Context(), actor, &tenantID, sourceIP, userAgent)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "MFA disabled successfully",
+	})
+}
+
+// RemoveMFAFactor handles DELETE /api/v1/me/mfa/factors/:factor_id
+func (h *MeHandler) RemoveMFAFactor(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	factorID, err := uuid.Parse(c.Param("factor_id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_factor_id",
+			"Invalid factor ID format", nil)
+		return
+	}
+
+	// The step-up code is optional only for dropping a pending factor before MFA is enabled
+	var body struct {
+		Code string `json:"code"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+				"Request validation failed", middleware.FormatValidationErrors(err))
+			return
+		}
+	}
+
+	verifyReq := newMFAVerifyRequest(userID, body.Code)
+	req := &mfa.RemoveFactorRequest{
+		UserID:       userID,
+		FactorID:     factorID,
+		TOTPCode:     verifyReq.TOTPCode,
+		RecoveryCode: verifyReq.RecoveryCode,
+	}
+	if err := h.accountService.RemoveMFAFactor(c.Request.Context(), req); err != nil {
+		respondWithMFAManageError(c, err)
+		return
+	}
+
+	h.logSelfServiceUpdate(c, &models.User{ID: userID}, tenantID, "mfa_factor_removed")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "MFA factor removed successfully",
+		"factor_id": factorID.String(),
+	})
+}
+
+// RegenerateRecoveryCodes handles POST /api/v1/me/mfa/recovery-codes
+func (h *MeHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var body mfaCodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	codes, err := h.accountService.RegenerateRecoveryCodes(c.Request.Context(), newMFAVerifyRequest(userID, body.Code))
+	if err != nil {
+		respondWithMFAManageError(c, err)
+		return
+	}
+
+	h.logSelfServiceUpdate(c, &models.User{ID: userID}, tenantID, "mfa_recovery_codes_regenerated")
+
+	c.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// ListIdentities handles GET /api/v1/me/identities
+func (h *MeHandler) ListIdentities(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	identities, err := h.linkingService.GetUserIdentities(c.Request.Context(), userID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error",
+			"Failed to get identities", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"identities": identities,
+		"count":      len(identities),
+	})
+}
+
+// UnlinkIdentity handles DELETE /api/v1/me/identities/:identity_id
+func (h *MeHandler) UnlinkIdentity(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	identityID, err := uuid.Parse(c.Param("identity_id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_identity_id",
+			"Invalid identity ID format", nil)
+		return
+	}
+
+	// The linking service checks that the identity belongs to this user
+	if err := h.linkingService.UnlinkIdentity(c.Request.Context(), userID, identityID); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "unlink_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logSelfServiceUpdate(c, &models.User{ID: userID}, tenantID, "identity_unlinked")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Identity unlinked successfully",
+	})
+}
+
+// SetPrimaryIdentity handles PUT /api/v1/me/identities/:identity_id/primary
+func (h *MeHandler) SetPrimaryIdentity(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	identityID, err := uuid.Parse(c.Param("identity_id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_identity_id",
+			"Invalid identity ID format", nil)
+		return
+	}
+
+	if err := h.linkingService.SetPrimaryIdentity(c.Request.Context(), userID, identityID); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "update_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logSelfServiceUpdate(c, &models.User{ID: userID}, tenantID, "primary_identity_changed")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Primary identity updated successfully",
+	})
+}
+
+// logSelfServiceUpdate records a change the user made to their own account
+func (h *MeHandler) logSelfServiceUpdate(c *gin.Context, u *models.User, tenantID uuid.UUID, action string) {
+	actor, _ := extractActorFromContext(c)
+	sourceIP, userAgent := extractSourceInfo(c)
+	target := &models.AuditTarget{
+		Type:       "user",
+		ID:         u.ID,
+		Identifier: u.Username,
+	}
+	_ = h.auditService.LogUserUpdated(c.Request.Context(), actor, target, &tenantID, sourceIP, userAgent, map[string]interface{}{
+		"action":       action,
+		"self_service": true,
+	})
+}
+
+// newMFAVerifyRequest builds a verify request, treating all-digit codes as TOTP
+// and anything else as a recovery code
+func newMFAVerifyRequest(userID uuid.UUID, code string) *mfa.VerifyRequest {
+	code = strings.TrimSpace(code)
+	req := &mfa.VerifyRequest{UserID: userID}
+	if strings.Trim(code, "0123456789") == "" {
+		req.TOTPCode = code
+	} else {
+		req.RecoveryCode = code
+	}
+	return req
+}
+
+// isMFAStepUpError reports whether err is a failed or missing step-up code
+func isMFAStepUpError(err error) bool {
+	return strings.Contains(err.Error(), "invalid MFA code") ||
+		strings.Contains(err.Error(), "either totp_code or recovery_code must be provided")
+}
+
+// respondWithMFAManageError maps MFA disable/regenerate errors onto HTTP responses
+func respondWithMFAManageError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "invalid MFA code"):
+		middleware.RespondWithError(c, http.StatusUnauthorized, "invalid_code",
+			"Invalid TOTP code or recovery code", nil)
+	case strings.Contains(err.Error(), "required by tenant policy"):
+		middleware.RespondWithError(c, http.StatusForbidden, "mfa_required",
+			err.Error(), nil)
+	case strings.Contains(err.Error(), "MFA factor not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found",
+			"MFA factor not found", nil)
+	case strings.Contains(err.Error(), "either totp_code or recovery_code must be provided"):
+		middleware.RespondWithError(c, http.StatusBadRequest, "code_required",
+			"A current TOTP code or recovery code is required", nil)
+	case strings.Contains(err.Error(), "MFA is not enabled"):
+		middleware.RespondWithError(c, http.StatusBadRequest, "mfa_not_enabled",
+			err.Error(), nil)
+	default:
+		middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error",
+			"Failed to update MFA settings", nil)
+	}
+}
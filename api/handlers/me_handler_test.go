@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/auth/mfa"
+	"github.com/arauth-identity/iam/identity/account"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccountService is a mock implementation of account.ServiceInterface
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) GetProfile(ctx context.Context, userID uuid.UUID) (*account.Profile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*account.Profile), args.Error(1)
+}
+
+func (m *MockAccountService) UpdateProfile(ctx context.Context, userID uuid.UUID, req *account.UpdateProfileRequest) (*models.User, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockAccountService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) DisableMFA(ctx context.Context, req *mfa.VerifyRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) RemoveMFAFactor(ctx context.Context, req *mfa.RemoveFactorRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) RegenerateRecoveryCodes(ctx context.Context, req *mfa.VerifyRequest) ([]string, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func setupMeRouter(handler *MeHandler, tenantID, userID uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Set("user_id", userID.String())
+		c.Next()
+	})
+	router.GET("/me", handler.GetProfile)
+	router.PUT("/me", handler.UpdateProfile)
+	router.POST("/me/password", handler.ChangePassword)
+	router.POST("/me/mfa/disable", handler.DisableMFA)
+	return router
+}
+
+func TestMeHandler_GetProfile(t *testing.T) {
+	mockAccount := new(MockAccountService)
+	handler := NewMeHandler(mockAccount, nil, nil, new(MockAuditService))
+
+	tenantID, userID := uuid.New(), uuid.New()
+	profile := &account.Profile{
+		User:           &models.User{ID: userID, Username: "alice"},
+		EditableFields: account.DefaultEditableFields,
+	}
+	mockAccount.On("GetProfile", mock.Anything, userID).Return(profile, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/me", nil)
+	setupMeRouter(handler, tenantID, userID).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []interface{}{"first_name", "last_name"}, response["editable_fields"])
+	mockAccount.AssertExpectations(t)
+}
+
+func TestMeHandler_UpdateProfile_FieldNotEditable(t *testing.T) {
+	mockAccount := new(MockAccountService)
+	handler := NewMeHandler(mockAccount, nil, nil, new(MockAuditService))
+
+	tenantID, userID := uuid.New(), uuid.New()
+	mockAccount.On("UpdateProfile", mock.Anything, userID, mock.Anything).
+		Return(nil, errors.New("field email is not editable"))
+
+	body, _ := json.Marshal(map[string]string{"email": "new@example.com"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/me", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	setupMeRouter(handler, tenantID, userID).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "field_not_editable")
+}
+
+func TestMeHandler_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"success", nil, http.StatusOK, "Password changed successfully"},
+		{"wrong current password", errors.New("current password is incorrect"), http.StatusUnauthorized, "invalid_current_password"},
+		{"policy violation", errors.New("password validation failed: too short"), http.StatusBadRequest, "invalid_password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAccount := new(MockAccountService)
+			handler := NewMeHandler(mockAccount, nil, nil, new(MockAuditService))
+
+			tenantID, userID := uuid.New(), uuid.New()
+			mockAccount.On("ChangePassword", mock.Anything, userID, "OldPassword123!", "NewPassword123!").Return(tt.serviceErr)
+
+			body, _ := json.Marshal(map[string]string{
+				"current_password": "OldPassword123!",
+				"new_password":     "NewPassword123!",
+			})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/me/password", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			setupMeRouter(handler, tenantID, userID).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockAccount.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMeHandler_DisableMFA_RecoveryCode(t *testing.T) {
+	mockAccount := new(MockAccountService)
+	handler := NewMeHandler(mockAccount, nil, nil, new(MockAuditService))
+
+	tenantID, userID := uuid.New(), uuid.New()
+	mockAccount.On("DisableMFA", mock.Anything, &mfa.VerifyRequest{
+		UserID:       userID,
+		RecoveryCode: "ABCD1234EFGH5678",
+	}).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{"code": "ABCD1234EFGH5678"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/me/mfa/disable", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	setupMeRouter(handler, tenantID, userID).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAccount.AssertExpectations(t)
+}
@@ -98,7 +98,7 @@ func TestMFA_Enforcement_Refresh_Enforced(t *testing.T) {
 		mockTenantSettingsRepo := new(MockTenantSettingsRepository)
 
 		// Real Services constructed with mocks
-		claimsBuilder := claims.NewBuilder(mockRoleRepo, mockPermRepo, mockSysRoleRepo, mockCapabilityService, nil)
+		claimsBuilder := claims.NewBuilder(mockRoleRepo, mockPermRepo, mockSysRoleRepo, mockCapabilityService, nil, nil)
 
 		// Correct Config structure
 		secConfig := &config.SecurityConfig{
@@ -198,7 +198,7 @@ func TestMFA_Enforcement_Refresh_Enforced(t *testing.T) {
 		mockTenantSettingsRepo := new(MockTenantSettingsRepository)
 
 		// Real Services
-		claimsBuilder := claims.NewBuilder(mockRoleRepo, mockPermRepo, mockSysRoleRepo, mockCapabilityService, nil)
+		claimsBuilder := claims.NewBuilder(mockRoleRepo, mockPermRepo, mockSysRoleRepo, mockCapabilityService, nil, nil)
 		secConfig := &config.SecurityConfig{JWT: config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 7 * 24 * time.Hour}}
 		lifetimeResolver := token.NewLifetimeResolver(secConfig, mockTenantSettingsRepo)
 		refreshService := token.NewRefreshService(mockTokenService, mockRefreshTokenRepo, mockUserRepo, claimsBuilder, lifetimeResolver)
@@ -308,6 +308,27 @@ func (m *MockRoleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID)
 func (m *MockRoleRepository) GetSystemRoles(ctx context.Context) ([]*models.Role, error) {
 	return nil, nil
 }
+func (m *MockRoleRepository) GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	return nil, nil
+}
+func (m *MockRoleRepository) GetChildRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	return nil, nil
+}
+func (m *MockRoleRepository) AddParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	return nil
+}
+func (m *MockRoleRepository) RemoveParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	return nil
+}
+func (m *MockRoleRepository) AssignRoleToUserWithExpiry(ctx context.Context, assignment *models.UserRoleAssignment) error {
+	return nil
+}
+func (m *MockRoleRepository) GetUserRoleAssignments(ctx context.Context, userID uuid.UUID) ([]*models.UserRoleAssignment, error) {
+	return []*models.UserRoleAssignment{}, nil
+}
+func (m *MockRoleRepository) DeleteExpiredRoleAssignments(ctx context.Context, before time.Time) ([]*models.UserRoleAssignment, error) {
+	return nil, nil
+}
 
 type MockPermissionRepository struct{ mock.Mock }
 
@@ -404,6 +425,11 @@ func (m *MockUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User,
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepo) LockByID(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 // Fixed GetByUsername signature
 func (m *MockUserRepo) GetByUsername(ctx context.Context, username string, tenantID uuid.UUID) (*models.User, error) {
 	return nil, nil
@@ -425,7 +451,10 @@ func (m *MockUserRepo) GetSystemUserByUsername(ctx context.Context, username str
 }
 
 func (m *MockUserRepo) Update(ctx context.Context, user *models.User) error { return nil }
-func (m *MockUserRepo) Delete(ctx context.Context, id uuid.UUID) error      { return nil }
+func (m *MockUserRepo) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	return nil
+}
+func (m *MockUserRepo) Delete(ctx context.Context, id uuid.UUID) error { return nil }
 
 // Corrected List signature
 func (m *MockUserRepo) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.UserFilters) ([]*models.User, error) {
@@ -452,6 +481,14 @@ func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *interfac
 	args := m.Called(ctx, token)
 	return args.Error(0)
 }
+func (m *MockRefreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.RefreshToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*interfaces.RefreshToken), args.Error(1)
+}
+
 func (m *MockRefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*interfaces.RefreshToken, error) {
 	args := m.Called(ctx, tokenHash)
 	return args.Get(0).(*interfaces.RefreshToken), args.Error(1)
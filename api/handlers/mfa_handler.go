@@ -71,13 +71,35 @@ func (h *MFAHandler) Enroll(c *gin.Context) {
 		return
 	}
 
+	// Body is optional; it carries a display name for the new factor and,
+	// once a factor is verified, a current code from it as a step-up check
+	var body struct {
+		Name string `json:"name"`
+		Code string `json:"code"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+				"Request validation failed", middleware.FormatValidationErrors(err))
+			return
+		}
+	}
+
 	// Create enroll request with user ID from token
+	verifyReq := newMFAVerifyRequest(userID, body.Code)
 	req := &mfa.EnrollRequest{
-		UserID: userID,
+		UserID:       userID,
+		Name:         body.Name,
+		TOTPCode:     verifyReq.TOTPCode,
+		RecoveryCode: verifyReq.RecoveryCode,
 	}
 
 	resp, err := h.mfaService.Enroll(c.Request.Context(), req)
 	if err != nil {
+		if isMFAStepUpError(err) {
+			respondWithMFAManageError(c, err)
+			return
+		}
 		middleware.RespondWithError(c, http.StatusInternalServerError, "enrollment_failed",
 			err.Error(), nil)
 		return
@@ -422,6 +444,9 @@ func (h *MFAHandler) VerifyChallenge(c *gin.Context) {
 		return
 	}
 
+	// MFA completes the login; record it (best effort, never blocks token issuance)
+	_ = h.userRepo.UpdateLastLogin(c.Request.Context(), user.ID, time.Now())
+
 	// Build claims
 	claimsObj, err := h.claimsBuilder.BuildClaims(c.Request.Context(), user)
 	if err != nil {
@@ -432,6 +457,7 @@ func (h *MFAHandler) VerifyChallenge(c *gin.Context) {
 
 	// Set AMR claim to include MFA
 	claimsObj.AMR = []string{"pwd", "mfa"}
+	claimsObj.AuthTime = time.Now().Unix()
 
 	// Get token lifetimes
 	var tenantID uuid.UUID
@@ -441,7 +467,8 @@ func (h *MFAHandler) VerifyChallenge(c *gin.Context) {
 	lifetimes := h.lifetimeResolver.GetAllLifetimes(c.Request.Context(), tenantID, false) // TODO: Support remember_me from request
 
 	// Generate access token
-	accessToken, err := h.tokenService.GenerateAccessToken(claimsObj, lifetimes.AccessTokenTTL)
+	accessTokenTTL := claimsObj.CapLifetime(lifetimes.AccessTokenTTL, time.Now())
+	accessToken, err := h.tokenService.GenerateAccessToken(claimsObj, accessTokenTTL)
 	if err != nil {
 		middleware.RespondWithError(c, http.StatusInternalServerError, "token_issue_failed",
 			"Failed to generate access token", nil)
@@ -478,6 +505,7 @@ func (h *MFAHandler) VerifyChallenge(c *gin.Context) {
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	clientInfoFromContext(c).ApplyTo(rt, time.Now())
 
 	if err := h.refreshTokenRepo.Create(c.Request.Context(), rt); err != nil {
 		middleware.RespondWithError(c, http.StatusInternalServerError, "token_storage_failed",
@@ -509,7 +537,7 @@ func (h *MFAHandler) VerifyChallenge(c *gin.Context) {
 	})
 	_ = h.auditService.LogTokenIssued(c.Request.Context(), actor, tenantIDPtr, sourceIP, userAgent, map[string]interface{}{
 		"token_type":   "access_token",
-		"expires_in":   int(lifetimes.AccessTokenTTL.Seconds()),
+		"expires_in":   int(accessTokenTTL.Seconds()),
 		"mfa_required": true,
 	})
 
@@ -519,7 +547,96 @@ func (h *MFAHandler) VerifyChallenge(c *gin.Context) {
 		"refresh_token":      refreshToken, // Return plain token to client
 		"id_token":           idToken,
 		"token_type":         "Bearer",
-		"expires_in":         int(lifetimes.AccessTokenTTL.Seconds()),
+		"expires_in":         int(accessTokenTTL.Seconds()),
 		"refresh_expires_in": int(lifetimes.RefreshTokenTTL.Seconds()),
 	})
 }
+
+// ResetUserMFA handles POST /api/v1/users/:id/mfa/reset
+// It removes all of the user's factors and recovery codes, revokes their sessions and
+// requires them to enroll again at next login.
+func (h *MFAHandler) ResetUserMFA(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_user_id",
+			"Invalid user ID format", nil)
+		return
+	}
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+				"Request validation failed", middleware.FormatValidationErrors(err))
+			return
+		}
+	}
+
+	u, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found",
+			"User not found", nil)
+		return
+	}
+
+	// Verify user belongs to tenant
+	if u.TenantID == nil || *u.TenantID != tenantID {
+		middleware.RespondWithError(c, http.StatusForbidden, "access_denied",
+			"User does not belong to this tenant", nil)
+		return
+	}
+
+	actor, _ := extractActorFromContext(c)
+	sourceIP, userAgent := extractSourceInfo(c)
+	target := &models.AuditTarget{
+		Type:       "user",
+		ID:         u.ID,
+		Identifier: u.Username,
+	}
+
+	factorsRemoved, err := h.mfaService.Reset(c.Request.Context(), u.ID)
+	if err != nil {
+		event := &models.AuditEvent{
+			EventType: models.EventTypeMFAReset,
+			Actor:     actor,
+			Target:    target,
+			TenantID:  &tenantID,
+			SourceIP:  sourceIP,
+			UserAgent: userAgent,
+			Metadata: map[string]interface{}{
+				"reason": body.Reason,
+			},
+			Result: models.ResultFailure,
+			Error:  err.Error(),
+		}
+		_ = h.auditService.LogEvent(c.Request.Context(), event)
+
+		middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error",
+			"Failed to reset MFA", nil)
+		return
+	}
+
+	// Sessions established with the old factors must not outlive the reset
+	sessionsRevoked := h.refreshTokenRepo.RevokeAllForUser(c.Request.Context(), u.ID) == nil
+
+	_ = h.auditService.LogMFAReset(c.Request.Context(), actor, target, &tenantID, sourceIP, userAgent, map[string]interface{}{
+		"reason":                body.Reason,
+		"factors_removed":       factorsRemoved,
+		"recovery_codes_reset":  true,
+		"sessions_revoked":      sessionsRevoked,
+		"reenrollment_required": true,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":               "MFA reset successfully. The user must enroll again at next login.",
+		"factors_removed":       factorsRemoved,
+		"reenrollment_required": true,
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/arauth-identity/iam/auth/claims"
 	"github.com/arauth-identity/iam/auth/mfa"
+	"github.com/arauth-identity/iam/identity/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -42,11 +43,6 @@ func (m *MockMFAService) Verify(ctx context.Context, req *mfa.VerifyRequest) (bo
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockMFAService) Disable(ctx context.Context, userID uuid.UUID, tenantID uuid.UUID) error {
-	args := m.Called(ctx, userID, tenantID)
-	return args.Error(0)
-}
-
 func (m *MockMFAService) GenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, tenantID uuid.UUID) ([]string, error) {
 	args := m.Called(ctx, userID, tenantID)
 	if args.Get(0) == nil {
@@ -81,6 +77,42 @@ func (m *MockMFAService) CreateSession(ctx context.Context, userID uuid.UUID, te
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockMFAService) GetStatus(ctx context.Context, userID uuid.UUID) (*mfa.Status, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mfa.Status), args.Error(1)
+}
+
+func (m *MockMFAService) HasVerifiedFactor(ctx context.Context, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFAService) RemoveFactor(ctx context.Context, req *mfa.RemoveFactorRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) Reset(ctx context.Context, userID uuid.UUID) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMFAService) Disable(ctx context.Context, req *mfa.VerifyRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) RegenerateRecoveryCodes(ctx context.Context, req *mfa.VerifyRequest) ([]string, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func TestMFAHandler_Enroll(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -179,3 +211,60 @@ func TestMFAHandler_Enroll_InvalidRequest(t *testing.T) {
 	// Handler checks for user_claims first, so missing claims should return 401
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestMFAHandler_ResetUserMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tenantID := uuid.New()
+	targetID := uuid.New()
+
+	tests := []struct {
+		name           string
+		userTenantID   uuid.UUID
+		expectReset    bool
+		expectedStatus int
+	}{
+		{"resets user in tenant", tenantID, true, http.StatusOK},
+		{"rejects user from another tenant", uuid.New(), false, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockMFAService)
+			mockUserRepo := new(MockUserRepo)
+			mockRefreshRepo := new(MockRefreshTokenRepository)
+			handler := NewMFAHandler(mockService, nil, nil, mockRefreshRepo, nil, mockUserRepo, nil, new(MockAuditService))
+
+			userTenantID := tt.userTenantID
+			mockUserRepo.On("GetByID", mock.Anything, targetID).Return(&models.User{
+				ID:       targetID,
+				TenantID: &userTenantID,
+				Username: "target",
+			}, nil)
+			if tt.expectReset {
+				mockService.On("Reset", mock.Anything, targetID).Return(2, nil)
+			}
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("tenant_id", tenantID)
+				c.Next()
+			})
+			router.POST("/users/:id/mfa/reset", handler.ResetUserMFA)
+
+			body, _ := json.Marshal(map[string]string{"reason": "lost device"})
+			req, _ := http.NewRequest("POST", "/users/"+targetID.String()+"/mfa/reset", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectReset {
+				assert.Contains(t, w.Body.String(), `"factors_removed":2`)
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "Reset", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/policy"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PolicyHandler handles conditional access policy HTTP requests
+type PolicyHandler struct {
+	policyService policy.ServiceInterface
+	auditService  audit.ServiceInterface
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(policyService policy.ServiceInterface, auditService audit.ServiceInterface) *PolicyHandler {
+	return &PolicyHandler{
+		policyService: policyService,
+		auditService:  auditService,
+	}
+}
+
+// Create handles POST /api/v1/policies
+func (h *PolicyHandler) Create(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req policy.CreatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	// Set tenant ID from context
+	req.TenantID = tenantID
+
+	created, err := h.policyService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondWithPolicyError(c, "creation_failed", err)
+		return
+	}
+
+	h.logPolicyEvent(c, models.EventTypePolicyCreated, created, tenantID)
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetByID handles GET /api/v1/policies/:id
+func (h *PolicyHandler) GetByID(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	p, ok := h.tenantPolicyParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// Update handles PUT /api/v1/policies/:id
+func (h *PolicyHandler) Update(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	p, ok := h.tenantPolicyParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	var req policy.UpdatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	updated, err := h.policyService.Update(c.Request.Context(), p.ID, &req)
+	if err != nil {
+		respondWithPolicyError(c, "update_failed", err)
+		return
+	}
+
+	h.logPolicyEvent(c, models.EventTypePolicyUpdated, updated, tenantID)
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete handles DELETE /api/v1/policies/:id
+func (h *PolicyHandler) Delete(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	p, ok := h.tenantPolicyParam(c, tenantID)
+	if !ok {
+		return
+	}
+
+	if err := h.policyService.Delete(c.Request.Context(), p.ID); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "deletion_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logPolicyEvent(c, models.EventTypePolicyDeleted, p, tenantID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy deleted successfully"})
+}
+
+// List handles GET /api/v1/policies
+func (h *PolicyHandler) List(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	policies, err := h.policyService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if policies == nil {
+		policies = []*models.Policy{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policies": policies,
+		"count":    len(policies),
+	})
+}
+
+// Simulate handles POST /api/v1/policies/simulate
+func (h *PolicyHandler) Simulate(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req policy.SimulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	result, err := h.policyService.Simulate(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.RespondWithError(c, http.StatusNotFound, "policy_not_found", err.Error(), nil)
+			return
+		}
+		respondWithPolicyError(c, "simulation_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// tenantPolicyParam loads the policy named by the :id path parameter and checks it belongs to the tenant.
+// It writes the error response and returns false when the policy cannot be used.
+func (h *PolicyHandler) tenantPolicyParam(c *gin.Context, tenantID uuid.UUID) (*models.Policy, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid policy ID format", nil)
+		return nil, false
+	}
+
+	p, err := h.policyService.GetByID(c.Request.Context(), id)
+	if err != nil || p.TenantID != tenantID {
+		middleware.RespondWithError(c, http.StatusNotFound, "policy_not_found",
+			"Policy not found", nil)
+		return nil, false
+	}
+
+	return p, true
+}
+
+// respondWithPolicyError reports a rejected policy or condition; condition errors get their own code
+func respondWithPolicyError(c *gin.Context, code string, err error) {
+	if strings.Contains(err.Error(), "invalid condition") {
+		code = "invalid_condition"
+	}
+	middleware.RespondWithError(c, http.StatusBadRequest, code, err.Error(), nil)
+}
+
+// logPolicyEvent records an audit event for a policy change
+func (h *PolicyHandler) logPolicyEvent(c *gin.Context, eventType string, p *models.Policy, tenantID uuid.UUID) {
+	if h.auditService == nil {
+		return
+	}
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target: &models.AuditTarget{
+			Type:       "policy",
+			ID:         p.ID,
+			Identifier: p.Name,
+		},
+		TenantID:  &tenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata: map[string]interface{}{
+			"effect":     p.Effect,
+			"permission": p.Resource + ":" + p.Action,
+			"enabled":    p.Enabled,
+		},
+		Result: models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/policy"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPolicyService is a mock implementation of policy.ServiceInterface
+type MockPolicyService struct {
+	mock.Mock
+}
+
+func (m *MockPolicyService) Create(ctx context.Context, req *policy.CreatePolicyRequest) (*models.Policy, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Policy), args.Error(1)
+}
+
+func (m *MockPolicyService) GetByID(ctx context.Context, id uuid.UUID) (*models.Policy, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Policy), args.Error(1)
+}
+
+func (m *MockPolicyService) Update(ctx context.Context, id uuid.UUID, req *policy.UpdatePolicyRequest) (*models.Policy, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Policy), args.Error(1)
+}
+
+func (m *MockPolicyService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPolicyService) List(ctx context.Context, tenantID uuid.UUID) ([]*models.Policy, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Policy), args.Error(1)
+}
+
+func (m *MockPolicyService) Applicable(ctx context.Context, tenantID uuid.UUID, resource, action string) ([]*models.Policy, error) {
+	args := m.Called(ctx, tenantID, resource, action)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Policy), args.Error(1)
+}
+
+func (m *MockPolicyService) Decide(policies []*models.Policy, input *policy.Input) *policy.Result {
+	args := m.Called(policies, input)
+	return args.Get(0).(*policy.Result)
+}
+
+func (m *MockPolicyService) Evaluate(ctx context.Context, tenantID uuid.UUID, resource, action string, input *policy.Input) (*policy.Result, error) {
+	args := m.Called(ctx, tenantID, resource, action, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*policy.Result), args.Error(1)
+}
+
+func (m *MockPolicyService) Simulate(ctx context.Context, tenantID uuid.UUID, req *policy.SimulateRequest) (*policy.Result, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*policy.Result), args.Error(1)
+}
+
+func TestPolicyHandler_Create_InvalidCondition(t *testing.T) {
+	mockService := new(MockPolicyService)
+	handler := NewPolicyHandler(mockService, nil)
+	tenantID := uuid.New()
+
+	router := newTenantTestRouter(tenantID)
+	router.POST("/api/v1/policies", handler.Create)
+
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(req *policy.CreatePolicyRequest) bool {
+		return req.TenantID == tenantID
+	})).Return(nil, fmt.Errorf("invalid condition: unexpected end of expression"))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name": "broken", "effect": "deny", "resource": "documents", "action": "read",
+		"condition": "subject.department ==",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/policies", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_condition")
+	mockService.AssertExpectations(t)
+}
+
+func TestPolicyHandler_Simulate(t *testing.T) {
+	mockService := new(MockPolicyService)
+	handler := NewPolicyHandler(mockService, nil)
+	tenantID := uuid.New()
+
+	router := newTenantTestRouter(tenantID)
+	router.POST("/api/v1/policies/simulate", handler.Simulate)
+
+	policyID := uuid.New()
+	mockService.On("Simulate", mock.Anything, tenantID, mock.MatchedBy(func(req *policy.SimulateRequest) bool {
+		return req.Resource == "documents" && req.Input.Context["ip"] == "203.0.113.7"
+	})).Return(&policy.Result{
+		Allowed: false,
+		Reason:  policy.ReasonDeniedByPolicy,
+		Policy:  &policy.PolicyRef{ID: policyID, Name: "corporate-network"},
+	}, nil)
+
+	body := []byte(`{"resource":"documents","action":"read","input":{"context":{"ip":"203.0.113.7"}}}`)
+	req, _ := http.NewRequest("POST", "/api/v1/policies/simulate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var result policy.Result
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "corporate-network", result.Policy.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestPolicyHandler_Simulate_OtherTenantPolicy(t *testing.T) {
+	mockService := new(MockPolicyService)
+	handler := NewPolicyHandler(mockService, nil)
+
+	router := newTenantTestRouter(uuid.New())
+	router.POST("/api/v1/policies/simulate", handler.Simulate)
+
+	mockService.On("Simulate", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("policy not found"))
+
+	body := []byte(`{"resource":"documents","action":"read","policy_id":"` + uuid.New().String() + `"}`)
+	req, _ := http.NewRequest("POST", "/api/v1/policies/simulate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
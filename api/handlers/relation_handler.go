@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/relation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RelationHandler handles relationship tuple HTTP requests
+type RelationHandler struct {
+	relationService relation.ServiceInterface
+	auditService    audit.ServiceInterface
+}
+
+// NewRelationHandler creates a new relationship handler
+func NewRelationHandler(relationService relation.ServiceInterface, auditService audit.ServiceInterface) *RelationHandler {
+	return &RelationHandler{
+		relationService: relationService,
+		auditService:    auditService,
+	}
+}
+
+// PutNamespace handles PUT /api/v1/relations/namespaces/:name
+func (h *RelationHandler) PutNamespace(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var config models.NamespaceConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	namespace, token, err := h.relationService.PutNamespace(c.Request.Context(), tenantID, c.Param("name"), &config)
+	if err != nil {
+		respondWithRelationError(c, err)
+		return
+	}
+
+	h.logRelationEvent(c, models.EventTypeRelationNamespaceUpdated, namespaceTarget(namespace.ID, namespace.Name), tenantID, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace":         namespace,
+		"consistency_token": token,
+	})
+}
+
+// GetNamespace handles GET /api/v1/relations/namespaces/:name
+func (h *RelationHandler) GetNamespace(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	namespace, err := h.relationService.GetNamespace(c.Request.Context(), tenantID, c.Param("name"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusNotFound, "namespace_not_found",
+			"Namespace not found", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, namespace)
+}
+
+// ListNamespaces handles GET /api/v1/relations/namespaces
+func (h *RelationHandler) ListNamespaces(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	namespaces, err := h.relationService.ListNamespaces(c.Request.Context(), tenantID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error",
+			"Failed to list namespaces", nil)
+		return
+	}
+	if namespaces == nil {
+		namespaces = []*models.RelationNamespace{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespaces": namespaces,
+		"count":      len(namespaces),
+	})
+}
+
+// DeleteNamespace handles DELETE /api/v1/relations/namespaces/:name
+func (h *RelationHandler) DeleteNamespace(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	name := c.Param("name")
+	token, err := h.relationService.DeleteNamespace(c.Request.Context(), tenantID, name)
+	if err != nil {
+		respondWithRelationError(c, err)
+		return
+	}
+
+	h.logRelationEvent(c, models.EventTypeRelationNamespaceDeleted, namespaceTarget(uuid.Nil, name), tenantID, nil)
+
+	c.JSON(http.StatusOK, gin.H{"consistency_token": token})
+}
+
+// WriteTuples handles POST /api/v1/relations/tuples/write
+func (h *RelationHandler) WriteTuples(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req relation.WriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	resp, err := h.relationService.WriteTuples(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		respondWithRelationError(c, err)
+		return
+	}
+
+	h.logRelationEvent(c, models.EventTypeRelationTuplesWritten, nil, tenantID, map[string]interface{}{
+		"writes":  tupleKeyStrings(req.Writes),
+		"deletes": tupleKeyStrings(req.Deletes),
+	})
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReadTuples handles GET /api/v1/relations/tuples
+func (h *RelationHandler) ReadTuples(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req relation.ReadRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Invalid query parameters", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	tuples, err := h.relationService.ReadTuples(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		respondWithRelationError(c, err)
+		return
+	}
+	if tuples == nil {
+		tuples = []*models.RelationTuple{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tuples": tuples,
+		"count":  len(tuples),
+	})
+}
+
+// Check handles POST /api/v1/relations/check
+func (h *RelationHandler) Check(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req relation.CheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	resp, err := h.relationService.Check(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		respondWithRelationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Expand handles POST /api/v1/relations/expand
+func (h *RelationHandler) Expand(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req relation.ExpandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	resp, err := h.relationService.Expand(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		respondWithRelationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListObjects handles POST /api/v1/relations/list-objects
+func (h *RelationHandler) ListObjects(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req relation.ListObjectsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	resp, err := h.relationService.ListObjects(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		respondWithRelationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// respondWithRelationError maps relationship service errors to responses
+func respondWithRelationError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"), strings.Contains(msg, "is not defined"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", msg, nil)
+	case strings.Contains(msg, "consistency token"):
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_consistency_token", msg, nil)
+	case strings.Contains(msg, "maximum depth"):
+		middleware.RespondWithError(c, http.StatusUnprocessableEntity, "depth_exceeded", msg, nil)
+	case strings.HasPrefix(msg, "failed to"):
+		middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error", msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request", msg, nil)
+	}
+}
+
+// namespaceTarget is the audit target of a namespace change
+func namespaceTarget(id uuid.UUID, name string) *models.AuditTarget {
+	return &models.AuditTarget{Type: "relation_namespace", ID: id, Identifier: name}
+}
+
+// tupleKeyStrings renders tuple keys for audit metadata
+func tupleKeyStrings(keys []relation.TupleKey) []string {
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, key.Object+"#"+key.Relation+"@"+key.Subject)
+	}
+	return out
+}
+
+// logRelationEvent records an audit event for a relationship change
+func (h *RelationHandler) logRelationEvent(c *gin.Context, eventType string, target *models.AuditTarget, tenantID uuid.UUID, metadata map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target:    target,
+		TenantID:  &tenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata:  metadata,
+		Result:    models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/relation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRelationService is a mock implementation of relation.ServiceInterface
+type MockRelationService struct {
+	mock.Mock
+}
+
+func (m *MockRelationService) PutNamespace(ctx context.Context, tenantID uuid.UUID, name string, config *models.NamespaceConfig) (*models.RelationNamespace, string, error) {
+	args := m.Called(ctx, tenantID, name, config)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*models.RelationNamespace), args.String(1), args.Error(2)
+}
+
+func (m *MockRelationService) GetNamespace(ctx context.Context, tenantID uuid.UUID, name string) (*models.RelationNamespace, error) {
+	args := m.Called(ctx, tenantID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RelationNamespace), args.Error(1)
+}
+
+func (m *MockRelationService) ListNamespaces(ctx context.Context, tenantID uuid.UUID) ([]*models.RelationNamespace, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RelationNamespace), args.Error(1)
+}
+
+func (m *MockRelationService) DeleteNamespace(ctx context.Context, tenantID uuid.UUID, name string) (string, error) {
+	args := m.Called(ctx, tenantID, name)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRelationService) WriteTuples(ctx context.Context, tenantID uuid.UUID, req *relation.WriteRequest) (*relation.WriteResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*relation.WriteResponse), args.Error(1)
+}
+
+func (m *MockRelationService) ReadTuples(ctx context.Context, tenantID uuid.UUID, req *relation.ReadRequest) ([]*models.RelationTuple, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RelationTuple), args.Error(1)
+}
+
+func (m *MockRelationService) Check(ctx context.Context, tenantID uuid.UUID, req *relation.CheckRequest) (*relation.CheckResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*relation.CheckResponse), args.Error(1)
+}
+
+func (m *MockRelationService) Expand(ctx context.Context, tenantID uuid.UUID, req *relation.ExpandRequest) (*relation.ExpandResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*relation.ExpandResponse), args.Error(1)
+}
+
+func (m *MockRelationService) ListObjects(ctx context.Context, tenantID uuid.UUID, req *relation.ListObjectsRequest) (*relation.ListObjectsResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*relation.ListObjectsResponse), args.Error(1)
+}
+
+func TestRelationHandler_WriteTuples(t *testing.T) {
+	mockService := new(MockRelationService)
+	handler := NewRelationHandler(mockService, nil)
+	tenantID := uuid.New()
+
+	router := newTenantTestRouter(tenantID)
+	router.POST("/api/v1/relations/tuples/write", handler.WriteTuples)
+
+	mockService.On("WriteTuples", mock.Anything, tenantID, mock.MatchedBy(func(req *relation.WriteRequest) bool {
+		return len(req.Writes) == 1 && req.Writes[0].Subject == "group:eng#member"
+	})).Return(&relation.WriteResponse{ConsistencyToken: "token-1"}, nil)
+
+	body := []byte(`{"writes":[{"object":"folder:42","relation":"editor","subject":"group:eng#member"}]}`)
+	req, _ := http.NewRequest("POST", "/api/v1/relations/tuples/write", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "token-1")
+	mockService.AssertExpectations(t)
+}
+
+func TestRelationHandler_Check_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("namespace drive not found"), http.StatusNotFound, "not_found"},
+		{fmt.Errorf("invalid consistency token"), http.StatusBadRequest, "invalid_consistency_token"},
+		{fmt.Errorf(`invalid object "doc": expected namespace:id`), http.StatusBadRequest, "invalid_request"},
+	}
+
+	for _, tt := range tests {
+		mockService := new(MockRelationService)
+		handler := NewRelationHandler(mockService, nil)
+		router := newTenantTestRouter(uuid.New())
+		router.POST("/api/v1/relations/check", handler.Check)
+
+		mockService.On("Check", mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.err)
+
+		body := []byte(`{"object":"drive:1","relation":"viewer","subject":"user:alice"}`)
+		req, _ := http.NewRequest("POST", "/api/v1/relations/check", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.status, w.Code, tt.err.Error())
+		assert.Contains(t, w.Body.String(), tt.code)
+	}
+}
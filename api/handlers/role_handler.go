@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -54,6 +55,10 @@ func (h *RoleHandler) Create(c *gin.Context) {
 	// Set tenant ID from context
 	req.TenantID = tenantID
 
+	if !h.checkRoleOwner(c, tenantID, req.OwnerID) {
+		return
+	}
+
 	createdRole, err := h.roleService.Create(c.Request.Context(), &req)
 	if err != nil {
 		middleware.RespondWithError(c, http.StatusBadRequest, "creation_failed",
@@ -144,6 +149,10 @@ func (h *RoleHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !h.checkRoleOwner(c, tenantID, req.OwnerID) {
+		return
+	}
+
 	updatedRole, err := h.roleService.Update(c.Request.Context(), id, &req)
 	if err != nil {
 		middleware.RespondWithError(c, http.StatusBadRequest, "update_failed",
@@ -493,6 +502,23 @@ func (h *RoleHandler) AssignRoleToUser(c *gin.Context) {
 		return
 	}
 
+	// Body is optional; an expires_at makes the assignment time-bound
+	var body struct {
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+				"Request validation failed", middleware.FormatValidationErrors(err))
+			return
+		}
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_expires_at",
+			"expires_at must be in the future", nil)
+		return
+	}
+
 	// Verify role belongs to tenant
 	existingRole, err := h.roleService.GetByID(c.Request.Context(), roleID)
 	if err != nil {
@@ -506,14 +532,29 @@ func (h *RoleHandler) AssignRoleToUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.roleService.AssignRoleToUser(c.Request.Context(), userID, roleID); err != nil {
+	actor, actorErr := extractActorFromContext(c)
+	if body.ExpiresAt != nil {
+		var assignedBy *uuid.UUID
+		if actorErr == nil {
+			assignedBy = &actor.UserID
+		}
+		err = h.roleService.AssignRoleToUserUntil(c.Request.Context(), userID, roleID, *body.ExpiresAt, assignedBy)
+	} else {
+		err = h.roleService.AssignRoleToUser(c.Request.Context(), userID, roleID)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "separation of duties violation") {
+			middleware.RespondWithError(c, http.StatusConflict, "sod_violation",
+				err.Error(), nil)
+			return
+		}
 		middleware.RespondWithError(c, http.StatusBadRequest, "assignment_failed",
 			err.Error(), nil)
 		return
 	}
 
 	// Log audit event for tenant role assignment
-	if actor, err := extractActorFromContext(c); err == nil {
+	if actorErr == nil {
 		sourceIP, userAgent := extractSourceInfo(c)
 		// Get user info for target
 		user, err := h.userRepo.GetByID(c.Request.Context(), userID)
@@ -523,11 +564,15 @@ func (h *RoleHandler) AssignRoleToUser(c *gin.Context) {
 				ID:         userID,
 				Identifier: user.Username,
 			}
-			_ = h.auditService.LogRoleAssigned(c.Request.Context(), actor, target, &tenantID, sourceIP, userAgent, map[string]interface{}{
+			metadata := map[string]interface{}{
 				"role_id":   roleID.String(),
 				"role_name": existingRole.Name,
 				"is_system": false,
-			})
+			}
+			if body.ExpiresAt != nil {
+				metadata["expires_at"] = body.ExpiresAt.UTC().Format(time.RFC3339)
+			}
+			_ = h.auditService.LogRoleAssigned(c.Request.Context(), actor, target, &tenantID, sourceIP, userAgent, metadata)
 		}
 	}
 
@@ -771,3 +816,172 @@ func (h *RoleHandler) RemovePermissionFromRole(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Permission removed successfully"})
 }
 
+
+// GetParentRoles handles GET /api/v1/roles/:id/parents
+func (h *RoleHandler) GetParentRoles(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	existingRole, ok := h.tenantRoleParam(c, tenantID, "id")
+	if !ok {
+		return
+	}
+
+	parents, err := h.roleService.GetParentRoles(c.Request.Context(), existingRole.ID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if parents == nil {
+		parents = []*models.Role{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"parents": parents})
+}
+
+// AddParentRole handles POST /api/v1/roles/:id/parents/:parent_id
+func (h *RoleHandler) AddParentRole(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	existingRole, ok := h.tenantRoleParam(c, tenantID, "id")
+	if !ok {
+		return
+	}
+	parentRole, ok := h.tenantRoleParam(c, tenantID, "parent_id")
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.AddParentRole(c.Request.Context(), existingRole.ID, parentRole.ID); err != nil {
+		if strings.Contains(err.Error(), "cycle") {
+			middleware.RespondWithError(c, http.StatusConflict, "role_hierarchy_cycle",
+				err.Error(), nil)
+			return
+		}
+		if strings.Contains(err.Error(), "separation of duties violation") {
+			middleware.RespondWithError(c, http.StatusConflict, "sod_violation",
+				err.Error(), nil)
+			return
+		}
+		middleware.RespondWithError(c, http.StatusBadRequest, "hierarchy_update_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logRoleHierarchyChange(c, existingRole, tenantID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Parent role added successfully"})
+}
+
+// RemoveParentRole handles DELETE /api/v1/roles/:id/parents/:parent_id
+func (h *RoleHandler) RemoveParentRole(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	existingRole, ok := h.tenantRoleParam(c, tenantID, "id")
+	if !ok {
+		return
+	}
+
+	parentID, err := uuid.Parse(c.Param("parent_id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_role_id",
+			"Invalid parent role ID format", nil)
+		return
+	}
+
+	if err := h.roleService.RemoveParentRole(c.Request.Context(), existingRole.ID, parentID); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "hierarchy_update_failed",
+			err.Error(), nil)
+		return
+	}
+
+	h.logRoleHierarchyChange(c, existingRole, tenantID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Parent role removed successfully"})
+}
+
+// GetEffectivePermissions handles GET /api/v1/roles/:id/effective-permissions
+func (h *RoleHandler) GetEffectivePermissions(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	existingRole, ok := h.tenantRoleParam(c, tenantID, "id")
+	if !ok {
+		return
+	}
+
+	permissions, err := h.roleService.GetEffectivePermissions(c.Request.Context(), existingRole.ID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permissions": permissions})
+}
+
+// tenantRoleParam loads the role named by a path parameter and checks it belongs to the tenant.
+// It writes the error response and returns false when the role cannot be used.
+func (h *RoleHandler) tenantRoleParam(c *gin.Context, tenantID uuid.UUID, param string) (*models.Role, bool) {
+	roleID, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_role_id",
+			"Invalid role ID format", nil)
+		return nil, false
+	}
+
+	existingRole, err := h.roleService.GetByID(c.Request.Context(), roleID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusNotFound, "role_not_found",
+			err.Error(), nil)
+		return nil, false
+	}
+	if existingRole.TenantID != tenantID {
+		middleware.RespondWithError(c, http.StatusForbidden, "access_denied",
+			"Role does not belong to this tenant", nil)
+		return nil, false
+	}
+
+	return existingRole, true
+}
+
+// logRoleHierarchyChange records a change to a role's parents as a role update
+func (h *RoleHandler) logRoleHierarchyChange(c *gin.Context, changed *models.Role, tenantID uuid.UUID) {
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+	sourceIP, userAgent := extractSourceInfo(c)
+	target := &models.AuditTarget{
+		Type:       "role",
+		ID:         changed.ID,
+		Identifier: changed.Name,
+	}
+	_ = h.auditService.LogRoleUpdated(c.Request.Context(), actor, target, &tenantID, sourceIP, userAgent)
+}
+
+// checkRoleOwner verifies that a role owner, if given, is a user in the tenant.
+// It writes the error response and returns false when the owner cannot be used.
+func (h *RoleHandler) checkRoleOwner(c *gin.Context, tenantID uuid.UUID, ownerID *uuid.UUID) bool {
+	if ownerID == nil {
+		return true
+	}
+	owner, err := h.userRepo.GetByID(c.Request.Context(), *ownerID)
+	if err != nil || owner.TenantID == nil || *owner.TenantID != tenantID {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_owner",
+			"Role owner must be a user in this tenant", nil)
+		return false
+	}
+	return true
+}
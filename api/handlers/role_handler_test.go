@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/arauth-identity/iam/identity/models"
 	"github.com/arauth-identity/iam/identity/role"
@@ -72,11 +74,47 @@ func (m *MockRoleService) AssignRoleToUser(ctx context.Context, userID, roleID u
 	return args.Error(0)
 }
 
+func (m *MockRoleService) AssignRoleToUserUntil(ctx context.Context, userID, roleID uuid.UUID, expiresAt time.Time, assignedBy *uuid.UUID) error {
+	args := m.Called(ctx, userID, roleID, expiresAt, assignedBy)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	args := m.Called(ctx, tenantID, userID, roleIDs)
+	return args.Error(0)
+}
+
 func (m *MockRoleService) RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error {
 	args := m.Called(ctx, userID, roleID)
 	return args.Error(0)
 }
 
+func (m *MockRoleService) GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	args := m.Called(ctx, roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) AddParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	args := m.Called(ctx, roleID, parentRoleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) RemoveParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	args := m.Called(ctx, roleID, parentRoleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) GetEffectivePermissions(ctx context.Context, roleID uuid.UUID) ([]*role.EffectivePermission, error) {
+	args := m.Called(ctx, roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*role.EffectivePermission), args.Error(1)
+}
+
 func (m *MockRoleService) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]*models.Permission, error) {
 	args := m.Called(ctx, roleID)
 	if args.Get(0) == nil {
@@ -194,3 +232,135 @@ func TestRoleHandler_List(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestRoleHandler_AddParentRole_Cycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockRoleService)
+	handler := NewRoleHandler(mockService, nil, nil, nil, nil)
+
+	tenantID := uuid.New()
+	child := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "viewer"}
+	parent := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "admin"}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Next()
+	})
+	router.POST("/api/v1/roles/:id/parents/:parent_id", handler.AddParentRole)
+
+	mockService.On("GetByID", mock.Anything, child.ID).Return(child, nil)
+	mockService.On("GetByID", mock.Anything, parent.ID).Return(parent, nil)
+	mockService.On("AddParentRole", mock.Anything, child.ID, parent.ID).
+		Return(fmt.Errorf("role hierarchy cycle: admin already inherits from viewer"))
+
+	req, _ := http.NewRequest("POST", "/api/v1/roles/"+child.ID.String()+"/parents/"+parent.ID.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "role_hierarchy_cycle")
+	mockService.AssertExpectations(t)
+}
+
+func TestRoleHandler_GetEffectivePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockRoleService)
+	handler := NewRoleHandler(mockService, nil, nil, nil, nil)
+
+	tenantID := uuid.New()
+	editor := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "editor"}
+	viewerID := uuid.New()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Next()
+	})
+	router.GET("/api/v1/roles/:id/effective-permissions", handler.GetEffectivePermissions)
+
+	mockService.On("GetByID", mock.Anything, editor.ID).Return(editor, nil)
+	mockService.On("GetEffectivePermissions", mock.Anything, editor.ID).Return([]*role.EffectivePermission{
+		{Permission: &models.Permission{Resource: "documents", Action: "update"}, SourceRole: role.RoleRef{ID: editor.ID, Name: "editor"}},
+		{Permission: &models.Permission{Resource: "documents", Action: "read"}, Inherited: true, SourceRole: role.RoleRef{ID: viewerID, Name: "viewer"}},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/roles/"+editor.ID.String()+"/effective-permissions", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Permissions []role.EffectivePermission `json:"permissions"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Permissions, 2)
+	assert.True(t, body.Permissions[1].Inherited)
+	assert.Equal(t, "viewer", body.Permissions[1].SourceRole.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestRoleHandler_AssignRoleToUser_WithExpiry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockRoleService)
+	handler := NewRoleHandler(mockService, new(MockSystemRoleRepository), nil, nil, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	existing := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "operator"}
+	expiresAt := time.Now().Add(4 * time.Hour).UTC().Truncate(time.Second)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Next()
+	})
+	router.POST("/api/v1/users/:id/roles/:role_id", handler.AssignRoleToUser)
+
+	mockService.On("GetByID", mock.Anything, existing.ID).Return(existing, nil)
+	mockService.On("AssignRoleToUserUntil", mock.Anything, userID, existing.ID, mock.MatchedBy(func(t time.Time) bool {
+		return t.Equal(expiresAt)
+	}), (*uuid.UUID)(nil)).Return(nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"expires_at": expiresAt.Format(time.RFC3339)})
+	req, _ := http.NewRequest("POST", "/api/v1/users/"+userID.String()+"/roles/"+existing.ID.String(), bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "AssignRoleToUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRoleHandler_AssignRoleToUser_PastExpiry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockRoleService)
+	handler := NewRoleHandler(mockService, new(MockSystemRoleRepository), nil, nil, nil)
+
+	tenantID := uuid.New()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Next()
+	})
+	router.POST("/api/v1/users/:id/roles/:role_id", handler.AssignRoleToUser)
+
+	body, _ := json.Marshal(map[string]interface{}{"expires_at": time.Now().Add(-time.Hour).Format(time.RFC3339)})
+	req, _ := http.NewRequest("POST", "/api/v1/users/"+uuid.New().String()+"/roles/"+uuid.New().String(), bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_expires_at")
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/connector"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SCIMConnectorHandler handles management of outbound SCIM provisioning connectors
+type SCIMConnectorHandler struct {
+	connectorService connector.ServiceInterface
+	auditService     audit.ServiceInterface
+}
+
+// NewSCIMConnectorHandler creates a new SCIM connector handler
+func NewSCIMConnectorHandler(connectorService connector.ServiceInterface, auditService audit.ServiceInterface) *SCIMConnectorHandler {
+	return &SCIMConnectorHandler{
+		connectorService: connectorService,
+		auditService:     auditService,
+	}
+}
+
+// Create handles POST /api/v1/scim/connectors
+func (h *SCIMConnectorHandler) Create(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req connector.CreateConnectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+	req.TenantID = tenantID
+	if actor, err := extractActorFromContext(c); err == nil {
+		req.CreatedBy = &actor.UserID
+	}
+
+	created, err := h.connectorService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondWithSCIMConnectorError(c, "creation_failed", err)
+		return
+	}
+
+	h.logConnectorEvent(c, models.EventTypeSCIMConnectorCreated, created, nil)
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// List handles GET /api/v1/scim/connectors
+func (h *SCIMConnectorHandler) List(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	connectors, err := h.connectorService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if connectors == nil {
+		connectors = []*models.SCIMConnector{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"connectors": connectors,
+		"count":      len(connectors),
+	})
+}
+
+// GetByID handles GET /api/v1/scim/connectors/:id
+func (h *SCIMConnectorHandler) GetByID(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimConnectorIDParam(c)
+	if !ok {
+		return
+	}
+
+	found, err := h.connectorService.GetByID(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSCIMConnectorError(c, "get_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, found)
+}
+
+// Update handles PUT /api/v1/scim/connectors/:id
+func (h *SCIMConnectorHandler) Update(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimConnectorIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req connector.UpdateConnectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	updated, err := h.connectorService.Update(c.Request.Context(), tenantID, id, &req)
+	if err != nil {
+		respondWithSCIMConnectorError(c, "update_failed", err)
+		return
+	}
+
+	h.logConnectorEvent(c, models.EventTypeSCIMConnectorUpdated, updated, map[string]interface{}{
+		"credential_rotated": req.Credential != nil,
+	})
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete handles DELETE /api/v1/scim/connectors/:id
+func (h *SCIMConnectorHandler) Delete(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimConnectorIDParam(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.connectorService.Delete(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSCIMConnectorError(c, "deletion_failed", err)
+		return
+	}
+
+	h.logConnectorEvent(c, models.EventTypeSCIMConnectorDeleted, deleted, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "SCIM connector deleted successfully"})
+}
+
+// Test handles POST /api/v1/scim/connectors/:id/test
+func (h *SCIMConnectorHandler) Test(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimConnectorIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.connectorService.Test(c.Request.Context(), tenantID, id); err != nil {
+		respondWithSCIMConnectorError(c, "test_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Connection test succeeded"})
+}
+
+// Status handles GET /api/v1/scim/connectors/:id/status
+func (h *SCIMConnectorHandler) Status(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimConnectorIDParam(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.connectorService.Status(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSCIMConnectorError(c, "status_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Operations handles GET /api/v1/scim/connectors/:id/operations
+func (h *SCIMConnectorHandler) Operations(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimConnectorIDParam(c)
+	if !ok {
+		return
+	}
+
+	var status *string
+	if s := c.Query("status"); s != "" {
+		status = &s
+	}
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+			if limit > 500 {
+				limit = 500
+			}
+		}
+	}
+
+	ops, err := h.connectorService.Operations(c.Request.Context(), tenantID, id, status, limit)
+	if err != nil {
+		respondWithSCIMConnectorError(c, "list_failed", err)
+		return
+	}
+	if ops == nil {
+		ops = []*models.SCIMSyncOperation{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"operations": ops,
+		"count":      len(ops),
+	})
+}
+
+// Retry handles POST /api/v1/scim/connectors/:id/retry
+func (h *SCIMConnectorHandler) Retry(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimConnectorIDParam(c)
+	if !ok {
+		return
+	}
+
+	retried, err := h.connectorService.RetryFailed(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSCIMConnectorError(c, "retry_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"retried": retried})
+}
+
+// Reconcile handles POST /api/v1/scim/connectors/:id/reconcile
+func (h *SCIMConnectorHandler) Reconcile(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimConnectorIDParam(c)
+	if !ok {
+		return
+	}
+
+	queued, err := h.connectorService.Reconcile(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSCIMConnectorError(c, "reconcile_failed", err)
+		return
+	}
+
+	if reconciled, err := h.connectorService.GetByID(c.Request.Context(), tenantID, id); err == nil {
+		h.logConnectorEvent(c, models.EventTypeSCIMConnectorReconciled, reconciled, map[string]interface{}{
+			"queued": queued,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queued": queued})
+}
+
+// scimConnectorIDParam parses the :id path parameter.
+// It writes the error response and returns false when the ID is malformed.
+func scimConnectorIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid SCIM connector ID format", nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondWithSCIMConnectorError maps connector service errors to HTTP statuses
+func respondWithSCIMConnectorError(c *gin.Context, code string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "connector not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", msg, nil)
+	case strings.Contains(msg, "already exists"):
+		middleware.RespondWithError(c, http.StatusConflict, "scim_connector_exists", msg, nil)
+	case strings.Contains(msg, "connection test failed"):
+		middleware.RespondWithError(c, http.StatusBadGateway, code, msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusBadRequest, code, msg, nil)
+	}
+}
+
+// logConnectorEvent records an audit event for a connector change
+func (h *SCIMConnectorHandler) logConnectorEvent(c *gin.Context, eventType string, target *models.SCIMConnector, metadata map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["base_url"] = target.BaseURL
+	metadata["enabled"] = target.Enabled
+
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target: &models.AuditTarget{
+			Type:       "scim_connector",
+			ID:         target.ID,
+			Identifier: target.Name,
+		},
+		TenantID:  &target.TenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata:  metadata,
+		Result:    models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/connector"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSCIMConnectorService is a mock implementation of connector.ServiceInterface
+type MockSCIMConnectorService struct {
+	mock.Mock
+}
+
+func (m *MockSCIMConnectorService) Create(ctx context.Context, req *connector.CreateConnectorRequest) (*models.SCIMConnector, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMConnector), args.Error(1)
+}
+
+func (m *MockSCIMConnectorService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnector, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMConnector), args.Error(1)
+}
+
+func (m *MockSCIMConnectorService) List(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMConnector, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SCIMConnector), args.Error(1)
+}
+
+func (m *MockSCIMConnectorService) Update(ctx context.Context, tenantID, id uuid.UUID, req *connector.UpdateConnectorRequest) (*models.SCIMConnector, error) {
+	args := m.Called(ctx, tenantID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMConnector), args.Error(1)
+}
+
+func (m *MockSCIMConnectorService) Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnector, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMConnector), args.Error(1)
+}
+
+func (m *MockSCIMConnectorService) Test(ctx context.Context, tenantID, id uuid.UUID) error {
+	args := m.Called(ctx, tenantID, id)
+	return args.Error(0)
+}
+
+func (m *MockSCIMConnectorService) Status(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnectorStatus, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMConnectorStatus), args.Error(1)
+}
+
+func (m *MockSCIMConnectorService) Operations(ctx context.Context, tenantID, id uuid.UUID, status *string, limit int) ([]*models.SCIMSyncOperation, error) {
+	args := m.Called(ctx, tenantID, id, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SCIMSyncOperation), args.Error(1)
+}
+
+func (m *MockSCIMConnectorService) RetryFailed(ctx context.Context, tenantID, id uuid.UUID) (int, error) {
+	args := m.Called(ctx, tenantID, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSCIMConnectorService) Reconcile(ctx context.Context, tenantID, id uuid.UUID) (int, error) {
+	args := m.Called(ctx, tenantID, id)
+	return args.Int(0), args.Error(1)
+}
+
+func TestSCIMConnectorHandler_Create(t *testing.T) {
+	mockService := new(MockSCIMConnectorService)
+	handler := NewSCIMConnectorHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	router := newClaimsTestRouter(tenantID, userID, "scim_connectors:manage")
+	router.POST("/api/v1/scim/connectors", handler.Create)
+
+	created := &models.SCIMConnector{
+		ID:                  uuid.New(),
+		TenantID:            tenantID,
+		Name:                "Slack",
+		BaseURL:             "https://api.slack.com/scim/v2",
+		AuthType:            models.SCIMConnectorAuthBearer,
+		CredentialEncrypted: "ciphertext",
+		Enabled:             true,
+	}
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(req *connector.CreateConnectorRequest) bool {
+		return req.TenantID == tenantID && *req.CreatedBy == userID && req.Credential == "xoxp-token"
+	})).Return(created, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":       "Slack",
+		"base_url":   "https://api.slack.com/scim/v2",
+		"auth_type":  "bearer",
+		"credential": "xoxp-token",
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/scim/connectors", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "ciphertext", "credentials are never returned")
+	mockService.AssertExpectations(t)
+}
+
+func TestSCIMConnectorHandler_Create_MissingCredential(t *testing.T) {
+	handler := NewSCIMConnectorHandler(new(MockSCIMConnectorService), nil)
+
+	router := newClaimsTestRouter(uuid.New(), uuid.New(), "scim_connectors:manage")
+	router.POST("/api/v1/scim/connectors", handler.Create)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":      "Slack",
+		"base_url":  "https://api.slack.com/scim/v2",
+		"auth_type": "bearer",
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/scim/connectors", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSCIMConnectorHandler_Test_Failure(t *testing.T) {
+	mockService := new(MockSCIMConnectorService)
+	handler := NewSCIMConnectorHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	id := uuid.New()
+	router := newClaimsTestRouter(tenantID, uuid.New(), "scim_connectors:manage")
+	router.POST("/api/v1/scim/connectors/:id/test", handler.Test)
+
+	mockService.On("Test", mock.Anything, tenantID, id).
+		Return(fmt.Errorf("connection test failed: downstream SCIM service returned 401"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/scim/connectors/"+id.String()+"/test", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "401")
+}
+
+func TestSCIMConnectorHandler_Status_NotFound(t *testing.T) {
+	mockService := new(MockSCIMConnectorService)
+	handler := NewSCIMConnectorHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	id := uuid.New()
+	router := newClaimsTestRouter(tenantID, uuid.New(), "scim_connectors:read")
+	router.GET("/api/v1/scim/connectors/:id/status", handler.Status)
+
+	mockService.On("Status", mock.Anything, tenantID, id).Return(nil, fmt.Errorf("SCIM connector not found"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/scim/connectors/"+id.String()+"/status", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSCIMConnectorHandler_Operations(t *testing.T) {
+	mockService := new(MockSCIMConnectorService)
+	handler := NewSCIMConnectorHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	id := uuid.New()
+	router := newClaimsTestRouter(tenantID, uuid.New(), "scim_connectors:read")
+	router.GET("/api/v1/scim/connectors/:id/operations", handler.Operations)
+
+	failed := models.SCIMSyncStatusFailed
+	mockService.On("Operations", mock.Anything, tenantID, id, &failed, 500).
+		Return([]*models.SCIMSyncOperation{{ID: uuid.New(), Status: failed}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/scim/connectors/"+id.String()+"/operations?status=failed&limit=1000", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Count int `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Count)
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -14,16 +16,20 @@ import (
 type SCIMHandler struct {
 	provisioningService scim.ProvisioningServiceInterface
 	tokenService        scim.TokenServiceInterface
+	schemaService       scim.SchemaServiceInterface
 }
 
-// NewSCIMHandler creates a new SCIM handler
+// NewSCIMHandler creates a new SCIM handler.
+// schemaService may be nil, in which case discovery lists no tenant-defined schemas.
 func NewSCIMHandler(
 	provisioningService scim.ProvisioningServiceInterface,
 	tokenService scim.TokenServiceInterface,
+	schemaService scim.SchemaServiceInterface,
 ) *SCIMHandler {
 	return &SCIMHandler{
 		provisioningService: provisioningService,
 		tokenService:        tokenService,
+		schemaService:       schemaService,
 	}
 }
 
@@ -68,6 +74,7 @@ func (h *SCIMHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", createdUser.Meta.Version)
 	c.JSON(http.StatusCreated, createdUser)
 }
 
@@ -94,7 +101,7 @@ func (h *SCIMHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, scimUser)
+	h.respondWithResource(c, scimUser, scimUser.Meta.Version)
 }
 
 // ListUsers handles GET /scim/v2/Users
@@ -112,6 +119,8 @@ func (h *SCIMHandler) ListUsers(c *gin.Context) {
 	// Parse SCIM query parameters
 	filters := &scim.UserFilters{
 		Filter:     c.Query("filter"),
+		SortBy:     c.Query("sortBy"),
+		SortOrder:  c.Query("sortOrder"),
 		StartIndex: 1,
 		Count:      100,
 	}
@@ -133,11 +142,7 @@ func (h *SCIMHandler) ListUsers(c *gin.Context) {
 
 	users, total, err := h.provisioningService.ListUsers(c.Request.Context(), tenantID, filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.SCIMError{
-			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
-			Detail:  err.Error(),
-			Status:  "500",
-		})
+		respondWithSCIMError(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -149,8 +154,13 @@ func (h *SCIMHandler) ListUsers(c *gin.Context) {
 		Resources:    make([]interface{}, len(users)),
 	}
 
+	attributes := scim.ParseAttributeList(c.Query("attributes"))
+	excluded := scim.ParseAttributeList(c.Query("excludedAttributes"))
 	for i, user := range users {
-		response.Resources[i] = user
+		if response.Resources[i], err = scim.Project(user, attributes, excluded); err != nil {
+			respondWithSCIMError(c, http.StatusInternalServerError, err)
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -169,6 +179,9 @@ func (h *SCIMHandler) UpdateUser(c *gin.Context) {
 	}
 
 	userID := c.Param("id")
+	if !h.userPreconditionMet(c, tenantID, userID) {
+		return
+	}
 
 	var scimUser models.SCIMUser
 	if err := c.ShouldBindJSON(&scimUser); err != nil {
@@ -190,7 +203,44 @@ func (h *SCIMHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedUser)
+	h.respondWithResource(c, updatedUser, updatedUser.Meta.Version)
+}
+
+// PatchUser handles PATCH /scim/v2/Users/:id
+func (h *SCIMHandler) PatchUser(c *gin.Context) {
+	tenantID, ok := h.getTenantIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.SCIMError{
+			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+			Detail:  "Tenant context required",
+			Status:  "401",
+		})
+		return
+	}
+
+	userID := c.Param("id")
+	if !h.userPreconditionMet(c, tenantID, userID) {
+		return
+	}
+
+	var req scim.PatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SCIMError{
+			Schemas:  []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+			Detail:   "Invalid request body",
+			Status:   "400",
+			SCIMType: "invalidSyntax",
+		})
+		return
+	}
+
+	patchedUser, err := h.provisioningService.PatchUser(c.Request.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondWithSCIMError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.respondWithResource(c, patchedUser, patchedUser.Meta.Version)
 }
 
 // DeleteUser handles DELETE /scim/v2/Users/:id
@@ -206,6 +256,9 @@ func (h *SCIMHandler) DeleteUser(c *gin.Context) {
 	}
 
 	userID := c.Param("id")
+	if !h.userPreconditionMet(c, tenantID, userID) {
+		return
+	}
 
 	err := h.provisioningService.DeleteUser(c.Request.Context(), tenantID, userID)
 	if err != nil {
@@ -252,6 +305,7 @@ func (h *SCIMHandler) CreateGroup(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", createdGroup.Meta.Version)
 	c.JSON(http.StatusCreated, createdGroup)
 }
 
@@ -278,7 +332,7 @@ func (h *SCIMHandler) GetGroup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, scimGroup)
+	h.respondWithResource(c, scimGroup, scimGroup.Meta.Version)
 }
 
 // ListGroups handles GET /scim/v2/Groups
@@ -295,6 +349,8 @@ func (h *SCIMHandler) ListGroups(c *gin.Context) {
 
 	filters := &scim.GroupFilters{
 		Filter:     c.Query("filter"),
+		SortBy:     c.Query("sortBy"),
+		SortOrder:  c.Query("sortOrder"),
 		StartIndex: 1,
 		Count:      100,
 	}
@@ -316,11 +372,7 @@ func (h *SCIMHandler) ListGroups(c *gin.Context) {
 
 	groups, total, err := h.provisioningService.ListGroups(c.Request.Context(), tenantID, filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.SCIMError{
-			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
-			Detail:  err.Error(),
-			Status:  "500",
-		})
+		respondWithSCIMError(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -332,8 +384,13 @@ func (h *SCIMHandler) ListGroups(c *gin.Context) {
 		Resources:    make([]interface{}, len(groups)),
 	}
 
+	attributes := scim.ParseAttributeList(c.Query("attributes"))
+	excluded := scim.ParseAttributeList(c.Query("excludedAttributes"))
 	for i, group := range groups {
-		response.Resources[i] = group
+		if response.Resources[i], err = scim.Project(group, attributes, excluded); err != nil {
+			respondWithSCIMError(c, http.StatusInternalServerError, err)
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -352,6 +409,9 @@ func (h *SCIMHandler) UpdateGroup(c *gin.Context) {
 	}
 
 	groupID := c.Param("id")
+	if !h.groupPreconditionMet(c, tenantID, groupID) {
+		return
+	}
 
 	var scimGroup models.SCIMGroup
 	if err := c.ShouldBindJSON(&scimGroup); err != nil {
@@ -373,7 +433,44 @@ func (h *SCIMHandler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedGroup)
+	h.respondWithResource(c, updatedGroup, updatedGroup.Meta.Version)
+}
+
+// PatchGroup handles PATCH /scim/v2/Groups/:id
+func (h *SCIMHandler) PatchGroup(c *gin.Context) {
+	tenantID, ok := h.getTenantIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.SCIMError{
+			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+			Detail:  "Tenant context required",
+			Status:  "401",
+		})
+		return
+	}
+
+	groupID := c.Param("id")
+	if !h.groupPreconditionMet(c, tenantID, groupID) {
+		return
+	}
+
+	var req scim.PatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SCIMError{
+			Schemas:  []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+			Detail:   "Invalid request body",
+			Status:   "400",
+			SCIMType: "invalidSyntax",
+		})
+		return
+	}
+
+	patchedGroup, err := h.provisioningService.PatchGroup(c.Request.Context(), tenantID, groupID, &req)
+	if err != nil {
+		respondWithSCIMError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.respondWithResource(c, patchedGroup, patchedGroup.Meta.Version)
 }
 
 // DeleteGroup handles DELETE /scim/v2/Groups/:id
@@ -389,6 +486,9 @@ func (h *SCIMHandler) DeleteGroup(c *gin.Context) {
 	}
 
 	groupID := c.Param("id")
+	if !h.groupPreconditionMet(c, tenantID, groupID) {
+		return
+	}
 
 	err := h.provisioningService.DeleteGroup(c.Request.Context(), tenantID, groupID)
 	if err != nil {
@@ -403,7 +503,8 @@ func (h *SCIMHandler) DeleteGroup(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// BulkOperations handles POST /scim/v2/Bulk
+// BulkOperations handles POST /scim/v2/Bulk. With ?atomic=true a failure
+// rolls back every operation; see scim.Bulk.
 func (h *SCIMHandler) BulkOperations(c *gin.Context) {
 	tenantID, ok := h.getTenantIDFromContext(c)
 	if !ok {
@@ -415,44 +516,113 @@ func (h *SCIMHandler) BulkOperations(c *gin.Context) {
 		return
 	}
 
-	var bulkRequest struct {
-		Schemas    []string              `json:"schemas"`
-		FailOnErrors int                `json:"failOnErrors,omitempty"`
-		Operations []scim.BulkOperation `json:"Operations"`
-	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, scim.MaxBulkPayloadSize)
 
+	var bulkRequest scim.BulkRequest
 	if err := c.ShouldBindJSON(&bulkRequest); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, models.SCIMError{
+				Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+				Detail:  fmt.Sprintf("payload too large: the maximum is %d bytes", scim.MaxBulkPayloadSize),
+				Status:  "413",
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, models.SCIMError{
-			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
-			Detail:  "Invalid request body",
-			Status:  "400",
+			Schemas:  []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+			Detail:   "Invalid request body",
+			Status:   "400",
+			SCIMType: "invalidSyntax",
 		})
 		return
 	}
+	bulkRequest.Atomic = c.Query("atomic") == "true"
 
-	response, err := h.provisioningService.BulkCreate(c.Request.Context(), tenantID, bulkRequest.Operations)
+	response, err := h.provisioningService.Bulk(c.Request.Context(), tenantID, &bulkRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.SCIMError{
-			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
-			Detail:  err.Error(),
-			Status:  "400",
-		})
+		respondWithSCIMError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// respondWithResource writes a single resource with its ETag, applying the
+// attributes and excludedAttributes parameters. A GET whose If-None-Match
+// matches the current version gets 304 Not Modified.
+func (h *SCIMHandler) respondWithResource(c *gin.Context, resource interface{}, version string) {
+	c.Header("ETag", version)
+	if c.Request.Method == http.MethodGet {
+		if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" && scim.ETagMatches(ifNoneMatch, version) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	projected, err := scim.Project(resource,
+		scim.ParseAttributeList(c.Query("attributes")),
+		scim.ParseAttributeList(c.Query("excludedAttributes")))
+	if err != nil {
+		respondWithSCIMError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, projected)
+}
+
+// userPreconditionMet enforces an If-Match header against the user's current
+// version. The check locks the user, so the version still holds when the
+// request's transaction writes it. It writes the error response and returns
+// false when the request must not proceed.
+func (h *SCIMHandler) userPreconditionMet(c *gin.Context, tenantID uuid.UUID, userID string) bool {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+	if err := h.provisioningService.CheckUserVersion(c.Request.Context(), tenantID, userID, ifMatch); err != nil {
+		respondWithSCIMError(c, http.StatusNotFound, err)
+		return false
+	}
+	return true
+}
+
+// groupPreconditionMet enforces an If-Match header against the group's current
+// version. The check locks the group, so the version still holds when the
+// request's transaction writes it. It writes the error response and returns
+// false when the request must not proceed.
+func (h *SCIMHandler) groupPreconditionMet(c *gin.Context, tenantID uuid.UUID, groupID string) bool {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+	if err := h.provisioningService.CheckGroupVersion(c.Request.Context(), tenantID, groupID, ifMatch); err != nil {
+		respondWithSCIMError(c, http.StatusNotFound, err)
+		return false
+	}
+	return true
+}
+
+// respondWithSCIMError maps provisioning errors to SCIM error responses
+func respondWithSCIMError(c *gin.Context, fallbackStatus int, err error) {
+	status, scimType := scim.ErrorStatus(err, fallbackStatus)
+	c.JSON(status, models.SCIMError{
+		Schemas:  []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+		Detail:   err.Error(),
+		Status:   strconv.Itoa(status),
+		SCIMType: scimType,
+	})
+}
+
 // ServiceProviderConfig handles GET /scim/v2/ServiceProviderConfig
 func (h *SCIMHandler) ServiceProviderConfig(c *gin.Context) {
 	config := gin.H{
 		"schemas":    []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
 		"patch":      gin.H{"supported": true},
-		"bulk":       gin.H{"supported": true, "maxOperations": 100, "maxPayloadSize": 1048576},
+		"bulk":       gin.H{"supported": true, "maxOperations": scim.MaxBulkOperations, "maxPayloadSize": scim.MaxBulkPayloadSize},
 		"filter":     gin.H{"supported": true, "maxResults": 200},
 		"changePassword": gin.H{"supported": false},
-		"sort":       gin.H{"supported": false},
-		"etag":       gin.H{"supported": false},
+		"sort":       gin.H{"supported": true},
+		"etag":       gin.H{"supported": true},
 		"authenticationSchemes": []gin.H{
 			{
 				"type":        "oauthbearertoken",
@@ -465,63 +635,37 @@ func (h *SCIMHandler) ServiceProviderConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, config)
 }
 
-// ResourceTypes handles GET /scim/v2/ResourceTypes
+// ResourceTypes handles GET /scim/v2/ResourceTypes. Requests authenticated
+// with a SCIM token also see the tenant's extension schemas.
 func (h *SCIMHandler) ResourceTypes(c *gin.Context) {
-	resourceTypes := []gin.H{
-		{
-			"schemas":     []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
-			"id":          "User",
-			"name":        "User",
-			"endpoint":    "/Users",
-			"description": "User Account",
-			"schema":      "urn:ietf:params:scim:schemas:core:2.0:User",
-			"meta": gin.H{
-				"location":     "/v2/ResourceTypes/User",
-				"resourceType": "ResourceType",
-			},
-		},
-		{
-			"schemas":     []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
-			"id":          "Group",
-			"name":        "Group",
-			"endpoint":    "/Groups",
-			"description": "Group",
-			"schema":      "urn:ietf:params:scim:schemas:core:2.0:Group",
-			"meta": gin.H{
-				"location":     "/v2/ResourceTypes/Group",
-				"resourceType": "ResourceType",
-			},
-		},
+	extensions, ok := h.discoveryExtensions(c)
+	if !ok {
+		return
 	}
-
-	c.JSON(http.StatusOK, resourceTypes)
+	c.JSON(http.StatusOK, scim.ResourceTypeDocuments(extensions))
 }
 
-// Schemas handles GET /scim/v2/Schemas
+// Schemas handles GET /scim/v2/Schemas. Requests authenticated with a SCIM
+// token also see the tenant's extension schemas.
 func (h *SCIMHandler) Schemas(c *gin.Context) {
-	schemas := []gin.H{
-		{
-			"id":          "urn:ietf:params:scim:schemas:core:2.0:User",
-			"name":        "User",
-			"description": "User Account",
-			"attributes": []gin.H{
-				{"name": "userName", "type": "string", "required": true, "caseExact": false},
-				{"name": "name", "type": "complex", "required": false},
-				{"name": "emails", "type": "complex", "multiValued": true, "required": false},
-				{"name": "active", "type": "boolean", "required": false},
-			},
-		},
-		{
-			"id":          "urn:ietf:params:scim:schemas:core:2.0:Group",
-			"name":        "Group",
-			"description": "Group",
-			"attributes": []gin.H{
-				{"name": "displayName", "type": "string", "required": true},
-				{"name": "members", "type": "complex", "multiValued": true, "required": false},
-			},
-		},
+	extensions, ok := h.discoveryExtensions(c)
+	if !ok {
+		return
 	}
-
-	c.JSON(http.StatusOK, schemas)
+	c.JSON(http.StatusOK, scim.SchemaDocuments(extensions))
 }
 
+// discoveryExtensions returns the extension schemas of the authenticated
+// tenant, if any. It writes the error response and returns false on failure.
+func (h *SCIMHandler) discoveryExtensions(c *gin.Context) ([]*models.SCIMSchemaExtension, bool) {
+	tenantID, ok := h.getTenantIDFromContext(c)
+	if !ok || h.schemaService == nil {
+		return nil, true
+	}
+	extensions, err := h.schemaService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		respondWithSCIMError(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return extensions, true
+}
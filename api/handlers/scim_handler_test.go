@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/scim"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockProvisioningService mocks the parts of scim.ProvisioningServiceInterface the tests use
+type MockProvisioningService struct {
+	scim.ProvisioningServiceInterface
+	mock.Mock
+}
+
+func (m *MockProvisioningService) GetUser(ctx context.Context, tenantID uuid.UUID, userID string) (*models.SCIMUser, error) {
+	args := m.Called(ctx, tenantID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMUser), args.Error(1)
+}
+
+func (m *MockProvisioningService) CheckUserVersion(ctx context.Context, tenantID uuid.UUID, userID, ifMatch string) error {
+	args := m.Called(ctx, tenantID, userID, ifMatch)
+	return args.Error(0)
+}
+
+func (m *MockProvisioningService) ListUsers(ctx context.Context, tenantID uuid.UUID, filters *scim.UserFilters) ([]*models.SCIMUser, int, error) {
+	args := m.Called(ctx, tenantID, filters)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.SCIMUser), args.Int(1), args.Error(2)
+}
+
+func (m *MockProvisioningService) PatchUser(ctx context.Context, tenantID uuid.UUID, userID string, req *scim.PatchRequest) (*models.SCIMUser, error) {
+	args := m.Called(ctx, tenantID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMUser), args.Error(1)
+}
+
+func (m *MockProvisioningService) Bulk(ctx context.Context, tenantID uuid.UUID, req *scim.BulkRequest) (*scim.BulkResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*scim.BulkResponse), args.Error(1)
+}
+
+func newSCIMTestRouter(service scim.ProvisioningServiceInterface, tenantID uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("scim_tenant_id", tenantID)
+		c.Next()
+	})
+	handler := NewSCIMHandler(service, nil, nil)
+	router.GET("/scim/v2/Users", handler.ListUsers)
+	router.GET("/scim/v2/Users/:id", handler.GetUser)
+	router.PATCH("/scim/v2/Users/:id", handler.PatchUser)
+	router.POST("/scim/v2/Bulk", handler.BulkOperations)
+	return router
+}
+
+func scimTestUser() *models.SCIMUser {
+	return &models.SCIMUser{
+		ID:       "u1",
+		UserName: "bjensen",
+		Name:     models.SCIMName{GivenName: "Barbara"},
+		Meta:     models.SCIMMeta{ResourceType: "User", Version: `W/"abc"`},
+	}
+}
+
+func TestSCIMHandler_GetUser_ETag(t *testing.T) {
+	tenantID := uuid.New()
+	mockService := new(MockProvisioningService)
+	mockService.On("GetUser", mock.Anything, tenantID, "u1").Return(scimTestUser(), nil)
+	router := newSCIMTestRouter(mockService, tenantID)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/scim/v2/Users/u1?attributes=userName", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `W/"abc"`, w.Header().Get("ETag"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "bjensen", body["userName"])
+	assert.NotContains(t, body, "name")
+
+	req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users/u1", nil)
+	req.Header.Set("If-None-Match", `W/"abc"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestSCIMHandler_PatchUser(t *testing.T) {
+	tenantID := uuid.New()
+	body := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"userName","value":"babs"}]}`
+
+	t.Run("stale If-Match", func(t *testing.T) {
+		mockService := new(MockProvisioningService)
+		mockService.On("CheckUserVersion", mock.Anything, tenantID, "u1", `W/"old"`).
+			Return(fmt.Errorf(`precondition failed: version W/"old" does not match W/"abc"`))
+
+		req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Users/u1", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", `W/"old"`)
+		w := httptest.NewRecorder()
+		newSCIMTestRouter(mockService, tenantID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		mockService.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockProvisioningService)
+		mockService.On("CheckUserVersion", mock.Anything, tenantID, "u1", `W/"abc"`).Return(nil)
+		patched := scimTestUser()
+		patched.UserName = "babs"
+		patched.Meta.Version = `W/"def"`
+		mockService.On("PatchUser", mock.Anything, tenantID, "u1", mock.MatchedBy(func(r *scim.PatchRequest) bool {
+			return len(r.Operations) == 1 && r.Operations[0].Path == "userName"
+		})).Return(patched, nil)
+
+		req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Users/u1", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", `W/"abc"`)
+		w := httptest.NewRecorder()
+		newSCIMTestRouter(mockService, tenantID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `W/"def"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("error types", func(t *testing.T) {
+		tests := []struct {
+			err      error
+			status   int
+			scimType string
+		}{
+			{fmt.Errorf("invalid path: unknown attribute foo (operation 1)"), http.StatusBadRequest, "invalidPath"},
+			{fmt.Errorf("mutability: id is read-only (operation 1)"), http.StatusBadRequest, "mutability"},
+			{fmt.Errorf("no target: no emails value matches the filter (operation 1)"), http.StatusBadRequest, "noTarget"},
+			{fmt.Errorf("user not found"), http.StatusNotFound, ""},
+		}
+		for _, tt := range tests {
+			mockService := new(MockProvisioningService)
+			mockService.On("PatchUser", mock.Anything, tenantID, "u1", mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			newSCIMTestRouter(mockService, tenantID).ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/scim/v2/Users/u1", bytes.NewBufferString(body)))
+
+			assert.Equal(t, tt.status, w.Code, tt.err.Error())
+			var resp models.SCIMError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.scimType, resp.SCIMType)
+		}
+	})
+}
+
+func TestSCIMHandler_ListUsers(t *testing.T) {
+	tenantID := uuid.New()
+
+	t.Run("passes sort parameters and projects resources", func(t *testing.T) {
+		mockService := new(MockProvisioningService)
+		mockService.On("ListUsers", mock.Anything, tenantID, mock.MatchedBy(func(f *scim.UserFilters) bool {
+			return f.Filter == `userName sw "b"` && f.SortBy == "userName" && f.SortOrder == "descending" && f.StartIndex == 3
+		})).Return([]*models.SCIMUser{scimTestUser()}, 1, nil)
+
+		w := httptest.NewRecorder()
+		url := `/scim/v2/Users?filter=userName+sw+%22b%22&sortBy=userName&sortOrder=descending&startIndex=3&excludedAttributes=name`
+		newSCIMTestRouter(mockService, tenantID).ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Resources []map[string]interface{} `json:"Resources"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Resources, 1)
+		assert.NotContains(t, resp.Resources[0], "name")
+		assert.Equal(t, "bjensen", resp.Resources[0]["userName"])
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		mockService := new(MockProvisioningService)
+		mockService.On("ListUsers", mock.Anything, tenantID, mock.Anything).
+			Return(nil, 0, fmt.Errorf("invalid filter: expected value at end of input"))
+
+		w := httptest.NewRecorder()
+		newSCIMTestRouter(mockService, tenantID).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/scim/v2/Users?filter=userName+eq", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"scimType":"invalidFilter"`)
+	})
+}
+
+func TestSCIMHandler_BulkOperations(t *testing.T) {
+	tenantID := uuid.New()
+
+	t.Run("atomic", func(t *testing.T) {
+		mockService := new(MockProvisioningService)
+		mockService.On("Bulk", mock.Anything, tenantID, mock.MatchedBy(func(r *scim.BulkRequest) bool {
+			return r.Atomic && r.FailOnErrors == 2 && len(r.Operations) == 1 && r.Operations[0].BulkID == "g1"
+		})).Return(&scim.BulkResponse{Operations: []scim.BulkOperationResult{{Method: "POST", BulkID: "g1", Status: "201"}}}, nil)
+
+		body := `{"failOnErrors":2,"Operations":[{"method":"POST","path":"/Groups","bulkId":"g1","data":{"displayName":"g"}}]}`
+		req := httptest.NewRequest(http.MethodPost, "/scim/v2/Bulk?atomic=true", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		newSCIMTestRouter(mockService, tenantID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("too many operations", func(t *testing.T) {
+		mockService := new(MockProvisioningService)
+		mockService.On("Bulk", mock.Anything, tenantID, mock.Anything).Return(nil, fmt.Errorf("too many operations: the request has 101, the maximum is 100"))
+
+		req := httptest.NewRequest(http.MethodPost, "/scim/v2/Bulk", bytes.NewBufferString(`{"Operations":[]}`))
+		w := httptest.NewRecorder()
+		newSCIMTestRouter(mockService, tenantID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("payload too large", func(t *testing.T) {
+		mockService := new(MockProvisioningService)
+		body := `{"Operations":[{"method":"POST","path":"/Groups","bulkId":"g1","data":{"displayName":"` +
+			string(bytes.Repeat([]byte("x"), scim.MaxBulkPayloadSize)) + `"}}]}`
+
+		req := httptest.NewRequest(http.MethodPost, "/scim/v2/Bulk", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		newSCIMTestRouter(mockService, tenantID).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		mockService.AssertNotCalled(t, "Bulk", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
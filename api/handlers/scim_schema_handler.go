@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/scim"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SCIMSchemaHandler handles management of tenant-defined SCIM extension schemas
+type SCIMSchemaHandler struct {
+	schemaService scim.SchemaServiceInterface
+	auditService  audit.ServiceInterface
+}
+
+// NewSCIMSchemaHandler creates a new SCIM extension schema handler
+func NewSCIMSchemaHandler(schemaService scim.SchemaServiceInterface, auditService audit.ServiceInterface) *SCIMSchemaHandler {
+	return &SCIMSchemaHandler{
+		schemaService: schemaService,
+		auditService:  auditService,
+	}
+}
+
+// Create handles POST /api/v1/scim/schemas
+func (h *SCIMSchemaHandler) Create(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req scim.CreateSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+	req.TenantID = tenantID
+	if actor, err := extractActorFromContext(c); err == nil {
+		req.CreatedBy = &actor.UserID
+	}
+
+	extension, err := h.schemaService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondWithSCIMSchemaError(c, "creation_failed", err)
+		return
+	}
+
+	h.logSchemaEvent(c, models.EventTypeSCIMSchemaCreated, extension)
+
+	c.JSON(http.StatusCreated, extension)
+}
+
+// List handles GET /api/v1/scim/schemas
+func (h *SCIMSchemaHandler) List(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	extensions, err := h.schemaService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if extensions == nil {
+		extensions = []*models.SCIMSchemaExtension{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schemas": extensions,
+		"count":   len(extensions),
+	})
+}
+
+// GetByID handles GET /api/v1/scim/schemas/:id
+func (h *SCIMSchemaHandler) GetByID(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimSchemaIDParam(c)
+	if !ok {
+		return
+	}
+
+	extension, err := h.schemaService.GetByID(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSCIMSchemaError(c, "get_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, extension)
+}
+
+// Update handles PUT /api/v1/scim/schemas/:id
+func (h *SCIMSchemaHandler) Update(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimSchemaIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req scim.UpdateSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	extension, err := h.schemaService.Update(c.Request.Context(), tenantID, id, &req)
+	if err != nil {
+		respondWithSCIMSchemaError(c, "update_failed", err)
+		return
+	}
+
+	h.logSchemaEvent(c, models.EventTypeSCIMSchemaUpdated, extension)
+
+	c.JSON(http.StatusOK, extension)
+}
+
+// Delete handles DELETE /api/v1/scim/schemas/:id
+func (h *SCIMSchemaHandler) Delete(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := scimSchemaIDParam(c)
+	if !ok {
+		return
+	}
+
+	extension, err := h.schemaService.Delete(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSCIMSchemaError(c, "deletion_failed", err)
+		return
+	}
+
+	h.logSchemaEvent(c, models.EventTypeSCIMSchemaDeleted, extension)
+
+	c.JSON(http.StatusOK, gin.H{"message": "SCIM extension schema deleted successfully"})
+}
+
+// scimSchemaIDParam parses the :id path parameter.
+// It writes the error response and returns false when the ID is malformed.
+func scimSchemaIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid SCIM extension schema ID format", nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondWithSCIMSchemaError maps extension schema service errors to HTTP statuses
+func respondWithSCIMSchemaError(c *gin.Context, code string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "schema not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", msg, nil)
+	case strings.Contains(msg, "already exists"):
+		middleware.RespondWithError(c, http.StatusConflict, "scim_schema_exists", msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusBadRequest, code, msg, nil)
+	}
+}
+
+// logSchemaEvent records an audit event for an extension schema change
+func (h *SCIMSchemaHandler) logSchemaEvent(c *gin.Context, eventType string, extension *models.SCIMSchemaExtension) {
+	if h.auditService == nil {
+		return
+	}
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+
+	attributes := make([]string, len(extension.Attributes))
+	for i, attr := range extension.Attributes {
+		attributes[i] = attr.Name
+	}
+
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target: &models.AuditTarget{
+			Type:       "scim_schema",
+			ID:         extension.ID,
+			Identifier: extension.SchemaURN,
+		},
+		TenantID:  &extension.TenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata: map[string]interface{}{
+			"attributes": attributes,
+			"required":   extension.Required,
+		},
+		Result: models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
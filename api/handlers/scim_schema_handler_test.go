@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/scim"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSCIMSchemaService is a mock implementation of scim.SchemaServiceInterface
+type MockSCIMSchemaService struct {
+	mock.Mock
+}
+
+func (m *MockSCIMSchemaService) Create(ctx context.Context, req *scim.CreateSchemaRequest) (*models.SCIMSchemaExtension, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMSchemaExtension), args.Error(1)
+}
+
+func (m *MockSCIMSchemaService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMSchemaExtension, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMSchemaExtension), args.Error(1)
+}
+
+func (m *MockSCIMSchemaService) List(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMSchemaExtension, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SCIMSchemaExtension), args.Error(1)
+}
+
+func (m *MockSCIMSchemaService) Update(ctx context.Context, tenantID, id uuid.UUID, req *scim.UpdateSchemaRequest) (*models.SCIMSchemaExtension, error) {
+	args := m.Called(ctx, tenantID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMSchemaExtension), args.Error(1)
+}
+
+func (m *MockSCIMSchemaService) Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMSchemaExtension, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMSchemaExtension), args.Error(1)
+}
+
+func TestSCIMSchemaHandler_Create(t *testing.T) {
+	mockService := new(MockSCIMSchemaService)
+	handler := NewSCIMSchemaHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	router := newClaimsTestRouter(tenantID, userID, "scim_schemas:manage")
+	router.POST("/api/v1/scim/schemas", handler.Create)
+
+	created := &models.SCIMSchemaExtension{ID: uuid.New(), TenantID: tenantID, SchemaURN: "urn:acme:ext", Name: "Acme"}
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(req *scim.CreateSchemaRequest) bool {
+		return req.TenantID == tenantID && *req.CreatedBy == userID && len(req.Attributes) == 1
+	})).Return(created, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"schema":     "urn:acme:ext",
+		"name":       "Acme",
+		"attributes": []map[string]interface{}{{"name": "badgeNumber", "type": "string"}},
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/scim/schemas", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSCIMSchemaHandler_Create_Duplicate(t *testing.T) {
+	mockService := new(MockSCIMSchemaService)
+	handler := NewSCIMSchemaHandler(mockService, nil)
+
+	router := newClaimsTestRouter(uuid.New(), uuid.New(), "scim_schemas:manage")
+	router.POST("/api/v1/scim/schemas", handler.Create)
+
+	mockService.On("Create", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("SCIM extension schema urn:acme:ext already exists"))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"schema":     "urn:acme:ext",
+		"name":       "Acme",
+		"attributes": []map[string]interface{}{{"name": "badgeNumber", "type": "string"}},
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/scim/schemas", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestSCIMHandler_Schemas_TenantExtensions(t *testing.T) {
+	schemaService := new(MockSCIMSchemaService)
+	handler := NewSCIMHandler(nil, nil, schemaService)
+
+	tenantID := uuid.New()
+	schemaService.On("List", mock.Anything, tenantID).Return([]*models.SCIMSchemaExtension{{
+		SchemaURN:  "urn:acme:ext",
+		Name:       "Acme",
+		Attributes: []models.SCIMSchemaAttribute{{Name: "pin", Type: "string", Mutability: models.SCIMMutabilityWriteOnly}},
+	}}, nil)
+
+	router := gin.New()
+	router.GET("/anonymous/ResourceTypes", handler.ResourceTypes)
+	router.GET("/scim/v2/Schemas", func(c *gin.Context) {
+		c.Set("scim_tenant_id", tenantID)
+		c.Next()
+	}, handler.Schemas)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/scim/v2/Schemas", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var schemas []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &schemas))
+	require.Len(t, schemas, 4)
+	assert.Equal(t, "urn:acme:ext", schemas[3]["id"])
+	attribute := schemas[3]["attributes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "never", attribute["returned"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/anonymous/ResourceTypes", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "urn:acme:ext", "anonymous requests see no tenant schemas")
+	assert.Contains(t, w.Body.String(), models.SCIMEnterpriseUserSchema)
+	schemaService.AssertNumberOfCalls(t, "List", 1)
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/arauth-identity/iam/api/middleware"
 	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
 	"github.com/arauth-identity/iam/identity/scim"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -37,12 +39,14 @@ func (h *SCIMTokenHandler) CreateToken(c *gin.Context) {
 			"Invalid request body", nil)
 		return
 	}
+	if actor, err := extractActorFromContext(c); err == nil {
+		req.CreatedBy = &actor.UserID
+	}
 
 	// Create token
 	token, plaintext, err := h.tokenService.CreateToken(c.Request.Context(), tenantID, &req)
 	if err != nil {
-		middleware.RespondWithError(c, http.StatusInternalServerError, "creation_failed",
-			"Failed to create SCIM token", nil)
+		respondWithSCIMTokenError(c, "creation_failed", "Failed to create SCIM token", err)
 		return
 	}
 
@@ -50,9 +54,11 @@ func (h *SCIMTokenHandler) CreateToken(c *gin.Context) {
 	if actor, err := extractActorFromContext(c); err == nil {
 		sourceIP, userAgent := extractSourceInfo(c)
 		_ = h.auditService.LogTokenIssued(c.Request.Context(), actor, &tenantID, sourceIP, userAgent, map[string]interface{}{
-			"token_id": token.ID.String(),
-			"name":     token.Name,
-			"scopes":   token.Scopes,
+			"token_id":    token.ID.String(),
+			"name":        token.Name,
+			"scopes":      token.Scopes,
+			"allowed_ips": token.AllowedIPs,
+			"expires_at":  token.ExpiresAt,
 		})
 	}
 
@@ -97,7 +103,7 @@ func (h *SCIMTokenHandler) GetToken(c *gin.Context) {
 		return
 	}
 
-	token, err := h.tokenService.GetToken(c.Request.Context(), tokenID)
+	token, err := h.tokenService.GetToken(c.Request.Context(), tenantID, tokenID)
 	if err != nil {
 		middleware.RespondWithError(c, http.StatusNotFound, "not_found",
 			"SCIM token not found", nil)
@@ -114,6 +120,61 @@ func (h *SCIMTokenHandler) GetToken(c *gin.Context) {
 	c.JSON(http.StatusOK, token)
 }
 
+// UpdateToken handles PUT /api/v1/scim/tokens/:id
+func (h *SCIMTokenHandler) UpdateToken(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid token ID format", nil)
+		return
+	}
+
+	var req scim.UpdateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Invalid request body", nil)
+		return
+	}
+
+	token, err := h.tokenService.UpdateToken(c.Request.Context(), tenantID, tokenID, &req)
+	if err != nil {
+		respondWithSCIMTokenError(c, "update_failed", "Failed to update SCIM token", err)
+		return
+	}
+
+	// Audit log
+	if actor, err := extractActorFromContext(c); err == nil && h.auditService != nil {
+		sourceIP, userAgent := extractSourceInfo(c)
+		event := &models.AuditEvent{
+			EventType: models.EventTypeSCIMTokenUpdated,
+			Actor:     actor,
+			Target: &models.AuditTarget{
+				Type:       "scim_token",
+				ID:         token.ID,
+				Identifier: token.Name,
+			},
+			TenantID:  &tenantID,
+			SourceIP:  sourceIP,
+			UserAgent: userAgent,
+			Metadata: map[string]interface{}{
+				"scopes":      token.Scopes,
+				"allowed_ips": token.AllowedIPs,
+				"expires_at":  token.ExpiresAt,
+			},
+			Result: models.ResultSuccess,
+		}
+		event.Flatten()
+		_ = h.auditService.LogEvent(c.Request.Context(), event)
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
 // RotateToken handles POST /api/v1/scim/tokens/:id/rotate
 func (h *SCIMTokenHandler) RotateToken(c *gin.Context) {
 	tenantID, ok := middleware.RequireTenant(c)
@@ -129,9 +190,9 @@ func (h *SCIMTokenHandler) RotateToken(c *gin.Context) {
 	}
 
 	// Rotate token
-	token, plaintext, err := h.tokenService.RotateToken(c.Request.Context(), tokenID)
+	token, plaintext, err := h.tokenService.RotateToken(c.Request.Context(), tenantID, tokenID)
 	if err != nil {
-		if err.Error() == "token not found" {
+		if strings.Contains(err.Error(), "token not found") {
 			middleware.RespondWithError(c, http.StatusNotFound, "not_found",
 				"SCIM token not found", nil)
 			return
@@ -141,7 +202,7 @@ func (h *SCIMTokenHandler) RotateToken(c *gin.Context) {
 		return
 	}
 
-	// Verify ownership (if service doesn't enforce, handler must check retrieved token)
+	// Verify ownership (the service already hides other tenants' tokens)
 	if token.TenantID != tenantID {
 		middleware.RespondWithError(c, http.StatusForbidden, "forbidden",
 			"Access denied to this SCIM token", nil)
@@ -182,7 +243,7 @@ func (h *SCIMTokenHandler) DeleteToken(c *gin.Context) {
 	}
 
 	// Check existence and ownership first (optional optimization but safer)
-	token, err := h.tokenService.GetToken(c.Request.Context(), tokenID)
+	token, err := h.tokenService.GetToken(c.Request.Context(), tenantID, tokenID)
 	if err != nil {
 		middleware.RespondWithError(c, http.StatusNotFound, "not_found",
 			"SCIM token not found", nil)
@@ -195,7 +256,7 @@ func (h *SCIMTokenHandler) DeleteToken(c *gin.Context) {
 		return
 	}
 
-	if err := h.tokenService.DeleteToken(c.Request.Context(), tokenID); err != nil {
+	if err := h.tokenService.DeleteToken(c.Request.Context(), tenantID, tokenID); err != nil {
 		middleware.RespondWithError(c, http.StatusInternalServerError, "deletion_failed",
 			"Failed to delete SCIM token", nil)
 		return
@@ -212,3 +273,17 @@ func (h *SCIMTokenHandler) DeleteToken(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// respondWithSCIMTokenError maps token service errors to HTTP statuses.
+// Validation errors are reported as they are; other failures get message.
+func respondWithSCIMTokenError(c *gin.Context, code, message string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "token not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", "SCIM token not found", nil)
+	case strings.HasPrefix(msg, "invalid "), strings.HasPrefix(msg, "expires_at"), strings.HasPrefix(msg, "at least one scope"):
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request", msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusInternalServerError, code, message, nil)
+	}
+}
@@ -19,16 +19,16 @@ import (
 // MockSCIMTokenService
 type MockSCIMTokenService struct {
 	scim.TokenServiceInterface
-	RotateTokenFunc func(ctx context.Context, id uuid.UUID) (*models.SCIMToken, string, error)
+	RotateTokenFunc func(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMToken, string, error)
 	CreateTokenFunc func(ctx context.Context, tenantID uuid.UUID, req *scim.CreateTokenRequest) (*models.SCIMToken, string, error)
-	GetTokenFunc    func(ctx context.Context, id uuid.UUID) (*models.SCIMToken, error)
-	DeleteTokenFunc func(ctx context.Context, id uuid.UUID) error
+	GetTokenFunc    func(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMToken, error)
+	DeleteTokenFunc func(ctx context.Context, tenantID, id uuid.UUID) error
 	ListTokensFunc  func(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMToken, error)
 }
 
-func (m *MockSCIMTokenService) RotateToken(ctx context.Context, id uuid.UUID) (*models.SCIMToken, string, error) {
+func (m *MockSCIMTokenService) RotateToken(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMToken, string, error) {
 	if m.RotateTokenFunc != nil {
-		return m.RotateTokenFunc(ctx, id)
+		return m.RotateTokenFunc(ctx, tenantID, id)
 	}
 	return nil, "", nil
 }
@@ -40,16 +40,16 @@ func (m *MockSCIMTokenService) CreateToken(ctx context.Context, tenantID uuid.UU
 	return nil, "", nil
 }
 
-func (m *MockSCIMTokenService) GetToken(ctx context.Context, id uuid.UUID) (*models.SCIMToken, error) {
+func (m *MockSCIMTokenService) GetToken(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMToken, error) {
 	if m.GetTokenFunc != nil {
-		return m.GetTokenFunc(ctx, id)
+		return m.GetTokenFunc(ctx, tenantID, id)
 	}
 	return nil, nil
 }
 
-func (m *MockSCIMTokenService) DeleteToken(ctx context.Context, id uuid.UUID) error {
+func (m *MockSCIMTokenService) DeleteToken(ctx context.Context, tenantID, id uuid.UUID) error {
 	if m.DeleteTokenFunc != nil {
-		return m.DeleteTokenFunc(ctx, id)
+		return m.DeleteTokenFunc(ctx, tenantID, id)
 	}
 	return nil
 }
@@ -102,7 +102,8 @@ func TestSCIMTokenHandler_RotateToken(t *testing.T) {
 		})
 		router.POST("/scim/tokens/:id/rotate", handler.RotateToken)
 
-		mockService.RotateTokenFunc = func(ctx context.Context, id uuid.UUID) (*models.SCIMToken, string, error) {
+		mockService.RotateTokenFunc = func(ctx context.Context, tid, id uuid.UUID) (*models.SCIMToken, string, error) {
+			assert.Equal(t, tenantID, tid)
 			assert.Equal(t, tokenID, id)
 			return &models.SCIMToken{
 				ID:       tokenID,
@@ -139,7 +140,7 @@ func TestSCIMTokenHandler_RotateToken(t *testing.T) {
 		})
 		router.POST("/scim/tokens/:id/rotate", handler.RotateToken)
 
-		mockService.RotateTokenFunc = func(ctx context.Context, id uuid.UUID) (*models.SCIMToken, string, error) {
+		mockService.RotateTokenFunc = func(ctx context.Context, tid, id uuid.UUID) (*models.SCIMToken, string, error) {
 			return nil, "", errors.New("token not found")
 		}
 
@@ -161,7 +162,7 @@ func TestSCIMTokenHandler_RotateToken(t *testing.T) {
 		})
 		router.POST("/scim/tokens/:id/rotate", handler.RotateToken)
 
-		mockService.RotateTokenFunc = func(ctx context.Context, id uuid.UUID) (*models.SCIMToken, string, error) {
+		mockService.RotateTokenFunc = func(ctx context.Context, tid, id uuid.UUID) (*models.SCIMToken, string, error) {
 			return &models.SCIMToken{
 				ID:       tokenID,
 				TenantID: uuid.New(), // Different tenant
@@ -168,3 +168,99 @@ func (h *SessionHandler) RevokeSession(c *gin.Context) {
 		"session_id": sessionID.String(),
 	})
 }
+
+// ListMySessions handles GET /api/v1/me/sessions
+// Self-service: lists the caller's own active sessions with device metadata
+func (h *SessionHandler) ListMySessions(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	userUUID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(c.Request.Context(), userUUID, tenantID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error",
+			"Failed to list sessions", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// RevokeMySession handles DELETE /api/v1/me/sessions/:id
+// Self-service: lets a user sign out one of their own sessions (e.g. a lost device)
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_session_id",
+			"Invalid session ID format", nil)
+		return
+	}
+
+	userUUID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Ownership check: sessions of other users or tenants are reported as not found
+	targetSession, err := h.sessionService.GetSessionByID(c.Request.Context(), sessionID)
+	if err != nil || targetSession.UserID != userUUID || targetSession.TenantID != tenantID {
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found",
+			"Session not found", nil)
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), sessionID, "revoked by user"); err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "internal_error",
+			"Failed to revoke session", nil)
+		return
+	}
+
+	actor, _ := extractActorFromContext(c)
+	sourceIP, userAgent := extractSourceInfo(c)
+	_ = h.auditService.LogTokenRevoked(c.Request.Context(), actor, &tenantID, sourceIP, userAgent, map[string]interface{}{
+		"session_id":  sessionID.String(),
+		"token_type":  "refresh_token",
+		"device_info": targetSession.DeviceInfo,
+		"ip_address":  targetSession.IPAddress,
+		"action":      "session_revoked_self_service",
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Session revoked successfully",
+		"session_id": sessionID.String(),
+	})
+}
+
+// currentUserID returns the authenticated user's ID, writing an error response if unavailable
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		middleware.RespondWithError(c, http.StatusUnauthorized, "unauthorized",
+			"User ID not found in token", nil)
+		return uuid.Nil, false
+	}
+
+	userIDStr, _ := userID.(string)
+	userUUID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_user_id",
+			"Invalid user ID format", nil)
+		return uuid.Nil, false
+	}
+
+	return userUUID, true
+}
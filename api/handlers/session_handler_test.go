@@ -237,3 +237,78 @@ func TestRevokeSession_CrossTenantDenied(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "does not belong to your tenant")
 	mockSessionService.AssertExpectations(t)
 }
+
+// TestRevokeMySession_Success tests self-service revocation of an own session
+func TestRevokeMySession_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(MockSessionService)
+	mockAuditService := new(MockAuditService)
+	handler := NewSessionHandler(mockSessionService, mockAuditService)
+
+	userID := uuid.New()
+	tenantID := uuid.New()
+	sessionID := uuid.New()
+
+	mockSessionService.On("GetSessionByID", mock.Anything, sessionID).Return(&session.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		TenantID:   tenantID,
+		DeviceInfo: "Firefox 121 on Linux",
+	}, nil)
+	mockSessionService.On("RevokeSession", mock.Anything, sessionID, mock.Anything).Return(nil)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Set("user_id", userID.String())
+		c.Set("user_claims", &claims.Claims{
+			Subject:       userID.String(),
+			Username:      "testuser",
+			PrincipalType: "TENANT",
+		})
+		c.Next()
+	})
+	router.DELETE("/me/sessions/:id", handler.RevokeMySession)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/me/sessions/"+sessionID.String(), nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSessionService.AssertExpectations(t)
+}
+
+// TestRevokeMySession_OtherUsersSession tests that users cannot revoke sessions they do not own
+func TestRevokeMySession_OtherUsersSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(MockSessionService)
+	mockAuditService := new(MockAuditService)
+	handler := NewSessionHandler(mockSessionService, mockAuditService)
+
+	userID := uuid.New()
+	tenantID := uuid.New()
+	sessionID := uuid.New()
+
+	mockSessionService.On("GetSessionByID", mock.Anything, sessionID).Return(&session.Session{
+		ID:       sessionID,
+		UserID:   uuid.New(), // Someone else's session
+		TenantID: tenantID,
+	}, nil)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Set("user_id", userID.String())
+		c.Next()
+	})
+	router.DELETE("/me/sessions/:id", handler.RevokeMySession)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/me/sessions/"+sessionID.String(), nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockSessionService.AssertNotCalled(t, "RevokeSession", mock.Anything, mock.Anything, mock.Anything)
+}
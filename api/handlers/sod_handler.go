@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/sod"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SoDHandler handles separation-of-duties rule HTTP requests
+type SoDHandler struct {
+	sodService   sod.ServiceInterface
+	auditService audit.ServiceInterface
+}
+
+// NewSoDHandler creates a new separation-of-duties handler
+func NewSoDHandler(sodService sod.ServiceInterface, auditService audit.ServiceInterface) *SoDHandler {
+	return &SoDHandler{
+		sodService:   sodService,
+		auditService: auditService,
+	}
+}
+
+// Create handles POST /api/v1/sod-rules
+func (h *SoDHandler) Create(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req sod.CreateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+	req.TenantID = tenantID
+	if actor, err := extractActorFromContext(c); err == nil {
+		req.CreatedBy = &actor.UserID
+	}
+
+	rule, err := h.sodService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondWithSoDError(c, "creation_failed", err)
+		return
+	}
+
+	h.logSoDEvent(c, models.EventTypeSoDRuleCreated, rule)
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// List handles GET /api/v1/sod-rules
+func (h *SoDHandler) List(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.sodService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
+		return
+	}
+	if rules == nil {
+		rules = []*models.SoDRule{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+// GetByID handles GET /api/v1/sod-rules/:id
+func (h *SoDHandler) GetByID(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := sodRuleIDParam(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.sodService.GetByID(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSoDError(c, "get_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// Update handles PUT /api/v1/sod-rules/:id
+func (h *SoDHandler) Update(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := sodRuleIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req sod.UpdateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+			"Request validation failed", middleware.FormatValidationErrors(err))
+		return
+	}
+
+	rule, err := h.sodService.Update(c.Request.Context(), tenantID, id, &req)
+	if err != nil {
+		respondWithSoDError(c, "update_failed", err)
+		return
+	}
+
+	h.logSoDEvent(c, models.EventTypeSoDRuleUpdated, rule)
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// Delete handles DELETE /api/v1/sod-rules/:id
+func (h *SoDHandler) Delete(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := sodRuleIDParam(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.sodService.Delete(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithSoDError(c, "deletion_failed", err)
+		return
+	}
+
+	h.logSoDEvent(c, models.EventTypeSoDRuleDeleted, rule)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Separation of duties rule deleted successfully"})
+}
+
+// Violations handles GET /api/v1/sod-rules/violations and GET /api/v1/sod-rules/:id/violations.
+// It lists users who already hold more of a rule's roles than the rule allows.
+func (h *SoDHandler) Violations(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	var ruleID *uuid.UUID
+	if c.Param("id") != "" {
+		id, ok := sodRuleIDParam(c)
+		if !ok {
+			return
+		}
+		ruleID = &id
+	}
+
+	violations, err := h.sodService.Violations(c.Request.Context(), tenantID, ruleID)
+	if err != nil {
+		respondWithSoDError(c, "report_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"violations": violations,
+		"count":      len(violations),
+	})
+}
+
+// sodRuleIDParam parses the :id path parameter.
+// It writes the error response and returns false when the ID is malformed.
+func sodRuleIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid separation of duties rule ID format", nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondWithSoDError maps separation-of-duties service errors to HTTP statuses
+func respondWithSoDError(c *gin.Context, code string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "rule not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", msg, nil)
+	case strings.Contains(msg, "already exists"):
+		middleware.RespondWithError(c, http.StatusConflict, "sod_rule_exists", msg, nil)
+	default:
+		middleware.RespondWithError(c, http.StatusBadRequest, code, msg, nil)
+	}
+}
+
+// logSoDEvent records an audit event for a rule change
+func (h *SoDHandler) logSoDEvent(c *gin.Context, eventType string, rule *models.SoDRule) {
+	if h.auditService == nil {
+		return
+	}
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+
+	roleIDs := make([]string, len(rule.RoleIDs))
+	for i, id := range rule.RoleIDs {
+		roleIDs[i] = id.String()
+	}
+
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target: &models.AuditTarget{
+			Type:       "sod_rule",
+			ID:         rule.ID,
+			Identifier: rule.Name,
+		},
+		TenantID:  &rule.TenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata: map[string]interface{}{
+			"role_ids":  roleIDs,
+			"max_roles": rule.MaxRoles,
+		},
+		Result: models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/sod"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSoDService is a mock implementation of sod.ServiceInterface
+type MockSoDService struct {
+	mock.Mock
+}
+
+func (m *MockSoDService) Create(ctx context.Context, req *sod.CreateRuleRequest) (*models.SoDRule, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SoDRule), args.Error(1)
+}
+
+func (m *MockSoDService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SoDRule, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SoDRule), args.Error(1)
+}
+
+func (m *MockSoDService) List(ctx context.Context, tenantID uuid.UUID) ([]*models.SoDRule, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SoDRule), args.Error(1)
+}
+
+func (m *MockSoDService) Update(ctx context.Context, tenantID, id uuid.UUID, req *sod.UpdateRuleRequest) (*models.SoDRule, error) {
+	args := m.Called(ctx, tenantID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SoDRule), args.Error(1)
+}
+
+func (m *MockSoDService) Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SoDRule, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SoDRule), args.Error(1)
+}
+
+func (m *MockSoDService) CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	args := m.Called(ctx, tenantID, userID, roleIDs)
+	return args.Error(0)
+}
+
+func (m *MockSoDService) CheckInheritance(ctx context.Context, tenantID, roleID, parentRoleID uuid.UUID) error {
+	args := m.Called(ctx, tenantID, roleID, parentRoleID)
+	return args.Error(0)
+}
+
+func (m *MockSoDService) Violations(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID) ([]*models.SoDViolation, error) {
+	args := m.Called(ctx, tenantID, ruleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SoDViolation), args.Error(1)
+}
+
+func TestSoDHandler_Create(t *testing.T) {
+	mockService := new(MockSoDService)
+	handler := NewSoDHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	router := newClaimsTestRouter(tenantID, userID, "sod_rules:manage")
+	router.POST("/api/v1/sod-rules", handler.Create)
+
+	roleIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	created := &models.SoDRule{ID: uuid.New(), TenantID: tenantID, Name: "payments", RoleIDs: roleIDs, MaxRoles: 1}
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(req *sod.CreateRuleRequest) bool {
+		return req.TenantID == tenantID && *req.CreatedBy == userID && len(req.RoleIDs) == 2
+	})).Return(created, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "payments", "role_ids": roleIDs})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/sod-rules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSoDHandler_Create_SingleRole(t *testing.T) {
+	mockService := new(MockSoDService)
+	handler := NewSoDHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	router := newClaimsTestRouter(tenantID, uuid.New(), "sod_rules:manage")
+	router.POST("/api/v1/sod-rules", handler.Create)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "payments", "role_ids": []uuid.UUID{uuid.New()}})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/sod-rules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestSoDHandler_GetByID_NotFound(t *testing.T) {
+	mockService := new(MockSoDService)
+	handler := NewSoDHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	router := newClaimsTestRouter(tenantID, uuid.New(), "sod_rules:read")
+	router.GET("/api/v1/sod-rules/:id", handler.GetByID)
+
+	id := uuid.New()
+	mockService.On("GetByID", mock.Anything, tenantID, id).Return(nil, fmt.Errorf("separation of duties rule not found"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/sod-rules/"+id.String(), nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSoDHandler_Violations(t *testing.T) {
+	mockService := new(MockSoDService)
+	handler := NewSoDHandler(mockService, nil)
+
+	tenantID := uuid.New()
+	router := newClaimsTestRouter(tenantID, uuid.New(), "sod_rules:read")
+	router.GET("/api/v1/sod-rules/violations", handler.Violations)
+	router.GET("/api/v1/sod-rules/:id/violations", handler.Violations)
+
+	ruleID := uuid.New()
+	violation := &models.SoDViolation{RuleID: ruleID, RuleName: "payments", MaxRoles: 1, UserID: uuid.New(), Username: "bob"}
+	mockService.On("Violations", mock.Anything, tenantID, (*uuid.UUID)(nil)).Return([]*models.SoDViolation{violation}, nil)
+	mockService.On("Violations", mock.Anything, tenantID, &ruleID).Return([]*models.SoDViolation{}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/sod-rules/violations", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, float64(1), resp["count"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/sod-rules/"+ruleID.String()+"/violations", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/account"
 	"github.com/arauth-identity/iam/identity/audit"
 	"github.com/arauth-identity/iam/identity/capability"
 	"github.com/arauth-identity/iam/identity/models"
@@ -388,6 +389,8 @@ func (h *SystemHandler) UpdateTenantSettingsFromContext(c *gin.Context) {
 		MFARequired                       *bool `json:"mfa_required,omitempty"`
 		RateLimitRequests                 *int  `json:"rate_limit_requests,omitempty"`
 		RateLimitWindowSeconds            *int  `json:"rate_limit_window_seconds,omitempty"`
+		// Self-service settings
+		SelfServiceProfileFields *[]string `json:"self_service_profile_fields,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -419,6 +422,7 @@ func (h *SystemHandler) UpdateTenantSettingsFromContext(c *gin.Context) {
 			MFARequired:                     false,
 			RateLimitRequests:                100,
 			RateLimitWindowSeconds:           60,
+			SelfServiceProfileFields:         account.DefaultEditableFields,
 		}
 	}
 
@@ -488,6 +492,14 @@ func (h *SystemHandler) UpdateTenantSettingsFromContext(c *gin.Context) {
 	if req.RateLimitWindowSeconds != nil {
 		settings.RateLimitWindowSeconds = *req.RateLimitWindowSeconds
 	}
+	if req.SelfServiceProfileFields != nil {
+		if err := account.ValidateEditableFields(*req.SelfServiceProfileFields); err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+				err.Error(), nil)
+			return
+		}
+		settings.SelfServiceProfileFields = *req.SelfServiceProfileFields
+	}
 
 	// Save settings
 	isNew := settings.ID == uuid.Nil
@@ -568,6 +580,8 @@ func (h *SystemHandler) UpdateTenantSettings(c *gin.Context) {
 		MFARequired                       *bool `json:"mfa_required,omitempty"`
 		RateLimitRequests                 *int  `json:"rate_limit_requests,omitempty"`
 		RateLimitWindowSeconds            *int  `json:"rate_limit_window_seconds,omitempty"`
+		// Self-service settings
+		SelfServiceProfileFields *[]string `json:"self_service_profile_fields,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -600,6 +614,7 @@ func (h *SystemHandler) UpdateTenantSettings(c *gin.Context) {
 			MFARequired:                     false,
 			RateLimitRequests:                100,
 			RateLimitWindowSeconds:           60,
+			SelfServiceProfileFields:         account.DefaultEditableFields,
 		}
 	}
 
@@ -670,6 +685,14 @@ func (h *SystemHandler) UpdateTenantSettings(c *gin.Context) {
 	if req.RateLimitWindowSeconds != nil {
 		settings.RateLimitWindowSeconds = *req.RateLimitWindowSeconds
 	}
+	if req.SelfServiceProfileFields != nil {
+		if err := account.ValidateEditableFields(*req.SelfServiceProfileFields); err != nil {
+			middleware.RespondWithError(c, http.StatusBadRequest, "invalid_request",
+				err.Error(), nil)
+			return
+		}
+		settings.SelfServiceProfileFields = *req.SelfServiceProfileFields
+	}
 
 	// Save settings
 	isNew := settings.ID == uuid.Nil
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"github.com/arauth-identity/iam/auth/claims"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// newTenantTestRouter creates a test router that sets the request's tenant
+func newTenantTestRouter(tenantID uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("tenant_id", tenantID)
+		c.Next()
+	})
+	return router
+}
+
+// newClaimsTestRouter creates a test router that sets the request's tenant
+// and the caller's claims
+func newClaimsTestRouter(tenantID, userID uuid.UUID, permissions ...string) *gin.Engine {
+	router := newTenantTestRouter(tenantID)
+	router.Use(func(c *gin.Context) {
+		c.Set("user_claims", &claims.Claims{
+			Subject:       userID.String(),
+			Username:      "alice",
+			PrincipalType: "TENANT",
+			TenantID:      tenantID.String(),
+			Permissions:   permissions,
+		})
+		c.Next()
+	})
+	return router
+}
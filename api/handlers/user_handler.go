@@ -127,15 +127,7 @@ func (h *UserHandler) CreateSystem(c *gin.Context) {
 	}
 
 	// Check if current user has system:users permission
-	hasSystemUsersPermission := false
-	for _, perm := range userClaims.SystemPermissions {
-		if perm == "system:users" || perm == "system:*" || perm == "*:*" {
-			hasSystemUsersPermission = true
-			break
-		}
-	}
-
-	if !hasSystemUsersPermission {
+	if !userClaims.HasSystemPermission("system", "users") {
 		middleware.RespondWithError(c, http.StatusForbidden, "access_denied",
 			"Required permission: system:users", nil)
 		return
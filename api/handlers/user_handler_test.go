@@ -10,6 +10,7 @@ import (
 
 	"github.com/arauth-identity/iam/identity/models"
 	"github.com/arauth-identity/iam/identity/user"
+	"github.com/arauth-identity/iam/security/password"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -106,6 +107,11 @@ func (m *MockUserService) ChangePassword(ctx context.Context, userID uuid.UUID,
 	return args.Error(0)
 }
 
+func (m *MockUserService) ChangePasswordWithPolicy(ctx context.Context, userID uuid.UUID, newPassword string, policy *password.Validator) error {
+	args := m.Called(ctx, userID, newPassword, policy)
+	return args.Error(0)
+}
+
 func TestUserHandler_Create(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -3,30 +3,43 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/api/middleware"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
 	"github.com/arauth-identity/iam/identity/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // WebhookHandler handles webhook-related HTTP requests
 type WebhookHandler struct {
 	webhookService webhook.ServiceInterface
+	auditService   audit.ServiceInterface
 }
 
 // NewWebhookHandler creates a new webhook handler
-func NewWebhookHandler(webhookService webhook.ServiceInterface) *WebhookHandler {
+func NewWebhookHandler(webhookService webhook.ServiceInterface, auditService audit.ServiceInterface) *WebhookHandler {
 	return &WebhookHandler{
 		webhookService: webhookService,
+		auditService:   auditService,
 	}
 }
 
+// ListEventTypes handles GET /api/v1/webhooks/events
+func (h *WebhookHandler) ListEventTypes(c *gin.Context) {
+	catalog := webhook.EventCatalog()
+	c.JSON(http.StatusOK, gin.H{
+		"events": catalog,
+		"count":  len(catalog),
+	})
+}
+
 // CreateWebhook handles POST /api/v1/webhooks
 func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
-	tenantID, exists := middleware.GetTenantID(c)
-	if !exists {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "tenant_required", "message": "Tenant ID is required"})
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
 		return
 	}
 
@@ -39,25 +52,30 @@ func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
 
 	w, err := h.webhookService.CreateWebhook(c.Request.Context(), tenantID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "creation_failed", "message": err.Error()})
+		respondWithWebhookError(c, "creation_failed", err)
 		return
 	}
 
+	h.logWebhookEvent(c, models.EventTypeWebhookCreated, w, nil)
+
 	c.JSON(http.StatusCreated, w)
 }
 
 // GetWebhook handles GET /api/v1/webhooks/:id
 func (h *WebhookHandler) GetWebhook(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_id", "message": "Invalid webhook ID"})
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
 		return
 	}
 
-	w, err := h.webhookService.GetWebhook(c.Request.Context(), id)
+	id, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	w, err := h.webhookService.GetWebhook(c.Request.Context(), tenantID, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Webhook not found"})
+		respondWithWebhookError(c, "get_failed", err)
 		return
 	}
 
@@ -66,27 +84,33 @@ func (h *WebhookHandler) GetWebhook(c *gin.Context) {
 
 // ListWebhooks handles GET /api/v1/webhooks
 func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
-	tenantID, exists := middleware.GetTenantID(c)
-	if !exists {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "tenant_required", "message": "Tenant ID is required"})
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
 		return
 	}
 
 	webhooks, err := h.webhookService.GetWebhooksByTenant(c.Request.Context(), tenantID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "list_failed", "message": err.Error()})
+		middleware.RespondWithError(c, http.StatusInternalServerError, "list_failed",
+			err.Error(), nil)
 		return
 	}
+	if webhooks == nil {
+		webhooks = []*models.Webhook{}
+	}
 
 	c.JSON(http.StatusOK, webhooks)
 }
 
 // UpdateWebhook handles PUT /api/v1/webhooks/:id
 func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_id", "message": "Invalid webhook ID"})
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := webhookIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -97,38 +121,123 @@ func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
 		return
 	}
 
-	w, err := h.webhookService.UpdateWebhook(c.Request.Context(), id, &req)
+	w, err := h.webhookService.UpdateWebhook(c.Request.Context(), tenantID, id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update_failed", "message": err.Error()})
+		respondWithWebhookError(c, "update_failed", err)
 		return
 	}
 
+	h.logWebhookEvent(c, models.EventTypeWebhookUpdated, w, map[string]interface{}{
+		"secret_rotated": req.Secret != nil || req.RotateKey,
+	})
+
 	c.JSON(http.StatusOK, w)
 }
 
 // DeleteWebhook handles DELETE /api/v1/webhooks/:id
 func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	w, err := h.webhookService.GetWebhook(c.Request.Context(), tenantID, id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_id", "message": "Invalid webhook ID"})
+		respondWithWebhookError(c, "deletion_failed", err)
+		return
+	}
+	if err := h.webhookService.DeleteWebhook(c.Request.Context(), tenantID, id); err != nil {
+		respondWithWebhookError(c, "deletion_failed", err)
 		return
 	}
 
-	if err := h.webhookService.DeleteWebhook(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete_failed", "message": err.Error()})
+	h.logWebhookEvent(c, models.EventTypeWebhookDeleted, w, nil)
+
+	c.Status(http.StatusNoContent)
+}
+
+// PauseWebhook handles POST /api/v1/webhooks/:id/pause
+func (h *WebhookHandler) PauseWebhook(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	w, err := h.webhookService.PauseWebhook(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithWebhookError(c, "pause_failed", err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	h.logWebhookEvent(c, models.EventTypeWebhookPaused, w, nil)
+
+	c.JSON(http.StatusOK, w)
+}
+
+// ResumeWebhook handles POST /api/v1/webhooks/:id/resume
+func (h *WebhookHandler) ResumeWebhook(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	w, err := h.webhookService.ResumeWebhook(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithWebhookError(c, "resume_failed", err)
+		return
+	}
+
+	h.logWebhookEvent(c, models.EventTypeWebhookResumed, w, nil)
+
+	c.JSON(http.StatusOK, w)
+}
+
+// SendTestEvent handles POST /api/v1/webhooks/:id/test.
+// The delivery is returned whether or not the endpoint accepted it.
+func (h *WebhookHandler) SendTestEvent(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	id, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.SendTestEvent(c.Request.Context(), tenantID, id)
+	if err != nil {
+		respondWithWebhookError(c, "test_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
 }
 
 // GetDeliveries handles GET /api/v1/webhooks/:id/deliveries
 func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
-	idStr := c.Param("id")
-	webhookID, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_id", "message": "Invalid webhook ID"})
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -149,11 +258,14 @@ func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
 		}
 	}
 
-	deliveries, total, err := h.webhookService.GetDeliveriesByWebhook(c.Request.Context(), webhookID, limit, offset)
+	deliveries, total, err := h.webhookService.GetDeliveriesByWebhook(c.Request.Context(), tenantID, webhookID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "list_failed", "message": err.Error()})
+		respondWithWebhookError(c, "list_failed", err)
 		return
 	}
+	if deliveries == nil {
+		deliveries = []*models.WebhookDelivery{}
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"deliveries": deliveries,
@@ -165,19 +277,123 @@ func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
 
 // GetDelivery handles GET /api/v1/webhooks/:id/deliveries/:delivery_id
 func (h *WebhookHandler) GetDelivery(c *gin.Context) {
-	deliveryIDStr := c.Param("delivery_id")
-	deliveryID, err := uuid.Parse(deliveryIDStr)
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
+		return
+	}
+
+	webhookID, deliveryID, ok := webhookDeliveryIDParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.GetDeliveryByID(c.Request.Context(), tenantID, webhookID, deliveryID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_id", "message": "Invalid delivery ID"})
+		respondWithWebhookError(c, "get_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// RedeliverDelivery handles POST /api/v1/webhooks/:id/deliveries/:delivery_id/redeliver.
+// The event is sent again as a new delivery, which is returned.
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	tenantID, ok := middleware.RequireTenant(c)
+	if !ok {
 		return
 	}
 
-	delivery, err := h.webhookService.GetDeliveryByID(c.Request.Context(), deliveryID)
+	webhookID, deliveryID, ok := webhookDeliveryIDParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), tenantID, webhookID, deliveryID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Delivery not found"})
+		respondWithWebhookError(c, "redelivery_failed", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, delivery)
 }
 
+// webhookIDParam parses the :id path parameter.
+// It writes the error response and returns false when the ID is malformed.
+func webhookIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid webhook ID", nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// webhookDeliveryIDParams parses the :id and :delivery_id path parameters
+func webhookDeliveryIDParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		middleware.RespondWithError(c, http.StatusBadRequest, "invalid_id",
+			"Invalid delivery ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return webhookID, deliveryID, true
+}
+
+// respondWithWebhookError maps webhook service errors to HTTP statuses
+func respondWithWebhookError(c *gin.Context, code string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		middleware.RespondWithError(c, http.StatusNotFound, "not_found", msg, nil)
+	case strings.Contains(msg, "unknown event type"), strings.Contains(msg, "event type is required"),
+		strings.HasPrefix(msg, "invalid "):
+		middleware.RespondWithError(c, http.StatusBadRequest, code, msg, nil)
+	case strings.Contains(msg, "duplicate key"):
+		middleware.RespondWithError(c, http.StatusConflict, "webhook_exists",
+			"A webhook with this name already exists", nil)
+	default:
+		middleware.RespondWithError(c, http.StatusInternalServerError, code, msg, nil)
+	}
+}
+
+// logWebhookEvent records an audit event for a webhook change
+func (h *WebhookHandler) logWebhookEvent(c *gin.Context, eventType string, target *models.Webhook, metadata map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	actor, err := extractActorFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["url"] = target.URL
+	metadata["enabled"] = target.Enabled
+	metadata["events"] = target.Events
+
+	sourceIP, userAgent := extractSourceInfo(c)
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Target: &models.AuditTarget{
+			Type:       "webhook",
+			ID:         target.ID,
+			Identifier: target.Name,
+		},
+		TenantID:  &target.TenantID,
+		SourceIP:  sourceIP,
+		UserAgent: userAgent,
+		Metadata:  metadata,
+		Result:    models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = h.auditService.LogEvent(c.Request.Context(), event)
+}
@@ -29,8 +29,8 @@ func (m *MockWebhookService) CreateWebhook(ctx context.Context, tenantID uuid.UU
 	return args.Get(0).(*models.Webhook), args.Error(1)
 }
 
-func (m *MockWebhookService) GetWebhook(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
-	args := m.Called(ctx, id)
+func (m *MockWebhookService) GetWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error) {
+	args := m.Called(ctx, tenantID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -42,34 +42,61 @@ func (m *MockWebhookService) GetWebhooksByTenant(ctx context.Context, tenantID u
 	return args.Get(0).([]*models.Webhook), args.Error(1)
 }
 
-func (m *MockWebhookService) UpdateWebhook(ctx context.Context, id uuid.UUID, req *webhook.UpdateWebhookRequest) (*models.Webhook, error) {
-	args := m.Called(ctx, id, req)
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, tenantID, id uuid.UUID, req *webhook.UpdateWebhookRequest) (*models.Webhook, error) {
+	args := m.Called(ctx, tenantID, id, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Webhook), args.Error(1)
 }
 
-func (m *MockWebhookService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, tenantID, id uuid.UUID) error {
+	args := m.Called(ctx, tenantID, id)
 	return args.Error(0)
 }
 
-func (m *MockWebhookService) TriggerWebhook(ctx context.Context, tenantID uuid.UUID, eventType string, payload map[string]interface{}, eventID *uuid.UUID) error {
-	args := m.Called(ctx, tenantID, eventType, payload, eventID)
-	return args.Error(0)
+func (m *MockWebhookService) PauseWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) ResumeWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
 }
 
-func (m *MockWebhookService) GetDeliveriesByWebhook(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*models.WebhookDelivery, int, error) {
-	args := m.Called(ctx, webhookID, limit, offset)
+func (m *MockWebhookService) GetDeliveriesByWebhook(ctx context.Context, tenantID, webhookID uuid.UUID, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+	args := m.Called(ctx, tenantID, webhookID, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
 	}
 	return args.Get(0).([]*models.WebhookDelivery), args.Int(1), args.Error(2)
 }
 
-func (m *MockWebhookService) GetDeliveryByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
-	args := m.Called(ctx, id)
+func (m *MockWebhookService) GetDeliveryByID(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, tenantID, webhookID, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) SendTestEvent(ctx context.Context, tenantID, id uuid.UUID) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, tenantID, webhookID, deliveryID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -82,7 +109,7 @@ func TestWebhookHandler_CreateWebhook(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockService := &MockWebhookService{}
-		handler := NewWebhookHandler(mockService)
+		handler := NewWebhookHandler(mockService, nil)
 
 		router := gin.New()
 		router.Use(func(c *gin.Context) {
@@ -112,7 +139,7 @@ func TestWebhookHandler_CreateWebhook(t *testing.T) {
 
 	t.Run("invalid_request", func(t *testing.T) {
 		mockService := &MockWebhookService{}
-		handler := NewWebhookHandler(mockService)
+		handler := NewWebhookHandler(mockService, nil)
 
 		router := gin.New()
 		router.Use(func(c *gin.Context) {
@@ -135,7 +162,7 @@ func TestWebhookHandler_ListWebhooks(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockService := &MockWebhookService{}
-		handler := NewWebhookHandler(mockService)
+		handler := NewWebhookHandler(mockService, nil)
 
 		router := gin.New()
 		router.Use(func(c *gin.Context) {
@@ -159,16 +186,17 @@ func TestWebhookHandler_ListWebhooks(t *testing.T) {
 
 func TestWebhookHandler_GetWebhook(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	tenantID := uuid.New()
 
 	t.Run("success", func(t *testing.T) {
 		mockService := &MockWebhookService{}
-		handler := NewWebhookHandler(mockService)
+		handler := NewWebhookHandler(mockService, nil)
 		id := uuid.New()
 
-		router := gin.New()
+		router := newClaimsTestRouter(tenantID, uuid.New(), "webhooks:read")
 		router.GET("/webhooks/:id", handler.GetWebhook)
 
-		mockService.On("GetWebhook", mock.Anything, id).Return(&models.Webhook{
+		mockService.On("GetWebhook", mock.Anything, tenantID, id).Return(&models.Webhook{
 			ID: id, URL: "https://example.com",
 		}, nil)
 
@@ -181,13 +209,13 @@ func TestWebhookHandler_GetWebhook(t *testing.T) {
 
 	t.Run("not_found", func(t *testing.T) {
 		mockService := &MockWebhookService{}
-		handler := NewWebhookHandler(mockService)
+		handler := NewWebhookHandler(mockService, nil)
 		id := uuid.New()
 
-		router := gin.New()
+		router := newClaimsTestRouter(tenantID, uuid.New(), "webhooks:read")
 		router.GET("/webhooks/:id", handler.GetWebhook)
 
-		mockService.On("GetWebhook", mock.Anything, id).Return(nil, errors.New("not found"))
+		mockService.On("GetWebhook", mock.Anything, tenantID, id).Return(nil, errors.New("not found"))
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/webhooks/"+id.String(), nil)
@@ -199,16 +227,18 @@ func TestWebhookHandler_GetWebhook(t *testing.T) {
 
 func TestWebhookHandler_DeleteWebhook(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	tenantID := uuid.New()
 	id := uuid.New()
 
 	t.Run("success", func(t *testing.T) {
 		mockService := &MockWebhookService{}
-		handler := NewWebhookHandler(mockService)
+		handler := NewWebhookHandler(mockService, nil)
 
-		router := gin.New()
+		router := newClaimsTestRouter(tenantID, uuid.New(), "webhooks:manage")
 		router.DELETE("/webhooks/:id", handler.DeleteWebhook)
 
-		mockService.On("DeleteWebhook", mock.Anything, id).Return(nil)
+		mockService.On("GetWebhook", mock.Anything, tenantID, id).Return(&models.Webhook{ID: id, TenantID: tenantID}, nil)
+		mockService.On("DeleteWebhook", mock.Anything, tenantID, id).Return(nil)
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("DELETE", "/webhooks/"+id.String(), nil)
@@ -217,3 +247,155 @@ func TestWebhookHandler_DeleteWebhook(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, w.Code)
 	})
 }
+
+func TestWebhookHandler_ListEventTypes(t *testing.T) {
+	handler := NewWebhookHandler(&MockWebhookService{}, nil)
+
+	router := newClaimsTestRouter(uuid.New(), uuid.New(), "webhooks:read")
+	router.GET("/webhooks/events", handler.ListEventTypes)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/webhooks/events", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"type":"user.created"`)
+	assert.Contains(t, w.Body.String(), `"type":"webhook.disabled"`)
+}
+
+func TestWebhookHandler_CreateWebhook_UnknownEvent(t *testing.T) {
+	mockService := &MockWebhookService{}
+	handler := NewWebhookHandler(mockService, nil)
+	tenantID := uuid.New()
+
+	router := newClaimsTestRouter(tenantID, uuid.New(), "webhooks:manage")
+	router.POST("/webhooks", handler.CreateWebhook)
+
+	mockService.On("CreateWebhook", mock.Anything, tenantID, mock.Anything).
+		Return(nil, errors.New("unknown event type: user.exploded"))
+
+	reqBody := `{"name": "My Webhook", "url": "https://example.com/webhook", "secret": "12345678901234567890123456789012", "events": ["user.exploded"]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(reqBody))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "user.exploded")
+}
+
+func TestWebhookHandler_CreateWebhook_SigningAlgorithm(t *testing.T) {
+	tenantID := uuid.New()
+
+	for _, tc := range []struct {
+		name string
+		body string
+		want int
+	}{
+		{"hmac requires a secret", `{"name": "a", "url": "https://example.com/a", "events": ["user.created"]}`, http.StatusBadRequest},
+		{"ed25519 generates its key", `{"name": "b", "url": "https://example.com/b", "signing_algorithm": "ed25519", "events": ["user.created"]}`, http.StatusCreated},
+		{"unknown algorithm", `{"name": "c", "url": "https://example.com/c", "secret": "12345678901234567890123456789012", "signing_algorithm": "md5", "events": ["user.created"]}`, http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockWebhookService{}
+			handler := NewWebhookHandler(mockService, nil)
+			router := newClaimsTestRouter(tenantID, uuid.New(), "webhooks:manage")
+			router.POST("/webhooks", handler.CreateWebhook)
+
+			mockService.On("CreateWebhook", mock.Anything, tenantID, mock.Anything).Return(&models.Webhook{
+				ID:               uuid.New(),
+				TenantID:         tenantID,
+				SigningAlgorithm: models.WebhookSigningEd25519,
+				PublicKey:        "whpk_key",
+			}, nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(tc.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.want, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestWebhookHandler_UpdateWebhook_InvalidRotation(t *testing.T) {
+	mockService := &MockWebhookService{}
+	handler := NewWebhookHandler(mockService, nil)
+	tenantID := uuid.New()
+	id := uuid.New()
+
+	router := newClaimsTestRouter(tenantID, uuid.New(), "webhooks:manage")
+	router.PUT("/webhooks/:id", handler.UpdateWebhook)
+
+	mockService.On("UpdateWebhook", mock.Anything, tenantID, id, mock.Anything).
+		Return(nil, errors.New("invalid rotate_key: hmac-sha256 webhooks are rotated by setting a new secret"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/webhooks/"+id.String(), strings.NewReader(`{"rotate_key": true}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhookHandler_SendTestEvent(t *testing.T) {
+	mockService := &MockWebhookService{}
+	handler := NewWebhookHandler(mockService, nil)
+	tenantID := uuid.New()
+	id := uuid.New()
+
+	router := newClaimsTestRouter(tenantID, uuid.New(), "webhooks:manage")
+	router.POST("/webhooks/:id/test", handler.SendTestEvent)
+
+	status := 503
+	mockService.On("SendTestEvent", mock.Anything, tenantID, id).Return(&models.WebhookDelivery{
+		ID:             uuid.New(),
+		WebhookID:      id,
+		EventType:      webhook.EventTypeTest,
+		Status:         models.DeliveryStatusFailed,
+		HTTPStatusCode: &status,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/webhooks/"+id.String()+"/test", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "a failed ping is a result, not an error")
+	assert.Contains(t, w.Body.String(), `"http_status_code":503`)
+}
+
+func TestWebhookHandler_RedeliverDelivery(t *testing.T) {
+	mockService := &MockWebhookService{}
+	handler := NewWebhookHandler(mockService, nil)
+	tenantID := uuid.New()
+	webhookID := uuid.New()
+	deliveryID := uuid.New()
+
+	router := newClaimsTestRouter(tenantID, uuid.New(), "webhooks:manage")
+	router.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", handler.RedeliverDelivery)
+
+	t.Run("success", func(t *testing.T) {
+		mockService.On("Redeliver", mock.Anything, tenantID, webhookID, deliveryID).Return(&models.WebhookDelivery{
+			ID:        uuid.New(),
+			WebhookID: webhookID,
+			EventType: models.EventTypeUserCreated,
+			Status:    models.DeliveryStatusSuccess,
+		}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/webhooks/"+webhookID.String()+"/deliveries/"+deliveryID.String()+"/redeliver", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), deliveryID.String(), "redelivery creates a new delivery")
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		mockService.On("Redeliver", mock.Anything, tenantID, webhookID, deliveryID).
+			Return(nil, errors.New("delivery not found")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/webhooks/"+webhookID.String()+"/deliveries/"+deliveryID.String()+"/redeliver", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/arauth-identity/iam/identity/permission"
 	"github.com/arauth-identity/iam/observability/security_events"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/gin-gonic/gin"
@@ -145,17 +146,10 @@ func RequirePermission(resource, action string, eventLogger security_events.Logg
 		}
 
 		userPermissions := permissions.([]string)
-		requiredPermission := resource + ":" + action
+		requiredPermission := permission.Key(resource, action)
 
-		hasPermission := false
-		for _, perm := range userPermissions {
-			if perm == requiredPermission {
-				hasPermission = true
-				break
-			}
-		}
-
-		if !hasPermission {
+		// Wildcard and parent-resource grants are honored (see permission.Matches)
+		if _, hasPermission := permission.MatchAny(userPermissions, requiredPermission); !hasPermission {
 			// Log permission denial
 			if eventLogger != nil {
 				event := security_events.NewSecurityEvent(
@@ -206,14 +200,10 @@ func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
 		}
 
 		userPerms := userPermissions.([]string)
-		permissionSet := make(map[string]bool)
-		for _, perm := range userPerms {
-			permissionSet[perm] = true
-		}
 
 		hasAny := false
 		for _, requiredPerm := range permissions {
-			if permissionSet[requiredPerm] {
+			if _, ok := permission.MatchAny(userPerms, requiredPerm); ok {
 				hasAny = true
 				break
 			}
@@ -250,12 +240,6 @@ func HasPermission(c *gin.Context, resource, action string) bool {
 		return false
 	}
 
-	requiredPermission := resource + ":" + action
-	for _, perm := range permissions {
-		if perm == requiredPermission {
-			return true
-		}
-	}
-
-	return false
+	_, ok = permission.MatchAny(permissions, permission.Key(resource, action))
+	return ok
 }
@@ -38,6 +38,24 @@ func TestRequirePermission(t *testing.T) {
 			requiredPerm:   "users:read",
 			expectedStatus: http.StatusForbidden,
 		},
+		{
+			name:           "action wildcard grants permission",
+			userPermissions: []string{"users:*"},
+			requiredPerm:   "users:write",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "parent resource wildcard grants child resource",
+			userPermissions: []string{"billing:*"},
+			requiredPerm:   "billing.invoices:read",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "resource wildcard limited to action",
+			userPermissions: []string{"*:read"},
+			requiredPerm:   "users:write",
+			expectedStatus: http.StatusForbidden,
+		},
 	}
 
 	for _, tt := range tests {
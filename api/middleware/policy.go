@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arauth-identity/iam/auth/claims"
+	"github.com/arauth-identity/iam/identity/policy"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ResourceAttributeResolver returns the attributes of the resource a request targets.
+// It returns nil attributes when the resource has none worth exposing.
+type ResourceAttributeResolver func(c *gin.Context) (map[string]interface{}, error)
+
+// PolicyEnforcer applies a tenant's conditional access policies to routes that opt in.
+// Routes are still expected to check role permissions with RequirePermission; policies
+// only narrow what those permissions allow.
+type PolicyEnforcer struct {
+	policyService policy.ServiceInterface
+	userRepo      interfaces.UserRepository
+}
+
+// NewPolicyEnforcer creates a new policy enforcer
+func NewPolicyEnforcer(policyService policy.ServiceInterface, userRepo interfaces.UserRepository) *PolicyEnforcer {
+	return &PolicyEnforcer{
+		policyService: policyService,
+		userRepo:      userRepo,
+	}
+}
+
+// Require creates middleware that denies the request when the tenant's policies for
+// resource:action do not allow it. resolve may be nil for policies that only look at
+// the subject and request context. A nil enforcer lets every request through.
+func (e *PolicyEnforcer) Require(resource, action string, resolve ResourceAttributeResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if e == nil {
+			c.Next()
+			return
+		}
+
+		tenantID, ok := RequireTenant(c)
+		if !ok {
+			return
+		}
+
+		policies, err := e.policyService.Applicable(c.Request.Context(), tenantID, resource, action)
+		if err != nil {
+			RespondWithError(c, http.StatusInternalServerError, "policy_evaluation_error",
+				"Failed to evaluate access policies", nil)
+			c.Abort()
+			return
+		}
+		// Most routes have no policies; skip loading attributes entirely
+		if len(policies) == 0 {
+			c.Next()
+			return
+		}
+
+		input, ok := e.buildInput(c, tenantID, resolve)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		result := e.policyService.Decide(policies, input)
+		if !result.Allowed {
+			message := "Access denied: " + result.Reason
+			if result.Policy != nil {
+				message += " " + result.Policy.Name
+			}
+			RespondWithError(c, http.StatusForbidden, "policy_denied", message, nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// UserParam resolves the attributes of the user named by a path parameter, such
+// as the target of /users/:id routes. Users of other tenants resolve to no attributes.
+func (e *PolicyEnforcer) UserParam(param string) ResourceAttributeResolver {
+	return func(c *gin.Context) (map[string]interface{}, error) {
+		id, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return nil, nil
+		}
+		user, err := e.userRepo.GetByID(c.Request.Context(), id)
+		if err != nil {
+			return nil, nil
+		}
+		if tenantID, ok := GetTenantID(c); ok && (user.TenantID == nil || *user.TenantID != tenantID) {
+			return nil, nil
+		}
+		return policy.UserAttributes(user), nil
+	}
+}
+
+// buildInput gathers subject, resource, context and tenant attributes for the request.
+// It writes the error response and returns false when the subject cannot be resolved.
+func (e *PolicyEnforcer) buildInput(c *gin.Context, tenantID uuid.UUID, resolve ResourceAttributeResolver) (*policy.Input, bool) {
+	claimsObj, exists := c.Get("user_claims")
+	if !exists {
+		RespondWithError(c, http.StatusUnauthorized, "unauthorized", "User claims not found", nil)
+		return nil, false
+	}
+	userClaims := claimsObj.(*claims.Claims)
+
+	userID, err := uuid.Parse(userClaims.Subject)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "invalid_user_id", "Invalid user ID in token", nil)
+		return nil, false
+	}
+	user, err := e.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		RespondWithError(c, http.StatusForbidden, "policy_denied", "Access denied by policy", nil)
+		return nil, false
+	}
+
+	var resourceAttrs map[string]interface{}
+	if resolve != nil {
+		resourceAttrs, err = resolve(c)
+		if err != nil {
+			RespondWithError(c, http.StatusInternalServerError, "policy_evaluation_error",
+				"Failed to evaluate access policies", nil)
+			return nil, false
+		}
+	}
+
+	now := time.Now()
+	requestContext := &policy.RequestContext{
+		IP:   c.ClientIP(),
+		Time: &now,
+		AMR:  userClaims.AMR,
+	}
+	if userClaims.AuthTime > 0 {
+		authTime := userClaims.AuthTime
+		requestContext.AuthTime = &authTime
+	}
+
+	return &policy.Input{
+		Subject:  policy.SubjectAttributes(user, userClaims.Roles),
+		Resource: resourceAttrs,
+		Context:  policy.ContextAttributes(requestContext),
+		Tenant:   policy.TenantAttributes(tenantID),
+	}, true
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/arauth-identity/iam/identity/models"
 	"github.com/arauth-identity/iam/identity/scim"
 )
 
@@ -49,20 +50,60 @@ func SCIMAuthMiddleware(tokenService scim.TokenServiceInterface) gin.HandlerFunc
 			return
 		}
 
-		// Store token and tenant ID in context
+		// Enforce the token's client address allowlist
+		if !token.AllowsIP(c.ClientIP()) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+				"detail":  "Token may not be used from this address",
+				"status":  "403",
+			})
+			c.Abort()
+			return
+		}
+
+		// Store token and tenant ID in context. The tenant comes only from
+		// the token; the request context carries the token to the
+		// provisioning service for scope checks and auditing.
 		c.Set("scim_token", token)
 		c.Set("scim_tenant_id", token.TenantID)
 		c.Set("scim_scopes", token.Scopes)
+		c.Request = c.Request.WithContext(scim.WithCaller(c.Request.Context(), &scim.Caller{
+			Token:     token,
+			SourceIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
 
 		c.Next()
 	}
 }
 
-// RequireSCIMScope checks if the request has the required SCIM scope
-func RequireSCIMScope(requiredScope string) gin.HandlerFunc {
+// OptionalSCIMAuthMiddleware authenticates a SCIM token when the request
+// carries one, so discovery endpoints can describe the token's tenant.
+// Requests without an Authorization header pass through anonymously.
+func OptionalSCIMAuthMiddleware(tokenService scim.TokenServiceInterface) gin.HandlerFunc {
+	authenticate := SCIMAuthMiddleware(tokenService)
 	return func(c *gin.Context) {
-		scopes, exists := c.Get("scim_scopes")
-		if !exists {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
+// RequireSCIMScope checks that the request's token grants access to a
+// resource type ("users" or "groups"): read access for GET and HEAD
+// requests, write access for everything else
+func RequireSCIMScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access := "write"
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			access = "read"
+		}
+
+		tokenValue, exists := c.Get("scim_token")
+		token, _ := tokenValue.(*models.SCIMToken)
+		if !exists || token == nil {
 			c.JSON(http.StatusForbidden, gin.H{
 				"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
 				"detail":  "Insufficient permissions",
@@ -72,19 +113,10 @@ func RequireSCIMScope(requiredScope string) gin.HandlerFunc {
 			return
 		}
 
-		scopeList := scopes.([]string)
-		hasScope := false
-		for _, scope := range scopeList {
-			if scope == requiredScope || scope == "*" {
-				hasScope = true
-				break
-			}
-		}
-
-		if !hasScope {
+		if !token.HasScope(resource, access) {
 			c.JSON(http.StatusForbidden, gin.H{
 				"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
-				"detail":  "Insufficient permissions: " + requiredScope + " scope required",
+				"detail":  "Insufficient permissions: " + resource + "." + access + " scope required",
 				"status":  "403",
 			})
 			c.Abort()
@@ -94,4 +126,3 @@ func RequireSCIMScope(requiredScope string) gin.HandlerFunc {
 		c.Next()
 	}
 }
-
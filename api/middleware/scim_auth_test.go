@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/scim"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSCIMTokenService accepts a single token value
+type stubSCIMTokenService struct {
+	scim.TokenServiceInterface
+	secret string
+	token  *models.SCIMToken
+}
+
+func (s *stubSCIMTokenService) ValidateToken(ctx context.Context, tokenString string) (*models.SCIMToken, error) {
+	if tokenString != s.secret {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return s.token, nil
+}
+
+func newSCIMAuthTestRouter(token *models.SCIMToken) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	users := router.Group("/scim/v2/Users")
+	users.Use(SCIMAuthMiddleware(&stubSCIMTokenService{secret: "secret", token: token}), RequireSCIMScope("users"))
+	respond := func(c *gin.Context) {
+		caller, ok := scim.CallerFromContext(c.Request.Context())
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, caller.Token.Name)
+	}
+	users.GET("", respond)
+	users.POST("", respond)
+	return router
+}
+
+func scimAuthRequest(router *gin.Engine, method, remoteAddr string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, "/scim/v2/Users", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.RemoteAddr = remoteAddr
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestSCIMAuth_ScopeByMethod tests that reads and writes need their own scope
+func TestSCIMAuth_ScopeByMethod(t *testing.T) {
+	router := newSCIMAuthTestRouter(&models.SCIMToken{ID: uuid.New(), Name: "okta", Scopes: []string{"users.read"}})
+
+	w := scimAuthRequest(router, http.MethodGet, "203.0.113.7:4000")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "okta", w.Body.String(), "the token travels in the request context")
+
+	w = scimAuthRequest(router, http.MethodPost, "203.0.113.7:4000")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "users.write scope required")
+}
+
+// TestSCIMAuth_IPAllowlist tests that tokens are refused outside their allowlist
+func TestSCIMAuth_IPAllowlist(t *testing.T) {
+	router := newSCIMAuthTestRouter(&models.SCIMToken{
+		ID:         uuid.New(),
+		Name:       "okta",
+		Scopes:     []string{"users"},
+		AllowedIPs: []string{"203.0.113.0/24"},
+	})
+
+	assert.Equal(t, http.StatusOK, scimAuthRequest(router, http.MethodPost, "203.0.113.7:4000").Code)
+
+	w := scimAuthRequest(router, http.MethodGet, "198.51.100.1:4000")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "may not be used from this address")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/arauth-identity/iam/auth/claims"
 	"github.com/arauth-identity/iam/auth/token"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/permission"
 )
 
 // RequireSystemUser ensures user is a SYSTEM principal
@@ -82,18 +83,9 @@ func RequireSystemPermission(resource, action string) gin.HandlerFunc {
 			return
 		}
 
-		requiredPerm := resource + ":" + action
-		hasPermission := false
-		for _, perm := range userClaims.SystemPermissions {
-			if perm == requiredPerm || perm == resource+":*" || perm == "*:*" {
-				hasPermission = true
-				break
-			}
-		}
-
-		if !hasPermission {
+		if !userClaims.HasSystemPermission(resource, action) {
 			RespondWithError(c, http.StatusForbidden, "forbidden",
-				"Required permission: "+requiredPerm, nil)
+				"Required permission: "+permission.Key(resource, action), nil)
 			c.Abort()
 			return
 		}
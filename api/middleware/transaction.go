@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TxRunner runs a function in a database transaction that repositories join
+// through the function's context
+type TxRunner = interfaces.TxRunner
+
+// TenantInvalidator drops a tenant's cached authorization grants
+type TenantInvalidator interface {
+	InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// errResponseFailed rolls back the transaction of a request that failed
+var errResponseFailed = errors.New("request failed")
+
+// commitOnClientErrorKey marks a request whose changes commit despite a 4xx
+const commitOnClientErrorKey = "tx_commit_on_client_error"
+
+// invalidationsKey carries the tenants invalidated during a request
+type invalidationsKey struct{}
+
+// invalidations collects the tenants whose grants a request invalidated
+type invalidations struct {
+	mu      sync.Mutex
+	tenants []uuid.UUID
+}
+
+func (i *invalidations) add(tenantID uuid.UUID) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, id := range i.tenants {
+		if id == tenantID {
+			return
+		}
+	}
+	i.tenants = append(i.tenants, tenantID)
+}
+
+// Transactional runs each mutating request in one database transaction, so
+// a change, its audit event and the event's outbox entry commit together.
+// The response is held back until the transaction ends: the transaction
+// commits when the handler responds with a status below 400, or below 500
+// after CommitOnClientError, and rolls back otherwise, and a failed commit turns the response into a 500. Tenants
+// whose grants were invalidated through TrackInvalidations are invalidated
+// again once the transaction ends, since grants may have been cached from
+// data the transaction changed or rolled back. A nil runner lets every
+// request through.
+func Transactional(runner TxRunner, invalidator TenantInvalidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if runner == nil || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		header := original.Header().Clone()
+		writer := &bufferedResponseWriter{ResponseWriter: original, status: http.StatusOK, size: -1}
+		c.Writer = writer
+		// Restored on panic too, so recovery can still respond
+		defer func() { c.Writer = original }()
+
+		request := c.Request
+		pending := &invalidations{}
+		err := runner.WithinTx(context.WithValue(request.Context(), invalidationsKey{}, pending), func(ctx context.Context) error {
+			c.Request = request.WithContext(ctx)
+			c.Next()
+			if writer.status >= http.StatusInternalServerError ||
+				(writer.status >= http.StatusBadRequest && !c.GetBool(commitOnClientErrorKey)) {
+				return errResponseFailed
+			}
+			return nil
+		})
+		c.Request = request
+
+		if invalidator != nil {
+			for _, tenantID := range pending.tenants {
+				_ = invalidator.InvalidateTenant(request.Context(), tenantID)
+			}
+		}
+
+		if err != nil && !errors.Is(err, errResponseFailed) {
+			// Nothing was saved, so the handler's response must not be sent
+			resetHeader(original.Header(), header)
+			c.Writer = original
+			RespondWithError(c, http.StatusInternalServerError, "transaction_failed",
+				"Failed to save changes", nil)
+			c.Abort()
+			return
+		}
+
+		writer.flush()
+	}
+}
+
+// CommitOnClientError makes Transactional commit a request's changes when
+// it fails with a 4xx, for routes whose rejections record something that
+// must last, such as a wrong password counting towards lockout. It must
+// run after Transactional.
+func CommitOnClientError(c *gin.Context) {
+	c.Set(commitOnClientErrorKey, true)
+	c.Next()
+}
+
+// TrackInvalidations wraps a TenantInvalidator so that tenants it
+// invalidates during a Transactional request are invalidated again once
+// the request's transaction ends
+func TrackInvalidations(next TenantInvalidator) TenantInvalidator {
+	return &trackingInvalidator{next: next}
+}
+
+type trackingInvalidator struct {
+	next TenantInvalidator
+}
+
+func (i *trackingInvalidator) InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	if pending, ok := ctx.Value(invalidationsKey{}).(*invalidations); ok {
+		pending.add(tenantID)
+	}
+	return i.next.InvalidateTenant(ctx, tenantID)
+}
+
+// resetHeader replaces header's fields with those in snapshot
+func resetHeader(header, snapshot http.Header) {
+	for key := range header {
+		delete(header, key)
+	}
+	for key, values := range snapshot {
+		header[key] = values
+	}
+}
+
+// bufferedResponseWriter holds a response back until flush, so it can be
+// replaced if the request's transaction fails to commit
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+	size   int
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+	}
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.body.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	return w.size
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.size != -1
+}
+
+// Flush does nothing; the response is sent by flush
+func (w *bufferedResponseWriter) Flush() {}
+
+// flush sends the held response
+func (w *bufferedResponseWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.Written() {
+		w.ResponseWriter.WriteHeaderNow()
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
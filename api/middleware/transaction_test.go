@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type txContextKey struct{}
+
+// fakeTxRunner records how the transaction ended
+type fakeTxRunner struct {
+	commitErr error
+	began     int
+	committed bool
+	err       error
+}
+
+func (r *fakeTxRunner) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.began++
+	r.err = fn(context.WithValue(ctx, txContextKey{}, true))
+	if r.err != nil {
+		return r.err
+	}
+	if r.commitErr != nil {
+		return r.commitErr
+	}
+	r.committed = true
+	return nil
+}
+
+type recordingInvalidator struct {
+	tenants []uuid.UUID
+}
+
+func (i *recordingInvalidator) InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	i.tenants = append(i.tenants, tenantID)
+	return nil
+}
+
+func newTransactionalRouter(runner TxRunner, invalidator TenantInvalidator, tenantID uuid.UUID, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(TenantContextKey, tenantID)
+		c.Next()
+	})
+	router.Use(Transactional(runner, invalidator))
+	router.GET("/test", handler)
+	router.POST("/test", handler)
+	return router
+}
+
+func TestTransactional_CommitsSuccessfulRequest(t *testing.T) {
+	runner := &fakeTxRunner{}
+	invalidator := &recordingInvalidator{}
+	router := newTransactionalRouter(runner, invalidator, uuid.New(), func(c *gin.Context) {
+		assert.Equal(t, true, c.Request.Context().Value(txContextKey{}))
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/test", nil)
+	router.ServeHTTP(w, req)
+
+	assert.True(t, runner.committed)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"id":"1"}`, w.Body.String())
+	// Nothing was invalidated during the request
+	assert.Empty(t, invalidator.tenants)
+}
+
+func TestTransactional_RollsBackFailedRequest(t *testing.T) {
+	runner := &fakeTxRunner{}
+	router := newTransactionalRouter(runner, nil, uuid.New(), func(c *gin.Context) {
+		c.JSON(http.StatusConflict, gin.H{"error": "sod_violation"})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/test", nil)
+	router.ServeHTTP(w, req)
+
+	assert.False(t, runner.committed)
+	assert.Error(t, runner.err)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "sod_violation")
+}
+
+func TestTransactional_CommitOnClientError(t *testing.T) {
+	runner := &fakeTxRunner{}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Transactional(runner, nil))
+	router.POST("/password", CommitOnClientError, func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "current password is incorrect"})
+	})
+	router.POST("/broken", CommitOnClientError, func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error"})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/password", nil)
+	router.ServeHTTP(w, req)
+	assert.True(t, runner.committed)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	runner.committed = false
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/broken", nil)
+	router.ServeHTTP(w, req)
+	assert.False(t, runner.committed)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestTransactional_InvalidatesTrackedTenantsAfterTx(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusConflict} {
+		runner := &fakeTxRunner{}
+		recorder := &recordingInvalidator{}
+		tracked := TrackInvalidations(recorder)
+		tenantID := uuid.New()
+		router := newTransactionalRouter(runner, recorder, uuid.New(), func(c *gin.Context) {
+			_ = tracked.InvalidateTenant(c.Request.Context(), tenantID)
+			_ = tracked.InvalidateTenant(c.Request.Context(), tenantID)
+			assert.Len(t, recorder.tenants, 2)
+			c.JSON(status, gin.H{})
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/test", nil)
+		router.ServeHTTP(w, req)
+
+		// Invalidated once more after the transaction, committed or not
+		assert.Equal(t, []uuid.UUID{tenantID, tenantID, tenantID}, recorder.tenants)
+	}
+}
+
+func TestTransactional_FailedCommitReplacesResponse(t *testing.T) {
+	runner := &fakeTxRunner{commitErr: errors.New("connection reset")}
+	router := newTransactionalRouter(runner, nil, uuid.New(), func(c *gin.Context) {
+		c.Header("Location", "/test/1")
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/test", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "transaction_failed")
+	assert.NotContains(t, w.Body.String(), `"id"`)
+	assert.Empty(t, w.Header().Get("Location"))
+}
+
+func TestTransactional_SkipsReads(t *testing.T) {
+	runner := &fakeTxRunner{}
+	router := newTransactionalRouter(runner, nil, uuid.New(), func(c *gin.Context) {
+		assert.Nil(t, c.Request.Context().Value(txContextKey{}))
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Zero(t, runner.began)
+}
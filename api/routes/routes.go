@@ -38,7 +38,7 @@ func getRedis(redisClient interface{}) *redis.Client {
 }
 
 // SetupRoutes configures all routes
-func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler, mfaHandler *handlers.MFAHandler, tenantHandler *handlers.TenantHandler, roleHandler *handlers.RoleHandler, permissionHandler *handlers.PermissionHandler, systemHandler *handlers.SystemHandler, capabilityHandler *handlers.CapabilityHandler, auditHandler *handlers.AuditHandler, federationHandler *handlers.FederationHandler, webhookHandler *handlers.WebhookHandler, identityLinkingHandler *handlers.IdentityLinkingHandler, introspectionHandler *handlers.IntrospectionHandler, impersonationHandler *handlers.ImpersonationHandler, oauthScopeHandler *handlers.OAuthScopeHandler, scimHandler *handlers.SCIMHandler, scimTokenHandler *handlers.SCIMTokenHandler, scimTokenService scim.TokenServiceInterface, invitationHandler *handlers.InvitationHandler, sessionHandler *handlers.SessionHandler, oauthClientHandler *handlers.OAuthClientHandler, tenantRepo interfaces.TenantRepository, cacheClient *cache.Cache, db interface{}, redisClient interface{}, tokenService interface{}, rateLimiter ratelimit.Limiter, eventLogger security_events.Logger) {
+func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler, mfaHandler *handlers.MFAHandler, tenantHandler *handlers.TenantHandler, roleHandler *handlers.RoleHandler, permissionHandler *handlers.PermissionHandler, systemHandler *handlers.SystemHandler, capabilityHandler *handlers.CapabilityHandler, auditHandler *handlers.AuditHandler, federationHandler *handlers.FederationHandler, webhookHandler *handlers.WebhookHandler, identityLinkingHandler *handlers.IdentityLinkingHandler, introspectionHandler *handlers.IntrospectionHandler, impersonationHandler *handlers.ImpersonationHandler, oauthScopeHandler *handlers.OAuthScopeHandler, scimHandler *handlers.SCIMHandler, scimTokenHandler *handlers.SCIMTokenHandler, scimSchemaHandler *handlers.SCIMSchemaHandler, scimConnectorHandler *handlers.SCIMConnectorHandler, scimTokenService scim.TokenServiceInterface, invitationHandler *handlers.InvitationHandler, sessionHandler *handlers.SessionHandler, meHandler *handlers.MeHandler, oauthClientHandler *handlers.OAuthClientHandler, authzHandler *handlers.AuthzHandler, groupHandler *handlers.GroupHandler, policyHandler *handlers.PolicyHandler, policyEnforcer *middleware.PolicyEnforcer, txRunner middleware.TxRunner, tenantInvalidator middleware.TenantInvalidator, relationHandler *handlers.RelationHandler, elevationHandler *handlers.ElevationHandler, accessReviewHandler *handlers.AccessReviewHandler, sodHandler *handlers.SoDHandler, jobHandler *handlers.JobHandler, tenantRepo interfaces.TenantRepository, cacheClient *cache.Cache, db interface{}, redisClient interface{}, tokenService interface{}, rateLimiter ratelimit.Limiter, eventLogger security_events.Logger) {
 	// Global middleware
 	router.Use(middleware.CORS())
 	router.Use(middleware.Logging(logger))
@@ -52,6 +52,10 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 		router.Use(middleware.RateLimit(cacheClient))
 	}
 
+	// Every mutating request runs in one database transaction, so its
+	// changes commit together with their audit events and outbox entries
+	router.Use(middleware.Transactional(txRunner, tenantInvalidator))
+
 	// Health check (no rate limiting)
 	healthHandler := handlers.NewHealthHandlerWithDeps(getDB(db), cacheClient, getRedis(redisClient))
 	router.GET("/health", healthHandler.Check)
@@ -120,6 +124,18 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 			systemPermissions.GET("", permissionHandler.ListSystem)
 		}
 
+		// Background jobs shared by all replicas
+		systemJobs := systemAPI.Group("/jobs")
+		{
+			systemJobs.GET("", middleware.RequireSystemPermission("jobs", "read"), jobHandler.ListJobs)
+			systemJobs.GET("/:name", middleware.RequireSystemPermission("jobs", "read"), jobHandler.GetJob)
+			systemJobs.GET("/:name/runs", middleware.RequireSystemPermission("jobs", "read"), jobHandler.ListJobRuns)
+			systemJobs.POST("/:name/run", middleware.RequireSystemPermission("jobs", "run"), jobHandler.TriggerJob)
+		}
+
+		// Audit log integrity: checks the audit hash chains for tampering
+		systemAPI.GET("/audit/verify", middleware.RequireSystemPermission("system", "audit"), auditHandler.VerifyChains)
+
 		// System settings management (future)
 		// systemAPI.GET("/settings", systemHandler.GetSystemSettings)
 		// systemAPI.PUT("/settings", systemHandler.UpdateSystemSettings)
@@ -141,8 +157,10 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 		}
 
 		// Auth routes (public - no tenant middleware required)
-		// SYSTEM users can login without tenant, TENANT users can provide tenant_id in request
+		// SYSTEM users can login without tenant, TENANT users can provide tenant_id in request.
+		// Failed attempts are recorded even though the request fails.
 		auth := v1.Group("/auth")
+		auth.Use(middleware.CommitOnClientError)
 		{
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
@@ -151,6 +169,7 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 
 		// MFA challenge endpoints (public - called during login flow before token is issued)
 		mfaPublic := v1.Group("/mfa")
+		mfaPublic.Use(middleware.CommitOnClientError)
 		{
 			mfaPublic.POST("/challenge", mfaHandler.Challenge)
 			mfaPublic.POST("/challenge/verify", mfaHandler.VerifyChallenge)
@@ -195,10 +214,13 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 				users.PUT("/:id/identities/:identity_id/primary", middleware.RequirePermission("users", "identities:manage", eventLogger), identityLinkingHandler.SetPrimaryIdentity)
 				users.POST("/:id/identities/:identity_id/verify", middleware.RequirePermission("users", "identities:verify", eventLogger), identityLinkingHandler.VerifyIdentity)
 				// Generic user routes
+				// Admin MFA reset (removes all factors and forces re-enrollment)
+				users.POST("/:id/mfa/reset", middleware.RequirePermission("users", "mfa:reset", eventLogger), mfaHandler.ResetUserMFA)
 				users.POST("/:id/change-password", middleware.RequirePermission("users", "update", eventLogger), userHandler.ChangePassword)
-				users.GET("/:id", middleware.RequirePermission("users", "read", eventLogger), userHandler.GetByID)
-				users.PUT("/:id", middleware.RequirePermission("users", "update", eventLogger), userHandler.Update)
-				users.DELETE("/:id", middleware.RequirePermission("users", "delete", eventLogger), userHandler.Delete)
+				// Conditional policies can narrow access to individual users (e.g. by department)
+				users.GET("/:id", middleware.RequirePermission("users", "read", eventLogger), policyEnforcer.Require("users", "read", policyEnforcer.UserParam("id")), userHandler.GetByID)
+				users.PUT("/:id", middleware.RequirePermission("users", "update", eventLogger), policyEnforcer.Require("users", "update", policyEnforcer.UserParam("id")), userHandler.Update)
+				users.DELETE("/:id", middleware.RequirePermission("users", "delete", eventLogger), policyEnforcer.Require("users", "delete", policyEnforcer.UserParam("id")), userHandler.Delete)
 			}
 
 			// Session routes (tenant-scoped)
@@ -208,6 +230,27 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 				sessions.POST("/:id/revoke", middleware.RequirePermission("sessions", "revoke", eventLogger), sessionHandler.RevokeSession)
 			}
 
+			// Self-service routes (tenant-scoped)
+			// No extra permission required - users can only act on their own account
+			me := tenantScoped.Group("/me")
+			{
+				me.GET("", meHandler.GetProfile)
+				me.PUT("", meHandler.UpdateProfile)
+				// A wrong current password counts towards lockout
+				me.POST("/password", middleware.CommitOnClientError, meHandler.ChangePassword)
+				me.GET("/mfa", meHandler.GetMFAStatus)
+				me.POST("/mfa/enroll", meHandler.EnrollMFA)
+				me.POST("/mfa/verify", middleware.CommitOnClientError, meHandler.VerifyMFA)
+				me.POST("/mfa/disable", meHandler.DisableMFA)
+				me.POST("/mfa/recovery-codes", meHandler.RegenerateRecoveryCodes)
+				me.DELETE("/mfa/factors/:factor_id", meHandler.RemoveMFAFactor)
+				me.GET("/identities", meHandler.ListIdentities)
+				me.DELETE("/identities/:identity_id", meHandler.UnlinkIdentity)
+				me.PUT("/identities/:identity_id/primary", meHandler.SetPrimaryIdentity)
+				me.GET("/sessions", sessionHandler.ListMySessions)
+				me.DELETE("/sessions/:id", sessionHandler.RevokeMySession)
+			}
+
 			// OAuth client routes (tenant-scoped)
 			oauthClients := tenantScoped.Group("/oauth/clients")
 			{
@@ -218,11 +261,23 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 				oauthClients.DELETE("/:id", middleware.RequirePermission("oauth", "clients:delete", eventLogger), oauthClientHandler.DeleteClient)
 			}
 
+			// Authorization decision routes (tenant-scoped)
+			// Lets downstream services ask for live allow/deny decisions instead of matching token claims
+			authzRoutes := tenantScoped.Group("/authz")
+			{
+				authzRoutes.POST("/check", middleware.RequirePermission("authz", "check", eventLogger), authzHandler.Check)
+				authzRoutes.POST("/check/batch", middleware.RequirePermission("authz", "check", eventLogger), authzHandler.CheckBatch)
+				// Support tooling: why a request was decided as it was, and what a change would do
+				authzRoutes.POST("/explain", middleware.RequirePermission("authz", "explain", eventLogger), authzHandler.Explain)
+				authzRoutes.POST("/what-if", middleware.RequirePermission("authz", "explain", eventLogger), authzHandler.WhatIf)
+			}
+
 			// MFA routes (tenant-scoped - require authentication)
 			mfa := tenantScoped.Group("/mfa")
 			{
 				mfa.POST("/enroll", mfaHandler.Enroll)
-				mfa.POST("/verify", mfaHandler.Verify)
+				// Failed verifications are audited even though the request fails.
+				mfa.POST("/verify", middleware.CommitOnClientError, mfaHandler.Verify)
 			}
 
 			// Role routes (tenant-scoped)
@@ -231,16 +286,101 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 			{
 				roles.POST("", middleware.RequirePermission("roles", "create", eventLogger), roleHandler.Create)
 				roles.GET("", middleware.RequirePermission("roles", "read", eventLogger), roleHandler.List)
+				// Just-in-time elevation: any tenant user may request a role for themselves and
+				// see their own requests; deciding needs roles:approve
+				roles.POST("/elevations", elevationHandler.Request)
+				roles.GET("/elevations", elevationHandler.List)
+				roles.GET("/elevations/:id", elevationHandler.GetByID)
+				roles.POST("/elevations/:id/approve", middleware.RequirePermission("roles", "approve", eventLogger), elevationHandler.Approve)
+				roles.POST("/elevations/:id/deny", middleware.RequirePermission("roles", "approve", eventLogger), elevationHandler.Deny)
+				roles.POST("/elevations/:id/cancel", elevationHandler.Cancel)
 				// Permission routes (must come before :id routes to avoid conflict)
 				roles.GET("/:id/permissions", middleware.RequirePermission("roles", "read", eventLogger), roleHandler.GetRolePermissions)
 				roles.POST("/:id/permissions/:permission_id", middleware.RequirePermission("roles", "permissions:assign", eventLogger), roleHandler.AssignPermissionToRole)
 				roles.DELETE("/:id/permissions/:permission_id", middleware.RequirePermission("roles", "permissions:remove", eventLogger), roleHandler.RemovePermissionFromRole)
+				roles.GET("/:id/effective-permissions", middleware.RequirePermission("roles", "read", eventLogger), roleHandler.GetEffectivePermissions)
+				roles.GET("/:id/parents", middleware.RequirePermission("roles", "read", eventLogger), roleHandler.GetParentRoles)
+				roles.POST("/:id/parents/:parent_id", middleware.RequirePermission("roles", "update", eventLogger), roleHandler.AddParentRole)
+				roles.DELETE("/:id/parents/:parent_id", middleware.RequirePermission("roles", "update", eventLogger), roleHandler.RemoveParentRole)
 				// Generic role routes
 				roles.GET("/:id", middleware.RequirePermission("roles", "read", eventLogger), roleHandler.GetByID)
 				roles.PUT("/:id", middleware.RequirePermission("roles", "update", eventLogger), roleHandler.Update)
 				roles.DELETE("/:id", middleware.RequirePermission("roles", "delete", eventLogger), roleHandler.Delete)
 			}
 
+			// Access review (recertification) routes (tenant-scoped).
+			// Reviewers need no permission to see and decide the items assigned to them.
+			accessReviews := tenantScoped.Group("/access-reviews")
+			{
+				accessReviews.POST("", middleware.RequirePermission("access_reviews", "manage", eventLogger), accessReviewHandler.Create)
+				accessReviews.GET("", middleware.RequirePermission("access_reviews", "read", eventLogger), accessReviewHandler.List)
+				accessReviews.GET("/my-items", accessReviewHandler.MyItems)
+				accessReviews.POST("/items/:item_id/decision", accessReviewHandler.Decide)
+				accessReviews.GET("/:id", middleware.RequirePermission("access_reviews", "read", eventLogger), accessReviewHandler.GetByID)
+				accessReviews.GET("/:id/items", middleware.RequirePermission("access_reviews", "read", eventLogger), accessReviewHandler.ListItems)
+				accessReviews.GET("/:id/report", middleware.RequirePermission("access_reviews", "read", eventLogger), accessReviewHandler.Export)
+				accessReviews.POST("/:id/close", middleware.RequirePermission("access_reviews", "manage", eventLogger), accessReviewHandler.Close)
+				accessReviews.POST("/:id/cancel", middleware.RequirePermission("access_reviews", "manage", eventLogger), accessReviewHandler.Cancel)
+				accessReviews.POST("/:id/remind", middleware.RequirePermission("access_reviews", "manage", eventLogger), accessReviewHandler.Remind)
+			}
+
+			// Separation-of-duties rule routes (tenant-scoped)
+			sodRules := tenantScoped.Group("/sod-rules")
+			{
+				sodRules.POST("", middleware.RequirePermission("sod_rules", "manage", eventLogger), sodHandler.Create)
+				sodRules.GET("", middleware.RequirePermission("sod_rules", "read", eventLogger), sodHandler.List)
+				sodRules.GET("/violations", middleware.RequirePermission("sod_rules", "read", eventLogger), sodHandler.Violations)
+				sodRules.GET("/:id", middleware.RequirePermission("sod_rules", "read", eventLogger), sodHandler.GetByID)
+				sodRules.GET("/:id/violations", middleware.RequirePermission("sod_rules", "read", eventLogger), sodHandler.Violations)
+				sodRules.PUT("/:id", middleware.RequirePermission("sod_rules", "manage", eventLogger), sodHandler.Update)
+				sodRules.DELETE("/:id", middleware.RequirePermission("sod_rules", "manage", eventLogger), sodHandler.Delete)
+			}
+
+			// Group routes (tenant-scoped)
+			groups := tenantScoped.Group("/groups")
+			{
+				groups.POST("", middleware.RequirePermission("groups", "create", eventLogger), groupHandler.Create)
+				groups.GET("", middleware.RequirePermission("groups", "read", eventLogger), groupHandler.List)
+				// Membership and role routes (must come before :id routes to avoid conflict)
+				groups.GET("/:id/members", middleware.RequirePermission("groups", "read", eventLogger), groupHandler.ListMembers)
+				groups.POST("/:id/members/:user_id", middleware.RequirePermission("groups", "members:assign", eventLogger), groupHandler.AddMember)
+				groups.DELETE("/:id/members/:user_id", middleware.RequirePermission("groups", "members:remove", eventLogger), groupHandler.RemoveMember)
+				groups.POST("/:id/groups/:member_group_id", middleware.RequirePermission("groups", "members:assign", eventLogger), groupHandler.AddMemberGroup)
+				groups.DELETE("/:id/groups/:member_group_id", middleware.RequirePermission("groups", "members:remove", eventLogger), groupHandler.RemoveMemberGroup)
+				groups.GET("/:id/roles", middleware.RequirePermission("groups", "read", eventLogger), groupHandler.GetRoles)
+				groups.POST("/:id/roles/:role_id", middleware.RequirePermission("groups", "roles:assign", eventLogger), groupHandler.AssignRole)
+				groups.DELETE("/:id/roles/:role_id", middleware.RequirePermission("groups", "roles:remove", eventLogger), groupHandler.RemoveRole)
+				// Generic group routes
+				groups.GET("/:id", middleware.RequirePermission("groups", "read", eventLogger), groupHandler.GetByID)
+				groups.PUT("/:id", middleware.RequirePermission("groups", "update", eventLogger), groupHandler.Update)
+				groups.DELETE("/:id", middleware.RequirePermission("groups", "delete", eventLogger), groupHandler.Delete)
+			}
+
+			// Conditional access policy routes (tenant-scoped)
+			policies := tenantScoped.Group("/policies")
+			{
+				policies.POST("", middleware.RequirePermission("policies", "create", eventLogger), policyHandler.Create)
+				policies.GET("", middleware.RequirePermission("policies", "read", eventLogger), policyHandler.List)
+				policies.POST("/simulate", middleware.RequirePermission("policies", "read", eventLogger), policyHandler.Simulate)
+				policies.GET("/:id", middleware.RequirePermission("policies", "read", eventLogger), policyHandler.GetByID)
+				policies.PUT("/:id", middleware.RequirePermission("policies", "update", eventLogger), policyHandler.Update)
+				policies.DELETE("/:id", middleware.RequirePermission("policies", "delete", eventLogger), policyHandler.Delete)
+			}
+
+			// Relationship tuple routes (tenant-scoped)
+			relations := tenantScoped.Group("/relations")
+			{
+				relations.GET("/namespaces", middleware.RequirePermission("relations", "read", eventLogger), relationHandler.ListNamespaces)
+				relations.GET("/namespaces/:name", middleware.RequirePermission("relations", "read", eventLogger), relationHandler.GetNamespace)
+				relations.PUT("/namespaces/:name", middleware.RequirePermission("relations", "manage", eventLogger), relationHandler.PutNamespace)
+				relations.DELETE("/namespaces/:name", middleware.RequirePermission("relations", "manage", eventLogger), relationHandler.DeleteNamespace)
+				relations.GET("/tuples", middleware.RequirePermission("relations", "read", eventLogger), relationHandler.ReadTuples)
+				relations.POST("/tuples/write", middleware.RequirePermission("relations", "write", eventLogger), relationHandler.WriteTuples)
+				relations.POST("/check", middleware.RequirePermission("relations", "read", eventLogger), relationHandler.Check)
+				relations.POST("/expand", middleware.RequirePermission("relations", "read", eventLogger), relationHandler.Expand)
+				relations.POST("/list-objects", middleware.RequirePermission("relations", "read", eventLogger), relationHandler.ListObjects)
+			}
+
 			// Permission routes (tenant-scoped)
 			permissions := tenantScoped.Group("/permissions")
 			{
@@ -295,6 +435,7 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 		// Federation authentication routes (public, no auth required for initiation)
 		// These routes handle OIDC/SAML login flows
 		federationAuth := v1.Group("/auth")
+		federationAuth.Use(middleware.CommitOnClientError)
 		{
 			federationAuth.GET("/oidc/:provider_id/initiate", federationHandler.InitiateOIDCLogin)
 			federationAuth.GET("/oidc/:provider_id/callback", federationHandler.HandleOIDCCallback)
@@ -302,9 +443,10 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 			federationAuth.POST("/saml/:provider_id/callback", federationHandler.HandleSAMLCallback)
 		}
 
-		// Token introspection endpoint (RFC 7662)
-		// Requires authentication (client credentials or bearer token)
+		// Token introspection (RFC 7662) and revocation (RFC 7009) endpoints
+		// Require OAuth client authentication (client_secret_basic or client_secret_post)
 		v1.POST("/introspect", introspectionHandler.IntrospectToken)
+		v1.POST("/oauth/revoke", introspectionHandler.RevokeToken)
 
 		// Impersonation endpoints (tenant-scoped, requires admin permission)
 		impersonation := tenantScoped.Group("/impersonation")
@@ -328,10 +470,10 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 		// SCIM 2.0 API routes (public, authenticated via Bearer token)
 		scimV2 := router.Group("/scim/v2")
 		{
-			// SCIM discovery endpoints (no auth required)
+			// SCIM discovery endpoints (no auth required; a SCIM token adds the tenant's extension schemas)
 			scimV2.GET("/ServiceProviderConfig", scimHandler.ServiceProviderConfig)
-			scimV2.GET("/ResourceTypes", scimHandler.ResourceTypes)
-			scimV2.GET("/Schemas", scimHandler.Schemas)
+			scimV2.GET("/ResourceTypes", middleware.OptionalSCIMAuthMiddleware(scimTokenService), scimHandler.ResourceTypes)
+			scimV2.GET("/Schemas", middleware.OptionalSCIMAuthMiddleware(scimTokenService), scimHandler.Schemas)
 
 			// SCIM resource endpoints (require authentication)
 			scimUsers := scimV2.Group("/Users")
@@ -342,6 +484,7 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 				scimUsers.GET("", scimHandler.ListUsers)
 				scimUsers.GET("/:id", scimHandler.GetUser)
 				scimUsers.PUT("/:id", scimHandler.UpdateUser)
+				scimUsers.PATCH("/:id", scimHandler.PatchUser)
 				scimUsers.DELETE("/:id", scimHandler.DeleteUser)
 			}
 
@@ -353,6 +496,7 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 				scimGroups.GET("", scimHandler.ListGroups)
 				scimGroups.GET("/:id", scimHandler.GetGroup)
 				scimGroups.PUT("/:id", scimHandler.UpdateGroup)
+				scimGroups.PATCH("/:id", scimHandler.PatchGroup)
 				scimGroups.DELETE("/:id", scimHandler.DeleteGroup)
 			}
 
@@ -370,10 +514,53 @@ func SetupRoutes(router *gin.Engine, logger *zap.Logger, userHandler *handlers.U
 			scimTokens.POST("", middleware.RequirePermission("scim_tokens", "create", eventLogger), scimTokenHandler.CreateToken)
 			scimTokens.GET("", middleware.RequirePermission("scim_tokens", "read", eventLogger), scimTokenHandler.ListTokens)
 			scimTokens.GET("/:id", middleware.RequirePermission("scim_tokens", "read", eventLogger), scimTokenHandler.GetToken)
+			scimTokens.PUT("/:id", middleware.RequirePermission("scim_tokens", "write", eventLogger), scimTokenHandler.UpdateToken)
 			scimTokens.POST("/:id/rotate", middleware.RequirePermission("scim_tokens", "write", eventLogger), scimTokenHandler.RotateToken)
 			scimTokens.DELETE("/:id", middleware.RequirePermission("scim_tokens", "delete", eventLogger), scimTokenHandler.DeleteToken)
 		}
 
+		// SCIM extension schema routes (tenant-scoped)
+		scimSchemas := tenantScoped.Group("/scim/schemas")
+		{
+			scimSchemas.POST("", middleware.RequirePermission("scim_schemas", "manage", eventLogger), scimSchemaHandler.Create)
+			scimSchemas.GET("", middleware.RequirePermission("scim_schemas", "read", eventLogger), scimSchemaHandler.List)
+			scimSchemas.GET("/:id", middleware.RequirePermission("scim_schemas", "read", eventLogger), scimSchemaHandler.GetByID)
+			scimSchemas.PUT("/:id", middleware.RequirePermission("scim_schemas", "manage", eventLogger), scimSchemaHandler.Update)
+			scimSchemas.DELETE("/:id", middleware.RequirePermission("scim_schemas", "manage", eventLogger), scimSchemaHandler.Delete)
+		}
+
+		// Outbound SCIM provisioning connector routes (tenant-scoped)
+		scimConnectors := tenantScoped.Group("/scim/connectors")
+		{
+			scimConnectors.POST("", middleware.RequirePermission("scim_connectors", "manage", eventLogger), scimConnectorHandler.Create)
+			scimConnectors.GET("", middleware.RequirePermission("scim_connectors", "read", eventLogger), scimConnectorHandler.List)
+			scimConnectors.GET("/:id", middleware.RequirePermission("scim_connectors", "read", eventLogger), scimConnectorHandler.GetByID)
+			scimConnectors.PUT("/:id", middleware.RequirePermission("scim_connectors", "manage", eventLogger), scimConnectorHandler.Update)
+			scimConnectors.DELETE("/:id", middleware.RequirePermission("scim_connectors", "manage", eventLogger), scimConnectorHandler.Delete)
+			scimConnectors.POST("/:id/test", middleware.RequirePermission("scim_connectors", "manage", eventLogger), scimConnectorHandler.Test)
+			scimConnectors.GET("/:id/status", middleware.RequirePermission("scim_connectors", "read", eventLogger), scimConnectorHandler.Status)
+			scimConnectors.GET("/:id/operations", middleware.RequirePermission("scim_connectors", "read", eventLogger), scimConnectorHandler.Operations)
+			scimConnectors.POST("/:id/retry", middleware.RequirePermission("scim_connectors", "manage", eventLogger), scimConnectorHandler.Retry)
+			scimConnectors.POST("/:id/reconcile", middleware.RequirePermission("scim_connectors", "manage", eventLogger), scimConnectorHandler.Reconcile)
+		}
+
+		// Webhook routes (tenant-scoped)
+		webhooks := tenantScoped.Group("/webhooks")
+		{
+			webhooks.GET("/events", middleware.RequirePermission("webhooks", "read", eventLogger), webhookHandler.ListEventTypes)
+			webhooks.POST("", middleware.RequirePermission("webhooks", "manage", eventLogger), webhookHandler.CreateWebhook)
+			webhooks.GET("", middleware.RequirePermission("webhooks", "read", eventLogger), webhookHandler.ListWebhooks)
+			webhooks.GET("/:id", middleware.RequirePermission("webhooks", "read", eventLogger), webhookHandler.GetWebhook)
+			webhooks.PUT("/:id", middleware.RequirePermission("webhooks", "manage", eventLogger), webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", middleware.RequirePermission("webhooks", "manage", eventLogger), webhookHandler.DeleteWebhook)
+			webhooks.POST("/:id/pause", middleware.RequirePermission("webhooks", "manage", eventLogger), webhookHandler.PauseWebhook)
+			webhooks.POST("/:id/resume", middleware.RequirePermission("webhooks", "manage", eventLogger), webhookHandler.ResumeWebhook)
+			webhooks.POST("/:id/test", middleware.RequirePermission("webhooks", "manage", eventLogger), webhookHandler.SendTestEvent)
+			webhooks.GET("/:id/deliveries", middleware.RequirePermission("webhooks", "read", eventLogger), webhookHandler.GetDeliveries)
+			webhooks.GET("/:id/deliveries/:delivery_id", middleware.RequirePermission("webhooks", "read", eventLogger), webhookHandler.GetDelivery)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", middleware.RequirePermission("webhooks", "manage", eventLogger), webhookHandler.RedeliverDelivery)
+		}
+
 		// System audit events route (SYSTEM users only - system-wide audit)
 		if ts, ok := tokenService.(token.ServiceInterface); ok {
 			systemAPI := router.Group("/system")
@@ -3,10 +3,14 @@ package claims
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/arauth-identity/iam/identity/capability"
+	"github.com/arauth-identity/iam/identity/group"
 	"github.com/arauth-identity/iam/identity/models"
 	"github.com/arauth-identity/iam/identity/oauth_scope"
+	"github.com/arauth-identity/iam/identity/permission"
+	"github.com/arauth-identity/iam/identity/role"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/google/uuid"
 )
@@ -18,16 +22,18 @@ type Builder struct {
 	systemRoleRepo    interfaces.SystemRoleRepository // NEW: For SYSTEM users
 	capabilityService capability.ServiceInterface     // NEW: For capability context
 	oauthScopeService oauth_scope.ServiceInterface    // NEW: For OAuth scope mapping
+	groupRepo         interfaces.GroupRepository      // Roles granted through group membership
 }
 
 // NewBuilder creates a new claims builder
-func NewBuilder(roleRepo interfaces.RoleRepository, permissionRepo interfaces.PermissionRepository, systemRoleRepo interfaces.SystemRoleRepository, capabilityService capability.ServiceInterface, oauthScopeService oauth_scope.ServiceInterface) *Builder {
+func NewBuilder(roleRepo interfaces.RoleRepository, permissionRepo interfaces.PermissionRepository, systemRoleRepo interfaces.SystemRoleRepository, capabilityService capability.ServiceInterface, oauthScopeService oauth_scope.ServiceInterface, groupRepo interfaces.GroupRepository) *Builder {
 	return &Builder{
 		roleRepo:          roleRepo,
 		permissionRepo:    permissionRepo,
 		systemRoleRepo:    systemRoleRepo,
 		capabilityService: capabilityService,
 		oauthScopeService: oauthScopeService,
+		groupRepo:         groupRepo,
 	}
 }
 
@@ -41,7 +47,8 @@ type Claims struct {
 	ExpiresAt int64    `json:"exp,omitempty"`
 	IssuedAt  int64    `json:"iat,omitempty"`
 	NotBefore int64    `json:"nbf,omitempty"`
-	AMR       []string `json:"amr,omitempty"` // Authentication Methods References
+	AMR       []string `json:"amr,omitempty"`       // Authentication Methods References
+	AuthTime  int64    `json:"auth_time,omitempty"` // When the user last authenticated interactively
 
 	// Custom claims
 	PrincipalType     string   `json:"principal_type"`      // NEW: SYSTEM, TENANT, SERVICE
@@ -49,16 +56,73 @@ type Claims struct {
 	Email             string   `json:"email,omitempty"`
 	Username          string   `json:"username,omitempty"`
 	Roles             []string `json:"roles,omitempty"`              // Tenant roles
+	Groups            []string `json:"groups,omitempty"`             // Tenant groups, including through nesting
 	Permissions       []string `json:"permissions,omitempty"`        // Tenant permissions
 	SystemRoles       []string `json:"system_roles,omitempty"`       // NEW: System roles
 	SystemPermissions []string `json:"system_permissions,omitempty"` // NEW: System permissions
 	Scope             string   `json:"scope,omitempty"`              // Space-separated scopes
+	// Enterprise attributes (SCIM enterprise User extension), read from user metadata
+	EmployeeNumber string `json:"employee_number,omitempty"`
+	CostCenter     string `json:"cost_center,omitempty"`
+	Organization   string `json:"organization,omitempty"`
+	Division       string `json:"division,omitempty"`
+	Department     string `json:"department,omitempty"`
+	ManagerID      string `json:"manager_id,omitempty"`
 	// Capability context (informational only, not authoritative for authorization)
 	Capabilities map[string]bool        `json:"capabilities,omitempty"` // Capabilities available to tenant
 	Features     map[string]FeatureInfo `json:"features,omitempty"`     // Features enabled by tenant
 	// Impersonation claims (if token is from impersonation)
 	ImpersonatedBy         string `json:"impersonated_by,omitempty"`          // ID of user who is impersonating
 	ImpersonationSessionID string `json:"impersonation_session_id,omitempty"` // Session ID for the impersonation
+	// RolesExpireAt is when the earliest time-bound role assignment lapses (unix seconds, 0 if none).
+	// Not emitted; tokens built from these claims are capped so they never outlive the role.
+	RolesExpireAt int64 `json:"-"`
+}
+
+// CapLifetime shortens a token lifetime so it ends no later than the earliest time-bound role expiry
+func (c *Claims) CapLifetime(lifetime time.Duration, now time.Time) time.Duration {
+	if c.RolesExpireAt == 0 {
+		return lifetime
+	}
+	remaining := time.Unix(c.RolesExpireAt, 0).Sub(now)
+	if remaining < lifetime {
+		if remaining < 0 {
+			return 0
+		}
+		return remaining
+	}
+	return lifetime
+}
+
+// HasPermission reports whether the tenant permissions in the claims grant resource:action,
+// honoring wildcard and parent-resource grants
+func (c *Claims) HasPermission(resource, action string) bool {
+	_, ok := permission.MatchAny(c.Permissions, permission.Key(resource, action))
+	return ok
+}
+
+// HasSystemPermission reports whether the system permissions in the claims grant resource:action
+func (c *Claims) HasSystemPermission(resource, action string) bool {
+	_, ok := permission.MatchAny(c.SystemPermissions, permission.Key(resource, action))
+	return ok
+}
+
+// EnterpriseAttributes returns the enterprise attributes that are set, keyed by claim name
+func (c *Claims) EnterpriseAttributes() map[string]string {
+	attrs := make(map[string]string)
+	for name, value := range map[string]string{
+		"employee_number": c.EmployeeNumber,
+		"cost_center":     c.CostCenter,
+		"organization":    c.Organization,
+		"division":        c.Division,
+		"department":      c.Department,
+		"manager_id":      c.ManagerID,
+	} {
+		if value != "" {
+			attrs[name] = value
+		}
+	}
+	return attrs
 }
 
 // FeatureInfo represents information about an enabled feature
@@ -87,6 +151,14 @@ func (b *Builder) BuildClaims(ctx context.Context, user *models.User) (*Claims,
 		claims.TenantID = user.TenantID.String()
 	}
 
+	// Enterprise attributes, as provisioned through SCIM
+	claims.EmployeeNumber = metadataString(user.Metadata, models.UserMetadataEmployeeNumber)
+	claims.CostCenter = metadataString(user.Metadata, models.UserMetadataCostCenter)
+	claims.Organization = metadataString(user.Metadata, models.UserMetadataOrganization)
+	claims.Division = metadataString(user.Metadata, models.UserMetadataDivision)
+	claims.Department = metadataString(user.Metadata, models.UserMetadataDepartment)
+	claims.ManagerID = metadataString(user.Metadata, models.UserMetadataManagerID)
+
 	// For SYSTEM users: get system roles and permissions
 	if user.PrincipalType == models.PrincipalTypeSystem {
 		systemRoles, err := b.systemRoleRepo.GetUserSystemRoles(ctx, user.ID)
@@ -107,7 +179,7 @@ func (b *Builder) BuildClaims(ctx context.Context, user *models.User) (*Claims,
 			}
 
 			for _, perm := range permissions {
-				permissionKey := perm.Resource + ":" + perm.Action
+				permissionKey := permission.Key(perm.Resource, perm.Action)
 				systemPermissionMap[permissionKey] = true
 			}
 		}
@@ -156,11 +228,46 @@ func (b *Builder) BuildClaims(ctx context.Context, user *models.User) (*Claims,
 		}
 	}
 
-	// Get user roles
-	roles, err := b.roleRepo.GetUserRoles(ctx, user.ID)
+	// Get user roles, noting when the earliest time-bound assignment lapses
+	assignments, err := b.roleRepo.GetUserRoleAssignments(ctx, user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant roles: %w", err)
 	}
+	roles := make([]*models.Role, 0, len(assignments))
+	for _, assignment := range assignments {
+		roles = append(roles, assignment.Role)
+		if assignment.ExpiresAt != nil {
+			expiresAt := assignment.ExpiresAt.Unix()
+			if claims.RolesExpireAt == 0 || expiresAt < claims.RolesExpireAt {
+				claims.RolesExpireAt = expiresAt
+			}
+		}
+	}
+
+	// Roles granted to the user's groups count as if assigned directly
+	if b.groupRepo != nil {
+		groups, err := group.UserGroups(ctx, b.groupRepo, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tenant groups: %w", err)
+		}
+		groupRoles, err := group.GroupRoles(ctx, b.groupRepo, groups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get group roles: %w", err)
+		}
+		roles = append(roles, groupRoles...)
+
+		groupNames := make([]string, 0, len(groups))
+		for _, g := range groups {
+			groupNames = append(groupNames, g.Name)
+		}
+		claims.Groups = groupNames
+	}
+
+	// Include inherited roles so their names and permissions reach the token
+	roles, err = role.ExpandRoles(ctx, b.roleRepo, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand tenant roles: %w", err)
+	}
 
 	// Extract role names
 	roleNames := make([]string, 0, len(roles))
@@ -177,7 +284,7 @@ func (b *Builder) BuildClaims(ctx context.Context, user *models.User) (*Claims,
 
 		// Add permissions to map
 		for _, perm := range permissions {
-			permissionKey := perm.Resource + ":" + perm.Action
+			permissionKey := permission.Key(perm.Resource, perm.Action)
 			permissionMap[permissionKey] = true
 		}
 	}
@@ -266,3 +373,9 @@ func joinStrings(strs []string, sep string) string {
 	}
 	return result
 }
+
+// metadataString returns a string metadata value, or "" when it is missing or not a string
+func metadataString(metadata map[string]interface{}, key string) string {
+	value, _ := metadata[key].(string)
+	return value
+}
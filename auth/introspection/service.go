@@ -3,29 +3,277 @@ package introspection
 import (
 	"context"
 	"crypto/rsa"
+	"fmt"
 	"time"
 
+	"github.com/arauth-identity/iam/auth/token"
+	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-// Service provides token introspection functionality (RFC 7662)
+// Service provides token introspection (RFC 7662) and revocation (RFC 7009) functionality
 type Service struct {
-	jwtSecret []byte
-	publicKey *rsa.PublicKey
-	issuer    string
+	jwtSecret        []byte
+	publicKey        *rsa.PublicKey
+	issuer           string
+	tokenService     token.ServiceInterface
+	refreshTokenRepo interfaces.RefreshTokenRepository
+	userRepo         interfaces.UserRepository
+	tenantRepo       interfaces.TenantRepository
 }
 
 // NewService creates a new token introspection service
-func NewService(jwtSecret []byte, publicKey *rsa.PublicKey, issuer string) ServiceInterface {
+func NewService(
+	jwtSecret []byte,
+	publicKey *rsa.PublicKey,
+	issuer string,
+	tokenService token.ServiceInterface,
+	refreshTokenRepo interfaces.RefreshTokenRepository,
+	userRepo interfaces.UserRepository,
+	tenantRepo interfaces.TenantRepository,
+) ServiceInterface {
 	return &Service{
-		jwtSecret: jwtSecret,
-		publicKey: publicKey,
-		issuer:    issuer,
+		jwtSecret:        jwtSecret,
+		publicKey:        publicKey,
+		issuer:           issuer,
+		tokenService:     tokenService,
+		refreshTokenRepo: refreshTokenRepo,
+		userRepo:         userRepo,
+		tenantRepo:       tenantRepo,
 	}
 }
 
+// inactive is the only response allowed for tokens that are not active (RFC 7662 Section 2.2)
+func inactive() *TokenInfo {
+	return &TokenInfo{Active: false}
+}
+
 // IntrospectToken introspects a token and returns its metadata
-func (s *Service) IntrospectToken(ctx context.Context, tokenString string, tokenTypeHint string) (*TokenInfo, error) {
+func (s *Service) IntrospectToken(ctx context.Context, callerTenantID uuid.UUID, tokenString string, tokenTypeHint string) (*TokenInfo, error) {
+	// The hint only decides the lookup order; unknown hints are ignored (RFC 7662 Section 2.1)
+	if tokenTypeHint == TokenTypeHintRefreshToken {
+		info, err := s.introspectRefreshToken(ctx, callerTenantID, tokenString)
+		if err != nil || info.Active {
+			return info, err
+		}
+		return s.introspectAccessToken(ctx, callerTenantID, tokenString)
+	}
+
+	info, err := s.introspectAccessToken(ctx, callerTenantID, tokenString)
+	if err != nil || info.Active {
+		return info, err
+	}
+	return s.introspectRefreshToken(ctx, callerTenantID, tokenString)
+}
+
+// RevokeToken revokes an access or refresh token
+func (s *Service) RevokeToken(ctx context.Context, callerTenantID uuid.UUID, tokenString string, tokenTypeHint string) (*TokenInfo, error) {
+	if tokenTypeHint == TokenTypeHintAccessToken {
+		info, err := s.revokeAccessToken(ctx, callerTenantID, tokenString)
+		if err != nil || info != nil {
+			return info, err
+		}
+		return s.revokeRefreshToken(ctx, callerTenantID, tokenString)
+	}
+
+	info, err := s.revokeRefreshToken(ctx, callerTenantID, tokenString)
+	if err != nil || info != nil {
+		return info, err
+	}
+	return s.revokeAccessToken(ctx, callerTenantID, tokenString)
+}
+
+// introspectAccessToken verifies a JWT access token and checks the blacklist and principal status
+func (s *Service) introspectAccessToken(ctx context.Context, callerTenantID uuid.UUID, tokenString string) (*TokenInfo, error) {
+	info, ok := s.parseAccessToken(tokenString)
+	if !ok {
+		return inactive(), nil
+	}
+
+	// Revoked tokens must never be reported as active
+	if info.JTI != "" && s.tokenService != nil {
+		revoked, err := s.tokenService.IsAccessTokenRevoked(ctx, info.JTI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation status: %w", err)
+		}
+		if revoked {
+			return inactive(), nil
+		}
+	}
+
+	active, err := s.isPrincipalActive(ctx, callerTenantID, info.Subject, info.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return inactive(), nil
+	}
+
+	return info, nil
+}
+
+// introspectRefreshToken looks up an opaque refresh token and checks its state and principal status
+func (s *Service) introspectRefreshToken(ctx context.Context, callerTenantID uuid.UUID, tokenString string) (*TokenInfo, error) {
+	record, ok := s.lookupRefreshToken(ctx, tokenString)
+	if !ok {
+		return inactive(), nil
+	}
+
+	if record.RevokedAt != nil || time.Now().After(record.ExpiresAt) {
+		return inactive(), nil
+	}
+
+	tenantID := ""
+	if record.TenantID != uuid.Nil {
+		tenantID = record.TenantID.String()
+	}
+
+	active, err := s.isPrincipalActive(ctx, callerTenantID, record.UserID.String(), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return inactive(), nil
+	}
+
+	info := &TokenInfo{
+		Active:    true,
+		TokenType: TokenTypeHintRefreshToken,
+		Subject:   record.UserID.String(),
+		TenantID:  tenantID,
+		IssuedAt:  record.CreatedAt.Unix(),
+		ExpiresAt: record.ExpiresAt.Unix(),
+		Issuer:    s.issuer,
+	}
+
+	if s.userRepo != nil {
+		if user, err := s.userRepo.GetByID(ctx, record.UserID); err == nil {
+			info.Username = user.Username
+			info.PrincipalType = string(user.PrincipalType)
+		}
+	}
+
+	return info, nil
+}
+
+// revokeAccessToken blacklists a JWT access token owned by the caller's tenant
+func (s *Service) revokeAccessToken(ctx context.Context, callerTenantID uuid.UUID, tokenString string) (*TokenInfo, error) {
+	info, ok := s.parseAccessToken(tokenString)
+	if !ok {
+		return nil, nil
+	}
+
+	if !s.belongsToTenant(callerTenantID, info.TenantID) {
+		return nil, nil
+	}
+
+	if s.tokenService == nil {
+		return nil, fmt.Errorf("token service not configured")
+	}
+
+	if err := s.tokenService.RevokeAccessToken(ctx, tokenString); err != nil {
+		return nil, fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return info, nil
+}
+
+// revokeRefreshToken revokes a refresh token owned by the caller's tenant
+func (s *Service) revokeRefreshToken(ctx context.Context, callerTenantID uuid.UUID, tokenString string) (*TokenInfo, error) {
+	record, ok := s.lookupRefreshToken(ctx, tokenString)
+	if !ok {
+		return nil, nil
+	}
+
+	tenantID := ""
+	if record.TenantID != uuid.Nil {
+		tenantID = record.TenantID.String()
+	}
+
+	if !s.belongsToTenant(callerTenantID, tenantID) {
+		return nil, nil
+	}
+
+	info := &TokenInfo{
+		TokenType: TokenTypeHintRefreshToken,
+		Subject:   record.UserID.String(),
+		TenantID:  tenantID,
+		ExpiresAt: record.ExpiresAt.Unix(),
+	}
+
+	// Revoking an already revoked token is a no-op (RFC 7009 Section 2.2)
+	if record.RevokedAt != nil {
+		return info, nil
+	}
+
+	if err := s.refreshTokenRepo.Revoke(ctx, record.ID); err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return info, nil
+}
+
+// lookupRefreshToken finds a refresh token record by its plaintext value
+func (s *Service) lookupRefreshToken(ctx context.Context, tokenString string) (*interfaces.RefreshToken, bool) {
+	if s.tokenService == nil || s.refreshTokenRepo == nil {
+		return nil, false
+	}
+
+	tokenHash, err := s.tokenService.HashRefreshToken(tokenString)
+	if err != nil {
+		return nil, false
+	}
+
+	record, err := s.refreshTokenRepo.GetByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return nil, false
+	}
+
+	return record, true
+}
+
+// belongsToTenant checks that a token's tenant matches the calling client's tenant
+func (s *Service) belongsToTenant(callerTenantID uuid.UUID, tokenTenantID string) bool {
+	if callerTenantID == uuid.Nil {
+		return true
+	}
+	return tokenTenantID == callerTenantID.String()
+}
+
+// isPrincipalActive checks tenant ownership and that both the user and the tenant are still active
+func (s *Service) isPrincipalActive(ctx context.Context, callerTenantID uuid.UUID, subject, tokenTenantID string) (bool, error) {
+	if !s.belongsToTenant(callerTenantID, tokenTenantID) {
+		return false, nil
+	}
+
+	if s.userRepo != nil {
+		userID, err := uuid.Parse(subject)
+		if err != nil {
+			return false, nil
+		}
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil || !user.IsActive() {
+			return false, nil
+		}
+	}
+
+	if s.tenantRepo != nil && tokenTenantID != "" {
+		tenantID, err := uuid.Parse(tokenTenantID)
+		if err != nil {
+			return false, nil
+		}
+		tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+		if err != nil || !tenant.IsActive() {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// parseAccessToken verifies a JWT access token and extracts its metadata
+func (s *Service) parseAccessToken(tokenString string) (*TokenInfo, bool) {
 	// Parse the token
 	parsedToken, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Verify signing method
@@ -45,33 +293,28 @@ func (s *Service) IntrospectToken(ctx context.Context, tokenString string, token
 	})
 
 	if err != nil || !parsedToken.Valid {
-		// Token is invalid or expired - return inactive token
-		return &TokenInfo{
-			Active: false,
-		}, nil
+		// Token is invalid or expired
+		return nil, false
 	}
 
 	// Extract claims
 	claims, ok := parsedToken.Claims.(jwt.MapClaims)
 	if !ok {
-		return &TokenInfo{
-			Active: false,
-		}, nil
+		return nil, false
 	}
 
 	// Check if token is expired
 	if exp, ok := claims["exp"].(float64); ok {
 		expTime := time.Unix(int64(exp), 0)
 		if time.Now().After(expTime) {
-			return &TokenInfo{
-				Active: false,
-			}, nil
+			return nil, false
 		}
 	}
 
 	// Build token info
 	info := &TokenInfo{
-		Active: true,
+		Active:    true,
+		TokenType: "Bearer",
 	}
 
 	// Extract standard claims
@@ -121,44 +364,31 @@ func (s *Service) IntrospectToken(ctx context.Context, tokenString string, token
 	}
 
 	// Extract roles
-	if roles, ok := claims["roles"].([]interface{}); ok {
-		info.Roles = make([]string, 0, len(roles))
-		for _, role := range roles {
-			if roleStr, ok := role.(string); ok {
-				info.Roles = append(info.Roles, roleStr)
-			}
-		}
-	}
+	info.Roles = stringSliceClaim(claims, "roles")
 
 	// Extract permissions
-	if perms, ok := claims["permissions"].([]interface{}); ok {
-		info.Permissions = make([]string, 0, len(perms))
-		for _, perm := range perms {
-			if permStr, ok := perm.(string); ok {
-				info.Permissions = append(info.Permissions, permStr)
-			}
-		}
-	}
+	info.Permissions = stringSliceClaim(claims, "permissions")
 
 	// Extract system roles (SYSTEM users)
-	if systemRoles, ok := claims["system_roles"].([]interface{}); ok {
-		info.SystemRoles = make([]string, 0, len(systemRoles))
-		for _, role := range systemRoles {
-			if roleStr, ok := role.(string); ok {
-				info.SystemRoles = append(info.SystemRoles, roleStr)
-			}
-		}
-	}
+	info.SystemRoles = stringSliceClaim(claims, "system_roles")
 
 	// Extract system permissions (SYSTEM users)
-	if systemPerms, ok := claims["system_permissions"].([]interface{}); ok {
-		info.SystemPerms = make([]string, 0, len(systemPerms))
-		for _, perm := range systemPerms {
-			if permStr, ok := perm.(string); ok {
-				info.SystemPerms = append(info.SystemPerms, permStr)
-			}
+	info.SystemPerms = stringSliceClaim(claims, "system_permissions")
+
+	return info, true
+}
+
+// stringSliceClaim safely extracts a string array claim
+func stringSliceClaim(claims jwt.MapClaims, key string) []string {
+	values, ok := claims[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			result = append(result, str)
 		}
 	}
-
-	return info, nil
+	return result
 }
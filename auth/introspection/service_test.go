@@ -0,0 +1,342 @@
+package introspection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/auth/claims"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("introspection-test-secret")
+
+// MockTokenService is a mock for testing
+type MockTokenService struct {
+	mock.Mock
+}
+
+func (m *MockTokenService) GenerateAccessToken(claimsObj *claims.Claims, expiresIn time.Duration) (string, error) {
+	args := m.Called(claimsObj, expiresIn)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) GenerateRefreshToken() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) HashRefreshToken(token string) (string, error) {
+	args := m.Called(token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) VerifyRefreshToken(token, hash string) bool {
+	args := m.Called(token, hash)
+	return args.Bool(0)
+}
+
+func (m *MockTokenService) ValidateAccessToken(tokenString string) (*claims.Claims, error) {
+	args := m.Called(tokenString)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*claims.Claims), args.Error(1)
+}
+
+func (m *MockTokenService) GetPublicKey() interface{} {
+	return nil
+}
+
+func (m *MockTokenService) RevokeAccessToken(ctx context.Context, tokenString string) error {
+	args := m.Called(ctx, tokenString)
+	return args.Error(0)
+}
+
+func (m *MockTokenService) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	args := m.Called(ctx, jti)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockRefreshTokenRepository is a mock for testing
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *interfaces.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.RefreshToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*interfaces.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*interfaces.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*interfaces.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*interfaces.RefreshToken, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*interfaces.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Revoke(ctx context.Context, tokenID uuid.UUID) error {
+	args := m.Called(ctx, tokenID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeByTokenHash(ctx context.Context, tokenHash string) error {
+	args := m.Called(ctx, tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeByClientID(ctx context.Context, clientID string) (int, error) {
+	args := m.Called(ctx, clientID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) DeleteExpired(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// stubUserRepository returns a fixed set of users by ID
+type stubUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, assert.AnError
+}
+
+// stubTenantRepository returns a fixed set of tenants by ID
+type stubTenantRepository struct {
+	interfaces.TenantRepository
+	tenants map[uuid.UUID]*models.Tenant
+}
+
+func (r *stubTenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
+	if t, ok := r.tenants[id]; ok {
+		return t, nil
+	}
+	return nil, assert.AnError
+}
+
+type fixture struct {
+	service      ServiceInterface
+	tokenService *MockTokenService
+	refreshRepo  *MockRefreshTokenRepository
+	user         *models.User
+	tenant       *models.Tenant
+}
+
+func newFixture() *fixture {
+	tenantID := uuid.New()
+	user := &models.User{ID: uuid.New(), TenantID: &tenantID, Username: "alice", Status: models.UserStatusActive, PrincipalType: models.PrincipalTypeTenant}
+	tenant := &models.Tenant{ID: tenantID, Status: models.TenantStatusActive}
+
+	f := &fixture{
+		tokenService: new(MockTokenService),
+		refreshRepo:  new(MockRefreshTokenRepository),
+		user:         user,
+		tenant:       tenant,
+	}
+	f.service = NewService(testSecret, nil, "https://iam.test", f.tokenService, f.refreshRepo,
+		&stubUserRepository{users: map[uuid.UUID]*models.User{user.ID: user}},
+		&stubTenantRepository{tenants: map[uuid.UUID]*models.Tenant{tenantID: tenant}},
+	)
+	return f
+}
+
+func (f *fixture) accessToken(t *testing.T, jti string) string {
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":       f.user.ID.String(),
+		"tenant_id": f.tenant.ID.String(),
+		"username":  f.user.Username,
+		"jti":       jti,
+		"iat":       time.Now().Unix(),
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := tok.SignedString(testSecret)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestIntrospectToken_ActiveAccessToken(t *testing.T) {
+	f := newFixture()
+	tokenString := f.accessToken(t, "jti-1")
+	f.tokenService.On("IsAccessTokenRevoked", mock.Anything, "jti-1").Return(false, nil)
+
+	info, err := f.service.IntrospectToken(context.Background(), f.tenant.ID, tokenString, "")
+
+	require.NoError(t, err)
+	assert.True(t, info.Active)
+	assert.Equal(t, "Bearer", info.TokenType)
+	assert.Equal(t, f.user.ID.String(), info.Subject)
+}
+
+func TestIntrospectToken_BlacklistedAccessTokenIsInactive(t *testing.T) {
+	f := newFixture()
+	tokenString := f.accessToken(t, "jti-revoked")
+	f.tokenService.On("IsAccessTokenRevoked", mock.Anything, "jti-revoked").Return(true, nil)
+	// Falls through to the refresh token lookup, which does not match
+	f.tokenService.On("HashRefreshToken", tokenString).Return("hash", nil)
+	f.refreshRepo.On("GetByTokenHash", mock.Anything, "hash").Return(nil, assert.AnError)
+
+	info, err := f.service.IntrospectToken(context.Background(), f.tenant.ID, tokenString, TokenTypeHintAccessToken)
+
+	require.NoError(t, err)
+	assert.False(t, info.Active)
+	assert.Empty(t, info.Subject, "inactive responses must not leak token metadata")
+}
+
+func TestIntrospectToken_SuspendedUserIsInactive(t *testing.T) {
+	f := newFixture()
+	f.user.Status = models.UserStatusSuspended
+	tokenString := f.accessToken(t, "jti-2")
+	f.tokenService.On("IsAccessTokenRevoked", mock.Anything, "jti-2").Return(false, nil)
+	f.tokenService.On("HashRefreshToken", tokenString).Return("hash", nil)
+	f.refreshRepo.On("GetByTokenHash", mock.Anything, "hash").Return(nil, assert.AnError)
+
+	info, err := f.service.IntrospectToken(context.Background(), f.tenant.ID, tokenString, "")
+
+	require.NoError(t, err)
+	assert.False(t, info.Active)
+}
+
+func TestIntrospectToken_OtherTenantIsInactive(t *testing.T) {
+	f := newFixture()
+	tokenString := f.accessToken(t, "jti-3")
+	f.tokenService.On("IsAccessTokenRevoked", mock.Anything, "jti-3").Return(false, nil)
+	f.tokenService.On("HashRefreshToken", tokenString).Return("hash", nil)
+	f.refreshRepo.On("GetByTokenHash", mock.Anything, "hash").Return(nil, assert.AnError)
+
+	info, err := f.service.IntrospectToken(context.Background(), uuid.New(), tokenString, "")
+
+	require.NoError(t, err)
+	assert.False(t, info.Active)
+}
+
+func TestIntrospectToken_RefreshToken(t *testing.T) {
+	f := newFixture()
+	record := &interfaces.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    f.user.ID,
+		TenantID:  f.tenant.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+	f.tokenService.On("HashRefreshToken", "opaque-refresh").Return("refresh-hash", nil)
+	f.refreshRepo.On("GetByTokenHash", mock.Anything, "refresh-hash").Return(record, nil)
+
+	info, err := f.service.IntrospectToken(context.Background(), f.tenant.ID, "opaque-refresh", TokenTypeHintRefreshToken)
+
+	require.NoError(t, err)
+	assert.True(t, info.Active)
+	assert.Equal(t, TokenTypeHintRefreshToken, info.TokenType)
+	assert.Equal(t, "alice", info.Username)
+}
+
+func TestIntrospectToken_RevokedRefreshTokenIsInactive(t *testing.T) {
+	f := newFixture()
+	revokedAt := time.Now()
+	record := &interfaces.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    f.user.ID,
+		TenantID:  f.tenant.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}
+	f.tokenService.On("HashRefreshToken", "opaque-refresh").Return("refresh-hash", nil)
+	f.refreshRepo.On("GetByTokenHash", mock.Anything, "refresh-hash").Return(record, nil)
+
+	info, err := f.service.IntrospectToken(context.Background(), f.tenant.ID, "opaque-refresh", TokenTypeHintRefreshToken)
+
+	require.NoError(t, err)
+	assert.False(t, info.Active)
+}
+
+func TestRevokeToken_RefreshToken(t *testing.T) {
+	f := newFixture()
+	record := &interfaces.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    f.user.ID,
+		TenantID:  f.tenant.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	f.tokenService.On("HashRefreshToken", "opaque-refresh").Return("refresh-hash", nil)
+	f.refreshRepo.On("GetByTokenHash", mock.Anything, "refresh-hash").Return(record, nil)
+	f.refreshRepo.On("Revoke", mock.Anything, record.ID).Return(nil)
+
+	info, err := f.service.RevokeToken(context.Background(), f.tenant.ID, "opaque-refresh", "")
+
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, f.user.ID.String(), info.Subject)
+	f.refreshRepo.AssertExpectations(t)
+}
+
+func TestRevokeToken_AccessToken(t *testing.T) {
+	f := newFixture()
+	tokenString := f.accessToken(t, "jti-4")
+	f.tokenService.On("RevokeAccessToken", mock.Anything, tokenString).Return(nil)
+
+	info, err := f.service.RevokeToken(context.Background(), f.tenant.ID, tokenString, TokenTypeHintAccessToken)
+
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, "jti-4", info.JTI)
+	f.tokenService.AssertExpectations(t)
+}
+
+func TestRevokeToken_UnknownTokenIsNotAnError(t *testing.T) {
+	f := newFixture()
+	f.tokenService.On("HashRefreshToken", "garbage").Return("garbage-hash", nil)
+	f.refreshRepo.On("GetByTokenHash", mock.Anything, "garbage-hash").Return(nil, assert.AnError)
+
+	info, err := f.service.RevokeToken(context.Background(), f.tenant.ID, "garbage", "")
+
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+	f.tokenService.AssertNotCalled(t, "RevokeAccessToken", mock.Anything, mock.Anything)
+}
+
+func TestRevokeToken_OtherTenantIsIgnored(t *testing.T) {
+	f := newFixture()
+	tokenString := f.accessToken(t, "jti-5")
+	f.tokenService.On("HashRefreshToken", tokenString).Return("hash", nil)
+	f.refreshRepo.On("GetByTokenHash", mock.Anything, "hash").Return(nil, assert.AnError)
+
+	info, err := f.service.RevokeToken(context.Background(), uuid.New(), tokenString, "")
+
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+	f.tokenService.AssertNotCalled(t, "RevokeAccessToken", mock.Anything, mock.Anything)
+}
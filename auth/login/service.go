@@ -21,6 +21,7 @@ type Service struct {
 	refreshTokenRepo    interfaces.RefreshTokenRepository
 	tenantSettingsRepo  interfaces.TenantSettingsRepository
 	tenantRepo          interfaces.TenantRepository
+	mfaFactorRepo       interfaces.MFAFactorRepository
 	hydraClient         *hydra.Client
 	passwordHasher      *password.Hasher
 	claimsBuilder       *claims.Builder
@@ -36,6 +37,7 @@ func NewService(
 	refreshTokenRepo interfaces.RefreshTokenRepository,
 	tenantSettingsRepo interfaces.TenantSettingsRepository,
 	tenantRepo interfaces.TenantRepository,
+	mfaFactorRepo interfaces.MFAFactorRepository,
 	hydraClient *hydra.Client,
 	claimsBuilder *claims.Builder,
 	tokenService token.ServiceInterface,
@@ -48,6 +50,7 @@ func NewService(
 		refreshTokenRepo:   refreshTokenRepo,
 		tenantSettingsRepo: tenantSettingsRepo,
 		tenantRepo:         tenantRepo,
+		mfaFactorRepo:      mfaFactorRepo,
 		hydraClient:        hydraClient,
 		passwordHasher:     password.NewHasher(),
 		claimsBuilder:      claimsBuilder,
@@ -64,6 +67,8 @@ type LoginRequest struct {
 	TenantID       uuid.UUID `json:"tenant_id"` // Set from context, not from request body
 	RememberMe    bool      `json:"remember_me,omitempty"` // Remember Me option
 	LoginChallenge *string   `json:"login_challenge,omitempty"` // For OAuth2 flow
+	IPAddress      string    `json:"-"` // Set from the request, used for session metadata
+	UserAgent      string    `json:"-"` // Set from the request, used for session metadata
 }
 
 // LoginResponse represents a login response
@@ -280,8 +285,12 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest) (*LoginResponse,
 	}
 	
 	if mfaRequired {
-		// MFA is required - check if user has enrolled
-		needsEnrollment := !user.MFAEnabled && user.MFASecretEncrypted == nil
+		// MFA is required - check if user has a verified factor
+		// (an admin MFA reset leaves MFA enabled with no factors, forcing re-enrollment)
+		needsEnrollment, err := s.needsMFAEnrollment(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
 		
 		var tenantIDStr string
 		if user.TenantID != nil {
@@ -295,6 +304,9 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest) (*LoginResponse,
 		}, nil
 	}
 
+	// Authentication is complete at this point; record it before issuing tokens
+	s.recordLogin(ctx, user)
+
 	// If login_challenge is provided, use OAuth2 flow
 	if req.LoginChallenge != nil {
 		return s.handleOAuth2Login(ctx, *req.LoginChallenge, user)
@@ -306,7 +318,8 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest) (*LoginResponse,
 	if user.TenantID != nil {
 		tenantID = *user.TenantID
 	}
-	return s.issueDirectTokens(ctx, user, tenantID, req.RememberMe)
+	client := &token.ClientInfo{IPAddress: req.IPAddress, UserAgent: req.UserAgent}
+	return s.issueDirectTokens(ctx, user, tenantID, req.RememberMe, client)
 }
 
 // handleOAuth2Login handles OAuth2 login flow with Hydra
@@ -414,3 +427,17 @@ func (s *Service) handleOAuth2Login(ctx context.Context, challenge string, user
 	}, nil
 }
 
+
+// needsMFAEnrollment reports whether the user has no verified MFA factor
+func (s *Service) needsMFAEnrollment(ctx context.Context, userID uuid.UUID) (bool, error) {
+	factors, err := s.mfaFactorRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get MFA factors: %w", err)
+	}
+	for _, f := range factors {
+		if f.Verified {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/arauth-identity/iam/auth/token"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/logger"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 // issueDirectTokens issues access and refresh tokens directly
-func (s *Service) issueDirectTokens(ctx context.Context, user *models.User, tenantID uuid.UUID, rememberMe bool) (*LoginResponse, error) {
+func (s *Service) issueDirectTokens(ctx context.Context, user *models.User, tenantID uuid.UUID, rememberMe bool, client *token.ClientInfo) (*LoginResponse, error) {
 	// Get token lifetimes
 	lifetimes := s.lifetimeResolver.GetAllLifetimes(ctx, tenantID, rememberMe)
 
@@ -23,6 +26,7 @@ func (s *Service) issueDirectTokens(ctx context.Context, user *models.User, tena
 
 	// Set AMR claim (assuming password authentication for direct tokens)
 	claimsObj.AMR = []string{"pwd"}
+	claimsObj.AuthTime = time.Now().Unix()
 
 	// Generate access token
 	accessToken, err := s.tokenService.GenerateAccessToken(claimsObj, lifetimes.AccessTokenTTL)
@@ -51,6 +55,7 @@ func (s *Service) issueDirectTokens(ctx context.Context, user *models.User, tena
 		ExpiresAt:  time.Now().Add(lifetimes.RefreshTokenTTL),
 		RememberMe: rememberMe,
 	}
+	client.ApplyTo(refreshTokenRecord, time.Now())
 
 	if err := s.refreshTokenRepo.Create(ctx, refreshTokenRecord); err != nil {
 		return nil, fmt.Errorf("failed to store refresh token: %w", err)
@@ -67,8 +72,24 @@ func (s *Service) issueDirectTokens(ctx context.Context, user *models.User, tena
 		RefreshToken:     refreshToken, // Return plain token to client
 		IDToken:          idToken,
 		TokenType:        "Bearer",
-		ExpiresIn:        int(lifetimes.AccessTokenTTL.Seconds()),
+		ExpiresIn:        int(claimsObj.CapLifetime(lifetimes.AccessTokenTTL, time.Now()).Seconds()),
 		RefreshExpiresIn: int(lifetimes.RefreshTokenTTL.Seconds()),
 		RememberMe:       rememberMe,
 	}, nil
 }
+
+// recordLogin updates the user's last login timestamp.
+// Failures are logged but never block a valid login.
+func (s *Service) recordLogin(ctx context.Context, user *models.User) {
+	now := time.Now()
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		if logger.Logger != nil {
+			logger.Logger.Warn("failed to record last login",
+				zap.Error(err),
+				zap.String("user_id", user.ID.String()),
+			)
+		}
+		return
+	}
+	user.LastLoginAt = &now
+}
@@ -83,14 +83,30 @@ func (s *Service) VerifyChallenge(ctx context.Context, req *VerifyChallengeReque
 		return nil, err
 	}
 
-	// Verify MFA code
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if err := s.checkTOTPCapability(ctx, user); err != nil {
+		return nil, err
+	}
+
+	factors, err := s.mfaFactorRepo.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MFA factors: %w", err)
+	}
+
+	// Verify MFA code. A pending factor only counts while the user enrolls
+	// their first one during login; once a factor is verified, logging in
+	// with a factor someone just added would bypass the step-up on Enroll.
 	verifyReq := &VerifyRequest{
 		UserID:      session.UserID,
 		TOTPCode:    req.TOTPCode,
 		RecoveryCode: req.RecoveryCode,
 	}
 
-	valid, err := s.Verify(ctx, verifyReq)
+	valid, err := s.verifyCode(ctx, user, verifyReq, len(verifiedFactors(factors)) == 0)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,106 @@
+package mfa
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/security/totp"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// FactorTypeTOTP is a time-based one-time password factor (authenticator app)
+const FactorTypeTOTP = "totp"
+
+// defaultFactorName is used when a factor is enrolled without a name
+const defaultFactorName = "Authenticator app"
+
+// maxFactorNameLength bounds user-supplied factor names
+const maxFactorNameLength = 100
+
+// Factor is the public view of an enrolled MFA factor; it never includes the secret
+type Factor struct {
+	ID         uuid.UUID  `json:"id"`
+	Type       string     `json:"type"`
+	Name       string     `json:"name"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Status summarises a user's MFA enrollment
+type Status struct {
+	Enabled                bool      `json:"enabled"`
+	Factors                []*Factor `json:"factors"`
+	RecoveryCodesRemaining int       `json:"recovery_codes_remaining"`
+}
+
+// RemoveFactorRequest represents a request to remove a factor.
+// A TOTP or recovery code from an already verified factor is required as step-up.
+type RemoveFactorRequest struct {
+	UserID       uuid.UUID `json:"user_id"`
+	FactorID     uuid.UUID `json:"factor_id"`
+	TOTPCode     string    `json:"totp_code,omitempty"`
+	RecoveryCode string    `json:"recovery_code,omitempty"`
+}
+
+// toFactor maps a stored factor to its public view
+func toFactor(f *interfaces.MFAFactor) *Factor {
+	return &Factor{
+		ID:         f.ID,
+		Type:       f.Type,
+		Name:       f.Name,
+		Verified:   f.Verified,
+		VerifiedAt: f.VerifiedAt,
+		LastUsedAt: f.LastUsedAt,
+		CreatedAt:  f.CreatedAt,
+	}
+}
+
+// verifiedFactors returns the factors that have completed enrollment
+func verifiedFactors(factors []*interfaces.MFAFactor) []*interfaces.MFAFactor {
+	var verified []*interfaces.MFAFactor
+	for _, f := range factors {
+		if f.Verified {
+			verified = append(verified, f)
+		}
+	}
+	return verified
+}
+
+// factorSettings returns the TOTP settings a factor was enrolled with.
+// Unset values fall back to the generator's configuration.
+func factorSettings(f *interfaces.MFAFactor) totp.Settings {
+	return totp.Settings{
+		Algorithm: f.Algorithm,
+		Digits:    f.Digits,
+		Period:    f.Period,
+	}
+}
+
+// factorName validates a requested factor name, or picks a free default name
+func factorName(requested string, existing []*interfaces.MFAFactor) (string, error) {
+	taken := make(map[string]bool, len(existing))
+	for _, f := range existing {
+		taken[strings.ToLower(f.Name)] = true
+	}
+
+	name := strings.TrimSpace(requested)
+	if name != "" {
+		if len(name) > maxFactorNameLength {
+			return "", fmt.Errorf("factor name must be at most %d characters", maxFactorNameLength)
+		}
+		if taken[strings.ToLower(name)] {
+			return "", fmt.Errorf("an MFA factor named %q already exists", name)
+		}
+		return name, nil
+	}
+
+	name = defaultFactorName
+	for i := 2; taken[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s %d", defaultFactorName, i)
+	}
+	return name, nil
+}
@@ -0,0 +1,42 @@
+package mfa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactorName(t *testing.T) {
+	existing := []*interfaces.MFAFactor{
+		{Name: "Authenticator app"},
+		{Name: "Work phone"},
+	}
+
+	tests := []struct {
+		name      string
+		requested string
+		existing  []*interfaces.MFAFactor
+		expected  string
+		expectErr bool
+	}{
+		{"default name for first factor", "", nil, "Authenticator app", false},
+		{"default name is numbered when taken", "", existing, "Authenticator app 2", false},
+		{"requested name is trimmed", "  Tablet  ", existing, "Tablet", false},
+		{"duplicate name is rejected case-insensitively", "work PHONE", existing, "", true},
+		{"overlong name is rejected", strings.Repeat("x", maxFactorNameLength+1), nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := factorName(tt.requested, tt.existing)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+}
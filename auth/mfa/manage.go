@@ -0,0 +1,195 @@
+package mfa
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// recoveryCodeCount is the number of recovery codes issued per enrollment or regeneration
+const recoveryCodeCount = 10
+
+// GetStatus returns the user's factors and the number of unused recovery codes
+func (s *Service) GetStatus(ctx context.Context, userID uuid.UUID) (*Status, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	factors, err := s.mfaFactorRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MFA factors: %w", err)
+	}
+
+	remaining, err := s.mfaRecoveryCodeRepo.CountRemaining(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Enabled:                user.MFAEnabled,
+		Factors:                make([]*Factor, 0, len(factors)),
+		RecoveryCodesRemaining: remaining,
+	}
+	for _, f := range factors {
+		status.Factors = append(status.Factors, toFactor(f))
+	}
+
+	return status, nil
+}
+
+// HasVerifiedFactor reports whether the user has completed enrollment of at least one factor
+func (s *Service) HasVerifiedFactor(ctx context.Context, userID uuid.UUID) (bool, error) {
+	factors, err := s.mfaFactorRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get MFA factors: %w", err)
+	}
+	return len(verifiedFactors(factors)) > 0, nil
+}
+
+// RemoveFactor removes one of the user's factors after a step-up check.
+// Removing the last verified factor turns MFA off and discards the recovery codes.
+func (s *Service) RemoveFactor(ctx context.Context, req *RemoveFactorRequest) error {
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	factor, err := s.mfaFactorRepo.GetByID(ctx, req.FactorID)
+	if err != nil || factor.UserID != user.ID {
+		return fmt.Errorf("MFA factor not found")
+	}
+
+	enrolled, err := s.HasVerifiedFactor(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// A pending factor of a user without MFA can be dropped without a code
+	if enrolled {
+		if err := s.stepUp(ctx, user, &VerifyRequest{
+			UserID:       user.ID,
+			TOTPCode:     req.TOTPCode,
+			RecoveryCode: req.RecoveryCode,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := s.mfaFactorRepo.Delete(ctx, factor.ID); err != nil {
+		return err
+	}
+
+	stillEnrolled, err := s.HasVerifiedFactor(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !stillEnrolled {
+		return s.clearMFA(ctx, user, false)
+	}
+
+	return nil
+}
+
+// Disable turns off MFA for a user after verifying a current TOTP or recovery code.
+// All factors and remaining recovery codes are removed.
+func (s *Service) Disable(ctx context.Context, req *VerifyRequest) error {
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if !user.MFAEnabled {
+		return fmt.Errorf("MFA is not enabled for this user")
+	}
+
+	if err := s.stepUp(ctx, user, req); err != nil {
+		return err
+	}
+
+	return s.clearMFA(ctx, user, false)
+}
+
+// RegenerateRecoveryCodes replaces all of a user's recovery codes after verifying a
+// current TOTP or recovery code. Previously issued codes stop working immediately.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, req *VerifyRequest) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if !user.MFAEnabled {
+		return nil, fmt.Errorf("MFA is not enabled for this user")
+	}
+
+	if err := s.stepUp(ctx, user, req); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.totpGenerator.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	// CreateRecoveryCodes replaces every existing code, used or not
+	if err := s.mfaRecoveryCodeRepo.CreateRecoveryCodes(ctx, user.ID, codes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Reset removes all of a user's factors and recovery codes on an administrator's behalf.
+// MFA stays required for the user, so they must enroll a new factor at next login.
+// It returns the number of factors removed.
+func (s *Service) Reset(ctx context.Context, userID uuid.UUID) (int, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("user not found: %w", err)
+	}
+
+	factors, err := s.mfaFactorRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get MFA factors: %w", err)
+	}
+
+	if err := s.clearMFA(ctx, user, true); err != nil {
+		return 0, err
+	}
+
+	return len(factors), nil
+}
+
+// stepUp verifies a code from an already verified factor or a recovery code
+func (s *Service) stepUp(ctx context.Context, user *models.User, req *VerifyRequest) error {
+	valid, err := s.verifyCode(ctx, user, req, false)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("invalid MFA code")
+	}
+	return nil
+}
+
+// clearMFA deletes every factor and recovery code of a user.
+// When requireEnrollment is set MFA stays enabled, forcing re-enrollment at next login.
+func (s *Service) clearMFA(ctx context.Context, user *models.User, requireEnrollment bool) error {
+	if err := s.mfaFactorRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := s.mfaRecoveryCodeRepo.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	user.MFAEnabled = requireEnrollment
+	user.MFASecretEncrypted = nil // Legacy single-secret column
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user MFA state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,404 @@
+package mfa
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/capability"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/cache"
+	"github.com/arauth-identity/iam/security/encryption"
+	"github.com/arauth-identity/iam/security/totp"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	pquernatotp "github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubUserRepository keeps users in memory; other methods are not used by these tests
+type stubUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *stubUserRepository) Update(ctx context.Context, u *models.User) error {
+	r.users[u.ID] = u
+	return nil
+}
+
+// totpCapability reports TOTP as supported; other methods are not used by these tests
+type totpCapability struct {
+	capability.ServiceInterface
+}
+
+func (totpCapability) IsCapabilitySupported(ctx context.Context, capabilityKey string) (bool, error) {
+	return true, nil
+}
+
+// fakeFactorRepository keeps factors in memory
+type fakeFactorRepository struct {
+	factors []*interfaces.MFAFactor
+}
+
+func (r *fakeFactorRepository) Create(ctx context.Context, f *interfaces.MFAFactor) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	f.CreatedAt = time.Now()
+	r.factors = append(r.factors, f)
+	return nil
+}
+
+func (r *fakeFactorRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.MFAFactor, error) {
+	for _, f := range r.factors {
+		if f.ID == id {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("MFA factor not found")
+}
+
+func (r *fakeFactorRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*interfaces.MFAFactor, error) {
+	var result []*interfaces.MFAFactor
+	for _, f := range r.factors {
+		if f.UserID == userID {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeFactorRepository) Update(ctx context.Context, f *interfaces.MFAFactor) error {
+	return nil
+}
+
+func (r *fakeFactorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.deleteWhere(func(f *interfaces.MFAFactor) bool { return f.ID == id })
+}
+
+func (r *fakeFactorRepository) DeleteUnverified(ctx context.Context, userID uuid.UUID) error {
+	return r.deleteWhere(func(f *interfaces.MFAFactor) bool { return f.UserID == userID && !f.Verified })
+}
+
+func (r *fakeFactorRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.deleteWhere(func(f *interfaces.MFAFactor) bool { return f.UserID == userID })
+}
+
+func (r *fakeFactorRepository) deleteWhere(match func(*interfaces.MFAFactor) bool) error {
+	kept := r.factors[:0]
+	for _, f := range r.factors {
+		if !match(f) {
+			kept = append(kept, f)
+		}
+	}
+	r.factors = kept
+	return nil
+}
+
+// fakeRecoveryCodeRepository keeps plaintext recovery codes in memory
+type fakeRecoveryCodeRepository struct {
+	codes map[uuid.UUID]map[string]bool
+}
+
+func (r *fakeRecoveryCodeRepository) CreateRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []string) error {
+	r.codes[userID] = make(map[string]bool)
+	for _, c := range codes {
+		r.codes[userID][c] = true
+	}
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepository) GetRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return []string{}, nil
+}
+
+func (r *fakeRecoveryCodeRepository) VerifyAndDeleteRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	if r.codes[userID][code] {
+		delete(r.codes[userID], code)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *fakeRecoveryCodeRepository) DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error {
+	delete(r.codes, userID)
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepository) CountRemaining(ctx context.Context, userID uuid.UUID) (int, error) {
+	return len(r.codes[userID]), nil
+}
+
+type manageFixture struct {
+	service   *Service
+	users     *stubUserRepository
+	factors   *fakeFactorRepository
+	recovery  *fakeRecoveryCodeRepository
+	encryptor *encryption.Encryptor
+	user      *models.User
+}
+
+func newManageFixture(t *testing.T) *manageFixture {
+	t.Helper()
+
+	encryptor, err := encryption.NewEncryptor([]byte("test-encryption-key-32-bytes-ok!"))
+	require.NoError(t, err)
+
+	u := &models.User{ID: uuid.New(), Email: "user@example.com", MFAEnabled: true}
+	f := &manageFixture{
+		users:     &stubUserRepository{users: map[uuid.UUID]*models.User{u.ID: u}},
+		factors:   &fakeFactorRepository{},
+		recovery:  &fakeRecoveryCodeRepository{codes: make(map[uuid.UUID]map[string]bool)},
+		encryptor: encryptor,
+		user:      u,
+	}
+	f.service = NewService(f.users, nil, f.recovery, f.factors, totp.NewGenerator("Test"), encryptor, nil, nil, nil)
+	return f
+}
+
+// addFactor enrolls a factor directly and returns its plaintext secret
+func (f *manageFixture) addFactor(t *testing.T, name string, verified bool) (*interfaces.MFAFactor, string) {
+	t.Helper()
+
+	secret, err := totp.NewGenerator("Test").GenerateSecret(f.user.Email)
+	require.NoError(t, err)
+	encrypted, err := f.encryptor.Encrypt(secret)
+	require.NoError(t, err)
+
+	factor := &interfaces.MFAFactor{
+		UserID:          f.user.ID,
+		Type:            FactorTypeTOTP,
+		Name:            name,
+		SecretEncrypted: encrypted,
+		Verified:        verified,
+	}
+	require.NoError(t, f.factors.Create(context.Background(), factor))
+	return factor, secret
+}
+
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := pquernatotp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestService_RemoveFactor_RequiresStepUp(t *testing.T) {
+	f := newManageFixture(t)
+	phone, _ := f.addFactor(t, "Phone", true)
+	f.addFactor(t, "Tablet", true)
+
+	err := f.service.RemoveFactor(context.Background(), &RemoveFactorRequest{
+		UserID:   f.user.ID,
+		FactorID: phone.ID,
+		TOTPCode: "000000",
+	})
+
+	assert.EqualError(t, err, "invalid MFA code")
+	assert.Len(t, f.factors.factors, 2)
+}
+
+func TestService_RemoveFactor_PendingFactorCannotStepUp(t *testing.T) {
+	f := newManageFixture(t)
+	phone, _ := f.addFactor(t, "Phone", true)
+	_, pendingSecret := f.addFactor(t, "New phone", false)
+
+	err := f.service.RemoveFactor(context.Background(), &RemoveFactorRequest{
+		UserID:   f.user.ID,
+		FactorID: phone.ID,
+		TOTPCode: currentCode(t, pendingSecret),
+	})
+
+	assert.EqualError(t, err, "invalid MFA code")
+	assert.Len(t, f.factors.factors, 2)
+}
+
+func TestService_RemoveFactor_KeepsMFAWhileFactorsRemain(t *testing.T) {
+	f := newManageFixture(t)
+	phone, _ := f.addFactor(t, "Phone", true)
+	_, tabletSecret := f.addFactor(t, "Tablet", true)
+	f.recovery.codes[f.user.ID] = map[string]bool{"CODE1": true}
+
+	err := f.service.RemoveFactor(context.Background(), &RemoveFactorRequest{
+		UserID:   f.user.ID,
+		FactorID: phone.ID,
+		TOTPCode: currentCode(t, tabletSecret),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, f.factors.factors, 1)
+	assert.Equal(t, "Tablet", f.factors.factors[0].Name)
+	assert.True(t, f.user.MFAEnabled)
+	assert.Len(t, f.recovery.codes[f.user.ID], 1)
+}
+
+func TestService_RemoveFactor_LastFactorDisablesMFA(t *testing.T) {
+	f := newManageFixture(t)
+	phone, _ := f.addFactor(t, "Phone", true)
+	f.recovery.codes[f.user.ID] = map[string]bool{"CODE1": true, "CODE2": true}
+
+	err := f.service.RemoveFactor(context.Background(), &RemoveFactorRequest{
+		UserID:       f.user.ID,
+		FactorID:     phone.ID,
+		RecoveryCode: "CODE1",
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, f.factors.factors)
+	assert.False(t, f.user.MFAEnabled)
+	assert.Empty(t, f.recovery.codes[f.user.ID])
+}
+
+func TestService_RemoveFactor_OtherUsersFactor(t *testing.T) {
+	f := newManageFixture(t)
+	_, secret := f.addFactor(t, "Phone", true)
+	other := &interfaces.MFAFactor{UserID: uuid.New(), Name: "Phone", Verified: true}
+	require.NoError(t, f.factors.Create(context.Background(), other))
+
+	err := f.service.RemoveFactor(context.Background(), &RemoveFactorRequest{
+		UserID:   f.user.ID,
+		FactorID: other.ID,
+		TOTPCode: currentCode(t, secret),
+	})
+
+	assert.EqualError(t, err, "MFA factor not found")
+	assert.Len(t, f.factors.factors, 2)
+}
+
+func TestService_RegenerateRecoveryCodes_InvalidatesOldCodes(t *testing.T) {
+	f := newManageFixture(t)
+	_, secret := f.addFactor(t, "Phone", true)
+	f.recovery.codes[f.user.ID] = map[string]bool{"OLDCODE": true}
+
+	codes, err := f.service.RegenerateRecoveryCodes(context.Background(), &VerifyRequest{
+		UserID:   f.user.ID,
+		TOTPCode: currentCode(t, secret),
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	assert.False(t, f.recovery.codes[f.user.ID]["OLDCODE"])
+	remaining, _ := f.recovery.CountRemaining(context.Background(), f.user.ID)
+	assert.Equal(t, recoveryCodeCount, remaining)
+}
+
+func TestService_Reset_RequiresReenrollment(t *testing.T) {
+	f := newManageFixture(t)
+	f.addFactor(t, "Phone", true)
+	f.addFactor(t, "Tablet", true)
+	f.recovery.codes[f.user.ID] = map[string]bool{"CODE1": true}
+
+	removed, err := f.service.Reset(context.Background(), f.user.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.Empty(t, f.factors.factors)
+	assert.Empty(t, f.recovery.codes[f.user.ID])
+	assert.True(t, f.user.MFAEnabled, "MFA stays required so the user must enroll again")
+
+	enrolled, err := f.service.HasVerifiedFactor(context.Background(), f.user.ID)
+	require.NoError(t, err)
+	assert.False(t, enrolled)
+}
+
+func TestService_StepUp_RejectsReusedCode(t *testing.T) {
+	f := newManageFixture(t)
+	f.service.replayGuard = NewReplayGuard(cache.NewMemoryCache(), nil)
+	_, secret := f.addFactor(t, "Phone", true)
+	code := currentCode(t, secret)
+
+	_, err := f.service.RegenerateRecoveryCodes(context.Background(), &VerifyRequest{UserID: f.user.ID, TOTPCode: code})
+	require.NoError(t, err)
+
+	_, err = f.service.RegenerateRecoveryCodes(context.Background(), &VerifyRequest{UserID: f.user.ID, TOTPCode: code})
+	assert.EqualError(t, err, "invalid MFA code")
+}
+
+func TestService_Enroll_SecondFactorRequiresStepUp(t *testing.T) {
+	f := newManageFixture(t)
+	f.service.capabilityService = totpCapability{}
+	_, secret := f.addFactor(t, "Phone", true)
+
+	_, err := f.service.Enroll(context.Background(), &EnrollRequest{UserID: f.user.ID, Name: "Tablet"})
+	assert.EqualError(t, err, "either totp_code or recovery_code must be provided")
+
+	_, err = f.service.Enroll(context.Background(), &EnrollRequest{UserID: f.user.ID, Name: "Tablet", TOTPCode: "000000"})
+	assert.EqualError(t, err, "invalid MFA code")
+	assert.Len(t, f.factors.factors, 1)
+
+	resp, err := f.service.Enroll(context.Background(), &EnrollRequest{UserID: f.user.ID, Name: "Tablet", TOTPCode: currentCode(t, secret)})
+	require.NoError(t, err)
+	assert.Equal(t, "Tablet", resp.Name)
+	assert.Len(t, f.factors.factors, 2)
+}
+
+func TestService_VerifyChallenge_IgnoresPendingFactorOnceEnrolled(t *testing.T) {
+	f := newManageFixture(t)
+	f.service.capabilityService = totpCapability{}
+	f.service.sessionManager = NewSessionManager(cache.NewMemoryCache())
+	_, verifiedSecret := f.addFactor(t, "Phone", true)
+	pending, pendingSecret := f.addFactor(t, "Attacker", false)
+
+	sessionID, err := f.service.sessionManager.CreateSession(context.Background(), f.user.ID, uuid.Nil)
+	require.NoError(t, err)
+
+	resp, err := f.service.VerifyChallenge(context.Background(), &VerifyChallengeRequest{SessionID: sessionID, TOTPCode: currentCode(t, pendingSecret)})
+	require.NoError(t, err)
+	assert.False(t, resp.Verified)
+	assert.False(t, pending.Verified)
+
+	resp, err = f.service.VerifyChallenge(context.Background(), &VerifyChallengeRequest{SessionID: sessionID, TOTPCode: currentCode(t, verifiedSecret)})
+	require.NoError(t, err)
+	assert.True(t, resp.Verified)
+}
+
+func TestService_VerifyChallenge_ConfirmsFirstFactor(t *testing.T) {
+	f := newManageFixture(t)
+	f.service.capabilityService = totpCapability{}
+	f.service.sessionManager = NewSessionManager(cache.NewMemoryCache())
+	pending, secret := f.addFactor(t, "Phone", false)
+
+	sessionID, err := f.service.sessionManager.CreateSession(context.Background(), f.user.ID, uuid.Nil)
+	require.NoError(t, err)
+
+	resp, err := f.service.VerifyChallenge(context.Background(), &VerifyChallengeRequest{SessionID: sessionID, TOTPCode: currentCode(t, secret)})
+	require.NoError(t, err)
+	assert.True(t, resp.Verified)
+	assert.True(t, pending.Verified)
+}
+
+func TestService_UseTimeStep_MixedPeriods(t *testing.T) {
+	f := newManageFixture(t)
+	f.service.replayGuard = NewReplayGuard(cache.NewMemoryCache(), nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	short := &interfaces.MFAFactor{Period: 30}
+	long := &interfaces.MFAFactor{Period: 60}
+
+	// Step 200 of a 30s factor ends at 6030s, step 100 of a 60s factor at 6060s
+	ok, err := f.service.useTimeStep(ctx, userID, 200, short)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = f.service.useTimeStep(ctx, userID, 100, long)
+	require.NoError(t, err)
+	assert.True(t, ok, "a later code from a factor with a longer period must be accepted")
+
+	ok, err = f.service.useTimeStep(ctx, userID, 201, short)
+	require.NoError(t, err)
+	assert.False(t, ok, "a code ending no later than the last one used must be rejected")
+
+	ok, err = f.service.useTimeStep(ctx, userID, 202, short)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
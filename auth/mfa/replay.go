@@ -0,0 +1,58 @@
+package mfa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/internal/cache"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ReplayGuard records when the last TOTP code used by each user stops being
+// valid, so that a code cannot be accepted twice, including by a concurrent
+// request. Times are compared rather than time steps because a user's factors
+// may use different periods.
+type ReplayGuard struct {
+	cache  cache.CacheInterface
+	logger *zap.Logger
+}
+
+// NewReplayGuard creates a new TOTP replay guard
+func NewReplayGuard(cacheClient cache.CacheInterface, logger *zap.Logger) *ReplayGuard {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ReplayGuard{cache: cacheClient, logger: logger}
+}
+
+// Use marks the time step ending at stepEnd as used for a user. It returns false
+// if a code whose step ends at that time or later was already used. ttl should
+// cover the whole validation window.
+func (g *ReplayGuard) Use(ctx context.Context, userID uuid.UUID, stepEnd time.Time, ttl time.Duration) (bool, error) {
+	lastKey := fmt.Sprintf("mfa:totp:last_step_end:%s", userID)
+	end := stepEnd.Unix()
+
+	var last int64
+	if err := g.cache.Get(ctx, lastKey, &last); err == nil && end <= last {
+		g.logger.Warn("Rejected reused TOTP code", zap.String("user_id", userID.String()))
+		return false, nil
+	}
+
+	// Claim the step atomically so two requests racing with the same code cannot both pass
+	claimed, err := g.cache.SetNX(ctx, fmt.Sprintf("mfa:totp:step_end:%s:%d", userID, end), true, ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+	if !claimed {
+		g.logger.Warn("Rejected reused TOTP code", zap.String("user_id", userID.String()))
+		return false, nil
+	}
+
+	if err := g.cache.Set(ctx, lastKey, end, ttl); err != nil {
+		return false, fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,39 @@
+package mfa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/internal/cache"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayGuard_Use(t *testing.T) {
+	guard := NewReplayGuard(cache.NewMemoryCache(), nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	stepEnd := time.Unix(3000, 0)
+
+	ok, err := guard.Use(ctx, userID, stepEnd, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = guard.Use(ctx, userID, stepEnd, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok, "same step must not be accepted twice")
+
+	ok, err = guard.Use(ctx, userID, stepEnd.Add(-30*time.Second), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok, "an older step must not be accepted after a newer one")
+
+	ok, err = guard.Use(ctx, userID, stepEnd.Add(30*time.Second), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = guard.Use(ctx, uuid.New(), stepEnd, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok, "steps are tracked per user")
+}
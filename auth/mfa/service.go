@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"time"
 
 	"github.com/arauth-identity/iam/identity/capability"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/logger"
 	"github.com/arauth-identity/iam/security/encryption"
 	"github.com/arauth-identity/iam/security/totp"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 // Service provides MFA functionality
@@ -18,9 +21,11 @@ type Service struct {
 	userRepo            interfaces.UserRepository
 	credentialRepo      interfaces.CredentialRepository
 	mfaRecoveryCodeRepo interfaces.MFARecoveryCodeRepository
+	mfaFactorRepo       interfaces.MFAFactorRepository
 	totpGenerator       *totp.Generator
 	encryptor           *encryption.Encryptor
 	sessionManager      *SessionManager
+	replayGuard         *ReplayGuard
 	capabilityService   capability.ServiceInterface
 }
 
@@ -29,32 +34,41 @@ func NewService(
 	userRepo interfaces.UserRepository,
 	credentialRepo interfaces.CredentialRepository,
 	mfaRecoveryCodeRepo interfaces.MFARecoveryCodeRepository,
+	mfaFactorRepo interfaces.MFAFactorRepository,
 	totpGenerator *totp.Generator,
 	encryptor *encryption.Encryptor,
 	sessionManager *SessionManager,
+	replayGuard *ReplayGuard,
 	capabilityService capability.ServiceInterface,
 ) *Service {
 	return &Service{
 		userRepo:            userRepo,
 		credentialRepo:      credentialRepo,
 		mfaRecoveryCodeRepo: mfaRecoveryCodeRepo,
+		mfaFactorRepo:       mfaFactorRepo,
 		totpGenerator:       totpGenerator,
 		encryptor:           encryptor,
 		sessionManager:      sessionManager,
+		replayGuard:         replayGuard,
 		capabilityService:   capabilityService,
 	}
 }
 
 // EnrollRequest represents a request to enroll in MFA
 type EnrollRequest struct {
-	UserID uuid.UUID `json:"user_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Name         string    `json:"name,omitempty"`          // Display name for the new factor, e.g. "Work phone"
+	TOTPCode     string    `json:"totp_code,omitempty"`     // Step-up code, required once a factor is verified
+	RecoveryCode string    `json:"recovery_code,omitempty"` // Alternative to TOTPCode for the step-up check
 }
 
 // EnrollResponse represents the response from MFA enrollment
 type EnrollResponse struct {
-	Secret        string   `json:"secret"`
-	QRCode        string   `json:"qr_code"` // Base64 encoded PNG
-	RecoveryCodes []string `json:"recovery_codes"`
+	FactorID      uuid.UUID `json:"factor_id"`
+	Name          string    `json:"name"`
+	Secret        string    `json:"secret"`
+	QRCode        string    `json:"qr_code"`                  // Base64 encoded PNG
+	RecoveryCodes []string  `json:"recovery_codes,omitempty"` // Only issued with the user's first factor
 }
 
 // VerifyRequest represents a request to verify MFA
@@ -64,7 +78,10 @@ type VerifyRequest struct {
 	RecoveryCode string    `json:"recovery_code,omitempty"`
 }
 
-// Enroll enrolls a user in MFA
+// Enroll starts enrollment of a new TOTP factor.
+// The factor stays pending until its first successful verification; any previous
+// pending factor is discarded. Recovery codes are only issued with the first factor.
+// Adding a factor next to a verified one requires a step-up code from an existing factor.
 func (s *Service) Enroll(ctx context.Context, req *EnrollRequest) (*EnrollResponse, error) {
 	// Get user
 	user, err := s.userRepo.GetByID(ctx, req.UserID)
@@ -72,28 +89,32 @@ func (s *Service) Enroll(ctx context.Context, req *EnrollRequest) (*EnrollRespon
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	// Check if MFA/TOTP is allowed and enabled via capability model
-	var tenantID uuid.UUID
-	if user.TenantID != nil {
-		tenantID = *user.TenantID
-		eval, err := s.capabilityService.EvaluateCapability(ctx, tenantID, user.ID, models.CapabilityKeyTOTP)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check TOTP capability: %w", err)
-		}
-		if !eval.CanUse {
-			return nil, fmt.Errorf("TOTP is not available for this tenant: %s", eval.Reason)
-		}
-	} else {
-		// For SYSTEM users, check if TOTP is supported
-		supported, err := s.capabilityService.IsCapabilitySupported(ctx, models.CapabilityKeyTOTP)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check TOTP capability: %w", err)
-		}
-		if !supported {
-			return nil, fmt.Errorf("TOTP is not supported")
+	if err := s.checkTOTPCapability(ctx, user); err != nil {
+		return nil, err
+	}
+
+	factors, err := s.mfaFactorRepo.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MFA factors: %w", err)
+	}
+	verified := verifiedFactors(factors)
+
+	// Otherwise a stolen session could add an attacker's authenticator
+	if len(verified) > 0 {
+		if err := s.stepUp(ctx, user, &VerifyRequest{
+			UserID:       user.ID,
+			TOTPCode:     req.TOTPCode,
+			RecoveryCode: req.RecoveryCode,
+		}); err != nil {
+			return nil, err
 		}
 	}
 
+	name, err := factorName(req.Name, verified)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate TOTP secret
 	secret, err := s.totpGenerator.GenerateSecret(user.Email)
 	if err != nil {
@@ -110,39 +131,60 @@ func (s *Service) Enroll(ctx context.Context, req *EnrollRequest) (*EnrollRespon
 	qrCodeBase64 := fmt.Sprintf("data:image/png;base64,%s",
 		base64.StdEncoding.EncodeToString(qrCodeBytes))
 
-	// Generate recovery codes
-	recoveryCodes, err := s.totpGenerator.GenerateRecoveryCodes(10)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
-	}
-
-	// Encrypt and store TOTP secret
+	// Encrypt TOTP secret
 	encryptedSecret, err := s.encryptor.Encrypt(secret)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
 	}
 
-	// Store encrypted secret but DO NOT enable MFA yet
-	// MFA will be enabled only after successful verification
-	user.MFASecretEncrypted = &encryptedSecret
-	// Keep MFAEnabled as false - it will be set to true after verification
-	if err := s.userRepo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to update user with MFA secret: %w", err)
+	// Only one enrollment may be pending at a time
+	if err := s.mfaFactorRepo.DeleteUnverified(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	// Store the factor but DO NOT enable it yet
+	// It becomes usable only after successful verification
+	settings := s.totpGenerator.Settings()
+	factor := &interfaces.MFAFactor{
+		UserID:          user.ID,
+		Type:            FactorTypeTOTP,
+		Name:            name,
+		SecretEncrypted: encryptedSecret,
+		Algorithm:       settings.Algorithm,
+		Digits:          settings.Digits,
+		Period:          settings.Period,
+	}
+	if err := s.mfaFactorRepo.Create(ctx, factor); err != nil {
+		return nil, err
 	}
 
-	// Store recovery codes (hashed in database)
-	if err := s.mfaRecoveryCodeRepo.CreateRecoveryCodes(ctx, user.ID, recoveryCodes); err != nil {
-		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	resp := &EnrollResponse{
+		FactorID: factor.ID,
+		Name:     factor.Name,
+		Secret:   secret, // Return plaintext secret only once for QR code setup
+		QRCode:   qrCodeBase64,
 	}
 
-	return &EnrollResponse{
-		Secret:        secret, // Return plaintext secret only once for QR code setup
-		QRCode:        qrCodeBase64,
-		RecoveryCodes: recoveryCodes, // Return recovery codes only once
-	}, nil
+	// Additional factors share the existing recovery codes
+	if len(verified) == 0 {
+		recoveryCodes, err := s.totpGenerator.GenerateRecoveryCodes(recoveryCodeCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+
+		// Store recovery codes (hashed in database), replacing any left over
+		if err := s.mfaRecoveryCodeRepo.CreateRecoveryCodes(ctx, user.ID, recoveryCodes); err != nil {
+			return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+		}
+		resp.RecoveryCodes = recoveryCodes // Return recovery codes only once
+	}
+
+	return resp, nil
 }
 
-// EnrollForLogin enrolls a user in MFA during login using a challenge session for security
+// EnrollForLogin enrolls a user in MFA during login using a challenge session for security.
+// Only users without a verified factor may enroll this way; otherwise a password alone
+// would be enough to add a factor.
 func (s *Service) EnrollForLogin(ctx context.Context, sessionID string) (*EnrollResponse, error) {
 	// Verify session exists and is valid
 	session, err := s.sessionManager.VerifySession(ctx, sessionID)
@@ -150,6 +192,14 @@ func (s *Service) EnrollForLogin(ctx context.Context, sessionID string) (*Enroll
 		return nil, fmt.Errorf("invalid or expired session: %w", err)
 	}
 
+	enrolled, err := s.HasVerifiedFactor(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if enrolled {
+		return nil, fmt.Errorf("MFA is already enrolled for this user")
+	}
+
 	// Use the user ID from the session
 	req := &EnrollRequest{
 		UserID: session.UserID,
@@ -159,8 +209,9 @@ func (s *Service) EnrollForLogin(ctx context.Context, sessionID string) (*Enroll
 	return s.Enroll(ctx, req)
 }
 
-// Verify verifies a TOTP code or recovery code
-// This method also enables MFA after first successful verification
+// Verify verifies a TOTP code or recovery code.
+// A TOTP code may match a pending factor, which completes its enrollment and
+// enables MFA for the user.
 func (s *Service) Verify(ctx context.Context, req *VerifyRequest) (bool, error) {
 	// Get user
 	user, err := s.userRepo.GetByID(ctx, req.UserID)
@@ -168,55 +219,69 @@ func (s *Service) Verify(ctx context.Context, req *VerifyRequest) (bool, error)
 		return false, fmt.Errorf("user not found: %w", err)
 	}
 
-	// Check if MFA/TOTP is allowed and enabled via capability model
-	var tenantID uuid.UUID
-	if user.TenantID != nil {
-		tenantID = *user.TenantID
-		eval, err := s.capabilityService.EvaluateCapability(ctx, tenantID, user.ID, models.CapabilityKeyTOTP)
-		if err != nil {
-			return false, fmt.Errorf("failed to check TOTP capability: %w", err)
-		}
-		if !eval.CanUse {
-			return false, fmt.Errorf("TOTP is not available for this tenant: %s", eval.Reason)
-		}
-	} else {
-		// For SYSTEM users, check if TOTP is supported
-		supported, err := s.capabilityService.IsCapabilitySupported(ctx, models.CapabilityKeyTOTP)
-		if err != nil {
-			return false, fmt.Errorf("failed to check TOTP capability: %w", err)
-		}
-		if !supported {
-			return false, fmt.Errorf("TOTP is not supported")
-		}
+	if err := s.checkTOTPCapability(ctx, user); err != nil {
+		return false, err
 	}
 
-	// Check if MFA secret exists (MFA may be enrolled but not yet verified)
-	if user.MFASecretEncrypted == nil {
+	return s.verifyCode(ctx, user, req, true)
+}
+
+// verifyCode checks a TOTP or recovery code against the user's factors.
+// Pending factors are only considered when allowPending is set, so step-up checks
+// cannot be satisfied by a factor that was just added.
+func (s *Service) verifyCode(ctx context.Context, user *models.User, req *VerifyRequest, allowPending bool) (bool, error) {
+	factors, err := s.mfaFactorRepo.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get MFA factors: %w", err)
+	}
+
+	// Check if any factor exists (MFA may be enrolled but not yet verified)
+	if len(factors) == 0 {
 		return false, fmt.Errorf("MFA secret not found. Please enroll in MFA first.")
 	}
 
 	if req.TOTPCode != "" {
-		// Get and decrypt TOTP secret
-		secret, err := s.encryptor.Decrypt(*user.MFASecretEncrypted)
-		if err != nil {
-			return false, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
-		}
+		checked := 0
+		for _, factor := range factors {
+			if !factor.Verified && !allowPending {
+				continue
+			}
+			checked++
 
-		// Verify TOTP code
-		valid := s.totpGenerator.Validate(secret, req.TOTPCode)
-
-		// If verification is successful and MFA is not yet enabled, enable it now
-		// This allows enrollment without immediate verification, but requires verification before MFA is active
-		if valid && !user.MFAEnabled {
-			user.MFAEnabled = true
-			if err := s.userRepo.Update(ctx, user); err != nil {
-				// Log error but don't fail verification - MFA is already working
-				// The flag will be set on next successful verification
-				fmt.Printf("Warning: Failed to enable MFA flag after verification: %v\n", err)
+			// Decrypt TOTP secret
+			secret, err := s.encryptor.Decrypt(factor.SecretEncrypted)
+			if err != nil {
+				return false, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+			}
+
+			step, ok := s.totpGenerator.ValidateStep(secret, req.TOTPCode, factorSettings(factor))
+			if !ok {
+				continue
+			}
+
+			// A code is only good once, even within its validity window
+			fresh, err := s.useTimeStep(ctx, user.ID, step, factor)
+			if err != nil {
+				return false, err
+			}
+			if !fresh {
+				return false, nil
 			}
+
+			if err := s.markFactorUsed(ctx, user, factor); err != nil {
+				return false, err
+			}
+			return true, nil
 		}
 
-		return valid, nil
+		// Never log the submitted code
+		if logger.Logger != nil {
+			logger.Logger.Warn("TOTP code did not match any MFA factor",
+				zap.String("user_id", user.ID.String()),
+				zap.Int("factors_checked", checked),
+			)
+		}
+		return false, nil
 	}
 
 	if req.RecoveryCode != "" {
@@ -231,6 +296,71 @@ func (s *Service) Verify(ctx context.Context, req *VerifyRequest) (bool, error)
 	return false, fmt.Errorf("either totp_code or recovery_code must be provided")
 }
 
+// useTimeStep records the time step a code matched and reports whether it was unused.
+// Without a replay guard every valid code is accepted.
+func (s *Service) useTimeStep(ctx context.Context, userID uuid.UUID, step int64, factor *interfaces.MFAFactor) (bool, error) {
+	if s.replayGuard == nil {
+		return true, nil
+	}
+
+	// Keep the step until it can no longer validate, i.e. past the skew window
+	period := time.Duration(factorSettings(factor).Period) * time.Second
+	if period <= 0 {
+		period = time.Duration(s.totpGenerator.Settings().Period) * time.Second
+	}
+	ttl := time.Duration(2*s.totpGenerator.Skew()+2) * period
+	stepEnd := time.Unix((step+1)*int64(period/time.Second), 0)
+
+	return s.replayGuard.Use(ctx, userID, stepEnd, ttl)
+}
+
+// markFactorUsed records a successful verification, completing enrollment of a
+// pending factor and enabling MFA for the user if needed
+func (s *Service) markFactorUsed(ctx context.Context, user *models.User, factor *interfaces.MFAFactor) error {
+	now := time.Now()
+	factor.LastUsedAt = &now
+	if !factor.Verified {
+		factor.Verified = true
+		factor.VerifiedAt = &now
+	}
+	if err := s.mfaFactorRepo.Update(ctx, factor); err != nil {
+		return fmt.Errorf("failed to update MFA factor: %w", err)
+	}
+
+	if !user.MFAEnabled {
+		user.MFAEnabled = true
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to enable MFA: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkTOTPCapability checks if MFA/TOTP is allowed and enabled via capability model
+func (s *Service) checkTOTPCapability(ctx context.Context, user *models.User) error {
+	if user.TenantID != nil {
+		eval, err := s.capabilityService.EvaluateCapability(ctx, *user.TenantID, user.ID, models.CapabilityKeyTOTP)
+		if err != nil {
+			return fmt.Errorf("failed to check TOTP capability: %w", err)
+		}
+		if !eval.CanUse {
+			return fmt.Errorf("TOTP is not available for this tenant: %s", eval.Reason)
+		}
+		return nil
+	}
+
+	// For SYSTEM users, check if TOTP is supported
+	supported, err := s.capabilityService.IsCapabilitySupported(ctx, models.CapabilityKeyTOTP)
+	if err != nil {
+		return fmt.Errorf("failed to check TOTP capability: %w", err)
+	}
+	if !supported {
+		return fmt.Errorf("TOTP is not supported")
+	}
+	return nil
+}
+
 // CreateSession creates a new MFA session
 func (s *Service) CreateSession(ctx context.Context, userID, tenantID uuid.UUID) (string, error) {
 	return s.sessionManager.CreateSession(ctx, userID, tenantID)
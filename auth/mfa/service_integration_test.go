@@ -34,6 +34,7 @@ func TestService_Enroll_Integration(t *testing.T) {
 	userRepo := postgres.NewUserRepository(db)
 	credentialRepo := postgres.NewCredentialRepository(db)
 	mfaRecoveryCodeRepo := postgres.NewMFARecoveryCodeRepository(db)
+	mfaFactorRepo := postgres.NewMFAFactorRepository(db)
 	tenantRepo := postgres.NewTenantRepository(db)
 
 	// Create tenant
@@ -88,7 +89,7 @@ func TestService_Enroll_Integration(t *testing.T) {
 	userCapabilityRepo := postgres.NewUserCapabilityStateRepository(db)
 	capabilityService := capability.NewService(systemCapabilityRepo, tenantCapabilityRepo, tenantFeatureRepo, userCapabilityRepo)
 
-	service := NewService(userRepo, credentialRepo, mfaRecoveryCodeRepo, totpGenerator, encryptor, sessionManager, capabilityService)
+	service := NewService(userRepo, credentialRepo, mfaRecoveryCodeRepo, mfaFactorRepo, totpGenerator, encryptor, sessionManager, nil, capabilityService)
 
 	// Test enrollment
 	req := &EnrollRequest{
@@ -103,11 +104,17 @@ func TestService_Enroll_Integration(t *testing.T) {
 	assert.NotEmpty(t, response.RecoveryCodes)
 	assert.Equal(t, 10, len(response.RecoveryCodes))
 
-	// Verify user has MFA enabled
+	// MFA is only enabled once the factor is verified
 	updatedUser, err := userRepo.GetByID(context.Background(), userID)
 	require.NoError(t, err)
-	assert.True(t, updatedUser.MFAEnabled)
-	assert.NotNil(t, updatedUser.MFASecretEncrypted)
+	assert.False(t, updatedUser.MFAEnabled)
+
+	// Enrollment creates a pending factor
+	factors, err := mfaFactorRepo.ListByUserID(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, factors, 1)
+	assert.Equal(t, response.FactorID, factors[0].ID)
+	assert.False(t, factors[0].Verified)
 }
 
 func TestService_Verify_Integration(t *testing.T) {
@@ -121,6 +128,7 @@ func TestService_Verify_Integration(t *testing.T) {
 	userRepo := postgres.NewUserRepository(db)
 	credentialRepo := postgres.NewCredentialRepository(db)
 	mfaRecoveryCodeRepo := postgres.NewMFARecoveryCodeRepository(db)
+	mfaFactorRepo := postgres.NewMFAFactorRepository(db)
 	tenantRepo := postgres.NewTenantRepository(db)
 
 	tenantID := uuid.New()
@@ -171,7 +179,7 @@ func TestService_Verify_Integration(t *testing.T) {
 	userCapabilityRepo := postgres.NewUserCapabilityStateRepository(db)
 	capabilityService := capability.NewService(systemCapabilityRepo, tenantCapabilityRepo, tenantFeatureRepo, userCapabilityRepo)
 
-	service := NewService(userRepo, credentialRepo, mfaRecoveryCodeRepo, totpGenerator, encryptor, sessionManager, capabilityService)
+	service := NewService(userRepo, credentialRepo, mfaRecoveryCodeRepo, mfaFactorRepo, totpGenerator, encryptor, sessionManager, nil, capabilityService)
 
 	// Enroll user first
 	enrollReq := &EnrollRequest{
@@ -205,6 +213,7 @@ func TestService_CreateChallenge_Integration(t *testing.T) {
 	userRepo := postgres.NewUserRepository(db)
 	credentialRepo := postgres.NewCredentialRepository(db)
 	mfaRecoveryCodeRepo := postgres.NewMFARecoveryCodeRepository(db)
+	mfaFactorRepo := postgres.NewMFAFactorRepository(db)
 	tenantRepo := postgres.NewTenantRepository(db)
 
 	tenantID := uuid.New()
@@ -281,7 +290,7 @@ func TestService_CreateChallenge_Integration(t *testing.T) {
 	userCapabilityRepo := postgres.NewUserCapabilityStateRepository(db)
 	capabilityService := capability.NewService(systemCapabilityRepo, tenantCapabilityRepo, tenantFeatureRepo, userCapabilityRepo)
 
-	service := NewService(userRepo, credentialRepo, mfaRecoveryCodeRepo, totpGenerator, encryptor, sessionManager, capabilityService)
+	service := NewService(userRepo, credentialRepo, mfaRecoveryCodeRepo, mfaFactorRepo, totpGenerator, encryptor, sessionManager, nil, capabilityService)
 
 	// Test challenge creation
 	req := &ChallengeRequest{
@@ -14,4 +14,10 @@ type ServiceInterface interface {
 	CreateChallenge(ctx context.Context, req *ChallengeRequest) (*ChallengeResponse, error)
 	VerifyChallenge(ctx context.Context, req *VerifyChallengeRequest) (*VerifyChallengeResponse, error)
 	CreateSession(ctx context.Context, userID, tenantID uuid.UUID) (string, error)
+	GetStatus(ctx context.Context, userID uuid.UUID) (*Status, error)
+	HasVerifiedFactor(ctx context.Context, userID uuid.UUID) (bool, error)
+	RemoveFactor(ctx context.Context, req *RemoveFactorRequest) error
+	Disable(ctx context.Context, req *VerifyRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, req *VerifyRequest) ([]string, error)
+	Reset(ctx context.Context, userID uuid.UUID) (int, error)
 }
@@ -0,0 +1,38 @@
+package token
+
+import (
+	"time"
+
+	"github.com/arauth-identity/iam/internal/useragent"
+	"github.com/arauth-identity/iam/storage/interfaces"
+)
+
+// maxDeviceInfoLength matches the refresh_tokens.device_info column size
+const maxDeviceInfoLength = 255
+
+// ClientInfo describes the client a refresh token is issued to.
+// IPAddress should already be resolved through the trusted proxy list.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// ApplyTo records the client's device/session metadata on a refresh token record.
+// A nil ClientInfo only updates LastUsedAt.
+func (ci *ClientInfo) ApplyTo(record *interfaces.RefreshToken, usedAt time.Time) {
+	record.LastUsedAt = &usedAt
+	if ci == nil {
+		return
+	}
+
+	record.IPAddress = ci.IPAddress
+	record.UserAgent = ci.UserAgent
+	record.DeviceInfo = ""
+	if ci.UserAgent != "" {
+		deviceInfo := useragent.Parse(ci.UserAgent).String()
+		if len(deviceInfo) > maxDeviceInfoLength {
+			deviceInfo = deviceInfo[:maxDeviceInfoLength]
+		}
+		record.DeviceInfo = deviceInfo
+	}
+}
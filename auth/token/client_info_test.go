@@ -0,0 +1,37 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientInfo_ApplyTo(t *testing.T) {
+	now := time.Now()
+	record := &interfaces.RefreshToken{}
+
+	client := &ClientInfo{
+		IPAddress: "203.0.113.7",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+	}
+	client.ApplyTo(record, now)
+
+	assert.Equal(t, "203.0.113.7", record.IPAddress)
+	assert.Equal(t, client.UserAgent, record.UserAgent)
+	assert.Equal(t, "Firefox 121 on Linux", record.DeviceInfo)
+	assert.Equal(t, &now, record.LastUsedAt)
+}
+
+func TestClientInfo_ApplyTo_NilKeepsMetadata(t *testing.T) {
+	now := time.Now()
+	record := &interfaces.RefreshToken{IPAddress: "198.51.100.1", DeviceInfo: "Safari 17 on iOS 17.1"}
+
+	var client *ClientInfo
+	client.ApplyTo(record, now)
+
+	assert.Equal(t, "198.51.100.1", record.IPAddress)
+	assert.Equal(t, "Safari 17 on iOS 17.1", record.DeviceInfo)
+	assert.Equal(t, &now, record.LastUsedAt)
+}
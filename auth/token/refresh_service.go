@@ -35,8 +35,10 @@ func NewRefreshService(
 	}
 }
 
-// RefreshToken refreshes an access token using a refresh token
-func (s *RefreshService) RefreshToken(ctx context.Context, refreshToken string) (*RefreshTokenResponse, error) {
+// RefreshToken refreshes an access token using a refresh token.
+// The rotated token records the calling client's metadata; when client is nil
+// the metadata of the previous token is carried over.
+func (s *RefreshService) RefreshToken(ctx context.Context, refreshToken string, client *ClientInfo) (*RefreshTokenResponse, error) {
 	// Hash the refresh token to look it up
 	refreshTokenHash, err := s.tokenService.HashRefreshToken(refreshToken)
 	if err != nil {
@@ -118,16 +120,21 @@ func (s *RefreshService) RefreshToken(ctx context.Context, refreshToken string)
 	}
 
 	// Store new refresh token
+	now := time.Now()
 	newTokenRecord := &interfaces.RefreshToken{
 		UserID:      user.ID,
 		TenantID:    tokenRecord.TenantID,
 		TokenHash:   newRefreshTokenHash,
-		ExpiresAt:   time.Now().Add(lifetimes.RefreshTokenTTL),
+		ExpiresAt:   now.Add(lifetimes.RefreshTokenTTL),
 		RememberMe:  tokenRecord.RememberMe,
 		MFAVerified: tokenRecord.MFAVerified, // Preserve MFA verification state
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		IPAddress:   tokenRecord.IPAddress,
+		UserAgent:   tokenRecord.UserAgent,
+		DeviceInfo:  tokenRecord.DeviceInfo,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
+	client.ApplyTo(newTokenRecord, now)
 
 	if err := s.refreshTokenRepo.Create(ctx, newTokenRecord); err != nil {
 		return nil, fmt.Errorf("failed to store refresh token: %w", err)
@@ -137,7 +144,7 @@ func (s *RefreshService) RefreshToken(ctx context.Context, refreshToken string)
 		AccessToken:      accessToken,
 		RefreshToken:     newRefreshToken,
 		TokenType:        "Bearer",
-		ExpiresIn:        int(lifetimes.AccessTokenTTL.Seconds()),
+		ExpiresIn:        int(claimsObj.CapLifetime(lifetimes.AccessTokenTTL, time.Now()).Seconds()),
 		RefreshExpiresIn: int(lifetimes.RefreshTokenTTL.Seconds()),
 	}, nil
 }
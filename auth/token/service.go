@@ -4,17 +4,22 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/arauth-identity/iam/auth/claims"
 	"github.com/arauth-identity/iam/config"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // Service provides token generation and validation
@@ -108,6 +113,9 @@ func (s *Service) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, e
 func (s *Service) GenerateAccessToken(claimsObj *claims.Claims, expiresIn time.Duration) (string, error) {
 	now := time.Now()
 
+	// Never let the token outlive a time-bound role it carries
+	expiresIn = claimsObj.CapLifetime(expiresIn, now)
+
 	// Build JWT claims
 	tokenClaims := jwt.MapClaims{
 		"sub":                claimsObj.Subject,
@@ -126,6 +134,19 @@ func (s *Service) GenerateAccessToken(claimsObj *claims.Claims, expiresIn time.D
 		"jti":                uuid.New().String(),
 	}
 
+	// Authentication context, used by step-up and conditional policies
+	if len(claimsObj.AMR) > 0 {
+		tokenClaims["amr"] = claimsObj.AMR
+	}
+	if claimsObj.AuthTime > 0 {
+		tokenClaims["auth_time"] = claimsObj.AuthTime
+	}
+
+	// Enterprise attributes, for resource servers that authorize on them
+	for name, value := range claimsObj.EnterpriseAttributes() {
+		tokenClaims[name] = value
+	}
+
 	// Add impersonation claims if present
 	if claimsObj.ImpersonatedBy != "" {
 		tokenClaims["impersonated_by"] = claimsObj.ImpersonatedBy
@@ -134,7 +155,106 @@ func (s *Service) GenerateAccessToken(claimsObj *claims.Claims, expiresIn time.D
 		tokenClaims["impersonation_session_id"] = claimsObj.ImpersonationSessionID
 	}
 
-	// Create token
+	return s.sign(tokenClaims)
+}
+
+// SignDetached signs a document as a detached JWS (RFC 7515, Appendix F): the
+// compact serialization with the payload left out, so the result can't be
+// presented as a token. typ and headers go into the protected header along
+// with the issuer and issue time. Used for signed documents such as access
+// review reports, which can be verified against the same JWKS as access tokens.
+func (s *Service) SignDetached(typ string, headers map[string]interface{}, payload []byte) (string, error) {
+	method, key, err := s.signingMethod()
+	if err != nil {
+		return "", err
+	}
+
+	header := map[string]interface{}{}
+	for k, v := range headers {
+		header[k] = v
+	}
+	header["alg"] = method.Alg()
+	header["typ"] = typ
+	header["iss"] = s.issuer
+	header["iat"] = time.Now().Unix()
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWS header: %w", err)
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	signature, err := method.Sign(protected+"."+base64.RawURLEncoding.EncodeToString(payload), key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign document: %w", err)
+	}
+	return protected + ".." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyDetached checks a detached JWS made by SignDetached against payload and
+// returns its protected header. The header's typ must equal typ.
+func (s *Service) VerifyDetached(signature string, payload []byte, typ string) (map[string]interface{}, error) {
+	parts := strings.Split(signature, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return nil, fmt.Errorf("invalid detached signature")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid detached signature: %w", err)
+	}
+	var header map[string]interface{}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("invalid detached signature: %w", err)
+	}
+	if header["typ"] != typ {
+		return nil, fmt.Errorf("unexpected signature type: %v", header["typ"])
+	}
+
+	method, key, err := s.verificationMethod(header["alg"])
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid detached signature: %w", err)
+	}
+	if err := method.Verify(parts[0]+"."+base64.RawURLEncoding.EncodeToString(payload), sig, key); err != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", err)
+	}
+	return header, nil
+}
+
+// signingMethod returns RS256 when a key pair is loaded, falling back to HS256
+func (s *Service) signingMethod() (jwt.SigningMethod, interface{}, error) {
+	if s.privateKey != nil {
+		return jwt.SigningMethodRS256, s.privateKey, nil
+	}
+	if len(s.secret) > 0 {
+		return jwt.SigningMethodHS256, s.secret, nil
+	}
+	return nil, nil, fmt.Errorf("no signing key available")
+}
+
+// verificationMethod returns the method and key for a signature made with alg
+func (s *Service) verificationMethod(alg interface{}) (jwt.SigningMethod, interface{}, error) {
+	switch alg {
+	case jwt.SigningMethodRS256.Alg():
+		if s.publicKey == nil {
+			return nil, nil, fmt.Errorf("public key not available")
+		}
+		return jwt.SigningMethodRS256, s.publicKey, nil
+	case jwt.SigningMethodHS256.Alg():
+		if len(s.secret) == 0 {
+			return nil, nil, fmt.Errorf("secret not available")
+		}
+		return jwt.SigningMethodHS256, s.secret, nil
+	}
+	return nil, nil, fmt.Errorf("unexpected signing method: %v", alg)
+}
+
+// sign signs claims with RS256 when a key pair is loaded, falling back to HS256
+func (s *Service) sign(tokenClaims jwt.MapClaims) (string, error) {
 	var token *jwt.Token
 	if s.privateKey != nil {
 		// Use RS256
@@ -149,24 +269,35 @@ func (s *Service) GenerateAccessToken(claimsObj *claims.Claims, expiresIn time.D
 	return "", fmt.Errorf("no signing key available")
 }
 
+// isAccessTokenType reports whether a JWT typ header denotes an access token
+func isAccessTokenType(typ interface{}) bool {
+	t, ok := typ.(string)
+	return ok && (strings.EqualFold(t, "JWT") || strings.EqualFold(t, "at+jwt"))
+}
+
 // GenerateRefreshToken generates an opaque refresh token (UUID)
 func (s *Service) GenerateRefreshToken() (string, error) {
 	return uuid.New().String(), nil
 }
 
 // HashRefreshToken hashes a refresh token for storage
+// The hash is deterministic (SHA-256) so the stored record can be looked up by it.
+// Refresh tokens are random UUIDs, so a slow salted hash adds no protection here.
 func (s *Service) HashRefreshToken(token string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(token), bcrypt.DefaultCost)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash refresh token: %w", err)
+	if token == "" {
+		return "", fmt.Errorf("failed to hash refresh token: token is empty")
 	}
-	return string(hash), nil
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:]), nil
 }
 
 // VerifyRefreshToken verifies a refresh token against its hash
 func (s *Service) VerifyRefreshToken(token, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(token))
-	return err == nil
+	computed, err := s.HashRefreshToken(token)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(hash)) == 1
 }
 
 // ValidateAccessToken validates and parses an access token
@@ -187,7 +318,7 @@ func (s *Service) ValidateAccessToken(tokenString string) (*claims.Claims, error
 			return s.secret, nil
 		}
 		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-	})
+	}, jwt.WithExpirationRequired())
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -197,6 +328,11 @@ func (s *Service) ValidateAccessToken(tokenString string) (*claims.Claims, error
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	// Documents signed with the same key carry their own type
+	if typ, ok := token.Header["typ"]; ok && !isAccessTokenType(typ) {
+		return nil, fmt.Errorf("invalid token type: %v", typ)
+	}
+
 	// Extract claims
 	claimsMap, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
@@ -212,6 +348,13 @@ func (s *Service) ValidateAccessToken(tokenString string) (*claims.Claims, error
 		Username:      getStringClaim(claimsMap, "username"),
 		Issuer:        getStringClaim(claimsMap, "iss"),
 		Audience:      getStringClaim(claimsMap, "aud"),
+		// Enterprise attributes
+		EmployeeNumber: getStringClaim(claimsMap, "employee_number"),
+		CostCenter:     getStringClaim(claimsMap, "cost_center"),
+		Organization:   getStringClaim(claimsMap, "organization"),
+		Division:       getStringClaim(claimsMap, "division"),
+		Department:     getStringClaim(claimsMap, "department"),
+		ManagerID:      getStringClaim(claimsMap, "manager_id"),
 	}
 
 	// Extract roles
@@ -254,6 +397,15 @@ func (s *Service) ValidateAccessToken(tokenString string) (*claims.Claims, error
 		}
 	}
 
+	// Extract authentication methods
+	if amr, ok := claimsMap["amr"].([]interface{}); ok {
+		for _, method := range amr {
+			if m, ok := method.(string); ok {
+				claimsObj.AMR = append(claimsObj.AMR, m)
+			}
+		}
+	}
+
 	// Extract scope
 	claimsObj.Scope = getStringClaim(claimsMap, "scope")
 
@@ -264,6 +416,9 @@ func (s *Service) ValidateAccessToken(tokenString string) (*claims.Claims, error
 	if iat, ok := claimsMap["iat"].(float64); ok {
 		claimsObj.IssuedAt = int64(iat)
 	}
+	if authTime, ok := claimsMap["auth_time"].(float64); ok {
+		claimsObj.AuthTime = int64(authTime)
+	}
 
 	// Extract JTI
 	claimsObj.ID = getStringClaim(claimsMap, "jti")
@@ -320,3 +475,9 @@ func (s *Service) GetPublicKey() interface{} {
 	}
 	return nil
 }
+
+// SigningKey returns the RSA key tokens are signed with, or nil when
+// tokens are signed with the shared HS256 secret
+func (s *Service) SigningKey() *rsa.PrivateKey {
+	return s.privateKey
+}
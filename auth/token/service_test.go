@@ -0,0 +1,86 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/auth/claims"
+	"github.com/arauth-identity/iam/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reportType = "access-review-report+jws"
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	service, err := NewService(&config.SecurityConfig{JWT: config.JWTConfig{Issuer: "test"}}, nil, nil)
+	require.NoError(t, err)
+	return service
+}
+
+func TestService_SignDetached_RoundTrip(t *testing.T) {
+	service := newTestService(t)
+	payload := []byte(`{"campaign":"q3"}`)
+
+	signature, err := service.SignDetached(reportType, map[string]interface{}{"tenant_id": "t1"}, payload)
+	require.NoError(t, err)
+
+	header, err := service.VerifyDetached(signature, payload, reportType)
+	require.NoError(t, err)
+	assert.Equal(t, "t1", header["tenant_id"])
+	assert.Equal(t, "test", header["iss"])
+
+	_, err = service.VerifyDetached(signature, []byte(`{"campaign":"q4"}`), reportType)
+	assert.Error(t, err)
+
+	_, err = service.VerifyDetached(signature, payload, "JWT")
+	assert.Error(t, err)
+}
+
+func TestService_ValidateAccessToken_RejectsDetachedSignature(t *testing.T) {
+	service := newTestService(t)
+
+	signature, err := service.SignDetached(reportType, map[string]interface{}{"sub": "user"}, []byte("report"))
+	require.NoError(t, err)
+
+	_, err = service.ValidateAccessToken(signature)
+	assert.Error(t, err)
+}
+
+func TestService_ValidateAccessToken_RequiresExpiry(t *testing.T) {
+	service := newTestService(t)
+
+	signed, err := service.sign(jwt.MapClaims{"sub": "user", "iat": time.Now().Unix()})
+	require.NoError(t, err)
+
+	_, err = service.ValidateAccessToken(signed)
+	assert.Error(t, err)
+}
+
+func TestService_ValidateAccessToken_RejectsForeignType(t *testing.T) {
+	service := newTestService(t)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "user",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["typ"] = "access-review-report+jwt"
+	signed, err := token.SignedString(service.privateKey)
+	require.NoError(t, err)
+
+	_, err = service.ValidateAccessToken(signed)
+	assert.ErrorContains(t, err, "invalid token type")
+}
+
+func TestService_ValidateAccessToken_AcceptsAccessToken(t *testing.T) {
+	service := newTestService(t)
+
+	signed, err := service.GenerateAccessToken(&claims.Claims{Subject: "user"}, time.Hour)
+	require.NoError(t, err)
+
+	parsed, err := service.ValidateAccessToken(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "user", parsed.Subject)
+}
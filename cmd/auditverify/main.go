@@ -0,0 +1,214 @@
+// Command auditverify checks the audit hash chains for gaps and modified
+// events, either in the database or in a JSON export
+// (GET /api/v1/audit/export?format=json).
+//
+//	auditverify -config config/config.yaml [-tenant <id>|system]
+//	auditverify -file audit_export.json -public-key signing_key.pub.pem
+//
+// Checkpoint signatures are verified with the token signing key: the
+// -public-key file (a public key, private key or certificate), or in
+// database mode the configured JWT signing key. It exits with status 2
+// when a chain fails verification.
+package main
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/arauth-identity/iam/config/loader"
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/auditchain"
+	"github.com/arauth-identity/iam/storage/postgres"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "Path to config file (database mode)")
+	file := flag.String("file", "", "JSON audit export to verify instead of the database")
+	tenant := flag.String("tenant", "", "Only verify this tenant's chain, or \"system\" for system events (database mode)")
+	publicKeyPath := flag.String("public-key", "", "PEM file with the key checkpoints are signed with")
+	asJSON := flag.Bool("json", false, "Print the reports as JSON")
+	flag.Parse()
+
+	var keys []*rsa.PublicKey
+	if *publicKeyPath != "" {
+		key, err := readPublicKey(*publicKeyPath)
+		if err != nil {
+			log.Fatalf("Failed to read public key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	var reports []*auditchain.Report
+	var err error
+	if *file != "" {
+		reports, err = verifyExport(*file, auditchain.NewKeySet(keys...))
+	} else {
+		reports, err = verifyDatabase(*configPath, *tenant, keys)
+	}
+	if err != nil {
+		log.Fatalf("Verification failed: %v", err)
+	}
+
+	valid := printReports(reports, *asJSON)
+	if !valid {
+		os.Exit(2)
+	}
+}
+
+// verifyDatabase verifies the chains stored in the configured database
+func verifyDatabase(configPath, tenant string, keys []*rsa.PublicKey) ([]*auditchain.Report, error) {
+	cfg, err := loader.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(keys) == 0 && cfg.Security.JWT.SigningKeyPath != "" {
+		key, err := readPublicKey(cfg.Security.JWT.SigningKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	db, err := postgres.NewConnection(&cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	chainService := audit.NewChainService(postgres.NewAuditEventRepository(db), nil, zap.NewNop(), keys...)
+	ctx := context.Background()
+
+	if tenant == "" {
+		return chainService.VerifyAll(ctx)
+	}
+	var tenantID *uuid.UUID
+	if tenant != "system" {
+		id, err := uuid.Parse(tenant)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tenant ID: %w", err)
+		}
+		tenantID = &id
+	}
+	report, err := chainService.Verify(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return []*auditchain.Report{report}, nil
+}
+
+// verifyExport verifies the chains in a JSON export. Exports made with
+// filters are checked event by event; missing events are only reported
+// when the export is complete.
+func verifyExport(path string, keys auditchain.KeySet) ([]*auditchain.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	var export audit.Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse export: %w", err)
+	}
+
+	type chain struct {
+		tenantID    *uuid.UUID
+		events      []*models.AuditEvent
+		checkpoints []*models.AuditCheckpoint
+	}
+	chains := map[uuid.UUID]*chain{}
+	get := func(tenantID *uuid.UUID) *chain {
+		id := models.AuditChainID(tenantID)
+		if chains[id] == nil {
+			chains[id] = &chain{tenantID: tenantID}
+		}
+		return chains[id]
+	}
+	for _, event := range export.Events {
+		c := get(event.TenantID)
+		c.events = append(c.events, event)
+	}
+	for _, cp := range export.Checkpoints {
+		c := get(cp.TenantID)
+		c.checkpoints = append(c.checkpoints, cp)
+	}
+
+	ids := make([]uuid.UUID, 0, len(chains))
+	for id := range chains {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+
+	reports := make([]*auditchain.Report, 0, len(ids))
+	for _, id := range ids {
+		c := chains[id]
+		sort.Slice(c.events, func(i, j int) bool { return c.events[i].Seq < c.events[j].Seq })
+
+		v := auditchain.NewVerifier(c.tenantID, c.checkpoints, keys, !export.Complete)
+		for _, event := range c.events {
+			v.Add(event)
+		}
+		reports = append(reports, v.Finish(nil))
+	}
+	return reports, nil
+}
+
+// readPublicKey reads an RSA key from a PEM file
+func readPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return auditchain.ParsePublicKey(data)
+}
+
+// printReports prints the reports and returns whether every chain is valid
+func printReports(reports []*auditchain.Report, asJSON bool) bool {
+	valid := true
+	for _, report := range reports {
+		valid = valid && report.Valid
+	}
+
+	if asJSON {
+		out, _ := json.MarshalIndent(map[string]interface{}{
+			"valid":  valid,
+			"chains": reports,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return valid
+	}
+
+	for _, report := range reports {
+		chain := "system"
+		if report.TenantID != nil {
+			chain = "tenant " + report.TenantID.String()
+		}
+		status := "OK"
+		if !report.Valid {
+			status = "FAILED"
+		}
+		fmt.Printf("%s: %s (%d events, seq %d-%d, %d checkpoints verified)\n",
+			chain, status, report.EventsChecked, report.FirstSeq, report.LastSeq, report.CheckpointsVerified)
+		for _, problem := range report.Problems {
+			if problem.EventID != nil {
+				fmt.Printf("  %s at seq %d (event %s): %s\n", problem.Kind, problem.Seq, problem.EventID, problem.Detail)
+			} else {
+				fmt.Printf("  %s at seq %d: %s\n", problem.Kind, problem.Seq, problem.Detail)
+			}
+		}
+		if report.ProblemsOmitted > 0 {
+			fmt.Printf("  ... and %d more problems\n", report.ProblemsOmitted)
+		}
+	}
+	if len(reports) == 0 {
+		fmt.Println("No audit chains found")
+	}
+	return valid
+}
@@ -8,10 +8,12 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/arauth-identity/iam/api/handlers"
+	"github.com/arauth-identity/iam/api/middleware"
 	"github.com/arauth-identity/iam/api/routes"
 	"github.com/arauth-identity/iam/auth/claims"
 	"github.com/arauth-identity/iam/auth/federation"
@@ -22,25 +24,36 @@ import (
 	"github.com/arauth-identity/iam/auth/token"
 	"github.com/arauth-identity/iam/config/loader"
 	"github.com/arauth-identity/iam/config/validator"
+	"github.com/arauth-identity/iam/identity/accessreview"
+	"github.com/arauth-identity/iam/identity/account"
 	auditevent "github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/authz"
 	"github.com/arauth-identity/iam/identity/capability"
+	"github.com/arauth-identity/iam/identity/connector"
+	"github.com/arauth-identity/iam/identity/elevation"
+	"github.com/arauth-identity/iam/identity/group"
 	"github.com/arauth-identity/iam/identity/impersonation"
 	"github.com/arauth-identity/iam/identity/invitation"
 	"github.com/arauth-identity/iam/identity/linking"
 	"github.com/arauth-identity/iam/identity/oauth_scope"
 	"github.com/arauth-identity/iam/identity/oauthclient"
 	"github.com/arauth-identity/iam/identity/permission"
+	"github.com/arauth-identity/iam/identity/policy"
 	"github.com/arauth-identity/iam/identity/ratelimit"
+	"github.com/arauth-identity/iam/identity/relation"
 	"github.com/arauth-identity/iam/identity/role"
 	"github.com/arauth-identity/iam/identity/scim"
 	"github.com/arauth-identity/iam/identity/session"
+	"github.com/arauth-identity/iam/identity/sod"
 	"github.com/arauth-identity/iam/identity/tenant"
 	"github.com/arauth-identity/iam/identity/user"
 	"github.com/arauth-identity/iam/identity/webhook"
 	auditlogger "github.com/arauth-identity/iam/internal/audit"
 	"github.com/arauth-identity/iam/internal/cache"
 	"github.com/arauth-identity/iam/internal/email"
+	"github.com/arauth-identity/iam/internal/jobs"
 	"github.com/arauth-identity/iam/internal/logger"
+	"github.com/arauth-identity/iam/internal/outbox"
 	webhookdispatcher "github.com/arauth-identity/iam/internal/webhook"
 	"github.com/arauth-identity/iam/observability/security_events"
 	"github.com/arauth-identity/iam/security/encryption"
@@ -156,8 +169,8 @@ func main() {
 
 	// Initialize security event logger (async, batched)
 	var securityEventLogger security_events.Logger
+	securityEventRepo := postgres.NewSecurityEventRepository(db)
 	if db != nil {
-		securityEventRepo := postgres.NewSecurityEventRepository(db)
 		batchSize := 100
 		flushInterval := 5 * time.Second
 		securityEventLogger = security_events.NewAsyncLogger(securityEventRepo, logger.Logger, batchSize, flushInterval)
@@ -176,11 +189,18 @@ func main() {
 	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
 	tenantSettingsRepo := postgres.NewTenantSettingsRepository(db)
 	mfaRecoveryCodeRepo := postgres.NewMFARecoveryCodeRepository(db)
+	mfaFactorRepo := postgres.NewMFAFactorRepository(db)
 	auditRepo := postgres.NewAuditRepository(db)
 	auditEventRepo := postgres.NewAuditEventRepository(db) // NEW: Structured audit event repository
 	roleRepo := postgres.NewRoleRepository(db)
 	permissionRepo := postgres.NewPermissionRepository(db)
 	systemRoleRepo := postgres.NewSystemRoleRepository(db) // NEW: System role repository
+	groupRepo := postgres.NewGroupRepository(db)
+	policyRepo := postgres.NewPolicyRepository(db)
+	relationRepo := postgres.NewRelationRepository(db)
+	elevationRepo := postgres.NewElevationRequestRepository(db)
+	accessReviewRepo := postgres.NewAccessReviewRepository(db)
+	sodRuleRepo := postgres.NewSoDRuleRepository(db)
 
 	// Initialize capability repositories
 	systemCapabilityRepo := postgres.NewSystemCapabilityRepository(db)
@@ -205,7 +225,7 @@ func main() {
 	// Initialize audit logger (legacy)
 	auditLogger := auditlogger.NewLogger(auditRepo)
 
-	// Initialize webhook service first (needed by audit service)
+	// Initialize webhook service; deliveries are queued by the outbox relay
 	webhookDispatcher := webhookdispatcher.NewDispatcher(webhookDeliveryRepo, logger.Logger)
 	webhookService := webhook.NewService(
 		webhookRepo,
@@ -214,10 +234,7 @@ func main() {
 		logger.Logger,
 	)
 
-	// Initialize audit event service (new structured audit) with webhook integration
-	auditEventService := auditevent.NewService(auditEventRepo, webhookService)
-
-	// Initialize encryption (for MFA secrets)
+	// Initialize encryption (for MFA secrets and connector credentials)
 	encryptionKey := []byte(cfg.Security.EncryptionKey)
 	if len(encryptionKey) != 32 {
 		logger.Logger.Fatal("Encryption key must be exactly 32 bytes (AES-256)")
@@ -227,26 +244,55 @@ func main() {
 		logger.Logger.Fatal("Failed to initialize encryptor", zap.Error(err))
 	}
 
+	// Initialize outbound SCIM provisioning; it is fed by audit events
+	connectorService := connector.NewService(
+		postgres.NewSCIMConnectorRepository(db),
+		postgres.NewSCIMSyncRepository(db),
+		userRepo,
+		groupRepo,
+		roleRepo,
+		encryptor,
+	)
+
+	// Initialize audit event service (new structured audit). Stored events are
+	// relayed from the outbox to webhooks and SCIM connectors.
+	outboxRepo := postgres.NewOutboxRepository(db)
+	outboxRelay := outbox.NewRelay(outboxRepo, logger.Logger, webhookService, connectorService)
+	auditEventService := auditevent.NewService(auditEventRepo)
+	webhookService.SetEventLogger(auditEventService)
+
 	// Initialize TOTP generator
 	totpIssuer := cfg.Security.TOTPIssuer
 	if totpIssuer == "" {
 		totpIssuer = "ARauth Identity"
 	}
-	totpGenerator := totp.NewGenerator(totpIssuer)
+	totpGenerator := totp.NewGeneratorWithOptions(totpIssuer, totp.Options{
+		Algorithm: cfg.Security.MFA.Algorithm,
+		Digits:    cfg.Security.MFA.Digits,
+		Period:    cfg.Security.MFA.Period,
+		Skew:      cfg.Security.MFA.Skew,
+		Logger:    logger.Logger,
+	})
+	if err := totp.ValidateSettings(totpGenerator.Settings()); err != nil {
+		logger.Logger.Fatal("Invalid MFA configuration", zap.Error(err))
+	}
 
 	// Initialize Hydra client
 	hydraClient := hydra.NewClient(cfg.Hydra.AdminURL)
 
-	// Initialize MFA session manager
+	// Initialize MFA session manager and TOTP replay guard
 	var mfaSessionManager *mfa.SessionManager
+	var totpReplayGuard *mfa.ReplayGuard
 	if cacheClient != nil {
 		mfaSessionManager = mfa.NewSessionManager(cacheClient)
+		totpReplayGuard = mfa.NewReplayGuard(cacheClient, logger.Logger)
 	} else {
 		// Use in-memory cache as fallback when Redis is not available
 		// In production, Redis should be required for MFA to persist across restarts
 		logger.Logger.Warn("Redis not available - Using in-memory cache for MFA sessions (sessions will not persist across restarts)")
 		memoryCache := cache.NewMemoryCache()
 		mfaSessionManager = mfa.NewSessionManager(memoryCache)
+		totpReplayGuard = mfa.NewReplayGuard(memoryCache, logger.Logger)
 	}
 
 	// Initialize token lifetime resolver
@@ -275,6 +321,9 @@ func main() {
 		}
 	}
 
+	// Audit hash chain checkpoints are signed with the token signing key
+	auditChainService := auditevent.NewChainService(auditEventRepo, tokenService.SigningKey(), logger.Logger)
+
 	// Initialize capability service (needed for claims builder)
 	capabilityService := capability.NewService(
 		systemCapabilityRepo,
@@ -287,18 +336,43 @@ func main() {
 	oauthScopeService := oauth_scope.NewService(oauthScopeRepo)
 
 	// Initialize claims builder with capability service and OAuth scope service
-	claimsBuilder := claims.NewBuilder(roleRepo, permissionRepo, systemRoleRepo, capabilityService, oauthScopeService)
+	claimsBuilder := claims.NewBuilder(roleRepo, permissionRepo, systemRoleRepo, capabilityService, oauthScopeService, groupRepo)
+
+	// Changes join one transaction with their audit events and outbox entries
+	txManager := postgres.NewTxManager(db)
 
 	// Initialize tenant initializer
 	tenantInitializer := tenant.NewInitializer(roleRepo, permissionRepo)
 
+	// Initialize conditional access policy service
+	policyService := policy.NewService(policyRepo)
+
+	// Initialize authorization decision service (grants are cached briefly in Redis when available)
+	var authzCache cache.CacheInterface
+	if cacheClient != nil {
+		authzCache = cacheClient
+	}
+	authzService := authz.NewService(userRepo, roleRepo, permissionRepo, groupRepo, policyService, tokenService, authzCache, authz.DefaultCacheTTL)
+
+	// Initialize relationship tuple service (check results share the authz cache)
+	relationService := relation.NewService(relationRepo, authzCache, relation.DefaultCacheTTL)
+
 	// Initialize services
 	tenantService := tenant.NewService(tenantRepo, tenantInitializer)
 	userService := user.NewService(userRepo, credentialRepo, refreshTokenRepo) // Pass credentialRepo to create credentials automatically
-	loginService := login.NewService(userRepo, credentialRepo, refreshTokenRepo, tenantSettingsRepo, tenantRepo, hydraClient, claimsBuilder, tokenService, lifetimeResolver, capabilityService)
-	mfaService := mfa.NewService(userRepo, credentialRepo, mfaRecoveryCodeRepo, totpGenerator, encryptor, mfaSessionManager, capabilityService)
-	roleService := role.NewService(roleRepo, permissionRepo)
+	loginService := login.NewService(userRepo, credentialRepo, refreshTokenRepo, tenantSettingsRepo, tenantRepo, mfaFactorRepo, hydraClient, claimsBuilder, tokenService, lifetimeResolver, capabilityService)
+	mfaService := mfa.NewService(userRepo, credentialRepo, mfaRecoveryCodeRepo, mfaFactorRepo, totpGenerator, encryptor, mfaSessionManager, totpReplayGuard, capabilityService)
+	sodService := sod.NewService(sodRuleRepo, roleRepo, groupRepo) // separation-of-duties rules veto role assignments
+	// Grants invalidated during a request are dropped again once its transaction ends
+	grantInvalidator := middleware.TrackInvalidations(authzService)
+	roleService := role.NewService(roleRepo, permissionRepo, grantInvalidator, sodService) // role changes invalidate cached authz grants
 	permissionService := permission.NewService(permissionRepo, tenantInitializer)
+	groupService := group.NewService(groupRepo, userRepo, roleRepo, grantInvalidator, sodService) // group changes invalidate cached authz grants
+	elevationService := elevation.NewService(elevationRepo, roleRepo, grantInvalidator, auditEventService, sodService)
+	// Separation-of-duties checks commit together with the assignment they allow
+	roleService.SetTxRunner(txManager)
+	groupService.SetTxRunner(txManager)
+	elevationService.SetTxRunner(txManager)
 
 	// Initialize session service
 	sessionService := session.NewService(refreshTokenRepo, userRepo)
@@ -328,15 +402,11 @@ func main() {
 	roleHandler := handlers.NewRoleHandler(roleService, systemRoleRepo, userRepo, auditEventService, permissionService)
 	systemHandler := handlers.NewSystemHandler(tenantService, tenantRepo, tenantSettingsRepo, capabilityService, auditEventService) // NEW: System handler with tenant settings
 	capabilityHandler := handlers.NewCapabilityHandler(capabilityService)                                                           // NEW: Capability handler
-	auditHandler := handlers.NewAuditHandler(auditEventService)                                                                     // NEW: Audit event handler
+	auditHandler := handlers.NewAuditHandler(auditEventService, auditChainService)                                                  // NEW: Audit event handler
 	federationHandler := handlers.NewFederationHandler(federationService)                                                           // NEW: Federation handler
-	webhookHandler := handlers.NewWebhookHandler(webhookService)                                                                    // NEW: Webhook handler
+	webhookHandler := handlers.NewWebhookHandler(webhookService, auditEventService)                                                 // NEW: Webhook handler
 	identityLinkingHandler := handlers.NewIdentityLinkingHandler(identityLinkingService)                                            // NEW: Identity linking handler
 
-	// Initialize token introspection service (RFC 7662)
-	introspectionService := introspection.NewService(jwtSecret, publicKey, cfg.Security.JWT.Issuer)
-	introspectionHandler := handlers.NewIntrospectionHandler(introspectionService) // NEW: Token introspection handler
-
 	// Initialize impersonation service
 	impersonationService := impersonation.NewService(
 		impersonationRepo,
@@ -354,14 +424,19 @@ func main() {
 	scimTokenRepo := postgres.NewSCIMTokenRepository(db)
 	scimTokenService := scim.NewTokenService(scimTokenRepo)
 
+	// Initialize SCIM extension schema service (tenant-defined User extensions)
+	scimSchemaService := scim.NewSchemaService(postgres.NewSCIMSchemaRepository(db))
+
 	// Initialize SCIM provisioning service
-	scimProvisioningService := scim.NewProvisioningService(userService, roleService, userRepo, roleRepo)
+	scimProvisioningService := scim.NewProvisioningService(userService, groupService, userRepo, scimSchemaService, auditEventService, txManager)
 
 	// Initialize SCIM handler
-	scimHandler := handlers.NewSCIMHandler(scimProvisioningService, scimTokenService)
+	scimHandler := handlers.NewSCIMHandler(scimProvisioningService, scimTokenService, scimSchemaService)
 
-	// Initialize SCIM token handler
+	// Initialize SCIM token and extension schema handlers
 	scimTokenHandler := handlers.NewSCIMTokenHandler(scimTokenService, auditEventService)
+	scimSchemaHandler := handlers.NewSCIMSchemaHandler(scimSchemaService, auditEventService)
+	scimConnectorHandler := handlers.NewSCIMConnectorHandler(connectorService, auditEventService)
 
 	// Initialize invitation repository and service
 	invitationRepo := postgres.NewInvitationRepository(db)
@@ -372,11 +447,72 @@ func main() {
 	// Initialize session handler
 	sessionHandler := handlers.NewSessionHandler(sessionService, auditEventService)
 
+	// Initialize self-service account service and handler
+	accountService := account.NewService(userService, userRepo, credentialRepo, tenantSettingsRepo, mfaService)
+	meHandler := handlers.NewMeHandler(accountService, mfaService, identityLinkingService, auditEventService)
+
 	// Initialize OAuth client repository, service, and handler
 	oauthClientRepo := postgres.NewOAuthClientRepository(db)
 	oauthClientService := oauthclient.NewService(oauthClientRepo, refreshTokenRepo)
+
+	// Initialize access review service; reports are signed with the token signing key
+	accessReviewService := accessreview.NewService(accessReviewRepo, roleRepo, oauthClientRepo, userRepo, emailService, tokenService, grantInvalidator)
 	oauthClientHandler := handlers.NewOAuthClientHandler(oauthClientService)
 
+	// Initialize authorization decision handler
+	authzHandler := handlers.NewAuthzHandler(authzService)
+
+	// Initialize group handler
+	groupHandler := handlers.NewGroupHandler(groupService, auditEventService)
+
+	// Initialize conditional access policy handler and enforcer
+	policyHandler := handlers.NewPolicyHandler(policyService, auditEventService)
+	policyEnforcer := middleware.NewPolicyEnforcer(policyService, userRepo)
+	relationHandler := handlers.NewRelationHandler(relationService, auditEventService)
+	elevationHandler := handlers.NewElevationHandler(elevationService, auditEventService)
+	accessReviewHandler := handlers.NewAccessReviewHandler(accessReviewService, auditEventService)
+	sodHandler := handlers.NewSoDHandler(sodService, auditEventService)
+
+	// Initialize background jobs. Every replica registers the same jobs and
+	// each run is leased to one replica through the scheduled_jobs table.
+	const (
+		securityEventRetention = 90 * 24 * time.Hour
+		invitationRetention    = 30 * 24 * time.Hour // Expired invitations stay listed this long
+		jobRunRetention        = 30 * 24 * time.Hour
+		outboxRetention        = 7 * 24 * time.Hour
+	)
+	jobRepo := postgres.NewJobRepository(db)
+	scheduler := jobs.NewScheduler(jobRepo, logger.Logger)
+	scheduler.Register("role_assignments.expire", jobs.Every(elevation.DefaultSweepInterval), 5*time.Minute, elevationService.ExpireDue)
+	scheduler.Register("access_reviews.remind", jobs.Every(accessreview.DefaultSweepInterval), 30*time.Minute, accessReviewService.RemindDue)
+	scheduler.Register("scim_connectors.push", jobs.Every(connector.DefaultSweepInterval), 5*time.Minute, connectorService.ProcessDue)
+	scheduler.Register("scim_connectors.reconcile", jobs.MustParse("*/5 * * * *"), 30*time.Minute, connectorService.ReconcileDue)
+	scheduler.Register("security_events.purge", jobs.MustParse("15 3 * * *"), 30*time.Minute, func(ctx context.Context) (int, error) {
+		return securityEventRepo.DeleteOlderThan(ctx, time.Now().Add(-securityEventRetention))
+	})
+	scheduler.Register("refresh_tokens.purge", jobs.MustParse("@hourly"), 10*time.Minute, func(ctx context.Context) (int, error) {
+		return 0, refreshTokenRepo.DeleteExpired(ctx)
+	})
+	scheduler.Register("invitations.purge", jobs.MustParse("30 3 * * *"), 10*time.Minute, func(ctx context.Context) (int, error) {
+		return invitationRepo.DeleteExpired(ctx, time.Now().Add(-invitationRetention))
+	})
+	scheduler.Register("job_runs.prune", jobs.MustParse("45 3 * * *"), 10*time.Minute, func(ctx context.Context) (int, error) {
+		return jobRepo.DeleteRunsBefore(ctx, time.Now().Add(-jobRunRetention))
+	})
+	scheduler.Register("outbox.prune", jobs.MustParse("0 4 * * *"), 10*time.Minute, func(ctx context.Context) (int, error) {
+		return outboxRepo.DeleteProcessedBefore(ctx, time.Now().Add(-outboxRetention))
+	})
+	if tokenService.SigningKey() != nil {
+		scheduler.Register("audit.checkpoint", jobs.Every(auditevent.DefaultCheckpointInterval), 5*time.Minute, auditChainService.Checkpoint)
+	} else {
+		logger.Logger.Warn("Audit checkpoints are not signed: tokens use a shared secret instead of an RSA signing key")
+	}
+	jobHandler := handlers.NewJobHandler(scheduler, auditEventService)
+
+	// Initialize token introspection (RFC 7662) and revocation (RFC 7009) service
+	introspectionService := introspection.NewService(jwtSecret, publicKey, cfg.Security.JWT.Issuer, tokenService, refreshTokenRepo, userRepo, tenantRepo)
+	introspectionHandler := handlers.NewIntrospectionHandler(introspectionService, oauthClientService, auditEventService)
+
 	// Set Gin mode
 	if cfg.Logging.Level == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -387,8 +523,14 @@ func main() {
 	// Create Gin router
 	router := gin.New()
 
+	// Only honour forwarding headers from configured proxies so client IPs recorded
+	// on sessions and audit events cannot be spoofed
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Logger.Fatal("Invalid trusted proxy configuration", zap.Error(err))
+	}
+
 	// Setup routes with dependencies
-	routes.SetupRoutes(router, logger.Logger, userHandler, authHandler, mfaHandler, tenantHandler, roleHandler, permissionHandler, systemHandler, capabilityHandler, auditHandler, federationHandler, webhookHandler, identityLinkingHandler, introspectionHandler, impersonationHandler, oauthScopeHandler, scimHandler, scimTokenHandler, scimTokenService, invitationHandler, sessionHandler, oauthClientHandler, tenantRepo, cacheClient, db, redisClient, tokenService, rateLimiter, securityEventLogger)
+	routes.SetupRoutes(router, logger.Logger, userHandler, authHandler, mfaHandler, tenantHandler, roleHandler, permissionHandler, systemHandler, capabilityHandler, auditHandler, federationHandler, webhookHandler, identityLinkingHandler, introspectionHandler, impersonationHandler, oauthScopeHandler, scimHandler, scimTokenHandler, scimSchemaHandler, scimConnectorHandler, scimTokenService, invitationHandler, sessionHandler, meHandler, oauthClientHandler, authzHandler, groupHandler, policyHandler, policyEnforcer, txManager, authzService, relationHandler, elevationHandler, accessReviewHandler, sodHandler, jobHandler, tenantRepo, cacheClient, db, redisClient, tokenService, rateLimiter, securityEventLogger)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -409,12 +551,32 @@ func main() {
 		}
 	}()
 
+	// Run background jobs, the outbox relay and webhook workers until shutdown
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		scheduler.Run(workerCtx, jobs.DefaultPollInterval)
+	}()
+	go func() {
+		defer workers.Done()
+		outboxRelay.Run(workerCtx, outbox.DefaultPollInterval)
+	}()
+	go func() {
+		defer workers.Done()
+		webhookService.RunWorkers(workerCtx, webhook.DefaultWorkers, webhook.DefaultPollInterval)
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Logger.Info("Shutting down server...")
+	stopWorkers()
+	workers.Wait() // Running jobs and deliveries record their result and release their lease
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
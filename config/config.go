@@ -23,6 +23,9 @@ type ServerConfig struct {
 	ReadTimeout time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" envDefault:"30s"`
 	WriteTimeout time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" envDefault:"30s"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" envDefault:"120s"`
+	// TrustedProxies lists proxy IPs/CIDRs whose X-Forwarded-For / X-Real-IP headers are honoured
+	// when resolving the client IP. Empty means no proxy is trusted and the socket address is used.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
 }
 
 // DatabaseConfig holds database configuration
@@ -96,9 +99,11 @@ type PasswordConfig struct {
 
 // MFAConfig holds MFA configuration
 type MFAConfig struct {
-	Issuer string `yaml:"issuer" env:"MFA_ISSUER" envDefault:"ARauth Identity"`
-	Period int    `yaml:"period" env:"MFA_PERIOD" envDefault:"30"`
-	Digits int    `yaml:"digits" env:"MFA_DIGITS" envDefault:"6"`
+	Issuer    string `yaml:"issuer" env:"MFA_ISSUER" envDefault:"ARauth Identity"`
+	Period    int    `yaml:"period" env:"MFA_PERIOD" envDefault:"30"`
+	Digits    int    `yaml:"digits" env:"MFA_DIGITS" envDefault:"6"`
+	Algorithm string `yaml:"algorithm" env:"MFA_ALGORITHM" envDefault:"SHA1"` // HMAC algorithm for new TOTP enrollments
+	Skew      int    `yaml:"skew" env:"MFA_SKEW" envDefault:"1"`              // Time steps accepted either side of the current one
 }
 
 // RateLimitConfig holds rate limiting configuration
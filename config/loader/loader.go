@@ -48,6 +48,14 @@ func loadFromEnv(cfg *config.Config) {
 	if host := os.Getenv("SERVER_HOST"); host != "" {
 		cfg.Server.Host = host
 	}
+	if proxies := os.Getenv("SERVER_TRUSTED_PROXIES"); proxies != "" {
+		cfg.Server.TrustedProxies = nil
+		for _, proxy := range strings.Split(proxies, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				cfg.Server.TrustedProxies = append(cfg.Server.TrustedProxies, proxy)
+			}
+		}
+	}
 
 	// Database
 	if host := os.Getenv("DATABASE_HOST"); host != "" {
@@ -93,6 +101,20 @@ func loadFromEnv(cfg *config.Config) {
 		cfg.Security.JWT.Issuer = issuer
 	}
 
+	// MFA
+	if period := os.Getenv("MFA_PERIOD"); period != "" {
+		_, _ = fmt.Sscanf(period, "%d", &cfg.Security.MFA.Period)
+	}
+	if digits := os.Getenv("MFA_DIGITS"); digits != "" {
+		_, _ = fmt.Sscanf(digits, "%d", &cfg.Security.MFA.Digits)
+	}
+	if algorithm := os.Getenv("MFA_ALGORITHM"); algorithm != "" {
+		cfg.Security.MFA.Algorithm = strings.ToUpper(algorithm)
+	}
+	if skew := os.Getenv("MFA_SKEW"); skew != "" {
+		_, _ = fmt.Sscanf(skew, "%d", &cfg.Security.MFA.Skew)
+	}
+
 	// Logging
 	if level := os.Getenv("LOG_LEVEL"); level != "" {
 		cfg.Logging.Level = strings.ToLower(level)
@@ -157,6 +179,15 @@ func setDefaults(cfg *config.Config) {
 	if cfg.Hydra.PublicURL == "" {
 		cfg.Hydra.PublicURL = "http://localhost:4444"
 	}
+	if cfg.Security.MFA.Period == 0 {
+		cfg.Security.MFA.Period = 30
+	}
+	if cfg.Security.MFA.Digits == 0 {
+		cfg.Security.MFA.Digits = 6
+	}
+	if cfg.Security.MFA.Algorithm == "" {
+		cfg.Security.MFA.Algorithm = "SHA1"
+	}
 	if cfg.Logging.Level == "" {
 		cfg.Logging.Level = "info"
 	}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/arauth-identity/iam/config"
+	"github.com/arauth-identity/iam/security/totp"
 )
 
 // Validate validates the configuration
@@ -66,6 +67,16 @@ func Validate(cfg *config.Config) error {
 	if cfg.Security.Password.MinLength < 8 {
 		return fmt.Errorf("password min_length must be >= 8")
 	}
+	if err := totp.ValidateSettings(totp.Settings{
+		Algorithm: cfg.Security.MFA.Algorithm,
+		Digits:    cfg.Security.MFA.Digits,
+		Period:    cfg.Security.MFA.Period,
+	}); err != nil {
+		return fmt.Errorf("invalid mfa configuration: %w", err)
+	}
+	if cfg.Security.MFA.Skew < 0 || cfg.Security.MFA.Skew > 5 {
+		return fmt.Errorf("mfa skew must be between 0 and 5")
+	}
 
 	// Logging validation
 	validLevels := map[string]bool{
@@ -0,0 +1,23 @@
+package accessreview
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines the interface for access review operations
+type ServiceInterface interface {
+	Create(ctx context.Context, req *CreateCampaignRequest) (*models.AccessReviewCampaign, []*models.AccessReviewItem, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.AccessReviewCampaign, error)
+	List(ctx context.Context, tenantID uuid.UUID, status *string) ([]*models.AccessReviewCampaign, error)
+	ListItems(ctx context.Context, tenantID, id uuid.UUID, filters *interfaces.AccessReviewItemFilters) ([]*models.AccessReviewItem, error)
+	ListReviewerItems(ctx context.Context, tenantID, reviewerID uuid.UUID) ([]*models.AccessReviewItem, error)
+	Decide(ctx context.Context, tenantID, itemID, actorID uuid.UUID, canManage bool, req *DecisionRequest) (*models.AccessReviewItem, error)
+	Close(ctx context.Context, tenantID, id, actorID uuid.UUID) (*CloseResult, error)
+	Cancel(ctx context.Context, tenantID, id, actorID uuid.UUID) (*models.AccessReviewCampaign, error)
+	ExportReport(ctx context.Context, tenantID, id uuid.UUID, format string) (*Report, error)
+	SendReminders(ctx context.Context, tenantID, id uuid.UUID) (int, error)
+}
@@ -0,0 +1,417 @@
+package accessreview
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/email"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryReviewRepository is an in-memory AccessReviewRepository
+type memoryReviewRepository struct {
+	campaigns   map[uuid.UUID]*models.AccessReviewCampaign
+	items       map[uuid.UUID]*models.AccessReviewItem
+	assignments []*interfaces.AccessReviewAssignment
+}
+
+func newMemoryReviewRepository() *memoryReviewRepository {
+	return &memoryReviewRepository{
+		campaigns: make(map[uuid.UUID]*models.AccessReviewCampaign),
+		items:     make(map[uuid.UUID]*models.AccessReviewItem),
+	}
+}
+
+func (r *memoryReviewRepository) CreateCampaign(ctx context.Context, campaign *models.AccessReviewCampaign, items []*models.AccessReviewItem) error {
+	campaign.ID = uuid.New()
+	copied := *campaign
+	r.campaigns[campaign.ID] = &copied
+	for _, item := range items {
+		item.ID = uuid.New()
+		item.CampaignID = campaign.ID
+		item.TenantID = campaign.TenantID
+		itemCopy := *item
+		r.items[item.ID] = &itemCopy
+	}
+	return nil
+}
+
+func (r *memoryReviewRepository) GetCampaign(ctx context.Context, id uuid.UUID) (*models.AccessReviewCampaign, error) {
+	campaign, ok := r.campaigns[id]
+	if !ok {
+		return nil, fmt.Errorf("access review campaign not found")
+	}
+	copied := *campaign
+	return &copied, nil
+}
+
+func (r *memoryReviewRepository) ListCampaigns(ctx context.Context, tenantID uuid.UUID, status *string) ([]*models.AccessReviewCampaign, error) {
+	var out []*models.AccessReviewCampaign
+	for _, campaign := range r.campaigns {
+		if campaign.TenantID == tenantID && (status == nil || campaign.Status == *status) {
+			copied := *campaign
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryReviewRepository) ListActiveCampaigns(ctx context.Context) ([]*models.AccessReviewCampaign, error) {
+	var out []*models.AccessReviewCampaign
+	for _, campaign := range r.campaigns {
+		if campaign.Status == models.AccessReviewStatusActive {
+			copied := *campaign
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryReviewRepository) UpdateCampaign(ctx context.Context, campaign *models.AccessReviewCampaign) error {
+	copied := *campaign
+	r.campaigns[campaign.ID] = &copied
+	return nil
+}
+
+func (r *memoryReviewRepository) GetItem(ctx context.Context, id uuid.UUID) (*models.AccessReviewItem, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, fmt.Errorf("access review item not found")
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (r *memoryReviewRepository) ListItems(ctx context.Context, campaignID uuid.UUID, filters *interfaces.AccessReviewItemFilters) ([]*models.AccessReviewItem, error) {
+	var out []*models.AccessReviewItem
+	for _, item := range r.items {
+		if item.CampaignID != campaignID {
+			continue
+		}
+		if filters != nil && filters.Decision != nil && *filters.Decision == "none" && item.IsDecided() {
+			continue
+		}
+		copied := *item
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+func (r *memoryReviewRepository) ListReviewerItems(ctx context.Context, tenantID, reviewerID uuid.UUID) ([]*models.AccessReviewItem, error) {
+	var out []*models.AccessReviewItem
+	for _, item := range r.items {
+		if item.TenantID == tenantID && item.ReviewerID != nil && *item.ReviewerID == reviewerID && !item.IsDecided() {
+			copied := *item
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryReviewRepository) UpdateItem(ctx context.Context, item *models.AccessReviewItem) error {
+	copied := *item
+	r.items[item.ID] = &copied
+	return nil
+}
+
+func (r *memoryReviewRepository) ListRoleAssignments(ctx context.Context, tenantID uuid.UUID, roleIDs, userIDs []uuid.UUID) ([]*interfaces.AccessReviewAssignment, error) {
+	return r.assignments, nil
+}
+
+// stubRoleRepository records removed assignments
+type stubRoleRepository struct {
+	interfaces.RoleRepository
+	removed [][2]uuid.UUID
+}
+
+func (r *stubRoleRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error {
+	r.removed = append(r.removed, [2]uuid.UUID{userID, roleID})
+	return nil
+}
+
+// stubClientRepository keeps OAuth clients in memory
+type stubClientRepository struct {
+	interfaces.OAuthClientRepository
+	clients map[uuid.UUID]*interfaces.OAuthClient
+}
+
+func (r *stubClientRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*interfaces.OAuthClient, error) {
+	var out []*interfaces.OAuthClient
+	for _, client := range r.clients {
+		if client.TenantID == tenantID {
+			out = append(out, client)
+		}
+	}
+	return out, nil
+}
+
+func (r *stubClientRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.OAuthClient, error) {
+	client, ok := r.clients[id]
+	if !ok {
+		return nil, fmt.Errorf("client not found")
+	}
+	return client, nil
+}
+
+func (r *stubClientRepository) Update(ctx context.Context, client *interfaces.OAuthClient) error {
+	r.clients[client.ID] = client
+	return nil
+}
+
+// stubUserRepository keeps users in memory
+type stubUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+// recordingEmailService records reminders
+type recordingEmailService struct {
+	email.NoOpEmailService
+	reminders map[string]int
+}
+
+func (s *recordingEmailService) SendAccessReviewReminder(ctx context.Context, to string, campaignName string, pendingItems int, dueAt string) error {
+	s.reminders[to] = pendingItems
+	return nil
+}
+
+// fixedSigner returns a recognisable signature
+type fixedSigner struct {
+	typ     string
+	headers map[string]interface{}
+	payload []byte
+}
+
+func (s *fixedSigner) SignDetached(typ string, headers map[string]interface{}, payload []byte) (string, error) {
+	s.typ, s.headers, s.payload = typ, headers, payload
+	return "signed", nil
+}
+
+type fixture struct {
+	service  *Service
+	reviews  *memoryReviewRepository
+	roles    *stubRoleRepository
+	clients  *stubClientRepository
+	emails   *recordingEmailService
+	signer   *fixedSigner
+	tenantID uuid.UUID
+	owner    uuid.UUID
+	manager  uuid.UUID
+	auditor  uuid.UUID
+	userID   uuid.UUID
+	roleID   uuid.UUID
+}
+
+func newFixture() *fixture {
+	tenantID := uuid.New()
+	f := &fixture{
+		reviews:  newMemoryReviewRepository(),
+		roles:    &stubRoleRepository{},
+		clients:  &stubClientRepository{clients: make(map[uuid.UUID]*interfaces.OAuthClient)},
+		emails:   &recordingEmailService{reminders: make(map[string]int)},
+		signer:   &fixedSigner{},
+		tenantID: tenantID,
+		owner:    uuid.New(),
+		manager:  uuid.New(),
+		auditor:  uuid.New(),
+		userID:   uuid.New(),
+		roleID:   uuid.New(),
+	}
+	users := &stubUserRepository{users: map[uuid.UUID]*models.User{}}
+	for id, mail := range map[uuid.UUID]string{f.owner: "owner@example.com", f.manager: "manager@example.com", f.auditor: "auditor@example.com"} {
+		users.users[id] = &models.User{ID: id, TenantID: &tenantID, Email: mail}
+	}
+	f.reviews.assignments = []*interfaces.AccessReviewAssignment{
+		{UserID: f.userID, Username: "bob", ManagerID: &f.manager, RoleID: f.roleID, RoleName: "db-admin", RoleOwnerID: &f.owner},
+		// The owner holds their own role and must not review it
+		{UserID: f.owner, Username: "olivia", RoleID: f.roleID, RoleName: "db-admin", RoleOwnerID: &f.owner},
+	}
+	f.service = NewService(f.reviews, f.roles, f.clients, users, f.emails, f.signer, nil)
+	return f
+}
+
+func (f *fixture) create(t *testing.T, reviewerType, defaultDecision string) (*models.AccessReviewCampaign, []*models.AccessReviewItem) {
+	t.Helper()
+	campaign, items, err := f.service.Create(context.Background(), &CreateCampaignRequest{
+		TenantID:            f.tenantID,
+		Name:                "Q3 db-admin review",
+		ScopeType:           models.AccessReviewScopeRole,
+		ScopeIDs:            []uuid.UUID{f.roleID},
+		ReviewerType:        reviewerType,
+		FallbackReviewerIDs: []uuid.UUID{f.auditor},
+		DefaultDecision:     defaultDecision,
+	})
+	require.NoError(t, err)
+	return campaign, items
+}
+
+func itemFor(items []*models.AccessReviewItem, userID uuid.UUID) *models.AccessReviewItem {
+	for _, item := range items {
+		if item.UserID != nil && *item.UserID == userID {
+			return item
+		}
+	}
+	return nil
+}
+
+func TestService_Create_RoutesReviewers(t *testing.T) {
+	f := newFixture()
+
+	campaign, items := f.create(t, models.AccessReviewerRoleOwner, "")
+	assert.Equal(t, models.AccessReviewStatusActive, campaign.Status)
+	assert.Equal(t, models.AccessReviewDecisionKeep, campaign.DefaultDecision)
+	require.Len(t, items, 2)
+	assert.Equal(t, f.owner, *itemFor(items, f.userID).ReviewerID)
+	assert.Equal(t, f.auditor, *itemFor(items, f.owner).ReviewerID, "self-review falls back")
+
+	_, items = f.create(t, models.AccessReviewerManager, "")
+	assert.Equal(t, f.manager, *itemFor(items, f.userID).ReviewerID)
+	assert.Equal(t, f.auditor, *itemFor(items, f.owner).ReviewerID, "no manager falls back")
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	_, _, err := f.service.Create(ctx, &CreateCampaignRequest{
+		TenantID: f.tenantID, Name: "x", ScopeType: models.AccessReviewScopeRole, ReviewerType: models.AccessReviewerUsers,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fallback_reviewer_ids is required")
+
+	_, _, err = f.service.Create(ctx, &CreateCampaignRequest{
+		TenantID: f.tenantID, Name: "x", ScopeType: models.AccessReviewScopeRole, ReviewerType: models.AccessReviewerUsers,
+		FallbackReviewerIDs: []uuid.UUID{uuid.New()},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fallback reviewer")
+}
+
+func TestService_Decide(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	_, items := f.create(t, models.AccessReviewerRoleOwner, "")
+	item := itemFor(items, f.userID)
+
+	_, err := f.service.Decide(ctx, f.tenantID, item.ID, f.manager, false, &DecisionRequest{Decision: models.AccessReviewDecisionRevoke})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not the reviewer")
+
+	_, err = f.service.Decide(ctx, f.tenantID, item.ID, f.userID, true, &DecisionRequest{Decision: models.AccessReviewDecisionKeep})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot review your own access")
+
+	comment := "left the team"
+	decided, err := f.service.Decide(ctx, f.tenantID, item.ID, f.owner, false, &DecisionRequest{Decision: models.AccessReviewDecisionRevoke, Comment: &comment})
+	require.NoError(t, err)
+	assert.Equal(t, models.AccessReviewDecisionRevoke, *decided.Decision)
+	assert.Equal(t, f.owner, *decided.DecidedBy)
+
+	_, err = f.service.Decide(ctx, uuid.New(), item.ID, f.owner, false, &DecisionRequest{Decision: models.AccessReviewDecisionKeep})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestService_Close_AppliesRevocations(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	campaign, items := f.create(t, models.AccessReviewerRoleOwner, models.AccessReviewDecisionRevoke)
+
+	// bob is kept explicitly; olivia is left undecided and takes the default
+	_, err := f.service.Decide(ctx, f.tenantID, itemFor(items, f.userID).ID, f.owner, false, &DecisionRequest{Decision: models.AccessReviewDecisionKeep})
+	require.NoError(t, err)
+
+	result, err := f.service.Close(ctx, f.tenantID, campaign.ID, f.auditor)
+	require.NoError(t, err)
+	assert.Equal(t, models.AccessReviewStatusClosed, result.Campaign.Status)
+	require.Len(t, result.Revoked, 1)
+	assert.Equal(t, f.owner, *result.Revoked[0].UserID)
+	assert.NotNil(t, result.Revoked[0].AppliedAt)
+	assert.Equal(t, [][2]uuid.UUID{{f.owner, f.roleID}}, f.roles.removed)
+
+	_, err = f.service.Close(ctx, f.tenantID, campaign.ID, f.auditor)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not active")
+}
+
+func TestService_Close_DeactivatesClients(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	client := &interfaces.OAuthClient{ID: uuid.New(), TenantID: f.tenantID, Name: "billing-sync", Scopes: []string{"users:read"}, IsActive: true, CreatedBy: &f.owner}
+	f.clients.clients[client.ID] = client
+
+	campaign, items, err := f.service.Create(ctx, &CreateCampaignRequest{
+		TenantID: f.tenantID, Name: "clients", ScopeType: models.AccessReviewScopeOAuthClient, ReviewerType: models.AccessReviewerRoleOwner,
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, f.owner, *items[0].ReviewerID)
+
+	_, err = f.service.Decide(ctx, f.tenantID, items[0].ID, f.owner, false, &DecisionRequest{Decision: models.AccessReviewDecisionRevoke})
+	require.NoError(t, err)
+	_, err = f.service.Close(ctx, f.tenantID, campaign.ID, f.auditor)
+	require.NoError(t, err)
+	assert.False(t, f.clients.clients[client.ID].IsActive)
+}
+
+func TestService_ExportReport(t *testing.T) {
+	f := newFixture()
+	campaign, _ := f.create(t, models.AccessReviewerRoleOwner, "")
+
+	report, err := f.service.ExportReport(context.Background(), f.tenantID, campaign.ID, ReportFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", report.ContentType)
+	assert.Equal(t, 3, strings.Count(string(report.Body), "\n"))
+	assert.Len(t, report.SHA256, 64)
+	assert.Equal(t, "signed", report.Signature)
+	assert.Equal(t, ReportSignatureType, f.signer.typ)
+	assert.Equal(t, report.Body, f.signer.payload)
+	assert.Equal(t, campaign.ID.String(), f.signer.headers["campaign_id"])
+
+	_, err = f.service.ExportReport(context.Background(), f.tenantID, campaign.ID, "xml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported report format")
+}
+
+func TestService_RemindDue(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	f.create(t, models.AccessReviewerRoleOwner, "")
+
+	count, err := f.service.RemindDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, map[string]int{"owner@example.com": 1, "auditor@example.com": 1}, f.emails.reminders)
+
+	// Reminded within the interval
+	count, err = f.service.RemindDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestReviewerRouter_RoundRobin(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	router := &reviewerRouter{campaign: &models.AccessReviewCampaign{
+		ReviewerType:        models.AccessReviewerUsers,
+		FallbackReviewerIDs: []uuid.UUID{a, b},
+	}}
+
+	assert.Equal(t, a, *router.reviewer(nil, nil))
+	assert.Equal(t, b, *router.reviewer(nil, nil))
+	assert.Equal(t, b, *router.reviewer(nil, &a), "skips the subject")
+	assert.Nil(t, (&reviewerRouter{campaign: &models.AccessReviewCampaign{}}).reviewer(nil, nil))
+}
@@ -0,0 +1,75 @@
+package account
+
+import (
+	"fmt"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// Profile fields that a tenant admin can open up for self-service editing
+const (
+	ProfileFieldUsername  = "username"
+	ProfileFieldEmail     = "email"
+	ProfileFieldFirstName = "first_name"
+	ProfileFieldLastName  = "last_name"
+)
+
+// DefaultEditableFields are editable when a tenant has not configured self-service fields
+var DefaultEditableFields = []string{ProfileFieldFirstName, ProfileFieldLastName}
+
+// supportedProfileFields lists every field that may appear in tenant settings
+var supportedProfileFields = map[string]bool{
+	ProfileFieldUsername:  true,
+	ProfileFieldEmail:     true,
+	ProfileFieldFirstName: true,
+	ProfileFieldLastName:  true,
+}
+
+// ValidateEditableFields checks that a tenant's self-service field list only names supported fields
+func ValidateEditableFields(fields []string) error {
+	for _, field := range fields {
+		if !supportedProfileFields[field] {
+			return fmt.Errorf("unsupported self-service profile field: %s", field)
+		}
+	}
+	return nil
+}
+
+// Profile is the logged-in user's view of their own account
+type Profile struct {
+	User           *models.User `json:"user"`
+	EditableFields []string     `json:"editable_fields"`
+}
+
+// UpdateProfileRequest represents a self-service profile update.
+// Only fields allowed by the tenant's settings may be set.
+type UpdateProfileRequest struct {
+	Username  *string `json:"username,omitempty"`
+	Email     *string `json:"email,omitempty"`
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+}
+
+// ChangePasswordRequest represents a self-service password change
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// requestedFields returns the names of the fields set on the request
+func (r *UpdateProfileRequest) requestedFields() []string {
+	var fields []string
+	if r.Username != nil {
+		fields = append(fields, ProfileFieldUsername)
+	}
+	if r.Email != nil {
+		fields = append(fields, ProfileFieldEmail)
+	}
+	if r.FirstName != nil {
+		fields = append(fields, ProfileFieldFirstName)
+	}
+	if r.LastName != nil {
+		fields = append(fields, ProfileFieldLastName)
+	}
+	return fields
+}
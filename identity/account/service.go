@@ -0,0 +1,206 @@
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arauth-identity/iam/auth/mfa"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/user"
+	"github.com/arauth-identity/iam/security/password"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// Service provides self-service account management for the logged-in user
+type Service struct {
+	userService        user.ServiceInterface
+	userRepo           interfaces.UserRepository
+	credentialRepo     interfaces.CredentialRepository
+	tenantSettingsRepo interfaces.TenantSettingsRepository
+	mfaService         mfa.ServiceInterface
+	passwordHasher     *password.Hasher
+}
+
+// NewService creates a new account service
+func NewService(
+	userService user.ServiceInterface,
+	userRepo interfaces.UserRepository,
+	credentialRepo interfaces.CredentialRepository,
+	tenantSettingsRepo interfaces.TenantSettingsRepository,
+	mfaService mfa.ServiceInterface,
+) *Service {
+	return &Service{
+		userService:        userService,
+		userRepo:           userRepo,
+		credentialRepo:     credentialRepo,
+		tenantSettingsRepo: tenantSettingsRepo,
+		mfaService:         mfaService,
+		passwordHasher:     password.NewHasher(),
+	}
+}
+
+// GetProfile returns the user's profile and the fields they may edit
+func (s *Service) GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	return &Profile{
+		User:           u,
+		EditableFields: s.editableFields(ctx, u),
+	}, nil
+}
+
+// UpdateProfile updates the user's own profile. Fields the tenant has not opened up
+// for self-service are rejected rather than silently ignored.
+func (s *Service) UpdateProfile(ctx context.Context, userID uuid.UUID, req *UpdateProfileRequest) (*models.User, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	allowed := make(map[string]bool)
+	for _, field := range s.editableFields(ctx, u) {
+		allowed[field] = true
+	}
+	for _, field := range req.requestedFields() {
+		if !allowed[field] {
+			return nil, fmt.Errorf("field %s is not editable", field)
+		}
+	}
+
+	return s.userService.Update(ctx, userID, &user.UpdateUserRequest{
+		Username:  req.Username,
+		Email:     req.Email,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	})
+}
+
+// ChangePassword verifies the current password and sets a new one under the tenant's
+// password policy. All sessions are revoked by the underlying user service.
+func (s *Service) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	cred, err := s.credentialRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("credentials not found: %w", err)
+	}
+	if cred.IsLocked() {
+		return fmt.Errorf("account is locked")
+	}
+
+	valid, err := s.passwordHasher.Verify(currentPassword, cred.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("failed to verify password: %w", err)
+	}
+	if !valid {
+		// Count wrong current passwords towards lockout, same as login
+		cred.IncrementFailedAttempts()
+		if err := s.credentialRepo.Update(ctx, cred); err != nil {
+			return fmt.Errorf("failed to update credentials: %w", err)
+		}
+		return fmt.Errorf("current password is incorrect")
+	}
+
+	if currentPassword == newPassword {
+		return fmt.Errorf("new password must be different from the current password")
+	}
+
+	return s.userService.ChangePasswordWithPolicy(ctx, userID, newPassword, s.passwordPolicy(ctx, u))
+}
+
+// DisableMFA turns off MFA after verifying a current TOTP or recovery code.
+// Users in tenants that require MFA cannot opt out.
+func (s *Service) DisableMFA(ctx context.Context, req *mfa.VerifyRequest) error {
+	u, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if settings := s.tenantSettings(ctx, u); settings != nil && settings.MFARequired {
+		return fmt.Errorf("MFA is required by tenant policy and cannot be disabled")
+	}
+
+	return s.mfaService.Disable(ctx, req)
+}
+
+// RemoveMFAFactor removes one MFA factor after a step-up check.
+// In tenants that require MFA the last verified factor cannot be removed.
+func (s *Service) RemoveMFAFactor(ctx context.Context, req *mfa.RemoveFactorRequest) error {
+	u, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if settings := s.tenantSettings(ctx, u); settings != nil && settings.MFARequired {
+		status, err := s.mfaService.GetStatus(ctx, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		verified := 0
+		removingVerified := false
+		for _, f := range status.Factors {
+			if f.Verified {
+				verified++
+				if f.ID == req.FactorID {
+					removingVerified = true
+				}
+			}
+		}
+		if removingVerified && verified == 1 {
+			return fmt.Errorf("MFA is required by tenant policy; enroll another factor before removing this one")
+		}
+	}
+
+	return s.mfaService.RemoveFactor(ctx, req)
+}
+
+// RegenerateRecoveryCodes replaces the user's MFA recovery codes after verifying a current code
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, req *mfa.VerifyRequest) ([]string, error) {
+	return s.mfaService.RegenerateRecoveryCodes(ctx, req)
+}
+
+// tenantSettings returns the settings for the user's tenant, or nil for SYSTEM users
+// and tenants without settings
+func (s *Service) tenantSettings(ctx context.Context, u *models.User) *interfaces.TenantSettings {
+	if u.TenantID == nil || s.tenantSettingsRepo == nil {
+		return nil
+	}
+	settings, err := s.tenantSettingsRepo.GetByTenantID(ctx, *u.TenantID)
+	if err != nil {
+		return nil
+	}
+	return settings
+}
+
+// editableFields returns the profile fields the user may change themselves
+func (s *Service) editableFields(ctx context.Context, u *models.User) []string {
+	settings := s.tenantSettings(ctx, u)
+	if settings == nil || settings.SelfServiceProfileFields == nil {
+		return DefaultEditableFields
+	}
+	return settings.SelfServiceProfileFields
+}
+
+// passwordPolicy builds the tenant's password validator. A nil result makes the user
+// service fall back to its default policy.
+func (s *Service) passwordPolicy(ctx context.Context, u *models.User) *password.Validator {
+	settings := s.tenantSettings(ctx, u)
+	if settings == nil || settings.MinPasswordLength == 0 {
+		return nil
+	}
+	return password.NewValidator(
+		settings.MinPasswordLength,
+		settings.RequireUppercase,
+		settings.RequireLowercase,
+		settings.RequireNumbers,
+		settings.RequireSpecialChars,
+	)
+}
@@ -0,0 +1,30 @@
+package account
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/auth/mfa"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines self-service operations a user performs on their own account
+type ServiceInterface interface {
+	// GetProfile returns the user's profile and the fields they may edit
+	GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error)
+
+	// UpdateProfile updates the user's own profile, restricted to tenant-editable fields
+	UpdateProfile(ctx context.Context, userID uuid.UUID, req *UpdateProfileRequest) (*models.User, error)
+
+	// ChangePassword verifies the current password and sets a new one under the tenant policy
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
+
+	// DisableMFA turns off MFA after verifying a current TOTP or recovery code
+	DisableMFA(ctx context.Context, req *mfa.VerifyRequest) error
+
+	// RemoveMFAFactor removes one MFA factor after verifying a code from another verified factor
+	RemoveMFAFactor(ctx context.Context, req *mfa.RemoveFactorRequest) error
+
+	// RegenerateRecoveryCodes replaces the user's MFA recovery codes after verifying a current code
+	RegenerateRecoveryCodes(ctx context.Context, req *mfa.VerifyRequest) ([]string, error)
+}
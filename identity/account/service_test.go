@@ -0,0 +1,278 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/arauth-identity/iam/auth/mfa"
+	"github.com/arauth-identity/iam/identity/credential"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/user"
+	"github.com/arauth-identity/iam/security/password"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubUserRepository serves a fixed set of users; other methods are not used by the service
+type stubUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+// stubTenantSettingsRepository serves fixed tenant settings
+type stubTenantSettingsRepository struct {
+	interfaces.TenantSettingsRepository
+	settings map[uuid.UUID]*interfaces.TenantSettings
+}
+
+func (r *stubTenantSettingsRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID) (*interfaces.TenantSettings, error) {
+	if s, ok := r.settings[tenantID]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("tenant settings not found")
+}
+
+// fakeCredentialRepository keeps credentials in memory
+type fakeCredentialRepository struct {
+	creds map[uuid.UUID]*credential.Credential
+}
+
+func (r *fakeCredentialRepository) Create(ctx context.Context, cred *credential.Credential) error {
+	r.creds[cred.UserID] = cred
+	return nil
+}
+
+func (r *fakeCredentialRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*credential.Credential, error) {
+	if c, ok := r.creds[userID]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("credentials not found")
+}
+
+func (r *fakeCredentialRepository) Update(ctx context.Context, cred *credential.Credential) error {
+	r.creds[cred.UserID] = cred
+	return nil
+}
+
+func (r *fakeCredentialRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	delete(r.creds, userID)
+	return nil
+}
+
+// MockUserService mocks the user service methods the account service delegates to
+type MockUserService struct {
+	user.ServiceInterface
+	mock.Mock
+}
+
+func (m *MockUserService) Update(ctx context.Context, id uuid.UUID, req *user.UpdateUserRequest) (*models.User, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) ChangePasswordWithPolicy(ctx context.Context, userID uuid.UUID, newPassword string, policy *password.Validator) error {
+	args := m.Called(ctx, userID, newPassword, policy)
+	return args.Error(0)
+}
+
+// MockMFAService mocks the MFA service methods the account service delegates to
+type MockMFAService struct {
+	mfa.ServiceInterface
+	mock.Mock
+}
+
+func (m *MockMFAService) Disable(ctx context.Context, req *mfa.VerifyRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) GetStatus(ctx context.Context, userID uuid.UUID) (*mfa.Status, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mfa.Status), args.Error(1)
+}
+
+func (m *MockMFAService) RemoveFactor(ctx context.Context, req *mfa.RemoveFactorRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+type testFixture struct {
+	service     *Service
+	userService *MockUserService
+	mfaService  *MockMFAService
+	creds       *fakeCredentialRepository
+	settings    *interfaces.TenantSettings
+	user        *models.User
+}
+
+func newTestFixture(t *testing.T, currentPassword string) *testFixture {
+	t.Helper()
+
+	tenantID := uuid.New()
+	u := &models.User{ID: uuid.New(), TenantID: &tenantID, Email: "user@example.com"}
+
+	hash, err := password.NewHasher().Hash(currentPassword)
+	require.NoError(t, err)
+
+	settings := &interfaces.TenantSettings{TenantID: tenantID}
+	creds := &fakeCredentialRepository{creds: map[uuid.UUID]*credential.Credential{
+		u.ID: {UserID: u.ID, PasswordHash: hash},
+	}}
+	userService := new(MockUserService)
+	mfaService := new(MockMFAService)
+
+	service := NewService(
+		userService,
+		&stubUserRepository{users: map[uuid.UUID]*models.User{u.ID: u}},
+		creds,
+		&stubTenantSettingsRepository{settings: map[uuid.UUID]*interfaces.TenantSettings{tenantID: settings}},
+		mfaService,
+	)
+
+	return &testFixture{
+		service:     service,
+		userService: userService,
+		mfaService:  mfaService,
+		creds:       creds,
+		settings:    settings,
+		user:        u,
+	}
+}
+
+func TestService_GetProfile_DefaultEditableFields(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+
+	profile, err := f.service.GetProfile(context.Background(), f.user.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, f.user, profile.User)
+	assert.Equal(t, DefaultEditableFields, profile.EditableFields)
+}
+
+func TestService_UpdateProfile_RejectsNonEditableField(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+	email := "new@example.com"
+
+	_, err := f.service.UpdateProfile(context.Background(), f.user.ID, &UpdateProfileRequest{Email: &email})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "field email is not editable")
+	f.userService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_UpdateProfile_TenantOpensField(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+	f.settings.SelfServiceProfileFields = []string{ProfileFieldEmail}
+	email := "new@example.com"
+	updated := &models.User{ID: f.user.ID, Email: email}
+
+	f.userService.On("Update", mock.Anything, f.user.ID, mock.MatchedBy(func(req *user.UpdateUserRequest) bool {
+		return req.Email != nil && *req.Email == email && req.Status == nil
+	})).Return(updated, nil)
+
+	result, err := f.service.UpdateProfile(context.Background(), f.user.ID, &UpdateProfileRequest{Email: &email})
+
+	require.NoError(t, err)
+	assert.Equal(t, updated, result)
+	f.userService.AssertExpectations(t)
+}
+
+func TestService_ChangePassword_UsesTenantPolicy(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+	f.settings.MinPasswordLength = 16
+
+	f.userService.On("ChangePasswordWithPolicy", mock.Anything, f.user.ID, "NewPassword123!", mock.MatchedBy(func(v *password.Validator) bool {
+		return v != nil && v.Validate("Short1!aaaaaaa", f.user.Email) != nil
+	})).Return(nil)
+
+	err := f.service.ChangePassword(context.Background(), f.user.ID, "CurrentPassword1!", "NewPassword123!")
+
+	require.NoError(t, err)
+	f.userService.AssertExpectations(t)
+}
+
+func TestService_ChangePassword_WrongCurrentPassword(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+
+	err := f.service.ChangePassword(context.Background(), f.user.ID, "WrongPassword1!", "NewPassword123!")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "current password is incorrect")
+	assert.Equal(t, 1, f.creds.creds[f.user.ID].FailedLoginAttempts)
+	f.userService.AssertNotCalled(t, "ChangePasswordWithPolicy", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_DisableMFA_RequiredByTenant(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+	f.settings.MFARequired = true
+
+	err := f.service.DisableMFA(context.Background(), &mfa.VerifyRequest{UserID: f.user.ID, TOTPCode: "123456"})
+
+	assert.Error(t, err)
+	f.mfaService.AssertNotCalled(t, "Disable", mock.Anything, mock.Anything)
+}
+
+func TestService_DisableMFA(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+	req := &mfa.VerifyRequest{UserID: f.user.ID, TOTPCode: "123456"}
+	f.mfaService.On("Disable", mock.Anything, req).Return(nil)
+
+	err := f.service.DisableMFA(context.Background(), req)
+
+	require.NoError(t, err)
+	f.mfaService.AssertExpectations(t)
+}
+
+func TestService_RemoveMFAFactor_LastFactorRequiredByTenant(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+	f.settings.MFARequired = true
+	factorID := uuid.New()
+	f.mfaService.On("GetStatus", mock.Anything, f.user.ID).Return(&mfa.Status{
+		Enabled: true,
+		Factors: []*mfa.Factor{{ID: factorID, Verified: true}},
+	}, nil)
+
+	err := f.service.RemoveMFAFactor(context.Background(), &mfa.RemoveFactorRequest{
+		UserID:   f.user.ID,
+		FactorID: factorID,
+		TOTPCode: "123456",
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "enroll another factor")
+	f.mfaService.AssertNotCalled(t, "RemoveFactor", mock.Anything, mock.Anything)
+}
+
+func TestService_RemoveMFAFactor_OtherFactorRemains(t *testing.T) {
+	f := newTestFixture(t, "CurrentPassword1!")
+	f.settings.MFARequired = true
+	factorID := uuid.New()
+	f.mfaService.On("GetStatus", mock.Anything, f.user.ID).Return(&mfa.Status{
+		Enabled: true,
+		Factors: []*mfa.Factor{{ID: factorID, Verified: true}, {ID: uuid.New(), Verified: true}},
+	}, nil)
+	req := &mfa.RemoveFactorRequest{UserID: f.user.ID, FactorID: factorID, TOTPCode: "123456"}
+	f.mfaService.On("RemoveFactor", mock.Anything, req).Return(nil)
+
+	err := f.service.RemoveMFAFactor(context.Background(), req)
+
+	require.NoError(t, err)
+	f.mfaService.AssertExpectations(t)
+}
@@ -0,0 +1,258 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/auditchain"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryAuditRepository chains events the way the Postgres repository does
+type memoryAuditRepository struct {
+	mu          sync.Mutex
+	events      []*models.AuditEvent
+	chains      map[uuid.UUID]*models.AuditChain
+	checkpoints []*models.AuditCheckpoint
+}
+
+func newMemoryAuditRepository() *memoryAuditRepository {
+	return &memoryAuditRepository{chains: map[uuid.UUID]*models.AuditChain{}}
+}
+
+func (r *memoryAuditRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.Flatten()
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now()
+	auditchain.Normalize(event)
+
+	chainID := models.AuditChainID(event.TenantID)
+	head, ok := r.chains[chainID]
+	if !ok {
+		head = &models.AuditChain{ChainID: chainID, TenantID: event.TenantID, Hash: auditchain.GenesisHash, CreatedAt: time.Now()}
+		r.chains[chainID] = head
+	}
+	if err := auditchain.Link(event, head.Seq+1, head.Hash); err != nil {
+		return err
+	}
+	head.Seq, head.Hash = event.Seq, event.Hash
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memoryAuditRepository) QueryEvents(ctx context.Context, filters *interfaces.AuditEventFilters) ([]*models.AuditEvent, int, error) {
+	filters.Validate()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*models.AuditEvent
+	for _, event := range r.events {
+		if filters.TenantID != nil && (event.TenantID == nil || *event.TenantID != *filters.TenantID) {
+			continue
+		}
+		if filters.EventType != nil && event.EventType != *filters.EventType {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, len(events), nil
+}
+
+func (r *memoryAuditRepository) GetEvent(ctx context.Context, eventID uuid.UUID) (*models.AuditEvent, error) {
+	return nil, fmt.Errorf("audit event not found")
+}
+
+func (r *memoryAuditRepository) ListChain(ctx context.Context, tenantID *uuid.UUID, afterSeq int64, limit int) ([]*models.AuditEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*models.AuditEvent
+	for _, event := range r.events {
+		if models.AuditChainID(event.TenantID) == models.AuditChainID(tenantID) && event.Seq > afterSeq {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r *memoryAuditRepository) CountUnchained(ctx context.Context, tenantID *uuid.UUID, since time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryAuditRepository) GetChain(ctx context.Context, tenantID *uuid.UUID) (*models.AuditChain, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	chain, ok := r.chains[models.AuditChainID(tenantID)]
+	if !ok {
+		return nil, fmt.Errorf("audit chain not found")
+	}
+	copied := *chain
+	return &copied, nil
+}
+
+func (r *memoryAuditRepository) ListChains(ctx context.Context) ([]*models.AuditChain, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var chains []*models.AuditChain
+	for _, chain := range r.chains {
+		copied := *chain
+		chains = append(chains, &copied)
+	}
+	return chains, nil
+}
+
+func (r *memoryAuditRepository) CreateCheckpoint(ctx context.Context, checkpoint *models.AuditCheckpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkpoints = append(r.checkpoints, checkpoint)
+	r.chains[checkpoint.ChainID].CheckpointSeq = checkpoint.Seq
+	return nil
+}
+
+func (r *memoryAuditRepository) ListCheckpoints(ctx context.Context, tenantID *uuid.UUID, fromSeq int64, limit int) ([]*models.AuditCheckpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var checkpoints []*models.AuditCheckpoint
+	for _, cp := range r.checkpoints {
+		if cp.ChainID == models.AuditChainID(tenantID) && cp.Seq >= fromSeq {
+			checkpoints = append(checkpoints, cp)
+		}
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].Seq < checkpoints[j].Seq })
+	if len(checkpoints) > limit {
+		checkpoints = checkpoints[:limit]
+	}
+	return checkpoints, nil
+}
+
+func logEvents(t *testing.T, service ServiceInterface, tenantID *uuid.UUID, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := service.LogEvent(context.Background(), &models.AuditEvent{
+			EventType: models.EventTypeUserUpdated,
+			Actor:     models.AuditActor{UserID: uuid.New(), Username: "admin", PrincipalType: "TENANT"},
+			Target:    &models.AuditTarget{Type: "user", ID: uuid.New(), Identifier: fmt.Sprintf("user-%d", i)},
+			TenantID:  tenantID,
+			Timestamp: time.Now(),
+			Metadata:  map[string]interface{}{"attempt": i},
+			Result:    models.ResultSuccess,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestChainService_CheckpointAndVerify(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	repo := newMemoryAuditRepository()
+	service := NewService(repo)
+	chainService := NewChainService(repo, key, zap.NewNop())
+	tenantID := uuid.New()
+
+	logEvents(t, service, &tenantID, 4)
+	logEvents(t, service, nil, 2)
+
+	signed, err := chainService.Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, signed)
+
+	// Nothing new to sign
+	signed, err = chainService.Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, signed)
+
+	reports, err := chainService.VerifyAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	for _, report := range reports {
+		assert.True(t, report.Valid, "%+v", report.Problems)
+		assert.Equal(t, 1, report.CheckpointsVerified)
+	}
+
+	// A database user edits an event
+	repo.events[1].Result = models.ResultDenied
+
+	report, err := chainService.Verify(ctx, &tenantID)
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	require.Len(t, report.Problems, 1)
+	assert.Equal(t, auditchain.ProblemModified, report.Problems[0].Kind)
+	assert.Equal(t, int64(2), report.Problems[0].Seq)
+
+	report, err = chainService.Verify(ctx, nil)
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+}
+
+func TestChainService_CheckpointWithoutKey(t *testing.T) {
+	chainService := NewChainService(newMemoryAuditRepository(), nil, zap.NewNop())
+	_, err := chainService.Checkpoint(context.Background())
+	assert.Error(t, err)
+}
+
+func TestService_ExportEventsJSON(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	repo := newMemoryAuditRepository()
+	service := NewService(repo)
+	tenantID := uuid.New()
+	logEvents(t, service, &tenantID, 3)
+	_, err = NewChainService(repo, key, zap.NewNop()).Checkpoint(ctx)
+	require.NoError(t, err)
+
+	data, filename, err := service.ExportEvents(ctx, &interfaces.AuditEventFilters{TenantID: &tenantID}, ExportFormatJSON)
+	require.NoError(t, err)
+	assert.Contains(t, filename, ".json")
+
+	var export Export
+	require.NoError(t, json.Unmarshal(data, &export))
+	assert.True(t, export.Complete)
+	require.Len(t, export.Events, 3)
+	require.Len(t, export.Checkpoints, 1)
+
+	// The export verifies on its own against the signing key
+	v := auditchain.NewVerifier(&tenantID, export.Checkpoints, auditchain.NewKeySet(&key.PublicKey), false)
+	for _, event := range export.Events {
+		v.Add(event)
+	}
+	report := v.Finish(nil)
+	assert.True(t, report.Valid, "%+v", report.Problems)
+	assert.Equal(t, 1, report.CheckpointsVerified)
+
+	eventType := models.EventTypeUserUpdated
+	data, _, err = service.ExportEvents(ctx, &interfaces.AuditEventFilters{TenantID: &tenantID, EventType: &eventType}, ExportFormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &export))
+	assert.False(t, export.Complete)
+
+	data, _, err = service.ExportEvents(ctx, &interfaces.AuditEventFilters{TenantID: &tenantID}, ExportFormatCSV)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Sequence,Previous Hash,Hash")
+	assert.Contains(t, string(data), export.Events[0].Hash)
+
+	_, _, err = service.ExportEvents(ctx, &interfaces.AuditEventFilters{TenantID: &tenantID}, "xml")
+	assert.Error(t, err)
+}
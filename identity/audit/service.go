@@ -6,25 +6,24 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/arauth-identity/iam/identity/models"
-	"github.com/arauth-identity/iam/identity/webhook"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/google/uuid"
 )
 
 // Service provides audit event logging functionality
 type Service struct {
-	repo           interfaces.AuditEventRepository
-	webhookService webhook.ServiceInterface
+	repo interfaces.AuditEventRepository
 }
 
-// NewService creates a new audit service
-func NewService(repo interfaces.AuditEventRepository, webhookService webhook.ServiceInterface) ServiceInterface {
+// NewService creates a new audit service. Webhooks and other consumers
+// receive stored events through the outbox relay.
+func NewService(repo interfaces.AuditEventRepository) ServiceInterface {
 	return &Service{
-		repo:           repo,
-		webhookService: webhookService,
+		repo: repo,
 	}
 }
 
@@ -34,61 +33,9 @@ func (s *Service) LogEvent(ctx context.Context, event *models.AuditEvent) error
 		return fmt.Errorf("invalid audit event: %w", err)
 	}
 
-	// Save audit event
-	if err := s.repo.Create(ctx, event); err != nil {
-		return err
-	}
-
-	// Trigger webhooks asynchronously (don't block on webhook delivery)
-	go s.triggerWebhooks(context.Background(), event)
-
-	return nil
-}
-
-// triggerWebhooks triggers webhooks for an audit event
-func (s *Service) triggerWebhooks(ctx context.Context, event *models.AuditEvent) {
-	if s.webhookService == nil {
-		return
-	}
-
-	// Convert audit event to webhook payload
-	payload := s.eventToPayload(event)
-
-	// Get tenant ID (may be nil for system events)
-	var tenantID uuid.UUID
-	if event.TenantID != nil {
-		tenantID = *event.TenantID
-	} else {
-		// System events don't trigger tenant webhooks
-		return
-	}
-
-	// Trigger webhook (async, errors are logged by webhook service)
-	_ = s.webhookService.TriggerWebhook(ctx, tenantID, event.EventType, payload, &event.ID)
-}
-
-// eventToPayload converts an audit event to a webhook payload map
-func (s *Service) eventToPayload(event *models.AuditEvent) map[string]interface{} {
-	// Marshal event to JSON and unmarshal to map to get all fields
-	eventJSON, err := json.Marshal(event)
-	if err != nil {
-		return map[string]interface{}{
-			"event_type": event.EventType,
-			"timestamp":  event.Timestamp,
-			"error":      "failed to serialize event",
-		}
-	}
-
-	var payload map[string]interface{}
-	if err := json.Unmarshal(eventJSON, &payload); err != nil {
-		return map[string]interface{}{
-			"event_type": event.EventType,
-			"timestamp":  event.Timestamp,
-			"error":      "failed to deserialize event",
-		}
-	}
-
-	return payload
+	// The event is queued for webhooks and other consumers in the same
+	// transaction, so it is delivered even if the process stops now
+	return s.repo.Create(ctx, event)
 }
 
 // QueryEvents queries audit events with filters
@@ -117,8 +64,26 @@ func (s *Service) GetEvent(ctx context.Context, eventID uuid.UUID) (*models.Audi
 	return event, nil
 }
 
-// ExportEvents exports audit events as CSV based on filters
-func (s *Service) ExportEvents(ctx context.Context, filters *interfaces.AuditEventFilters) ([]byte, string, error) {
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// Export is the JSON audit export. Events carry their place in their
+// tenant's hash chain, and the checkpoints signed over the exported range
+// let the export be verified offline with cmd/auditverify.
+type Export struct {
+	ExportedAt time.Time `json:"exported_at"`
+	// Complete is set when the export holds every event of its chains, so
+	// missing events can be told apart from filtered ones
+	Complete    bool                      `json:"complete"`
+	Events      []*models.AuditEvent      `json:"events"`
+	Checkpoints []*models.AuditCheckpoint `json:"checkpoints"`
+}
+
+// ExportEvents exports audit events as CSV or JSON based on filters
+func (s *Service) ExportEvents(ctx context.Context, filters *interfaces.AuditEventFilters, format string) ([]byte, string, error) {
 	// Query events (reuse existing query logic)
 	// For export, we might want to increase the limit, but for now we respect the filters
 	// If filters.PageSize is 0, QueryEvents might use default.
@@ -127,16 +92,29 @@ func (s *Service) ExportEvents(ctx context.Context, filters *interfaces.AuditEve
 	// For a V1 MVP, we will fetch up to MaxPageSize (100) or whatever is requested.
 	// To support full export, we would need pagination loop, but let's start simple as per requirement.
 
-	events, _, err := s.repo.QueryEvents(ctx, filters)
+	events, total, err := s.repo.QueryEvents(ctx, filters)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to query audit events for export: %w", err)
 	}
 
+	switch format {
+	case "", ExportFormatCSV:
+		return exportCSV(events)
+	case ExportFormatJSON:
+		return s.exportJSON(ctx, filters, events, total)
+	default:
+		return nil, "", fmt.Errorf("invalid export format: %s", format)
+	}
+}
+
+// exportCSV writes events as CSV, with each event's chain hashes
+func exportCSV(events []*models.AuditEvent) ([]byte, string, error) {
 	b := &bytes.Buffer{}
 	w := csv.NewWriter(b)
 
 	// Write Header
-	header := []string{"Event ID", "Timestamp", "Event Type", "Actor", "Result", "IP Address", "Target Type", "Target ID"}
+	header := []string{"Event ID", "Timestamp", "Event Type", "Actor", "Result", "IP Address", "Target Type", "Target ID",
+		"Sequence", "Previous Hash", "Hash"}
 	if err := w.Write(header); err != nil {
 		return nil, "", fmt.Errorf("failed to write CSV header: %w", err)
 	}
@@ -153,6 +131,7 @@ func (s *Service) ExportEvents(ctx context.Context, filters *interfaces.AuditEve
 			event.Result,
 			event.SourceIP,
 			"", "",
+			"", event.PrevHash, event.Hash,
 		}
 
 		if event.Target != nil {
@@ -161,6 +140,9 @@ func (s *Service) ExportEvents(ctx context.Context, filters *interfaces.AuditEve
 				record[7] = event.Target.ID.String()
 			}
 		}
+		if event.Seq > 0 {
+			record[8] = strconv.FormatInt(event.Seq, 10)
+		}
 
 		if err := w.Write(record); err != nil {
 			return nil, "", fmt.Errorf("failed to write CSV record: %w", err)
@@ -176,6 +158,73 @@ func (s *Service) ExportEvents(ctx context.Context, filters *interfaces.AuditEve
 	return b.Bytes(), filename, nil
 }
 
+// exportJSON writes events as an Export with the checkpoints covering them
+func (s *Service) exportJSON(ctx context.Context, filters *interfaces.AuditEventFilters, events []*models.AuditEvent, total int) ([]byte, string, error) {
+	export := &Export{
+		ExportedAt:  time.Now().UTC(),
+		Complete:    onlyTenantFilter(filters) && len(events) == total,
+		Events:      events,
+		Checkpoints: []*models.AuditCheckpoint{},
+	}
+	if export.Events == nil {
+		export.Events = []*models.AuditEvent{}
+	}
+
+	// The range each chain's exported events span
+	type seqRange struct {
+		tenantID *uuid.UUID
+		min, max int64
+	}
+	ranges := map[uuid.UUID]*seqRange{}
+	for _, event := range events {
+		event.Expand()
+		if event.Seq == 0 {
+			continue
+		}
+		chainID := models.AuditChainID(event.TenantID)
+		r, ok := ranges[chainID]
+		if !ok {
+			ranges[chainID] = &seqRange{tenantID: event.TenantID, min: event.Seq, max: event.Seq}
+			continue
+		}
+		if event.Seq < r.min {
+			r.min = event.Seq
+		}
+		if event.Seq > r.max {
+			r.max = event.Seq
+		}
+	}
+
+	// Include the checkpoints within each range and the first one after it
+	for _, r := range ranges {
+		checkpoints, err := s.repo.ListCheckpoints(ctx, r.tenantID, r.min, interfaces.MaxPageSize)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list audit checkpoints for export: %w", err)
+		}
+		for _, cp := range checkpoints {
+			export.Checkpoints = append(export.Checkpoints, cp)
+			if cp.Seq >= r.max {
+				break
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal audit export: %w", err)
+	}
+
+	filename := fmt.Sprintf("audit_export_%s.json", time.Now().Format("20060102_150405"))
+	return data, filename, nil
+}
+
+// onlyTenantFilter reports whether the filters select whole chains
+func onlyTenantFilter(filters *interfaces.AuditEventFilters) bool {
+	return filters.EventType == nil && filters.ActorUserID == nil && filters.TargetType == nil &&
+		filters.TargetID == nil && filters.Result == nil && filters.StartDate == nil &&
+		filters.EndDate == nil && filters.Page <= 1
+}
+
 // createEvent is a helper to create an audit event
 func (s *Service) createEvent(eventType string, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, sourceIP, userAgent string, result string, errorMsg string, metadata map[string]interface{}) *models.AuditEvent {
 	event := &models.AuditEvent{
@@ -314,8 +363,8 @@ func (s *Service) LogMFADisabled(ctx context.Context, actor models.AuditActor, t
 }
 
 // LogMFAReset logs an MFA reset event
-func (s *Service) LogMFAReset(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, sourceIP, userAgent string) error {
-	event := s.createEvent(models.EventTypeMFAReset, actor, target, tenantID, sourceIP, userAgent, models.ResultSuccess, "", nil)
+func (s *Service) LogMFAReset(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, sourceIP, userAgent string, metadata map[string]interface{}) error {
+	event := s.createEvent(models.EventTypeMFAReset, actor, target, tenantID, sourceIP, userAgent, models.ResultSuccess, "", metadata)
 	return s.LogEvent(ctx, event)
 }
 
@@ -19,8 +19,10 @@ type ServiceInterface interface {
 	// GetEvent retrieves a single audit event by ID
 	GetEvent(ctx context.Context, eventID uuid.UUID) (*models.AuditEvent, error)
 
-	// ExportEvents exports audit events as CSV based on filters
-	ExportEvents(ctx context.Context, filters *interfaces.AuditEventFilters) ([]byte, string, error)
+	// ExportEvents exports audit events as CSV or JSON based on filters.
+	// Both formats include each event's chain hashes; JSON also includes
+	// the signed checkpoints covering the exported events.
+	ExportEvents(ctx context.Context, filters *interfaces.AuditEventFilters, format string) ([]byte, string, error)
 
 	// Helper methods for common events
 	LogUserCreated(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, sourceIP, userAgent string, metadata map[string]interface{}) error
@@ -45,7 +47,7 @@ type ServiceInterface interface {
 	LogMFAChallengeCreated(ctx context.Context, actor models.AuditActor, tenantID *uuid.UUID, sourceIP, userAgent string) error
 	LogMFAVerified(ctx context.Context, actor models.AuditActor, tenantID *uuid.UUID, sourceIP, userAgent string, success bool) error
 	LogMFADisabled(ctx context.Context, actor models.AuditActor, tenantID *uuid.UUID, sourceIP, userAgent string) error
-	LogMFAReset(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, sourceIP, userAgent string) error
+	LogMFAReset(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, tenantID *uuid.UUID, sourceIP, userAgent string, metadata map[string]interface{}) error
 
 	LogTenantCreated(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, sourceIP, userAgent string) error
 	LogTenantUpdated(ctx context.Context, actor models.AuditActor, target *models.AuditTarget, sourceIP, userAgent string) error
@@ -0,0 +1,231 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/arauth-identity/iam/identity/permission"
+	"github.com/arauth-identity/iam/identity/policy"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// whatIfPageSize is how many users are loaded at a time during a what-if run
+const whatIfPageSize = 100
+
+// Explain evaluates a request like Check and returns the full trace: the roles
+// considered and why, the permissions they carry, the conditional policies
+// evaluated and the rule that decided the outcome. It always reads live
+// assignments, so it can differ from a Check answered from the grant cache
+// until that cache entry expires.
+func (s *Service) Explain(ctx context.Context, tenantID uuid.UUID, req *CheckRequest) (*Explanation, error) {
+	subject, denied, err := s.prepare(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	required := permission.Key(req.Resource, req.Action)
+	explanation := &Explanation{
+		RequiredPermission: required,
+		Roles:              []RoleTrace{},
+		Permissions:        []PermissionTrace{},
+	}
+	if denied != nil {
+		explanation.Decision = denied
+		explanation.DecidingRule = denied.Reason
+		return explanation, nil
+	}
+
+	roles, grants, err := s.newResolver(nil).resolve(ctx, tenantID, *subject.userID)
+	if err != nil {
+		return nil, err
+	}
+	explanation.Roles = roles
+	for _, g := range grants {
+		explanation.Permissions = append(explanation.Permissions, PermissionTrace{
+			Grant:   g,
+			Matches: permission.Matches(g.Permission, required),
+		})
+	}
+
+	decision, result, err := s.decide(ctx, tenantID, req, subject, grants)
+	if err != nil {
+		return nil, err
+	}
+	explanation.Decision = decision
+	explanation.Policies = result
+	explanation.DecidingRule = decidingRule(decision, result, required)
+
+	return explanation, nil
+}
+
+// decidingRule describes, in one line, what settled a decision
+func decidingRule(decision *Decision, result *policy.Result, required string) string {
+	if decision.MatchedRoleID == nil {
+		return fmt.Sprintf("no considered role grants %s", required)
+	}
+	if result != nil && !result.Allowed {
+		if result.Policy != nil {
+			return fmt.Sprintf("deny policy %q matched", result.Policy.Name)
+		}
+		return result.Reason
+	}
+
+	rule := fmt.Sprintf("role %q grants %s", decision.MatchedRole, decision.MatchedPermission)
+	if result != nil && result.Policy != nil {
+		rule += fmt.Sprintf(" and allow policy %q matched", result.Policy.Name)
+	}
+	return rule
+}
+
+// WhatIf computes, without applying anything, how the effective permissions of
+// every active user in the tenant would change under the requested role and
+// permission changes. Effective permissions are role grants; conditional
+// policies depend on each request and are not part of the comparison.
+func (s *Service) WhatIf(ctx context.Context, tenantID uuid.UUID, req *WhatIfRequest) (*WhatIfResult, error) {
+	sc, err := s.buildScenario(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	current, proposed := s.newResolver(nil), s.newResolver(sc)
+	result := &WhatIfResult{Changes: make([]*AccessChange, 0)}
+
+	for page := 1; ; page++ {
+		users, err := s.userRepo.List(ctx, tenantID, &interfaces.UserFilters{Page: page, PageSize: whatIfPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		for _, user := range users {
+			if !user.IsActive() {
+				continue
+			}
+			result.UsersEvaluated++
+
+			_, before, err := current.resolve(ctx, tenantID, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			_, after, err := proposed.resolve(ctx, tenantID, user.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			gained, lost := diffPermissions(before, after)
+			if len(gained) > 0 || len(lost) > 0 {
+				result.Changes = append(result.Changes, &AccessChange{
+					UserID:   user.ID,
+					Username: user.Username,
+					Gained:   gained,
+					Lost:     lost,
+				})
+			}
+		}
+
+		if len(users) < whatIfPageSize {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// buildScenario validates a what-if request against the tenant
+func (s *Service) buildScenario(ctx context.Context, tenantID uuid.UUID, req *WhatIfRequest) (*scenario, error) {
+	if req == nil {
+		return nil, fmt.Errorf("what-if request is required")
+	}
+	total := len(req.RemoveRoles) + len(req.AddPermissions) + len(req.RemovePermissions)
+	if total == 0 {
+		return nil, fmt.Errorf("at least one change is required")
+	}
+	if total > MaxWhatIfChanges {
+		return nil, fmt.Errorf("what-if may contain at most %d changes", MaxWhatIfChanges)
+	}
+
+	sc := &scenario{
+		removedRoles:       make(map[uuid.UUID]bool),
+		addedPermissions:   make(map[uuid.UUID][]string),
+		removedPermissions: make(map[uuid.UUID]map[string]bool),
+	}
+
+	for _, roleID := range req.RemoveRoles {
+		if err := s.checkTenantRole(ctx, tenantID, roleID); err != nil {
+			return nil, err
+		}
+		sc.removedRoles[roleID] = true
+	}
+	for _, change := range req.AddPermissions {
+		key, err := s.checkPermissionChange(ctx, tenantID, change)
+		if err != nil {
+			return nil, err
+		}
+		sc.addedPermissions[change.RoleID] = append(sc.addedPermissions[change.RoleID], key)
+	}
+	for _, change := range req.RemovePermissions {
+		key, err := s.checkPermissionChange(ctx, tenantID, change)
+		if err != nil {
+			return nil, err
+		}
+		if sc.removedPermissions[change.RoleID] == nil {
+			sc.removedPermissions[change.RoleID] = make(map[string]bool)
+		}
+		sc.removedPermissions[change.RoleID][key] = true
+	}
+
+	return sc, nil
+}
+
+// checkPermissionChange validates one permission change and returns its permission key
+func (s *Service) checkPermissionChange(ctx context.Context, tenantID uuid.UUID, change PermissionChange) (string, error) {
+	if err := s.checkTenantRole(ctx, tenantID, change.RoleID); err != nil {
+		return "", err
+	}
+	resource, action, ok := permission.Split(change.Permission)
+	if !ok {
+		return "", fmt.Errorf("invalid permission %q: expected resource:action", change.Permission)
+	}
+	if err := permission.ValidatePattern(resource, action); err != nil {
+		return "", fmt.Errorf("invalid permission %q: %w", change.Permission, err)
+	}
+	return permission.Key(resource, action), nil
+}
+
+// checkTenantRole verifies a role exists in the tenant
+func (s *Service) checkTenantRole(ctx context.Context, tenantID, roleID uuid.UUID) error {
+	r, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil || r.TenantID != tenantID || !r.IsActive() {
+		return fmt.Errorf("unknown role %s", roleID)
+	}
+	return nil
+}
+
+// diffPermissions returns the permission keys only in after and only in before, sorted
+func diffPermissions(before, after []Grant) (gained, lost []string) {
+	had := make(map[string]bool, len(before))
+	for _, g := range before {
+		had[g.Permission] = true
+	}
+	has := make(map[string]bool, len(after))
+	for _, g := range after {
+		has[g.Permission] = true
+	}
+
+	gained, lost = make([]string, 0), make([]string, 0)
+	for key := range has {
+		if !had[key] {
+			gained = append(gained, key)
+		}
+	}
+	for key := range had {
+		if !has[key] {
+			lost = append(lost, key)
+		}
+	}
+	sort.Strings(gained)
+	sort.Strings(lost)
+
+	return gained, lost
+}
@@ -0,0 +1,138 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/policy"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Explain_TracesRoles(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	team := &models.Group{ID: uuid.New(), TenantID: f.tenantID, Name: "team"}
+	reviewer := &models.Role{ID: uuid.New(), TenantID: f.tenantID, Name: "reviewer"}
+	publisher := &models.Role{ID: uuid.New(), TenantID: f.tenantID, Name: "publisher"}
+	f.groups.userGroups[f.user.ID] = []*models.Group{team}
+	f.groups.roles[team.ID] = []*models.Role{reviewer}
+	f.roles.parents[reviewer.ID] = []*models.Role{publisher}
+	f.service.permissionRepo.(*stubPermissionRepository).rolePermissions[publisher.ID] = []*models.Permission{
+		{Resource: "documents", Action: "publish"},
+	}
+
+	explanation, err := f.service.Explain(context.Background(), f.tenantID, f.check("documents", "publish"))
+
+	require.NoError(t, err)
+	assert.True(t, explanation.Decision.Allowed)
+	assert.Equal(t, "documents:publish", explanation.RequiredPermission)
+	assert.Equal(t, `role "publisher" grants documents:publish`, explanation.DecidingRule)
+
+	byName := make(map[string]RoleTrace)
+	for _, r := range explanation.Roles {
+		byName[r.RoleName] = r
+	}
+	require.Len(t, byName, 4)
+	assert.Equal(t, RoleSourceDirect, byName["editor"].Source)
+	assert.Equal(t, ExcludedOtherTenant, byName["foreign_admin"].Excluded)
+	assert.Equal(t, RoleSourceGroup, byName["reviewer"].Source)
+	assert.Equal(t, "team", byName["reviewer"].Via)
+	assert.Equal(t, RoleSourceInherited, byName["publisher"].Source)
+	assert.Equal(t, "reviewer", byName["publisher"].Via)
+
+	// The foreign role's permissions are never listed
+	matching := 0
+	for _, p := range explanation.Permissions {
+		assert.NotEqual(t, "documents:delete", p.Permission)
+		if p.Matches {
+			matching++
+		}
+	}
+	assert.Equal(t, 1, matching)
+}
+
+func TestService_Explain_Denials(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	f.addPolicy(models.PolicyEffectDeny, "documents", "update", `!ip_in(context.ip, "10.0.0.0/8")`)
+	ctx := context.Background()
+
+	explanation, err := f.service.Explain(ctx, f.tenantID, f.check("documents", "delete"))
+	require.NoError(t, err)
+	assert.False(t, explanation.Decision.Allowed)
+	assert.Equal(t, "no considered role grants documents:delete", explanation.DecidingRule)
+	assert.Nil(t, explanation.Policies)
+
+	req := f.check("documents", "update")
+	req.Context = &policy.RequestContext{IP: "203.0.113.7"}
+	explanation, err = f.service.Explain(ctx, f.tenantID, req)
+	require.NoError(t, err)
+	assert.False(t, explanation.Decision.Allowed)
+	assert.Equal(t, `deny policy "deny-documents-update" matched`, explanation.DecidingRule)
+	require.NotNil(t, explanation.Policies)
+	require.Len(t, explanation.Policies.Evaluations, 1)
+	assert.True(t, explanation.Policies.Evaluations[0].Matched)
+
+	f.user.Status = models.UserStatusSuspended
+	explanation, err = f.service.Explain(ctx, f.tenantID, f.check("documents", "read"))
+	require.NoError(t, err)
+	assert.Equal(t, ReasonSubjectInactive, explanation.DecidingRule)
+	assert.Empty(t, explanation.Roles)
+}
+
+func TestService_WhatIf(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	ctx := context.Background()
+
+	// A second user holding editor through a group, and one unaffected user
+	other := &models.User{ID: uuid.New(), TenantID: &f.tenantID, Username: "bob", Status: models.UserStatusActive}
+	bystander := &models.User{ID: uuid.New(), TenantID: &f.tenantID, Username: "carol", Status: models.UserStatusActive}
+	team := &models.Group{ID: uuid.New(), TenantID: f.tenantID, Name: "team"}
+	f.groups.userGroups[other.ID] = []*models.Group{team}
+	f.groups.roles[team.ID] = []*models.Role{f.role}
+	users := f.service.userRepo.(*stubUserRepository).users
+	users[other.ID] = other
+	users[bystander.ID] = bystander
+
+	result, err := f.service.WhatIf(ctx, f.tenantID, &WhatIfRequest{RemoveRoles: []uuid.UUID{f.role.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.UsersEvaluated)
+	require.Len(t, result.Changes, 2)
+	for _, change := range result.Changes {
+		assert.Equal(t, []string{"documents:read", "documents:update"}, change.Lost)
+		assert.Empty(t, change.Gained)
+	}
+
+	result, err = f.service.WhatIf(ctx, f.tenantID, &WhatIfRequest{
+		AddPermissions:    []PermissionChange{{RoleID: f.role.ID, Permission: "documents:*"}},
+		RemovePermissions: []PermissionChange{{RoleID: f.role.ID, Permission: "documents:update"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, []string{"documents:*"}, result.Changes[0].Gained)
+	assert.Equal(t, []string{"documents:update"}, result.Changes[0].Lost)
+
+	// Nothing was applied
+	decision, err := f.service.Check(ctx, f.tenantID, f.check("documents", "update"))
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestService_WhatIf_InvalidRequest(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	ctx := context.Background()
+
+	_, err := f.service.WhatIf(ctx, f.tenantID, &WhatIfRequest{})
+	assert.EqualError(t, err, "at least one change is required")
+
+	_, err = f.service.WhatIf(ctx, f.tenantID, &WhatIfRequest{RemoveRoles: []uuid.UUID{uuid.New()}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown role")
+
+	_, err = f.service.WhatIf(ctx, f.tenantID, &WhatIfRequest{
+		AddPermissions: []PermissionChange{{RoleID: f.role.ID, Permission: "documents"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid permission")
+}
@@ -0,0 +1,124 @@
+package authz
+
+import (
+	"github.com/arauth-identity/iam/identity/policy"
+	"github.com/google/uuid"
+)
+
+// MaxBatchSize bounds the number of checks in a single batch request
+const MaxBatchSize = 100
+
+// Subject identifies who an authorization check is for.
+// Exactly one of UserID or Token must be set.
+type Subject struct {
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+	Token  string     `json:"token,omitempty"` // Access token issued by this service
+}
+
+// CheckRequest asks whether a subject may perform an action on a resource
+type CheckRequest struct {
+	Subject    Subject                `json:"subject"`
+	TenantID   *uuid.UUID             `json:"tenant_id,omitempty"` // Defaults to the caller's tenant
+	Resource   string                 `json:"resource"`
+	Action     string                 `json:"action"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"` // Attributes of the resource instance
+	Context    *policy.RequestContext `json:"context,omitempty"`    // Request attributes for conditional policies
+}
+
+// Decision is the result of an authorization check
+type Decision struct {
+	Allowed           bool       `json:"allowed"`
+	Reason            string     `json:"reason"`
+	UserID            *uuid.UUID `json:"user_id,omitempty"`
+	MatchedRoleID     *uuid.UUID `json:"matched_role_id,omitempty"`
+	MatchedRole       string     `json:"matched_role,omitempty"`
+	MatchedPermission string     `json:"matched_permission,omitempty"`
+	MatchedPolicy     string     `json:"matched_policy,omitempty"` // Policy that allowed or denied the request
+}
+
+// Grant is a permission a user holds through one of their roles
+type Grant struct {
+	RoleID     uuid.UUID `json:"role_id"`
+	RoleName   string    `json:"role_name"`
+	Permission string    `json:"permission"` // resource:action
+}
+
+// Role sources in an explanation
+const (
+	RoleSourceDirect    = "direct"    // Assigned to the user
+	RoleSourceGroup     = "group"     // Granted to a group the user belongs to
+	RoleSourceInherited = "inherited" // Inherited from another considered role
+)
+
+// RoleTrace is a role considered while evaluating a request
+type RoleTrace struct {
+	RoleID   uuid.UUID `json:"role_id"`
+	RoleName string    `json:"role_name"`
+	Source   string    `json:"source"`
+	Via      string    `json:"via,omitempty"`      // Group granting the role, or role inheriting from it
+	Excluded string    `json:"excluded,omitempty"` // Why the role grants nothing, if it doesn't
+}
+
+// PermissionTrace is a permission found on one of the considered roles
+type PermissionTrace struct {
+	Grant
+	Matches bool `json:"matches"` // Satisfies the required permission, exactly or by wildcard
+}
+
+// Explanation is a decision together with the trace that produced it
+type Explanation struct {
+	Decision           *Decision         `json:"decision"`
+	RequiredPermission string            `json:"required_permission"`
+	Roles              []RoleTrace       `json:"roles"`
+	Permissions        []PermissionTrace `json:"permissions"`
+	Policies           *policy.Result    `json:"policies,omitempty"` // Conditional policies, when any apply
+	DecidingRule       string            `json:"deciding_rule"`
+}
+
+// PermissionChange adds or removes a permission on a role in a what-if simulation
+type PermissionChange struct {
+	RoleID     uuid.UUID `json:"role_id"`
+	Permission string    `json:"permission"` // resource:action, wildcards allowed
+}
+
+// WhatIfRequest describes hypothetical role and permission changes. Nothing is applied.
+type WhatIfRequest struct {
+	RemoveRoles       []uuid.UUID        `json:"remove_roles,omitempty"`
+	AddPermissions    []PermissionChange `json:"add_permissions,omitempty"`
+	RemovePermissions []PermissionChange `json:"remove_permissions,omitempty"`
+}
+
+// AccessChange is how a user's effective permissions would change
+type AccessChange struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Gained   []string  `json:"gained"`
+	Lost     []string  `json:"lost"`
+}
+
+// WhatIfResult lists the users whose effective access a WhatIfRequest would change
+type WhatIfResult struct {
+	UsersEvaluated int             `json:"users_evaluated"`
+	Changes        []*AccessChange `json:"changes"`
+}
+
+// Decision reasons
+const (
+	ReasonPermissionGranted = "permission granted"
+	ReasonNoMatchingGrant   = "no role grants the required permission"
+	ReasonSubjectNotFound   = "subject not found"
+	ReasonSubjectInactive   = "subject is not active"
+	ReasonTenantMismatch    = "subject does not belong to the tenant"
+	ReasonResourceTenant    = "resource belongs to another tenant"
+	ReasonInvalidToken      = "token is invalid, expired or revoked"
+)
+
+// MaxWhatIfChanges bounds the number of changes in a single what-if request
+const MaxWhatIfChanges = 50
+
+// Role exclusion reasons in an explanation
+const (
+	ExcludedOtherTenant = "role belongs to another tenant"
+	ExcludedInactive    = "role is not active"
+	ExcludedRemoved     = "role is removed in the simulation"
+)
@@ -0,0 +1,177 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arauth-identity/iam/identity/group"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/permission"
+	"github.com/arauth-identity/iam/identity/role"
+	"github.com/google/uuid"
+)
+
+// scenario is a set of hypothetical changes applied on top of stored roles and permissions
+type scenario struct {
+	removedRoles       map[uuid.UUID]bool
+	addedPermissions   map[uuid.UUID][]string
+	removedPermissions map[uuid.UUID]map[string]bool
+}
+
+// grantResolver walks a user's roles, groups and role permissions, recording why
+// each role was or wasn't counted. Role parents and permissions are loaded once
+// per resolver, so a tenant-wide what-if run doesn't re-read the same roles for
+// every user.
+type grantResolver struct {
+	service     *Service
+	scenario    *scenario
+	parents     map[uuid.UUID][]*models.Role
+	permissions map[uuid.UUID][]string
+}
+
+// newResolver creates a grant resolver. sc may be nil to resolve stored data as is.
+func (s *Service) newResolver(sc *scenario) *grantResolver {
+	return &grantResolver{
+		service:     s,
+		scenario:    sc,
+		parents:     make(map[uuid.UUID][]*models.Role),
+		permissions: make(map[uuid.UUID][]string),
+	}
+}
+
+// resolve returns the roles considered for a user and the grants of those that count.
+// Direct roles come first, then group roles, then inherited roles, which is the
+// order matchGrant prefers grants in.
+func (r *grantResolver) resolve(ctx context.Context, tenantID, userID uuid.UUID) ([]RoleTrace, []Grant, error) {
+	traces := make([]RoleTrace, 0)
+	counted := make([]*models.Role, 0)
+	seen := make(map[uuid.UUID]bool)
+
+	consider := func(candidate *models.Role, source, via string) bool {
+		if seen[candidate.ID] {
+			return false
+		}
+		seen[candidate.ID] = true
+
+		trace := RoleTrace{RoleID: candidate.ID, RoleName: candidate.Name, Source: source, Via: via}
+		switch {
+		case candidate.TenantID != tenantID:
+			// Tenant isolation: roles from other tenants never grant anything here
+			trace.Excluded = ExcludedOtherTenant
+		case !candidate.IsActive():
+			trace.Excluded = ExcludedInactive
+		case r.scenario != nil && r.scenario.removedRoles[candidate.ID]:
+			trace.Excluded = ExcludedRemoved
+		default:
+			counted = append(counted, candidate)
+		}
+		traces = append(traces, trace)
+		return trace.Excluded == ""
+	}
+
+	direct, err := r.service.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	for _, assigned := range direct {
+		consider(assigned, RoleSourceDirect, "")
+	}
+
+	if r.service.groupRepo != nil {
+		groups, err := group.UserGroups(ctx, r.service.groupRepo, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, g := range groups {
+			granted, err := r.service.groupRepo.GetGroupRoles(ctx, g.ID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get roles of group %s: %w", g.Name, err)
+			}
+			for _, grantedRole := range granted {
+				consider(grantedRole, RoleSourceGroup, g.Name)
+			}
+		}
+	}
+
+	// Inherited roles contribute their permissions as if assigned directly
+	frontier := append([]*models.Role(nil), counted...)
+	for depth := 0; len(frontier) > 0 && depth < role.MaxHierarchyDepth; depth++ {
+		var next []*models.Role
+		for _, child := range frontier {
+			parents, err := r.parentRoles(ctx, child)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, parent := range parents {
+				if consider(parent, RoleSourceInherited, child.Name) {
+					next = append(next, parent)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	grants := make([]Grant, 0)
+	for _, c := range counted {
+		keys, err := r.rolePermissions(ctx, c)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range keys {
+			grants = append(grants, Grant{RoleID: c.ID, RoleName: c.Name, Permission: key})
+		}
+	}
+
+	return traces, grants, nil
+}
+
+// parentRoles returns the roles a role inherits from
+func (r *grantResolver) parentRoles(ctx context.Context, child *models.Role) ([]*models.Role, error) {
+	if parents, ok := r.parents[child.ID]; ok {
+		return parents, nil
+	}
+	parents, err := r.service.roleRepo.GetParentRoles(ctx, child.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent roles of %s: %w", child.Name, err)
+	}
+	r.parents[child.ID] = parents
+	return parents, nil
+}
+
+// rolePermissions returns the active permissions of a role as resource:action
+// keys, with the scenario's additions and removals applied
+func (r *grantResolver) rolePermissions(ctx context.Context, subject *models.Role) ([]string, error) {
+	if keys, ok := r.permissions[subject.ID]; ok {
+		return keys, nil
+	}
+
+	permissions, err := r.service.permissionRepo.GetRolePermissions(ctx, subject.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions for role %s: %w", subject.Name, err)
+	}
+
+	keys := make([]string, 0, len(permissions))
+	present := make(map[string]bool, len(permissions))
+	for _, perm := range permissions {
+		if !perm.IsActive() {
+			continue
+		}
+		key := permission.Key(perm.Resource, perm.Action)
+		if r.scenario != nil && r.scenario.removedPermissions[subject.ID][key] {
+			continue
+		}
+		present[key] = true
+		keys = append(keys, key)
+	}
+	if r.scenario != nil {
+		for _, key := range r.scenario.addedPermissions[subject.ID] {
+			if !present[key] {
+				present[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	r.permissions[subject.ID] = keys
+	return keys, nil
+}
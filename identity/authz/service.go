@@ -0,0 +1,366 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/auth/token"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/permission"
+	"github.com/arauth-identity/iam/identity/policy"
+	"github.com/arauth-identity/iam/internal/cache"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// DefaultCacheTTL is how long a user's grants are cached. It is kept short so
+// that role changes take effect quickly without an explicit invalidation.
+const DefaultCacheTTL = 30 * time.Second
+
+// Service evaluates authorization requests against live role assignments
+type Service struct {
+	userRepo       interfaces.UserRepository
+	roleRepo       interfaces.RoleRepository
+	permissionRepo interfaces.PermissionRepository
+	groupRepo      interfaces.GroupRepository
+	policyService  policy.ServiceInterface
+	tokenService   token.ServiceInterface
+	cache          cache.CacheInterface
+	cacheTTL       time.Duration
+}
+
+// NewService creates a new authorization service.
+// groupRepo may be nil, in which case only roles assigned directly to users count.
+// policyService may be nil, in which case role grants are not refined by conditional policies.
+// cacheClient may be nil, in which case grants are loaded on every check.
+func NewService(
+	userRepo interfaces.UserRepository,
+	roleRepo interfaces.RoleRepository,
+	permissionRepo interfaces.PermissionRepository,
+	groupRepo interfaces.GroupRepository,
+	policyService policy.ServiceInterface,
+	tokenService token.ServiceInterface,
+	cacheClient cache.CacheInterface,
+	cacheTTL time.Duration,
+) *Service {
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultCacheTTL
+	}
+	return &Service{
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		groupRepo:      groupRepo,
+		policyService:  policyService,
+		tokenService:   tokenService,
+		cache:          cacheClient,
+		cacheTTL:       cacheTTL,
+	}
+}
+
+// Check evaluates a single authorization request within a tenant
+func (s *Service) Check(ctx context.Context, tenantID uuid.UUID, req *CheckRequest) (*Decision, error) {
+	subject, denied, err := s.prepare(ctx, tenantID, req)
+	if err != nil || denied != nil {
+		return denied, err
+	}
+
+	grants, err := s.getGrants(ctx, tenantID, *subject.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	decision, _, err := s.decide(ctx, tenantID, req, subject, grants)
+	return decision, err
+}
+
+// prepare validates a request and resolves its subject. A non-nil decision means
+// the request is denied before any grant is consulted.
+func (s *Service) prepare(ctx context.Context, tenantID uuid.UUID, req *CheckRequest) (*resolvedSubject, *Decision, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, nil, err
+	}
+
+	subject, reason, err := s.resolveSubject(ctx, tenantID, &req.Subject)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reason != "" {
+		return nil, &Decision{Allowed: false, Reason: reason, UserID: subject.userID}, nil
+	}
+
+	// Instance attributes can pin a resource to a tenant; never allow across tenants
+	if owner, ok := req.Attributes["tenant_id"].(string); ok && owner != tenantID.String() {
+		return nil, &Decision{Allowed: false, Reason: ReasonResourceTenant, UserID: subject.userID}, nil
+	}
+
+	return subject, nil, nil
+}
+
+// decide matches a subject's grants against the request and refines the outcome
+// with conditional policies. The policy result is nil when no policy applies.
+func (s *Service) decide(ctx context.Context, tenantID uuid.UUID, req *CheckRequest, subject *resolvedSubject, grants []Grant) (*Decision, *policy.Result, error) {
+	userID := subject.userID
+
+	match := matchGrant(grants, permission.Key(req.Resource, req.Action))
+	if match == nil {
+		return &Decision{Allowed: false, Reason: ReasonNoMatchingGrant, UserID: userID}, nil, nil
+	}
+
+	roleID := match.RoleID
+	decision := &Decision{
+		Allowed:           true,
+		Reason:            ReasonPermissionGranted,
+		UserID:            userID,
+		MatchedRoleID:     &roleID,
+		MatchedRole:       match.RoleName,
+		MatchedPermission: match.Permission,
+	}
+
+	// Conditional policies can only narrow what roles grant
+	result, err := s.evaluatePolicies(ctx, tenantID, req, subject, grants)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result != nil {
+		if result.Policy != nil {
+			decision.MatchedPolicy = result.Policy.Name
+		}
+		if !result.Allowed {
+			decision.Allowed = false
+			decision.Reason = result.Reason
+		}
+	}
+
+	return decision, result, nil
+}
+
+// evaluatePolicies decides the request against the tenant's conditional policies.
+// It returns nil when no policy applies.
+func (s *Service) evaluatePolicies(ctx context.Context, tenantID uuid.UUID, req *CheckRequest, subject *resolvedSubject, grants []Grant) (*policy.Result, error) {
+	if s.policyService == nil {
+		return nil, nil
+	}
+
+	policies, err := s.policyService.Applicable(ctx, tenantID, req.Resource, req.Action)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	roleNames := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, g := range grants {
+		if !seen[g.RoleName] {
+			seen[g.RoleName] = true
+			roleNames = append(roleNames, g.RoleName)
+		}
+	}
+
+	requestContext := policy.RequestContext{}
+	if req.Context != nil {
+		requestContext = *req.Context
+	}
+	// A token's authentication methods come from the token, never from the caller
+	if req.Subject.Token != "" {
+		requestContext.AMR = subject.amr
+		requestContext.AuthTime = subject.authTime
+	}
+
+	input := &policy.Input{
+		Subject:  policy.SubjectAttributes(subject.user, roleNames),
+		Resource: req.Attributes,
+		Context:  policy.ContextAttributes(&requestContext),
+		Tenant:   policy.TenantAttributes(tenantID),
+	}
+
+	return s.policyService.Decide(policies, input), nil
+}
+
+// matchGrant returns the grant satisfying the required permission, preferring an
+// exact grant over a wildcard or parent-resource one
+func matchGrant(grants []Grant, required string) *Grant {
+	var implied *Grant
+	for i := range grants {
+		if grants[i].Permission == required {
+			return &grants[i]
+		}
+		if implied == nil && permission.Matches(grants[i].Permission, required) {
+			implied = &grants[i]
+		}
+	}
+	return implied
+}
+
+// CheckBatch evaluates several authorization requests within a tenant, in order
+func (s *Service) CheckBatch(ctx context.Context, tenantID uuid.UUID, reqs []*CheckRequest) ([]*Decision, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("at least one check is required")
+	}
+	if len(reqs) > MaxBatchSize {
+		return nil, fmt.Errorf("batch may contain at most %d checks", MaxBatchSize)
+	}
+
+	decisions := make([]*Decision, 0, len(reqs))
+	for i, req := range reqs {
+		decision, err := s.Check(ctx, tenantID, req)
+		if err != nil {
+			return nil, fmt.Errorf("check %d: %w", i, err)
+		}
+		decisions = append(decisions, decision)
+	}
+
+	return decisions, nil
+}
+
+// InvalidateUser drops cached grants for a user
+func (s *Service) InvalidateUser(ctx context.Context, tenantID, userID uuid.UUID) error {
+	if s.cache == nil {
+		return nil
+	}
+	if err := s.cache.Delete(ctx, grantsCacheKey(tenantID, s.generation(ctx, tenantID), userID)); err != nil {
+		return fmt.Errorf("failed to invalidate authorization cache: %w", err)
+	}
+	return nil
+}
+
+// InvalidateTenant drops cached grants for every user in a tenant. A change to one
+// role can reach any user through role inheritance, so the tenant's cache
+// generation is rotated instead of tracking which users are affected.
+func (s *Service) InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	if s.cache == nil {
+		return nil
+	}
+	// Outlive every grant cached under the previous generation, so an expired
+	// generation can never bring those entries back
+	if err := s.cache.Set(ctx, generationCacheKey(tenantID), uuid.New().String(), 2*s.cacheTTL); err != nil {
+		return fmt.Errorf("failed to invalidate authorization cache: %w", err)
+	}
+	return nil
+}
+
+// validateRequest checks that a request is well formed
+func validateRequest(req *CheckRequest) error {
+	if req == nil {
+		return fmt.Errorf("check request is required")
+	}
+	if strings.TrimSpace(req.Resource) == "" || strings.TrimSpace(req.Action) == "" {
+		return fmt.Errorf("resource and action are required")
+	}
+	hasUser := req.Subject.UserID != nil && *req.Subject.UserID != uuid.Nil
+	hasToken := req.Subject.Token != ""
+	if hasUser == hasToken {
+		return fmt.Errorf("subject must have exactly one of user_id or token")
+	}
+	return nil
+}
+
+// resolvedSubject is the user a check is for, with the session details of a token subject
+type resolvedSubject struct {
+	userID   *uuid.UUID
+	user     *models.User
+	amr      []string
+	authTime *int64
+}
+
+// resolveSubject returns the user a subject refers to. A non-empty reason means
+// the subject cannot be authorized in this tenant and the check is denied.
+func (s *Service) resolveSubject(ctx context.Context, tenantID uuid.UUID, subject *Subject) (*resolvedSubject, string, error) {
+	resolved := &resolvedSubject{userID: subject.UserID}
+
+	if subject.Token != "" {
+		tokenClaims, err := s.tokenService.ValidateAccessToken(subject.Token)
+		if err != nil {
+			return resolved, ReasonInvalidToken, nil
+		}
+		if tokenClaims.ID != "" {
+			revoked, err := s.tokenService.IsAccessTokenRevoked(ctx, tokenClaims.ID)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to check token revocation: %w", err)
+			}
+			if revoked {
+				return resolved, ReasonInvalidToken, nil
+			}
+		}
+		if tokenClaims.TenantID != tenantID.String() {
+			return resolved, ReasonTenantMismatch, nil
+		}
+		parsed, err := uuid.Parse(tokenClaims.Subject)
+		if err != nil {
+			return resolved, ReasonInvalidToken, nil
+		}
+		resolved.userID = &parsed
+		resolved.amr = tokenClaims.AMR
+		if tokenClaims.AuthTime > 0 {
+			authTime := tokenClaims.AuthTime
+			resolved.authTime = &authTime
+		}
+	}
+
+	// Always consult the live user record rather than the token claims
+	user, err := s.userRepo.GetByID(ctx, *resolved.userID)
+	if err != nil {
+		return resolved, ReasonSubjectNotFound, nil
+	}
+	if user.TenantID == nil || *user.TenantID != tenantID {
+		return resolved, ReasonTenantMismatch, nil
+	}
+	if !user.IsActive() {
+		return resolved, ReasonSubjectInactive, nil
+	}
+	resolved.user = user
+
+	return resolved, "", nil
+}
+
+// getGrants returns the permissions a user holds in a tenant, using the cache when available
+func (s *Service) getGrants(ctx context.Context, tenantID, userID uuid.UUID) ([]Grant, error) {
+	if s.cache == nil {
+		return s.loadGrants(ctx, tenantID, userID)
+	}
+
+	key := grantsCacheKey(tenantID, s.generation(ctx, tenantID), userID)
+	var cached []Grant
+	if err := s.cache.Get(ctx, key, &cached); err == nil {
+		return cached, nil
+	}
+
+	grants, err := s.loadGrants(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Caching is best effort; a failure only costs a reload on the next check
+	_ = s.cache.Set(ctx, key, grants, s.cacheTTL)
+
+	return grants, nil
+}
+
+// loadGrants reads a user's role assignments, group role grants and role permissions from the repositories
+func (s *Service) loadGrants(ctx context.Context, tenantID, userID uuid.UUID) ([]Grant, error) {
+	_, grants, err := s.newResolver(nil).resolve(ctx, tenantID, userID)
+	return grants, err
+}
+
+// generation returns the tenant's current cache generation, "0" until the tenant is first invalidated
+func (s *Service) generation(ctx context.Context, tenantID uuid.UUID) string {
+	var gen string
+	if err := s.cache.Get(ctx, generationCacheKey(tenantID), &gen); err != nil || gen == "" {
+		return "0"
+	}
+	return gen
+}
+
+// grantsCacheKey is the cache key for a user's grants in a tenant
+func grantsCacheKey(tenantID uuid.UUID, generation string, userID uuid.UUID) string {
+	return fmt.Sprintf("authz:grants:%s:%s:%s", tenantID, generation, userID)
+}
+
+// generationCacheKey is the cache key holding a tenant's grant cache generation
+func generationCacheKey(tenantID uuid.UUID) string {
+	return fmt.Sprintf("authz:gen:%s", tenantID)
+}
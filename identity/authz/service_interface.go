@@ -0,0 +1,28 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines the interface for authorization decisions
+type ServiceInterface interface {
+	// Check evaluates a single authorization request within a tenant
+	Check(ctx context.Context, tenantID uuid.UUID, req *CheckRequest) (*Decision, error)
+
+	// CheckBatch evaluates several authorization requests within a tenant, in order
+	CheckBatch(ctx context.Context, tenantID uuid.UUID, reqs []*CheckRequest) ([]*Decision, error)
+
+	// Explain evaluates a request and returns the trace behind the decision
+	Explain(ctx context.Context, tenantID uuid.UUID, req *CheckRequest) (*Explanation, error)
+
+	// WhatIf reports whose effective access hypothetical role and permission changes would alter
+	WhatIf(ctx context.Context, tenantID uuid.UUID, req *WhatIfRequest) (*WhatIfResult, error)
+
+	// InvalidateUser drops cached grants for a user
+	InvalidateUser(ctx context.Context, tenantID, userID uuid.UUID) error
+
+	// InvalidateTenant drops cached grants for every user in a tenant
+	InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error
+}
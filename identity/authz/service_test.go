@@ -0,0 +1,449 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/auth/claims"
+	"github.com/arauth-identity/iam/auth/token"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/policy"
+	"github.com/arauth-identity/iam/internal/cache"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubUserRepository serves a fixed set of users
+type stubUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *stubUserRepository) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.UserFilters) ([]*models.User, error) {
+	if filters != nil && filters.Page > 1 {
+		return nil, nil
+	}
+	var users []*models.User
+	for _, u := range r.users {
+		if u.TenantID != nil && *u.TenantID == tenantID {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// stubRoleRepository serves fixed role assignments and counts lookups
+type stubRoleRepository struct {
+	interfaces.RoleRepository
+	userRoles map[uuid.UUID][]*models.Role
+	parents   map[uuid.UUID][]*models.Role
+	calls     int
+}
+
+func (r *stubRoleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error) {
+	r.calls++
+	return r.userRoles[userID], nil
+}
+
+func (r *stubRoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	for _, roles := range r.userRoles {
+		for _, assigned := range roles {
+			if assigned.ID == id {
+				return assigned, nil
+			}
+		}
+	}
+	for _, roles := range r.parents {
+		for _, parent := range roles {
+			if parent.ID == id {
+				return parent, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("role not found")
+}
+
+func (r *stubRoleRepository) GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	return r.parents[roleID], nil
+}
+
+// stubGroupRepository serves fixed group memberships, nesting and role grants
+type stubGroupRepository struct {
+	interfaces.GroupRepository
+	userGroups map[uuid.UUID][]*models.Group
+	parents    map[uuid.UUID][]*models.Group
+	roles      map[uuid.UUID][]*models.Role
+}
+
+func (r *stubGroupRepository) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]*models.Group, error) {
+	return r.userGroups[userID], nil
+}
+
+func (r *stubGroupRepository) GetParentGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	return r.parents[groupID], nil
+}
+
+func (r *stubGroupRepository) GetGroupRoles(ctx context.Context, groupID uuid.UUID) ([]*models.Role, error) {
+	return r.roles[groupID], nil
+}
+
+// stubPolicyRepository serves a fixed set of policies
+type stubPolicyRepository struct {
+	interfaces.PolicyRepository
+	policies []*models.Policy
+}
+
+func (r *stubPolicyRepository) List(ctx context.Context, tenantID uuid.UUID, enabledOnly bool) ([]*models.Policy, error) {
+	var policies []*models.Policy
+	for _, p := range r.policies {
+		if p.TenantID == tenantID && (p.Enabled || !enabledOnly) {
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
+
+// stubPermissionRepository serves fixed role permissions
+type stubPermissionRepository struct {
+	interfaces.PermissionRepository
+	rolePermissions map[uuid.UUID][]*models.Permission
+}
+
+func (r *stubPermissionRepository) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]*models.Permission, error) {
+	return r.rolePermissions[roleID], nil
+}
+
+// stubTokenService validates tokens from a fixed map
+type stubTokenService struct {
+	token.ServiceInterface
+	tokens  map[string]*claims.Claims
+	revoked map[string]bool
+}
+
+func (s *stubTokenService) ValidateAccessToken(tokenString string) (*claims.Claims, error) {
+	if c, ok := s.tokens[tokenString]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("invalid token")
+}
+
+func (s *stubTokenService) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.revoked[jti], nil
+}
+
+type authzFixture struct {
+	service  *Service
+	roles    *stubRoleRepository
+	groups   *stubGroupRepository
+	policies *stubPolicyRepository
+	tokens   *stubTokenService
+	tenantID uuid.UUID
+	user     *models.User
+	role     *models.Role
+}
+
+func newAuthzFixture(t *testing.T, cacheClient cache.CacheInterface) *authzFixture {
+	t.Helper()
+
+	tenantID := uuid.New()
+	user := &models.User{ID: uuid.New(), TenantID: &tenantID, Status: models.UserStatusActive}
+	editor := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "editor"}
+	foreign := &models.Role{ID: uuid.New(), TenantID: uuid.New(), Name: "foreign_admin"}
+
+	f := &authzFixture{
+		roles: &stubRoleRepository{
+			userRoles: map[uuid.UUID][]*models.Role{user.ID: {editor, foreign}},
+			parents:   map[uuid.UUID][]*models.Role{},
+		},
+		groups: &stubGroupRepository{
+			userGroups: map[uuid.UUID][]*models.Group{},
+			parents:    map[uuid.UUID][]*models.Group{},
+			roles:      map[uuid.UUID][]*models.Role{},
+		},
+		policies: &stubPolicyRepository{},
+		tokens:   &stubTokenService{tokens: map[string]*claims.Claims{}, revoked: map[string]bool{}},
+		tenantID: tenantID,
+		user:     user,
+		role:     editor,
+	}
+	permissions := &stubPermissionRepository{rolePermissions: map[uuid.UUID][]*models.Permission{
+		editor.ID:  {{Resource: "documents", Action: "read"}, {Resource: "documents", Action: "update"}},
+		foreign.ID: {{Resource: "documents", Action: "delete"}},
+	}}
+
+	f.service = NewService(
+		&stubUserRepository{users: map[uuid.UUID]*models.User{user.ID: user}},
+		f.roles, permissions, f.groups, policy.NewService(f.policies), f.tokens, cacheClient, 0,
+	)
+	return f
+}
+
+func (f *authzFixture) check(resource, action string) *CheckRequest {
+	return &CheckRequest{Subject: Subject{UserID: &f.user.ID}, Resource: resource, Action: action}
+}
+
+func TestService_Check_Allowed(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+
+	decision, err := f.service.Check(context.Background(), f.tenantID, f.check("documents", "update"))
+
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "editor", decision.MatchedRole)
+	assert.Equal(t, f.role.ID, *decision.MatchedRoleID)
+	assert.Equal(t, "documents:update", decision.MatchedPermission)
+}
+
+func TestService_Check_IgnoresRolesFromOtherTenants(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+
+	decision, err := f.service.Check(context.Background(), f.tenantID, f.check("documents", "delete"))
+
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonNoMatchingGrant, decision.Reason)
+}
+
+func TestService_Check_SubjectChecks(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	ctx := context.Background()
+
+	decision, err := f.service.Check(ctx, uuid.New(), f.check("documents", "read"))
+	require.NoError(t, err)
+	assert.Equal(t, ReasonTenantMismatch, decision.Reason)
+
+	f.user.Status = models.UserStatusSuspended
+	decision, err = f.service.Check(ctx, f.tenantID, f.check("documents", "read"))
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonSubjectInactive, decision.Reason)
+
+	unknown := uuid.New()
+	decision, err = f.service.Check(ctx, f.tenantID, &CheckRequest{Subject: Subject{UserID: &unknown}, Resource: "documents", Action: "read"})
+	require.NoError(t, err)
+	assert.Equal(t, ReasonSubjectNotFound, decision.Reason)
+}
+
+func TestService_Check_ResourceTenantAttribute(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	req := f.check("documents", "read")
+	req.Attributes = map[string]interface{}{"tenant_id": uuid.New().String()}
+
+	decision, err := f.service.Check(context.Background(), f.tenantID, req)
+
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonResourceTenant, decision.Reason)
+}
+
+func TestService_Check_TokenSubject(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	f.tokens.tokens["good"] = &claims.Claims{ID: "jti-1", Subject: f.user.ID.String(), TenantID: f.tenantID.String()}
+	f.tokens.tokens["revoked"] = &claims.Claims{ID: "jti-2", Subject: f.user.ID.String(), TenantID: f.tenantID.String()}
+	f.tokens.revoked["jti-2"] = true
+	ctx := context.Background()
+
+	decision, err := f.service.Check(ctx, f.tenantID, &CheckRequest{Subject: Subject{Token: "good"}, Resource: "documents", Action: "read"})
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, f.user.ID, *decision.UserID)
+
+	for _, tok := range []string{"revoked", "garbage"} {
+		decision, err = f.service.Check(ctx, f.tenantID, &CheckRequest{Subject: Subject{Token: tok}, Resource: "documents", Action: "read"})
+		require.NoError(t, err)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, ReasonInvalidToken, decision.Reason)
+	}
+}
+
+func TestService_Check_InvalidRequest(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+
+	_, err := f.service.Check(context.Background(), f.tenantID, &CheckRequest{Resource: "documents", Action: "read"})
+	assert.EqualError(t, err, "subject must have exactly one of user_id or token")
+
+	_, err = f.service.Check(context.Background(), f.tenantID, &CheckRequest{Subject: Subject{UserID: &f.user.ID}, Resource: "documents"})
+	assert.EqualError(t, err, "resource and action are required")
+}
+
+func TestService_Check_CachesGrants(t *testing.T) {
+	f := newAuthzFixture(t, cache.NewMemoryCache())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := f.service.Check(ctx, f.tenantID, f.check("documents", "read"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, f.roles.calls)
+
+	require.NoError(t, f.service.InvalidateUser(ctx, f.tenantID, f.user.ID))
+	_, err := f.service.Check(ctx, f.tenantID, f.check("documents", "read"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.roles.calls)
+}
+
+func TestService_Check_InheritedRole(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	publisher := &models.Role{ID: uuid.New(), TenantID: f.tenantID, Name: "publisher"}
+	f.roles.parents[f.role.ID] = []*models.Role{publisher}
+	f.service.permissionRepo.(*stubPermissionRepository).rolePermissions[publisher.ID] = []*models.Permission{
+		{Resource: "documents", Action: "publish"},
+	}
+
+	decision, err := f.service.Check(context.Background(), f.tenantID, f.check("documents", "publish"))
+
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "publisher", decision.MatchedRole)
+}
+
+func TestService_Check_NestedGroupRole(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	team := &models.Group{ID: uuid.New(), TenantID: f.tenantID, Name: "team"}
+	department := &models.Group{ID: uuid.New(), TenantID: f.tenantID, Name: "department"}
+	auditor := &models.Role{ID: uuid.New(), TenantID: f.tenantID, Name: "auditor"}
+	f.groups.userGroups[f.user.ID] = []*models.Group{team}
+	f.groups.parents[team.ID] = []*models.Group{department}
+	f.groups.roles[department.ID] = []*models.Role{auditor}
+	f.service.permissionRepo.(*stubPermissionRepository).rolePermissions[auditor.ID] = []*models.Permission{
+		{Resource: "audit", Action: "read"},
+	}
+
+	decision, err := f.service.Check(context.Background(), f.tenantID, f.check("audit", "read"))
+
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "auditor", decision.MatchedRole)
+}
+
+func (f *authzFixture) addPolicy(effect, resource, action, condition string) {
+	f.policies.policies = append(f.policies.policies, &models.Policy{
+		ID: uuid.New(), TenantID: f.tenantID, Name: effect + "-" + resource + "-" + action,
+		Effect: effect, Resource: resource, Action: action, Condition: condition, Enabled: true,
+	})
+}
+
+func TestService_Check_DenyPolicy(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	f.addPolicy(models.PolicyEffectDeny, "documents", "update", `!ip_in(context.ip, "10.0.0.0/8")`)
+	ctx := context.Background()
+
+	req := f.check("documents", "update")
+	req.Context = &policy.RequestContext{IP: "203.0.113.7"}
+	decision, err := f.service.Check(ctx, f.tenantID, req)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, policy.ReasonDeniedByPolicy, decision.Reason)
+	assert.Equal(t, "deny-documents-update", decision.MatchedPolicy)
+
+	req.Context = &policy.RequestContext{IP: "10.1.2.3"}
+	decision, err = f.service.Check(ctx, f.tenantID, req)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	// Policies never grant what roles do not
+	decision, err = f.service.Check(ctx, f.tenantID, f.check("documents", "delete"))
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonNoMatchingGrant, decision.Reason)
+}
+
+func TestService_Check_AllowPolicyAttributes(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	f.user.Metadata = map[string]interface{}{"department": "support"}
+	f.addPolicy(models.PolicyEffectAllow, "documents", "*", `subject.department == resource.department && "editor" in subject.roles`)
+	ctx := context.Background()
+
+	req := f.check("documents", "read")
+	req.Attributes = map[string]interface{}{"department": "support"}
+	decision, err := f.service.Check(ctx, f.tenantID, req)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "allow-documents-*", decision.MatchedPolicy)
+
+	req.Attributes = map[string]interface{}{"department": "finance"}
+	decision, err = f.service.Check(ctx, f.tenantID, req)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, policy.ReasonNoAllowPolicyMatch, decision.Reason)
+}
+
+func TestService_Check_PolicyUsesTokenMFA(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	f.addPolicy(models.PolicyEffectAllow, "documents", "update", `mfa_within(300)`)
+	authTime := time.Now().Add(-time.Minute).Unix()
+	f.tokens.tokens["fresh"] = &claims.Claims{Subject: f.user.ID.String(), TenantID: f.tenantID.String(), AMR: []string{"pwd", "mfa"}, AuthTime: authTime}
+	f.tokens.tokens["password"] = &claims.Claims{Subject: f.user.ID.String(), TenantID: f.tenantID.String(), AMR: []string{"pwd"}, AuthTime: authTime}
+
+	check := func(token string, rc *policy.RequestContext) *Decision {
+		req := &CheckRequest{Subject: Subject{Token: token}, Resource: "documents", Action: "update", Context: rc}
+		decision, err := f.service.Check(context.Background(), f.tenantID, req)
+		require.NoError(t, err)
+		return decision
+	}
+
+	assert.True(t, check("fresh", nil).Allowed)
+	assert.False(t, check("password", nil).Allowed)
+	// A caller cannot claim MFA for a token subject
+	assert.False(t, check("password", &policy.RequestContext{AMR: []string{"mfa"}, AuthTime: &authTime}).Allowed)
+}
+
+func TestService_InvalidateTenant(t *testing.T) {
+	f := newAuthzFixture(t, cache.NewMemoryCache())
+	ctx := context.Background()
+
+	_, err := f.service.Check(ctx, f.tenantID, f.check("documents", "read"))
+	require.NoError(t, err)
+	_, err = f.service.Check(ctx, f.tenantID, f.check("documents", "read"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.roles.calls)
+
+	require.NoError(t, f.service.InvalidateTenant(ctx, f.tenantID))
+	_, err = f.service.Check(ctx, f.tenantID, f.check("documents", "read"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.roles.calls)
+}
+
+func TestService_CheckBatch(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+
+	decisions, err := f.service.CheckBatch(context.Background(), f.tenantID, []*CheckRequest{
+		f.check("documents", "read"),
+		f.check("documents", "delete"),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+	assert.True(t, decisions[0].Allowed)
+	assert.False(t, decisions[1].Allowed)
+
+	_, err = f.service.CheckBatch(context.Background(), f.tenantID, make([]*CheckRequest, MaxBatchSize+1))
+	assert.Error(t, err)
+}
+
+func TestService_Check_WildcardGrant(t *testing.T) {
+	f := newAuthzFixture(t, nil)
+	admin := &models.Role{ID: uuid.New(), TenantID: f.tenantID, Name: "billing_admin"}
+	f.roles.userRoles[f.user.ID] = append(f.roles.userRoles[f.user.ID], admin)
+	f.service.permissionRepo.(*stubPermissionRepository).rolePermissions[admin.ID] = []*models.Permission{
+		{Resource: "billing", Action: "*"},
+	}
+
+	decision, err := f.service.Check(context.Background(), f.tenantID, f.check("billing.invoices", "read"))
+
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "billing_admin", decision.MatchedRole)
+	assert.Equal(t, "billing:*", decision.MatchedPermission)
+}
@@ -0,0 +1,82 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// errForbiddenAddress is returned when a connector would reach an address
+// that is not public
+var errForbiddenAddress = errors.New("address is not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598)
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicAddress reports whether ip is a public unicast address. Connectors
+// are configured by tenants, so they must not reach loopback, private,
+// link-local or other internal addresses of the deployment.
+func publicAddress(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// newHTTPClient returns the client connectors use. The address of every
+// connection is checked when it is dialed, after name resolution, so a host
+// that resolved to a public address when the connector was saved cannot be
+// pointed at an internal one later.
+func newHTTPClient(allow func(net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allow(ip) {
+				return fmt.Errorf("%s: %w", host, errForbiddenAddress)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would connect on our behalf, past the check
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+		// A redirect is returned as the response, so it fails like any other non-2xx status
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// validateServiceURL checks that raw is an https URL whose host resolves
+// only to addresses allow accepts
+func validateServiceURL(ctx context.Context, field, raw string, allow func(net.IP) bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%s must be an https URL", field)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("%s must not have a query or fragment", field)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%s host could not be resolved", field)
+	}
+	for _, addr := range addrs {
+		if !allow(addr.IP) {
+			return fmt.Errorf("%s must not point to a loopback, private or link-local address", field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// scimContentType is the media type of SCIM requests (RFC 7644 section 3.1)
+const scimContentType = "application/scim+json"
+
+// remoteError is a non-2xx response from a downstream SCIM service or its
+// token endpoint. The response body is not kept: errors are shown to the
+// tenant, and the body could reveal what is behind a misdirected URL.
+type remoteError struct {
+	status   int
+	endpoint string
+}
+
+func (e *remoteError) Error() string {
+	return fmt.Sprintf("%s returned %d", e.endpoint, e.status)
+}
+
+// isNotFound reports whether err is a 404 from the downstream service
+func isNotFound(err error) bool {
+	var remote *remoteError
+	return errors.As(err, &remote) && remote.status == http.StatusNotFound
+}
+
+// isRetryable reports whether a failed request may succeed later. Requests
+// the downstream service rejected as invalid are not retried.
+func isRetryable(err error) bool {
+	var remote *remoteError
+	if !errors.As(err, &remote) {
+		return true
+	}
+	return remote.status >= 500 || remote.status == http.StatusRequestTimeout || remote.status == http.StatusTooManyRequests
+}
+
+// tokenSource supplies the bearer token of a request
+type tokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type staticToken string
+
+func (t staticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// clientCredentials obtains tokens with the OAuth 2.0 client credentials grant
+// (RFC 6749 section 4.4) and reuses each until shortly before it expires
+type clientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	httpClient   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func (c *clientCredentials) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &remoteError{status: resp.StatusCode, endpoint: "token endpoint"}
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("token endpoint returned no access token")
+	}
+
+	lifetime := time.Duration(token.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = 5 * time.Minute
+	}
+	c.accessToken = token.AccessToken
+	c.expiresAt = time.Now().Add(lifetime - lifetime/10)
+
+	return c.accessToken, nil
+}
+
+// client talks SCIM 2.0 to a connector's downstream service
+type client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      tokenSource
+}
+
+// endpoint returns the resource endpoint of a resource type
+func endpoint(resourceType string) string {
+	if resourceType == models.SCIMResourceGroup {
+		return "/Groups"
+	}
+	return "/Users"
+}
+
+// create creates a resource and returns its downstream ID
+func (c *client) create(ctx context.Context, resourceType string, doc map[string]interface{}) (string, error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, endpoint(resourceType), doc, &created); err != nil {
+		return "", err
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("downstream SCIM service returned no id for the created %s", resourceType)
+	}
+	return created.ID, nil
+}
+
+// replace replaces a resource
+func (c *client) replace(ctx context.Context, resourceType, id string, doc map[string]interface{}) error {
+	return c.do(ctx, http.MethodPut, endpoint(resourceType)+"/"+url.PathEscape(id), doc, nil)
+}
+
+// deactivate sets a user's active attribute to false
+func (c *client) deactivate(ctx context.Context, id string) error {
+	patch := map[string]interface{}{
+		"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		"Operations": []map[string]interface{}{{"op": "replace", "path": "active", "value": false}},
+	}
+	return c.do(ctx, http.MethodPatch, endpoint(models.SCIMResourceUser)+"/"+url.PathEscape(id), patch, nil)
+}
+
+// delete deletes a resource. A resource that is already gone is not an error.
+func (c *client) delete(ctx context.Context, resourceType, id string) error {
+	err := c.do(ctx, http.MethodDelete, endpoint(resourceType)+"/"+url.PathEscape(id), nil, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// find returns the ID of the resource whose attribute equals value
+func (c *client) find(ctx context.Context, resourceType, attribute, value string) (string, bool, error) {
+	filter := fmt.Sprintf(`%s eq "%s"`, attribute, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value))
+	var list struct {
+		Resources []struct {
+			ID string `json:"id"`
+		} `json:"Resources"`
+	}
+	query := "?filter=" + url.QueryEscape(filter) + "&count=1"
+	if err := c.do(ctx, http.MethodGet, endpoint(resourceType)+query, nil, &list); err != nil {
+		return "", false, err
+	}
+	if len(list.Resources) == 0 || list.Resources[0].ID == "" {
+		return "", false, nil
+	}
+	return list.Resources[0].ID, true, nil
+}
+
+// ping fetches the service provider configuration to check connectivity and credentials
+func (c *client) ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/ServiceProviderConfig", nil, nil)
+}
+
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode SCIM request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create SCIM request: %w", err)
+	}
+	token, err := c.token.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", scimContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", scimContentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach downstream SCIM service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &remoteError{status: resp.StatusCode, endpoint: "downstream SCIM service"}
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode SCIM response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package connector
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// A user mapping maps target SCIM attributes to source attributes of the
+// local user. Targets are core attribute paths such as "name.givenName" or
+// URN-qualified extension attributes such as
+// "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department".
+// Sources are one of userSources or "metadata.<key>".
+
+// DefaultUserMapping is used when a connector defines no mapping of its own
+var DefaultUserMapping = map[string]string{
+	"userName":        "username",
+	"externalId":      "id",
+	"name.givenName":  "first_name",
+	"name.familyName": "last_name",
+	"displayName":     "display_name",
+	"emails":          "email",
+	"active":          "active",
+}
+
+// userSources are the source attributes a mapping can read
+var userSources = map[string]bool{
+	"id":           true,
+	"username":     true,
+	"email":        true,
+	"first_name":   true,
+	"last_name":    true,
+	"display_name": true,
+	"active":       true,
+	"status":       true,
+	"roles":        true, // Names of the user's directly assigned roles
+}
+
+// metadataSourcePrefix reads a source attribute from user metadata
+const metadataSourcePrefix = "metadata."
+
+// coreTargetPattern matches an attribute or sub-attribute of the core User schema
+var coreTargetPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*(\.[A-Za-z][A-Za-z0-9_-]*)?$`)
+
+// emailLikeTargets are multi-valued attributes a single string is written to
+// as the primary work value
+var emailLikeTargets = map[string]bool{"emails": true, "phoneNumbers": true}
+
+// validateUserMapping checks that a mapping sets userName and names known
+// sources and well-formed targets
+func validateUserMapping(mapping map[string]string) error {
+	if mapping["userName"] == "" {
+		return fmt.Errorf("user mapping must map userName")
+	}
+	for target, source := range mapping {
+		if _, _, ok := splitTarget(target); !ok {
+			return fmt.Errorf("invalid user mapping target %q", target)
+		}
+		if !userSources[source] && (!strings.HasPrefix(source, metadataSourcePrefix) || len(source) == len(metadataSourcePrefix)) {
+			return fmt.Errorf("invalid user mapping source %q for %s", source, target)
+		}
+	}
+	return nil
+}
+
+// mapsSource reports whether a mapping reads the given source attribute
+func mapsSource(mapping map[string]string, source string) bool {
+	for _, s := range mapping {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTarget splits a target into the extension schema URN it belongs to,
+// empty for the core schema, and the attribute path within it
+func splitTarget(target string) (string, string, bool) {
+	if strings.HasPrefix(strings.ToLower(target), "urn:") {
+		i := strings.LastIndex(target, ":")
+		schema, path := target[:i], target[i+1:]
+		if !coreTargetPattern.MatchString(path) || strings.Count(schema, ":") < 2 {
+			return "", "", false
+		}
+		return schema, path, true
+	}
+	return "", target, coreTargetPattern.MatchString(target)
+}
+
+// userSourceValue reads a source attribute of a user. It returns false when
+// the user has no value for it.
+func userSourceValue(u *models.User, roles []string, source string) (interface{}, bool) {
+	switch source {
+	case "id":
+		return u.ID.String(), true
+	case "username":
+		return u.Username, u.Username != ""
+	case "email":
+		return u.Email, u.Email != ""
+	case "first_name":
+		return stringValue(u.FirstName)
+	case "last_name":
+		return stringValue(u.LastName)
+	case "display_name":
+		name := u.FullName()
+		if name == "" {
+			name = u.Username
+		}
+		return name, true
+	case "active":
+		return u.IsActive(), true
+	case "status":
+		return u.Status, true
+	case "roles":
+		return roles, len(roles) > 0
+	}
+	value, ok := u.Metadata[strings.TrimPrefix(source, metadataSourcePrefix)]
+	if !ok || value == nil || value == "" {
+		return nil, false
+	}
+	return value, true
+}
+
+func stringValue(s *string) (interface{}, bool) {
+	if s == nil || *s == "" {
+		return nil, false
+	}
+	return *s, true
+}
+
+// buildUserDocument builds the SCIM User resource pushed downstream
+func buildUserDocument(u *models.User, roles []string, mapping map[string]string) map[string]interface{} {
+	doc := map[string]interface{}{}
+	schemas := []string{models.SCIMUserSchema}
+
+	for target, source := range mapping {
+		value, ok := userSourceValue(u, roles, source)
+		if !ok {
+			continue
+		}
+		schema, path, _ := splitTarget(target)
+		object := doc
+		if schema != "" {
+			ext, _ := doc[schema].(map[string]interface{})
+			if ext == nil {
+				ext = map[string]interface{}{}
+				doc[schema] = ext
+				schemas = append(schemas, schema)
+			}
+			object = ext
+		}
+		setPath(object, path, targetValue(path, value))
+	}
+
+	sort.Strings(schemas[1:])
+	doc["schemas"] = schemas
+	return doc
+}
+
+// targetValue shapes a value for its target: lists become multi-valued
+// attributes and strings written to emails or phoneNumbers become their
+// primary work value
+func targetValue(path string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case []string:
+		values := make([]map[string]interface{}, len(v))
+		for i, s := range v {
+			values[i] = map[string]interface{}{"value": s}
+		}
+		return values
+	case string:
+		if emailLikeTargets[path] {
+			return []map[string]interface{}{{"value": v, "type": "work", "primary": true}}
+		}
+	}
+	return value
+}
+
+// setPath sets an attribute or sub-attribute of a resource
+func setPath(object map[string]interface{}, path string, value interface{}) {
+	attr, sub, nested := strings.Cut(path, ".")
+	if !nested {
+		object[attr] = value
+		return
+	}
+	parent, _ := object[attr].(map[string]interface{})
+	if parent == nil {
+		parent = map[string]interface{}{}
+		object[attr] = parent
+	}
+	parent[sub] = value
+}
+
+// buildGroupDocument builds the SCIM Group resource pushed downstream.
+// members holds the downstream IDs of the group's users and groups.
+func buildGroupDocument(g *models.Group, members []string) map[string]interface{} {
+	values := make([]map[string]interface{}, len(members))
+	for i, id := range members {
+		values[i] = map[string]interface{}{"value": id}
+	}
+	return map[string]interface{}{
+		"schemas":     []string{models.SCIMGroupSchema},
+		"displayName": g.Name,
+		"externalId":  g.ID.String(),
+		"members":     values,
+	}
+}
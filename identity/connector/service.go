@@ -0,0 +1,857 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/security/encryption"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultSweepInterval is how often queued operations are pushed downstream
+	DefaultSweepInterval = 15 * time.Second
+
+	// ReconcileInterval is how often each enabled connector is reconciled in full
+	ReconcileInterval = 24 * time.Hour
+
+	// MaxAttempts is how many times an operation is tried before it fails
+	MaxAttempts = 8
+
+	claimBatchSize     = 100
+	claimLease         = 5 * time.Minute
+	baseBackoff        = 30 * time.Second
+	maxBackoff         = time.Hour
+	completedRetention = 7 * 24 * time.Hour
+	reconcilePageSize  = 500
+)
+
+// Service provisions users and groups to downstream applications over SCIM.
+// Changes reach it as audit events and are queued per connector; a worker
+// pushes queued operations and retries failures with exponential backoff,
+// and a periodic reconciliation re-queues every resource so that changes
+// made without an audit event are caught up.
+type Service struct {
+	connectorRepo interfaces.SCIMConnectorRepository
+	syncRepo      interfaces.SCIMSyncRepository
+	userRepo      interfaces.UserRepository
+	groupRepo     interfaces.GroupRepository
+	roleRepo      interfaces.RoleRepository
+	encryptor     *encryption.Encryptor
+	httpClient    *http.Client
+	allowAddress  func(net.IP) bool
+
+	mu      sync.Mutex
+	clients map[uuid.UUID]*cachedClient
+}
+
+// cachedClient is a connector's client, reused until the connector changes
+type cachedClient struct {
+	updatedAt time.Time
+	client    *client
+}
+
+// CreateConnectorRequest represents a request to create a connector
+type CreateConnectorRequest struct {
+	TenantID          uuid.UUID         `json:"-"`
+	CreatedBy         *uuid.UUID        `json:"-"`
+	Name              string            `json:"name" binding:"required,min=1,max=255"`
+	BaseURL           string            `json:"base_url" binding:"required,url"`
+	AuthType          string            `json:"auth_type" binding:"required,oneof=bearer oauth2"`
+	TokenURL          *string           `json:"token_url,omitempty" binding:"omitempty,url"`
+	ClientID          *string           `json:"client_id,omitempty"`
+	Scopes            []string          `json:"scopes,omitempty"`
+	Credential        string            `json:"credential" binding:"required"` // Bearer token or OAuth client secret
+	UserMapping       map[string]string `json:"user_mapping,omitempty"`        // Defaults to DefaultUserMapping
+	SyncGroups        bool              `json:"sync_groups"`
+	DeprovisionAction string            `json:"deprovision_action,omitempty" binding:"omitempty,oneof=delete deactivate"`
+	Enabled           *bool             `json:"enabled,omitempty"` // Defaults to true
+}
+
+// UpdateConnectorRequest represents a request to update a connector
+type UpdateConnectorRequest struct {
+	Name              *string           `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	BaseURL           *string           `json:"base_url,omitempty" binding:"omitempty,url"`
+	AuthType          *string           `json:"auth_type,omitempty" binding:"omitempty,oneof=bearer oauth2"`
+	TokenURL          *string           `json:"token_url,omitempty" binding:"omitempty,url"`
+	ClientID          *string           `json:"client_id,omitempty"`
+	Scopes            []string          `json:"scopes,omitempty"`
+	Credential        *string           `json:"credential,omitempty"` // Replaces the stored credential
+	UserMapping       map[string]string `json:"user_mapping,omitempty"`
+	SyncGroups        *bool             `json:"sync_groups,omitempty"`
+	DeprovisionAction *string           `json:"deprovision_action,omitempty" binding:"omitempty,oneof=delete deactivate"`
+	Enabled           *bool             `json:"enabled,omitempty"`
+}
+
+// NewService creates a new outbound SCIM connector service. Credentials are
+// stored encrypted with encryptor.
+func NewService(connectorRepo interfaces.SCIMConnectorRepository, syncRepo interfaces.SCIMSyncRepository, userRepo interfaces.UserRepository, groupRepo interfaces.GroupRepository, roleRepo interfaces.RoleRepository, encryptor *encryption.Encryptor) *Service {
+	return &Service{
+		connectorRepo: connectorRepo,
+		syncRepo:      syncRepo,
+		userRepo:      userRepo,
+		groupRepo:     groupRepo,
+		roleRepo:      roleRepo,
+		encryptor:     encryptor,
+		httpClient:    newHTTPClient(publicAddress),
+		allowAddress:  publicAddress,
+		clients:       make(map[uuid.UUID]*cachedClient),
+	}
+}
+
+// Create creates a connector. It starts empty; run Reconcile to push the
+// tenant's existing users and groups.
+func (s *Service) Create(ctx context.Context, req *CreateConnectorRequest) (*models.SCIMConnector, error) {
+	connector := &models.SCIMConnector{
+		TenantID:          req.TenantID,
+		Name:              strings.TrimSpace(req.Name),
+		BaseURL:           req.BaseURL,
+		AuthType:          req.AuthType,
+		TokenURL:          req.TokenURL,
+		ClientID:          req.ClientID,
+		Scopes:            req.Scopes,
+		UserMapping:       req.UserMapping,
+		SyncGroups:        req.SyncGroups,
+		DeprovisionAction: req.DeprovisionAction,
+		Enabled:           req.Enabled == nil || *req.Enabled,
+		CreatedBy:         req.CreatedBy,
+	}
+	if connector.UserMapping == nil {
+		connector.UserMapping = DefaultUserMapping
+	}
+	if connector.DeprovisionAction == "" {
+		connector.DeprovisionAction = models.SCIMDeprovisionDelete
+	}
+	if err := s.validateConnector(ctx, connector); err != nil {
+		return nil, err
+	}
+	if err := s.setCredential(connector, req.Credential); err != nil {
+		return nil, err
+	}
+
+	if err := s.connectorRepo.Create(ctx, connector); err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return nil, fmt.Errorf("SCIM connector %s already exists", connector.Name)
+		}
+		return nil, fmt.Errorf("failed to create SCIM connector: %w", err)
+	}
+
+	return connector, nil
+}
+
+// GetByID retrieves a connector within a tenant
+func (s *Service) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnector, error) {
+	connector, err := s.connectorRepo.GetByID(ctx, id)
+	if err != nil || connector.TenantID != tenantID {
+		return nil, fmt.Errorf("SCIM connector not found")
+	}
+	return connector, nil
+}
+
+// List retrieves a tenant's connectors
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMConnector, error) {
+	connectors, err := s.connectorRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SCIM connectors: %w", err)
+	}
+	return connectors, nil
+}
+
+// Update updates a connector
+func (s *Service) Update(ctx context.Context, tenantID, id uuid.UUID, req *UpdateConnectorRequest) (*models.SCIMConnector, error) {
+	connector, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		connector.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.BaseURL != nil {
+		connector.BaseURL = *req.BaseURL
+	}
+	if req.AuthType != nil {
+		connector.AuthType = *req.AuthType
+	}
+	if req.TokenURL != nil {
+		connector.TokenURL = req.TokenURL
+	}
+	if req.ClientID != nil {
+		connector.ClientID = req.ClientID
+	}
+	if req.Scopes != nil {
+		connector.Scopes = req.Scopes
+	}
+	if req.UserMapping != nil {
+		connector.UserMapping = req.UserMapping
+	}
+	if req.SyncGroups != nil {
+		connector.SyncGroups = *req.SyncGroups
+	}
+	if req.DeprovisionAction != nil {
+		connector.DeprovisionAction = *req.DeprovisionAction
+	}
+	if req.Enabled != nil {
+		connector.Enabled = *req.Enabled
+	}
+	if err := s.validateConnector(ctx, connector); err != nil {
+		return nil, err
+	}
+	if req.Credential != nil {
+		if err := s.setCredential(connector, *req.Credential); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.connectorRepo.Update(ctx, connector); err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return nil, fmt.Errorf("SCIM connector %s already exists", connector.Name)
+		}
+		return nil, fmt.Errorf("failed to update SCIM connector: %w", err)
+	}
+
+	return connector, nil
+}
+
+// Delete deletes a connector and its queue. Resources already provisioned
+// downstream are left in place.
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnector, error) {
+	connector, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.connectorRepo.Delete(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to delete SCIM connector: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.clients, id)
+	s.mu.Unlock()
+
+	return connector, nil
+}
+
+// Test checks that the downstream service is reachable with the connector's credentials
+func (s *Service) Test(ctx context.Context, tenantID, id uuid.UUID) error {
+	connector, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	c, err := s.client(connector)
+	if err != nil {
+		return err
+	}
+	if err := c.ping(ctx); err != nil {
+		// Only the status is reported; the rest could reveal what is behind the URL
+		var remote *remoteError
+		if errors.As(err, &remote) {
+			return fmt.Errorf("connection test failed: %w", remote)
+		}
+		return fmt.Errorf("connection test failed: the downstream service could not be reached")
+	}
+	return nil
+}
+
+// Status summarizes a connector's queue and provisioned resources
+func (s *Service) Status(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnectorStatus, error) {
+	connector, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.syncRepo.CountByStatus(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SCIM connector status: %w", err)
+	}
+	users, err := s.syncRepo.ListRemote(ctx, id, models.SCIMResourceUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SCIM connector status: %w", err)
+	}
+	groups, err := s.syncRepo.ListRemote(ctx, id, models.SCIMResourceGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SCIM connector status: %w", err)
+	}
+
+	return &models.SCIMConnectorStatus{
+		ConnectorID:       id,
+		Enabled:           connector.Enabled,
+		Pending:           counts[models.SCIMSyncStatusPending],
+		Retrying:          counts["retrying"],
+		Failed:            counts[models.SCIMSyncStatusFailed],
+		Succeeded:         counts[models.SCIMSyncStatusSucceeded],
+		ProvisionedUsers:  len(users),
+		ProvisionedGroups: len(groups),
+		LastSyncAt:        connector.LastSyncAt,
+		LastReconciledAt:  connector.LastReconciledAt,
+		LastError:         connector.LastError,
+	}, nil
+}
+
+// Operations lists a connector's queued and recent operations
+func (s *Service) Operations(ctx context.Context, tenantID, id uuid.UUID, status *string, limit int) ([]*models.SCIMSyncOperation, error) {
+	if _, err := s.GetByID(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+	ops, err := s.syncRepo.List(ctx, id, &interfaces.SCIMSyncOperationFilters{Status: status, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SCIM sync operations: %w", err)
+	}
+	return ops, nil
+}
+
+// RetryFailed re-queues a connector's failed operations
+func (s *Service) RetryFailed(ctx context.Context, tenantID, id uuid.UUID) (int, error) {
+	if _, err := s.GetByID(ctx, tenantID, id); err != nil {
+		return 0, err
+	}
+	count, err := s.syncRepo.RetryFailed(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retry SCIM sync operations: %w", err)
+	}
+	return count, nil
+}
+
+// Reconcile queues every user, and group when the connector syncs groups,
+// of the connector's tenant, and the removal of resources provisioned for
+// users and groups that no longer exist. It returns the number of operations
+// queued.
+func (s *Service) Reconcile(ctx context.Context, tenantID, id uuid.UUID) (int, error) {
+	connector, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return 0, err
+	}
+	if !connector.Enabled {
+		return 0, fmt.Errorf("SCIM connector %s is disabled", connector.Name)
+	}
+	return s.reconcile(ctx, connector)
+}
+
+func (s *Service) reconcile(ctx context.Context, connector *models.SCIMConnector) (int, error) {
+	queued := 0
+
+	users := make(map[uuid.UUID]bool)
+	for offset := 0; ; offset += reconcilePageSize {
+		page, err := s.userRepo.List(ctx, connector.TenantID, &interfaces.UserFilters{PageSize: reconcilePageSize, Offset: &offset})
+		if err != nil {
+			return queued, fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, u := range page {
+			users[u.ID] = true
+			if err := s.enqueue(ctx, connector, models.SCIMResourceUser, u.ID, models.SCIMSyncActionUpsert); err != nil {
+				return queued, err
+			}
+			queued++
+		}
+		if len(page) < reconcilePageSize {
+			break
+		}
+	}
+	n, err := s.enqueueOrphans(ctx, connector, models.SCIMResourceUser, users)
+	queued += n
+	if err != nil {
+		return queued, err
+	}
+
+	if connector.SyncGroups {
+		groups := make(map[uuid.UUID]bool)
+		for offset := 0; ; offset += reconcilePageSize {
+			page, err := s.groupRepo.List(ctx, connector.TenantID, &interfaces.GroupFilters{PageSize: reconcilePageSize, Offset: &offset})
+			if err != nil {
+				return queued, fmt.Errorf("failed to list groups: %w", err)
+			}
+			for _, g := range page {
+				groups[g.ID] = true
+				if err := s.enqueue(ctx, connector, models.SCIMResourceGroup, g.ID, models.SCIMSyncActionUpsert); err != nil {
+					return queued, err
+				}
+				queued++
+			}
+			if len(page) < reconcilePageSize {
+				break
+			}
+		}
+		n, err := s.enqueueOrphans(ctx, connector, models.SCIMResourceGroup, groups)
+		queued += n
+		if err != nil {
+			return queued, err
+		}
+	}
+
+	now := time.Now()
+	connector.LastReconciledAt = &now
+	if err := s.connectorRepo.UpdateSyncState(ctx, connector); err != nil {
+		return queued, err
+	}
+
+	return queued, nil
+}
+
+// enqueueOrphans queues the removal of provisioned resources whose local
+// resource is not in existing
+func (s *Service) enqueueOrphans(ctx context.Context, connector *models.SCIMConnector, resourceType string, existing map[uuid.UUID]bool) (int, error) {
+	remotes, err := s.syncRepo.ListRemote(ctx, connector.ID, resourceType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list remote resources: %w", err)
+	}
+	queued := 0
+	for _, remote := range remotes {
+		if existing[remote.LocalID] {
+			continue
+		}
+		if err := s.enqueue(ctx, connector, resourceType, remote.LocalID, models.SCIMSyncActionDelete); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// HandleEvent queues the users and groups an audit event changed on each of
+// the tenant's enabled connectors. It implements outbox.Handler.
+// Role assignments are user events; role renames reach downstream
+// applications with the next reconciliation.
+func (s *Service) HandleEvent(ctx context.Context, event *models.AuditEvent) error {
+	if event.TenantID == nil || event.Target == nil || event.Result != models.ResultSuccess {
+		return nil
+	}
+
+	var resourceType, action string
+	switch event.Target.Type {
+	case "user":
+		resourceType, action = models.SCIMResourceUser, models.SCIMSyncActionUpsert
+		if event.EventType == models.EventTypeUserDeleted {
+			action = models.SCIMSyncActionDelete
+		}
+	case "group":
+		resourceType, action = models.SCIMResourceGroup, models.SCIMSyncActionUpsert
+		if event.EventType == models.EventTypeGroupDeleted {
+			action = models.SCIMSyncActionDelete
+		}
+	default:
+		return nil
+	}
+	if event.Target.ID == uuid.Nil {
+		return nil
+	}
+
+	connectors, err := s.connectorRepo.ListByTenant(ctx, *event.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list SCIM connectors: %w", err)
+	}
+	for _, connector := range connectors {
+		if !connector.Enabled || (resourceType == models.SCIMResourceGroup && !connector.SyncGroups) {
+			continue
+		}
+		if err := s.enqueue(ctx, connector, resourceType, event.Target.ID, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) enqueue(ctx context.Context, connector *models.SCIMConnector, resourceType string, resourceID uuid.UUID, action string) error {
+	op := &models.SCIMSyncOperation{
+		ConnectorID:  connector.ID,
+		TenantID:     connector.TenantID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+	}
+	if err := s.syncRepo.Enqueue(ctx, op); err != nil {
+		return fmt.Errorf("failed to queue SCIM sync operation: %w", err)
+	}
+	return nil
+}
+
+// ProcessDue pushes queued operations that are due and returns how many were
+// attempted. Once an attempt on a connector fails in a way that may clear up,
+// its remaining operations are deferred without spending an attempt, so one
+// unavailable application does not exhaust its queue or hold up others.
+func (s *Service) ProcessDue(ctx context.Context) (int, error) {
+	attempted := 0
+	for {
+		ops, err := s.syncRepo.ClaimDue(ctx, time.Now(), claimLease, claimBatchSize)
+		if err != nil {
+			return attempted, fmt.Errorf("failed to claim SCIM sync operations: %w", err)
+		}
+
+		connectors := make(map[uuid.UUID]*models.SCIMConnector)
+		deferredUntil := make(map[uuid.UUID]time.Time)
+		for _, op := range ops {
+			connector, ok := connectors[op.ConnectorID]
+			if !ok {
+				connector, err = s.connectorRepo.GetByID(ctx, op.ConnectorID)
+				if err != nil {
+					continue // Deleted; its queue goes with it
+				}
+				connectors[op.ConnectorID] = connector
+			}
+
+			if until, ok := deferredUntil[connector.ID]; ok {
+				op.NextAttemptAt = until
+				_ = s.syncRepo.Update(ctx, op)
+				continue
+			}
+
+			if !connector.Enabled {
+				// Kept as failed so that RetryFailed can push it once re-enabled
+				now, msg := time.Now(), "SCIM connector is disabled"
+				op.Status, op.LastError, op.CompletedAt = models.SCIMSyncStatusFailed, &msg, &now
+				_ = s.syncRepo.Update(ctx, op)
+				continue
+			}
+
+			syncErr := s.sync(ctx, connector, op)
+			attempted++
+			s.complete(ctx, connector, op, syncErr)
+			if syncErr != nil && op.Status == models.SCIMSyncStatusPending {
+				deferredUntil[connector.ID] = op.NextAttemptAt
+			}
+		}
+
+		for _, connector := range connectors {
+			_ = s.connectorRepo.UpdateSyncState(ctx, connector)
+		}
+		if len(ops) < claimBatchSize {
+			return attempted, nil
+		}
+	}
+}
+
+// complete records the outcome of an attempt on an operation and its connector
+func (s *Service) complete(ctx context.Context, connector *models.SCIMConnector, op *models.SCIMSyncOperation, syncErr error) {
+	now := time.Now()
+	op.Attempts++
+	if syncErr == nil {
+		op.Status = models.SCIMSyncStatusSucceeded
+		op.LastError = nil
+		op.CompletedAt = &now
+		connector.LastSyncAt = &now
+		connector.LastError = nil
+	} else {
+		msg := syncErr.Error()
+		op.LastError = &msg
+		connector.LastError = &msg
+		if op.Attempts >= MaxAttempts || !isRetryable(syncErr) {
+			op.Status = models.SCIMSyncStatusFailed
+			op.CompletedAt = &now
+		} else {
+			op.NextAttemptAt = now.Add(backoff(op.Attempts))
+		}
+	}
+	_ = s.syncRepo.Update(ctx, op)
+}
+
+// backoff returns the delay before the next attempt after the given number of attempts
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// sync pushes one operation downstream
+func (s *Service) sync(ctx context.Context, connector *models.SCIMConnector, op *models.SCIMSyncOperation) error {
+	c, err := s.client(connector)
+	if err != nil {
+		return err
+	}
+	if op.ResourceType == models.SCIMResourceGroup {
+		if op.Action == models.SCIMSyncActionDelete {
+			return s.deleteGroup(ctx, connector, c, op.ResourceID)
+		}
+		return s.pushGroup(ctx, connector, c, op.ResourceID)
+	}
+	if op.Action == models.SCIMSyncActionDelete {
+		return s.deprovisionUser(ctx, connector, c, op.ResourceID)
+	}
+	_, err = s.pushUser(ctx, connector, c, op.ResourceID)
+	return err
+}
+
+// pushUser creates or replaces a user downstream and returns its downstream
+// ID. A user that no longer exists is deprovisioned instead. An account with
+// the same userName that was not provisioned by the connector is adopted.
+func (s *Service) pushUser(ctx context.Context, connector *models.SCIMConnector, c *client, userID uuid.UUID) (string, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if err != nil || u.TenantID == nil || *u.TenantID != connector.TenantID || u.DeletedAt != nil {
+		return "", s.deprovisionUser(ctx, connector, c, userID)
+	}
+
+	var roles []string
+	if mapsSource(connector.UserMapping, "roles") {
+		userRoles, err := s.roleRepo.GetUserRoles(ctx, u.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get user roles: %w", err)
+		}
+		for _, r := range userRoles {
+			roles = append(roles, r.Name)
+		}
+	}
+
+	doc := buildUserDocument(u, roles, connector.UserMapping)
+	userName, _ := doc["userName"].(string)
+	return s.push(ctx, connector, c, models.SCIMResourceUser, u.ID, doc, "userName", userName)
+}
+
+// deprovisionUser deletes or deactivates a provisioned user downstream
+func (s *Service) deprovisionUser(ctx context.Context, connector *models.SCIMConnector, c *client, userID uuid.UUID) error {
+	remote, err := s.remote(ctx, connector, models.SCIMResourceUser, userID)
+	if err != nil || remote == nil {
+		return err
+	}
+
+	if connector.DeprovisionAction == models.SCIMDeprovisionDeactivate {
+		if err := c.deactivate(ctx, remote.RemoteID); err != nil && !isNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := c.delete(ctx, models.SCIMResourceUser, remote.RemoteID); err != nil {
+		return err
+	}
+	return s.syncRepo.DeleteRemote(ctx, connector.ID, models.SCIMResourceUser, userID)
+}
+
+// pushGroup creates or replaces a group downstream with its direct members.
+// Member users not yet provisioned are pushed first; member groups are
+// included once they have been provisioned.
+func (s *Service) pushGroup(ctx context.Context, connector *models.SCIMConnector, c *client, groupID uuid.UUID) error {
+	g, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return fmt.Errorf("failed to get group: %w", err)
+	}
+	if err != nil || g.TenantID != connector.TenantID || !g.IsActive() {
+		return s.deleteGroup(ctx, connector, c, groupID)
+	}
+
+	users, err := s.groupRepo.ListMembers(ctx, g.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list group members: %w", err)
+	}
+	var members []string
+	for _, member := range users {
+		remote, err := s.remote(ctx, connector, models.SCIMResourceUser, member.UserID)
+		if err != nil {
+			return err
+		}
+		if remote != nil {
+			members = append(members, remote.RemoteID)
+			continue
+		}
+		remoteID, err := s.pushUser(ctx, connector, c, member.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to provision member %s: %w", member.Username, err)
+		}
+		if remoteID != "" {
+			members = append(members, remoteID)
+		}
+	}
+
+	memberGroups, err := s.groupRepo.ListMemberGroups(ctx, g.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list member groups: %w", err)
+	}
+	for _, member := range memberGroups {
+		remote, err := s.remote(ctx, connector, models.SCIMResourceGroup, member.ID)
+		if err != nil {
+			return err
+		}
+		if remote != nil {
+			members = append(members, remote.RemoteID)
+		}
+	}
+
+	_, err = s.push(ctx, connector, c, models.SCIMResourceGroup, g.ID, buildGroupDocument(g, members), "displayName", g.Name)
+	return err
+}
+
+// deleteGroup deletes a provisioned group downstream
+func (s *Service) deleteGroup(ctx context.Context, connector *models.SCIMConnector, c *client, groupID uuid.UUID) error {
+	remote, err := s.remote(ctx, connector, models.SCIMResourceGroup, groupID)
+	if err != nil || remote == nil {
+		return err
+	}
+	if err := c.delete(ctx, models.SCIMResourceGroup, remote.RemoteID); err != nil {
+		return err
+	}
+	return s.syncRepo.DeleteRemote(ctx, connector.ID, models.SCIMResourceGroup, groupID)
+}
+
+// push replaces the downstream resource linked to a local resource, or
+// creates it when there is none. Before creating, a downstream resource whose
+// matchAttribute equals matchValue is looked up and adopted.
+func (s *Service) push(ctx context.Context, connector *models.SCIMConnector, c *client, resourceType string, localID uuid.UUID, doc map[string]interface{}, matchAttribute, matchValue string) (string, error) {
+	remote, err := s.remote(ctx, connector, resourceType, localID)
+	if err != nil {
+		return "", err
+	}
+
+	remoteID := ""
+	if remote != nil {
+		err := c.replace(ctx, resourceType, remote.RemoteID, doc)
+		if err == nil {
+			remoteID = remote.RemoteID
+		} else if !isNotFound(err) {
+			return "", err
+		}
+		// Removed downstream; provision it again
+	}
+
+	if remoteID == "" {
+		id, found, err := c.find(ctx, resourceType, matchAttribute, matchValue)
+		if err != nil {
+			return "", err
+		}
+		if found {
+			if err := c.replace(ctx, resourceType, id, doc); err != nil {
+				return "", err
+			}
+		} else if id, err = c.create(ctx, resourceType, doc); err != nil {
+			return "", err
+		}
+		remoteID = id
+	}
+
+	err = s.syncRepo.SaveRemote(ctx, &models.SCIMRemoteResource{
+		ConnectorID:  connector.ID,
+		ResourceType: resourceType,
+		LocalID:      localID,
+		RemoteID:     remoteID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return remoteID, nil
+}
+
+// remote returns the downstream resource linked to a local resource, or nil
+func (s *Service) remote(ctx context.Context, connector *models.SCIMConnector, resourceType string, localID uuid.UUID) (*models.SCIMRemoteResource, error) {
+	remote, err := s.syncRepo.GetRemote(ctx, connector.ID, resourceType, localID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return remote, nil
+}
+
+// client returns the SCIM client of a connector
+func (s *Service) client(connector *models.SCIMConnector) (*client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.clients[connector.ID]; ok && cached.updatedAt.Equal(connector.UpdatedAt) {
+		return cached.client, nil
+	}
+
+	credential, err := s.encryptor.Decrypt(connector.CredentialEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt connector credential: %w", err)
+	}
+
+	var token tokenSource = staticToken(credential)
+	if connector.AuthType == models.SCIMConnectorAuthOAuth2 {
+		token = &clientCredentials{
+			tokenURL:     *connector.TokenURL,
+			clientID:     *connector.ClientID,
+			clientSecret: credential,
+			scopes:       connector.Scopes,
+			httpClient:   s.httpClient,
+		}
+	}
+
+	c := &client{
+		baseURL:    strings.TrimRight(connector.BaseURL, "/"),
+		httpClient: s.httpClient,
+		token:      token,
+	}
+	s.clients[connector.ID] = &cachedClient{updatedAt: connector.UpdatedAt, client: c}
+	return c, nil
+}
+
+func (s *Service) setCredential(connector *models.SCIMConnector, credential string) error {
+	if strings.TrimSpace(credential) == "" {
+		return fmt.Errorf("credential is required")
+	}
+	encrypted, err := s.encryptor.Encrypt(credential)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt connector credential: %w", err)
+	}
+	connector.CredentialEncrypted = encrypted
+	return nil
+}
+
+// validateConnector checks a connector's settings
+func (s *Service) validateConnector(ctx context.Context, connector *models.SCIMConnector) error {
+	if connector.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := validateServiceURL(ctx, "base_url", connector.BaseURL, s.allowAddress); err != nil {
+		return err
+	}
+
+	switch connector.AuthType {
+	case models.SCIMConnectorAuthBearer:
+	case models.SCIMConnectorAuthOAuth2:
+		if connector.TokenURL == nil || connector.ClientID == nil || *connector.ClientID == "" {
+			return fmt.Errorf("oauth2 connectors require token_url and client_id")
+		}
+		if err := validateServiceURL(ctx, "token_url", *connector.TokenURL, s.allowAddress); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported auth_type %q", connector.AuthType)
+	}
+
+	switch connector.DeprovisionAction {
+	case models.SCIMDeprovisionDelete, models.SCIMDeprovisionDeactivate:
+	default:
+		return fmt.Errorf("unsupported deprovision_action %q", connector.DeprovisionAction)
+	}
+
+	return validateUserMapping(connector.UserMapping)
+}
+
+// ReconcileDue reconciles enabled connectors that have not been reconciled
+// within ReconcileInterval and purges old completed operations. It returns
+// the number of connectors reconciled.
+func (s *Service) ReconcileDue(ctx context.Context) (int, error) {
+	connectors, err := s.connectorRepo.ListEnabled(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list SCIM connectors: %w", err)
+	}
+
+	count := 0
+	for _, connector := range connectors {
+		if connector.LastReconciledAt != nil && time.Since(*connector.LastReconciledAt) < ReconcileInterval {
+			continue
+		}
+		if _, err := s.reconcile(ctx, connector); err != nil {
+			return count, fmt.Errorf("failed to reconcile SCIM connector %s: %w", connector.ID, err)
+		}
+		count++
+	}
+
+	if _, err := s.syncRepo.DeleteCompletedBefore(ctx, time.Now().Add(-completedRetention)); err != nil {
+		return count, err
+	}
+	return count, nil
+}
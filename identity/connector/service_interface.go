@@ -0,0 +1,22 @@
+package connector
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines the interface for outbound SCIM connector operations
+type ServiceInterface interface {
+	Create(ctx context.Context, req *CreateConnectorRequest) (*models.SCIMConnector, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnector, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMConnector, error)
+	Update(ctx context.Context, tenantID, id uuid.UUID, req *UpdateConnectorRequest) (*models.SCIMConnector, error)
+	Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnector, error)
+	Test(ctx context.Context, tenantID, id uuid.UUID) error
+	Status(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMConnectorStatus, error)
+	Operations(ctx context.Context, tenantID, id uuid.UUID, status *string, limit int) ([]*models.SCIMSyncOperation, error)
+	RetryFailed(ctx context.Context, tenantID, id uuid.UUID) (int, error)
+	Reconcile(ctx context.Context, tenantID, id uuid.UUID) (int, error)
+}
@@ -0,0 +1,813 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/security/encryption"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryConnectorRepository is an in-memory interfaces.SCIMConnectorRepository
+type memoryConnectorRepository struct {
+	connectors map[uuid.UUID]*models.SCIMConnector
+}
+
+func (r *memoryConnectorRepository) Create(ctx context.Context, connector *models.SCIMConnector) error {
+	connector.ID = uuid.New()
+	connector.UpdatedAt = time.Now()
+	r.connectors[connector.ID] = connector
+	return nil
+}
+
+func (r *memoryConnectorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMConnector, error) {
+	if connector, ok := r.connectors[id]; ok {
+		copied := *connector
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("SCIM connector not found")
+}
+
+func (r *memoryConnectorRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMConnector, error) {
+	var out []*models.SCIMConnector
+	for _, connector := range r.connectors {
+		if connector.TenantID == tenantID {
+			copied := *connector
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryConnectorRepository) ListEnabled(ctx context.Context) ([]*models.SCIMConnector, error) {
+	var out []*models.SCIMConnector
+	for _, connector := range r.connectors {
+		if connector.Enabled {
+			copied := *connector
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryConnectorRepository) Update(ctx context.Context, connector *models.SCIMConnector) error {
+	connector.UpdatedAt = time.Now()
+	copied := *connector
+	r.connectors[connector.ID] = &copied
+	return nil
+}
+
+func (r *memoryConnectorRepository) UpdateSyncState(ctx context.Context, connector *models.SCIMConnector) error {
+	stored := r.connectors[connector.ID]
+	stored.LastSyncAt, stored.LastReconciledAt, stored.LastError = connector.LastSyncAt, connector.LastReconciledAt, connector.LastError
+	return nil
+}
+
+func (r *memoryConnectorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.connectors, id)
+	return nil
+}
+
+// memorySyncRepository is an in-memory interfaces.SCIMSyncRepository
+type memorySyncRepository struct {
+	ops     []*models.SCIMSyncOperation
+	remotes map[string]*models.SCIMRemoteResource
+}
+
+func newMemorySyncRepository() *memorySyncRepository {
+	return &memorySyncRepository{remotes: make(map[string]*models.SCIMRemoteResource)}
+}
+
+func (r *memorySyncRepository) Enqueue(ctx context.Context, op *models.SCIMSyncOperation) error {
+	now := time.Now()
+	for _, queued := range r.ops {
+		if queued.Status == models.SCIMSyncStatusPending && queued.ConnectorID == op.ConnectorID &&
+			queued.ResourceType == op.ResourceType && queued.ResourceID == op.ResourceID {
+			queued.Action, queued.NextAttemptAt, queued.UpdatedAt = op.Action, now, now
+			*op = *queued
+			return nil
+		}
+	}
+	op.ID = uuid.New()
+	op.Status = models.SCIMSyncStatusPending
+	op.NextAttemptAt, op.CreatedAt, op.UpdatedAt = now, now, now
+	stored := *op
+	r.ops = append(r.ops, &stored)
+	return nil
+}
+
+func (r *memorySyncRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.SCIMSyncOperation, error) {
+	var claimed []*models.SCIMSyncOperation
+	for _, op := range r.ops {
+		if len(claimed) < limit && op.Status == models.SCIMSyncStatusPending && !op.NextAttemptAt.After(now) {
+			op.NextAttemptAt = now.Add(lease)
+			copied := *op
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *memorySyncRepository) Update(ctx context.Context, op *models.SCIMSyncOperation) error {
+	for _, stored := range r.ops {
+		if stored.ID == op.ID && stored.UpdatedAt.Equal(op.UpdatedAt) {
+			op.UpdatedAt = time.Now()
+			*stored = *op
+		}
+	}
+	return nil
+}
+
+func (r *memorySyncRepository) List(ctx context.Context, connectorID uuid.UUID, filters *interfaces.SCIMSyncOperationFilters) ([]*models.SCIMSyncOperation, error) {
+	var out []*models.SCIMSyncOperation
+	for _, op := range r.ops {
+		if op.ConnectorID == connectorID && (filters.Status == nil || *filters.Status == op.Status) {
+			out = append(out, op)
+		}
+	}
+	return out, nil
+}
+
+func (r *memorySyncRepository) CountByStatus(ctx context.Context, connectorID uuid.UUID) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, op := range r.ops {
+		if op.ConnectorID != connectorID {
+			continue
+		}
+		if op.Status == models.SCIMSyncStatusPending && op.Attempts > 0 {
+			counts["retrying"]++
+		} else {
+			counts[op.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *memorySyncRepository) RetryFailed(ctx context.Context, connectorID uuid.UUID) (int, error) {
+	count := 0
+	for _, op := range r.ops {
+		if op.ConnectorID == connectorID && op.Status == models.SCIMSyncStatusFailed {
+			op.Status, op.Attempts, op.NextAttemptAt, op.CompletedAt = models.SCIMSyncStatusPending, 0, time.Now(), nil
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memorySyncRepository) DeleteCompletedBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+func remoteKey(connectorID uuid.UUID, resourceType string, localID uuid.UUID) string {
+	return connectorID.String() + resourceType + localID.String()
+}
+
+func (r *memorySyncRepository) GetRemote(ctx context.Context, connectorID uuid.UUID, resourceType string, localID uuid.UUID) (*models.SCIMRemoteResource, error) {
+	if remote, ok := r.remotes[remoteKey(connectorID, resourceType, localID)]; ok {
+		return remote, nil
+	}
+	return nil, fmt.Errorf("remote resource not found")
+}
+
+func (r *memorySyncRepository) ListRemote(ctx context.Context, connectorID uuid.UUID, resourceType string) ([]*models.SCIMRemoteResource, error) {
+	var out []*models.SCIMRemoteResource
+	for _, remote := range r.remotes {
+		if remote.ConnectorID == connectorID && remote.ResourceType == resourceType {
+			out = append(out, remote)
+		}
+	}
+	return out, nil
+}
+
+func (r *memorySyncRepository) SaveRemote(ctx context.Context, remote *models.SCIMRemoteResource) error {
+	r.remotes[remoteKey(remote.ConnectorID, remote.ResourceType, remote.LocalID)] = remote
+	return nil
+}
+
+func (r *memorySyncRepository) DeleteRemote(ctx context.Context, connectorID uuid.UUID, resourceType string, localID uuid.UUID) error {
+	delete(r.remotes, remoteKey(connectorID, resourceType, localID))
+	return nil
+}
+
+// stubUserRepository serves a fixed set of users
+type stubUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *stubUserRepository) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.UserFilters) ([]*models.User, error) {
+	if filters.Offset != nil && *filters.Offset > 0 {
+		return nil, nil
+	}
+	var users []*models.User
+	for _, u := range r.users {
+		if *u.TenantID == tenantID {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// stubGroupRepository serves fixed groups and memberships
+type stubGroupRepository struct {
+	interfaces.GroupRepository
+	groups  map[uuid.UUID]*models.Group
+	members map[uuid.UUID][]*models.GroupMember
+}
+
+func (r *stubGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	if g, ok := r.groups[id]; ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("group not found")
+}
+
+func (r *stubGroupRepository) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) ([]*models.Group, error) {
+	if filters.Offset != nil && *filters.Offset > 0 {
+		return nil, nil
+	}
+	var groups []*models.Group
+	for _, g := range r.groups {
+		if g.TenantID == tenantID {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+func (r *stubGroupRepository) ListMembers(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMember, error) {
+	return r.members[groupID], nil
+}
+
+func (r *stubGroupRepository) ListMemberGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	return nil, nil
+}
+
+// stubRoleRepository serves fixed role assignments
+type stubRoleRepository struct {
+	interfaces.RoleRepository
+	userRoles map[uuid.UUID][]*models.Role
+}
+
+func (r *stubRoleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error) {
+	return r.userRoles[userID], nil
+}
+
+// scimStandIn is a minimal downstream SCIM service
+type scimStandIn struct {
+	mu        sync.Mutex
+	token     string
+	resources map[string]map[string]map[string]interface{} // endpoint -> id -> resource
+	requests  []string
+	failWith  int // Status returned to every request while non-zero
+	nextID    int
+}
+
+func newSCIMStandIn(token string) (*scimStandIn, *httptest.Server) {
+	standIn := &scimStandIn{
+		token:     token,
+		resources: map[string]map[string]map[string]interface{}{"Users": {}, "Groups": {}},
+	}
+	return standIn, httptest.NewTLSServer(standIn)
+}
+
+func (s *scimStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	if r.Header.Get("Authorization") != "Bearer "+s.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if s.failWith != 0 {
+		w.WriteHeader(s.failWith)
+		_, _ = w.Write([]byte(`{"detail":"stand-in failure"}`))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] == "ServiceProviderConfig" {
+		_, _ = w.Write([]byte(`{}`))
+		return
+	}
+	resources := s.resources[parts[0]]
+
+	switch {
+	case r.Method == http.MethodPost:
+		var doc map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&doc)
+		s.nextID++
+		doc["id"] = fmt.Sprintf("remote-%d", s.nextID)
+		resources[doc["id"].(string)] = doc
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(doc)
+	case r.Method == http.MethodGet && len(parts) == 1:
+		// Supports the `<attribute> eq "<value>"` filters the connector sends
+		attribute, value, _ := strings.Cut(r.URL.Query().Get("filter"), ` eq `)
+		value = strings.Trim(value, `"`)
+		var found []map[string]interface{}
+		for _, doc := range resources {
+			if doc[attribute] == value {
+				found = append(found, doc)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"Resources": found})
+	case len(parts) == 2 && resources[parts[1]] == nil:
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPut:
+		var doc map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&doc)
+		doc["id"] = parts[1]
+		resources[parts[1]] = doc
+		_ = json.NewEncoder(w).Encode(doc)
+	case r.Method == http.MethodPatch:
+		resources[parts[1]]["active"] = false
+		_ = json.NewEncoder(w).Encode(resources[parts[1]])
+	case r.Method == http.MethodDelete:
+		delete(resources, parts[1])
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *scimStandIn) users() map[string]map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resources["Users"]
+}
+
+type connectorFixture struct {
+	service    *Service
+	connectors *memoryConnectorRepository
+	queue      *memorySyncRepository
+	users      *stubUserRepository
+	groups     *stubGroupRepository
+	standIn    *scimStandIn
+	server     *httptest.Server
+	tenantID   uuid.UUID
+}
+
+func newConnectorFixture(t *testing.T) *connectorFixture {
+	t.Helper()
+	encryptor, err := encryption.NewEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	standIn, server := newSCIMStandIn("downstream-token")
+	t.Cleanup(server.Close)
+
+	f := &connectorFixture{
+		connectors: &memoryConnectorRepository{connectors: make(map[uuid.UUID]*models.SCIMConnector)},
+		queue:      newMemorySyncRepository(),
+		users:      &stubUserRepository{users: make(map[uuid.UUID]*models.User)},
+		groups:     &stubGroupRepository{groups: make(map[uuid.UUID]*models.Group), members: make(map[uuid.UUID][]*models.GroupMember)},
+		standIn:    standIn,
+		server:     server,
+		tenantID:   uuid.New(),
+	}
+	f.service = NewService(f.connectors, f.queue, f.users, f.groups, &stubRoleRepository{}, encryptor)
+
+	// The test servers listen on loopback with a self-signed certificate
+	allowAll := func(net.IP) bool { return true }
+	f.service.allowAddress = allowAll
+	f.service.httpClient = newHTTPClient(allowAll)
+	f.service.httpClient.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+	return f
+}
+
+func (f *connectorFixture) createConnector(t *testing.T, req *CreateConnectorRequest) *models.SCIMConnector {
+	t.Helper()
+	req.TenantID = f.tenantID
+	if req.Name == "" {
+		req.Name = "downstream"
+	}
+	if req.BaseURL == "" {
+		req.BaseURL = f.server.URL
+	}
+	if req.AuthType == "" {
+		req.AuthType = models.SCIMConnectorAuthBearer
+		req.Credential = "downstream-token"
+	}
+	connector, err := f.service.Create(context.Background(), req)
+	require.NoError(t, err)
+	return connector
+}
+
+func (f *connectorFixture) addUser(username string) *models.User {
+	first := strings.ToUpper(username[:1]) + username[1:]
+	u := &models.User{
+		ID:        uuid.New(),
+		TenantID:  &f.tenantID,
+		Username:  username,
+		Email:     username + "@example.com",
+		FirstName: &first,
+		Status:    models.UserStatusActive,
+		Metadata:  map[string]interface{}{models.UserMetadataDepartment: "Sales"},
+	}
+	f.users.users[u.ID] = u
+	return u
+}
+
+func (f *connectorFixture) event(eventType, targetType string, id uuid.UUID) *models.AuditEvent {
+	return &models.AuditEvent{
+		EventType: eventType,
+		TenantID:  &f.tenantID,
+		Target:    &models.AuditTarget{Type: targetType, ID: id},
+		Result:    models.ResultSuccess,
+	}
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	f := newConnectorFixture(t)
+	ctx := context.Background()
+
+	connector := f.createConnector(t, &CreateConnectorRequest{})
+	assert.NotEmpty(t, connector.CredentialEncrypted)
+	assert.NotContains(t, connector.CredentialEncrypted, "downstream-token", "credentials are stored encrypted")
+	assert.Equal(t, DefaultUserMapping, connector.UserMapping)
+	assert.Equal(t, models.SCIMDeprovisionDelete, connector.DeprovisionAction)
+	assert.True(t, connector.Enabled)
+
+	tests := []struct {
+		name    string
+		req     CreateConnectorRequest
+		wantErr string
+	}{
+		{"non-http URL", CreateConnectorRequest{BaseURL: "ftp://example.com/scim"}, "base_url must be an https URL"},
+		{"plain http URL", CreateConnectorRequest{BaseURL: "http://example.com/scim"}, "base_url must be an https URL"},
+		{"oauth2 without client", CreateConnectorRequest{AuthType: "oauth2", Credential: "secret"}, "require token_url and client_id"},
+		{"mapping without userName", CreateConnectorRequest{UserMapping: map[string]string{"displayName": "display_name"}}, "must map userName"},
+		{"unknown source", CreateConnectorRequest{UserMapping: map[string]string{"userName": "password"}}, `invalid user mapping source "password"`},
+		{"invalid target", CreateConnectorRequest{UserMapping: map[string]string{"userName": "username", "a.b.c": "email"}}, `invalid user mapping target "a.b.c"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.TenantID, req.Name = f.tenantID, tt.name
+			if req.BaseURL == "" {
+				req.BaseURL = f.server.URL
+			}
+			if req.AuthType == "" {
+				req.AuthType, req.Credential = "bearer", "token"
+			}
+			_, err := f.service.Create(ctx, &req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := f.service.GetByID(ctx, uuid.New(), connector.ID)
+	assert.EqualError(t, err, "SCIM connector not found", "connectors of other tenants are not visible")
+}
+
+func TestService_EventDrivenSync(t *testing.T) {
+	f := newConnectorFixture(t)
+	ctx := context.Background()
+	connector := f.createConnector(t, &CreateConnectorRequest{SyncGroups: true, UserMapping: map[string]string{
+		"userName":   "username",
+		"externalId": "id",
+		"emails":     "email",
+		"active":     "active",
+		"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department": "metadata.department",
+	}})
+
+	alice := f.addUser("alice")
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeUserCreated, "user", alice.ID)))
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeRoleAssigned, "user", alice.ID)))
+	require.Len(t, f.queue.ops, 1, "changes to the same user are coalesced")
+
+	count, err := f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.Len(t, f.standIn.users(), 1)
+	remote := f.standIn.users()["remote-1"]
+	assert.Equal(t, "alice", remote["userName"])
+	assert.Equal(t, alice.ID.String(), remote["externalId"])
+	assert.Equal(t, true, remote["active"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"value": "alice@example.com", "type": "work", "primary": true}}, remote["emails"])
+	assert.Equal(t, map[string]interface{}{"department": "Sales"}, remote[models.SCIMEnterpriseUserSchema])
+	assert.Equal(t, models.SCIMSyncStatusSucceeded, f.queue.ops[0].Status)
+
+	// Updates replace the provisioned user
+	alice.Status = models.UserStatusSuspended
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeUserUpdated, "user", alice.ID)))
+	_, err = f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, false, f.standIn.users()["remote-1"]["active"])
+	assert.Contains(t, f.standIn.requests, "PUT /Users/remote-1")
+
+	// Group changes push the group with the downstream IDs of its members,
+	// provisioning members that are not there yet
+	bob := f.addUser("bob")
+	group := &models.Group{ID: uuid.New(), TenantID: f.tenantID, Name: "engineering"}
+	f.groups.groups[group.ID] = group
+	f.groups.members[group.ID] = []*models.GroupMember{{UserID: alice.ID, Username: "alice"}, {UserID: bob.ID, Username: "bob"}}
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeGroupMemberAdded, "group", group.ID)))
+	_, err = f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	require.Len(t, f.standIn.resources["Groups"], 1)
+	for _, g := range f.standIn.resources["Groups"] {
+		assert.Equal(t, "engineering", g["displayName"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"value": "remote-1"}, map[string]interface{}{"value": "remote-2"}}, g["members"])
+	}
+
+	// Deleted users are deleted downstream
+	delete(f.users.users, alice.ID)
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeUserDeleted, "user", alice.ID)))
+	_, err = f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, f.standIn.users(), "remote-1")
+	_, err = f.queue.GetRemote(ctx, connector.ID, models.SCIMResourceUser, alice.ID)
+	assert.Error(t, err)
+
+	status, err := f.service.Status(ctx, f.tenantID, connector.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.ProvisionedUsers)
+	assert.Equal(t, 1, status.ProvisionedGroups)
+	assert.NotNil(t, status.LastSyncAt)
+	assert.Nil(t, status.LastError)
+}
+
+func TestService_IgnoresOtherEvents(t *testing.T) {
+	f := newConnectorFixture(t)
+	ctx := context.Background()
+	f.createConnector(t, &CreateConnectorRequest{})
+	disabled := false
+	f.createConnector(t, &CreateConnectorRequest{Name: "disabled", Enabled: &disabled})
+
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeGroupCreated, "group", uuid.New())), "groups are not synced")
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypePolicyUpdated, "policy", uuid.New())))
+	failed := f.event(models.EventTypeUserUpdated, "user", uuid.New())
+	failed.Result = models.ResultFailure
+	require.NoError(t, f.service.HandleEvent(ctx, failed))
+	assert.Empty(t, f.queue.ops)
+
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeUserUpdated, "user", uuid.New())))
+	assert.Len(t, f.queue.ops, 1, "disabled connectors are skipped")
+}
+
+func TestService_RetriesWithBackoff(t *testing.T) {
+	f := newConnectorFixture(t)
+	ctx := context.Background()
+	connector := f.createConnector(t, &CreateConnectorRequest{})
+	alice, bob := f.addUser("alice"), f.addUser("bob")
+
+	f.standIn.failWith = http.StatusServiceUnavailable
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeUserCreated, "user", alice.ID)))
+	require.NoError(t, f.service.HandleEvent(ctx, f.event(models.EventTypeUserCreated, "user", bob.ID)))
+
+	count, err := f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "the rest of an unavailable connector's queue is deferred")
+	for _, op := range f.queue.ops {
+		assert.Equal(t, models.SCIMSyncStatusPending, op.Status)
+		assert.WithinDuration(t, time.Now().Add(baseBackoff), op.NextAttemptAt, 5*time.Second)
+	}
+	assert.Equal(t, 1, f.queue.ops[0].Attempts)
+	assert.Equal(t, 0, f.queue.ops[1].Attempts, "deferred operations keep their attempts")
+	assert.Contains(t, *f.queue.ops[0].LastError, "503")
+
+	count, err = f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count, "nothing is due before the backoff elapses")
+
+	status, err := f.service.Status(ctx, f.tenantID, connector.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Retrying)
+	assert.Equal(t, 1, status.Pending)
+	assert.Equal(t, "downstream SCIM service returned 503", *status.LastError)
+
+	// Rejected requests are not retried
+	f.standIn.failWith = http.StatusBadRequest
+	for _, op := range f.queue.ops {
+		op.NextAttemptAt = time.Now()
+	}
+	_, err = f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.SCIMSyncStatusFailed, f.queue.ops[0].Status)
+	assert.Equal(t, models.SCIMSyncStatusFailed, f.queue.ops[1].Status)
+
+	f.standIn.failWith = 0
+	retried, err := f.service.RetryFailed(ctx, f.tenantID, connector.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, retried)
+	_, err = f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Len(t, f.standIn.users(), 2)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1))
+	assert.Equal(t, time.Minute, backoff(2))
+	assert.Equal(t, 4*time.Minute, backoff(4))
+	assert.Equal(t, time.Hour, backoff(MaxAttempts))
+}
+
+func TestService_Reconcile(t *testing.T) {
+	f := newConnectorFixture(t)
+	ctx := context.Background()
+	connector := f.createConnector(t, &CreateConnectorRequest{DeprovisionAction: models.SCIMDeprovisionDeactivate})
+
+	alice := f.addUser("alice")
+	// alice already has an account downstream, which is adopted
+	f.standIn.resources["Users"]["existing"] = map[string]interface{}{"id": "existing", "userName": "alice"}
+	// A user provisioned earlier that has since been deleted
+	gone := uuid.New()
+	f.standIn.resources["Users"]["gone"] = map[string]interface{}{"id": "gone", "userName": "gone", "active": true}
+	require.NoError(t, f.queue.SaveRemote(ctx, &models.SCIMRemoteResource{ConnectorID: connector.ID, ResourceType: models.SCIMResourceUser, LocalID: gone, RemoteID: "gone"}))
+
+	queued, err := f.service.Reconcile(ctx, f.tenantID, connector.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, queued)
+	assert.NotNil(t, f.connectors.connectors[connector.ID].LastReconciledAt)
+
+	_, err = f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	users := f.standIn.users()
+	require.Len(t, users, 2)
+	assert.Equal(t, alice.ID.String(), users["existing"]["externalId"])
+	assert.Equal(t, false, users["gone"]["active"], "the deprovision action is deactivate")
+
+	reconciled, err := f.service.ReconcileDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reconciled, "the connector was reconciled recently")
+}
+
+func TestService_OAuth2ClientCredentials(t *testing.T) {
+	f := newConnectorFixture(t)
+	ctx := context.Background()
+
+	var tokenRequests int
+	tokenServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if clientID != "arauth" || secret != "client-secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		tokenRequests++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "downstream-token", "expires_in": 3600})
+	}))
+	defer tokenServer.Close()
+
+	tokenURL, clientID := tokenServer.URL, "arauth"
+	connector := f.createConnector(t, &CreateConnectorRequest{
+		AuthType:   models.SCIMConnectorAuthOAuth2,
+		TokenURL:   &tokenURL,
+		ClientID:   &clientID,
+		Credential: "client-secret",
+	})
+
+	require.NoError(t, f.service.Test(ctx, f.tenantID, connector.ID))
+	f.addUser("alice")
+	_, err := f.service.Reconcile(ctx, f.tenantID, connector.ID)
+	require.NoError(t, err)
+	_, err = f.service.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Len(t, f.standIn.users(), 1)
+	assert.Equal(t, 1, tokenRequests, "access tokens are reused until they expire")
+}
+
+func TestService_Test_HidesResponseBody(t *testing.T) {
+	f := newConnectorFixture(t)
+	ctx := context.Background()
+
+	tokenServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client","internal":"db01.corp:5432"}`))
+	}))
+	defer tokenServer.Close()
+
+	tokenURL, clientID := tokenServer.URL, "arauth"
+	connector := f.createConnector(t, &CreateConnectorRequest{
+		AuthType:   models.SCIMConnectorAuthOAuth2,
+		TokenURL:   &tokenURL,
+		ClientID:   &clientID,
+		Credential: "client-secret",
+	})
+	err := f.service.Test(ctx, f.tenantID, connector.ID)
+	assert.EqualError(t, err, "connection test failed: token endpoint returned 401")
+
+	f.standIn.failWith = http.StatusForbidden
+	bearer := f.createConnector(t, &CreateConnectorRequest{Name: "bearer"})
+	err = f.service.Test(ctx, f.tenantID, bearer.ID)
+	assert.EqualError(t, err, "connection test failed: downstream SCIM service returned 403")
+}
+
+func TestService_Test_Unreachable(t *testing.T) {
+	f := newConnectorFixture(t)
+	connector := f.createConnector(t, &CreateConnectorRequest{})
+	f.server.Close()
+
+	err := f.service.Test(context.Background(), f.tenantID, connector.ID)
+	assert.EqualError(t, err, "connection test failed: the downstream service could not be reached")
+}
+
+func TestValidateServiceURL(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{"https://203.0.113.10/scim/v2", ""},
+		{"https://[2001:db8::1]/scim/v2", ""},
+		{"http://203.0.113.10/scim/v2", "must be an https URL"},
+		{"https://203.0.113.10/scim/v2?tenant=1", "must not have a query or fragment"},
+		{"https://127.0.0.1/scim/v2", "must not point to"},
+		{"https://[::1]/scim/v2", "must not point to"},
+		{"https://[::ffff:10.0.0.1]/scim/v2", "must not point to"},
+		{"https://10.1.2.3/scim/v2", "must not point to"},
+		{"https://172.16.0.1/scim/v2", "must not point to"},
+		{"https://192.168.1.1/scim/v2", "must not point to"},
+		{"https://169.254.169.254/latest/meta-data", "must not point to"},
+		{"https://[fe80::1]/scim/v2", "must not point to"},
+		{"https://[fd00::1]/scim/v2", "must not point to"},
+		{"https://100.64.0.1/scim/v2", "must not point to"},
+		{"https://0.0.0.0/scim/v2", "must not point to"},
+		{"https://224.0.0.1/scim/v2", "must not point to"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := validateServiceURL(ctx, "base_url", tt.url, publicAddress)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestNewHTTPClient_ChecksDialedAddress(t *testing.T) {
+	// A host that passed validation may resolve to a loopback address later
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := newHTTPClient(publicAddress).Get(server.URL)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errForbiddenAddress)
+}
+
+func TestNewHTTPClient_DoesNotFollowRedirects(t *testing.T) {
+	internal := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer internal.Close()
+	server := httptest.NewTLSServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer server.Close()
+
+	client := newHTTPClient(func(net.IP) bool { return true })
+	client.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
+
+func TestBuildUserDocument(t *testing.T) {
+	first := "Alice"
+	u := &models.User{
+		ID:        uuid.New(),
+		Username:  "alice",
+		FirstName: &first,
+		Status:    models.UserStatusActive,
+		Metadata:  map[string]interface{}{"cost_center": "4130"},
+	}
+	doc := buildUserDocument(u, []string{"admin", "auditor"}, map[string]string{
+		"userName":                             "username",
+		"name.givenName":                       "first_name",
+		"name.familyName":                      "last_name",
+		"roles":                                "roles",
+		"urn:acme:scim:hr:1.0:User:costCenter": "metadata.cost_center",
+	})
+
+	assert.Equal(t, []string{models.SCIMUserSchema, "urn:acme:scim:hr:1.0:User"}, doc["schemas"])
+	assert.Equal(t, map[string]interface{}{"givenName": "Alice"}, doc["name"], "missing values are left out")
+	assert.Equal(t, []map[string]interface{}{{"value": "admin"}, {"value": "auditor"}}, doc["roles"])
+	assert.Equal(t, map[string]interface{}{"costCenter": "4130"}, doc["urn:acme:scim:hr:1.0:User"])
+
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{"name", "roles", "schemas", "urn:acme:scim:hr:1.0:User", "userName"}, keys)
+}
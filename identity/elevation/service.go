@@ -0,0 +1,348 @@
+package elevation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/role"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// MaxDuration is the longest a role can be requested for
+const MaxDuration = 24 * time.Hour
+
+// DefaultSweepInterval is how often expired assignments are swept up
+const DefaultSweepInterval = time.Minute
+
+// Service manages just-in-time role elevation: users request a role for a limited
+// time, an approver grants or denies it, and the grant lapses on its own.
+type Service struct {
+	elevationRepo interfaces.ElevationRequestRepository
+	roleRepo      interfaces.RoleRepository
+	invalidator   role.CacheInvalidator
+	auditService  audit.ServiceInterface
+	checker       role.AssignmentChecker
+	txRunner      interfaces.TxRunner
+}
+
+// NewService creates a new elevation service.
+// invalidator may be nil when no authorization cache is in use; auditService is
+// used to record expiries, which happen outside any request. checker may be nil
+// when assignments are not restricted.
+func NewService(elevationRepo interfaces.ElevationRequestRepository, roleRepo interfaces.RoleRepository, invalidator role.CacheInvalidator, auditService audit.ServiceInterface, checker role.AssignmentChecker) *Service {
+	return &Service{
+		elevationRepo: elevationRepo,
+		roleRepo:      roleRepo,
+		invalidator:   invalidator,
+		auditService:  auditService,
+		checker:       checker,
+	}
+}
+
+// SetTxRunner sets the transaction runner that approvals are made in. Without
+// one they join the context's transaction, if any.
+func (s *Service) SetTxRunner(txRunner interfaces.TxRunner) {
+	s.txRunner = txRunner
+}
+
+// CreateElevationRequest represents a request to hold a role for a limited time
+type CreateElevationRequest struct {
+	TenantID        uuid.UUID `json:"-"`
+	UserID          uuid.UUID `json:"-"`
+	RoleID          uuid.UUID `json:"role_id" binding:"required"`
+	Reason          string    `json:"reason" binding:"required,max=1000"`
+	DurationMinutes int       `json:"duration_minutes" binding:"required,min=1"`
+}
+
+// DecisionRequest carries an approver's optional comment
+type DecisionRequest struct {
+	Comment *string `json:"comment,omitempty" binding:"omitempty,max=1000"`
+}
+
+// Request records a user's request for a role. The role must belong to the tenant,
+// the user must not already hold it permanently, and only one request per role may be pending.
+func (s *Service) Request(ctx context.Context, req *CreateElevationRequest) (*models.ElevationRequest, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if duration <= 0 || duration > MaxDuration {
+		return nil, fmt.Errorf("duration must be between 1 minute and %d hours", int(MaxDuration.Hours()))
+	}
+
+	targetRole, err := s.roleRepo.GetByID(ctx, req.RoleID)
+	if err != nil || targetRole.TenantID != req.TenantID {
+		return nil, fmt.Errorf("role not found")
+	}
+
+	assignments, err := s.roleRepo.GetUserRoleAssignments(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	for _, assignment := range assignments {
+		if assignment.Role.ID == req.RoleID && assignment.ExpiresAt == nil {
+			return nil, fmt.Errorf("user already holds role %s", targetRole.Name)
+		}
+	}
+
+	pending := models.ElevationStatusPending
+	existing, err := s.elevationRepo.List(ctx, req.TenantID, &interfaces.ElevationRequestFilters{
+		Status: &pending,
+		UserID: &req.UserID,
+		RoleID: &req.RoleID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pending requests: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("an elevation request for role %s is already pending", targetRole.Name)
+	}
+
+	if err := s.checkAssignment(ctx, req.TenantID, req.UserID, req.RoleID); err != nil {
+		return nil, err
+	}
+
+	elevation := &models.ElevationRequest{
+		TenantID:        req.TenantID,
+		UserID:          req.UserID,
+		RoleID:          req.RoleID,
+		Reason:          reason,
+		DurationSeconds: int(duration.Seconds()),
+		Status:          models.ElevationStatusPending,
+	}
+	if err := s.elevationRepo.Create(ctx, elevation); err != nil {
+		return nil, fmt.Errorf("failed to create elevation request: %w", err)
+	}
+
+	return elevation, nil
+}
+
+// GetByID retrieves an elevation request within a tenant
+func (s *Service) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.ElevationRequest, error) {
+	req, err := s.elevationRepo.GetByID(ctx, id)
+	if err != nil || req.TenantID != tenantID {
+		return nil, fmt.Errorf("elevation request not found")
+	}
+	return req, nil
+}
+
+// List retrieves a tenant's elevation requests
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.ElevationRequestFilters) ([]*models.ElevationRequest, error) {
+	requests, err := s.elevationRepo.List(ctx, tenantID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list elevation requests: %w", err)
+	}
+	return requests, nil
+}
+
+// Approve grants a pending request: the role is assigned to the requester until
+// now plus the requested duration. Requesters cannot approve their own requests.
+func (s *Service) Approve(ctx context.Context, tenantID, id, approverID uuid.UUID, comment *string) (*models.ElevationRequest, error) {
+	req, err := s.decide(ctx, tenantID, id, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetRole, err := s.roleRepo.GetByID(ctx, req.RoleID)
+	if err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
+	}
+
+	// The request is approved only if the role is assigned
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		// The user's roles may have changed since the request was made
+		if err := s.checkAssignment(ctx, tenantID, req.UserID, req.RoleID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		expiresAt := now.Add(req.Duration())
+		req.Status = models.ElevationStatusApproved
+		req.DecidedBy = &approverID
+		req.DecidedAt = &now
+		req.DecisionComment = comment
+		req.ExpiresAt = &expiresAt
+		if err := s.elevationRepo.Transition(ctx, req, models.ElevationStatusPending); err != nil {
+			return err
+		}
+
+		assignment := &models.UserRoleAssignment{
+			UserID:             req.UserID,
+			Role:               targetRole,
+			AssignedBy:         &approverID,
+			ExpiresAt:          &expiresAt,
+			ElevationRequestID: &req.ID,
+		}
+		if err := s.roleRepo.AssignRoleToUserWithExpiry(ctx, assignment); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateTenant(ctx, tenantID)
+
+	return req, nil
+}
+
+// Deny rejects a pending request
+func (s *Service) Deny(ctx context.Context, tenantID, id, approverID uuid.UUID, comment *string) (*models.ElevationRequest, error) {
+	req, err := s.decide(ctx, tenantID, id, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	req.Status = models.ElevationStatusDenied
+	req.DecidedBy = &approverID
+	req.DecidedAt = &now
+	req.DecisionComment = comment
+	if err := s.elevationRepo.Transition(ctx, req, models.ElevationStatusPending); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// Cancel withdraws a pending request. Only the requester can cancel it.
+func (s *Service) Cancel(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.ElevationRequest, error) {
+	req, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID != userID {
+		return nil, fmt.Errorf("only the requester can cancel an elevation request")
+	}
+	if req.Status != models.ElevationStatusPending {
+		return nil, fmt.Errorf("elevation request is not pending")
+	}
+
+	req.Status = models.ElevationStatusCancelled
+	if err := s.elevationRepo.Transition(ctx, req, models.ElevationStatusPending); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// ExpireDue removes time-bound role assignments that have lapsed, marks the
+// elevation requests behind them expired and records each expiry in the audit
+// log (which also notifies the tenant's webhooks). Returns the number of
+// assignments removed.
+func (s *Service) ExpireDue(ctx context.Context) (int, error) {
+	expired, err := s.roleRepo.DeleteExpiredRoleAssignments(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired role assignments: %w", err)
+	}
+
+	tenants := make(map[uuid.UUID]bool)
+	for _, assignment := range expired {
+		tenantID := assignment.Role.TenantID
+		tenants[tenantID] = true
+
+		metadata := map[string]interface{}{
+			"role_id":    assignment.Role.ID.String(),
+			"role_name":  assignment.Role.Name,
+			"expires_at": assignment.ExpiresAt.UTC().Format(time.RFC3339),
+		}
+		if assignment.ElevationRequestID != nil {
+			metadata["elevation_request_id"] = assignment.ElevationRequestID.String()
+			s.markExpired(ctx, *assignment.ElevationRequestID)
+		}
+		s.logExpiry(ctx, assignment, tenantID, metadata)
+	}
+
+	for tenantID := range tenants {
+		s.invalidateTenant(ctx, tenantID)
+	}
+
+	return len(expired), nil
+}
+
+// decide loads a pending request for an approver's decision
+func (s *Service) decide(ctx context.Context, tenantID, id, approverID uuid.UUID) (*models.ElevationRequest, error) {
+	req, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != models.ElevationStatusPending {
+		return nil, fmt.Errorf("elevation request is not pending")
+	}
+	if req.UserID == approverID {
+		return nil, fmt.Errorf("cannot decide your own elevation request")
+	}
+	return req, nil
+}
+
+// markExpired moves an approved request to expired. A request that was already
+// moved on is left alone.
+func (s *Service) markExpired(ctx context.Context, id uuid.UUID) {
+	req, err := s.elevationRepo.GetByID(ctx, id)
+	if err != nil || req.Status != models.ElevationStatusApproved {
+		return
+	}
+	req.Status = models.ElevationStatusExpired
+	_ = s.elevationRepo.Transition(ctx, req, models.ElevationStatusApproved)
+}
+
+// logExpiry records an expired assignment. The actor is whoever granted the role,
+// acting through the system, or the user themselves if that is unknown.
+func (s *Service) logExpiry(ctx context.Context, assignment *models.UserRoleAssignment, tenantID uuid.UUID, metadata map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+	actorID := assignment.UserID
+	if assignment.AssignedBy != nil {
+		actorID = *assignment.AssignedBy
+	}
+	event := &models.AuditEvent{
+		EventType: models.EventTypeRoleAssignmentExpired,
+		Actor: models.AuditActor{
+			UserID:        actorID,
+			Username:      "system",
+			PrincipalType: string(models.PrincipalTypeSystem),
+		},
+		Target: &models.AuditTarget{
+			Type: "user",
+			ID:   assignment.UserID,
+		},
+		TenantID: &tenantID,
+		Metadata: metadata,
+		Result:   models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = s.auditService.LogEvent(ctx, event)
+}
+
+// checkAssignment verifies the user may hold the role
+func (s *Service) checkAssignment(ctx context.Context, tenantID, userID, roleID uuid.UUID) error {
+	if s.checker == nil {
+		return nil
+	}
+	return s.checker.CheckAssignment(ctx, tenantID, userID, []uuid.UUID{roleID})
+}
+
+// invalidateTenant drops cached authorization data for a tenant. Failures are
+// ignored: cached grants expire on their own.
+func (s *Service) invalidateTenant(ctx context.Context, tenantID uuid.UUID) {
+	if s.invalidator == nil {
+		return
+	}
+	_ = s.invalidator.InvalidateTenant(ctx, tenantID)
+}
+
+// withinTx runs fn in a transaction, or directly without a transaction runner
+func (s *Service) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.txRunner == nil {
+		return fn(ctx)
+	}
+	return s.txRunner.WithinTx(ctx, fn)
+}
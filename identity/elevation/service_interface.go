@@ -0,0 +1,20 @@
+package elevation
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines the interface for role elevation operations
+type ServiceInterface interface {
+	Request(ctx context.Context, req *CreateElevationRequest) (*models.ElevationRequest, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.ElevationRequest, error)
+	List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.ElevationRequestFilters) ([]*models.ElevationRequest, error)
+	Approve(ctx context.Context, tenantID, id, approverID uuid.UUID, comment *string) (*models.ElevationRequest, error)
+	Deny(ctx context.Context, tenantID, id, approverID uuid.UUID, comment *string) (*models.ElevationRequest, error)
+	Cancel(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.ElevationRequest, error)
+	ExpireDue(ctx context.Context) (int, error)
+}
@@ -0,0 +1,367 @@
+package elevation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryElevationRepository is an in-memory ElevationRequestRepository
+type memoryElevationRepository struct {
+	requests map[uuid.UUID]*models.ElevationRequest
+}
+
+func newMemoryElevationRepository() *memoryElevationRepository {
+	return &memoryElevationRepository{requests: make(map[uuid.UUID]*models.ElevationRequest)}
+}
+
+func (r *memoryElevationRepository) Create(ctx context.Context, req *models.ElevationRequest) error {
+	req.ID = uuid.New()
+	req.CreatedAt = time.Now()
+	copied := *req
+	r.requests[req.ID] = &copied
+	return nil
+}
+
+func (r *memoryElevationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ElevationRequest, error) {
+	req, ok := r.requests[id]
+	if !ok {
+		return nil, fmt.Errorf("elevation request not found")
+	}
+	copied := *req
+	return &copied, nil
+}
+
+func (r *memoryElevationRepository) Transition(ctx context.Context, req *models.ElevationRequest, from string) error {
+	stored, ok := r.requests[req.ID]
+	if !ok || stored.Status != from {
+		return fmt.Errorf("elevation request is no longer %s", from)
+	}
+	copied := *req
+	r.requests[req.ID] = &copied
+	return nil
+}
+
+func (r *memoryElevationRepository) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.ElevationRequestFilters) ([]*models.ElevationRequest, error) {
+	var out []*models.ElevationRequest
+	for _, req := range r.requests {
+		if req.TenantID != tenantID {
+			continue
+		}
+		if filters != nil {
+			if filters.Status != nil && req.Status != *filters.Status {
+				continue
+			}
+			if filters.UserID != nil && req.UserID != *filters.UserID {
+				continue
+			}
+			if filters.RoleID != nil && req.RoleID != *filters.RoleID {
+				continue
+			}
+		}
+		copied := *req
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+// stubRoleRepository keeps roles and user assignments in memory
+type stubRoleRepository struct {
+	interfaces.RoleRepository
+	roles       map[uuid.UUID]*models.Role
+	assignments []*models.UserRoleAssignment
+	assignErr   error
+}
+
+func (r *stubRoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	role, ok := r.roles[id]
+	if !ok {
+		return nil, fmt.Errorf("role not found")
+	}
+	return role, nil
+}
+
+func (r *stubRoleRepository) GetUserRoleAssignments(ctx context.Context, userID uuid.UUID) ([]*models.UserRoleAssignment, error) {
+	var out []*models.UserRoleAssignment
+	for _, a := range r.assignments {
+		if a.UserID == userID && !a.IsExpired(time.Now()) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (r *stubRoleRepository) AssignRoleToUserWithExpiry(ctx context.Context, assignment *models.UserRoleAssignment) error {
+	if r.assignErr != nil {
+		return r.assignErr
+	}
+	r.assignments = append(r.assignments, assignment)
+	return nil
+}
+
+func (r *stubRoleRepository) DeleteExpiredRoleAssignments(ctx context.Context, before time.Time) ([]*models.UserRoleAssignment, error) {
+	var kept, expired []*models.UserRoleAssignment
+	for _, a := range r.assignments {
+		if a.IsExpired(before) {
+			expired = append(expired, a)
+		} else {
+			kept = append(kept, a)
+		}
+	}
+	r.assignments = kept
+	return expired, nil
+}
+
+// recordingAuditService records logged events
+type recordingAuditService struct {
+	audit.ServiceInterface
+	events []*models.AuditEvent
+}
+
+func (s *recordingAuditService) LogEvent(ctx context.Context, event *models.AuditEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+// recordingInvalidator records invalidated tenants
+type recordingInvalidator struct {
+	tenants []uuid.UUID
+}
+
+func (i *recordingInvalidator) InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	i.tenants = append(i.tenants, tenantID)
+	return nil
+}
+
+// snapshotTxRunner restores the elevation requests when fn fails
+type snapshotTxRunner struct {
+	elevations *memoryElevationRepository
+}
+
+func (r *snapshotTxRunner) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved := make(map[uuid.UUID]*models.ElevationRequest, len(r.elevations.requests))
+	for id, req := range r.elevations.requests {
+		saved[id] = req
+	}
+	if err := fn(ctx); err != nil {
+		r.elevations.requests = saved
+		return err
+	}
+	return nil
+}
+
+type fixture struct {
+	service     *Service
+	elevations  *memoryElevationRepository
+	roles       *stubRoleRepository
+	audit       *recordingAuditService
+	invalidator *recordingInvalidator
+	tenantID    uuid.UUID
+	role        *models.Role
+	requester   uuid.UUID
+	approver    uuid.UUID
+}
+
+func newFixture() *fixture {
+	tenantID := uuid.New()
+	role := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "db-admin"}
+	f := &fixture{
+		elevations:  newMemoryElevationRepository(),
+		roles:       &stubRoleRepository{roles: map[uuid.UUID]*models.Role{role.ID: role}},
+		audit:       &recordingAuditService{},
+		invalidator: &recordingInvalidator{},
+		tenantID:    tenantID,
+		role:        role,
+		requester:   uuid.New(),
+		approver:    uuid.New(),
+	}
+	f.service = NewService(f.elevations, f.roles, f.invalidator, f.audit, nil)
+	return f
+}
+
+func (f *fixture) request(t *testing.T) *models.ElevationRequest {
+	t.Helper()
+	req, err := f.service.Request(context.Background(), &CreateElevationRequest{
+		TenantID:        f.tenantID,
+		UserID:          f.requester,
+		RoleID:          f.role.ID,
+		Reason:          "incident 4821",
+		DurationMinutes: 120,
+	})
+	require.NoError(t, err)
+	return req
+}
+
+func TestService_Request(t *testing.T) {
+	f := newFixture()
+
+	req := f.request(t)
+	assert.Equal(t, models.ElevationStatusPending, req.Status)
+	assert.Equal(t, 2*time.Hour, req.Duration())
+
+	_, err := f.service.Request(context.Background(), &CreateElevationRequest{
+		TenantID: f.tenantID, UserID: f.requester, RoleID: f.role.ID, Reason: "again", DurationMinutes: 30,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already pending")
+}
+
+func TestService_Request_Validation(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *CreateElevationRequest
+		want string
+	}{
+		{"blank reason", &CreateElevationRequest{TenantID: f.tenantID, UserID: f.requester, RoleID: f.role.ID, Reason: "  ", DurationMinutes: 10}, "reason is required"},
+		{"too long", &CreateElevationRequest{TenantID: f.tenantID, UserID: f.requester, RoleID: f.role.ID, Reason: "x", DurationMinutes: 25 * 60}, "duration must be"},
+		{"other tenant's role", &CreateElevationRequest{TenantID: uuid.New(), UserID: f.requester, RoleID: f.role.ID, Reason: "x", DurationMinutes: 10}, "role not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Request(ctx, tt.req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	// A role held permanently cannot be requested
+	f.roles.assignments = append(f.roles.assignments, &models.UserRoleAssignment{UserID: f.requester, Role: f.role})
+	_, err := f.service.Request(ctx, &CreateElevationRequest{TenantID: f.tenantID, UserID: f.requester, RoleID: f.role.ID, Reason: "x", DurationMinutes: 10})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already holds role")
+}
+
+func TestService_Approve(t *testing.T) {
+	f := newFixture()
+	req := f.request(t)
+
+	comment := "approved for the incident"
+	approved, err := f.service.Approve(context.Background(), f.tenantID, req.ID, f.approver, &comment)
+	require.NoError(t, err)
+	assert.Equal(t, models.ElevationStatusApproved, approved.Status)
+	assert.Equal(t, f.approver, *approved.DecidedBy)
+	require.NotNil(t, approved.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), *approved.ExpiresAt, time.Minute)
+
+	require.Len(t, f.roles.assignments, 1)
+	assignment := f.roles.assignments[0]
+	assert.Equal(t, f.requester, assignment.UserID)
+	assert.Equal(t, *approved.ExpiresAt, *assignment.ExpiresAt)
+	assert.Equal(t, req.ID, *assignment.ElevationRequestID)
+	assert.Equal(t, []uuid.UUID{f.tenantID}, f.invalidator.tenants)
+
+	// Already decided
+	_, err = f.service.Deny(context.Background(), f.tenantID, req.ID, f.approver, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not pending")
+}
+
+func TestService_Approve_AssignmentFails(t *testing.T) {
+	f := newFixture()
+	f.service.SetTxRunner(&snapshotTxRunner{elevations: f.elevations})
+	req := f.request(t)
+	f.roles.assignErr = fmt.Errorf("connection reset")
+
+	_, err := f.service.Approve(context.Background(), f.tenantID, req.ID, f.approver, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to assign role")
+
+	// The request is still pending and can be approved once the role can be assigned
+	stored, err := f.elevations.GetByID(context.Background(), req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ElevationStatusPending, stored.Status)
+	assert.Nil(t, stored.DecidedBy)
+	assert.Empty(t, f.invalidator.tenants)
+
+	f.roles.assignErr = nil
+	approved, err := f.service.Approve(context.Background(), f.tenantID, req.ID, f.approver, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.ElevationStatusApproved, approved.Status)
+	require.Len(t, f.roles.assignments, 1)
+}
+
+func TestService_Approve_OwnRequest(t *testing.T) {
+	f := newFixture()
+	req := f.request(t)
+
+	_, err := f.service.Approve(context.Background(), f.tenantID, req.ID, f.requester, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decide your own")
+	assert.Empty(t, f.roles.assignments)
+}
+
+func TestService_Approve_OtherTenant(t *testing.T) {
+	f := newFixture()
+	req := f.request(t)
+
+	_, err := f.service.Approve(context.Background(), uuid.New(), req.ID, f.approver, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "elevation request not found")
+}
+
+func TestService_DenyAndCancel(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	req := f.request(t)
+	denied, err := f.service.Deny(ctx, f.tenantID, req.ID, f.approver, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.ElevationStatusDenied, denied.Status)
+	assert.Empty(t, f.roles.assignments)
+
+	// A new request is allowed once the previous one is decided
+	req = f.request(t)
+	_, err = f.service.Cancel(ctx, f.tenantID, req.ID, f.approver)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only the requester")
+
+	cancelled, err := f.service.Cancel(ctx, f.tenantID, req.ID, f.requester)
+	require.NoError(t, err)
+	assert.Equal(t, models.ElevationStatusCancelled, cancelled.Status)
+}
+
+func TestService_ExpireDue(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	req := f.request(t)
+	_, err := f.service.Approve(ctx, f.tenantID, req.ID, f.approver, nil)
+	require.NoError(t, err)
+
+	// Nothing is due yet
+	count, err := f.service.ExpireDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	past := time.Now().Add(-time.Second)
+	f.roles.assignments[0].ExpiresAt = &past
+	f.invalidator.tenants = nil
+
+	count, err = f.service.ExpireDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Empty(t, f.roles.assignments)
+	assert.Equal(t, []uuid.UUID{f.tenantID}, f.invalidator.tenants)
+
+	stored, err := f.service.GetByID(ctx, f.tenantID, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ElevationStatusExpired, stored.Status)
+
+	require.Len(t, f.audit.events, 1)
+	event := f.audit.events[0]
+	assert.Equal(t, models.EventTypeRoleAssignmentExpired, event.EventType)
+	assert.Equal(t, f.approver, event.Actor.UserID)
+	assert.Equal(t, f.requester, event.Target.ID)
+	assert.Equal(t, req.ID.String(), event.Metadata["elevation_request_id"])
+}
@@ -0,0 +1,145 @@
+package group
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// MaxNestingDepth bounds how many levels of containing groups are followed
+const MaxNestingDepth = 10
+
+// UserGroups returns the groups a user belongs to, directly or through nested
+// groups, without duplicates. Direct groups come first.
+func UserGroups(ctx context.Context, groupRepo interfaces.GroupRepository, userID uuid.UUID) ([]*models.Group, error) {
+	direct, err := groupRepo.GetUserGroups(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user groups: %w", err)
+	}
+
+	groups := make([]*models.Group, 0, len(direct))
+	seen := make(map[uuid.UUID]bool)
+	frontier := make([]*models.Group, 0, len(direct))
+	for _, g := range direct {
+		if seen[g.ID] {
+			continue
+		}
+		seen[g.ID] = true
+		groups = append(groups, g)
+		frontier = append(frontier, g)
+	}
+
+	// A member of a nested group is a member of every group containing it
+	for depth := 0; len(frontier) > 0 && depth < MaxNestingDepth; depth++ {
+		var next []*models.Group
+		for _, g := range frontier {
+			parents, err := groupRepo.GetParentGroups(ctx, g.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parent groups of %s: %w", g.Name, err)
+			}
+			for _, parent := range parents {
+				if seen[parent.ID] || parent.TenantID != g.TenantID || !parent.IsActive() {
+					continue
+				}
+				seen[parent.ID] = true
+				groups = append(groups, parent)
+				next = append(next, parent)
+			}
+		}
+		frontier = next
+	}
+
+	return groups, nil
+}
+
+// GroupRoles returns the roles granted to any of the given groups, without duplicates.
+// Roles from a tenant other than the granting group's are ignored.
+func GroupRoles(ctx context.Context, groupRepo interfaces.GroupRepository, groups []*models.Group) ([]*models.Role, error) {
+	roles := make([]*models.Role, 0)
+	seen := make(map[uuid.UUID]bool)
+
+	for _, g := range groups {
+		granted, err := groupRepo.GetGroupRoles(ctx, g.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get roles of group %s: %w", g.Name, err)
+		}
+		for _, r := range granted {
+			if seen[r.ID] || r.TenantID != g.TenantID || !r.IsActive() {
+				continue
+			}
+			seen[r.ID] = true
+			roles = append(roles, r)
+		}
+	}
+
+	return roles, nil
+}
+
+// memberUserIDs returns the IDs of the users in a group, directly or through
+// groups nested in it, without duplicates
+func memberUserIDs(ctx context.Context, groupRepo interfaces.GroupRepository, groupID uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	seenUsers := make(map[uuid.UUID]bool)
+	seenGroups := map[uuid.UUID]bool{groupID: true}
+	frontier := []uuid.UUID{groupID}
+
+	for depth := 0; len(frontier) > 0 && depth <= MaxNestingDepth; depth++ {
+		var next []uuid.UUID
+		for _, id := range frontier {
+			members, err := groupRepo.ListMembers(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list members: %w", err)
+			}
+			for _, m := range members {
+				if !seenUsers[m.UserID] {
+					seenUsers[m.UserID] = true
+					userIDs = append(userIDs, m.UserID)
+				}
+			}
+
+			nested, err := groupRepo.ListMemberGroups(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list member groups: %w", err)
+			}
+			for _, g := range nested {
+				if seenGroups[g.ID] || !g.IsActive() {
+					continue
+				}
+				seenGroups[g.ID] = true
+				next = append(next, g.ID)
+			}
+		}
+		frontier = next
+	}
+
+	return userIDs, nil
+}
+
+// ancestorIDs returns the IDs of every group that contains the given group, directly or transitively
+func ancestorIDs(ctx context.Context, groupRepo interfaces.GroupRepository, groupID uuid.UUID) (map[uuid.UUID]bool, error) {
+	ancestors := make(map[uuid.UUID]bool)
+	frontier := []uuid.UUID{groupID}
+
+	for depth := 0; len(frontier) > 0 && depth <= MaxNestingDepth; depth++ {
+		var next []uuid.UUID
+		for _, id := range frontier {
+			parents, err := groupRepo.GetParentGroups(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parent groups: %w", err)
+			}
+			for _, parent := range parents {
+				if ancestors[parent.ID] {
+					continue
+				}
+				ancestors[parent.ID] = true
+				next = append(next, parent.ID)
+			}
+		}
+		frontier = next
+	}
+
+	return ancestors, nil
+}
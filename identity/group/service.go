@@ -0,0 +1,625 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/role"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// Service provides group management business logic
+type Service struct {
+	groupRepo   interfaces.GroupRepository
+	userRepo    interfaces.UserRepository
+	roleRepo    interfaces.RoleRepository
+	invalidator role.CacheInvalidator
+	checker     role.AssignmentChecker
+	txRunner    interfaces.TxRunner
+}
+
+// NewService creates a new group service.
+// invalidator may be nil when no authorization cache is in use; checker may be
+// nil when the roles a group grants are not restricted.
+func NewService(groupRepo interfaces.GroupRepository, userRepo interfaces.UserRepository, roleRepo interfaces.RoleRepository, invalidator role.CacheInvalidator, checker role.AssignmentChecker) *Service {
+	return &Service{
+		groupRepo:   groupRepo,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		invalidator: invalidator,
+		checker:     checker,
+	}
+}
+
+// SetTxRunner sets the transaction runner that the roles a change grants are
+// checked and granted in. Without one they join the context's transaction,
+// if any.
+func (s *Service) SetTxRunner(txRunner interfaces.TxRunner) {
+	s.txRunner = txRunner
+}
+
+// CreateGroupRequest represents a request to create a group
+type CreateGroupRequest struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	Name        string    `json:"name" binding:"required,min=1,max=255"`
+	Description *string   `json:"description,omitempty"`
+	ExternalID  *string   `json:"external_id,omitempty"`
+}
+
+// UpdateGroupRequest represents a request to update a group
+type UpdateGroupRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	ExternalID  *string `json:"external_id,omitempty"`
+}
+
+// Create creates a new group
+func (s *Service) Create(ctx context.Context, req *CreateGroupRequest) (*models.Group, error) {
+	if req.TenantID == uuid.Nil {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("group name is required")
+	}
+
+	if existing, _ := s.groupRepo.GetByName(ctx, req.TenantID, name); existing != nil {
+		return nil, fmt.Errorf("group with name %s already exists", name)
+	}
+	if req.ExternalID != nil && *req.ExternalID != "" {
+		if existing, _ := s.groupRepo.GetByExternalID(ctx, req.TenantID, *req.ExternalID); existing != nil {
+			return nil, fmt.Errorf("group with external id %s already exists", *req.ExternalID)
+		}
+	}
+
+	group := &models.Group{
+		ID:          uuid.New(),
+		TenantID:    req.TenantID,
+		Name:        name,
+		Description: req.Description,
+		ExternalID:  req.ExternalID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	return group, nil
+}
+
+// GetByID retrieves a group by ID
+func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	group, err := s.groupRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("group not found: %w", err)
+	}
+
+	return group, nil
+}
+
+// Lock locks a group until the context's transaction ends
+func (s *Service) Lock(ctx context.Context, id uuid.UUID) error {
+	return s.groupRepo.LockByID(ctx, id)
+}
+
+// GetByExternalID retrieves a group by the identifier assigned by an external IdP
+func (s *Service) GetByExternalID(ctx context.Context, tenantID uuid.UUID, externalID string) (*models.Group, error) {
+	group, err := s.groupRepo.GetByExternalID(ctx, tenantID, externalID)
+	if err != nil {
+		return nil, fmt.Errorf("group not found: %w", err)
+	}
+
+	return group, nil
+}
+
+// Update updates an existing group
+func (s *Service) Update(ctx context.Context, id uuid.UUID, req *UpdateGroupRequest) (*models.Group, error) {
+	group, err := s.groupRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("group not found: %w", err)
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("group name is required")
+		}
+		if existing, _ := s.groupRepo.GetByName(ctx, group.TenantID, name); existing != nil && existing.ID != id {
+			return nil, fmt.Errorf("group name %s is already taken", name)
+		}
+		group.Name = name
+	}
+
+	if req.Description != nil {
+		group.Description = req.Description
+	}
+
+	if req.ExternalID != nil {
+		if *req.ExternalID == "" {
+			group.ExternalID = nil
+		} else {
+			if existing, _ := s.groupRepo.GetByExternalID(ctx, group.TenantID, *req.ExternalID); existing != nil && existing.ID != id {
+				return nil, fmt.Errorf("group with external id %s already exists", *req.ExternalID)
+			}
+			group.ExternalID = req.ExternalID
+		}
+	}
+
+	group.UpdatedAt = time.Now()
+
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to update group: %w", err)
+	}
+
+	return group, nil
+}
+
+// Delete deletes a group along with its memberships and role grants
+func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("group not found: %w", err)
+	}
+
+	if err := s.groupRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	s.invalidateTenant(ctx, group.TenantID)
+
+	return nil
+}
+
+// List retrieves a list of groups
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) ([]*models.Group, error) {
+	if tenantID == uuid.Nil {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	groups, err := s.groupRepo.List(ctx, tenantID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// Count returns the number of groups matching the filters
+func (s *Service) Count(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) (int, error) {
+	count, err := s.groupRepo.Count(ctx, tenantID, filters)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count groups: %w", err)
+	}
+
+	return count, nil
+}
+
+// AddMember adds a user of the group's tenant to a group
+func (s *Service) AddMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("group not found: %w", err)
+	}
+
+	if err := s.checkUserTenant(ctx, group.TenantID, userID); err != nil {
+		return err
+	}
+
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		if err := s.lockAssignments(ctx, group.TenantID); err != nil {
+			return err
+		}
+
+		granted, err := s.grantedRoleIDs(ctx, group)
+		if err != nil {
+			return err
+		}
+		if err := s.checkAssignment(ctx, group.TenantID, userID, granted); err != nil {
+			return err
+		}
+
+		if err := s.groupRepo.AddMember(ctx, groupID, userID); err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateTenant(ctx, group.TenantID)
+
+	return nil
+}
+
+// RemoveMember removes a user from a group
+func (s *Service) RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("group not found: %w", err)
+	}
+
+	if err := s.groupRepo.RemoveMember(ctx, groupID, userID); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	s.invalidateTenant(ctx, group.TenantID)
+
+	return nil
+}
+
+// ListMembers retrieves the users directly in a group
+func (s *Service) ListMembers(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMember, error) {
+	members, err := s.groupRepo.ListMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetMembers replaces a group's direct user members and nested groups with the given sets.
+// Every member is validated before anything is changed.
+func (s *Service) SetMembers(ctx context.Context, groupID uuid.UUID, userIDs, memberGroupIDs []uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("group not found: %w", err)
+	}
+
+	wantUsers := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		if err := s.checkUserTenant(ctx, group.TenantID, id); err != nil {
+			return err
+		}
+		wantUsers[id] = true
+	}
+	wantGroups := make(map[uuid.UUID]bool, len(memberGroupIDs))
+	for _, id := range memberGroupIDs {
+		if err := s.checkNesting(ctx, group, id); err != nil {
+			return err
+		}
+		wantGroups[id] = true
+	}
+
+	currentUsers, err := s.groupRepo.ListMembers(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to list members: %w", err)
+	}
+	var staleUsers []uuid.UUID
+	for _, m := range currentUsers {
+		if wantUsers[m.UserID] {
+			delete(wantUsers, m.UserID)
+			continue
+		}
+		staleUsers = append(staleUsers, m.UserID)
+	}
+
+	currentGroups, err := s.groupRepo.ListMemberGroups(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to list member groups: %w", err)
+	}
+	var staleGroups []uuid.UUID
+	for _, g := range currentGroups {
+		if wantGroups[g.ID] {
+			delete(wantGroups, g.ID)
+			continue
+		}
+		staleGroups = append(staleGroups, g.ID)
+	}
+
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		// Users joining the group, directly or through a nested group, gain its roles
+		if len(wantUsers) > 0 || len(wantGroups) > 0 {
+			if err := s.lockAssignments(ctx, group.TenantID); err != nil {
+				return err
+			}
+			granted, err := s.grantedRoleIDs(ctx, group)
+			if err != nil {
+				return err
+			}
+			for id := range wantUsers {
+				if err := s.checkAssignment(ctx, group.TenantID, id, granted); err != nil {
+					return err
+				}
+			}
+			for id := range wantGroups {
+				if err := s.checkMemberUsers(ctx, group.TenantID, id, granted); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, id := range staleUsers {
+			if err := s.groupRepo.RemoveMember(ctx, groupID, id); err != nil {
+				return fmt.Errorf("failed to remove member: %w", err)
+			}
+		}
+		for id := range wantUsers {
+			if err := s.groupRepo.AddMember(ctx, groupID, id); err != nil {
+				return fmt.Errorf("failed to add member: %w", err)
+			}
+		}
+
+		for _, id := range staleGroups {
+			if err := s.groupRepo.RemoveMemberGroup(ctx, groupID, id); err != nil {
+				return fmt.Errorf("failed to remove member group: %w", err)
+			}
+		}
+		for id := range wantGroups {
+			if err := s.groupRepo.AddMemberGroup(ctx, groupID, id); err != nil {
+				return fmt.Errorf("failed to add member group: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateTenant(ctx, group.TenantID)
+
+	return nil
+}
+
+// GetUserGroups retrieves the groups a user belongs to, including through nested groups
+func (s *Service) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]*models.Group, error) {
+	return UserGroups(ctx, s.groupRepo, userID)
+}
+
+// AddMemberGroup nests a group inside another group of the same tenant.
+// Nesting must stay acyclic, and the nested group's users gain the group's roles.
+func (s *Service) AddMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("group not found: %w", err)
+	}
+
+	if err := s.checkNesting(ctx, group, memberGroupID); err != nil {
+		return err
+	}
+
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		if err := s.lockAssignments(ctx, group.TenantID); err != nil {
+			return err
+		}
+
+		// Every user in the nested group gains the group's roles
+		granted, err := s.grantedRoleIDs(ctx, group)
+		if err != nil {
+			return err
+		}
+		if err := s.checkMemberUsers(ctx, group.TenantID, memberGroupID, granted); err != nil {
+			return err
+		}
+
+		if err := s.groupRepo.AddMemberGroup(ctx, groupID, memberGroupID); err != nil {
+			return fmt.Errorf("failed to add member group: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateTenant(ctx, group.TenantID)
+
+	return nil
+}
+
+// RemoveMemberGroup removes a nested group from a group
+func (s *Service) RemoveMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("group not found: %w", err)
+	}
+
+	if err := s.groupRepo.RemoveMemberGroup(ctx, groupID, memberGroupID); err != nil {
+		return fmt.Errorf("failed to remove member group: %w", err)
+	}
+
+	s.invalidateTenant(ctx, group.TenantID)
+
+	return nil
+}
+
+// ListMemberGroups retrieves the groups directly nested in a group
+func (s *Service) ListMemberGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	groups, err := s.groupRepo.ListMemberGroups(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list member groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// AssignRole grants a role of the same tenant to a group
+func (s *Service) AssignRole(ctx context.Context, groupID, roleID uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("group not found: %w", err)
+	}
+
+	r, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return fmt.Errorf("role not found: %w", err)
+	}
+	if r.TenantID != group.TenantID {
+		return fmt.Errorf("role must belong to the same tenant as the group")
+	}
+
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		if err := s.lockAssignments(ctx, group.TenantID); err != nil {
+			return err
+		}
+
+		// Every member gains the role, including users of nested groups
+		if err := s.checkMemberUsers(ctx, group.TenantID, groupID, []uuid.UUID{roleID}); err != nil {
+			return err
+		}
+
+		if err := s.groupRepo.AssignRole(ctx, groupID, roleID); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateTenant(ctx, group.TenantID)
+
+	return nil
+}
+
+// RemoveRole revokes a role from a group
+func (s *Service) RemoveRole(ctx context.Context, groupID, roleID uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("group not found: %w", err)
+	}
+
+	if err := s.groupRepo.RemoveRole(ctx, groupID, roleID); err != nil {
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+
+	s.invalidateTenant(ctx, group.TenantID)
+
+	return nil
+}
+
+// GetGroupRoles retrieves the roles granted directly to a group
+func (s *Service) GetGroupRoles(ctx context.Context, groupID uuid.UUID) ([]*models.Role, error) {
+	roles, err := s.groupRepo.GetGroupRoles(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// checkUserTenant verifies a user exists in the tenant
+func (s *Service) checkUserTenant(ctx context.Context, tenantID, userID uuid.UUID) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u.TenantID == nil || *u.TenantID != tenantID {
+		return fmt.Errorf("user %s not found", userID)
+	}
+	return nil
+}
+
+// checkNesting verifies memberGroupID can be nested in group without crossing
+// tenants or creating a cycle
+func (s *Service) checkNesting(ctx context.Context, group *models.Group, memberGroupID uuid.UUID) error {
+	if memberGroupID == group.ID {
+		return fmt.Errorf("a group cannot contain itself")
+	}
+
+	member, err := s.groupRepo.GetByID(ctx, memberGroupID)
+	if err != nil || member.TenantID != group.TenantID {
+		return fmt.Errorf("group %s not found", memberGroupID)
+	}
+
+	// Nesting member in group closes a cycle if member already contains group
+	ancestors, err := ancestorIDs(ctx, s.groupRepo, group.ID)
+	if err != nil {
+		return err
+	}
+	if ancestors[memberGroupID] {
+		return fmt.Errorf("group nesting cycle: %s already contains %s", member.Name, group.Name)
+	}
+
+	return nil
+}
+
+// grantedRoleIDs returns the IDs of the roles granted to a group or any group containing it,
+// which are the roles a new member gains
+func (s *Service) grantedRoleIDs(ctx context.Context, group *models.Group) ([]uuid.UUID, error) {
+	if s.checker == nil {
+		return nil, nil
+	}
+
+	ancestors, err := ancestorIDs(ctx, s.groupRepo, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := []uuid.UUID{group.ID}
+	for id := range ancestors {
+		groupIDs = append(groupIDs, id)
+	}
+
+	var roleIDs []uuid.UUID
+	for _, id := range groupIDs {
+		roles, err := s.groupRepo.GetGroupRoles(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get group roles: %w", err)
+		}
+		for _, r := range roles {
+			if r.TenantID == group.TenantID {
+				roleIDs = append(roleIDs, r.ID)
+			}
+		}
+	}
+
+	return roleIDs, nil
+}
+
+// withinTx runs fn in a transaction, or directly without a transaction runner
+func (s *Service) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.txRunner == nil {
+		return fn(ctx)
+	}
+	return s.txRunner.WithinTx(ctx, fn)
+}
+
+// lockAssignments makes other checked assignments in the tenant wait until
+// the transaction ends. It is taken before reading the roles and members a
+// change combines, so they cannot change before the change is stored.
+func (s *Service) lockAssignments(ctx context.Context, tenantID uuid.UUID) error {
+	if s.checker == nil {
+		return nil
+	}
+	return s.checker.LockAssignments(ctx, tenantID)
+}
+
+// checkAssignment verifies a user may gain the given roles
+func (s *Service) checkAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	if s.checker == nil || len(roleIDs) == 0 {
+		return nil
+	}
+	return s.checker.CheckAssignment(ctx, tenantID, userID, roleIDs)
+}
+
+// checkMemberUsers verifies every user in a group, directly or through nested
+// groups, may gain the given roles
+func (s *Service) checkMemberUsers(ctx context.Context, tenantID, groupID uuid.UUID, roleIDs []uuid.UUID) error {
+	if s.checker == nil || len(roleIDs) == 0 {
+		return nil
+	}
+
+	userIDs, err := memberUserIDs(ctx, s.groupRepo, groupID)
+	if err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		if err := s.checker.CheckAssignment(ctx, tenantID, id, roleIDs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// invalidateTenant drops cached authorization data for a tenant. Failures are
+// ignored: the change has already been stored and cached grants expire on their own.
+func (s *Service) invalidateTenant(ctx context.Context, tenantID uuid.UUID) {
+	if s.invalidator == nil {
+		return
+	}
+	_ = s.invalidator.InvalidateTenant(ctx, tenantID)
+}
@@ -0,0 +1,38 @@
+package group
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines the interface for group service operations
+type ServiceInterface interface {
+	Create(ctx context.Context, req *CreateGroupRequest) (*models.Group, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error)
+	Lock(ctx context.Context, id uuid.UUID) error
+	GetByExternalID(ctx context.Context, tenantID uuid.UUID, externalID string) (*models.Group, error)
+	Update(ctx context.Context, id uuid.UUID, req *UpdateGroupRequest) (*models.Group, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) ([]*models.Group, error)
+	Count(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) (int, error)
+
+	// Membership
+	AddMember(ctx context.Context, groupID, userID uuid.UUID) error
+	RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error
+	ListMembers(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMember, error)
+	SetMembers(ctx context.Context, groupID uuid.UUID, userIDs, memberGroupIDs []uuid.UUID) error
+	GetUserGroups(ctx context.Context, userID uuid.UUID) ([]*models.Group, error)
+
+	// Nested groups
+	AddMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error
+	RemoveMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error
+	ListMemberGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error)
+
+	// Role grants
+	AssignRole(ctx context.Context, groupID, roleID uuid.UUID) error
+	RemoveRole(ctx context.Context, groupID, roleID uuid.UUID) error
+	GetGroupRoles(ctx context.Context, groupID uuid.UUID) ([]*models.Role, error)
+}
@@ -0,0 +1,334 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryGroupRepository keeps groups, memberships and role grants in memory
+type memoryGroupRepository struct {
+	interfaces.GroupRepository
+	groups  map[uuid.UUID]*models.Group
+	members map[uuid.UUID]map[uuid.UUID]bool // group -> users
+	nested  map[uuid.UUID]map[uuid.UUID]bool // group -> member groups
+	roles   map[uuid.UUID][]*models.Role
+}
+
+func newMemoryGroupRepository(groups ...*models.Group) *memoryGroupRepository {
+	r := &memoryGroupRepository{
+		groups:  map[uuid.UUID]*models.Group{},
+		members: map[uuid.UUID]map[uuid.UUID]bool{},
+		nested:  map[uuid.UUID]map[uuid.UUID]bool{},
+		roles:   map[uuid.UUID][]*models.Role{},
+	}
+	for _, g := range groups {
+		r.groups[g.ID] = g
+	}
+	return r
+}
+
+func (r *memoryGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	if g, ok := r.groups[id]; ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("group not found")
+}
+
+func (r *memoryGroupRepository) AddMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	if r.members[groupID] == nil {
+		r.members[groupID] = map[uuid.UUID]bool{}
+	}
+	r.members[groupID][userID] = true
+	return nil
+}
+
+func (r *memoryGroupRepository) RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	delete(r.members[groupID], userID)
+	return nil
+}
+
+func (r *memoryGroupRepository) ListMembers(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMember, error) {
+	var members []*models.GroupMember
+	for userID := range r.members[groupID] {
+		members = append(members, &models.GroupMember{GroupID: groupID, UserID: userID})
+	}
+	return members, nil
+}
+
+func (r *memoryGroupRepository) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]*models.Group, error) {
+	var groups []*models.Group
+	for groupID, users := range r.members {
+		if users[userID] {
+			groups = append(groups, r.groups[groupID])
+		}
+	}
+	return groups, nil
+}
+
+func (r *memoryGroupRepository) AddMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error {
+	if r.nested[groupID] == nil {
+		r.nested[groupID] = map[uuid.UUID]bool{}
+	}
+	r.nested[groupID][memberGroupID] = true
+	return nil
+}
+
+func (r *memoryGroupRepository) RemoveMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error {
+	delete(r.nested[groupID], memberGroupID)
+	return nil
+}
+
+func (r *memoryGroupRepository) ListMemberGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	var groups []*models.Group
+	for id := range r.nested[groupID] {
+		groups = append(groups, r.groups[id])
+	}
+	return groups, nil
+}
+
+func (r *memoryGroupRepository) GetParentGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	var parents []*models.Group
+	for parentID, children := range r.nested {
+		if children[groupID] {
+			parents = append(parents, r.groups[parentID])
+		}
+	}
+	return parents, nil
+}
+
+func (r *memoryGroupRepository) GetGroupRoles(ctx context.Context, groupID uuid.UUID) ([]*models.Role, error) {
+	return r.roles[groupID], nil
+}
+
+// stubRoleRepository serves a fixed set of roles
+type stubRoleRepository struct {
+	interfaces.RoleRepository
+	roles map[uuid.UUID]*models.Role
+}
+
+func (r *stubRoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	if role, ok := r.roles[id]; ok {
+		return role, nil
+	}
+	return nil, fmt.Errorf("role not found")
+}
+
+// stubUserRepository serves a fixed set of users
+type stubUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+// recordingInvalidator records the tenants whose caches were invalidated
+type recordingInvalidator struct {
+	tenants []uuid.UUID
+}
+
+func (r *recordingInvalidator) InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	r.tenants = append(r.tenants, tenantID)
+	return nil
+}
+
+func newGroup(tenantID uuid.UUID, name string) *models.Group {
+	return &models.Group{ID: uuid.New(), TenantID: tenantID, Name: name}
+}
+
+func TestService_AddMemberGroup_RejectsCycle(t *testing.T) {
+	tenantID := uuid.New()
+	engineering := newGroup(tenantID, "engineering")
+	platform := newGroup(tenantID, "platform")
+	sre := newGroup(tenantID, "sre")
+	repo := newMemoryGroupRepository(engineering, platform, sre)
+	service := NewService(repo, &stubUserRepository{}, nil, nil, nil)
+	ctx := context.Background()
+
+	require.NoError(t, service.AddMemberGroup(ctx, engineering.ID, platform.ID))
+	require.NoError(t, service.AddMemberGroup(ctx, platform.ID, sre.ID))
+
+	err := service.AddMemberGroup(ctx, sre.ID, engineering.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle")
+
+	err = service.AddMemberGroup(ctx, sre.ID, sre.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot contain itself")
+}
+
+func TestService_AddMemberGroup_RejectsOtherTenant(t *testing.T) {
+	engineering := newGroup(uuid.New(), "engineering")
+	foreign := newGroup(uuid.New(), "foreign")
+	service := NewService(newMemoryGroupRepository(engineering, foreign), &stubUserRepository{}, nil, nil, nil)
+
+	err := service.AddMemberGroup(context.Background(), engineering.ID, foreign.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestService_AddMember(t *testing.T) {
+	tenantID := uuid.New()
+	otherTenantID := uuid.New()
+	engineering := newGroup(tenantID, "engineering")
+	member := &models.User{ID: uuid.New(), TenantID: &tenantID}
+	outsider := &models.User{ID: uuid.New(), TenantID: &otherTenantID}
+	repo := newMemoryGroupRepository(engineering)
+	invalidator := &recordingInvalidator{}
+	service := NewService(repo, &stubUserRepository{users: map[uuid.UUID]*models.User{
+		member.ID:   member,
+		outsider.ID: outsider,
+	}}, nil, invalidator, nil)
+	ctx := context.Background()
+
+	require.NoError(t, service.AddMember(ctx, engineering.ID, member.ID))
+	assert.True(t, repo.members[engineering.ID][member.ID])
+	assert.Equal(t, []uuid.UUID{tenantID}, invalidator.tenants)
+
+	err := service.AddMember(ctx, engineering.ID, outsider.ID)
+	require.Error(t, err)
+	assert.False(t, repo.members[engineering.ID][outsider.ID])
+}
+
+func TestService_SetMembers(t *testing.T) {
+	tenantID := uuid.New()
+	engineering := newGroup(tenantID, "engineering")
+	platform := newGroup(tenantID, "platform")
+	kept := &models.User{ID: uuid.New(), TenantID: &tenantID}
+	dropped := &models.User{ID: uuid.New(), TenantID: &tenantID}
+	added := &models.User{ID: uuid.New(), TenantID: &tenantID}
+	repo := newMemoryGroupRepository(engineering, platform)
+	service := NewService(repo, &stubUserRepository{users: map[uuid.UUID]*models.User{
+		kept.ID: kept, dropped.ID: dropped, added.ID: added,
+	}}, nil, nil, nil)
+	ctx := context.Background()
+	require.NoError(t, service.AddMember(ctx, engineering.ID, kept.ID))
+	require.NoError(t, service.AddMember(ctx, engineering.ID, dropped.ID))
+
+	err := service.SetMembers(ctx, engineering.ID, []uuid.UUID{kept.ID, added.ID}, []uuid.UUID{platform.ID})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[uuid.UUID]bool{kept.ID: true, added.ID: true}, repo.members[engineering.ID])
+	assert.Equal(t, map[uuid.UUID]bool{platform.ID: true}, repo.nested[engineering.ID])
+
+	// An invalid member leaves the group untouched
+	err = service.SetMembers(ctx, engineering.ID, []uuid.UUID{uuid.New()}, nil)
+	require.Error(t, err)
+	assert.Len(t, repo.members[engineering.ID], 2)
+}
+
+// denyingChecker rejects any assignment that includes a denied role
+type denyingChecker struct {
+	denied uuid.UUID
+}
+
+func (c *denyingChecker) LockAssignments(ctx context.Context, tenantID uuid.UUID) error {
+	return nil
+}
+
+func (c *denyingChecker) CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	for _, id := range roleIDs {
+		if id == c.denied {
+			return fmt.Errorf("separation of duties violation: rule %q allows at most 1 of its roles", "payments")
+		}
+	}
+	return nil
+}
+
+func (c *denyingChecker) CheckInheritance(ctx context.Context, tenantID, roleID, parentRoleID uuid.UUID) error {
+	return c.CheckAssignment(ctx, tenantID, uuid.Nil, []uuid.UUID{parentRoleID})
+}
+
+func TestService_AddMember_SoDViolation(t *testing.T) {
+	tenantID := uuid.New()
+	finance := newGroup(tenantID, "finance")
+	approver := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "payments_approver"}
+	member := &models.User{ID: uuid.New(), TenantID: &tenantID}
+	repo := newMemoryGroupRepository(finance)
+	repo.roles[finance.ID] = []*models.Role{approver}
+	service := NewService(repo, &stubUserRepository{users: map[uuid.UUID]*models.User{
+		member.ID: member,
+	}}, nil, nil, &denyingChecker{denied: approver.ID})
+	ctx := context.Background()
+
+	err := service.AddMember(ctx, finance.ID, member.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+	assert.False(t, repo.members[finance.ID][member.ID])
+
+	err = service.SetMembers(ctx, finance.ID, []uuid.UUID{member.ID}, nil)
+	require.Error(t, err)
+	assert.Empty(t, repo.members[finance.ID])
+}
+
+func TestService_NestedGroups_SoDViolation(t *testing.T) {
+	tenantID := uuid.New()
+	finance := newGroup(tenantID, "finance")
+	payments := newGroup(tenantID, "payments")
+	clerks := newGroup(tenantID, "clerks")
+	approver := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "payments_approver"}
+	clerk := uuid.New()
+
+	// clerk is in clerks, which sits in payments
+	repo := newMemoryGroupRepository(finance, payments, clerks)
+	repo.members[clerks.ID] = map[uuid.UUID]bool{clerk: true}
+	repo.nested[payments.ID] = map[uuid.UUID]bool{clerks.ID: true}
+	repo.roles[finance.ID] = []*models.Role{approver}
+	checker := &denyingChecker{denied: approver.ID}
+	service := NewService(repo, &stubUserRepository{}, &stubRoleRepository{roles: map[uuid.UUID]*models.Role{approver.ID: approver}}, nil, checker)
+	ctx := context.Background()
+
+	// Nesting payments in finance would give clerk the approver role
+	err := service.AddMemberGroup(ctx, finance.ID, payments.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+	assert.Empty(t, repo.nested[finance.ID])
+
+	err = service.SetMembers(ctx, finance.ID, nil, []uuid.UUID{payments.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+	assert.Empty(t, repo.nested[finance.ID])
+
+	// So would granting the role to payments directly
+	err = service.AssignRole(ctx, payments.ID, approver.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+}
+
+func TestUserGroupsAndRoles_Nested(t *testing.T) {
+	tenantID := uuid.New()
+	engineering := newGroup(tenantID, "engineering")
+	platform := newGroup(tenantID, "platform")
+	foreign := newGroup(uuid.New(), "foreign")
+	user := uuid.New()
+	repo := newMemoryGroupRepository(engineering, platform, foreign)
+	repo.members[platform.ID] = map[uuid.UUID]bool{user: true}
+	repo.nested[engineering.ID] = map[uuid.UUID]bool{platform.ID: true}
+	repo.nested[foreign.ID] = map[uuid.UUID]bool{platform.ID: true}
+
+	developer := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "developer"}
+	repo.roles[engineering.ID] = []*models.Role{developer}
+	repo.roles[platform.ID] = []*models.Role{developer, {ID: uuid.New(), TenantID: uuid.New(), Name: "foreign_admin"}}
+
+	groups, err := UserGroups(context.Background(), repo, user)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "platform", groups[0].Name)
+	assert.Equal(t, "engineering", groups[1].Name)
+
+	roles, err := GroupRoles(context.Background(), repo, groups)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "developer", roles[0].Name)
+}
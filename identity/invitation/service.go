@@ -81,6 +81,13 @@ func (s *Service) CreateInvitation(ctx context.Context, tenantID uuid.UUID, invi
 		return nil, fmt.Errorf("pending invitation already exists for this email")
 	}
 
+	// Reject role sets that could never be accepted
+	if len(req.RoleIDs) > 0 {
+		if err := s.roleService.CheckAssignment(ctx, tenantID, uuid.Nil, req.RoleIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate invitation token
 	token, err := generateToken()
 	if err != nil {
@@ -213,6 +220,13 @@ func (s *Service) AcceptInvitation(ctx context.Context, token string, req *Accep
 		return nil, fmt.Errorf("user with this email already exists")
 	}
 
+	// The invited roles must be allowed together; rules may have changed since the invitation was sent
+	if len(invitation.RoleIDs) > 0 {
+		if err := s.roleService.CheckAssignment(ctx, invitation.TenantID, uuid.Nil, invitation.RoleIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create user
 	createReq := &user.CreateUserRequest{
 		TenantID:  invitation.TenantID,
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Access review campaign scopes: what a campaign reviews
+const (
+	AccessReviewScopeRole        = "role"         // Users holding the listed roles
+	AccessReviewScopeUser        = "user"         // Roles held by the listed users
+	AccessReviewScopeOAuthClient = "oauth_client" // The listed OAuth clients
+)
+
+// Access review reviewer types: who reviews each item
+const (
+	AccessReviewerRoleOwner = "role_owner" // The role's owner, or the client's creator
+	AccessReviewerManager   = "manager"    // The user's manager (metadata.manager_id)
+	AccessReviewerUsers     = "users"      // The campaign's fallback reviewers
+)
+
+// Access review campaign statuses
+const (
+	AccessReviewStatusActive    = "active"
+	AccessReviewStatusClosed    = "closed"
+	AccessReviewStatusCancelled = "cancelled"
+)
+
+// Access review decisions
+const (
+	AccessReviewDecisionKeep   = "keep"
+	AccessReviewDecisionRevoke = "revoke"
+)
+
+// Access review item types
+const (
+	AccessReviewItemUserRole    = "user_role"
+	AccessReviewItemOAuthClient = "oauth_client"
+)
+
+// AccessReviewCampaign is a recertification of who holds what in a tenant
+type AccessReviewCampaign struct {
+	ID                  uuid.UUID   `json:"id" db:"id"`
+	TenantID            uuid.UUID   `json:"tenant_id" db:"tenant_id"`
+	Name                string      `json:"name" db:"name"`
+	Description         *string     `json:"description,omitempty" db:"description"`
+	ScopeType           string      `json:"scope_type" db:"scope_type"`
+	ScopeIDs            []uuid.UUID `json:"scope_ids" db:"scope_ids"`
+	ReviewerType        string      `json:"reviewer_type" db:"reviewer_type"`
+	FallbackReviewerIDs []uuid.UUID `json:"fallback_reviewer_ids" db:"fallback_reviewer_ids"`
+	DefaultDecision     string      `json:"default_decision" db:"default_decision"` // Applied to items left undecided at close
+	Status              string      `json:"status" db:"status"`
+	DueAt               *time.Time  `json:"due_at,omitempty" db:"due_at"`
+	CreatedBy           *uuid.UUID  `json:"created_by,omitempty" db:"created_by"`
+	ClosedBy            *uuid.UUID  `json:"closed_by,omitempty" db:"closed_by"`
+	ClosedAt            *time.Time  `json:"closed_at,omitempty" db:"closed_at"`
+	LastRemindedAt      *time.Time  `json:"last_reminded_at,omitempty" db:"last_reminded_at"`
+	CreatedAt           time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// AccessReviewItem is one piece of access under review in a campaign
+type AccessReviewItem struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	CampaignID uuid.UUID  `json:"campaign_id" db:"campaign_id"`
+	TenantID   uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	ItemType   string     `json:"item_type" db:"item_type"`
+	UserID     *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	RoleID     *uuid.UUID `json:"role_id,omitempty" db:"role_id"`
+	ClientID   *uuid.UUID `json:"client_id,omitempty" db:"client_id"`
+	Subject    string     `json:"subject" db:"subject"` // Username or client name at snapshot time
+	Access     string     `json:"access" db:"access"`   // Role name or client scopes at snapshot time
+	ReviewerID *uuid.UUID `json:"reviewer_id,omitempty" db:"reviewer_id"`
+	Decision   *string    `json:"decision,omitempty" db:"decision"`
+	Comment    *string    `json:"comment,omitempty" db:"comment"`
+	DecidedBy  *uuid.UUID `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt  *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty" db:"applied_at"`
+	ApplyError *string    `json:"apply_error,omitempty" db:"apply_error"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsDecided reports whether a reviewer has recorded a decision
+func (i *AccessReviewItem) IsDecided() bool {
+	return i.Decision != nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditChain is the head of a tenant's audit hash chain. System events,
+// which have no tenant, form their own chain with the nil chain ID.
+type AuditChain struct {
+	ChainID       uuid.UUID  `json:"chain_id" db:"chain_id"`
+	TenantID      *uuid.UUID `json:"tenant_id,omitempty" db:"tenant_id"`
+	Seq           int64      `json:"seq" db:"seq"`   // Sequence number of the last event
+	Hash          string     `json:"hash" db:"hash"` // Hash of the last event
+	CheckpointSeq int64      `json:"checkpoint_seq" db:"checkpoint_seq"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AuditCheckpoint is a signed statement that a chain had the given hash
+// at the given sequence number
+type AuditCheckpoint struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	ChainID   uuid.UUID  `json:"chain_id" db:"chain_id"`
+	TenantID  *uuid.UUID `json:"tenant_id,omitempty" db:"tenant_id"`
+	Seq       int64      `json:"seq" db:"seq"`
+	Hash      string     `json:"hash" db:"hash"`
+	KeyID     string     `json:"key_id" db:"key_id"`       // Thumbprint of the signing key
+	Signature string     `json:"signature" db:"signature"` // Base64 RS256 signature
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AuditChainID returns the chain an event of the tenant belongs to
+func AuditChainID(tenantID *uuid.UUID) uuid.UUID {
+	if tenantID == nil {
+		return uuid.Nil
+	}
+	return *tenantID
+}
@@ -24,6 +24,52 @@ const (
 	EventTypeRoleUpdated  = "role.updated"
 	EventTypeRoleDeleted  = "role.deleted"
 
+	// Role elevation and time-bound assignment events
+	EventTypeRoleElevationRequested = "role.elevation.requested"
+	EventTypeRoleElevationApproved  = "role.elevation.approved"
+	EventTypeRoleElevationDenied    = "role.elevation.denied"
+	EventTypeRoleElevationCancelled = "role.elevation.cancelled"
+	EventTypeRoleAssignmentExpired  = "role.assignment.expired"
+
+	// Access review events
+	EventTypeAccessReviewCreated   = "access_review.created"
+	EventTypeAccessReviewDecided   = "access_review.item.decided"
+	EventTypeAccessReviewClosed    = "access_review.closed"
+	EventTypeAccessReviewCancelled = "access_review.cancelled"
+	EventTypeAccessReviewRevoked   = "access_review.access.revoked"
+	EventTypeAccessReviewExported  = "access_review.report.exported"
+
+	// Separation-of-duties events
+	EventTypeSoDRuleCreated = "sod_rule.created"
+	EventTypeSoDRuleUpdated = "sod_rule.updated"
+	EventTypeSoDRuleDeleted = "sod_rule.deleted"
+
+	// SCIM token events. Issuing and revoking tokens are token.* events.
+	EventTypeSCIMTokenUpdated = "scim_token.updated"
+
+	// SCIM extension schema events
+	EventTypeSCIMSchemaCreated = "scim_schema.created"
+	EventTypeSCIMSchemaUpdated = "scim_schema.updated"
+	EventTypeSCIMSchemaDeleted = "scim_schema.deleted"
+
+	// Outbound SCIM connector events
+	EventTypeSCIMConnectorCreated    = "scim_connector.created"
+	EventTypeSCIMConnectorUpdated    = "scim_connector.updated"
+	EventTypeSCIMConnectorDeleted    = "scim_connector.deleted"
+	EventTypeSCIMConnectorReconciled = "scim_connector.reconciled"
+
+	// Webhook events. webhook.disabled is also emitted when a failing
+	// endpoint is disabled automatically.
+	EventTypeWebhookCreated  = "webhook.created"
+	EventTypeWebhookUpdated  = "webhook.updated"
+	EventTypeWebhookDeleted  = "webhook.deleted"
+	EventTypeWebhookPaused   = "webhook.paused"
+	EventTypeWebhookResumed  = "webhook.resumed"
+	EventTypeWebhookDisabled = "webhook.disabled"
+
+	// Background job events
+	EventTypeJobTriggered = "job.triggered"
+
 	// Permission events
 	EventTypePermissionAssigned = "permission.assigned"
 	EventTypePermissionRemoved  = "permission.removed"
@@ -60,6 +106,25 @@ const (
 	EventTypeOAuthScopeCreated = "oauth_scope.created"
 	EventTypeOAuthScopeUpdated = "oauth_scope.updated"
 	EventTypeOAuthScopeDeleted = "oauth_scope.deleted"
+
+	// Group events
+	EventTypeGroupCreated       = "group.created"
+	EventTypeGroupUpdated       = "group.updated"
+	EventTypeGroupDeleted       = "group.deleted"
+	EventTypeGroupMemberAdded   = "group.member.added"
+	EventTypeGroupMemberRemoved = "group.member.removed"
+	EventTypeGroupRoleAssigned  = "group.role.assigned"
+	EventTypeGroupRoleRemoved   = "group.role.removed"
+
+	// Policy events
+	EventTypePolicyCreated = "policy.created"
+	EventTypePolicyUpdated = "policy.updated"
+	EventTypePolicyDeleted = "policy.deleted"
+
+	// Relationship events
+	EventTypeRelationNamespaceUpdated = "relation.namespace.updated"
+	EventTypeRelationNamespaceDeleted = "relation.namespace.deleted"
+	EventTypeRelationTuplesWritten    = "relation.tuples.written"
 )
 
 // Result constants
@@ -84,6 +149,12 @@ type AuditEvent struct {
 	Error     string                 `json:"error,omitempty" db:"error"`
 	CreatedAt time.Time              `json:"created_at" db:"created_at"`
 
+	// Position in the tenant's audit chain. Events recorded before chaining
+	// was introduced have no sequence number or hashes.
+	Seq      int64  `json:"seq,omitempty" db:"seq"`
+	PrevHash string `json:"prev_hash,omitempty" db:"prev_hash"`
+	Hash     string `json:"hash,omitempty" db:"hash"`
+
 	// Database fields (flattened for storage)
 	ActorUserID        uuid.UUID  `json:"-" db:"actor_user_id"`
 	ActorUsername      string     `json:"-" db:"actor_username"`
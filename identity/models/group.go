@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group represents a tenant-scoped collection of users. Groups can contain
+// other groups and can be granted roles, which reach every member.
+type Group struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	Name        string     `json:"name" db:"name"`
+	Description *string    `json:"description,omitempty" db:"description"`
+	ExternalID  *string    `json:"external_id,omitempty" db:"external_id"` // Identifier assigned by the provisioning IdP
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt   *time.Time `json:"-" db:"deleted_at"`
+}
+
+// IsActive checks if group is active (not deleted)
+func (g *Group) IsActive() bool {
+	return g.DeletedAt == nil
+}
+
+// GroupMember represents a user's direct membership in a group
+type GroupMember struct {
+	GroupID  uuid.UUID `json:"group_id" db:"group_id"`
+	UserID   uuid.UUID `json:"user_id" db:"user_id"`
+	Username string    `json:"username" db:"username"`
+	Email    string    `json:"email" db:"email"`
+	AddedAt  time.Time `json:"added_at" db:"created_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduledJob is a background job shared by every replica. A replica runs
+// the job only while it holds the row's lease.
+type ScheduledJob struct {
+	Name                string     `json:"name" db:"name"`
+	Schedule            string     `json:"schedule" db:"schedule"`
+	TimeoutSeconds      int        `json:"timeout_seconds" db:"timeout_seconds"`
+	Enabled             bool       `json:"enabled" db:"enabled"`
+	NextRunAt           time.Time  `json:"next_run_at" db:"next_run_at"`
+	LockedBy            *string    `json:"locked_by,omitempty" db:"locked_by"` // Replica currently running the job
+	LockedUntil         *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	LastStartedAt       *time.Time `json:"last_started_at,omitempty" db:"last_started_at"`
+	LastFinishedAt      *time.Time `json:"last_finished_at,omitempty" db:"last_finished_at"`
+	LastStatus          *string    `json:"last_status,omitempty" db:"last_status"`
+	LastError           *string    `json:"last_error,omitempty" db:"last_error"`
+	LastDurationMs      *int64     `json:"last_duration_ms,omitempty" db:"last_duration_ms"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty" db:"last_success_at"`
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	TotalRuns           int64      `json:"total_runs" db:"total_runs"`
+	TotalFailures       int64      `json:"total_failures" db:"total_failures"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Running reports whether a replica holds the job's lease at now
+func (j *ScheduledJob) Running(now time.Time) bool {
+	return j.LockedBy != nil && j.LockedUntil != nil && j.LockedUntil.After(now)
+}
+
+// JobRun records one run of a scheduled job
+type JobRun struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	JobName    string    `json:"job_name" db:"job_name"`
+	Runner     string    `json:"runner" db:"runner"` // Replica that ran the job
+	Status     string    `json:"status" db:"status"`
+	Processed  int       `json:"processed" db:"processed"` // Items the job handled, e.g. deliveries retried
+	Error      *string   `json:"error,omitempty" db:"error"`
+	StartedAt  time.Time `json:"started_at" db:"started_at"`
+	FinishedAt time.Time `json:"finished_at" db:"finished_at"`
+	DurationMs int64     `json:"duration_ms" db:"duration_ms"`
+}
+
+// Job run statuses
+const (
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is an audit event waiting to be handed to its consumers.
+// It is written in the same transaction as the audit event.
+type OutboxEvent struct {
+	ID            int64           `json:"id" db:"id"` // Events are relayed in ID order
+	EventID       uuid.UUID       `json:"event_id" db:"event_id"`
+	TenantID      *uuid.UUID      `json:"tenant_id,omitempty" db:"tenant_id"`
+	EventType     string          `json:"event_type" db:"event_type"`
+	Payload       json.RawMessage `json:"payload" db:"payload"` // The audit event as JSON
+	Status        string          `json:"status" db:"status"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	ProcessedAt   *time.Time      `json:"processed_at,omitempty" db:"processed_at"`
+}
+
+// Outbox event statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusProcessed = "processed"
+	OutboxStatusDead      = "dead" // Gave up after too many failed attempts
+)
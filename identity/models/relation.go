@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RelationTuple states that a subject has a relation to an object, written
+// namespace:object_id#relation@subject. The subject is either a direct subject
+// (user:alice) or a userset (group:eng#member: everyone with the member
+// relation to group:eng).
+type RelationTuple struct {
+	TenantID         uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	Namespace        string    `json:"namespace" db:"namespace"`
+	ObjectID         string    `json:"object_id" db:"object_id"`
+	Relation         string    `json:"relation" db:"relation"`
+	SubjectNamespace string    `json:"subject_namespace" db:"subject_namespace"`
+	SubjectID        string    `json:"subject_id" db:"subject_id"`
+	SubjectRelation  string    `json:"subject_relation,omitempty" db:"subject_relation"` // Empty for a direct subject
+	Revision         int64     `json:"revision" db:"revision"`                           // Tenant revision that wrote the tuple
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
+
+// RelationNamespace is a tenant's definition of an object type and its relations
+type RelationNamespace struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	TenantID  uuid.UUID       `json:"tenant_id" db:"tenant_id"`
+	Name      string          `json:"name" db:"name"`
+	Config    NamespaceConfig `json:"config" db:"config"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// NamespaceConfig maps each relation of a namespace to the rewrite that computes
+// its subjects. A relation with no rewrite has only the subjects of its own tuples.
+type NamespaceConfig struct {
+	Relations map[string]*UsersetRewrite `json:"relations"`
+}
+
+// UsersetRewrite computes the subjects of a relation. Exactly one field is set.
+type UsersetRewrite struct {
+	This            bool              `json:"this,omitempty"`             // Subjects of the relation's own tuples
+	ComputedUserset string            `json:"computed_userset,omitempty"` // Subjects of another relation of the same object
+	TupleToUserset  *TupleToUserset   `json:"tuple_to_userset,omitempty"`
+	Union           []*UsersetRewrite `json:"union,omitempty"`
+	Intersection    []*UsersetRewrite `json:"intersection,omitempty"`
+	Exclusion       *UsersetExclusion `json:"exclusion,omitempty"`
+}
+
+// TupleToUserset follows the Tupleset relation of an object (e.g. parent) to other
+// objects and takes the subjects of their ComputedUserset relation (e.g. viewer)
+type TupleToUserset struct {
+	Tupleset        string `json:"tupleset"`
+	ComputedUserset string `json:"computed_userset"`
+}
+
+// UsersetExclusion holds the subjects of Base that are not subjects of Subtract
+type UsersetExclusion struct {
+	Base     *UsersetRewrite `json:"base"`
+	Subtract *UsersetRewrite `json:"subtract"`
+}
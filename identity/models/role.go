@@ -13,6 +13,7 @@ type Role struct {
 	Name        string    `json:"name" db:"name"`
 	Description *string   `json:"description,omitempty" db:"description"`
 	IsSystem    bool      `json:"is_system" db:"is_system"`
+	OwnerID     *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"` // Accountable user; reviews the role in access reviews
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 	DeletedAt   *time.Time `json:"-" db:"deleted_at"`
@@ -23,3 +24,17 @@ func (r *Role) IsActive() bool {
 	return r.DeletedAt == nil
 }
 
+// UserRoleAssignment is a role held directly by a user
+type UserRoleAssignment struct {
+	UserID             uuid.UUID  `json:"user_id" db:"user_id"`
+	Role               *Role      `json:"role"`
+	AssignedAt         time.Time  `json:"assigned_at" db:"assigned_at"`
+	AssignedBy         *uuid.UUID `json:"assigned_by,omitempty" db:"assigned_by"`
+	ExpiresAt          *time.Time `json:"expires_at,omitempty" db:"expires_at"`                     // Nil for a permanent assignment
+	ElevationRequestID *uuid.UUID `json:"elevation_request_id,omitempty" db:"elevation_request_id"` // Set when granted through elevation
+}
+
+// IsExpired reports whether a time-bound assignment has lapsed at the given time
+func (a *UserRoleAssignment) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !a.ExpiresAt.After(now)
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Elevation request statuses
+const (
+	ElevationStatusPending   = "pending"
+	ElevationStatusApproved  = "approved"
+	ElevationStatusDenied    = "denied"
+	ElevationStatusCancelled = "cancelled"
+	ElevationStatusExpired   = "expired"
+)
+
+// ElevationRequest is a user's request to hold a role for a limited time.
+// Once approved the role is assigned until ExpiresAt.
+type ElevationRequest struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	TenantID        uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	RoleID          uuid.UUID  `json:"role_id" db:"role_id"`
+	Reason          string     `json:"reason" db:"reason"`
+	DurationSeconds int        `json:"duration_seconds" db:"duration_seconds"`
+	Status          string     `json:"status" db:"status"`
+	DecidedBy       *uuid.UUID `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt       *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+	DecisionComment *string    `json:"decision_comment,omitempty" db:"decision_comment"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Duration returns how long the role is requested for
+func (r *ElevationRequest) Duration() time.Duration {
+	return time.Duration(r.DurationSeconds) * time.Second
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +18,7 @@ type SCIMToken struct {
 	TokenHash  string     `json:"-" db:"token_hash"` // Never expose hash (bcrypt)
 	LookupHash string     `json:"-" db:"lookup_hash"` // Never expose hash (SHA256 for lookup)
 	Scopes     []string   `json:"scopes" db:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips,omitempty" db:"allowed_ips"` // IP addresses and CIDR ranges; empty allows any
 	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
 	CreatedBy  *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
@@ -35,6 +40,40 @@ func (t *SCIMToken) IsDeleted() bool {
 	return t.DeletedAt != nil
 }
 
+// HasScope reports whether the token grants an access level ("read" or
+// "write") to a resource ("users" or "groups"). A bare resource scope grants
+// both levels and "*" grants everything.
+func (t *SCIMToken) HasScope(resource, access string) bool {
+	for _, scope := range t.Scopes {
+		if scope == "*" || scope == resource || scope == resource+"."+access {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP reports whether the token may be used from the given client
+// address. Tokens without an allowlist may be used from anywhere.
+func (t *SCIMToken) AllowsIP(clientIP string) bool {
+	if len(t.AllowedIPs) == 0 {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, allowed := range t.AllowedIPs {
+		if _, network, err := net.ParseCIDR(allowed); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // SCIMUser represents a SCIM 2.0 User resource
 type SCIMUser struct {
 	Schemas    []string              `json:"schemas"`
@@ -61,9 +100,98 @@ type SCIMUser struct {
 	Entitlements []SCIMEntitlement    `json:"entitlements,omitempty"`
 	Roles      []SCIMRole             `json:"roles,omitempty"`
 	X509Certificates []SCIMX509Certificate `json:"x509Certificates,omitempty"`
+	EnterpriseUser *SCIMEnterpriseUser  `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Extensions map[string]map[string]interface{} `json:"-"` // Tenant-defined extension attributes by schema URN
 	Meta       SCIMMeta               `json:"meta"`
 }
 
+// Schema URNs of the SCIM core and enterprise resources (RFC 7643)
+const (
+	SCIMUserSchema           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMGroupSchema          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMEnterpriseUserSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+)
+
+// scimUserJSON has the fields of SCIMUser without its JSON methods
+type scimUserJSON SCIMUser
+
+// MarshalJSON writes the user with its tenant-defined extensions as
+// top-level attributes named by their schema URN
+func (u SCIMUser) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(scimUserJSON(u))
+	if err != nil || len(u.Extensions) == 0 {
+		return raw, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	for urn, values := range u.Extensions {
+		if doc[urn], err = json.Marshal(values); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON reads a user, collecting top-level attributes named by a
+// schema URN other than the core and enterprise ones into Extensions
+func (u *SCIMUser) UnmarshalJSON(data []byte) error {
+	var user scimUserJSON
+	if err := json.Unmarshal(data, &user); err != nil {
+		return err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	for key, raw := range doc {
+		if !strings.HasPrefix(strings.ToLower(key), "urn:") ||
+			strings.EqualFold(key, SCIMUserSchema) || strings.EqualFold(key, SCIMEnterpriseUserSchema) {
+			continue
+		}
+		var values map[string]interface{}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return fmt.Errorf("extension %s must be an object", key)
+		}
+		if user.Extensions == nil {
+			user.Extensions = make(map[string]map[string]interface{})
+		}
+		user.Extensions[key] = values
+	}
+	*u = SCIMUser(user)
+	return nil
+}
+
+// SCIMEnterpriseUser holds the enterprise User extension attributes (RFC 7643 section 4.3)
+type SCIMEnterpriseUser struct {
+	EmployeeNumber string       `json:"employeeNumber,omitempty"`
+	CostCenter     string       `json:"costCenter,omitempty"`
+	Organization   string       `json:"organization,omitempty"`
+	Division       string       `json:"division,omitempty"`
+	Department     string       `json:"department,omitempty"`
+	Manager        *SCIMManager `json:"manager,omitempty"`
+}
+
+// SCIMManager references a user's manager
+type SCIMManager struct {
+	Value       string `json:"value,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// User metadata keys the enterprise extension attributes are stored under.
+// Metadata is what ABAC conditions see as subject and resource attributes,
+// so these read as subject.department, resource.manager_id and so on.
+const (
+	UserMetadataEmployeeNumber = "employee_number"
+	UserMetadataCostCenter     = "cost_center"
+	UserMetadataOrganization   = "organization"
+	UserMetadataDivision       = "division"
+	UserMetadataDepartment     = "department"
+	UserMetadataManagerID      = "manager_id"
+)
+
 // SCIMName represents a SCIM name structure
 type SCIMName struct {
 	Formatted  string `json:"formatted,omitempty"`
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SCIMConnector pushes a tenant's users, and optionally its groups, to a
+// downstream application that speaks SCIM 2.0
+type SCIMConnector struct {
+	ID                  uuid.UUID         `json:"id" db:"id"`
+	TenantID            uuid.UUID         `json:"tenant_id" db:"tenant_id"`
+	Name                string            `json:"name" db:"name"`
+	BaseURL             string            `json:"base_url" db:"base_url"` // SCIM service root, e.g. https://app.example.com/scim/v2
+	AuthType            string            `json:"auth_type" db:"auth_type"`
+	TokenURL            *string           `json:"token_url,omitempty" db:"token_url"` // OAuth 2.0 token endpoint for client credentials
+	ClientID            *string           `json:"client_id,omitempty" db:"client_id"`
+	Scopes              []string          `json:"scopes,omitempty" db:"scopes"`
+	CredentialEncrypted string            `json:"-" db:"credential_encrypted"`    // Bearer token or OAuth client secret
+	UserMapping         map[string]string `json:"user_mapping" db:"user_mapping"` // Target SCIM attribute -> source attribute
+	SyncGroups          bool              `json:"sync_groups" db:"sync_groups"`
+	DeprovisionAction   string            `json:"deprovision_action" db:"deprovision_action"`
+	Enabled             bool              `json:"enabled" db:"enabled"`
+	LastSyncAt          *time.Time        `json:"last_sync_at,omitempty" db:"last_sync_at"`
+	LastReconciledAt    *time.Time        `json:"last_reconciled_at,omitempty" db:"last_reconciled_at"`
+	LastError           *string           `json:"last_error,omitempty" db:"last_error"`
+	CreatedBy           *uuid.UUID        `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt           time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// Connector authentication types
+const (
+	SCIMConnectorAuthBearer = "bearer"
+	SCIMConnectorAuthOAuth2 = "oauth2" // OAuth 2.0 client credentials grant
+)
+
+// What a connector does downstream when a user is deleted
+const (
+	SCIMDeprovisionDelete     = "delete"
+	SCIMDeprovisionDeactivate = "deactivate" // Set active to false and keep the account
+)
+
+// SCIMSyncOperation is an entry in a connector's retry queue: one resource
+// to be pushed to, or removed from, the downstream application
+type SCIMSyncOperation struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ConnectorID   uuid.UUID  `json:"connector_id" db:"connector_id"`
+	TenantID      uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	ResourceType  string     `json:"resource_type" db:"resource_type"`
+	ResourceID    uuid.UUID  `json:"resource_id" db:"resource_id"`
+	Action        string     `json:"action" db:"action"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// Synchronized resource types
+const (
+	SCIMResourceUser  = "User"
+	SCIMResourceGroup = "Group"
+)
+
+// Sync operation actions
+const (
+	SCIMSyncActionUpsert = "upsert"
+	SCIMSyncActionDelete = "delete"
+)
+
+// Sync operation statuses. A pending operation that has been attempted before
+// is waiting for a retry; a failed one has exhausted its attempts or was
+// rejected by the downstream application.
+const (
+	SCIMSyncStatusPending   = "pending"
+	SCIMSyncStatusSucceeded = "succeeded"
+	SCIMSyncStatusFailed    = "failed"
+)
+
+// SCIMRemoteResource links a local user or group to its ID in the downstream application
+type SCIMRemoteResource struct {
+	ConnectorID  uuid.UUID `json:"connector_id" db:"connector_id"`
+	ResourceType string    `json:"resource_type" db:"resource_type"`
+	LocalID      uuid.UUID `json:"local_id" db:"local_id"`
+	RemoteID     string    `json:"remote_id" db:"remote_id"`
+	SyncedAt     time.Time `json:"synced_at" db:"synced_at"`
+}
+
+// SCIMConnectorStatus summarizes a connector's sync state
+type SCIMConnectorStatus struct {
+	ConnectorID       uuid.UUID  `json:"connector_id"`
+	Enabled           bool       `json:"enabled"`
+	Pending           int        `json:"pending"`
+	Retrying          int        `json:"retrying"` // Pending operations that have failed at least once
+	Failed            int        `json:"failed"`
+	Succeeded         int        `json:"succeeded"`
+	ProvisionedUsers  int        `json:"provisioned_users"`
+	ProvisionedGroups int        `json:"provisioned_groups"`
+	LastSyncAt        *time.Time `json:"last_sync_at,omitempty"`
+	LastReconciledAt  *time.Time `json:"last_reconciled_at,omitempty"`
+	LastError         *string    `json:"last_error,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SCIMSchemaExtension is a tenant-defined extension schema of the SCIM User
+// resource. It is listed by /Schemas and /ResourceTypes, its values are
+// validated when users are written, and each attribute is stored in the
+// user's metadata under the attribute's name.
+type SCIMSchemaExtension struct {
+	ID          uuid.UUID             `json:"id" db:"id"`
+	TenantID    uuid.UUID             `json:"tenant_id" db:"tenant_id"`
+	SchemaURN   string                `json:"schema" db:"schema_urn"`
+	Name        string                `json:"name" db:"name"`
+	Description *string               `json:"description,omitempty" db:"description"`
+	Required    bool                  `json:"required" db:"required"` // Whether every user must carry the extension
+	Attributes  []SCIMSchemaAttribute `json:"attributes" db:"attributes"`
+	CreatedBy   *uuid.UUID            `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// SCIMSchemaAttribute defines one attribute of an extension schema (RFC 7643 section 7)
+type SCIMSchemaAttribute struct {
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	MultiValued     bool     `json:"multiValued"`
+	Description     string   `json:"description,omitempty"`
+	Required        bool     `json:"required"`
+	CaseExact       bool     `json:"caseExact"`
+	Mutability      string   `json:"mutability"`
+	CanonicalValues []string `json:"canonicalValues,omitempty"`
+}
+
+// Attribute types supported in extension schemas
+const (
+	SCIMTypeString    = "string"
+	SCIMTypeBoolean   = "boolean"
+	SCIMTypeInteger   = "integer"
+	SCIMTypeDecimal   = "decimal"
+	SCIMTypeDateTime  = "dateTime"
+	SCIMTypeReference = "reference"
+)
+
+// Attribute mutability values
+const (
+	SCIMMutabilityReadWrite = "readWrite"
+	SCIMMutabilityReadOnly  = "readOnly"
+	SCIMMutabilityImmutable = "immutable"
+	SCIMMutabilityWriteOnly = "writeOnly"
+)
+
+// Attribute returns the extension attribute with the given name, compared case-insensitively
+func (e *SCIMSchemaExtension) Attribute(name string) (*SCIMSchemaAttribute, bool) {
+	for i := range e.Attributes {
+		if strings.EqualFold(e.Attributes[i].Name, name) {
+			return &e.Attributes[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SoDRule is a static separation-of-duties rule: no user may hold more than
+// MaxRoles of the roles in RoleIDs, whether assigned directly or through groups
+type SoDRule struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	TenantID    uuid.UUID   `json:"tenant_id" db:"tenant_id"`
+	Name        string      `json:"name" db:"name"`
+	Description *string     `json:"description,omitempty" db:"description"`
+	RoleIDs     []uuid.UUID `json:"role_ids" db:"role_ids"`
+	MaxRoles    int         `json:"max_roles" db:"max_roles"`
+	CreatedBy   *uuid.UUID  `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// Covers reports whether a role is one of the rule's mutually exclusive roles
+func (r *SoDRule) Covers(roleID uuid.UUID) bool {
+	for _, id := range r.RoleIDs {
+		if id == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// SoDViolation is a user holding more of a rule's roles than it allows
+type SoDViolation struct {
+	RuleID   uuid.UUID   `json:"rule_id"`
+	RuleName string      `json:"rule_name"`
+	MaxRoles int         `json:"max_roles"`
+	UserID   uuid.UUID   `json:"user_id"`
+	Username string      `json:"username"`
+	RoleIDs  []uuid.UUID `json:"role_ids"`
+}
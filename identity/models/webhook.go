@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,11 +16,74 @@ type Webhook struct {
 	Secret    string    `json:"secret" db:"secret"` // Note: Should not be returned in API responses
 	Enabled   bool      `json:"enabled" db:"enabled"`
 	Events    []string  `json:"events" db:"events"`
+
+	// Signing. Secret is an HMAC secret, or a whsk_ Ed25519 signing key whose
+	// public key is shown as PublicKey. After a rotation the previous secret
+	// keeps signing alongside the new one until PreviousSecretExpiresAt.
+	SigningAlgorithm        string     `json:"signing_algorithm" db:"signing_algorithm"`
+	PublicKey               string     `json:"public_key,omitempty" db:"-"`
+	PreviousSecret          *string    `json:"-" db:"previous_secret"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty" db:"previous_secret_expires_at"`
+
+	// Health. Enabled is cleared when the webhook is paused or disabled after
+	// too many consecutive failures; DisabledReason records which.
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	DisabledReason      *string    `json:"disabled_reason,omitempty" db:"disabled_reason"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
+// Webhook signing algorithms
+const (
+	WebhookSigningHMAC    = "hmac-sha256"
+	WebhookSigningEd25519 = "ed25519"
+)
+
+// SigningSecrets returns the secrets requests are signed with: the current
+// secret and, during a rotation, the previous one
+func (w *Webhook) SigningSecrets(now time.Time) []string {
+	secrets := []string{w.Secret}
+	if w.PreviousSecret != nil && w.PreviousSecretExpiresAt != nil && now.Before(*w.PreviousSecretExpiresAt) {
+		secrets = append(secrets, *w.PreviousSecret)
+	}
+	return secrets
+}
+
+// Reasons a webhook stopped receiving events
+const (
+	WebhookDisabledPaused   = "paused"
+	WebhookDisabledFailures = "consecutive_failures"
+)
+
+// EventTypeWildcard subscribes a webhook to every event type
+const EventTypeWildcard = "*"
+
+// Subscribes reports whether the webhook wants events of the given type.
+// Subscriptions are exact event types, "*", or a prefix ending in ".*" such as
+// "user.*", which matches user.created and user.impersonation.ended.
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, pattern := range w.Events {
+		if MatchesEventType(pattern, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesEventType reports whether an event type matches a subscription pattern
+func MatchesEventType(pattern, eventType string) bool {
+	if pattern == EventTypeWildcard || pattern == eventType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasSuffix(prefix, ".") {
+		return strings.HasPrefix(eventType, prefix)
+	}
+	return false
+}
+
 // WebhookDelivery represents a webhook delivery attempt
 type WebhookDelivery struct {
 	ID            uuid.UUID              `json:"id" db:"id"`
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/permission"
 	"github.com/arauth-identity/iam/storage/interfaces"
 )
 
@@ -127,9 +128,31 @@ func (s *Service) DeleteScope(ctx context.Context, id uuid.UUID) error {
 	return s.scopeRepo.Delete(ctx, id)
 }
 
-// GetScopesForPermissions returns all scopes that include any of the given permissions
+// GetScopesForPermissions returns all scopes that include any of the given permissions.
+// Wildcard and parent-resource grants count, so users:* matches a scope listing users:read.
 func (s *Service) GetScopesForPermissions(ctx context.Context, tenantID uuid.UUID, permissions []string) ([]*models.OAuthScope, error) {
-	return s.scopeRepo.GetScopesByPermissions(ctx, tenantID, permissions)
+	if len(permissions) == 0 {
+		return []*models.OAuthScope{}, nil
+	}
+
+	// Matching is done here rather than with an array overlap in SQL so that
+	// implied permissions are found too
+	scopes, err := s.scopeRepo.List(ctx, tenantID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scopes: %w", err)
+	}
+
+	matching := make([]*models.OAuthScope, 0)
+	for _, scope := range scopes {
+		for _, scopePermission := range scope.Permissions {
+			if _, ok := permission.MatchAny(permissions, scopePermission); ok {
+				matching = append(matching, scope)
+				break
+			}
+		}
+	}
+
+	return matching, nil
 }
 
 // GetDefaultScopes returns all default scopes for a tenant
@@ -33,6 +33,7 @@ type CreateClientResponse struct {
 // Client represents an OAuth2 client (WITHOUT secret - safe for listing)
 type Client struct {
 	ID             uuid.UUID `json:"id"`
+	TenantID       uuid.UUID `json:"tenant_id"`
 	ClientID       string    `json:"client_id"`
 	Name           string    `json:"name"`
 	Description    string    `json:"description"`
@@ -132,6 +132,7 @@ func (s *Service) ListClients(ctx context.Context, tenantID uuid.UUID) ([]*Clien
 		}
 		clients[i] = &Client{
 			ID:             rc.ID,
+			TenantID:       rc.TenantID,
 			ClientID:       rc.ClientID,
 			Name:           rc.Name,
 			Description:    desc,
@@ -169,6 +170,55 @@ func (s *Service) GetClient(ctx context.Context, id uuid.UUID, tenantID uuid.UUI
 	// Return client WITHOUT secret
 	return &Client{
 		ID:             repoClient.ID,
+		TenantID:       repoClient.TenantID,
+		ClientID:       repoClient.ClientID,
+		Name:           repoClient.Name,
+		Description:    desc,
+		RedirectURIs:   repoClient.RedirectURIs,
+		GrantTypes:     repoClient.GrantTypes,
+		Scopes:         repoClient.Scopes,
+		IsConfidential: repoClient.IsConfidential,
+		IsActive:       repoClient.IsActive,
+		CreatedAt:      repoClient.CreatedAt,
+		UpdatedAt:      repoClient.UpdatedAt,
+	}, nil
+}
+
+// AuthenticateClient verifies client credentials presented at a token endpoint
+// (client_secret_basic or client_secret_post)
+// SECURITY: The same error is returned for unknown clients and wrong secrets
+func (s *Service) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*Client, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+
+	repoClient, err := s.repo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+
+	if !repoClient.IsActive {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+
+	// Public clients have no secret to check; confidential clients must present one
+	if repoClient.IsConfidential {
+		if clientSecret == "" {
+			return nil, fmt.Errorf("invalid client credentials")
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(repoClient.ClientSecretHash), []byte(clientSecret)); err != nil {
+			return nil, fmt.Errorf("invalid client credentials")
+		}
+	}
+
+	desc := ""
+	if repoClient.Description != nil {
+		desc = *repoClient.Description
+	}
+
+	return &Client{
+		ID:             repoClient.ID,
+		TenantID:       repoClient.TenantID,
 		ClientID:       repoClient.ClientID,
 		Name:           repoClient.Name,
 		Description:    desc,
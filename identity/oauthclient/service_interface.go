@@ -17,6 +17,9 @@ type ServiceInterface interface {
 	// GetClient retrieves a single client (WITHOUT secret)
 	GetClient(ctx context.Context, id uuid.UUID, tenantID uuid.UUID) (*Client, error)
 
+	// AuthenticateClient verifies client credentials (client_secret_basic or client_secret_post)
+	AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*Client, error)
+
 	// RotateSecret generates a new secret and invalidates the old one
 	RotateSecret(ctx context.Context, id uuid.UUID, tenantID uuid.UUID) (*RotateSecretResponse, error)
 
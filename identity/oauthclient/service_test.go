@@ -65,6 +65,14 @@ func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *interfac
 	return args.Error(0)
 }
 
+func (m *MockRefreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.RefreshToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*interfaces.RefreshToken), args.Error(1)
+}
+
 func (m *MockRefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*interfaces.RefreshToken, error) {
 	args := m.Called(ctx, tokenHash)
 	if args.Get(0) == nil {
@@ -0,0 +1,126 @@
+package permission
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wildcard matches any segment of a resource or action
+const Wildcard = "*"
+
+// Permission strings have the form resource:action. Resources are dotted
+// hierarchies (billing.invoices) and actions may have colon-separated
+// sub-actions (clients:create); the first colon separates the two.
+//
+// A granted permission matches a required one when:
+//   - each resource segment is equal or "*", and a grant on a resource also
+//     covers everything below it (billing:read implies billing.invoices:read);
+//     a trailing "*" covers descendants only (billing.*:read);
+//   - each action segment is equal or "*", and a trailing "*" covers any
+//     remaining sub-actions (clients:* implies clients:create).
+//
+// So users:*, *:read and *:* work as expected.
+
+// Key builds the permission string for a resource and action
+func Key(resource, action string) string {
+	return resource + ":" + action
+}
+
+// Split splits a permission string into its resource and action
+func Split(permission string) (resource, action string, ok bool) {
+	idx := strings.Index(permission, ":")
+	if idx <= 0 || idx == len(permission)-1 {
+		return "", "", false
+	}
+	return permission[:idx], permission[idx+1:], true
+}
+
+// IsWildcard reports whether a permission string contains a wildcard segment
+func IsWildcard(permission string) bool {
+	return strings.Contains(permission, Wildcard)
+}
+
+// Matches reports whether a granted permission satisfies a required permission
+func Matches(granted, required string) bool {
+	if granted == required {
+		return true
+	}
+	grantedResource, grantedAction, ok := Split(granted)
+	if !ok {
+		return false
+	}
+	requiredResource, requiredAction, ok := Split(required)
+	if !ok {
+		return false
+	}
+	return matchSegments(strings.Split(grantedResource, "."), strings.Split(requiredResource, "."), true) &&
+		matchSegments(strings.Split(grantedAction, ":"), strings.Split(requiredAction, ":"), false)
+}
+
+// MatchAny returns the first granted permission that satisfies the required one
+func MatchAny(granted []string, required string) (string, bool) {
+	for _, g := range granted {
+		if Matches(g, required) {
+			return g, true
+		}
+	}
+	return "", false
+}
+
+// matchSegments matches granted segments against required ones. When
+// prefixImplies is set, a granted prefix also covers longer required values.
+func matchSegments(granted, required []string, prefixImplies bool) bool {
+	for i, seg := range granted {
+		if i >= len(required) {
+			return false
+		}
+		if seg == Wildcard {
+			if i == len(granted)-1 {
+				return true
+			}
+			continue
+		}
+		if seg != required[i] {
+			return false
+		}
+	}
+	return prefixImplies || len(granted) == len(required)
+}
+
+// ValidatePattern checks that a resource and action form a valid permission,
+// including the rules for wildcard grants:
+//   - a wildcard must be a whole segment ("users*" is rejected);
+//   - a wildcard resource must either be "*" on its own or follow a concrete
+//     leading segment ("billing.*", not "*.invoices");
+//   - segments may not be empty.
+func ValidatePattern(resource, action string) error {
+	if resource == "" || action == "" {
+		return fmt.Errorf("resource and action are required")
+	}
+	if strings.Contains(resource, ":") {
+		return fmt.Errorf("permission resource cannot contain ':'")
+	}
+
+	resourceSegments := strings.Split(resource, ".")
+	if err := validateSegments("resource", resourceSegments); err != nil {
+		return err
+	}
+	if resourceSegments[0] == Wildcard && len(resourceSegments) > 1 {
+		return fmt.Errorf("wildcard resource must be '*' or start with a concrete namespace")
+	}
+
+	return validateSegments("action", strings.Split(action, ":"))
+}
+
+// validateSegments rejects empty segments and partial wildcards
+func validateSegments(kind string, segments []string) error {
+	for _, seg := range segments {
+		if strings.TrimSpace(seg) != seg || seg == "" {
+			return fmt.Errorf("permission %s has an empty or padded segment", kind)
+		}
+		if seg != Wildcard && strings.Contains(seg, Wildcard) {
+			return fmt.Errorf("wildcard in permission %s must be a whole segment", kind)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package permission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		granted  string
+		required string
+		expected bool
+	}{
+		{"users:read", "users:read", true},
+		{"users:read", "users:update", false},
+		{"users:*", "users:update", true},
+		{"users:*", "users:roles:assign", true},
+		{"*:read", "users:read", true},
+		{"*:read", "users:update", false},
+		{"*:*", "billing.invoices:delete", true},
+		{"billing:*", "billing.invoices:read", true},
+		{"billing:read", "billing.invoices:read", true},
+		{"billing.*:read", "billing:read", false},
+		{"billing.*:read", "billing.invoices.lines:read", true},
+		{"billing.invoices:read", "billing:read", false},
+		{"billing:read", "billingx:read", false},
+		{"users:roles", "users:roles:assign", false},
+		{"users:roles:*", "users:roles:assign", true},
+		{"oauth:clients:*", "oauth:clients:rotate-secret", true},
+		{"users", "users:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.granted+" vs "+tt.required, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Matches(tt.granted, tt.required))
+		})
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	matched, ok := MatchAny([]string{"users:read", "billing:*"}, "billing.invoices:read")
+	assert.True(t, ok)
+	assert.Equal(t, "billing:*", matched)
+
+	_, ok = MatchAny(nil, "users:read")
+	assert.False(t, ok)
+}
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		resource  string
+		action    string
+		expectErr bool
+	}{
+		{"app.billing", "read", false},
+		{"app.billing", "*", false},
+		{"app.*", "read", false},
+		{"*", "*", false},
+		{"app.bill*", "read", true},
+		{"app.billing", "re*", true},
+		{"*.billing", "read", true},
+		{"app..billing", "read", true},
+		{"app.billing", "clients::create", true},
+		{"app:billing", "read", true},
+		{"", "read", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource+":"+tt.action, func(t *testing.T) {
+			err := ValidatePattern(tt.resource, tt.action)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
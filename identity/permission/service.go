@@ -69,32 +69,8 @@ func (s *Service) Create(ctx context.Context, req *CreatePermissionRequest) (*mo
 		return nil, fmt.Errorf("resource and action are required")
 	}
 
-	// CRITICAL SECURITY: Validate namespace - tenants can only create permissions in allowed namespaces
-	// Allowed namespaces: tenant.*, app.*, resource.*
-	// Forbidden namespaces: system.*, platform.*
-	allowedNamespaces := []string{"tenant.", "app.", "resource."}
-	forbiddenNamespaces := []string{"system.", "platform."}
-
-	resourceLower := strings.ToLower(resource)
-
-	// Check if resource starts with forbidden namespace
-	for _, forbidden := range forbiddenNamespaces {
-		if strings.HasPrefix(resourceLower, forbidden) {
-			return nil, fmt.Errorf("permission resource cannot start with '%s' namespace. Allowed namespaces: tenant.*, app.*, resource.*", forbidden)
-		}
-	}
-
-	// Check if resource starts with allowed namespace
-	hasAllowedNamespace := false
-	for _, allowed := range allowedNamespaces {
-		if strings.HasPrefix(resourceLower, allowed) {
-			hasAllowedNamespace = true
-			break
-		}
-	}
-
-	if !hasAllowedNamespace {
-		return nil, fmt.Errorf("permission resource must start with an allowed namespace: tenant.*, app.*, or resource.*")
+	if err := validateTenantPermission(resource, action); err != nil {
+		return nil, err
 	}
 
 	// Check if permission with same name already exists
@@ -126,8 +102,8 @@ func (s *Service) Create(ctx context.Context, req *CreatePermissionRequest) (*mo
 		return nil, fmt.Errorf("failed to create permission: %w", err)
 	}
 
-	// CRITICAL: Auto-attach new permission to tenant_owner
-	// This maintains the invariant: "tenant_owner always has all tenant permissions"
+	// CRITICAL: Maintain the invariant "tenant_owner always has all tenant permissions"
+	// tenant_owner holds *:*, so this only backfills that grant for tenants that predate it
 	if s.tenantInitializer != nil {
 		if err := s.tenantInitializer.AttachAllPermissionsToTenantOwner(ctx, req.TenantID); err != nil {
 			// Log error but don't fail permission creation
@@ -192,6 +168,12 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, req *UpdatePermissio
 		permission.Action = strings.TrimSpace(*req.Action)
 	}
 
+	if req.Resource != nil || req.Action != nil {
+		if err := validateTenantPermission(permission.Resource, permission.Action); err != nil {
+			return nil, err
+		}
+	}
+
 	permission.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, permission); err != nil {
@@ -241,3 +223,37 @@ func (s *Service) List(ctx context.Context, tenantID uuid.UUID, filters *interfa
 
 	return permissions, nil
 }
+
+// validateTenantPermission checks that a tenant-defined permission stays within the
+// allowed namespaces and follows the wildcard rules
+func validateTenantPermission(resource, action string) error {
+	// CRITICAL SECURITY: Validate namespace - tenants can only create permissions in allowed namespaces
+	// Allowed namespaces: tenant.*, app.*, resource.*
+	// Forbidden namespaces: system.*, platform.*
+	allowedNamespaces := []string{"tenant.", "app.", "resource."}
+	forbiddenNamespaces := []string{"system.", "platform."}
+
+	resourceLower := strings.ToLower(resource)
+
+	// Check if resource starts with forbidden namespace
+	for _, forbidden := range forbiddenNamespaces {
+		if strings.HasPrefix(resourceLower, forbidden) {
+			return fmt.Errorf("permission resource cannot start with '%s' namespace. Allowed namespaces: tenant.*, app.*, resource.*", forbidden)
+		}
+	}
+
+	// Check if resource starts with allowed namespace
+	hasAllowedNamespace := false
+	for _, allowed := range allowedNamespaces {
+		if strings.HasPrefix(resourceLower, allowed) {
+			hasAllowedNamespace = true
+			break
+		}
+	}
+
+	if !hasAllowedNamespace {
+		return fmt.Errorf("permission resource must start with an allowed namespace: tenant.*, app.*, or resource.*")
+	}
+
+	return ValidatePattern(resource, action)
+}
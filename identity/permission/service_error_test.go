@@ -94,3 +94,36 @@ func TestService_GetByID_NotFound(t *testing.T) {
 	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestService_Create_PartialWildcard(t *testing.T) {
+	mockRepo := new(MockPermissionRepository)
+	service := NewService(mockRepo, nil)
+
+	req := &CreatePermissionRequest{
+		TenantID: uuid.New(),
+		Name:     "app.billing.partial",
+		Resource: "app.bill*",
+		Action:   "read",
+	}
+
+	_, err := service.Create(context.Background(), req)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "whole segment")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Create_GlobalWildcardRejected(t *testing.T) {
+	mockRepo := new(MockPermissionRepository)
+	service := NewService(mockRepo, nil)
+
+	req := &CreatePermissionRequest{
+		TenantID: uuid.New(),
+		Name:     "everything",
+		Resource: "*",
+		Action:   "*",
+	}
+
+	_, err := service.Create(context.Background(), req)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "allowed namespace")
+}
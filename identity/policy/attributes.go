@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// Input holds the attributes a condition is evaluated against
+type Input struct {
+	Subject  map[string]interface{} `json:"subject,omitempty"`
+	Resource map[string]interface{} `json:"resource,omitempty"`
+	Context  map[string]interface{} `json:"context,omitempty"`
+	Tenant   map[string]interface{} `json:"tenant,omitempty"`
+}
+
+// RequestContext describes the request being authorized
+type RequestContext struct {
+	IP       string     `json:"ip,omitempty"`
+	Time     *time.Time `json:"time,omitempty"`      // Defaults to now
+	AMR      []string   `json:"amr,omitempty"`       // Authentication methods of the subject's session
+	AuthTime *int64     `json:"auth_time,omitempty"` // Unix time the session last authenticated interactively
+}
+
+// UserAttributes returns a user's attributes for use as subject or resource
+// attributes: the user's metadata plus id, tenant_id, username, email and status,
+// which take precedence over metadata keys of the same name
+func UserAttributes(user *models.User) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(user.Metadata)+5)
+	for k, v := range user.Metadata {
+		attrs[k] = v
+	}
+	attrs["id"] = user.ID.String()
+	attrs["username"] = user.Username
+	attrs["email"] = user.Email
+	attrs["status"] = user.Status
+	if user.TenantID != nil {
+		attrs["tenant_id"] = user.TenantID.String()
+	}
+	return attrs
+}
+
+// SubjectAttributes returns the subject attributes for a user holding the given roles
+func SubjectAttributes(user *models.User, roles []string) map[string]interface{} {
+	attrs := UserAttributes(user)
+	if roles == nil {
+		roles = []string{}
+	}
+	attrs["roles"] = roles
+	return attrs
+}
+
+// ContextAttributes returns the context attributes for a request:
+// ip, time (RFC 3339), amr, mfa (whether MFA was used) and, when the
+// authentication time is known, mfa_age_seconds
+func ContextAttributes(rc *RequestContext) map[string]interface{} {
+	if rc == nil {
+		rc = &RequestContext{}
+	}
+
+	now := time.Now()
+	if rc.Time != nil {
+		now = *rc.Time
+	}
+
+	amr := rc.AMR
+	if amr == nil {
+		amr = []string{}
+	}
+	mfa := false
+	for _, method := range amr {
+		if method == "mfa" || method == "otp" {
+			mfa = true
+			break
+		}
+	}
+
+	attrs := map[string]interface{}{
+		"ip":   rc.IP,
+		"time": now.UTC().Format(time.RFC3339),
+		"amr":  amr,
+		"mfa":  mfa,
+	}
+	if mfa && rc.AuthTime != nil {
+		age := now.Unix() - *rc.AuthTime
+		if age < 0 {
+			age = 0
+		}
+		attrs["mfa_age_seconds"] = float64(age)
+	}
+	return attrs
+}
+
+// TenantAttributes returns the tenant attributes for a tenant
+func TenantAttributes(tenantID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{"id": tenantID.String()}
+}
@@ -0,0 +1,897 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Conditions are written in a small expression language:
+//
+//	subject.department == resource.department && context.mfa
+//	ip_in(context.ip, "10.0.0.0/8", "192.168.0.0/16") || "admin" in subject.roles
+//	time_between("09:00", "17:00", "Europe/Berlin") && weekday() != "sunday"
+//
+// Values are strings, numbers, booleans, null and lists ([1, 2]). Attributes are
+// read with dotted paths rooted at subject, resource, context or tenant; a
+// missing attribute is null. Operators are ! && || == != < <= > >= and "in"
+// (list membership, substring or map key). The language has no loops,
+// assignments or user-defined functions, and expressions are bounded in size,
+// so evaluation always terminates quickly.
+
+// MaxExpressionLength bounds the size of a condition
+const MaxExpressionLength = 2048
+
+// MaxExpressionDepth bounds how deeply a condition may nest
+const MaxExpressionDepth = 32
+
+// attributeRoots are the names a path may start with
+var attributeRoots = map[string]bool{
+	"subject":  true,
+	"resource": true,
+	"context":  true,
+	"tenant":   true,
+}
+
+// Expression is a compiled condition
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses a condition. An empty condition always holds.
+func Compile(source string) (*Expression, error) {
+	if len(source) > MaxExpressionLength {
+		return nil, fmt.Errorf("condition may be at most %d characters", MaxExpressionLength)
+	}
+	if strings.TrimSpace(source) == "" {
+		return &Expression{source: source, root: literal{value: true}}, nil
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the condition source
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluate evaluates the condition against the input. The condition must produce a boolean.
+func (e *Expression) Evaluate(input *Input) (bool, error) {
+	if input == nil {
+		input = &Input{}
+	}
+	value, err := e.root.eval(input)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition must evaluate to true or false, got %s", typeName(value))
+	}
+	return result, nil
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// twoCharOperators are checked before single-character ones
+var twoCharOperators = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == r {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) && startsOperand(tokens)):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+
+		default:
+			matched := false
+			if i+1 < len(runes) {
+				pair := string(runes[i : i+2])
+				for _, op := range twoCharOperators {
+					if pair == op {
+						tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+						i += 2
+						matched = true
+						break
+					}
+				}
+			}
+			if matched {
+				continue
+			}
+			if strings.ContainsRune("!<>().,[]", r) {
+				tokens = append(tokens, token{kind: tokenOperator, text: string(r), pos: i})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// startsOperand reports whether the next token begins an operand, so a '-' is a sign
+func startsOperand(tokens []token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind == tokenOperator && last.text != ")" && last.text != "]"
+}
+
+// Parser
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokenOperator || t.kind == tokenIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		t := p.peek()
+		if t.kind == tokenEOF {
+			return fmt.Errorf("expected %q at end of condition", text)
+		}
+		return fmt.Errorf("expected %q at position %d", text, t.pos)
+	}
+	return nil
+}
+
+func checkDepth(depth int) error {
+	if depth > MaxExpressionDepth {
+		return fmt.Errorf("condition is nested more than %d levels deep", MaxExpressionDepth)
+	}
+	return nil
+}
+
+func (p *parser) parseOr(depth int) (node, error) {
+	if err := checkDepth(depth); err != nil {
+		return nil, err
+	}
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = logical{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = logical{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if p.accept("!") {
+		if err := checkDepth(depth + 1); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return not{operand: operand}, nil
+	}
+	return p.parseComparison(depth)
+}
+
+var comparisonOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "in": true,
+}
+
+func (p *parser) parseComparison(depth int) (node, error) {
+	left, err := p.parsePrimary(depth)
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if (t.kind == tokenOperator || t.kind == tokenIdent) && comparisonOperators[t.text] {
+		p.next()
+		right, err := p.parsePrimary(depth)
+		if err != nil {
+			return nil, err
+		}
+		return comparison{op: t.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	if err := checkDepth(depth); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return literal{value: t.text}, nil
+
+	case tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return literal{value: n}, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		case "null":
+			return literal{value: nil}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(t, depth)
+		}
+		return p.parsePath(t)
+
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			var items []node
+			if !p.accept("]") {
+				for {
+					item, err := p.parsePrimary(depth + 1)
+					if err != nil {
+						return nil, err
+					}
+					items = append(items, item)
+					if p.accept("]") {
+						break
+					}
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+			}
+			return list{items: items}, nil
+		}
+	}
+
+	if t.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of condition")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+func (p *parser) parsePath(first token) (node, error) {
+	if !attributeRoots[first.text] {
+		return nil, fmt.Errorf("unknown attribute %q at position %d; attributes start with subject, resource, context or tenant", first.text, first.pos)
+	}
+	path := attributePath{root: first.text}
+	for p.accept(".") {
+		t := p.next()
+		if t.kind != tokenIdent {
+			return nil, fmt.Errorf("expected attribute name at position %d", t.pos)
+		}
+		path.keys = append(path.keys, t.text)
+	}
+	return path, nil
+}
+
+func (p *parser) parseCall(name token, depth int) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+
+	var args []node
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseOr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("wrong number of arguments to %s at position %d", name.text, name.pos)
+	}
+	if fn.check != nil {
+		if err := fn.check(args); err != nil {
+			return nil, fmt.Errorf("%s: %w", name.text, err)
+		}
+	}
+
+	return call{name: name.text, fn: fn, args: args}, nil
+}
+
+// AST
+
+type node interface {
+	eval(input *Input) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (n literal) eval(*Input) (interface{}, error) {
+	return n.value, nil
+}
+
+type attributePath struct {
+	root string
+	keys []string
+}
+
+func (n attributePath) eval(input *Input) (interface{}, error) {
+	var current interface{}
+	switch n.root {
+	case "subject":
+		current = input.Subject
+	case "resource":
+		current = input.Resource
+	case "context":
+		current = input.Context
+	case "tenant":
+		current = input.Tenant
+	}
+	for _, key := range n.keys {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		current = m[key]
+	}
+	return normalize(current), nil
+}
+
+type list struct {
+	items []node
+}
+
+func (n list) eval(input *Input) (interface{}, error) {
+	values := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+type not struct {
+	operand node
+}
+
+func (n not) eval(input *Input) (interface{}, error) {
+	v, err := n.operand.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! needs true or false, got %s", typeName(v))
+	}
+	return !b, nil
+}
+
+type logical struct {
+	and         bool
+	left, right node
+}
+
+func (n logical) eval(input *Input) (interface{}, error) {
+	op := "||"
+	if n.and {
+		op = "&&"
+	}
+
+	left, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := left.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s needs true or false, got %s", op, typeName(left))
+	}
+	// Short-circuit
+	if n.and != l {
+		return l, nil
+	}
+
+	right, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := right.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s needs true or false, got %s", op, typeName(right))
+	}
+	return r, nil
+}
+
+type comparison struct {
+	op          string
+	left, right node
+}
+
+func (n comparison) eval(input *Input) (interface{}, error) {
+	left, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left), nil
+	}
+
+	// Ordering comparisons need two numbers or two strings
+	if l, ok := left.(float64); ok {
+		if r, ok := right.(float64); ok {
+			return compareOrdered(n.op, l, r), nil
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			return compareOrdered(n.op, l, r), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot compare %s %s %s", typeName(left), n.op, typeName(right))
+}
+
+func compareOrdered[T float64 | string](op string, l, r T) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+type call struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n call) eval(input *Input) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	v, err := n.fn.call(input, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+// Functions
+
+type function struct {
+	minArgs int
+	maxArgs int // -1 for no limit
+	check   func(args []node) error
+	call    func(input *Input, args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]function{
+	// ip_in(ip, cidr...) reports whether ip is in any of the CIDR ranges (or lists of ranges)
+	"ip_in": {minArgs: 2, maxArgs: -1, check: checkLiteralCIDRs, call: ipIn},
+	// time_between(start, end[, timezone]) reports whether the request time falls in a
+	// daily HH:MM window; windows may cross midnight
+	"time_between": {minArgs: 2, maxArgs: 3, check: checkLiteralTimeWindow, call: timeBetween},
+	// weekday([timezone]) returns the lowercase day of the week of the request time
+	"weekday": {minArgs: 0, maxArgs: 1, call: weekday},
+	// mfa_within(seconds) reports whether MFA was completed at most that long ago
+	"mfa_within": {minArgs: 1, maxArgs: 1, call: mfaWithin},
+	// starts_with(s, prefix) reports whether s starts with prefix
+	"starts_with": {minArgs: 2, maxArgs: 2, call: startsWith},
+	// lower(s) returns s in lower case
+	"lower": {minArgs: 1, maxArgs: 1, call: lower},
+}
+
+func ipIn(_ *Input, args []interface{}) (interface{}, error) {
+	ipStr, ok := args[0].(string)
+	if !ok {
+		return false, nil
+	}
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return false, nil
+	}
+
+	var ranges []interface{}
+	for _, arg := range args[1:] {
+		if items, ok := arg.([]interface{}); ok {
+			ranges = append(ranges, items...)
+		} else {
+			ranges = append(ranges, arg)
+		}
+	}
+
+	for _, r := range ranges {
+		cidr, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("ranges must be strings, got %s", typeName(r))
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", cidr)
+		}
+		if network.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func checkLiteralCIDRs(args []node) error {
+	for _, arg := range args[1:] {
+		if lit, ok := arg.(literal); ok {
+			if cidr, ok := lit.value.(string); ok {
+				if _, _, err := net.ParseCIDR(cidr); err != nil {
+					return fmt.Errorf("invalid CIDR %q", cidr)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func timeBetween(input *Input, args []interface{}) (interface{}, error) {
+	start, ok1 := args[0].(string)
+	end, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("window bounds must be HH:MM strings")
+	}
+	startMin, err := parseClock(start)
+	if err != nil {
+		return nil, err
+	}
+	endMin, err := parseClock(end)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := requestTime(input, args[2:])
+	if err != nil {
+		return nil, err
+	}
+	minute := now.Hour()*60 + now.Minute()
+
+	if startMin <= endMin {
+		return minute >= startMin && minute < endMin, nil
+	}
+	// Window crosses midnight
+	return minute >= startMin || minute < endMin, nil
+}
+
+func checkLiteralTimeWindow(args []node) error {
+	for _, arg := range args[:2] {
+		if lit, ok := arg.(literal); ok {
+			s, isString := lit.value.(string)
+			if !isString {
+				return fmt.Errorf("window bounds must be HH:MM strings")
+			}
+			if _, err := parseClock(s); err != nil {
+				return err
+			}
+		}
+	}
+	if len(args) == 3 {
+		if lit, ok := args[2].(literal); ok {
+			if tz, ok := lit.value.(string); ok {
+				if _, err := time.LoadLocation(tz); err != nil {
+					return fmt.Errorf("unknown time zone %q", tz)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func weekday(input *Input, args []interface{}) (interface{}, error) {
+	now, err := requestTime(input, args)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ToLower(now.Weekday().String()), nil
+}
+
+func mfaWithin(input *Input, args []interface{}) (interface{}, error) {
+	limit, ok := args[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("seconds must be a number")
+	}
+	age, ok := normalize(input.Context["mfa_age_seconds"]).(float64)
+	if !ok {
+		return false, nil
+	}
+	return age <= limit, nil
+}
+
+func startsWith(_ *Input, args []interface{}) (interface{}, error) {
+	s, ok1 := args[0].(string)
+	prefix, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return false, nil
+	}
+	return strings.HasPrefix(s, prefix), nil
+}
+
+func lower(_ *Input, args []interface{}) (interface{}, error) {
+	s, ok := args[0].(string)
+	if !ok {
+		return args[0], nil
+	}
+	return strings.ToLower(s), nil
+}
+
+// parseClock parses HH:MM into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// requestTime returns the request time from context.time (RFC 3339), or now,
+// in the optional time zone argument (UTC by default)
+func requestTime(input *Input, args []interface{}) (time.Time, error) {
+	now := time.Now()
+	if s, ok := input.Context["time"].(string); ok && s != "" {
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid context.time %q", s)
+		}
+		now = parsed
+	}
+
+	loc := time.UTC
+	if len(args) > 0 {
+		tz, ok := args[0].(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("time zone must be a string")
+		}
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tz)
+		}
+	}
+	return now.In(loc), nil
+}
+
+// Values
+
+// normalize converts attribute values to the types the language works with:
+// every number becomes a float64 and every slice a []interface{}
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil, bool, string, float64, map[string]interface{}, []interface{}:
+		return x
+	case int:
+		return float64(x)
+	case int32:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case float32:
+		return float64(x)
+	case []string:
+		items := make([]interface{}, len(x))
+		for i, s := range x {
+			items[i] = s
+		}
+		return items
+	case fmt.Stringer:
+		return x.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = normalize(rv.Index(i).Interface())
+		}
+		return items
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Convert(reflect.TypeOf(int64(0))).Int())
+	}
+	return fmt.Sprint(v)
+}
+
+func equal(a, b interface{}) bool {
+	a, b = normalize(a), normalize(b)
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// contains reports whether needle is in haystack: an element of a list, a
+// substring of a string or a key of a map
+func contains(haystack, needle interface{}) bool {
+	switch h := normalize(haystack).(type) {
+	case []interface{}:
+		for _, item := range h {
+			if equal(item, needle) {
+				return true
+			}
+		}
+	case string:
+		if s, ok := needle.(string); ok {
+			return strings.Contains(h, s)
+		}
+	case map[string]interface{}:
+		if s, ok := needle.(string); ok {
+			_, exists := h[s]
+			return exists
+		}
+	}
+	return false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
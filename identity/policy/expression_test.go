@@ -0,0 +1,120 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInput() *Input {
+	return &Input{
+		Subject: map[string]interface{}{
+			"id":         "user-1",
+			"department": "support",
+			"level":      3,
+			"roles":      []string{"editor", "viewer"},
+		},
+		Resource: map[string]interface{}{"department": "support", "owner": "user-1"},
+		Context: map[string]interface{}{
+			"ip":   "10.1.2.3",
+			"time": "2026-03-04T22:30:00Z", // a Wednesday
+			"mfa":  true,
+		},
+		Tenant: map[string]interface{}{"id": "tenant-1"},
+	}
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{``, true},
+		{`true`, true},
+		{`subject.department == resource.department`, true},
+		{`subject.id == resource.owner && context.mfa`, true},
+		{`subject.level >= 3 && subject.level < 5`, true},
+		{`subject.level > 3 || false`, false},
+		{`"editor" in subject.roles`, true},
+		{`"admin" in subject.roles`, false},
+		{`subject.department in ["support", "sales"]`, true},
+		{`!(subject.department == "finance")`, true},
+		{`subject.missing == null`, true},
+		{`subject.missing.deeper == null`, true},
+		{`ip_in(context.ip, "10.0.0.0/8")`, true},
+		{`ip_in(context.ip, "192.168.0.0/16", "172.16.0.0/12")`, false},
+		{`time_between("22:00", "06:00")`, true},
+		{`time_between("09:00", "17:00")`, false},
+		{`time_between("09:00", "17:00", "America/Los_Angeles")`, true},
+		{`weekday() == "wednesday"`, true},
+		{`starts_with(lower("SUPPORT-team"), "support")`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			expr, err := Compile(tt.condition)
+			require.NoError(t, err)
+			got, err := expr.Evaluate(testInput())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpression_MFAWithin(t *testing.T) {
+	expr, err := Compile(`mfa_within(300)`)
+	require.NoError(t, err)
+
+	input := testInput()
+	got, err := expr.Evaluate(input)
+	require.NoError(t, err)
+	assert.False(t, got, "unknown MFA age must not satisfy mfa_within")
+
+	input.Context["mfa_age_seconds"] = 120
+	got, err = expr.Evaluate(input)
+	require.NoError(t, err)
+	assert.True(t, got)
+
+	input.Context["mfa_age_seconds"] = 301
+	got, err = expr.Evaluate(input)
+	require.NoError(t, err)
+	assert.False(t, got)
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []string{
+		`subject.department ==`,
+		`user.department == "x"`,
+		`unknown_fn(1)`,
+		`ip_in(context.ip, "not-a-cidr")`,
+		`time_between("25:00", "06:00")`,
+		`time_between("09:00", "17:00", "Mars/Olympus")`,
+		`"unterminated`,
+		`(true`,
+		`true false`,
+		strings.Repeat("(", MaxExpressionDepth+1) + "true" + strings.Repeat(")", MaxExpressionDepth+1),
+		strings.Repeat("a", MaxExpressionLength+1),
+	}
+
+	for _, condition := range tests {
+		_, err := Compile(condition)
+		assert.Error(t, err, condition)
+	}
+}
+
+func TestExpression_EvaluateTypeErrors(t *testing.T) {
+	tests := []string{
+		`subject.department`,
+		`subject.level < "3"`,
+		`!subject.department`,
+	}
+
+	for _, condition := range tests {
+		expr, err := Compile(condition)
+		require.NoError(t, err, condition)
+		_, err = expr.Evaluate(testInput())
+		assert.Error(t, err, condition)
+	}
+}
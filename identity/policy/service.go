@@ -0,0 +1,374 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/permission"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// maxCompiledConditions bounds the compiled condition cache
+const maxCompiledConditions = 1024
+
+// Decision reasons
+const (
+	ReasonNoPolicies         = "no policy applies"
+	ReasonNoDenyMatched      = "no deny policy matched"
+	ReasonAllowedByPolicy    = "allowed by policy"
+	ReasonDeniedByPolicy     = "denied by policy"
+	ReasonNoAllowPolicyMatch = "no allow policy condition was met"
+)
+
+// PolicyRef identifies the policy that decided a request
+type PolicyRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// Evaluation is the outcome of one policy's condition
+type Evaluation struct {
+	PolicyID uuid.UUID `json:"policy_id"`
+	Name     string    `json:"name"`
+	Effect   string    `json:"effect"`
+	Matched  bool      `json:"matched"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Result is the combined outcome of the policies that apply to a request
+type Result struct {
+	Allowed     bool         `json:"allowed"`
+	Reason      string       `json:"reason"`
+	Policy      *PolicyRef   `json:"policy,omitempty"` // The policy that decided the request, if any
+	Evaluations []Evaluation `json:"evaluations"`
+}
+
+// Service manages conditional access policies and evaluates them
+type Service struct {
+	policyRepo interfaces.PolicyRepository
+
+	mu       sync.Mutex
+	compiled map[string]*Expression
+}
+
+// NewService creates a new policy service
+func NewService(policyRepo interfaces.PolicyRepository) *Service {
+	return &Service{
+		policyRepo: policyRepo,
+		compiled:   make(map[string]*Expression),
+	}
+}
+
+// CreatePolicyRequest represents a request to create a policy
+type CreatePolicyRequest struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	Name        string    `json:"name" binding:"required,min=1,max=255"`
+	Description *string   `json:"description,omitempty"`
+	Effect      string    `json:"effect" binding:"required,oneof=allow deny"`
+	Resource    string    `json:"resource" binding:"required"`
+	Action      string    `json:"action" binding:"required"`
+	Condition   string    `json:"condition"`
+	Enabled     *bool     `json:"enabled,omitempty"` // Defaults to true
+}
+
+// UpdatePolicyRequest represents a request to update a policy
+type UpdatePolicyRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Effect      *string `json:"effect,omitempty" binding:"omitempty,oneof=allow deny"`
+	Resource    *string `json:"resource,omitempty"`
+	Action      *string `json:"action,omitempty"`
+	Condition   *string `json:"condition,omitempty"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
+
+// SimulateRequest asks how policies would decide a request. With a condition,
+// only that unsaved condition is evaluated; with a policy ID, only that policy
+// (enabled or not); otherwise every enabled policy that applies.
+type SimulateRequest struct {
+	Resource  string     `json:"resource" binding:"required"`
+	Action    string     `json:"action" binding:"required"`
+	Input     Input      `json:"input"`
+	Condition *string    `json:"condition,omitempty"`
+	Effect    string     `json:"effect,omitempty"` // Effect of the unsaved condition; defaults to allow
+	PolicyID  *uuid.UUID `json:"policy_id,omitempty"`
+}
+
+// Create creates a new policy
+func (s *Service) Create(ctx context.Context, req *CreatePolicyRequest) (*models.Policy, error) {
+	if req.TenantID == uuid.Nil {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("policy name is required")
+	}
+	if existing, _ := s.policyRepo.GetByName(ctx, req.TenantID, name); existing != nil {
+		return nil, fmt.Errorf("policy with name %s already exists", name)
+	}
+
+	policy := &models.Policy{
+		ID:          uuid.New(),
+		TenantID:    req.TenantID,
+		Name:        name,
+		Description: req.Description,
+		Effect:      req.Effect,
+		Resource:    req.Resource,
+		Action:      req.Action,
+		Condition:   req.Condition,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Create(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to create policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// GetByID retrieves a policy by ID
+func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*models.Policy, error) {
+	policy, err := s.policyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Update updates an existing policy
+func (s *Service) Update(ctx context.Context, id uuid.UUID, req *UpdatePolicyRequest) (*models.Policy, error) {
+	policy, err := s.policyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("policy name is required")
+		}
+		if existing, _ := s.policyRepo.GetByName(ctx, policy.TenantID, name); existing != nil && existing.ID != id {
+			return nil, fmt.Errorf("policy name %s is already taken", name)
+		}
+		policy.Name = name
+	}
+	if req.Description != nil {
+		policy.Description = req.Description
+	}
+	if req.Effect != nil {
+		policy.Effect = *req.Effect
+	}
+	if req.Resource != nil {
+		policy.Resource = *req.Resource
+	}
+	if req.Action != nil {
+		policy.Action = *req.Action
+	}
+	if req.Condition != nil {
+		policy.Condition = *req.Condition
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Update(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to update policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Delete deletes a policy
+func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := s.policyRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves all policies of a tenant
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID) ([]*models.Policy, error) {
+	if tenantID == uuid.Nil {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	policies, err := s.policyRepo.List(ctx, tenantID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// Applicable returns the enabled policies of a tenant that apply to resource:action
+func (s *Service) Applicable(ctx context.Context, tenantID uuid.UUID, resource, action string) ([]*models.Policy, error) {
+	policies, err := s.policyRepo.List(ctx, tenantID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	required := permission.Key(resource, action)
+	applicable := make([]*models.Policy, 0, len(policies))
+	for _, p := range policies {
+		if permission.Matches(permission.Key(p.Resource, p.Action), required) {
+			applicable = append(applicable, p)
+		}
+	}
+
+	return applicable, nil
+}
+
+// Decide combines the outcome of the given policies for one request:
+//   - a deny policy whose condition holds denies the request;
+//   - otherwise, when allow policies are present, at least one must hold;
+//   - with no policies the request is allowed.
+//
+// A condition that cannot be evaluated fails closed: it counts as holding for a
+// deny policy and as not holding for an allow policy.
+func (s *Service) Decide(policies []*models.Policy, input *Input) *Result {
+	result := &Result{Allowed: true, Reason: ReasonNoPolicies, Evaluations: make([]Evaluation, 0, len(policies))}
+	if len(policies) == 0 {
+		return result
+	}
+
+	var denied, allowed *models.Policy
+	hasAllow := false
+	for _, p := range policies {
+		matched, err := s.evaluate(p.Condition, input)
+		evaluation := Evaluation{PolicyID: p.ID, Name: p.Name, Effect: p.Effect, Matched: matched}
+		if err != nil {
+			evaluation.Error = err.Error()
+		}
+		result.Evaluations = append(result.Evaluations, evaluation)
+
+		switch p.Effect {
+		case models.PolicyEffectDeny:
+			if denied == nil && (matched || err != nil) {
+				denied = p
+			}
+		default:
+			hasAllow = true
+			if allowed == nil && matched {
+				allowed = p
+			}
+		}
+	}
+
+	switch {
+	case denied != nil:
+		result.Allowed = false
+		result.Reason = ReasonDeniedByPolicy
+		result.Policy = &PolicyRef{ID: denied.ID, Name: denied.Name}
+	case allowed != nil:
+		result.Reason = ReasonAllowedByPolicy
+		result.Policy = &PolicyRef{ID: allowed.ID, Name: allowed.Name}
+	case hasAllow:
+		result.Allowed = false
+		result.Reason = ReasonNoAllowPolicyMatch
+	default:
+		result.Reason = ReasonNoDenyMatched
+	}
+
+	return result
+}
+
+// Evaluate decides a request for resource:action against the tenant's enabled policies
+func (s *Service) Evaluate(ctx context.Context, tenantID uuid.UUID, resource, action string, input *Input) (*Result, error) {
+	policies, err := s.Applicable(ctx, tenantID, resource, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Decide(policies, input), nil
+}
+
+// Simulate shows how policies would decide a request without enforcing anything
+func (s *Service) Simulate(ctx context.Context, tenantID uuid.UUID, req *SimulateRequest) (*Result, error) {
+	if err := permission.ValidatePattern(req.Resource, req.Action); err != nil {
+		return nil, err
+	}
+
+	input := req.Input
+	if input.Tenant == nil {
+		input.Tenant = TenantAttributes(tenantID)
+	}
+
+	switch {
+	case req.Condition != nil:
+		effect := req.Effect
+		if effect == "" {
+			effect = models.PolicyEffectAllow
+		}
+		if effect != models.PolicyEffectAllow && effect != models.PolicyEffectDeny {
+			return nil, fmt.Errorf("effect must be allow or deny")
+		}
+		if _, err := Compile(*req.Condition); err != nil {
+			return nil, fmt.Errorf("invalid condition: %w", err)
+		}
+		draft := &models.Policy{Name: "simulation", Effect: effect, Resource: req.Resource, Action: req.Action, Condition: *req.Condition}
+		return s.Decide([]*models.Policy{draft}, &input), nil
+
+	case req.PolicyID != nil:
+		policy, err := s.policyRepo.GetByID(ctx, *req.PolicyID)
+		if err != nil || policy.TenantID != tenantID {
+			return nil, fmt.Errorf("policy not found")
+		}
+		return s.Decide([]*models.Policy{policy}, &input), nil
+	}
+
+	return s.Evaluate(ctx, tenantID, req.Resource, req.Action, &input)
+}
+
+// evaluate evaluates a condition, reusing compiled expressions
+func (s *Service) evaluate(condition string, input *Input) (bool, error) {
+	s.mu.Lock()
+	expr, ok := s.compiled[condition]
+	s.mu.Unlock()
+
+	if !ok {
+		var err error
+		expr, err = Compile(condition)
+		if err != nil {
+			return false, fmt.Errorf("invalid condition: %w", err)
+		}
+		s.mu.Lock()
+		if len(s.compiled) >= maxCompiledConditions {
+			s.compiled = make(map[string]*Expression)
+		}
+		s.compiled[condition] = expr
+		s.mu.Unlock()
+	}
+
+	return expr.Evaluate(input)
+}
+
+// validatePolicy checks the effect, permission pattern and condition of a policy
+func validatePolicy(policy *models.Policy) error {
+	if policy.Effect != models.PolicyEffectAllow && policy.Effect != models.PolicyEffectDeny {
+		return fmt.Errorf("effect must be allow or deny")
+	}
+	if err := permission.ValidatePattern(policy.Resource, policy.Action); err != nil {
+		return err
+	}
+	if _, err := Compile(policy.Condition); err != nil {
+		return fmt.Errorf("invalid condition: %w", err)
+	}
+	return nil
+}
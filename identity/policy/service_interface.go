@@ -0,0 +1,29 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines the interface for policy service operations
+type ServiceInterface interface {
+	Create(ctx context.Context, req *CreatePolicyRequest) (*models.Policy, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Policy, error)
+	Update(ctx context.Context, id uuid.UUID, req *UpdatePolicyRequest) (*models.Policy, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, tenantID uuid.UUID) ([]*models.Policy, error)
+
+	// Applicable returns the enabled policies of a tenant that apply to resource:action
+	Applicable(ctx context.Context, tenantID uuid.UUID, resource, action string) ([]*models.Policy, error)
+
+	// Decide combines the outcome of the given policies for one request
+	Decide(policies []*models.Policy, input *Input) *Result
+
+	// Evaluate decides a request for resource:action against the tenant's enabled policies
+	Evaluate(ctx context.Context, tenantID uuid.UUID, resource, action string, input *Input) (*Result, error)
+
+	// Simulate shows how policies would decide a request without enforcing anything
+	Simulate(ctx context.Context, tenantID uuid.UUID, req *SimulateRequest) (*Result, error)
+}
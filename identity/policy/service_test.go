@@ -0,0 +1,175 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPolicyRepository is an in-memory policy repository
+type memoryPolicyRepository struct {
+	interfaces.PolicyRepository
+	policies map[uuid.UUID]*models.Policy
+}
+
+func newMemoryPolicyRepository() *memoryPolicyRepository {
+	return &memoryPolicyRepository{policies: make(map[uuid.UUID]*models.Policy)}
+}
+
+func (r *memoryPolicyRepository) Create(ctx context.Context, policy *models.Policy) error {
+	r.policies[policy.ID] = policy
+	return nil
+}
+
+func (r *memoryPolicyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Policy, error) {
+	policy, ok := r.policies[id]
+	if !ok {
+		return nil, fmt.Errorf("policy not found")
+	}
+	return policy, nil
+}
+
+func (r *memoryPolicyRepository) GetByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Policy, error) {
+	for _, policy := range r.policies {
+		if policy.TenantID == tenantID && policy.Name == name {
+			return policy, nil
+		}
+	}
+	return nil, fmt.Errorf("policy not found")
+}
+
+func (r *memoryPolicyRepository) List(ctx context.Context, tenantID uuid.UUID, enabledOnly bool) ([]*models.Policy, error) {
+	var policies []*models.Policy
+	for _, policy := range r.policies {
+		if policy.TenantID == tenantID && (policy.Enabled || !enabledOnly) {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+func newPolicy(effect, resource, action, condition string) *models.Policy {
+	return &models.Policy{
+		ID: uuid.New(), Name: effect + " " + condition,
+		Effect: effect, Resource: resource, Action: action, Condition: condition, Enabled: true,
+	}
+}
+
+func TestService_Decide(t *testing.T) {
+	service := NewService(newMemoryPolicyRepository())
+	input := testInput()
+
+	result := service.Decide(nil, input)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, ReasonNoPolicies, result.Reason)
+
+	offNetwork := newPolicy(models.PolicyEffectDeny, "documents", "*", `!ip_in(context.ip, "10.0.0.0/8")`)
+	result = service.Decide([]*models.Policy{offNetwork}, input)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, ReasonNoDenyMatched, result.Reason)
+
+	sameDepartment := newPolicy(models.PolicyEffectAllow, "documents", "*", `subject.department == resource.department`)
+	otherDepartment := newPolicy(models.PolicyEffectAllow, "documents", "*", `subject.department == "finance"`)
+	result = service.Decide([]*models.Policy{offNetwork, otherDepartment, sameDepartment}, input)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, ReasonAllowedByPolicy, result.Reason)
+	assert.Equal(t, sameDepartment.Name, result.Policy.Name)
+	assert.Len(t, result.Evaluations, 3)
+
+	result = service.Decide([]*models.Policy{otherDepartment}, input)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, ReasonNoAllowPolicyMatch, result.Reason)
+
+	input.Context["ip"] = "203.0.113.7"
+	result = service.Decide([]*models.Policy{sameDepartment, offNetwork}, input)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, ReasonDeniedByPolicy, result.Reason)
+	assert.Equal(t, offNetwork.Name, result.Policy.Name)
+}
+
+func TestService_Decide_FailsClosed(t *testing.T) {
+	service := NewService(newMemoryPolicyRepository())
+	broken := newPolicy(models.PolicyEffectDeny, "documents", "*", `subject.level < "3"`)
+
+	result := service.Decide([]*models.Policy{broken}, testInput())
+	assert.False(t, result.Allowed)
+	assert.Equal(t, ReasonDeniedByPolicy, result.Reason)
+	assert.NotEmpty(t, result.Evaluations[0].Error)
+
+	broken.Effect = models.PolicyEffectAllow
+	result = service.Decide([]*models.Policy{broken}, testInput())
+	assert.False(t, result.Allowed)
+	assert.Equal(t, ReasonNoAllowPolicyMatch, result.Reason)
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	service := NewService(newMemoryPolicyRepository())
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	_, err := service.Create(ctx, &CreatePolicyRequest{
+		TenantID: tenantID, Name: "bad", Effect: models.PolicyEffectAllow,
+		Resource: "documents", Action: "read", Condition: `subject.department ==`,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid condition")
+
+	created, err := service.Create(ctx, &CreatePolicyRequest{
+		TenantID: tenantID, Name: "office-hours", Effect: models.PolicyEffectAllow,
+		Resource: "documents", Action: "*", Condition: `time_between("09:00", "17:00")`,
+	})
+	require.NoError(t, err)
+	assert.True(t, created.Enabled)
+
+	_, err = service.Create(ctx, &CreatePolicyRequest{
+		TenantID: tenantID, Name: "office-hours", Effect: models.PolicyEffectDeny,
+		Resource: "documents", Action: "*",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
+
+func TestService_Simulate(t *testing.T) {
+	repo := newMemoryPolicyRepository()
+	service := NewService(repo)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	disabled := newPolicy(models.PolicyEffectAllow, "documents", "read", `subject.department == "finance"`)
+	disabled.TenantID = tenantID
+	disabled.Enabled = false
+	repo.policies[disabled.ID] = disabled
+
+	// Disabled policies are not enforced
+	result, err := service.Simulate(ctx, tenantID, &SimulateRequest{Resource: "documents", Action: "read", Input: *testInput()})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, ReasonNoPolicies, result.Reason)
+
+	// ...but can be simulated directly
+	result, err = service.Simulate(ctx, tenantID, &SimulateRequest{Resource: "documents", Action: "read", Input: *testInput(), PolicyID: &disabled.ID})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// Policies of other tenants cannot
+	_, err = service.Simulate(ctx, uuid.New(), &SimulateRequest{Resource: "documents", Action: "read", PolicyID: &disabled.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	condition := `"editor" in subject.roles`
+	result, err = service.Simulate(ctx, tenantID, &SimulateRequest{Resource: "documents", Action: "read", Input: *testInput(), Condition: &condition})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, ReasonAllowedByPolicy, result.Reason)
+
+	invalid := `"editor" in`
+	_, err = service.Simulate(ctx, tenantID, &SimulateRequest{Resource: "documents", Action: "read", Condition: &invalid})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid condition")
+}
@@ -0,0 +1,86 @@
+package relation
+
+import (
+	"fmt"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// ValidateNamespaceConfig checks that every rewrite of a namespace is well formed
+// and only refers to relations the namespace defines. The computed relation of a
+// tuple_to_userset lives in other namespaces; objects whose namespace does not
+// define it are skipped during evaluation.
+func ValidateNamespaceConfig(config *models.NamespaceConfig) error {
+	if len(config.Relations) == 0 {
+		return fmt.Errorf("namespace must define at least one relation")
+	}
+	for name, rewrite := range config.Relations {
+		if err := validateName("relation", name); err != nil {
+			return err
+		}
+		if rewrite == nil {
+			continue
+		}
+		if err := validateRewrite(config, rewrite, 0); err != nil {
+			return fmt.Errorf("relation %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func validateRewrite(config *models.NamespaceConfig, rewrite *models.UsersetRewrite, depth int) error {
+	if rewrite == nil {
+		return fmt.Errorf("empty rewrite")
+	}
+	if depth > MaxDepth {
+		return fmt.Errorf("rewrite nested more than %d levels", MaxDepth)
+	}
+
+	set := 0
+	if rewrite.This {
+		set++
+	}
+	if rewrite.ComputedUserset != "" {
+		set++
+		if _, ok := config.Relations[rewrite.ComputedUserset]; !ok {
+			return fmt.Errorf("computed_userset refers to undefined relation %s", rewrite.ComputedUserset)
+		}
+	}
+	if rewrite.TupleToUserset != nil {
+		set++
+		if _, ok := config.Relations[rewrite.TupleToUserset.Tupleset]; !ok {
+			return fmt.Errorf("tuple_to_userset refers to undefined relation %s", rewrite.TupleToUserset.Tupleset)
+		}
+		if err := validateName("relation", rewrite.TupleToUserset.ComputedUserset); err != nil {
+			return err
+		}
+	}
+	for _, children := range [][]*models.UsersetRewrite{rewrite.Union, rewrite.Intersection} {
+		if children == nil {
+			continue
+		}
+		set++
+		if len(children) == 0 {
+			return fmt.Errorf("union and intersection need at least one child")
+		}
+		for _, child := range children {
+			if err := validateRewrite(config, child, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	if rewrite.Exclusion != nil {
+		set++
+		if err := validateRewrite(config, rewrite.Exclusion.Base, depth+1); err != nil {
+			return err
+		}
+		if err := validateRewrite(config, rewrite.Exclusion.Subtract, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if set != 1 {
+		return fmt.Errorf("a rewrite must set exactly one of this, computed_userset, tuple_to_userset, union, intersection or exclusion")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package relation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Consistency tokens are opaque to clients. A token names a tenant revision: a
+// read that presents it is evaluated at that revision or a later one, so it sees
+// every write up to the one that returned the token.
+
+// EncodeToken returns the consistency token of a tenant revision
+func EncodeToken(tenantID uuid.UUID, revision int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(tenantID.String() + "/" + strconv.FormatInt(revision, 10)))
+}
+
+// DecodeToken returns the revision named by a tenant's consistency token
+func DecodeToken(tenantID uuid.UUID, token string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid consistency token")
+	}
+	tenant, revisionPart, ok := strings.Cut(string(raw), "/")
+	if !ok || tenant != tenantID.String() {
+		return 0, fmt.Errorf("invalid consistency token")
+	}
+	revision, err := strconv.ParseInt(revisionPart, 10, 64)
+	if err != nil || revision < 0 {
+		return 0, fmt.Errorf("invalid consistency token")
+	}
+	return revision, nil
+}
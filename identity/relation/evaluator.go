@@ -0,0 +1,314 @@
+package relation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// evaluator evaluates checks and expansions against a tenant's tuples. It
+// remembers namespace configs and, for checks, results for one subject, so it
+// must not outlive a single request.
+type evaluator struct {
+	ctx        context.Context
+	repo       interfaces.RelationRepository
+	tenantID   uuid.UUID
+	subject    Subject
+	namespaces map[string]*models.NamespaceConfig
+	active     map[string]bool
+	memo       map[string]bool
+	cycles     int
+}
+
+func newEvaluator(ctx context.Context, repo interfaces.RelationRepository, tenantID uuid.UUID, subject Subject) *evaluator {
+	return &evaluator{
+		ctx:        ctx,
+		repo:       repo,
+		tenantID:   tenantID,
+		subject:    subject,
+		namespaces: make(map[string]*models.NamespaceConfig),
+		active:     make(map[string]bool),
+		memo:       make(map[string]bool),
+	}
+}
+
+// config returns the config of a namespace, or nil when the tenant does not define it
+func (e *evaluator) config(namespace string) (*models.NamespaceConfig, error) {
+	if config, ok := e.namespaces[namespace]; ok {
+		return config, nil
+	}
+	ns, err := e.repo.GetNamespace(e.ctx, e.tenantID, namespace)
+	if err != nil {
+		if err.Error() == "namespace not found" {
+			e.namespaces[namespace] = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	e.namespaces[namespace] = &ns.Config
+	return &ns.Config, nil
+}
+
+// rewrite returns the rewrite of a relation. A relation without a rewrite has only its own tuples.
+func (e *evaluator) rewrite(namespace, relation string) (*models.UsersetRewrite, error) {
+	config, err := e.config(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, fmt.Errorf("namespace %s not found", namespace)
+	}
+	rewrite, ok := config.Relations[relation]
+	if !ok {
+		return nil, fmt.Errorf("relation %s is not defined in namespace %s", relation, namespace)
+	}
+	if rewrite == nil {
+		return &models.UsersetRewrite{This: true}, nil
+	}
+	return rewrite, nil
+}
+
+// defines reports whether a namespace exists and defines a relation
+func (e *evaluator) defines(namespace, relation string) (bool, error) {
+	config, err := e.config(namespace)
+	if err != nil || config == nil {
+		return false, err
+	}
+	_, ok := config.Relations[relation]
+	return ok, nil
+}
+
+// check reports whether the evaluator's subject has relation to object
+func (e *evaluator) check(object Object, relation string, depth int) (bool, error) {
+	if depth > MaxDepth {
+		return false, fmt.Errorf("relationship check exceeded maximum depth of %d", MaxDepth)
+	}
+
+	// A userset always contains itself
+	if e.subject.Relation == relation && e.subject.Namespace == object.Namespace && e.subject.ID == object.ID {
+		return true, nil
+	}
+
+	key := object.String() + "#" + relation
+	if result, ok := e.memo[key]; ok {
+		return result, nil
+	}
+	if e.active[key] {
+		// Following a cycle again cannot reach new subjects
+		e.cycles++
+		return false, nil
+	}
+	e.active[key] = true
+	defer delete(e.active, key)
+
+	rewrite, err := e.rewrite(object.Namespace, relation)
+	if err != nil {
+		return false, err
+	}
+
+	cycles := e.cycles
+	result, err := e.checkRewrite(object, relation, rewrite, depth)
+	if err != nil {
+		return false, err
+	}
+	// A result reached by cutting a cycle short may be incomplete for other callers
+	if e.cycles == cycles {
+		e.memo[key] = result
+	}
+	return result, nil
+}
+
+func (e *evaluator) checkRewrite(object Object, relation string, rewrite *models.UsersetRewrite, depth int) (bool, error) {
+	switch {
+	case rewrite.This:
+		tuples, err := e.tuples(object, relation)
+		if err != nil {
+			return false, err
+		}
+		for _, t := range tuples {
+			subject := tupleSubject(t)
+			if subject == e.subject {
+				return true, nil
+			}
+			if subject.Relation == "" {
+				continue
+			}
+			ok, err := e.check(Object{Namespace: subject.Namespace, ID: subject.ID}, subject.Relation, depth+1)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+
+	case rewrite.ComputedUserset != "":
+		return e.check(object, rewrite.ComputedUserset, depth+1)
+
+	case rewrite.TupleToUserset != nil:
+		targets, err := e.tupleTargets(object, rewrite.TupleToUserset)
+		if err != nil {
+			return false, err
+		}
+		for _, target := range targets {
+			ok, err := e.check(target, rewrite.TupleToUserset.ComputedUserset, depth+1)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+
+	case rewrite.Union != nil:
+		for _, child := range rewrite.Union {
+			ok, err := e.checkRewrite(object, relation, child, depth+1)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+
+	case rewrite.Intersection != nil:
+		for _, child := range rewrite.Intersection {
+			ok, err := e.checkRewrite(object, relation, child, depth+1)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case rewrite.Exclusion != nil:
+		ok, err := e.checkRewrite(object, relation, rewrite.Exclusion.Base, depth+1)
+		if err != nil || !ok {
+			return false, err
+		}
+		excluded, err := e.checkRewrite(object, relation, rewrite.Exclusion.Subtract, depth+1)
+		if err != nil {
+			return false, err
+		}
+		return !excluded, nil
+	}
+
+	return false, fmt.Errorf("relation %s of namespace %s has an empty rewrite", relation, object.Namespace)
+}
+
+// expand returns the userset tree of an object's relation
+func (e *evaluator) expand(object Object, relation string, depth int) (*UsersetTree, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("relationship expand exceeded maximum depth of %d", MaxDepth)
+	}
+
+	key := object.String() + "#" + relation
+	if e.active[key] {
+		return &UsersetTree{Operation: OperationLeaf, Userset: key}, nil
+	}
+	e.active[key] = true
+	defer delete(e.active, key)
+
+	rewrite, err := e.rewrite(object.Namespace, relation)
+	if err != nil {
+		return nil, err
+	}
+	node, err := e.expandRewrite(object, relation, rewrite, depth)
+	if err != nil {
+		return nil, err
+	}
+	if node.Userset != "" {
+		// The node computes another relation; keep both names in the tree
+		node = &UsersetTree{Operation: OperationUnion, Children: []*UsersetTree{node}}
+	}
+	node.Userset = key
+	return node, nil
+}
+
+func (e *evaluator) expandRewrite(object Object, relation string, rewrite *models.UsersetRewrite, depth int) (*UsersetTree, error) {
+	switch {
+	case rewrite.This:
+		tuples, err := e.tuples(object, relation)
+		if err != nil {
+			return nil, err
+		}
+		subjects := make([]string, 0, len(tuples))
+		for _, t := range tuples {
+			subjects = append(subjects, tupleSubject(t).String())
+		}
+		return &UsersetTree{Operation: OperationLeaf, Subjects: subjects}, nil
+
+	case rewrite.ComputedUserset != "":
+		return e.expand(object, rewrite.ComputedUserset, depth+1)
+
+	case rewrite.TupleToUserset != nil:
+		targets, err := e.tupleTargets(object, rewrite.TupleToUserset)
+		if err != nil {
+			return nil, err
+		}
+		node := &UsersetTree{Operation: OperationUnion}
+		for _, target := range targets {
+			child, err := e.expand(target, rewrite.TupleToUserset.ComputedUserset, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+		return node, nil
+
+	case rewrite.Union != nil, rewrite.Intersection != nil:
+		node := &UsersetTree{Operation: OperationUnion}
+		children := rewrite.Union
+		if rewrite.Intersection != nil {
+			node.Operation = OperationIntersection
+			children = rewrite.Intersection
+		}
+		for _, child := range children {
+			childNode, err := e.expandRewrite(object, relation, child, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, childNode)
+		}
+		return node, nil
+
+	case rewrite.Exclusion != nil:
+		base, err := e.expandRewrite(object, relation, rewrite.Exclusion.Base, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		subtract, err := e.expandRewrite(object, relation, rewrite.Exclusion.Subtract, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return &UsersetTree{Operation: OperationExclusion, Children: []*UsersetTree{base, subtract}}, nil
+	}
+
+	return nil, fmt.Errorf("relation %s of namespace %s has an empty rewrite", relation, object.Namespace)
+}
+
+// tuples returns the tuples of an object's relation
+func (e *evaluator) tuples(object Object, relation string) ([]*models.RelationTuple, error) {
+	return e.repo.ListTuples(e.ctx, e.tenantID, interfaces.RelationTupleFilter{
+		Namespace: object.Namespace,
+		ObjectID:  object.ID,
+		Relation:  relation,
+	})
+}
+
+// tupleTargets returns the objects an object's tupleset relation points to that
+// define the computed relation. Objects of other namespaces (a document's parent
+// may be a folder or a drive) are skipped when they do not define it.
+func (e *evaluator) tupleTargets(object Object, ttu *models.TupleToUserset) ([]Object, error) {
+	tuples, err := e.tuples(object, ttu.Tupleset)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]Object, 0, len(tuples))
+	for _, t := range tuples {
+		ok, err := e.defines(t.SubjectNamespace, ttu.ComputedUserset)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			targets = append(targets, Object{Namespace: t.SubjectNamespace, ID: t.SubjectID})
+		}
+	}
+	return targets, nil
+}
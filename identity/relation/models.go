@@ -0,0 +1,99 @@
+package relation
+
+// Limits on relationship requests
+const (
+	// MaxDepth bounds how deeply a check or expand follows usersets and rewrites
+	MaxDepth = 32
+
+	// MaxWriteBatch bounds the number of tuples written and deleted in one request
+	MaxWriteBatch = 100
+
+	// MaxReadLimit bounds the number of tuples returned by one read
+	MaxReadLimit = 1000
+
+	// MaxListObjectsCandidates bounds the objects of a namespace a list-objects request considers
+	MaxListObjectsCandidates = 10000
+
+	// DefaultListObjectsLimit and MaxListObjectsLimit bound the objects a list-objects request returns
+	DefaultListObjectsLimit = 100
+	MaxListObjectsLimit     = 1000
+)
+
+// WriteRequest inserts and deletes tuples atomically
+type WriteRequest struct {
+	Writes  []TupleKey `json:"writes"`
+	Deletes []TupleKey `json:"deletes"`
+}
+
+// WriteResponse returns the consistency token of a write
+type WriteResponse struct {
+	ConsistencyToken string `json:"consistency_token"`
+}
+
+// ReadRequest selects tuples. Object is either namespace:id or just a namespace.
+type ReadRequest struct {
+	Object   string `form:"object"`
+	Relation string `form:"relation"`
+	Subject  string `form:"subject"` // namespace:id or namespace:id#relation
+	Limit    int    `form:"limit"`
+}
+
+// CheckRequest asks whether a subject has a relation to an object
+type CheckRequest struct {
+	Object           string `json:"object" binding:"required"`
+	Relation         string `json:"relation" binding:"required"`
+	Subject          string `json:"subject" binding:"required"`
+	ConsistencyToken string `json:"consistency_token,omitempty"` // Evaluate at least as fresh as this token
+}
+
+// CheckResponse is the result of a relationship check
+type CheckResponse struct {
+	Allowed          bool   `json:"allowed"`
+	ConsistencyToken string `json:"consistency_token"` // Revision the check was evaluated at
+}
+
+// ExpandRequest asks for the subjects of an object's relation
+type ExpandRequest struct {
+	Object           string `json:"object" binding:"required"`
+	Relation         string `json:"relation" binding:"required"`
+	ConsistencyToken string `json:"consistency_token,omitempty"`
+}
+
+// ExpandResponse holds the userset tree of an object's relation
+type ExpandResponse struct {
+	Tree             *UsersetTree `json:"tree"`
+	ConsistencyToken string       `json:"consistency_token"`
+}
+
+// UsersetTree shows how the subjects of a relation are computed. Leaves list the
+// subjects of tuples; userset subjects (namespace:id#relation) can be expanded in turn.
+type UsersetTree struct {
+	Operation string         `json:"operation"` // leaf, union, intersection or exclusion
+	Userset   string         `json:"userset,omitempty"`
+	Subjects  []string       `json:"subjects,omitempty"`
+	Children  []*UsersetTree `json:"children,omitempty"`
+}
+
+// Userset tree operations
+const (
+	OperationLeaf         = "leaf"
+	OperationUnion        = "union"
+	OperationIntersection = "intersection"
+	OperationExclusion    = "exclusion"
+)
+
+// ListObjectsRequest asks which objects of a namespace a subject has a relation to
+type ListObjectsRequest struct {
+	Namespace        string `json:"namespace" binding:"required"`
+	Relation         string `json:"relation" binding:"required"`
+	Subject          string `json:"subject" binding:"required"`
+	Limit            int    `json:"limit,omitempty"`
+	ConsistencyToken string `json:"consistency_token,omitempty"`
+}
+
+// ListObjectsResponse lists the objects a subject has a relation to
+type ListObjectsResponse struct {
+	Objects          []string `json:"objects"`
+	Truncated        bool     `json:"truncated"` // More objects may match than were considered or returned
+	ConsistencyToken string   `json:"consistency_token"`
+}
@@ -0,0 +1,365 @@
+package relation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/cache"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// DefaultCacheTTL is how long check results are cached. Results are cached per
+// tenant revision, so a write makes every earlier result unreachable.
+const DefaultCacheTTL = 10 * time.Second
+
+// Service manages relationship tuples and answers relationship queries
+type Service struct {
+	relationRepo interfaces.RelationRepository
+	cache        cache.CacheInterface
+	cacheTTL     time.Duration
+}
+
+// NewService creates a new relationship service.
+// cacheClient may be nil, in which case every check is evaluated against the store.
+func NewService(relationRepo interfaces.RelationRepository, cacheClient cache.CacheInterface, cacheTTL time.Duration) *Service {
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultCacheTTL
+	}
+	return &Service{
+		relationRepo: relationRepo,
+		cache:        cacheClient,
+		cacheTTL:     cacheTTL,
+	}
+}
+
+// checkCacheEntry is a cached check result
+type checkCacheEntry struct {
+	Allowed bool `json:"allowed"`
+}
+
+// PutNamespace creates or replaces a namespace definition and returns the consistency token of the change
+func (s *Service) PutNamespace(ctx context.Context, tenantID uuid.UUID, name string, config *models.NamespaceConfig) (*models.RelationNamespace, string, error) {
+	if err := validateName("namespace", name); err != nil {
+		return nil, "", err
+	}
+	if err := ValidateNamespaceConfig(config); err != nil {
+		return nil, "", fmt.Errorf("invalid namespace config: %w", err)
+	}
+
+	namespace := &models.RelationNamespace{TenantID: tenantID, Name: name, Config: *config}
+	revision, err := s.relationRepo.UpsertNamespace(ctx, namespace)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to save namespace: %w", err)
+	}
+
+	return namespace, EncodeToken(tenantID, revision), nil
+}
+
+// GetNamespace retrieves a namespace definition
+func (s *Service) GetNamespace(ctx context.Context, tenantID uuid.UUID, name string) (*models.RelationNamespace, error) {
+	namespace, err := s.relationRepo.GetNamespace(ctx, tenantID, name)
+	if err != nil {
+		return nil, fmt.Errorf("namespace not found: %w", err)
+	}
+
+	return namespace, nil
+}
+
+// ListNamespaces retrieves all namespace definitions of a tenant
+func (s *Service) ListNamespaces(ctx context.Context, tenantID uuid.UUID) ([]*models.RelationNamespace, error) {
+	namespaces, err := s.relationRepo.ListNamespaces(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	return namespaces, nil
+}
+
+// DeleteNamespace deletes a namespace definition and its tuples and returns the consistency token of the change
+func (s *Service) DeleteNamespace(ctx context.Context, tenantID uuid.UUID, name string) (string, error) {
+	revision, err := s.relationRepo.DeleteNamespace(ctx, tenantID, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete namespace: %w", err)
+	}
+
+	return EncodeToken(tenantID, revision), nil
+}
+
+// WriteTuples atomically inserts and deletes tuples. Written tuples must name a
+// relation that can be assigned directly, and userset subjects must name a
+// defined relation; deletes are accepted as long as they parse.
+func (s *Service) WriteTuples(ctx context.Context, tenantID uuid.UUID, req *WriteRequest) (*WriteResponse, error) {
+	total := len(req.Writes) + len(req.Deletes)
+	if total == 0 {
+		return nil, fmt.Errorf("at least one write or delete is required")
+	}
+	if total > MaxWriteBatch {
+		return nil, fmt.Errorf("at most %d tuples can be written or deleted at once", MaxWriteBatch)
+	}
+
+	e := newEvaluator(ctx, s.relationRepo, tenantID, Subject{})
+	writes := make([]*models.RelationTuple, 0, len(req.Writes))
+	for _, key := range req.Writes {
+		t, err := ParseTupleKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateWrite(e, t); err != nil {
+			return nil, err
+		}
+		writes = append(writes, t)
+	}
+	deletes := make([]*models.RelationTuple, 0, len(req.Deletes))
+	for _, key := range req.Deletes {
+		t, err := ParseTupleKey(key)
+		if err != nil {
+			return nil, err
+		}
+		deletes = append(deletes, t)
+	}
+
+	revision, err := s.relationRepo.WriteTuples(ctx, tenantID, writes, deletes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write tuples: %w", err)
+	}
+
+	return &WriteResponse{ConsistencyToken: EncodeToken(tenantID, revision)}, nil
+}
+
+// ReadTuples retrieves a tenant's tuples
+func (s *Service) ReadTuples(ctx context.Context, tenantID uuid.UUID, req *ReadRequest) ([]*models.RelationTuple, error) {
+	filter := interfaces.RelationTupleFilter{Relation: req.Relation, Limit: req.Limit}
+	if filter.Limit <= 0 || filter.Limit > MaxReadLimit {
+		filter.Limit = MaxReadLimit
+	}
+
+	if req.Object != "" {
+		if strings.Contains(req.Object, ":") {
+			object, err := ParseObject(req.Object)
+			if err != nil {
+				return nil, err
+			}
+			filter.Namespace, filter.ObjectID = object.Namespace, object.ID
+		} else {
+			if err := validateName("namespace", req.Object); err != nil {
+				return nil, err
+			}
+			filter.Namespace = req.Object
+		}
+	}
+	if req.Subject != "" {
+		subject, err := ParseSubject(req.Subject)
+		if err != nil {
+			return nil, err
+		}
+		filter.SubjectNamespace, filter.SubjectID = subject.Namespace, subject.ID
+		filter.SubjectRelation = &subject.Relation
+	}
+
+	tuples, err := s.relationRepo.ListTuples(ctx, tenantID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	return tuples, nil
+}
+
+// Check reports whether a subject has a relation to an object, directly or
+// through the namespace's rewrites
+func (s *Service) Check(ctx context.Context, tenantID uuid.UUID, req *CheckRequest) (*CheckResponse, error) {
+	object, err := ParseObject(req.Object)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateName("relation", req.Relation); err != nil {
+		return nil, err
+	}
+	subject, err := ParseSubject(req.Subject)
+	if err != nil {
+		return nil, err
+	}
+	minRevision, err := decodeOptionalToken(tenantID, req.ConsistencyToken)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.revision(ctx, tenantID, minRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	// A result is only reused at the revision it was evaluated at, so a
+	// revoked tuple is never served from the cache
+	key := checkCacheKey(tenantID, revision, object, req.Relation, subject)
+	if s.cache != nil {
+		var cached checkCacheEntry
+		if err := s.cache.Get(ctx, key, &cached); err == nil {
+			return &CheckResponse{Allowed: cached.Allowed, ConsistencyToken: EncodeToken(tenantID, revision)}, nil
+		}
+	}
+
+	allowed, err := newEvaluator(ctx, s.relationRepo, tenantID, subject).check(object, req.Relation, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		// Best effort: a failed cache write only costs a re-evaluation
+		_ = s.cache.Set(ctx, key, checkCacheEntry{Allowed: allowed}, s.cacheTTL)
+	}
+
+	return &CheckResponse{Allowed: allowed, ConsistencyToken: EncodeToken(tenantID, revision)}, nil
+}
+
+// Expand returns the userset tree of an object's relation
+func (s *Service) Expand(ctx context.Context, tenantID uuid.UUID, req *ExpandRequest) (*ExpandResponse, error) {
+	object, err := ParseObject(req.Object)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateName("relation", req.Relation); err != nil {
+		return nil, err
+	}
+	minRevision, err := decodeOptionalToken(tenantID, req.ConsistencyToken)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.revision(ctx, tenantID, minRevision)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := newEvaluator(ctx, s.relationRepo, tenantID, Subject{}).expand(object, req.Relation, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExpandResponse{Tree: tree, ConsistencyToken: EncodeToken(tenantID, revision)}, nil
+}
+
+// ListObjects returns the objects of a namespace a subject has a relation to.
+// Only objects that appear in tuples can match, and at most
+// MaxListObjectsCandidates of them are considered.
+func (s *Service) ListObjects(ctx context.Context, tenantID uuid.UUID, req *ListObjectsRequest) (*ListObjectsResponse, error) {
+	if err := validateName("namespace", req.Namespace); err != nil {
+		return nil, err
+	}
+	subject, err := ParseSubject(req.Subject)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultListObjectsLimit
+	}
+	if limit > MaxListObjectsLimit {
+		limit = MaxListObjectsLimit
+	}
+	minRevision, err := decodeOptionalToken(tenantID, req.ConsistencyToken)
+	if err != nil {
+		return nil, err
+	}
+
+	e := newEvaluator(ctx, s.relationRepo, tenantID, subject)
+	if _, err := e.rewrite(req.Namespace, req.Relation); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.revision(ctx, tenantID, minRevision)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.relationRepo.ListObjectIDs(ctx, tenantID, req.Namespace, MaxListObjectsCandidates+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	response := &ListObjectsResponse{Objects: []string{}, ConsistencyToken: EncodeToken(tenantID, revision)}
+	if len(candidates) > MaxListObjectsCandidates {
+		candidates = candidates[:MaxListObjectsCandidates]
+		response.Truncated = true
+	}
+	for _, id := range candidates {
+		object := Object{Namespace: req.Namespace, ID: id}
+		ok, err := e.check(object, req.Relation, 0)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if len(response.Objects) == limit {
+			response.Truncated = true
+			break
+		}
+		response.Objects = append(response.Objects, object.String())
+	}
+
+	return response, nil
+}
+
+// revision returns the tenant's current revision, which a read evaluated now is
+// at least as fresh as
+func (s *Service) revision(ctx context.Context, tenantID uuid.UUID, minRevision int64) (int64, error) {
+	revision, err := s.relationRepo.CurrentRevision(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	if revision < minRevision {
+		return 0, fmt.Errorf("invalid consistency token")
+	}
+	return revision, nil
+}
+
+// validateWrite checks that a tuple can be written under the tenant's namespace configs
+func validateWrite(e *evaluator, t *models.RelationTuple) error {
+	rewrite, err := e.rewrite(t.Namespace, t.Relation)
+	if err != nil {
+		return err
+	}
+	if !assignable(rewrite) {
+		return fmt.Errorf("relation %s of namespace %s is computed and cannot be written", t.Relation, t.Namespace)
+	}
+	if t.SubjectRelation != "" {
+		if _, err := e.rewrite(t.SubjectNamespace, t.SubjectRelation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignable reports whether a rewrite includes the relation's own tuples
+func assignable(rewrite *models.UsersetRewrite) bool {
+	switch {
+	case rewrite == nil:
+		return false
+	case rewrite.This:
+		return true
+	case rewrite.Exclusion != nil:
+		return assignable(rewrite.Exclusion.Base)
+	}
+	for _, children := range [][]*models.UsersetRewrite{rewrite.Union, rewrite.Intersection} {
+		for _, child := range children {
+			if assignable(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeOptionalToken(tenantID uuid.UUID, token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	return DecodeToken(tenantID, token)
+}
+
+// checkCacheKey is the cache key of a check result evaluated at a tenant revision
+func checkCacheKey(tenantID uuid.UUID, revision int64, object Object, relation string, subject Subject) string {
+	return fmt.Sprintf("relation:check:%s:%d:%s#%s@%s", tenantID, revision, object, relation, subject)
+}
@@ -0,0 +1,31 @@
+package relation
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines the interface for relationship service operations
+type ServiceInterface interface {
+	PutNamespace(ctx context.Context, tenantID uuid.UUID, name string, config *models.NamespaceConfig) (*models.RelationNamespace, string, error)
+	GetNamespace(ctx context.Context, tenantID uuid.UUID, name string) (*models.RelationNamespace, error)
+	ListNamespaces(ctx context.Context, tenantID uuid.UUID) ([]*models.RelationNamespace, error)
+	DeleteNamespace(ctx context.Context, tenantID uuid.UUID, name string) (string, error)
+
+	// WriteTuples atomically inserts and deletes tuples
+	WriteTuples(ctx context.Context, tenantID uuid.UUID, req *WriteRequest) (*WriteResponse, error)
+
+	// ReadTuples retrieves tuples matching an object, relation and subject
+	ReadTuples(ctx context.Context, tenantID uuid.UUID, req *ReadRequest) ([]*models.RelationTuple, error)
+
+	// Check reports whether a subject has a relation to an object
+	Check(ctx context.Context, tenantID uuid.UUID, req *CheckRequest) (*CheckResponse, error)
+
+	// Expand returns the userset tree of an object's relation
+	Expand(ctx context.Context, tenantID uuid.UUID, req *ExpandRequest) (*ExpandResponse, error)
+
+	// ListObjects returns the objects of a namespace a subject has a relation to
+	ListObjects(ctx context.Context, tenantID uuid.UUID, req *ListObjectsRequest) (*ListObjectsResponse, error)
+}
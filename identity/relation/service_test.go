@@ -0,0 +1,378 @@
+package relation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/cache"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRelationRepository is an in-memory relationship repository
+type memoryRelationRepository struct {
+	namespaces map[string]*models.RelationNamespace
+	tuples     map[string]*models.RelationTuple
+	revision   int64
+}
+
+func newMemoryRelationRepository() *memoryRelationRepository {
+	return &memoryRelationRepository{
+		namespaces: make(map[string]*models.RelationNamespace),
+		tuples:     make(map[string]*models.RelationTuple),
+	}
+}
+
+func (r *memoryRelationRepository) UpsertNamespace(ctx context.Context, namespace *models.RelationNamespace) (int64, error) {
+	r.namespaces[namespace.TenantID.String()+namespace.Name] = namespace
+	r.revision++
+	return r.revision, nil
+}
+
+func (r *memoryRelationRepository) GetNamespace(ctx context.Context, tenantID uuid.UUID, name string) (*models.RelationNamespace, error) {
+	namespace, ok := r.namespaces[tenantID.String()+name]
+	if !ok {
+		return nil, fmt.Errorf("namespace not found")
+	}
+	return namespace, nil
+}
+
+func (r *memoryRelationRepository) ListNamespaces(ctx context.Context, tenantID uuid.UUID) ([]*models.RelationNamespace, error) {
+	var namespaces []*models.RelationNamespace
+	for _, namespace := range r.namespaces {
+		if namespace.TenantID == tenantID {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces, nil
+}
+
+func (r *memoryRelationRepository) DeleteNamespace(ctx context.Context, tenantID uuid.UUID, name string) (int64, error) {
+	if _, ok := r.namespaces[tenantID.String()+name]; !ok {
+		return 0, fmt.Errorf("namespace not found")
+	}
+	delete(r.namespaces, tenantID.String()+name)
+	r.revision++
+	return r.revision, nil
+}
+
+func (r *memoryRelationRepository) WriteTuples(ctx context.Context, tenantID uuid.UUID, writes, deletes []*models.RelationTuple) (int64, error) {
+	r.revision++
+	for _, t := range deletes {
+		delete(r.tuples, tenantID.String()+FormatTuple(t))
+	}
+	for _, t := range writes {
+		t.TenantID = tenantID
+		t.Revision = r.revision
+		r.tuples[tenantID.String()+FormatTuple(t)] = t
+	}
+	return r.revision, nil
+}
+
+func (r *memoryRelationRepository) ListTuples(ctx context.Context, tenantID uuid.UUID, filter interfaces.RelationTupleFilter) ([]*models.RelationTuple, error) {
+	var tuples []*models.RelationTuple
+	for _, t := range r.tuples {
+		if t.TenantID != tenantID ||
+			(filter.Namespace != "" && t.Namespace != filter.Namespace) ||
+			(filter.ObjectID != "" && t.ObjectID != filter.ObjectID) ||
+			(filter.Relation != "" && t.Relation != filter.Relation) ||
+			(filter.SubjectNamespace != "" && t.SubjectNamespace != filter.SubjectNamespace) ||
+			(filter.SubjectID != "" && t.SubjectID != filter.SubjectID) ||
+			(filter.SubjectRelation != nil && t.SubjectRelation != *filter.SubjectRelation) {
+			continue
+		}
+		tuples = append(tuples, t)
+	}
+	sort.Slice(tuples, func(i, j int) bool { return FormatTuple(tuples[i]) < FormatTuple(tuples[j]) })
+	return tuples, nil
+}
+
+func (r *memoryRelationRepository) ListObjectIDs(ctx context.Context, tenantID uuid.UUID, namespace string, limit int) ([]string, error) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, t := range r.tuples {
+		if t.TenantID == tenantID && t.Namespace == namespace && !seen[t.ObjectID] {
+			seen[t.ObjectID] = true
+			ids = append(ids, t.ObjectID)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (r *memoryRelationRepository) CurrentRevision(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	return r.revision, nil
+}
+
+// relationFixture sets up the folder/document model from the Zanzibar paper
+type relationFixture struct {
+	repo     *memoryRelationRepository
+	service  *Service
+	tenantID uuid.UUID
+}
+
+func newRelationFixture(t *testing.T, cacheClient cache.CacheInterface) *relationFixture {
+	f := &relationFixture{
+		repo:     newMemoryRelationRepository(),
+		tenantID: uuid.New(),
+	}
+	f.service = NewService(f.repo, cacheClient, 0)
+	ctx := context.Background()
+
+	_, _, err := f.service.PutNamespace(ctx, f.tenantID, "group", &models.NamespaceConfig{
+		Relations: map[string]*models.UsersetRewrite{"member": nil},
+	})
+	require.NoError(t, err)
+
+	inherited := func(relation string) *models.UsersetRewrite {
+		return &models.UsersetRewrite{TupleToUserset: &models.TupleToUserset{Tupleset: "parent", ComputedUserset: relation}}
+	}
+	for _, namespace := range []string{"folder", "document"} {
+		_, _, err := f.service.PutNamespace(ctx, f.tenantID, namespace, &models.NamespaceConfig{
+			Relations: map[string]*models.UsersetRewrite{
+				"parent": nil,
+				"banned": nil,
+				"owner":  nil,
+				"editor": {Union: []*models.UsersetRewrite{
+					{This: true},
+					{ComputedUserset: "owner"},
+					inherited("editor"),
+				}},
+				"viewer": {Exclusion: &models.UsersetExclusion{
+					Base: &models.UsersetRewrite{Union: []*models.UsersetRewrite{
+						{This: true},
+						{ComputedUserset: "editor"},
+						inherited("viewer"),
+					}},
+					Subtract: &models.UsersetRewrite{ComputedUserset: "banned"},
+				}},
+			},
+		})
+		require.NoError(t, err)
+	}
+	return f
+}
+
+func (f *relationFixture) write(t *testing.T, keys ...string) string {
+	req := &WriteRequest{}
+	for _, key := range keys {
+		req.Writes = append(req.Writes, tupleKey(key))
+	}
+	resp, err := f.service.WriteTuples(context.Background(), f.tenantID, req)
+	require.NoError(t, err)
+	return resp.ConsistencyToken
+}
+
+func (f *relationFixture) check(t *testing.T, object, relation, subject, token string) bool {
+	resp, err := f.service.Check(context.Background(), f.tenantID, &CheckRequest{
+		Object: object, Relation: relation, Subject: subject, ConsistencyToken: token,
+	})
+	require.NoError(t, err)
+	return resp.Allowed
+}
+
+// tupleKey parses "object#relation@subject"
+func tupleKey(s string) TupleKey {
+	object, rest, _ := strings.Cut(s, "#")
+	relation, subject, _ := strings.Cut(rest, "@")
+	return TupleKey{Object: object, Relation: relation, Subject: subject}
+}
+
+func TestService_Check_Rewrites(t *testing.T) {
+	f := newRelationFixture(t, nil)
+	f.write(t,
+		"group:eng#member@user:alice",
+		"folder:42#editor@group:eng#member",
+		"folder:42#owner@user:carol",
+		"document:7#parent@folder:42",
+		"document:7#viewer@user:dave",
+		"document:7#banned@user:erin",
+		"folder:42#viewer@user:erin",
+	)
+
+	tests := []struct {
+		object, relation, subject string
+		want                      bool
+	}{
+		{"folder:42", "editor", "user:alice", true},  // through the group userset
+		{"document:7", "editor", "user:alice", true}, // inherited from the parent folder
+		{"document:7", "viewer", "user:alice", true}, // editors are viewers
+		{"document:7", "editor", "user:carol", true}, // owners of the parent are editors
+		{"document:7", "viewer", "user:dave", true},  // direct tuple
+		{"document:7", "editor", "user:dave", false}, // viewers are not editors
+		{"folder:42", "viewer", "user:dave", false},  // relations do not flow to parents
+		{"folder:42", "viewer", "user:erin", true},   // direct tuple
+		{"document:7", "viewer", "user:erin", false}, // banned on the document
+		{"document:7", "viewer", "group:eng#member", true},
+		{"group:eng", "member", "user:bob", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, f.check(t, tt.object, tt.relation, tt.subject, ""),
+			"%s#%s@%s", tt.object, tt.relation, tt.subject)
+	}
+}
+
+func TestService_Check_NestedGroupCycle(t *testing.T) {
+	f := newRelationFixture(t, nil)
+	f.write(t,
+		"group:a#member@group:b#member",
+		"group:b#member@group:a#member",
+		"group:b#member@user:alice",
+	)
+
+	assert.True(t, f.check(t, "group:a", "member", "user:alice", ""))
+	assert.False(t, f.check(t, "group:a", "member", "user:bob", ""))
+}
+
+func TestService_Check_ConsistencyToken(t *testing.T) {
+	f := newRelationFixture(t, cache.NewMemoryCache())
+	f.write(t, "document:7#owner@user:alice")
+
+	// Warm the cache with a negative result
+	assert.False(t, f.check(t, "document:7", "viewer", "user:bob", ""))
+
+	token := f.write(t, "document:7#viewer@user:bob")
+
+	// A write makes the cached result unreachable, with or without a token
+	assert.True(t, f.check(t, "document:7", "viewer", "user:bob", ""))
+	assert.True(t, f.check(t, "document:7", "viewer", "user:bob", token))
+
+	_, err := f.service.Check(context.Background(), uuid.New(), &CheckRequest{
+		Object: "document:7", Relation: "viewer", Subject: "user:bob", ConsistencyToken: token,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "consistency token")
+}
+
+func TestService_Check_RevokeSkipsCache(t *testing.T) {
+	f := newRelationFixture(t, cache.NewMemoryCache())
+	f.write(t, "document:7#viewer@user:bob")
+
+	// Warm the cache with a positive result
+	assert.True(t, f.check(t, "document:7", "viewer", "user:bob", ""))
+
+	_, err := f.service.WriteTuples(context.Background(), f.tenantID, &WriteRequest{
+		Deletes: []TupleKey{tupleKey("document:7#viewer@user:bob")},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, f.check(t, "document:7", "viewer", "user:bob", ""))
+}
+
+func TestService_WriteTuples_Validation(t *testing.T) {
+	f := newRelationFixture(t, nil)
+	ctx := context.Background()
+
+	tests := map[string]string{
+		"unknown namespace":      "drive:1#owner@user:alice",
+		"undefined relation":     "document:7#commenter@user:alice",
+		"undefined userset":      "document:7#viewer@group:eng#admin",
+		"malformed object":       "document#viewer@user:alice",
+		"malformed subject":      "document:7#viewer@alice",
+		"invalid namespace name": "Document:7#viewer@user:alice",
+	}
+	for name, key := range tests {
+		_, err := f.service.WriteTuples(ctx, f.tenantID, &WriteRequest{Writes: []TupleKey{tupleKey(key)}})
+		assert.Error(t, err, name)
+	}
+
+	// A purely computed relation cannot be written
+	_, _, err := f.service.PutNamespace(ctx, f.tenantID, "report", &models.NamespaceConfig{
+		Relations: map[string]*models.UsersetRewrite{
+			"owner":  nil,
+			"reader": {ComputedUserset: "owner"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = f.service.WriteTuples(ctx, f.tenantID, &WriteRequest{Writes: []TupleKey{tupleKey("report:1#reader@user:alice")}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be written")
+}
+
+func TestService_PutNamespace_InvalidConfig(t *testing.T) {
+	f := newRelationFixture(t, nil)
+	ctx := context.Background()
+
+	configs := []*models.NamespaceConfig{
+		{},
+		{Relations: map[string]*models.UsersetRewrite{"viewer": {ComputedUserset: "editor"}}},
+		{Relations: map[string]*models.UsersetRewrite{"viewer": {This: true, ComputedUserset: "viewer"}}},
+		{Relations: map[string]*models.UsersetRewrite{"viewer": {Union: []*models.UsersetRewrite{}}}},
+		{Relations: map[string]*models.UsersetRewrite{"viewer": {TupleToUserset: &models.TupleToUserset{Tupleset: "parent", ComputedUserset: "viewer"}}}},
+	}
+	for i, config := range configs {
+		_, _, err := f.service.PutNamespace(ctx, f.tenantID, "drive", config)
+		assert.Error(t, err, "config %d", i)
+	}
+}
+
+func TestService_ListObjects(t *testing.T) {
+	f := newRelationFixture(t, nil)
+	f.write(t,
+		"group:eng#member@user:alice",
+		"folder:42#editor@group:eng#member",
+		"document:1#parent@folder:42",
+		"document:2#parent@folder:42",
+		"document:2#banned@user:alice",
+		"document:3#viewer@user:alice",
+		"document:4#viewer@user:bob",
+	)
+
+	resp, err := f.service.ListObjects(context.Background(), f.tenantID, &ListObjectsRequest{
+		Namespace: "document", Relation: "viewer", Subject: "user:alice",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"document:1", "document:3"}, resp.Objects)
+	assert.False(t, resp.Truncated)
+
+	resp, err = f.service.ListObjects(context.Background(), f.tenantID, &ListObjectsRequest{
+		Namespace: "document", Relation: "viewer", Subject: "user:alice", Limit: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"document:1"}, resp.Objects)
+	assert.True(t, resp.Truncated)
+}
+
+func TestService_Expand(t *testing.T) {
+	f := newRelationFixture(t, nil)
+	f.write(t,
+		"folder:42#editor@group:eng#member",
+		"document:7#parent@folder:42",
+		"document:7#editor@user:dave",
+	)
+
+	resp, err := f.service.Expand(context.Background(), f.tenantID, &ExpandRequest{Object: "document:7", Relation: "editor"})
+	require.NoError(t, err)
+
+	tree := resp.Tree
+	assert.Equal(t, OperationUnion, tree.Operation)
+	assert.Equal(t, "document:7#editor", tree.Userset)
+	require.Len(t, tree.Children, 3)
+	assert.Equal(t, []string{"user:dave"}, tree.Children[0].Subjects)
+	assert.Equal(t, "document:7#owner", tree.Children[1].Userset)
+
+	inherited := tree.Children[2]
+	require.Len(t, inherited.Children, 1)
+	assert.Equal(t, "folder:42#editor", inherited.Children[0].Userset)
+	assert.Equal(t, []string{"group:eng#member"}, inherited.Children[0].Children[0].Subjects)
+}
+
+func TestService_TenantIsolation(t *testing.T) {
+	f := newRelationFixture(t, nil)
+	f.write(t, "document:7#viewer@user:alice")
+
+	_, err := f.service.Check(context.Background(), uuid.New(), &CheckRequest{
+		Object: "document:7", Relation: "viewer", Subject: "user:alice",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "namespace document not found")
+}
@@ -0,0 +1,115 @@
+package relation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+var (
+	namePattern     = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	objectIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-@|=+/]{1,255}$`)
+)
+
+// Object identifies an object as namespace:id
+type Object struct {
+	Namespace string
+	ID        string
+}
+
+func (o Object) String() string {
+	return o.Namespace + ":" + o.ID
+}
+
+// Subject identifies a direct subject (namespace:id) or a userset (namespace:id#relation)
+type Subject struct {
+	Namespace string
+	ID        string
+	Relation  string // Empty for a direct subject
+}
+
+func (s Subject) String() string {
+	if s.Relation == "" {
+		return s.Namespace + ":" + s.ID
+	}
+	return s.Namespace + ":" + s.ID + "#" + s.Relation
+}
+
+// TupleKey is the API form of a relationship tuple
+type TupleKey struct {
+	Object   string `json:"object" binding:"required"`   // namespace:id
+	Relation string `json:"relation" binding:"required"` // Relation of the object
+	Subject  string `json:"subject" binding:"required"`  // namespace:id or namespace:id#relation
+}
+
+// ParseObject parses namespace:id
+func ParseObject(s string) (Object, error) {
+	namespace, id, ok := strings.Cut(s, ":")
+	if !ok {
+		return Object{}, fmt.Errorf("invalid object %q: expected namespace:id", s)
+	}
+	if err := validateName("namespace", namespace); err != nil {
+		return Object{}, err
+	}
+	if !objectIDPattern.MatchString(id) {
+		return Object{}, fmt.Errorf("invalid object id %q", id)
+	}
+	return Object{Namespace: namespace, ID: id}, nil
+}
+
+// ParseSubject parses namespace:id or namespace:id#relation
+func ParseSubject(s string) (Subject, error) {
+	objectPart, relation, hasRelation := strings.Cut(s, "#")
+	object, err := ParseObject(objectPart)
+	if err != nil {
+		return Subject{}, fmt.Errorf("invalid subject %q: %w", s, err)
+	}
+	if hasRelation {
+		if err := validateName("relation", relation); err != nil {
+			return Subject{}, fmt.Errorf("invalid subject %q: %w", s, err)
+		}
+	}
+	return Subject{Namespace: object.Namespace, ID: object.ID, Relation: relation}, nil
+}
+
+// ParseTupleKey parses the object, relation and subject of a tuple
+func ParseTupleKey(key TupleKey) (*models.RelationTuple, error) {
+	object, err := ParseObject(key.Object)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateName("relation", key.Relation); err != nil {
+		return nil, err
+	}
+	subject, err := ParseSubject(key.Subject)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RelationTuple{
+		Namespace:        object.Namespace,
+		ObjectID:         object.ID,
+		Relation:         key.Relation,
+		SubjectNamespace: subject.Namespace,
+		SubjectID:        subject.ID,
+		SubjectRelation:  subject.Relation,
+	}, nil
+}
+
+// FormatTuple renders a tuple as namespace:id#relation@subject
+func FormatTuple(t *models.RelationTuple) string {
+	return Object{Namespace: t.Namespace, ID: t.ObjectID}.String() + "#" + t.Relation + "@" + tupleSubject(t).String()
+}
+
+// tupleSubject returns the subject of a tuple
+func tupleSubject(t *models.RelationTuple) Subject {
+	return Subject{Namespace: t.SubjectNamespace, ID: t.SubjectID, Relation: t.SubjectRelation}
+}
+
+func validateName(kind, name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid %s %q: must be lowercase letters, digits or underscores", kind, name)
+	}
+	return nil
+}
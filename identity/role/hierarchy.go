@@ -0,0 +1,127 @@
+package role
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// MaxHierarchyDepth bounds how many levels of parent roles are followed.
+// It keeps expansion cheap and turns an accidental cycle in stored data into
+// a bounded walk instead of an infinite one.
+const MaxHierarchyDepth = 10
+
+// EffectivePermission is a permission a role holds, either directly or through a parent role
+type EffectivePermission struct {
+	Permission *models.Permission `json:"permission"`
+	Inherited  bool               `json:"inherited"`
+	SourceRole RoleRef            `json:"source_role"` // Role the permission is attached to
+}
+
+// RoleRef identifies a role in hierarchy responses
+type RoleRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// ExpandRoles returns the given roles followed by every role they inherit from,
+// without duplicates. Inherited roles from another tenant or deleted roles are skipped.
+func ExpandRoles(ctx context.Context, roleRepo interfaces.RoleRepository, roles []*models.Role) ([]*models.Role, error) {
+	expanded := make([]*models.Role, 0, len(roles))
+	seen := make(map[uuid.UUID]bool)
+
+	frontier := make([]*models.Role, 0, len(roles))
+	for _, r := range roles {
+		if r == nil || seen[r.ID] {
+			continue
+		}
+		seen[r.ID] = true
+		expanded = append(expanded, r)
+		frontier = append(frontier, r)
+	}
+
+	for depth := 0; len(frontier) > 0 && depth < MaxHierarchyDepth; depth++ {
+		var next []*models.Role
+		for _, child := range frontier {
+			parents, err := roleRepo.GetParentRoles(ctx, child.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parent roles of %s: %w", child.Name, err)
+			}
+			for _, parent := range parents {
+				if seen[parent.ID] || parent.TenantID != child.TenantID || !parent.IsActive() {
+					continue
+				}
+				seen[parent.ID] = true
+				expanded = append(expanded, parent)
+				next = append(next, parent)
+			}
+		}
+		frontier = next
+	}
+
+	return expanded, nil
+}
+
+// ancestorIDs returns the IDs of every role the given role inherits from, and
+// the depth of the deepest chain above it
+func ancestorIDs(ctx context.Context, roleRepo interfaces.RoleRepository, roleID uuid.UUID) (map[uuid.UUID]bool, int, error) {
+	ancestors := make(map[uuid.UUID]bool)
+	frontier := []uuid.UUID{roleID}
+	depth := 0
+
+	for len(frontier) > 0 && depth <= MaxHierarchyDepth {
+		var next []uuid.UUID
+		for _, id := range frontier {
+			parents, err := roleRepo.GetParentRoles(ctx, id)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to get parent roles: %w", err)
+			}
+			for _, parent := range parents {
+				if ancestors[parent.ID] {
+					continue
+				}
+				ancestors[parent.ID] = true
+				next = append(next, parent.ID)
+			}
+		}
+		if len(next) > 0 {
+			depth++
+		}
+		frontier = next
+	}
+
+	return ancestors, depth, nil
+}
+
+// descendantDepth returns the depth of the deepest chain of roles inheriting from the given role
+func descendantDepth(ctx context.Context, roleRepo interfaces.RoleRepository, roleID uuid.UUID) (int, error) {
+	seen := map[uuid.UUID]bool{roleID: true}
+	frontier := []uuid.UUID{roleID}
+	depth := 0
+
+	for len(frontier) > 0 && depth <= MaxHierarchyDepth {
+		var next []uuid.UUID
+		for _, id := range frontier {
+			children, err := roleRepo.GetChildRoles(ctx, id)
+			if err != nil {
+				return 0, fmt.Errorf("failed to get child roles: %w", err)
+			}
+			for _, child := range children {
+				if seen[child.ID] {
+					continue
+				}
+				seen[child.ID] = true
+				next = append(next, child.ID)
+			}
+		}
+		if len(next) > 0 {
+			depth++
+		}
+		frontier = next
+	}
+
+	return depth, nil
+}
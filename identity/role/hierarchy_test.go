@@ -0,0 +1,187 @@
+package role
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingInvalidator records the tenants whose caches were invalidated
+type recordingInvalidator struct {
+	tenants []uuid.UUID
+}
+
+func (r *recordingInvalidator) InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	r.tenants = append(r.tenants, tenantID)
+	return nil
+}
+
+func TestService_AddParentRole(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+	invalidator := &recordingInvalidator{}
+	service := NewService(mockRepo, new(MockPermissionRepository), invalidator, nil)
+
+	tenantID := uuid.New()
+	editor := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "editor"}
+	viewer := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "viewer"}
+
+	mockRepo.On("GetByID", mock.Anything, editor.ID).Return(editor, nil)
+	mockRepo.On("GetByID", mock.Anything, viewer.ID).Return(viewer, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, viewer.ID).Return([]*models.Role{}, nil)
+	mockRepo.On("GetChildRoles", mock.Anything, editor.ID).Return([]*models.Role{}, nil)
+	mockRepo.On("AddParentRole", mock.Anything, editor.ID, viewer.ID).Return(nil)
+
+	err := service.AddParentRole(context.Background(), editor.ID, viewer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tenantID}, invalidator.tenants)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// inheritanceChecker rejects making any role inherit from a denied parent
+type inheritanceChecker struct {
+	denied uuid.UUID
+}
+
+func (c *inheritanceChecker) LockAssignments(ctx context.Context, tenantID uuid.UUID) error {
+	return nil
+}
+
+func (c *inheritanceChecker) CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	return nil
+}
+
+func (c *inheritanceChecker) CheckInheritance(ctx context.Context, tenantID, roleID, parentRoleID uuid.UUID) error {
+	if parentRoleID == c.denied {
+		return fmt.Errorf("separation of duties violation: rule %q allows at most 1 of its roles", "payments")
+	}
+	return nil
+}
+
+func TestService_AddParentRole_ChecksHolders(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+	tenantID := uuid.New()
+	lead := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "finance_lead"}
+	approver := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "payments_approver"}
+	service := NewService(mockRepo, new(MockPermissionRepository), nil, &inheritanceChecker{denied: approver.ID})
+
+	mockRepo.On("GetByID", mock.Anything, lead.ID).Return(lead, nil)
+	mockRepo.On("GetByID", mock.Anything, approver.ID).Return(approver, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, approver.ID).Return([]*models.Role{}, nil)
+	mockRepo.On("GetChildRoles", mock.Anything, lead.ID).Return([]*models.Role{}, nil)
+
+	err := service.AddParentRole(context.Background(), lead.ID, approver.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+
+	mockRepo.AssertNotCalled(t, "AddParentRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_AddParentRole_RejectsCycle(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+	service := NewService(mockRepo, new(MockPermissionRepository), nil, nil)
+
+	tenantID := uuid.New()
+	viewer := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "viewer"}
+	editor := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "editor"}
+	admin := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "admin"}
+
+	// admin -> editor -> viewer already; viewer -> admin would close the loop
+	mockRepo.On("GetByID", mock.Anything, viewer.ID).Return(viewer, nil)
+	mockRepo.On("GetByID", mock.Anything, admin.ID).Return(admin, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, admin.ID).Return([]*models.Role{editor}, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, editor.ID).Return([]*models.Role{viewer}, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, viewer.ID).Return([]*models.Role{}, nil)
+
+	err := service.AddParentRole(context.Background(), viewer.ID, admin.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle")
+
+	mockRepo.AssertNotCalled(t, "AddParentRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_AddParentRole_Validation(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+	service := NewService(mockRepo, new(MockPermissionRepository), nil, nil)
+
+	role := &models.Role{ID: uuid.New(), TenantID: uuid.New(), Name: "editor"}
+	foreign := &models.Role{ID: uuid.New(), TenantID: uuid.New(), Name: "viewer"}
+	system := &models.Role{ID: uuid.New(), TenantID: role.TenantID, Name: "tenant_owner", IsSystem: true}
+
+	mockRepo.On("GetByID", mock.Anything, role.ID).Return(role, nil)
+	mockRepo.On("GetByID", mock.Anything, foreign.ID).Return(foreign, nil)
+	mockRepo.On("GetByID", mock.Anything, system.ID).Return(system, nil)
+
+	err := service.AddParentRole(context.Background(), role.ID, role.ID)
+	assert.EqualError(t, err, "a role cannot inherit from itself")
+
+	err = service.AddParentRole(context.Background(), role.ID, foreign.ID)
+	assert.EqualError(t, err, "parent role must belong to the same tenant")
+
+	err = service.AddParentRole(context.Background(), system.ID, role.ID)
+	assert.EqualError(t, err, "cannot modify system role: system roles are immutable")
+
+	mockRepo.AssertNotCalled(t, "AddParentRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_GetEffectivePermissions(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+	mockPermRepo := new(MockPermissionRepository)
+	service := NewService(mockRepo, mockPermRepo, nil, nil)
+
+	tenantID := uuid.New()
+	editor := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "editor"}
+	viewer := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "viewer"}
+	read := &models.Permission{ID: uuid.New(), Resource: "documents", Action: "read"}
+	update := &models.Permission{ID: uuid.New(), Resource: "documents", Action: "update"}
+	export := &models.Permission{ID: uuid.New(), Resource: "documents", Action: "export"}
+
+	mockRepo.On("GetByID", mock.Anything, editor.ID).Return(editor, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, editor.ID).Return([]*models.Role{viewer}, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, viewer.ID).Return([]*models.Role{}, nil)
+	mockPermRepo.On("GetRolePermissions", mock.Anything, editor.ID).Return([]*models.Permission{update, read}, nil)
+	mockPermRepo.On("GetRolePermissions", mock.Anything, viewer.ID).Return([]*models.Permission{read, export}, nil)
+
+	effective, err := service.GetEffectivePermissions(context.Background(), editor.ID)
+	require.NoError(t, err)
+	require.Len(t, effective, 3)
+
+	byAction := make(map[string]*EffectivePermission)
+	for _, ep := range effective {
+		byAction[ep.Permission.Action] = ep
+	}
+	assert.False(t, byAction["update"].Inherited)
+	assert.False(t, byAction["read"].Inherited, "a permission held directly is reported as direct")
+	assert.True(t, byAction["export"].Inherited)
+	assert.Equal(t, "viewer", byAction["export"].SourceRole.Name)
+}
+
+func TestExpandRoles_SkipsForeignAndRepeatedParents(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+
+	tenantID := uuid.New()
+	admin := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "admin"}
+	editor := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "editor"}
+	viewer := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "viewer"}
+	foreign := &models.Role{ID: uuid.New(), TenantID: uuid.New(), Name: "foreign"}
+
+	// Diamond: admin inherits editor and viewer, editor inherits viewer
+	mockRepo.On("GetParentRoles", mock.Anything, admin.ID).Return([]*models.Role{editor, viewer, foreign}, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, editor.ID).Return([]*models.Role{viewer}, nil)
+	mockRepo.On("GetParentRoles", mock.Anything, viewer.ID).Return([]*models.Role{}, nil)
+
+	expanded, err := ExpandRoles(context.Background(), mockRepo, []*models.Role{admin})
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(expanded))
+	for _, r := range expanded {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{"admin", "editor", "viewer"}, names)
+}
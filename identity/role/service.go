@@ -11,32 +11,70 @@ import (
 	"github.com/arauth-identity/iam/storage/interfaces"
 )
 
+// CacheInvalidator drops cached authorization data derived from a tenant's roles
+type CacheInvalidator interface {
+	InvalidateTenant(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// AssignmentChecker vetoes role assignments, e.g. ones that would break
+// separation-of-duties rules. Checks must run in the transaction that makes
+// the assignment, so that concurrent assignments are checked one at a time.
+type AssignmentChecker interface {
+	// LockAssignments makes other checks in the tenant wait until the
+	// context's transaction ends. Callers that read what an assignment
+	// grants before checking it take the lock first.
+	LockAssignments(ctx context.Context, tenantID uuid.UUID) error
+
+	// CheckAssignment returns an error if the user may not hold roleIDs on top of
+	// the roles they already hold. userID may be uuid.Nil for a user who does not exist yet.
+	CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error
+
+	// CheckInheritance returns an error if the holders of roleID may not also
+	// hold parentRoleID and the roles it inherits from
+	CheckInheritance(ctx context.Context, tenantID, roleID, parentRoleID uuid.UUID) error
+}
+
 // Service provides role management business logic
 type Service struct {
 	roleRepo       interfaces.RoleRepository
 	permissionRepo interfaces.PermissionRepository
+	invalidator    CacheInvalidator
+	checker        AssignmentChecker
+	txRunner       interfaces.TxRunner
 }
 
-// NewService creates a new role service
-func NewService(roleRepo interfaces.RoleRepository, permissionRepo interfaces.PermissionRepository) *Service {
+// NewService creates a new role service.
+// invalidator may be nil when no authorization cache is in use; checker may be
+// nil when assignments are not restricted.
+func NewService(roleRepo interfaces.RoleRepository, permissionRepo interfaces.PermissionRepository, invalidator CacheInvalidator, checker AssignmentChecker) *Service {
 	return &Service{
 		roleRepo:       roleRepo,
 		permissionRepo: permissionRepo,
+		invalidator:    invalidator,
+		checker:        checker,
 	}
 }
 
+// SetTxRunner sets the transaction runner that assignments are checked and
+// made in. Without one they join the context's transaction, if any.
+func (s *Service) SetTxRunner(txRunner interfaces.TxRunner) {
+	s.txRunner = txRunner
+}
+
 // CreateRoleRequest represents a request to create a role
 type CreateRoleRequest struct {
 	TenantID    uuid.UUID `json:"tenant_id" binding:"required"`
 	Name        string    `json:"name" binding:"required,min=3,max=255"`
-	Description *string   `json:"description,omitempty"`
-	IsSystem    bool      `json:"is_system,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	IsSystem    bool       `json:"is_system,omitempty"`
+	OwnerID     *uuid.UUID `json:"owner_id,omitempty"`
 }
 
 // UpdateRoleRequest represents a request to update a role
 type UpdateRoleRequest struct {
-	Name        *string `json:"name,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	OwnerID     *uuid.UUID `json:"owner_id,omitempty"`
 }
 
 // Create creates a new role
@@ -74,6 +112,7 @@ func (s *Service) Create(ctx context.Context, req *CreateRoleRequest) (*models.R
 		Name:        name,
 		Description: req.Description,
 		IsSystem:    false, // Tenant-created roles are never system roles
+		OwnerID:     req.OwnerID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -128,12 +167,18 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, req *UpdateRoleReque
 		role.Description = req.Description
 	}
 
+	if req.OwnerID != nil {
+		role.OwnerID = req.OwnerID
+	}
+
 	role.UpdatedAt = time.Now()
 
 	if err := s.roleRepo.Update(ctx, role); err != nil {
 		return nil, fmt.Errorf("failed to update role: %w", err)
 	}
 
+	s.invalidateTenant(ctx, role.TenantID)
+
 	return role, nil
 }
 
@@ -150,7 +195,14 @@ func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("cannot delete system role: system roles are protected and cannot be deleted")
 	}
 
-	return s.roleRepo.Delete(ctx, id)
+	if err := s.roleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Roles inheriting from this one lose its permissions
+	s.invalidateTenant(ctx, role.TenantID)
+
+	return nil
 }
 
 // List retrieves a list of roles
@@ -185,20 +237,82 @@ func (s *Service) AssignRoleToUser(ctx context.Context, userID, roleID uuid.UUID
 		return fmt.Errorf("role not found: %w", err)
 	}
 
-	// Assign role
-	if err := s.roleRepo.AssignRoleToUser(ctx, userID, roleID); err != nil {
-		return fmt.Errorf("failed to assign role: %w", err)
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		if err := s.CheckAssignment(ctx, role.TenantID, userID, []uuid.UUID{roleID}); err != nil {
+			return err
+		}
+
+		// Assign role
+		if err := s.roleRepo.AssignRoleToUser(ctx, userID, roleID); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateTenant(ctx, role.TenantID)
+
+	return nil
+}
+
+// AssignRoleToUserUntil assigns a role to a user that lapses at expiresAt.
+// If the user already holds the role permanently, the assignment stays permanent.
+func (s *Service) AssignRoleToUserUntil(ctx context.Context, userID, roleID uuid.UUID, expiresAt time.Time, assignedBy *uuid.UUID) error {
+	if !expiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+
+	role, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return fmt.Errorf("role not found: %w", err)
+	}
+
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		if err := s.CheckAssignment(ctx, role.TenantID, userID, []uuid.UUID{roleID}); err != nil {
+			return err
+		}
+
+		assignment := &models.UserRoleAssignment{
+			UserID:     userID,
+			Role:       role,
+			AssignedBy: assignedBy,
+			ExpiresAt:  &expiresAt,
+		}
+		if err := s.roleRepo.AssignRoleToUserWithExpiry(ctx, assignment); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Note: In production, you might want to verify user belongs to same tenant as role
-	_ = role // Use role for tenant validation if needed
+	s.invalidateTenant(ctx, role.TenantID)
 
 	return nil
 }
 
+// CheckAssignment returns an error if the user may not be given the roles.
+// Every assignment made through this service is checked; callers that assign
+// several roles at once can check the whole set up front.
+func (s *Service) CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	if s.checker == nil {
+		return nil
+	}
+	return s.checker.CheckAssignment(ctx, tenantID, userID, roleIDs)
+}
+
 // RemoveRoleFromUser removes a role from a user
 func (s *Service) RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error {
-	return s.roleRepo.RemoveRoleFromUser(ctx, userID, roleID)
+	if err := s.roleRepo.RemoveRoleFromUser(ctx, userID, roleID); err != nil {
+		return err
+	}
+
+	s.invalidateRole(ctx, roleID)
+
+	return nil
 }
 
 // GetRolePermissions retrieves all permissions for a role
@@ -214,7 +328,7 @@ func (s *Service) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]*
 // AssignPermissionToRole assigns a permission to a role
 func (s *Service) AssignPermissionToRole(ctx context.Context, roleID, permissionID uuid.UUID) error {
 	// Verify role exists
-	_, err := s.roleRepo.GetByID(ctx, roleID)
+	role, err := s.roleRepo.GetByID(ctx, roleID)
 	if err != nil {
 		return fmt.Errorf("role not found: %w", err)
 	}
@@ -230,11 +344,177 @@ func (s *Service) AssignPermissionToRole(ctx context.Context, roleID, permission
 		return fmt.Errorf("failed to assign permission: %w", err)
 	}
 
+	s.invalidateTenant(ctx, role.TenantID)
+
 	return nil
 }
 
 // RemovePermissionFromRole removes a permission from a role
 func (s *Service) RemovePermissionFromRole(ctx context.Context, roleID, permissionID uuid.UUID) error {
-	return s.permissionRepo.RemovePermissionFromRole(ctx, roleID, permissionID)
+	if err := s.permissionRepo.RemovePermissionFromRole(ctx, roleID, permissionID); err != nil {
+		return err
+	}
+
+	s.invalidateRole(ctx, roleID)
+
+	return nil
+}
+
+// GetParentRoles retrieves the roles a role directly inherits from
+func (s *Service) GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	parents, err := s.roleRepo.GetParentRoles(ctx, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent roles: %w", err)
+	}
+
+	return parents, nil
+}
+
+// AddParentRole makes a role inherit every permission of a parent role.
+// The hierarchy must stay acyclic and within MaxHierarchyDepth levels.
+func (s *Service) AddParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	if roleID == parentRoleID {
+		return fmt.Errorf("a role cannot inherit from itself")
+	}
+
+	role, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return fmt.Errorf("role not found: %w", err)
+	}
+	if role.IsSystem {
+		return fmt.Errorf("cannot modify system role: system roles are immutable")
+	}
+
+	parent, err := s.roleRepo.GetByID(ctx, parentRoleID)
+	if err != nil {
+		return fmt.Errorf("parent role not found: %w", err)
+	}
+	if parent.TenantID != role.TenantID {
+		return fmt.Errorf("parent role must belong to the same tenant")
+	}
+
+	// Adding role -> parent closes a cycle if role is already an ancestor of parent
+	ancestors, parentDepth, err := ancestorIDs(ctx, s.roleRepo, parentRoleID)
+	if err != nil {
+		return err
+	}
+	if ancestors[roleID] {
+		return fmt.Errorf("role hierarchy cycle: %s already inherits from %s", parent.Name, role.Name)
+	}
+
+	childDepth, err := descendantDepth(ctx, s.roleRepo, roleID)
+	if err != nil {
+		return err
+	}
+	if parentDepth+childDepth+1 > MaxHierarchyDepth {
+		return fmt.Errorf("role hierarchy may be at most %d levels deep", MaxHierarchyDepth)
+	}
+
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		// Everyone holding the role gains the parent's roles
+		if s.checker != nil {
+			if err := s.checker.CheckInheritance(ctx, role.TenantID, roleID, parentRoleID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.roleRepo.AddParentRole(ctx, roleID, parentRoleID); err != nil {
+			return fmt.Errorf("failed to add parent role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateTenant(ctx, role.TenantID)
+
+	return nil
+}
+
+// RemoveParentRole stops a role inheriting from a parent role
+func (s *Service) RemoveParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	role, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return fmt.Errorf("role not found: %w", err)
+	}
+	if role.IsSystem {
+		return fmt.Errorf("cannot modify system role: system roles are immutable")
+	}
+
+	if err := s.roleRepo.RemoveParentRole(ctx, roleID, parentRoleID); err != nil {
+		return fmt.Errorf("failed to remove parent role: %w", err)
+	}
+
+	s.invalidateTenant(ctx, role.TenantID)
+
+	return nil
+}
+
+// GetEffectivePermissions retrieves every permission a role holds, marking those
+// that come from a parent role. A permission held both directly and through a
+// parent is reported once, as direct.
+func (s *Service) GetEffectivePermissions(ctx context.Context, roleID uuid.UUID) ([]*EffectivePermission, error) {
+	role, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
+	}
+
+	roles, err := ExpandRoles(ctx, s.roleRepo, []*models.Role{role})
+	if err != nil {
+		return nil, err
+	}
+
+	effective := make([]*EffectivePermission, 0)
+	seen := make(map[uuid.UUID]bool)
+	// ExpandRoles lists the role itself first, so direct permissions win
+	for _, r := range roles {
+		permissions, err := s.permissionRepo.GetRolePermissions(ctx, r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get role permissions: %w", err)
+		}
+		for _, perm := range permissions {
+			if seen[perm.ID] {
+				continue
+			}
+			seen[perm.ID] = true
+			effective = append(effective, &EffectivePermission{
+				Permission: perm,
+				Inherited:  r.ID != role.ID,
+				SourceRole: RoleRef{ID: r.ID, Name: r.Name},
+			})
+		}
+	}
+
+	return effective, nil
+}
+
+// withinTx runs fn in a transaction, or directly without a transaction runner
+func (s *Service) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.txRunner == nil {
+		return fn(ctx)
+	}
+	return s.txRunner.WithinTx(ctx, fn)
+}
+
+// invalidateTenant drops cached authorization data for a tenant. Failures are
+// ignored: the role change has already been stored and cached grants expire on their own.
+func (s *Service) invalidateTenant(ctx context.Context, tenantID uuid.UUID) {
+	if s.invalidator == nil {
+		return
+	}
+	_ = s.invalidator.InvalidateTenant(ctx, tenantID)
+}
+
+// invalidateRole drops cached authorization data for the tenant a role belongs to
+func (s *Service) invalidateRole(ctx context.Context, roleID uuid.UUID) {
+	if s.invalidator == nil {
+		return
+	}
+	role, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return
+	}
+	s.invalidateTenant(ctx, role.TenantID)
 }
 
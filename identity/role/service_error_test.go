@@ -13,7 +13,7 @@ import (
 func TestService_Create_EmptyName(t *testing.T) {
 	mockRoleRepo := new(MockRoleRepository)
 	mockPermRepo := new(MockPermissionRepository)
-	service := NewService(mockRoleRepo, mockPermRepo)
+	service := NewService(mockRoleRepo, mockPermRepo, nil, nil)
 
 	req := &CreateRoleRequest{
 		TenantID: uuid.New(),
@@ -27,7 +27,7 @@ func TestService_Create_EmptyName(t *testing.T) {
 func TestService_Create_DuplicateName(t *testing.T) {
 	mockRoleRepo := new(MockRoleRepository)
 	mockPermRepo := new(MockPermissionRepository)
-	service := NewService(mockRoleRepo, mockPermRepo)
+	service := NewService(mockRoleRepo, mockPermRepo, nil, nil)
 
 	tenantID := uuid.New()
 	roleName := "Admin"
@@ -54,7 +54,7 @@ func TestService_Create_DuplicateName(t *testing.T) {
 func TestService_GetByID_NotFound(t *testing.T) {
 	mockRoleRepo := new(MockRoleRepository)
 	mockPermRepo := new(MockPermissionRepository)
-	service := NewService(mockRoleRepo, mockPermRepo)
+	service := NewService(mockRoleRepo, mockPermRepo, nil, nil)
 
 	nonExistentID := uuid.New()
 	mockRoleRepo.On("GetByID", mock.Anything, nonExistentID).Return(nil, assert.AnError)
@@ -67,7 +67,7 @@ func TestService_GetByID_NotFound(t *testing.T) {
 func TestService_AssignRoleToUser_RoleNotFound(t *testing.T) {
 	mockRoleRepo := new(MockRoleRepository)
 	mockPermRepo := new(MockPermissionRepository)
-	service := NewService(mockRoleRepo, mockPermRepo)
+	service := NewService(mockRoleRepo, mockPermRepo, nil, nil)
 
 	userID := uuid.New()
 	roleID := uuid.New()
@@ -82,7 +82,7 @@ func TestService_AssignRoleToUser_RoleNotFound(t *testing.T) {
 func TestService_AssignPermissionToRole_PermissionNotFound(t *testing.T) {
 	mockRoleRepo := new(MockRoleRepository)
 	mockPermRepo := new(MockPermissionRepository)
-	service := NewService(mockRoleRepo, mockPermRepo)
+	service := NewService(mockRoleRepo, mockPermRepo, nil, nil)
 
 	roleID := uuid.New()
 	permissionID := uuid.New()
@@ -38,7 +38,7 @@ func TestService_Create_Integration(t *testing.T) {
 	err := tenantRepo.Create(context.Background(), tenant)
 	require.NoError(t, err)
 
-	service := NewService(roleRepo, permissionRepo)
+	service := NewService(roleRepo, permissionRepo, nil, nil)
 
 	req := &CreateRoleRequest{
 		TenantID:    tenantID,
@@ -89,7 +89,7 @@ func TestService_AssignRoleToUser_Integration(t *testing.T) {
 	err = userRepo.Create(context.Background(), user)
 	require.NoError(t, err)
 
-	service := NewService(roleRepo, permissionRepo)
+	service := NewService(roleRepo, permissionRepo, nil, nil)
 
 	// Create role
 	createReq := &CreateRoleRequest{
@@ -133,7 +133,7 @@ func TestService_AssignPermissionToRole_Integration(t *testing.T) {
 	err := tenantRepo.Create(context.Background(), tenant)
 	require.NoError(t, err)
 
-	roleService := NewService(roleRepo, permissionRepo)
+	roleService := NewService(roleRepo, permissionRepo, nil, nil)
 	
 	// Import permission service
 	permissionService := permission.NewService(permissionRepo)
@@ -2,6 +2,7 @@ package role
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
@@ -17,9 +18,15 @@ type ServiceInterface interface {
 	List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.RoleFilters) ([]*models.Role, error)
 	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error)
 	AssignRoleToUser(ctx context.Context, userID, roleID uuid.UUID) error
+	AssignRoleToUserUntil(ctx context.Context, userID, roleID uuid.UUID, expiresAt time.Time, assignedBy *uuid.UUID) error
+	CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error
 	RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error
 	GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]*models.Permission, error)
 	AssignPermissionToRole(ctx context.Context, roleID, permissionID uuid.UUID) error
 	RemovePermissionFromRole(ctx context.Context, roleID, permissionID uuid.UUID) error
+	GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error)
+	AddParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error
+	RemoveParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error
+	GetEffectivePermissions(ctx context.Context, roleID uuid.UUID) ([]*EffectivePermission, error)
 }
 
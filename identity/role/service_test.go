@@ -3,6 +3,7 @@ package role
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
@@ -67,6 +68,32 @@ func (m *MockRoleRepository) RemoveRoleFromUser(ctx context.Context, userID, rol
 	return args.Error(0)
 }
 
+func (m *MockRoleRepository) GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	args := m.Called(ctx, roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetChildRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	args := m.Called(ctx, roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) AddParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	args := m.Called(ctx, roleID, parentRoleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) RemoveParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	args := m.Called(ctx, roleID, parentRoleID)
+	return args.Error(0)
+}
+
 func (m *MockRoleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -75,10 +102,31 @@ func (m *MockRoleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID)
 	return args.Get(0).([]*models.Role), args.Error(1)
 }
 
+func (m *MockRoleRepository) AssignRoleToUserWithExpiry(ctx context.Context, assignment *models.UserRoleAssignment) error {
+	args := m.Called(ctx, assignment)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) GetUserRoleAssignments(ctx context.Context, userID uuid.UUID) ([]*models.UserRoleAssignment, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.UserRoleAssignment), args.Error(1)
+}
+
+func (m *MockRoleRepository) DeleteExpiredRoleAssignments(ctx context.Context, before time.Time) ([]*models.UserRoleAssignment, error) {
+	args := m.Called(ctx, before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.UserRoleAssignment), args.Error(1)
+}
+
 func TestService_Create(t *testing.T) {
 	mockRepo := new(MockRoleRepository)
 	mockPermRepo := new(MockPermissionRepository)
-	service := NewService(mockRepo, mockPermRepo)
+	service := NewService(mockRepo, mockPermRepo, nil, nil)
 
 	tenantID := uuid.New()
 	desc := "Administrator role"
@@ -111,7 +159,7 @@ func TestService_Create(t *testing.T) {
 func TestService_GetByID(t *testing.T) {
 	mockRepo := new(MockRoleRepository)
 	mockPermRepo := new(MockPermissionRepository)
-	service := NewService(mockRepo, mockPermRepo)
+	service := NewService(mockRepo, mockPermRepo, nil, nil)
 
 	roleID := uuid.New()
 	expectedRole := &models.Role{
@@ -132,7 +180,7 @@ func TestService_GetByID(t *testing.T) {
 func TestService_AssignRoleToUser(t *testing.T) {
 	mockRepo := new(MockRoleRepository)
 	mockPermRepo := new(MockPermissionRepository)
-	service := NewService(mockRepo, mockPermRepo)
+	service := NewService(mockRepo, mockPermRepo, nil, nil)
 
 	userID := uuid.New()
 	roleID := uuid.New()
@@ -147,6 +195,38 @@ func TestService_AssignRoleToUser(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_AssignRoleToUserUntil(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+	mockPermRepo := new(MockPermissionRepository)
+	service := NewService(mockRepo, mockPermRepo, nil, nil)
+
+	userID := uuid.New()
+	assignedBy := uuid.New()
+	role := &models.Role{ID: uuid.New(), TenantID: uuid.New()}
+	expiresAt := time.Now().Add(2 * time.Hour)
+
+	mockRepo.On("GetByID", mock.Anything, role.ID).Return(role, nil)
+	mockRepo.On("AssignRoleToUserWithExpiry", mock.Anything, mock.MatchedBy(func(a *models.UserRoleAssignment) bool {
+		return a.UserID == userID && a.Role == role && a.ExpiresAt.Equal(expiresAt) && *a.AssignedBy == assignedBy
+	})).Return(nil)
+
+	err := service.AssignRoleToUserUntil(context.Background(), userID, role.ID, expiresAt, &assignedBy)
+	require.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_AssignRoleToUserUntil_PastExpiry(t *testing.T) {
+	mockRepo := new(MockRoleRepository)
+	service := NewService(mockRepo, new(MockPermissionRepository), nil, nil)
+
+	err := service.AssignRoleToUserUntil(context.Background(), uuid.New(), uuid.New(), time.Now().Add(-time.Minute), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be in the future")
+
+	mockRepo.AssertNotCalled(t, "AssignRoleToUserWithExpiry", mock.Anything, mock.Anything)
+}
+
 // MockPermissionRepository for role service tests
 type MockPermissionRepository struct {
 	mock.Mock
@@ -0,0 +1,438 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// Bulk limits, advertised in ServiceProviderConfig
+const (
+	MaxBulkOperations  = 100
+	MaxBulkPayloadSize = 1 << 20
+)
+
+// bulkIDReference matches "bulkId:<id>" references in operation paths and data
+var bulkIDReference = regexp.MustCompile(`bulkId:([^"/\s,\]}]+)`)
+
+type bulkState int
+
+const (
+	bulkPending bulkState = iota
+	bulkVisiting
+	bulkDone
+)
+
+// errBulkFailed rolls back the transaction of an atomic request that failed
+var errBulkFailed = errors.New("bulk operation failed")
+
+// bulkOutcome is the effect of one applied operation
+type bulkOutcome struct {
+	id       string
+	location string
+	version  string
+	status   int
+}
+
+// bulkRun holds the state of one bulk request while it is processed
+type bulkRun struct {
+	s        *ProvisioningService
+	ctx      context.Context
+	tenantID uuid.UUID
+	req      *BulkRequest
+
+	byBulkID map[string]int
+	state    []bulkState
+	results  []*BulkOperationResult
+	resolved map[string]string // bulkId to created resource ID
+	errors   int
+	stopped  bool
+}
+
+// Bulk processes a bulk request (RFC 7644 section 3.7). Operations run in
+// request order, except that an operation referencing "bulkId:<id>" runs
+// after the operation that defines that bulkId, with the reference replaced
+// by the created resource's ID. Processing stops once failOnErrors errors
+// have occurred; unprocessed operations are left out of the response.
+//
+// Each operation runs in its own transaction, so a failed operation leaves
+// nothing behind. In atomic mode all operations run in one transaction: the
+// first error stops processing and rolls back every operation, whose results
+// then report that they were rolled back. Atomic mode needs the service's
+// transaction runner.
+func (s *ProvisioningService) Bulk(ctx context.Context, tenantID uuid.UUID, req *BulkRequest) (*BulkResponse, error) {
+	if len(req.Operations) > MaxBulkOperations {
+		return nil, fmt.Errorf("too many operations: the request has %d, the maximum is %d", len(req.Operations), MaxBulkOperations)
+	}
+	if req.FailOnErrors < 0 {
+		return nil, fmt.Errorf("invalid value: failOnErrors must not be negative")
+	}
+	if req.Atomic && s.txRunner == nil {
+		return nil, fmt.Errorf("invalid value: atomic bulk requests are not supported")
+	}
+
+	run := &bulkRun{
+		s:        s,
+		ctx:      ctx,
+		tenantID: tenantID,
+		req:      req,
+		byBulkID: make(map[string]int),
+		state:    make([]bulkState, len(req.Operations)),
+		results:  make([]*BulkOperationResult, len(req.Operations)),
+		resolved: make(map[string]string),
+	}
+	for i, op := range req.Operations {
+		if op.BulkID == "" {
+			continue
+		}
+		if _, exists := run.byBulkID[op.BulkID]; exists {
+			return nil, fmt.Errorf("invalid value: bulkId %q is used more than once", op.BulkID)
+		}
+		run.byBulkID[op.BulkID] = i
+	}
+
+	if req.Atomic {
+		err := s.txRunner.WithinTx(ctx, func(ctx context.Context) error {
+			run.ctx = ctx
+			run.processAll()
+			if run.errors > 0 {
+				return errBulkFailed
+			}
+			return nil
+		})
+		run.ctx = ctx
+		switch {
+		case errors.Is(err, errBulkFailed):
+			run.rolledBack()
+		case err != nil:
+			return nil, fmt.Errorf("failed to commit bulk request: %w", err)
+		}
+	} else {
+		run.processAll()
+	}
+
+	response := &BulkResponse{
+		Schemas:    []string{"urn:ietf:params:scim:api:messages:2.0:BulkResponse"},
+		Operations: []BulkOperationResult{},
+	}
+	for _, result := range run.results {
+		if result != nil {
+			response.Operations = append(response.Operations, *result)
+		}
+	}
+	return response, nil
+}
+
+// processAll runs the operations in request order until processing stops
+func (r *bulkRun) processAll() {
+	for i := range r.req.Operations {
+		if r.stopped {
+			return
+		}
+		r.process(i)
+	}
+}
+
+// process runs operation i after the operations it references
+func (r *bulkRun) process(i int) {
+	if r.state[i] != bulkPending {
+		return
+	}
+	r.state[i] = bulkVisiting
+	op := r.req.Operations[i]
+
+	for _, ref := range bulkReferences(op) {
+		j, ok := r.byBulkID[ref]
+		if !ok {
+			r.fail(i, http.StatusConflict, "invalidValue", fmt.Sprintf("bulkId %s is not defined in this request", ref))
+			return
+		}
+		if r.state[j] == bulkVisiting {
+			r.fail(i, http.StatusConflict, "invalidValue", fmt.Sprintf("circular reference to bulkId %s", ref))
+			return
+		}
+		r.process(j)
+		if r.stopped {
+			r.state[i] = bulkPending
+			return
+		}
+		if _, ok := r.resolved[ref]; !ok {
+			r.fail(i, http.StatusConflict, "invalidValue", fmt.Sprintf("bulkId %s did not resolve because its operation failed", ref))
+			return
+		}
+	}
+
+	r.execute(i)
+}
+
+// execute applies operation i, whose references have all resolved
+func (r *bulkRun) execute(i int) {
+	op := r.req.Operations[i]
+	path := r.substitute(op.Path)
+	data := json.RawMessage(r.substitute(string(op.Data)))
+	method := strings.ToUpper(op.Method)
+
+	resourceType, id, err := parseBulkPath(method, path)
+	if err == nil && method == http.MethodPost && op.BulkID == "" {
+		err = fmt.Errorf("invalid value: POST operations require a bulkId")
+	}
+	if err == nil {
+		// Every bulk operation writes, so it needs the write scope of its resource type
+		err = requireScope(r.ctx, strings.ToLower(resourceType), "write")
+	}
+	if err != nil {
+		r.failWith(i, err)
+		return
+	}
+
+	var outcome *bulkOutcome
+	err = r.s.withinTx(r.ctx, func(ctx context.Context) error {
+		var err error
+		outcome, err = r.apply(ctx, method, resourceType, id, data, op.Version)
+		return err
+	})
+	if err != nil {
+		r.failWith(i, err)
+		return
+	}
+	r.succeed(i, outcome)
+}
+
+// apply runs one operation against the provisioning service
+func (r *bulkRun) apply(ctx context.Context, method, resourceType, id string, data json.RawMessage, version string) (*bulkOutcome, error) {
+	if resourceType == "Users" {
+		return r.applyUser(ctx, method, id, data, version)
+	}
+	return r.applyGroup(ctx, method, id, data, version)
+}
+
+func (r *bulkRun) applyUser(ctx context.Context, method, id string, data json.RawMessage, version string) (*bulkOutcome, error) {
+	s, tenantID := r.s, r.tenantID
+
+	if method == http.MethodPost {
+		var scimUser models.SCIMUser
+		if err := json.Unmarshal(data, &scimUser); err != nil {
+			return nil, fmt.Errorf("invalid syntax: %v", err)
+		}
+		created, err := s.CreateUser(ctx, tenantID, &scimUser)
+		if err != nil {
+			return nil, err
+		}
+		return &bulkOutcome{
+			id: created.ID, location: created.Meta.Location, version: created.Meta.Version,
+			status: http.StatusCreated,
+		}, nil
+	}
+
+	if version != "" {
+		if err := s.CheckUserVersion(ctx, tenantID, id, version); err != nil {
+			return nil, err
+		}
+	}
+
+	var updated *models.SCIMUser
+	var err error
+	switch method {
+	case http.MethodDelete:
+		if err := s.DeleteUser(ctx, tenantID, id); err != nil {
+			return nil, err
+		}
+		return &bulkOutcome{id: id, status: http.StatusNoContent}, nil
+	case http.MethodPut:
+		var scimUser models.SCIMUser
+		if err := json.Unmarshal(data, &scimUser); err != nil {
+			return nil, fmt.Errorf("invalid syntax: %v", err)
+		}
+		updated, err = s.UpdateUser(ctx, tenantID, id, &scimUser)
+	case http.MethodPatch:
+		var patch PatchRequest
+		if err := json.Unmarshal(data, &patch); err != nil {
+			return nil, fmt.Errorf("invalid syntax: %v", err)
+		}
+		updated, err = s.PatchUser(ctx, tenantID, id, &patch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bulkOutcome{
+		id: id, location: updated.Meta.Location, version: updated.Meta.Version,
+		status: http.StatusOK,
+	}, nil
+}
+
+func (r *bulkRun) applyGroup(ctx context.Context, method, id string, data json.RawMessage, version string) (*bulkOutcome, error) {
+	s, tenantID := r.s, r.tenantID
+
+	if method == http.MethodPost {
+		var scimGroup models.SCIMGroup
+		if err := json.Unmarshal(data, &scimGroup); err != nil {
+			return nil, fmt.Errorf("invalid syntax: %v", err)
+		}
+		created, err := s.CreateGroup(ctx, tenantID, &scimGroup)
+		if err != nil {
+			return nil, err
+		}
+		return &bulkOutcome{
+			id: created.ID, location: created.Meta.Location, version: created.Meta.Version,
+			status: http.StatusCreated,
+		}, nil
+	}
+
+	if version != "" {
+		if err := s.CheckGroupVersion(ctx, tenantID, id, version); err != nil {
+			return nil, err
+		}
+	}
+
+	var updated *models.SCIMGroup
+	var err error
+	switch method {
+	case http.MethodDelete:
+		if err := s.DeleteGroup(ctx, tenantID, id); err != nil {
+			return nil, err
+		}
+		return &bulkOutcome{id: id, status: http.StatusNoContent}, nil
+	case http.MethodPut:
+		var scimGroup models.SCIMGroup
+		if err := json.Unmarshal(data, &scimGroup); err != nil {
+			return nil, fmt.Errorf("invalid syntax: %v", err)
+		}
+		updated, err = s.UpdateGroup(ctx, tenantID, id, &scimGroup)
+	case http.MethodPatch:
+		var patch PatchRequest
+		if err := json.Unmarshal(data, &patch); err != nil {
+			return nil, fmt.Errorf("invalid syntax: %v", err)
+		}
+		updated, err = s.PatchGroup(ctx, tenantID, id, &patch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bulkOutcome{
+		id: id, location: updated.Meta.Location, version: updated.Meta.Version,
+		status: http.StatusOK,
+	}, nil
+}
+
+// rolledBack reports the operations of a failed atomic request that
+// succeeded before it was rolled back
+func (r *bulkRun) rolledBack() {
+	for i, result := range r.results {
+		if result == nil || result.Response != nil {
+			continue
+		}
+		status := http.StatusFailedDependency
+		r.results[i] = &BulkOperationResult{
+			Method:   result.Method,
+			BulkID:   result.BulkID,
+			Status:   strconv.Itoa(status),
+			Response: bulkError(status, "", "rolled back because another operation in the atomic request failed"),
+		}
+	}
+}
+
+func (r *bulkRun) succeed(i int, outcome *bulkOutcome) {
+	op := r.req.Operations[i]
+	if op.BulkID != "" && outcome.id != "" {
+		r.resolved[op.BulkID] = outcome.id
+	}
+	result := &BulkOperationResult{
+		Location: outcome.location,
+		Method:   op.Method,
+		BulkID:   op.BulkID,
+		Version:  outcome.version,
+		Status:   strconv.Itoa(outcome.status),
+	}
+	r.results[i] = result
+	r.state[i] = bulkDone
+}
+
+func (r *bulkRun) failWith(i int, err error) {
+	status, scimType := ErrorStatus(err, http.StatusBadRequest)
+	r.fail(i, status, scimType, err.Error())
+}
+
+func (r *bulkRun) fail(i int, status int, scimType, detail string) {
+	op := r.req.Operations[i]
+	r.results[i] = &BulkOperationResult{
+		Method:   op.Method,
+		BulkID:   op.BulkID,
+		Status:   strconv.Itoa(status),
+		Response: bulkError(status, scimType, detail),
+	}
+	r.state[i] = bulkDone
+	r.errors++
+	if r.req.Atomic || (r.req.FailOnErrors > 0 && r.errors >= r.req.FailOnErrors) {
+		r.stopped = true
+	}
+}
+
+// substitute replaces resolved bulkId references with resource IDs
+func (r *bulkRun) substitute(s string) string {
+	return bulkIDReference.ReplaceAllStringFunc(s, func(match string) string {
+		if id, ok := r.resolved[strings.TrimPrefix(match, "bulkId:")]; ok {
+			return id
+		}
+		return match
+	})
+}
+
+// bulkReferences returns the distinct bulkIds an operation references
+func bulkReferences(op BulkOperation) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, m := range bulkIDReference.FindAllStringSubmatch(op.Path+" "+string(op.Data), -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			refs = append(refs, m[1])
+		}
+	}
+	return refs
+}
+
+// parseBulkPath splits "/Users", "/Groups/<id>" and the like. POST takes a
+// resource type only; the other methods need a resource ID.
+func parseBulkPath(method, path string) (resourceType, id string, err error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	resourceType = parts[0]
+	if resourceType != "Users" && resourceType != "Groups" {
+		return "", "", fmt.Errorf("invalid path: %q is not a Users or Groups path", path)
+	}
+	if len(parts) > 2 {
+		return "", "", fmt.Errorf("invalid path: %q", path)
+	}
+	if len(parts) == 2 {
+		id = parts[1]
+	}
+
+	switch method {
+	case http.MethodPost:
+		if id != "" {
+			return "", "", fmt.Errorf("invalid path: POST takes a resource type path such as /%s", resourceType)
+		}
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		if id == "" {
+			return "", "", fmt.Errorf("invalid path: %s requires a resource ID", method)
+		}
+	default:
+		return "", "", fmt.Errorf("invalid syntax: unsupported method %q", method)
+	}
+	return resourceType, id, nil
+}
+
+func bulkError(status int, scimType, detail string) models.SCIMError {
+	return models.SCIMError{
+		Schemas:  []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+		Detail:   detail,
+		Status:   strconv.Itoa(status),
+		SCIMType: scimType,
+	}
+}
@@ -0,0 +1,231 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBulkTestService() (ProvisioningServiceInterface, *stubGroupService) {
+	groups := newStubGroupService()
+	return NewProvisioningService(nil, groups, &stubUserRepository{users: map[uuid.UUID]*models.User{}}, nil, nil, nil), groups
+}
+
+func bulkData(t *testing.T, v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+func TestBulk_ResolvesBulkIDReferences(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	service, _ := newBulkTestService()
+
+	// The parent references the child, which is defined later in the request
+	response, err := service.Bulk(ctx, tenantID, &BulkRequest{Operations: []BulkOperation{
+		{Method: "POST", Path: "/Groups", BulkID: "parent", Data: bulkData(t, map[string]interface{}{
+			"displayName": "parent",
+			"members":     []interface{}{map[string]interface{}{"value": "bulkId:child", "type": "Group"}},
+		})},
+		{Method: "POST", Path: "/Groups", BulkID: "child", Data: bulkData(t, map[string]interface{}{"displayName": "child"})},
+		{Method: "PATCH", Path: "/Groups/bulkId:child", Data: bulkData(t, map[string]interface{}{
+			"Operations": []interface{}{map[string]interface{}{"op": "replace", "path": "displayName", "value": "renamed"}},
+		})},
+	}})
+	require.NoError(t, err)
+	require.Len(t, response.Operations, 3)
+	for _, result := range response.Operations {
+		assert.Nil(t, result.Response)
+	}
+	assert.Equal(t, "201", response.Operations[0].Status)
+	assert.Equal(t, "201", response.Operations[1].Status)
+	assert.Equal(t, "200", response.Operations[2].Status)
+	assert.NotEmpty(t, response.Operations[1].Version)
+
+	childID := response.Operations[1].Location[len("/scim/v2/Groups/"):]
+	parent, err := service.GetGroup(ctx, tenantID, response.Operations[0].Location[len("/scim/v2/Groups/"):])
+	require.NoError(t, err)
+	require.Len(t, parent.Members, 1)
+	assert.Equal(t, childID, parent.Members[0].Value)
+
+	child, err := service.GetGroup(ctx, tenantID, childID)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", child.DisplayName)
+}
+
+func TestBulk_ReferenceErrors(t *testing.T) {
+	service, _ := newBulkTestService()
+
+	response, err := service.Bulk(context.Background(), uuid.New(), &BulkRequest{Operations: []BulkOperation{
+		{Method: "POST", Path: "/Groups", BulkID: "a", Data: json.RawMessage(`{"displayName":"a","members":[{"value":"bulkId:b"}]}`)},
+		{Method: "POST", Path: "/Groups", BulkID: "b", Data: json.RawMessage(`{"displayName":"b","members":[{"value":"bulkId:a"}]}`)},
+		{Method: "POST", Path: "/Groups", BulkID: "c", Data: json.RawMessage(`{"displayName":"c","members":[{"value":"bulkId:missing"}]}`)},
+	}})
+	require.NoError(t, err)
+	require.Len(t, response.Operations, 3)
+	for _, result := range response.Operations {
+		assert.Equal(t, "409", result.Status)
+		require.IsType(t, models.SCIMError{}, result.Response)
+		assert.Equal(t, "invalidValue", result.Response.(models.SCIMError).SCIMType)
+	}
+	assert.Contains(t, response.Operations[1].Response.(models.SCIMError).Detail, "circular")
+	assert.Contains(t, response.Operations[2].Response.(models.SCIMError).Detail, "not defined")
+}
+
+func TestBulk_FailOnErrors(t *testing.T) {
+	service, groups := newBulkTestService()
+
+	response, err := service.Bulk(context.Background(), uuid.New(), &BulkRequest{
+		FailOnErrors: 1,
+		Operations: []BulkOperation{
+			{Method: "PUT", Path: "/Groups/" + uuid.NewString(), Data: json.RawMessage(`{"displayName":"x"}`)},
+			{Method: "POST", Path: "/Groups", BulkID: "g", Data: json.RawMessage(`{"displayName":"g"}`)},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Operations, 1, "processing stops at the first error")
+	assert.Equal(t, "404", response.Operations[0].Status)
+	assert.Empty(t, groups.groups)
+}
+
+// snapshotTxRunner rolls the stub group service back to its state at the
+// start of a failed transaction
+type snapshotTxRunner struct {
+	groups *stubGroupService
+}
+
+func (r *snapshotTxRunner) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	groups := make(map[uuid.UUID]models.Group, len(r.groups.groups))
+	for id, g := range r.groups.groups {
+		groups[id] = *g
+	}
+	users := make(map[uuid.UUID][]uuid.UUID, len(r.groups.users))
+	for id, members := range r.groups.users {
+		users[id] = members
+	}
+	memberGroups := make(map[uuid.UUID][]uuid.UUID, len(r.groups.memberGroups))
+	for id, members := range r.groups.memberGroups {
+		memberGroups[id] = members
+	}
+
+	if err := fn(ctx); err != nil {
+		r.groups.groups = make(map[uuid.UUID]*models.Group, len(groups))
+		for id, g := range groups {
+			g := g
+			r.groups.groups[id] = &g
+		}
+		r.groups.users = users
+		r.groups.memberGroups = memberGroups
+		return err
+	}
+	return nil
+}
+
+func newAtomicBulkTestService() (ProvisioningServiceInterface, *stubGroupService) {
+	groups := newStubGroupService()
+	users := &stubUserRepository{users: map[uuid.UUID]*models.User{}}
+	return NewProvisioningService(nil, groups, users, nil, nil, &snapshotTxRunner{groups: groups}), groups
+}
+
+func TestBulk_AtomicRollsBack(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	service, groups := newAtomicBulkTestService()
+	existing, err := service.CreateGroup(ctx, tenantID, &models.SCIMGroup{DisplayName: "existing"})
+	require.NoError(t, err)
+
+	response, err := service.Bulk(ctx, tenantID, &BulkRequest{
+		Atomic: true,
+		Operations: []BulkOperation{
+			{Method: "DELETE", Path: "/Groups/" + existing.ID},
+			{Method: "POST", Path: "/Groups", BulkID: "g", Data: json.RawMessage(`{"displayName":"g"}`)},
+			{Method: "PATCH", Path: "/Groups/bulkId:g", Data: json.RawMessage(`{"Operations":[{"op":"replace","path":"displayName","value":"renamed"}]}`)},
+			{Method: "POST", Path: "/Groups", BulkID: "bad", Data: json.RawMessage(`{}`)},
+			{Method: "POST", Path: "/Groups", BulkID: "never", Data: json.RawMessage(`{"displayName":"never"}`)},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Operations, 4, "processing stops at the first error")
+	for _, result := range response.Operations[:3] {
+		assert.Equal(t, "424", result.Status)
+	}
+	assert.Equal(t, "g", response.Operations[1].BulkID)
+	assert.Equal(t, "400", response.Operations[3].Status)
+
+	// Nothing the request did is left behind
+	require.Len(t, groups.groups, 1)
+	got, err := service.GetGroup(ctx, tenantID, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "existing", got.DisplayName)
+}
+
+func TestBulk_AtomicCommits(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	service, groups := newAtomicBulkTestService()
+
+	response, err := service.Bulk(ctx, tenantID, &BulkRequest{
+		Atomic: true,
+		Operations: []BulkOperation{
+			{Method: "POST", Path: "/Groups", BulkID: "a", Data: json.RawMessage(`{"displayName":"a"}`)},
+			{Method: "POST", Path: "/Groups", BulkID: "b", Data: json.RawMessage(`{"displayName":"b"}`)},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Operations, 2)
+	for _, result := range response.Operations {
+		assert.Equal(t, "201", result.Status)
+	}
+	assert.Len(t, groups.groups, 2)
+}
+
+func TestBulk_AtomicNeedsTxRunner(t *testing.T) {
+	service, groups := newBulkTestService()
+
+	_, err := service.Bulk(context.Background(), uuid.New(), &BulkRequest{
+		Atomic:     true,
+		Operations: []BulkOperation{{Method: "POST", Path: "/Groups", BulkID: "g", Data: json.RawMessage(`{"displayName":"g"}`)}},
+	})
+	require.Error(t, err)
+	assert.Empty(t, groups.groups)
+}
+
+func TestBulk_Limits(t *testing.T) {
+	service, _ := newBulkTestService()
+
+	_, err := service.Bulk(context.Background(), uuid.New(), &BulkRequest{Operations: make([]BulkOperation, MaxBulkOperations+1)})
+	require.Error(t, err)
+	status, _ := ErrorStatus(err, 400)
+	assert.Equal(t, 413, status)
+
+	_, err = service.Bulk(context.Background(), uuid.New(), &BulkRequest{Operations: []BulkOperation{
+		{Method: "POST", Path: "/Groups", BulkID: "x"},
+		{Method: "POST", Path: "/Groups", BulkID: "x"},
+	}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than once")
+}
+
+func TestParseBulkPath(t *testing.T) {
+	resourceType, id, err := parseBulkPath("PATCH", "/Users/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "Users", resourceType)
+	assert.Equal(t, "abc", id)
+
+	for _, tc := range []struct{ method, path string }{
+		{"POST", "/Users/abc"},
+		{"DELETE", "/Groups"},
+		{"PUT", "/Roles/abc"},
+		{"GET", "/Users/abc"},
+		{"PATCH", "/Users/abc/extra"},
+	} {
+		_, _, err := parseBulkPath(tc.method, tc.path)
+		assert.Error(t, err, "%s %s", tc.method, tc.path)
+	}
+}
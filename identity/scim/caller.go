@@ -0,0 +1,40 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// Caller is the SCIM client making a request: the token it authenticated
+// with and where the request came from
+type Caller struct {
+	Token     *models.SCIMToken
+	SourceIP  string
+	UserAgent string
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context carrying the SCIM caller of a request
+func WithCaller(ctx context.Context, caller *Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the SCIM caller of a request, if any
+func CallerFromContext(ctx context.Context) (*Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(*Caller)
+	return caller, ok && caller.Token != nil
+}
+
+// requireScope checks that the caller's token grants an access level to a
+// resource type. Requests without a caller, made from within the service, are
+// not restricted.
+func requireScope(ctx context.Context, resource, access string) error {
+	caller, ok := CallerFromContext(ctx)
+	if !ok || caller.Token.HasScope(resource, access) {
+		return nil
+	}
+	return fmt.Errorf("insufficient scope: %s.%s is required", resource, access)
+}
@@ -0,0 +1,107 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/audit"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditService keeps the events logged through it
+type recordingAuditService struct {
+	audit.ServiceInterface
+	mu     sync.Mutex
+	events []*models.AuditEvent
+}
+
+func (s *recordingAuditService) LogEvent(ctx context.Context, event *models.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestSCIMToken_HasScope(t *testing.T) {
+	token := &models.SCIMToken{Scopes: []string{"users.read", "groups"}}
+	assert.True(t, token.HasScope("users", "read"))
+	assert.False(t, token.HasScope("users", "write"))
+	assert.True(t, token.HasScope("groups", "read"), "a bare resource scope grants read and write")
+	assert.True(t, token.HasScope("groups", "write"))
+
+	assert.True(t, (&models.SCIMToken{Scopes: []string{"*"}}).HasScope("users", "write"))
+	assert.False(t, (&models.SCIMToken{}).HasScope("users", "read"))
+}
+
+func TestSCIMToken_AllowsIP(t *testing.T) {
+	assert.True(t, (&models.SCIMToken{}).AllowsIP("203.0.113.7"), "tokens without an allowlist may be used from anywhere")
+
+	token := &models.SCIMToken{AllowedIPs: []string{"10.0.0.0/8", "203.0.113.7", "2001:db8::/32"}}
+	assert.True(t, token.AllowsIP("10.20.30.40"))
+	assert.True(t, token.AllowsIP("203.0.113.7"))
+	assert.True(t, token.AllowsIP("2001:db8::1"))
+	assert.False(t, token.AllowsIP("203.0.113.8"))
+	assert.False(t, token.AllowsIP("not-an-ip"))
+}
+
+func TestProvisioningService_AuditsChangesAgainstToken(t *testing.T) {
+	tenantID := uuid.New()
+	auditLog := &recordingAuditService{}
+	groups := newStubGroupService()
+	service := NewProvisioningService(nil, groups, &stubUserRepository{users: map[uuid.UUID]*models.User{}}, nil, auditLog, nil)
+
+	// Changes made without a SCIM caller are not attributed to anyone
+	_, err := service.CreateGroup(context.Background(), tenantID, &models.SCIMGroup{DisplayName: "internal"})
+	require.NoError(t, err)
+	assert.Empty(t, auditLog.events)
+
+	token := &models.SCIMToken{ID: uuid.New(), TenantID: tenantID, Name: "okta", Scopes: []string{"groups"}}
+	ctx := WithCaller(context.Background(), &Caller{Token: token, SourceIP: "203.0.113.7", UserAgent: "Okta SCIM"})
+
+	created, err := service.CreateGroup(ctx, tenantID, &models.SCIMGroup{DisplayName: "engineering"})
+	require.NoError(t, err)
+	_, err = service.PatchGroup(ctx, tenantID, created.ID, &PatchRequest{Operations: []PatchOperation{
+		{Op: "replace", Path: "displayName", Value: "platform"},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteGroup(ctx, tenantID, created.ID))
+
+	require.Len(t, auditLog.events, 3)
+	assert.Equal(t, models.EventTypeGroupCreated, auditLog.events[0].EventType)
+	assert.Equal(t, models.EventTypeGroupUpdated, auditLog.events[1].EventType)
+	assert.Equal(t, models.EventTypeGroupDeleted, auditLog.events[2].EventType)
+	for _, event := range auditLog.events {
+		assert.Equal(t, token.ID, event.Actor.UserID)
+		assert.Equal(t, "okta", event.Actor.Username)
+		assert.Equal(t, string(models.PrincipalTypeService), event.Actor.PrincipalType)
+		assert.Equal(t, tenantID, *event.TenantID)
+		assert.Equal(t, "203.0.113.7", event.SourceIP)
+		assert.Equal(t, token.ID.String(), event.Metadata["scim_token_id"])
+		assert.Equal(t, "group", event.Target.Type)
+	}
+}
+
+func TestBulk_RequiresWriteScopePerOperation(t *testing.T) {
+	tenantID := uuid.New()
+	service, groups := newBulkTestService()
+	token := &models.SCIMToken{ID: uuid.New(), TenantID: tenantID, Scopes: []string{"users.write", "groups.read"}}
+	ctx := WithCaller(context.Background(), &Caller{Token: token})
+
+	response, err := service.Bulk(ctx, tenantID, &BulkRequest{Operations: []BulkOperation{
+		{Method: "POST", Path: "/Groups", BulkID: "g", Data: bulkData(t, map[string]interface{}{"displayName": "blocked"})},
+	}})
+	require.NoError(t, err)
+	require.Len(t, response.Operations, 1)
+	assert.Equal(t, "403", response.Operations[0].Status)
+	assert.Contains(t, response.Operations[0].Response.(models.SCIMError).Detail, "groups.write")
+	assert.Empty(t, groups.groups)
+
+	status, _ := ErrorStatus(requireScope(ctx, "groups", "write"), http.StatusBadRequest)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.NoError(t, requireScope(ctx, "groups", "read"))
+}
@@ -0,0 +1,135 @@
+package scim
+
+import (
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// Discovery documents for /Schemas and /ResourceTypes (RFC 7643 sections 6 and 7)
+
+// SchemaDocuments describes the core User and Group schemas, the enterprise
+// User extension and a tenant's extension schemas
+func SchemaDocuments(extensions []*models.SCIMSchemaExtension) []map[string]interface{} {
+	documents := []map[string]interface{}{
+		schemaDocument(models.SCIMUserSchema, "User", "User Account", []map[string]interface{}{
+			{"name": "userName", "type": "string", "required": true, "caseExact": false, "mutability": "readWrite", "uniqueness": "server"},
+			{"name": "name", "type": "complex", "required": false, "mutability": "readWrite", "subAttributes": []map[string]interface{}{
+				stringAttribute("formatted"), stringAttribute("familyName"), stringAttribute("givenName"),
+			}},
+			stringAttribute("displayName"),
+			{"name": "emails", "type": "complex", "multiValued": true, "required": false, "mutability": "readWrite", "subAttributes": []map[string]interface{}{
+				stringAttribute("value"), stringAttribute("type"),
+				{"name": "primary", "type": "boolean", "required": false, "mutability": "readWrite"},
+			}},
+			{"name": "active", "type": "boolean", "required": false, "mutability": "readWrite"},
+		}),
+		schemaDocument(models.SCIMGroupSchema, "Group", "Group", []map[string]interface{}{
+			{"name": "displayName", "type": "string", "required": true, "mutability": "readWrite"},
+			stringAttribute("externalId"),
+			stringAttribute("description"),
+			{"name": "members", "type": "complex", "multiValued": true, "required": false, "mutability": "readWrite", "subAttributes": []map[string]interface{}{
+				{"name": "value", "type": "string", "required": false, "mutability": "immutable"},
+				{"name": "type", "type": "string", "required": false, "mutability": "immutable", "canonicalValues": []string{"User", "Group"}},
+			}},
+		}),
+		schemaDocument(models.SCIMEnterpriseUserSchema, "EnterpriseUser", "Enterprise User", []map[string]interface{}{
+			stringAttribute("employeeNumber"),
+			stringAttribute("costCenter"),
+			stringAttribute("organization"),
+			stringAttribute("division"),
+			stringAttribute("department"),
+			{"name": "manager", "type": "complex", "required": false, "mutability": "readWrite", "subAttributes": []map[string]interface{}{
+				{"name": "value", "type": "string", "required": false, "mutability": "readWrite"},
+				{"name": "$ref", "type": "reference", "referenceTypes": []string{"User"}, "required": false, "mutability": "readOnly"},
+			}},
+		}),
+	}
+
+	for _, ext := range extensions {
+		attributes := make([]map[string]interface{}, 0, len(ext.Attributes))
+		for _, attr := range ext.Attributes {
+			doc := map[string]interface{}{
+				"name":        attr.Name,
+				"type":        attr.Type,
+				"multiValued": attr.MultiValued,
+				"required":    attr.Required,
+				"caseExact":   attr.CaseExact,
+				"mutability":  attr.Mutability,
+				"returned":    "default",
+			}
+			if attr.Mutability == models.SCIMMutabilityWriteOnly {
+				doc["returned"] = "never"
+			}
+			if attr.Description != "" {
+				doc["description"] = attr.Description
+			}
+			if len(attr.CanonicalValues) > 0 {
+				doc["canonicalValues"] = attr.CanonicalValues
+			}
+			attributes = append(attributes, doc)
+		}
+		description := ext.Name
+		if ext.Description != nil {
+			description = *ext.Description
+		}
+		documents = append(documents, schemaDocument(ext.SchemaURN, ext.Name, description, attributes))
+	}
+
+	return documents
+}
+
+// ResourceTypeDocuments describes the User and Group resource types. Users
+// carry the enterprise extension and the tenant's extension schemas.
+func ResourceTypeDocuments(extensions []*models.SCIMSchemaExtension) []map[string]interface{} {
+	userExtensions := []map[string]interface{}{
+		{"schema": models.SCIMEnterpriseUserSchema, "required": false},
+	}
+	for _, ext := range extensions {
+		userExtensions = append(userExtensions, map[string]interface{}{"schema": ext.SchemaURN, "required": ext.Required})
+	}
+
+	return []map[string]interface{}{
+		{
+			"schemas":          []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":               "User",
+			"name":             "User",
+			"endpoint":         "/Users",
+			"description":      "User Account",
+			"schema":           models.SCIMUserSchema,
+			"schemaExtensions": userExtensions,
+			"meta": map[string]interface{}{
+				"location":     "/v2/ResourceTypes/User",
+				"resourceType": "ResourceType",
+			},
+		},
+		{
+			"schemas":     []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":          "Group",
+			"name":        "Group",
+			"endpoint":    "/Groups",
+			"description": "Group",
+			"schema":      models.SCIMGroupSchema,
+			"meta": map[string]interface{}{
+				"location":     "/v2/ResourceTypes/Group",
+				"resourceType": "ResourceType",
+			},
+		},
+	}
+}
+
+func schemaDocument(id, name, description string, attributes []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"schemas":     []string{"urn:ietf:params:scim:schemas:core:2.0:Schema"},
+		"id":          id,
+		"name":        name,
+		"description": description,
+		"attributes":  attributes,
+		"meta": map[string]interface{}{
+			"location":     "/v2/Schemas/" + id,
+			"resourceType": "Schema",
+		},
+	}
+}
+
+func stringAttribute(name string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "type": "string", "required": false, "mutability": "readWrite"}
+}
@@ -0,0 +1,46 @@
+package scim
+
+import (
+	"net/http"
+	"strings"
+)
+
+// errorTypes maps the prefixes of provisioning errors to their SCIM error
+// type (RFC 7644 section 3.12). These errors are all client errors.
+var errorTypes = []struct {
+	prefix   string
+	scimType string
+}{
+	{"invalid filter", "invalidFilter"},
+	{"invalid sortBy", "invalidValue"},
+	{"invalid sortOrder", "invalidValue"},
+	{"invalid path", "invalidPath"},
+	{"no target", "noTarget"},
+	{"invalid value", "invalidValue"},
+	{"invalid syntax", "invalidSyntax"},
+	{"mutability", "mutability"},
+}
+
+// ErrorStatus returns the HTTP status and SCIM error type for a provisioning
+// error. Errors without a recognised form get fallbackStatus.
+func ErrorStatus(err error, fallbackStatus int) (int, string) {
+	msg := err.Error()
+	for _, t := range errorTypes {
+		if strings.HasPrefix(msg, t.prefix) {
+			return http.StatusBadRequest, t.scimType
+		}
+	}
+	switch {
+	case strings.HasPrefix(msg, "insufficient scope"):
+		return http.StatusForbidden, ""
+	case strings.HasPrefix(msg, "precondition failed"):
+		return http.StatusPreconditionFailed, ""
+	case strings.HasPrefix(msg, "too many operations"), strings.HasPrefix(msg, "payload too large"):
+		return http.StatusRequestEntityTooLarge, ""
+	case strings.Contains(msg, "already exists"):
+		return http.StatusConflict, "uniqueness"
+	case strings.Contains(msg, "not found"):
+		return http.StatusNotFound, ""
+	}
+	return fallbackStatus, ""
+}
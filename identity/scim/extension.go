@@ -0,0 +1,295 @@
+package scim
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// Schema extensions of the User resource are stored in the user's metadata:
+// the enterprise extension under the models.UserMetadata* keys and each
+// tenant-defined attribute under its own name. Metadata keys that no
+// extension claims are left alone by SCIM writes.
+
+// enterpriseMetadataKeys maps enterprise extension attributes to metadata keys
+var enterpriseMetadataKeys = []struct {
+	key   string
+	field func(*models.SCIMEnterpriseUser) *string
+}{
+	{models.UserMetadataEmployeeNumber, func(e *models.SCIMEnterpriseUser) *string { return &e.EmployeeNumber }},
+	{models.UserMetadataCostCenter, func(e *models.SCIMEnterpriseUser) *string { return &e.CostCenter }},
+	{models.UserMetadataOrganization, func(e *models.SCIMEnterpriseUser) *string { return &e.Organization }},
+	{models.UserMetadataDivision, func(e *models.SCIMEnterpriseUser) *string { return &e.Division }},
+	{models.UserMetadataDepartment, func(e *models.SCIMEnterpriseUser) *string { return &e.Department }},
+}
+
+// reservedAttributeNames cannot name tenant-defined attributes: they are
+// enterprise metadata keys or attributes policy.UserAttributes always sets
+var reservedAttributeNames = map[string]bool{
+	models.UserMetadataEmployeeNumber: true,
+	models.UserMetadataCostCenter:     true,
+	models.UserMetadataOrganization:   true,
+	models.UserMetadataDivision:       true,
+	models.UserMetadataDepartment:     true,
+	models.UserMetadataManagerID:      true,
+	"id":                              true,
+	"tenant_id":                       true,
+	"username":                        true,
+	"email":                           true,
+	"status":                          true,
+	"roles":                           true,
+}
+
+// enterpriseFromMetadata reads the enterprise extension from user metadata.
+// It returns nil when the user has none of its attributes.
+func enterpriseFromMetadata(metadata map[string]interface{}) *models.SCIMEnterpriseUser {
+	enterprise := &models.SCIMEnterpriseUser{}
+	found := false
+	for _, m := range enterpriseMetadataKeys {
+		if value, ok := metadata[m.key].(string); ok && value != "" {
+			*m.field(enterprise) = value
+			found = true
+		}
+	}
+	if managerID, ok := metadata[models.UserMetadataManagerID].(string); ok && managerID != "" {
+		enterprise.Manager = &models.SCIMManager{Value: managerID, Ref: "/scim/v2/Users/" + managerID}
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	return enterprise
+}
+
+// applyEnterprise writes the enterprise extension to user metadata. Attributes
+// the extension leaves empty are removed.
+func applyEnterprise(metadata map[string]interface{}, enterprise *models.SCIMEnterpriseUser) {
+	if enterprise == nil {
+		enterprise = &models.SCIMEnterpriseUser{}
+	}
+	for _, m := range enterpriseMetadataKeys {
+		setMetadata(metadata, m.key, *m.field(enterprise))
+	}
+	managerID := ""
+	if enterprise.Manager != nil {
+		managerID = enterprise.Manager.Value
+	}
+	setMetadata(metadata, models.UserMetadataManagerID, managerID)
+}
+
+func setMetadata(metadata map[string]interface{}, key, value string) {
+	if value == "" {
+		delete(metadata, key)
+		return
+	}
+	metadata[key] = value
+}
+
+// extensionsFromMetadata reads tenant-defined extension values from user
+// metadata. Write-only attributes are never returned.
+func extensionsFromMetadata(metadata map[string]interface{}, extensions []*models.SCIMSchemaExtension) map[string]map[string]interface{} {
+	var out map[string]map[string]interface{}
+	for _, ext := range extensions {
+		values := make(map[string]interface{})
+		for _, attr := range ext.Attributes {
+			if attr.Mutability == models.SCIMMutabilityWriteOnly {
+				continue
+			}
+			if value, ok := metadata[attr.Name]; ok && value != nil {
+				values[attr.Name] = value
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]map[string]interface{})
+		}
+		out[ext.SchemaURN] = values
+	}
+	return out
+}
+
+// applyExtensions validates tenant-defined extension values against their
+// schemas and writes them to metadata, which holds the user's current
+// values. As with PUT, read-write attributes missing from input are removed.
+// Read-only attributes cannot be set, immutable ones cannot change once set,
+// and write-only ones are kept when missing since they are never returned.
+func applyExtensions(metadata map[string]interface{}, input map[string]map[string]interface{}, extensions []*models.SCIMSchemaExtension) error {
+	byURN := make(map[string]*models.SCIMSchemaExtension, len(extensions))
+	for _, ext := range extensions {
+		byURN[strings.ToLower(ext.SchemaURN)] = ext
+	}
+	given := make(map[*models.SCIMSchemaExtension]map[string]interface{}, len(input))
+	for urn, values := range input {
+		ext, ok := byURN[strings.ToLower(urn)]
+		if !ok {
+			return fmt.Errorf("invalid syntax: unknown schema extension %s", urn)
+		}
+		given[ext] = values
+	}
+
+	for _, ext := range extensions {
+		values := make(map[string]interface{}, len(given[ext]))
+		for name, value := range given[ext] {
+			attr, ok := ext.Attribute(name)
+			if !ok {
+				return fmt.Errorf("invalid value: schema %s has no attribute %s", ext.SchemaURN, name)
+			}
+			if value != nil {
+				values[attr.Name] = value
+			}
+		}
+
+		carried := ext.Required
+		for i := range ext.Attributes {
+			attr := &ext.Attributes[i]
+			current, hasCurrent := metadata[attr.Name]
+			value, hasValue := values[attr.Name]
+			if hasValue {
+				var err error
+				if value, err = coerceExtensionValue(attr, value); err != nil {
+					return fmt.Errorf("invalid value: %s:%s %v", ext.SchemaURN, attr.Name, err)
+				}
+			}
+
+			switch attr.Mutability {
+			case models.SCIMMutabilityReadOnly:
+				if hasValue && !reflect.DeepEqual(value, current) {
+					return fmt.Errorf("mutability: %s:%s is read-only", ext.SchemaURN, attr.Name)
+				}
+				continue
+			case models.SCIMMutabilityImmutable:
+				if hasCurrent && hasValue && !reflect.DeepEqual(value, current) {
+					return fmt.Errorf("mutability: %s:%s is immutable", ext.SchemaURN, attr.Name)
+				}
+				if !hasValue {
+					continue
+				}
+			case models.SCIMMutabilityWriteOnly:
+				if !hasValue {
+					continue
+				}
+			}
+
+			if hasValue {
+				metadata[attr.Name] = value
+				carried = true
+			} else {
+				delete(metadata, attr.Name)
+			}
+		}
+
+		if !carried {
+			continue
+		}
+		for _, attr := range ext.Attributes {
+			if _, ok := metadata[attr.Name]; attr.Required && !ok {
+				return fmt.Errorf("invalid value: %s:%s is required", ext.SchemaURN, attr.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// coerceExtensionValue checks a value against an attribute's type and
+// canonical values and returns it in its stored form
+func coerceExtensionValue(attr *models.SCIMSchemaAttribute, value interface{}) (interface{}, error) {
+	if attr.MultiValued {
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		out := make([]interface{}, len(values))
+		for i, v := range values {
+			single := *attr
+			single.MultiValued = false
+			coerced, err := coerceExtensionValue(&single, v)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = coerced
+		}
+		return out, nil
+	}
+
+	switch attr.Type {
+	case models.SCIMTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(v) {
+			case "true":
+				return true, nil
+			case "false":
+				return false, nil
+			}
+		}
+		return nil, fmt.Errorf("must be a boolean")
+	case models.SCIMTypeInteger:
+		if v, ok := value.(float64); ok && v == math.Trunc(v) {
+			return v, nil
+		}
+		return nil, fmt.Errorf("must be an integer")
+	case models.SCIMTypeDecimal:
+		if v, ok := value.(float64); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("must be a number")
+	case models.SCIMTypeDateTime:
+		v, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a dateTime string")
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("must be an RFC 3339 dateTime")
+		}
+		return t.UTC().Format(time.RFC3339), nil
+	}
+
+	// string and reference
+	v, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("must be a string")
+	}
+	if len(attr.CanonicalValues) == 0 {
+		return v, nil
+	}
+	for _, canonical := range attr.CanonicalValues {
+		if v == canonical || (!attr.CaseExact && strings.EqualFold(v, canonical)) {
+			return canonical, nil
+		}
+	}
+	return nil, fmt.Errorf("must be one of %s", strings.Join(attr.CanonicalValues, ", "))
+}
+
+// userPatchAttributes returns the PATCH attribute definitions of the User
+// resource, including the tenant's extension schemas
+func userPatchAttributes(extensions []*models.SCIMSchemaExtension) map[string]*attributeDef {
+	if len(extensions) == 0 {
+		return userAttributes
+	}
+	attrs := make(map[string]*attributeDef, len(userAttributes)+len(extensions))
+	for name, def := range userAttributes {
+		attrs[name] = def
+	}
+	for _, ext := range extensions {
+		def := &attributeDef{name: ext.SchemaURN, sub: make(map[string]*attributeDef, len(ext.Attributes))}
+		for _, attr := range ext.Attributes {
+			def.sub[strings.ToLower(attr.Name)] = &attributeDef{
+				name:        attr.Name,
+				multiValued: attr.MultiValued,
+				boolean:     attr.Type == models.SCIMTypeBoolean,
+				readOnly:    attr.Mutability == models.SCIMMutabilityReadOnly,
+			}
+		}
+		attrs[strings.ToLower(ext.SchemaURN)] = def
+	}
+	return attrs
+}
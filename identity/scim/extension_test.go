@@ -0,0 +1,188 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testExtensionURN = "urn:acme:scim:schemas:extension:hr:1.0:User"
+
+func testExtensions() []*models.SCIMSchemaExtension {
+	return []*models.SCIMSchemaExtension{{
+		SchemaURN: testExtensionURN,
+		Name:      "HR",
+		Attributes: []models.SCIMSchemaAttribute{
+			{Name: "badgeNumber", Type: models.SCIMTypeString, Mutability: models.SCIMMutabilityImmutable},
+			{Name: "clearance", Type: models.SCIMTypeString, Mutability: models.SCIMMutabilityReadWrite, CanonicalValues: []string{"Secret", "TopSecret"}},
+			{Name: "floor", Type: models.SCIMTypeInteger, Mutability: models.SCIMMutabilityReadWrite},
+			{Name: "pin", Type: models.SCIMTypeString, Mutability: models.SCIMMutabilityWriteOnly},
+			{Name: "hrScore", Type: models.SCIMTypeDecimal, Mutability: models.SCIMMutabilityReadOnly},
+		},
+	}}
+}
+
+func TestSCIMUserJSON_Extensions(t *testing.T) {
+	body := `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "bjensen",
+		"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"department": "Sales", "manager": {"value": "m1"}},
+		"` + testExtensionURN + `": {"floor": 3}
+	}`
+	var user models.SCIMUser
+	require.NoError(t, json.Unmarshal([]byte(body), &user))
+	require.NotNil(t, user.EnterpriseUser)
+	assert.Equal(t, "Sales", user.EnterpriseUser.Department)
+	assert.Equal(t, "m1", user.EnterpriseUser.Manager.Value)
+	assert.Equal(t, map[string]map[string]interface{}{testExtensionURN: {"floor": float64(3)}}, user.Extensions)
+
+	raw, err := json.Marshal(user)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &doc))
+	assert.Equal(t, map[string]interface{}{"floor": float64(3)}, doc[testExtensionURN])
+	assert.Equal(t, "Sales", doc[models.SCIMEnterpriseUserSchema].(map[string]interface{})["department"])
+}
+
+func TestEnterpriseMetadata(t *testing.T) {
+	metadata := map[string]interface{}{"other": "kept", models.UserMetadataCostCenter: "old"}
+	applyEnterprise(metadata, &models.SCIMEnterpriseUser{
+		EmployeeNumber: "701984",
+		Department:     "Tour Operations",
+		Manager:        &models.SCIMManager{Value: "26118915-6090-4610-87e4-49d8ca9f808d"},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"other":                           "kept",
+		models.UserMetadataEmployeeNumber: "701984",
+		models.UserMetadataDepartment:     "Tour Operations",
+		models.UserMetadataManagerID:      "26118915-6090-4610-87e4-49d8ca9f808d",
+	}, metadata, "attributes left empty are removed")
+
+	enterprise := enterpriseFromMetadata(metadata)
+	require.NotNil(t, enterprise)
+	assert.Equal(t, "701984", enterprise.EmployeeNumber)
+	assert.Equal(t, "/scim/v2/Users/26118915-6090-4610-87e4-49d8ca9f808d", enterprise.Manager.Ref)
+
+	assert.Nil(t, enterpriseFromMetadata(map[string]interface{}{"other": "kept"}))
+}
+
+func TestApplyExtensions(t *testing.T) {
+	extensions := testExtensions()
+
+	t.Run("coerces and stores values", func(t *testing.T) {
+		metadata := map[string]interface{}{}
+		err := applyExtensions(metadata, map[string]map[string]interface{}{
+			testExtensionURN: {"BadgeNumber": "B-1", "clearance": "secret", "floor": float64(3), "pin": "1234"},
+		}, extensions)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"badgeNumber": "B-1", "clearance": "Secret", "floor": float64(3), "pin": "1234"}, metadata)
+
+		out := extensionsFromMetadata(metadata, extensions)
+		assert.NotContains(t, out[testExtensionURN], "pin", "write-only attributes are never returned")
+	})
+
+	t.Run("replaces read-write values and keeps others", func(t *testing.T) {
+		metadata := map[string]interface{}{"badgeNumber": "B-1", "clearance": "Secret", "pin": "1234", "hrScore": 4.5}
+		err := applyExtensions(metadata, map[string]map[string]interface{}{testExtensionURN: {"floor": float64(2)}}, extensions)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"badgeNumber": "B-1", "floor": float64(2), "pin": "1234", "hrScore": 4.5}, metadata)
+	})
+
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		values   map[string]interface{}
+		wantErr  string
+	}{
+		{"unknown attribute", nil, map[string]interface{}{"desk": "A"}, "invalid value: schema " + testExtensionURN + " has no attribute desk"},
+		{"wrong type", nil, map[string]interface{}{"floor": 2.5}, "must be an integer"},
+		{"non-canonical value", nil, map[string]interface{}{"clearance": "Public"}, "must be one of Secret, TopSecret"},
+		{"read-only attribute", nil, map[string]interface{}{"hrScore": float64(1)}, "is read-only"},
+		{"immutable attribute", map[string]interface{}{"badgeNumber": "B-1"}, map[string]interface{}{"badgeNumber": "B-2"}, "is immutable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := tt.metadata
+			if metadata == nil {
+				metadata = map[string]interface{}{}
+			}
+			err := applyExtensions(metadata, map[string]map[string]interface{}{testExtensionURN: tt.values}, extensions)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("unknown schema", func(t *testing.T) {
+		err := applyExtensions(map[string]interface{}{}, map[string]map[string]interface{}{"urn:acme:other": {}}, extensions)
+		assert.EqualError(t, err, "invalid syntax: unknown schema extension urn:acme:other")
+	})
+
+	t.Run("required attributes and schemas", func(t *testing.T) {
+		required := testExtensions()
+		required[0].Attributes[2].Required = true
+		err := applyExtensions(map[string]interface{}{}, map[string]map[string]interface{}{testExtensionURN: {"clearance": "Secret"}}, required)
+		assert.ErrorContains(t, err, "floor is required")
+
+		err = applyExtensions(map[string]interface{}{}, nil, required)
+		assert.NoError(t, err, "an optional extension the user does not carry is not checked")
+
+		required[0].Required = true
+		err = applyExtensions(map[string]interface{}{}, nil, required)
+		assert.ErrorContains(t, err, "floor is required")
+	})
+}
+
+func TestApplyPatch_Extensions(t *testing.T) {
+	extensions := testExtensions()
+	user := testSCIMUser()
+	user.Extensions = map[string]map[string]interface{}{testExtensionURN: {"floor": float64(3)}}
+
+	var patched models.SCIMUser
+	err := applyPatch(user, userPatchAttributes(extensions), []PatchOperation{
+		{Op: "add", Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", Value: "Sales"},
+		{Op: "replace", Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager", Value: "m1"},
+		{Op: "replace", Path: testExtensionURN + ":clearance", Value: "Secret"},
+		{Op: "remove", Path: testExtensionURN + ":floor"},
+	}, &patched)
+	require.NoError(t, err)
+	require.NotNil(t, patched.EnterpriseUser)
+	assert.Equal(t, "Sales", patched.EnterpriseUser.Department)
+	assert.Equal(t, "m1", patched.EnterpriseUser.Manager.Value, "a bare manager id is taken as its value")
+	assert.Equal(t, map[string]map[string]interface{}{testExtensionURN: {"clearance": "Secret"}}, patched.Extensions)
+
+	patched = models.SCIMUser{}
+	err = applyPatch(user, userPatchAttributes(extensions), []PatchOperation{
+		{Op: "replace", Value: map[string]interface{}{
+			"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]interface{}{"costCenter": "4130"},
+		}},
+	}, &patched)
+	require.NoError(t, err)
+	assert.Equal(t, "4130", patched.EnterpriseUser.CostCenter)
+
+	err = applyPatch(user, userPatchAttributes(extensions), []PatchOperation{
+		{Op: "replace", Path: "urn:acme:unknown:1.0:User:floor", Value: float64(1)},
+	}, &patched)
+	assert.Error(t, err)
+
+	err = applyPatch(user, userPatchAttributes(extensions), []PatchOperation{
+		{Op: "replace", Path: testExtensionURN + ":hrScore", Value: float64(1)},
+	}, &patched)
+	assert.Error(t, err, "read-only extension attributes cannot be patched")
+}
+
+func TestProject_Extensions(t *testing.T) {
+	user := testSCIMUser()
+	user.EnterpriseUser = &models.SCIMEnterpriseUser{Department: "Sales", CostCenter: "4130"}
+
+	projected, err := Project(user, []string{"userName", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department"}, nil)
+	require.NoError(t, err)
+	doc := projected.(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"department": "Sales"}, doc[models.SCIMEnterpriseUserSchema])
+
+	projected, err = Project(user, nil, []string{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"})
+	require.NoError(t, err)
+	assert.NotContains(t, projected.(map[string]interface{}), models.SCIMEnterpriseUserSchema)
+}
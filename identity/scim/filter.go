@@ -0,0 +1,708 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/storage/interfaces"
+)
+
+// SCIM filters (RFC 7644 section 3.4.2.2) are parsed into a filterNode tree.
+// List requests translate the tree into a repository FilterExpr; PATCH
+// requests match it in memory against the elements of a multi-valued attribute.
+
+const (
+	userSchemaPrefix  = "urn:ietf:params:scim:schemas:core:2.0:User:"
+	groupSchemaPrefix = "urn:ietf:params:scim:schemas:core:2.0:Group:"
+)
+
+// Filter node operators besides the comparison operators
+const (
+	filterAnd       = "and"
+	filterOr        = "or"
+	filterNot       = "not"
+	filterValuePath = "valuePath"
+)
+
+// comparisonOperators are the attribute operators of the filter grammar
+var comparisonOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true, "pr": true,
+}
+
+// filterNode is a parsed SCIM filter
+type filterNode struct {
+	op       string        // and, or, not, valuePath or a comparison operator
+	attr     string        // Lower-cased attribute path without schema URN
+	value    interface{}   // string, float64, bool or nil
+	children []*filterNode // Operands of and/or/not; the element filter of a valuePath
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lexFilter splits a filter or path into tokens
+func lexFilter(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(input); {
+		ch := input[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '(':
+			tokens = append(tokens, filterToken{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, filterToken{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case ch == '[':
+			tokens = append(tokens, filterToken{kind: tokenLBracket, text: "[", pos: i})
+			i++
+		case ch == ']':
+			tokens = append(tokens, filterToken{kind: tokenRBracket, text: "]", pos: i})
+			i++
+		case ch == '"':
+			end := i + 1
+			for end < len(input) && input[end] != '"' {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			var value string
+			if err := json.Unmarshal([]byte(input[i:end+1]), &value); err != nil {
+				return nil, fmt.Errorf("invalid string at position %d", i)
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: value, pos: i})
+			i = end + 1
+		default:
+			end := i
+			for end < len(input) && !strings.ContainsRune(" \t\n\r()[]\"", rune(input[end])) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, text: input[i:end], pos: i})
+			i = end
+		}
+	}
+	return append(tokens, filterToken{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// filterParser is a recursive-descent parser over lexed tokens
+type filterParser struct {
+	tokens      []filterToken
+	pos         int
+	inValuePath bool
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenWord && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *filterParser) expect(kind tokenKind, what string) error {
+	if tok := p.next(); tok.kind != kind {
+		return p.unexpected(tok, what)
+	}
+	return nil
+}
+
+func (p *filterParser) unexpected(tok filterToken, want string) error {
+	if tok.kind == tokenEOF {
+		return fmt.Errorf("expected %s at end of input", want)
+	}
+	return fmt.Errorf("expected %s at position %d, got %q", want, tok.pos, tok.text)
+}
+
+// parseFilter parses a SCIM filter expression
+func parseFilter(input string) (*filterNode, error) {
+	tokens, err := lexFilter(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %v", err)
+	}
+	p := &filterParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = p.unexpected(p.peek(), "and, or or end of filter")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %v", err)
+	}
+	return node, nil
+}
+
+func (p *filterParser) parseOr() (*filterNode, error) {
+	return p.parseJunction(filterOr, p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (*filterNode, error) {
+	return p.parseJunction(filterAnd, p.parseUnary)
+}
+
+// parseJunction parses operands separated by keyword, flattening them into one node
+func (p *filterParser) parseJunction(keyword string, operand func() (*filterNode, error)) (*filterNode, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peekKeyword(keyword) {
+		return first, nil
+	}
+	node := &filterNode{op: keyword, children: []*filterNode{first}}
+	for p.peekKeyword(keyword) {
+		p.next()
+		child, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, child)
+	}
+	return node, nil
+}
+
+func (p *filterParser) parseUnary() (*filterNode, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return node, p.expect(tokenRParen, ")")
+	case tok.kind == tokenWord && strings.EqualFold(tok.text, filterNot) && p.peek().kind == tokenLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return &filterNode{op: filterNot, children: []*filterNode{inner}}, nil
+	case tok.kind == tokenWord:
+		return p.parseAttributeExpression(tok)
+	}
+	return nil, p.unexpected(tok, "attribute")
+}
+
+// parseAttributeExpression parses "attr op value", "attr pr" or "attr[filter]",
+// optionally followed by ".subAttr op value"
+func (p *filterParser) parseAttributeExpression(tok filterToken) (*filterNode, error) {
+	attr, err := normalizeAttribute(tok.text)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenLBracket {
+		return p.parseComparison(attr)
+	}
+
+	if p.inValuePath {
+		return nil, fmt.Errorf("nested value filters are not supported at position %d", p.peek().pos)
+	}
+	p.next()
+	p.inValuePath = true
+	inner, err := p.parseOr()
+	p.inValuePath = false
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenRBracket, "]"); err != nil {
+		return nil, err
+	}
+	node := &filterNode{op: filterValuePath, attr: attr, children: []*filterNode{inner}}
+
+	// emails[type eq "work"].value co "@example.com" compares a sub-attribute
+	// of the matching elements
+	if sub := p.peek(); sub.kind == tokenWord && strings.HasPrefix(sub.text, ".") {
+		p.next()
+		comparison, err := p.parseComparison(strings.ToLower(strings.TrimPrefix(sub.text, ".")))
+		if err != nil {
+			return nil, err
+		}
+		node.children = []*filterNode{{op: filterAnd, children: []*filterNode{inner, comparison}}}
+	}
+	return node, nil
+}
+
+func (p *filterParser) parseComparison(attr string) (*filterNode, error) {
+	tok := p.next()
+	op := strings.ToLower(tok.text)
+	if tok.kind != tokenWord || !comparisonOperators[op] {
+		return nil, p.unexpected(tok, "operator")
+	}
+	if op == "pr" {
+		return &filterNode{op: op, attr: attr}, nil
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return &filterNode{op: op, attr: attr, value: value}, nil
+}
+
+func (p *filterParser) parseValue() (interface{}, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return tok.text, nil
+	case tokenWord:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		if n, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			return n, nil
+		}
+	}
+	return nil, p.unexpected(tok, "value")
+}
+
+// normalizeAttribute strips a core schema URN and lower-cases an attribute path
+func normalizeAttribute(attr string) (string, error) {
+	for _, prefix := range []string{userSchemaPrefix, groupSchemaPrefix} {
+		if len(attr) > len(prefix) && strings.EqualFold(attr[:len(prefix)], prefix) {
+			attr = attr[len(prefix):]
+			break
+		}
+	}
+	if attr == "" || strings.HasPrefix(attr, ".") || strings.HasSuffix(attr, ".") {
+		return "", fmt.Errorf("invalid attribute %q", attr)
+	}
+	return strings.ToLower(attr), nil
+}
+
+// splitExtensionURN splits a path qualified by an extension schema URN into
+// the lower-cased URN and the attribute path after it. The URN ends at the
+// last colon; core schema URNs are left to normalizeAttribute.
+func splitExtensionURN(attr string) (string, string, bool) {
+	lower := strings.ToLower(attr)
+	if !strings.HasPrefix(lower, "urn:") ||
+		strings.HasPrefix(lower, strings.ToLower(userSchemaPrefix)) ||
+		strings.HasPrefix(lower, strings.ToLower(groupSchemaPrefix)) {
+		return "", "", false
+	}
+	i := strings.LastIndex(attr, ":")
+	return lower[:i], attr[i+1:], true
+}
+
+// matches reports whether element, a value of a multi-valued attribute,
+// satisfies the filter. Attribute paths are relative to the element.
+func (n *filterNode) matches(element interface{}) bool {
+	switch n.op {
+	case filterAnd:
+		for _, child := range n.children {
+			if !child.matches(element) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, child := range n.children {
+			if child.matches(element) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return !n.children[0].matches(element)
+	case filterValuePath:
+		values, _ := lookupAttribute(element, n.attr).([]interface{})
+		for _, v := range values {
+			if n.children[0].matches(v) {
+				return true
+			}
+		}
+		return false
+	}
+	return compareFilterValue(lookupAttribute(element, n.attr), n.op, n.value)
+}
+
+// lookupAttribute follows a dotted attribute path through decoded JSON,
+// matching keys case-insensitively
+func lookupAttribute(value interface{}, path string) interface{} {
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = nil
+		for key, v := range object {
+			if strings.EqualFold(key, name) {
+				value = v
+				break
+			}
+		}
+	}
+	return value
+}
+
+// compareFilterValue applies a comparison operator to a decoded JSON value.
+// Strings compare case-insensitively; a multi-valued actual matches when any
+// of its values does.
+func compareFilterValue(actual interface{}, op string, expected interface{}) bool {
+	if op == "pr" {
+		return isPresent(actual)
+	}
+	if values, ok := actual.([]interface{}); ok {
+		for _, v := range values {
+			if compareFilterValue(v, op, expected) {
+				return true
+			}
+		}
+		return op == "ne" && expected != nil && len(values) == 0
+	}
+	if expected == nil {
+		switch op {
+		case "eq":
+			return !isPresent(actual)
+		case "ne":
+			return isPresent(actual)
+		}
+		return false
+	}
+
+	var cmp int
+	switch want := expected.(type) {
+	case string:
+		got, ok := actual.(string)
+		if !ok {
+			return op == "ne"
+		}
+		got, want = strings.ToLower(got), strings.ToLower(want)
+		switch op {
+		case "co":
+			return strings.Contains(got, want)
+		case "sw":
+			return strings.HasPrefix(got, want)
+		case "ew":
+			return strings.HasSuffix(got, want)
+		}
+		cmp = strings.Compare(got, want)
+	case bool:
+		got, ok := actual.(bool)
+		switch op {
+		case "eq":
+			return ok && got == want
+		case "ne":
+			return !ok || got != want
+		}
+		return false
+	case float64:
+		got, ok := actual.(float64)
+		if !ok {
+			return op == "ne"
+		}
+		switch {
+		case got < want:
+			cmp = -1
+		case got > want:
+			cmp = 1
+		}
+	default:
+		return false
+	}
+
+	switch op {
+	case "eq":
+		return cmp == 0
+	case "ne":
+		return cmp != 0
+	case "gt":
+		return cmp > 0
+	case "ge":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "le":
+		return cmp <= 0
+	}
+	return false
+}
+
+func isPresent(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// filterAttributes maps the SCIM attributes of a resource type onto
+// repository filter and sort fields
+type filterAttributes struct {
+	// fields are attributes stored in a repository column; all are sortable
+	fields map[string]string
+	// times are the fields holding date-times
+	times map[string]bool
+	// constants are attributes with the same value on every resource
+	constants map[string]interface{}
+	// special translates attributes that need more than a column comparison
+	special map[string]func(n *filterNode) (*interfaces.FilterExpr, error)
+}
+
+// userFilterAttributes covers the User attributes the repository stores.
+// A user has a single email, returned as the primary work address.
+var userFilterAttributes = &filterAttributes{
+	fields: map[string]string{
+		"id":                interfaces.UserFieldID,
+		"username":          interfaces.UserFieldUsername,
+		"emails":            interfaces.UserFieldEmail,
+		"emails.value":      interfaces.UserFieldEmail,
+		"name.givenname":    interfaces.UserFieldFirstName,
+		"name.familyname":   interfaces.UserFieldLastName,
+		"name.formatted":    interfaces.UserFieldDisplayName,
+		"displayname":       interfaces.UserFieldDisplayName,
+		"meta.created":      interfaces.UserFieldCreatedAt,
+		"meta.lastmodified": interfaces.UserFieldUpdatedAt,
+	},
+	times: map[string]bool{
+		interfaces.UserFieldCreatedAt: true,
+		interfaces.UserFieldUpdatedAt: true,
+	},
+	constants: map[string]interface{}{
+		"emails.type":    "work",
+		"emails.primary": true,
+	},
+	special: map[string]func(n *filterNode) (*interfaces.FilterExpr, error){
+		"active": translateActive,
+	},
+}
+
+// groupFilterAttributes covers the Group attributes the repository stores
+var groupFilterAttributes = &filterAttributes{
+	fields: map[string]string{
+		"id":                interfaces.GroupFieldID,
+		"displayname":       interfaces.GroupFieldName,
+		"externalid":        interfaces.GroupFieldExternalID,
+		"meta.created":      interfaces.GroupFieldCreatedAt,
+		"meta.lastmodified": interfaces.GroupFieldUpdatedAt,
+	},
+	times: map[string]bool{
+		interfaces.GroupFieldCreatedAt: true,
+		interfaces.GroupFieldUpdatedAt: true,
+	},
+	special: map[string]func(n *filterNode) (*interfaces.FilterExpr, error){
+		"members":       translateMember,
+		"members.value": translateMember,
+	},
+}
+
+// parse parses a SCIM filter and translates it into a repository filter
+func (a *filterAttributes) parse(filter string) (*interfaces.FilterExpr, error) {
+	node, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return a.translate(node, "")
+}
+
+// translate converts a filter node; prefix is the valuePath attribute when
+// translating the element filter of a valuePath
+func (a *filterAttributes) translate(n *filterNode, prefix string) (*interfaces.FilterExpr, error) {
+	switch n.op {
+	case filterAnd, filterOr, filterNot:
+		expr := &interfaces.FilterExpr{Op: interfaces.FilterOp(n.op)}
+		for _, child := range n.children {
+			translated, err := a.translate(child, prefix)
+			if err != nil {
+				return nil, err
+			}
+			expr.Children = append(expr.Children, translated)
+		}
+		return expr, nil
+	case filterValuePath:
+		return a.translate(n.children[0], n.attr+".")
+	}
+
+	attr := prefix + n.attr
+	comparison := *n
+	comparison.attr = attr
+	if n.value == nil && n.op != "pr" && n.op != "eq" && n.op != "ne" {
+		return nil, fmt.Errorf("invalid filter: %s %s needs a value", attr, n.op)
+	}
+
+	if translate, ok := a.special[attr]; ok {
+		return translate(&comparison)
+	}
+	if value, ok := a.constants[attr]; ok {
+		if compareFilterValue(value, n.op, n.value) {
+			return &interfaces.FilterExpr{Op: interfaces.FilterTrue}, nil
+		}
+		return &interfaces.FilterExpr{Op: interfaces.FilterFalse}, nil
+	}
+
+	field, ok := a.fields[attr]
+	if !ok {
+		return nil, fmt.Errorf("invalid filter: attribute %s is not supported", attr)
+	}
+	if n.value != nil {
+		value, ok := n.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid filter: %s must be compared with a string", attr)
+		}
+		if a.times[field] {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return nil, fmt.Errorf("invalid filter: %s must be compared with an RFC 3339 date-time", attr)
+			}
+			if n.op == "co" || n.op == "sw" || n.op == "ew" {
+				return nil, fmt.Errorf("invalid filter: operator %s is not supported for %s", n.op, attr)
+			}
+		}
+	}
+	return &interfaces.FilterExpr{Op: interfaces.FilterOp(n.op), Field: field, Value: n.value}, nil
+}
+
+// sortField returns the repository field for a sortBy attribute
+func (a *filterAttributes) sortField(sortBy string) (string, error) {
+	attr, err := normalizeAttribute(sortBy)
+	if err != nil {
+		return "", fmt.Errorf("invalid sortBy: %v", err)
+	}
+	field, ok := a.fields[attr]
+	if !ok {
+		return "", fmt.Errorf("invalid sortBy: cannot sort by %s", sortBy)
+	}
+	return field, nil
+}
+
+// translateActive maps the active attribute onto the user status
+func translateActive(n *filterNode) (*interfaces.FilterExpr, error) {
+	if n.op == "pr" {
+		return &interfaces.FilterExpr{Op: interfaces.FilterTrue}, nil
+	}
+	active, ok := n.value.(bool)
+	if !ok || (n.op != "eq" && n.op != "ne") {
+		return nil, fmt.Errorf("invalid filter: active only supports eq and ne with true or false")
+	}
+	op := interfaces.FilterEqual
+	if active != (n.op == "eq") {
+		op = interfaces.FilterNotEqual
+	}
+	return &interfaces.FilterExpr{Op: op, Field: interfaces.UserFieldStatus, Value: "active"}, nil
+}
+
+// translateMember maps members and members.value onto group membership
+func translateMember(n *filterNode) (*interfaces.FilterExpr, error) {
+	if _, ok := n.value.(string); !ok || (n.op != "eq" && n.op != "ne") {
+		return nil, fmt.Errorf("invalid filter: %s only supports eq and ne with a member ID", n.attr)
+	}
+	return &interfaces.FilterExpr{Op: interfaces.FilterOp(n.op), Field: interfaces.GroupFieldMember, Value: n.value}, nil
+}
+
+// parseSortOrder reports whether a SCIM sortOrder is descending
+func parseSortOrder(sortOrder string) (bool, error) {
+	switch strings.ToLower(sortOrder) {
+	case "", "ascending":
+		return false, nil
+	case "descending":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid sortOrder: %s", sortOrder)
+}
+
+// patchPath is a parsed PATCH path: attr, attr.sub, attr[filter] or attr[filter].sub,
+// optionally qualified by an extension schema URN
+type patchPath struct {
+	schema string // Lower-cased extension schema URN, if any
+	attr   string
+	filter *filterNode
+	sub    string
+}
+
+// parsePatchPath parses the path of a PATCH operation (RFC 7644 section 3.5.2)
+func parsePatchPath(input string) (*patchPath, error) {
+	tokens, err := lexFilter(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %v", err)
+	}
+	p := &filterParser{tokens: tokens}
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %v", err)
+	}
+	return path, nil
+}
+
+func (p *filterParser) parsePath() (*patchPath, error) {
+	tok := p.next()
+	if tok.kind != tokenWord {
+		return nil, p.unexpected(tok, "attribute")
+	}
+	text := tok.text
+	schema, rest, ok := splitExtensionURN(text)
+	if ok {
+		text = rest
+	}
+	attr, err := normalizeAttribute(text)
+	if err != nil {
+		return nil, err
+	}
+	path := &patchPath{schema: schema, attr: attr}
+
+	if p.peek().kind == tokenLBracket {
+		p.next()
+		p.inValuePath = true
+		if path.filter, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRBracket, "]"); err != nil {
+			return nil, err
+		}
+		if sub := p.peek(); sub.kind == tokenWord && strings.HasPrefix(sub.text, ".") {
+			p.next()
+			path.sub = strings.ToLower(strings.TrimPrefix(sub.text, "."))
+		}
+	} else if i := strings.Index(attr, "."); i > 0 {
+		path.attr, path.sub = attr[:i], attr[i+1:]
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok, "end of path")
+	}
+	if strings.Contains(path.attr, ".") || strings.Contains(path.sub, ".") {
+		return nil, fmt.Errorf("attribute %q is nested too deeply", tok.text)
+	}
+	return path, nil
+}
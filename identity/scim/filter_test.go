@@ -0,0 +1,175 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		check  func(t *testing.T, n *filterNode)
+	}{
+		{"simple", `userName eq "bjensen"`, func(t *testing.T, n *filterNode) {
+			assert.Equal(t, &filterNode{op: "eq", attr: "username", value: "bjensen"}, n)
+		}},
+		{"case-insensitive operator and schema URN", `urn:ietf:params:scim:schemas:core:2.0:User:name.familyName CO "O'Malley"`, func(t *testing.T, n *filterNode) {
+			assert.Equal(t, &filterNode{op: "co", attr: "name.familyname", value: "O'Malley"}, n)
+		}},
+		{"precedence", `title pr and userType eq "Employee" or active eq false`, func(t *testing.T, n *filterNode) {
+			require.Equal(t, "or", n.op)
+			require.Len(t, n.children, 2)
+			assert.Equal(t, "and", n.children[0].op)
+			assert.Equal(t, false, n.children[1].value)
+		}},
+		{"not and grouping", `not (emails co "example.com" or emails.value co "example.org")`, func(t *testing.T, n *filterNode) {
+			require.Equal(t, "not", n.op)
+			assert.Equal(t, "or", n.children[0].op)
+		}},
+		{"value path", `emails[type eq "work" and value co "@example.com"]`, func(t *testing.T, n *filterNode) {
+			require.Equal(t, filterValuePath, n.op)
+			assert.Equal(t, "emails", n.attr)
+			assert.Equal(t, "and", n.children[0].op)
+		}},
+		{"value path with sub-attribute", `emails[type eq "work"].value co "@acme"`, func(t *testing.T, n *filterNode) {
+			require.Equal(t, filterValuePath, n.op)
+			inner := n.children[0]
+			require.Equal(t, "and", inner.op)
+			assert.Equal(t, &filterNode{op: "co", attr: "value", value: "@acme"}, inner.children[1])
+		}},
+		{"escaped string, number and null", `displayName eq "a \"b\"" and x gt 10 and y eq null`, func(t *testing.T, n *filterNode) {
+			assert.Equal(t, `a "b"`, n.children[0].value)
+			assert.Equal(t, float64(10), n.children[1].value)
+			assert.Nil(t, n.children[2].value)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := parseFilter(tt.filter)
+			require.NoError(t, err)
+			tt.check(t, n)
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, filter := range []string{
+		`userName`,
+		`userName eq`,
+		`userName like "x"`,
+		`userName eq "x`,
+		`(userName eq "x"`,
+		`userName eq "x" extra`,
+		`emails[value eq "x"`,
+		`emails[members[value eq "x"]]`,
+		`userName eq bjensen`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := parseFilter(filter)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid filter")
+		})
+	}
+}
+
+func TestFilterNode_Matches(t *testing.T) {
+	element := map[string]interface{}{"value": "Alice@Example.com", "type": "work", "primary": true}
+
+	for filter, want := range map[string]bool{
+		`type eq "WORK"`: true,
+		`value ew "example.com" and primary eq true`: true,
+		`type eq "home" or value sw "bob"`:           false,
+		`not (type eq "home")`:                       true,
+		`display pr`:                                 false,
+		`display eq null`:                            true,
+	} {
+		n, err := parseFilter(filter)
+		require.NoError(t, err)
+		assert.Equal(t, want, n.matches(element), filter)
+	}
+}
+
+func TestUserFilterAttributes_Parse(t *testing.T) {
+	expr, err := userFilterAttributes.parse(`emails[type eq "work"].value co "@acme" and active eq false`)
+	require.NoError(t, err)
+	assert.Equal(t, &interfaces.FilterExpr{Op: interfaces.FilterAnd, Children: []*interfaces.FilterExpr{
+		{Op: interfaces.FilterAnd, Children: []*interfaces.FilterExpr{
+			{Op: interfaces.FilterTrue},
+			{Op: interfaces.FilterContains, Field: interfaces.UserFieldEmail, Value: "@acme"},
+		}},
+		{Op: interfaces.FilterNotEqual, Field: interfaces.UserFieldStatus, Value: "active"},
+	}}, expr)
+
+	expr, err = userFilterAttributes.parse(`emails[type eq "home"]`)
+	require.NoError(t, err)
+	assert.Equal(t, &interfaces.FilterExpr{Op: interfaces.FilterFalse}, expr)
+
+	expr, err = userFilterAttributes.parse(`meta.lastModified gt "2024-01-01T00:00:00Z"`)
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.UserFieldUpdatedAt, expr.Field)
+
+	for _, filter := range []string{
+		`externalId eq "x"`,
+		`userName eq 5`,
+		`active eq "yes"`,
+		`meta.created gt "yesterday"`,
+		`userName co null`,
+	} {
+		_, err := userFilterAttributes.parse(filter)
+		require.Error(t, err, filter)
+		assert.Contains(t, err.Error(), "invalid filter", filter)
+	}
+}
+
+func TestGroupFilterAttributes_Parse(t *testing.T) {
+	expr, err := groupFilterAttributes.parse(`displayName eq "Admins" or members[value eq "42"]`)
+	require.NoError(t, err)
+	assert.Equal(t, &interfaces.FilterExpr{Op: interfaces.FilterOr, Children: []*interfaces.FilterExpr{
+		{Op: interfaces.FilterEqual, Field: interfaces.GroupFieldName, Value: "Admins"},
+		{Op: interfaces.FilterEqual, Field: interfaces.GroupFieldMember, Value: "42"},
+	}}, expr)
+
+	_, err = groupFilterAttributes.parse(`members co "4"`)
+	assert.Error(t, err)
+}
+
+func TestFilterAttributes_SortField(t *testing.T) {
+	field, err := userFilterAttributes.sortField("name.familyName")
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.UserFieldLastName, field)
+
+	_, err = groupFilterAttributes.sortField("members")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid sortBy")
+
+	descending, err := parseSortOrder("Descending")
+	require.NoError(t, err)
+	assert.True(t, descending)
+	_, err = parseSortOrder("sideways")
+	assert.Error(t, err)
+}
+
+func TestParsePatchPath(t *testing.T) {
+	path, err := parsePatchPath("name.givenName")
+	require.NoError(t, err)
+	assert.Equal(t, &patchPath{attr: "name", sub: "givenname"}, path)
+
+	path, err = parsePatchPath(`members[value eq "2819c223"]`)
+	require.NoError(t, err)
+	assert.Equal(t, "members", path.attr)
+	assert.NotNil(t, path.filter)
+
+	path, err = parsePatchPath(`emails[type eq "work"].value`)
+	require.NoError(t, err)
+	assert.Equal(t, "value", path.sub)
+
+	for _, input := range []string{"", "name.givenName.x", `emails[type eq "work"] x`} {
+		_, err := parsePatchPath(input)
+		require.Error(t, err, input)
+		assert.Contains(t, err.Error(), "invalid path")
+	}
+}
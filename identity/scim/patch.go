@@ -0,0 +1,472 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// PATCH operations (RFC 7644 section 3.5.2) are applied to the JSON form of
+// the current resource, which is then decoded back into the resource type.
+// Errors start with the SCIM error type they map to: "invalid path",
+// "no target", "invalid value", "invalid syntax" or "mutability".
+// Attributes of schema extensions are addressed by the schema URN, as in
+// "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department".
+
+// attributeDef describes a resource attribute, derived from the model's JSON tags
+type attributeDef struct {
+	name        string
+	multiValued bool
+	boolean     bool
+	readOnly    bool
+	sub         map[string]*attributeDef // Sub-attributes of a complex attribute, by lower-cased name
+}
+
+var (
+	userAttributes  = attributesOf(reflect.TypeOf(models.SCIMUser{}))
+	groupAttributes = attributesOf(reflect.TypeOf(models.SCIMGroup{}))
+)
+
+// attributesOf describes the JSON fields of a model struct
+func attributesOf(t reflect.Type) map[string]*attributeDef {
+	defs := make(map[string]*attributeDef)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		def := &attributeDef{name: name, readOnly: name == "id" || name == "meta"}
+		ft := field.Type
+		if ft.Kind() == reflect.Slice {
+			def.multiValued = true
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Bool:
+			def.boolean = true
+		case ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}):
+			def.sub = attributesOf(ft)
+		}
+		defs[strings.ToLower(name)] = def
+	}
+	return defs
+}
+
+// applyPatch applies operations to resource and decodes the result into out
+func applyPatch(resource interface{}, attrs map[string]*attributeDef, operations []PatchOperation, out interface{}) error {
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("failed to encode resource: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("failed to decode resource: %w", err)
+	}
+
+	for i, op := range operations {
+		if err := applyOperation(doc, attrs, op); err != nil {
+			return fmt.Errorf("%w (operation %d)", err, i+1)
+		}
+	}
+
+	if raw, err = json.Marshal(doc); err != nil {
+		return fmt.Errorf("failed to encode patched resource: %w", err)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("invalid value: %v", err)
+	}
+	return nil
+}
+
+func applyOperation(doc map[string]interface{}, attrs map[string]*attributeDef, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "replace" && kind != "remove" {
+		return fmt.Errorf("invalid syntax: unsupported op %q", op.Op)
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return fmt.Errorf("no target: remove requires a path")
+		}
+		// Without a path the value holds attributes to add or replace; Entra ID
+		// also sends dotted paths such as "name.givenName" as keys
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid value: %s without a path requires an object", kind)
+		}
+		for key, value := range values {
+			path, err := parsePatchPath(key)
+			if err != nil {
+				return err
+			}
+			if err := applyAtPath(doc, attrs, kind, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	return applyAtPath(doc, attrs, kind, path, op.Value)
+}
+
+func applyAtPath(doc map[string]interface{}, attrs map[string]*attributeDef, kind string, path *patchPath, value interface{}) error {
+	if path.schema != "" {
+		return applyInExtension(doc, attrs, kind, path, value)
+	}
+
+	def, ok := attrs[path.attr]
+	if !ok {
+		return fmt.Errorf("invalid path: unknown attribute %s", path.attr)
+	}
+	var subDef *attributeDef
+	if path.sub != "" {
+		if subDef, ok = def.sub[path.sub]; !ok {
+			return fmt.Errorf("invalid path: unknown attribute %s.%s", def.name, path.sub)
+		}
+	}
+	if path.filter != nil && (!def.multiValued || def.sub == nil) {
+		return fmt.Errorf("invalid path: %s is not a multi-valued complex attribute", def.name)
+	}
+
+	if kind != "remove" {
+		target := def
+		if subDef != nil {
+			target = subDef
+		}
+		var err error
+		if value, err = coerceValue(target, value, path.filter != nil || subDef != nil); err != nil {
+			return err
+		}
+	}
+
+	if def.readOnly {
+		if kind != "remove" && subDef == nil && path.filter == nil && reflect.DeepEqual(doc[def.name], value) {
+			return nil
+		}
+		return fmt.Errorf("mutability: %s is read-only", def.name)
+	}
+
+	switch {
+	case path.filter != nil:
+		return applyToMatches(doc, def, kind, path, value)
+	case subDef != nil && def.multiValued:
+		return applyToAllElements(doc, def, subDef, kind, value)
+	case subDef != nil:
+		parent, _ := doc[def.name].(map[string]interface{})
+		if parent == nil {
+			if kind == "remove" {
+				return nil
+			}
+			parent = make(map[string]interface{})
+			doc[def.name] = parent
+		}
+		if kind == "remove" {
+			delete(parent, subDef.name)
+		} else {
+			parent[subDef.name] = value
+		}
+		return nil
+	}
+
+	switch kind {
+	case "remove":
+		if def.multiValued && value != nil {
+			return removeElements(doc, def, value)
+		}
+		delete(doc, def.name)
+	case "add":
+		if def.multiValued {
+			existing, _ := doc[def.name].([]interface{})
+			for _, v := range value.([]interface{}) {
+				if !containsElement(existing, v) {
+					existing = append(existing, v)
+				}
+			}
+			doc[def.name] = existing
+			return nil
+		}
+		if def.sub != nil {
+			mergeInto(doc, def.name, value.(map[string]interface{}))
+			return nil
+		}
+		doc[def.name] = value
+	case "replace":
+		if def.sub != nil && !def.multiValued {
+			// Sub-attributes not in the value are left unchanged
+			mergeInto(doc, def.name, value.(map[string]interface{}))
+			return nil
+		}
+		doc[def.name] = value
+	}
+	return nil
+}
+
+// applyInExtension applies an operation to an attribute of a schema extension,
+// which is held in the resource as an object named by the schema URN
+func applyInExtension(doc map[string]interface{}, attrs map[string]*attributeDef, kind string, path *patchPath, value interface{}) error {
+	// A path naming just the extension, e.g. "urn:...:enterprise:2.0:User",
+	// parses as attribute "user" of schema "urn:...:enterprise:2.0"
+	if _, ok := attrs[path.schema+":"+path.attr]; ok && path.filter == nil && path.sub == "" {
+		return applyAtPath(doc, attrs, kind, &patchPath{attr: path.schema + ":" + path.attr}, value)
+	}
+
+	ext, ok := attrs[path.schema]
+	if !ok || ext.sub == nil {
+		return fmt.Errorf("invalid path: unknown schema %s", path.schema)
+	}
+	object, _ := doc[ext.name].(map[string]interface{})
+	if object == nil {
+		if kind == "remove" {
+			return nil
+		}
+		object = make(map[string]interface{})
+	}
+
+	inner := &patchPath{attr: path.attr, filter: path.filter, sub: path.sub}
+	if err := applyAtPath(object, ext.sub, kind, inner, value); err != nil {
+		return err
+	}
+	if len(object) == 0 {
+		delete(doc, ext.name)
+	} else {
+		doc[ext.name] = object
+	}
+	return nil
+}
+
+// applyToMatches applies an operation to the elements selected by a value filter
+func applyToMatches(doc map[string]interface{}, def *attributeDef, kind string, path *patchPath, value interface{}) error {
+	elements, _ := doc[def.name].([]interface{})
+	var matched []int
+	for i, element := range elements {
+		if path.filter.matches(element) {
+			matched = append(matched, i)
+		}
+	}
+
+	if len(matched) == 0 {
+		if kind == "remove" {
+			return nil
+		}
+		// An add or replace on a simple "attr eq value" selector creates the
+		// element, which is what Entra ID expects for emails[type eq "work"]
+		element, ok := newElementFor(def, path.filter)
+		if !ok {
+			return fmt.Errorf("no target: no %s value matches the filter", def.name)
+		}
+		if path.sub != "" {
+			element[def.sub[path.sub].name] = value
+		} else {
+			for k, v := range value.(map[string]interface{}) {
+				element[k] = v
+			}
+		}
+		doc[def.name] = append(elements, element)
+		return nil
+	}
+
+	if kind == "remove" && path.sub == "" {
+		kept := make([]interface{}, 0, len(elements))
+		for i, element := range elements {
+			if !containsIndex(matched, i) {
+				kept = append(kept, element)
+			}
+		}
+		doc[def.name] = kept
+		return nil
+	}
+
+	for _, i := range matched {
+		element, ok := elements[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch {
+		case path.sub != "" && kind == "remove":
+			delete(element, def.sub[path.sub].name)
+		case path.sub != "":
+			element[def.sub[path.sub].name] = value
+		case kind == "replace":
+			elements[i] = value
+		default:
+			for k, v := range value.(map[string]interface{}) {
+				element[k] = v
+			}
+		}
+	}
+	return nil
+}
+
+// applyToAllElements applies an operation to a sub-attribute of every element
+// of a multi-valued attribute, creating one element when there are none
+func applyToAllElements(doc map[string]interface{}, def, subDef *attributeDef, kind string, value interface{}) error {
+	elements, _ := doc[def.name].([]interface{})
+	if len(elements) == 0 {
+		if kind != "remove" {
+			doc[def.name] = []interface{}{map[string]interface{}{subDef.name: value}}
+		}
+		return nil
+	}
+	for _, e := range elements {
+		if element, ok := e.(map[string]interface{}); ok {
+			if kind == "remove" {
+				delete(element, subDef.name)
+			} else {
+				element[subDef.name] = value
+			}
+		}
+	}
+	return nil
+}
+
+// removeElements removes the given values from a multi-valued attribute.
+// Complex values match on their "value" sub-attribute when they have one.
+func removeElements(doc map[string]interface{}, def *attributeDef, value interface{}) error {
+	remove, ok := value.([]interface{})
+	if !ok {
+		remove = []interface{}{value}
+	}
+	elements, _ := doc[def.name].([]interface{})
+	kept := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		if !containsElement(remove, element) {
+			kept = append(kept, element)
+		}
+	}
+	doc[def.name] = kept
+	return nil
+}
+
+// newElementFor builds the element a simple "attr eq value" filter selects
+func newElementFor(def *attributeDef, filter *filterNode) (map[string]interface{}, bool) {
+	subDef, ok := def.sub[filter.attr]
+	if filter.op != "eq" || filter.value == nil || !ok {
+		return nil, false
+	}
+	return map[string]interface{}{subDef.name: filter.value}, true
+}
+
+// coerceValue checks a value against an attribute's shape, wrapping single
+// values of multi-valued attributes and accepting "True"/"False" strings for
+// booleans. element is true when value is one element or sub-attribute.
+func coerceValue(def *attributeDef, value interface{}, element bool) (interface{}, error) {
+	if value == nil {
+		return nil, fmt.Errorf("invalid value: %s requires a value", def.name)
+	}
+
+	if def.multiValued && !element {
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		out := make([]interface{}, len(values))
+		for i, v := range values {
+			coerced, err := coerceValue(def, v, true)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = coerced
+		}
+		return out, nil
+	}
+
+	switch {
+	case def.boolean:
+		if s, ok := value.(string); ok {
+			switch strings.ToLower(s) {
+			case "true":
+				return true, nil
+			case "false":
+				return false, nil
+			}
+		}
+		if _, ok := value.(bool); !ok {
+			return nil, fmt.Errorf("invalid value: %s must be a boolean", def.name)
+		}
+	case def.sub != nil:
+		object, ok := value.(map[string]interface{})
+		if _, hasValue := def.sub["value"]; !ok && hasValue {
+			// A bare value stands for the "value" sub-attribute; Entra ID sends
+			// the enterprise manager this way
+			object, ok = map[string]interface{}{"value": value}, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid value: %s must be an object", def.name)
+		}
+		out := make(map[string]interface{}, len(object))
+		for key, v := range object {
+			subDef, ok := def.sub[strings.ToLower(key)]
+			if !ok {
+				out[key] = v
+				continue
+			}
+			coerced, err := coerceValue(subDef, v, false)
+			if err != nil {
+				return nil, err
+			}
+			out[subDef.name] = coerced
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
+// mergeInto sets the entries of values on the object held at doc[name]
+func mergeInto(doc map[string]interface{}, name string, values map[string]interface{}) {
+	object, _ := doc[name].(map[string]interface{})
+	if object == nil {
+		object = make(map[string]interface{}, len(values))
+		doc[name] = object
+	}
+	for k, v := range values {
+		object[k] = v
+	}
+}
+
+// containsElement reports whether values holds v, comparing complex values by
+// their "value" sub-attribute when both have one
+func containsElement(values []interface{}, v interface{}) bool {
+	key, hasKey := elementKey(v)
+	for _, existing := range values {
+		if existingKey, ok := elementKey(existing); hasKey && ok {
+			if strings.EqualFold(existingKey, key) {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(existing, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func elementKey(v interface{}) (string, bool) {
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	key, ok := object["value"].(string)
+	return key, ok
+}
+
+func containsIndex(indexes []int, i int) bool {
+	for _, index := range indexes {
+		if index == i {
+			return true
+		}
+	}
+	return false
+}
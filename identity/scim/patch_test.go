@@ -0,0 +1,140 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSCIMUser() *models.SCIMUser {
+	return &models.SCIMUser{
+		Schemas:  []string{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		ID:       "u1",
+		UserName: "bjensen",
+		Active:   true,
+		Name:     models.SCIMName{GivenName: "Barbara", FamilyName: "Jensen"},
+		Emails:   []models.SCIMEmail{{Value: "bjensen@example.com", Type: "work", Primary: true}},
+	}
+}
+
+func patchUser(t *testing.T, operations ...PatchOperation) (*models.SCIMUser, error) {
+	t.Helper()
+	var patched models.SCIMUser
+	err := applyPatch(testSCIMUser(), userAttributes, operations, &patched)
+	return &patched, err
+}
+
+func TestApplyPatch_User(t *testing.T) {
+	t.Run("replace simple and sub-attributes", func(t *testing.T) {
+		u, err := patchUser(t,
+			PatchOperation{Op: "Replace", Path: "userName", Value: "barbara"},
+			PatchOperation{Op: "replace", Path: "name.familyName", Value: "Jones"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "barbara", u.UserName)
+		assert.Equal(t, "Jones", u.Name.FamilyName)
+		assert.Equal(t, "Barbara", u.Name.GivenName)
+	})
+
+	t.Run("replace without path and string booleans", func(t *testing.T) {
+		u, err := patchUser(t, PatchOperation{Op: "Replace", Value: map[string]interface{}{
+			"active":         "False",
+			"name.givenName": "Babs",
+		}})
+		require.NoError(t, err)
+		assert.False(t, u.Active)
+		assert.Equal(t, "Babs", u.Name.GivenName)
+	})
+
+	t.Run("replace through value filter", func(t *testing.T) {
+		u, err := patchUser(t, PatchOperation{Op: "replace", Path: `emails[type eq "work"].value`, Value: "bj@example.org"})
+		require.NoError(t, err)
+		require.Len(t, u.Emails, 1)
+		assert.Equal(t, "bj@example.org", u.Emails[0].Value)
+		assert.True(t, u.Emails[0].Primary)
+	})
+
+	t.Run("add creates the element a simple filter selects", func(t *testing.T) {
+		u, err := patchUser(t, PatchOperation{Op: "add", Path: `phoneNumbers[type eq "mobile"].value`, Value: "555-0100"})
+		require.NoError(t, err)
+		assert.Equal(t, []models.SCIMPhoneNumber{{Value: "555-0100", Type: "mobile"}}, u.PhoneNumbers)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		u, err := patchUser(t, PatchOperation{Op: "remove", Path: "name.givenName"})
+		require.NoError(t, err)
+		assert.Empty(t, u.Name.GivenName)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			op   PatchOperation
+			want string
+		}{
+			{PatchOperation{Op: "move", Path: "userName"}, "invalid syntax"},
+			{PatchOperation{Op: "remove"}, "no target"},
+			{PatchOperation{Op: "replace", Path: "nickname.x", Value: "a"}, "invalid path"},
+			{PatchOperation{Op: "replace", Path: "unknown", Value: "a"}, "invalid path"},
+			{PatchOperation{Op: "replace", Path: "id", Value: "u2"}, "mutability"},
+			{PatchOperation{Op: "replace", Path: "active", Value: "maybe"}, "invalid value"},
+			{PatchOperation{Op: "replace", Path: `emails[type eq "work" or primary eq true].display`, Value: "x"}, ""},
+			{PatchOperation{Op: "replace", Path: `emails[value sw "x"]`, Value: map[string]interface{}{"value": "x"}}, "no target"},
+		}
+		for _, tt := range tests {
+			_, err := patchUser(t, tt.op)
+			if tt.want == "" {
+				assert.NoError(t, err, tt.op.Path)
+				continue
+			}
+			require.Error(t, err, tt.op.Path)
+			assert.Contains(t, err.Error(), tt.want, tt.op.Path)
+		}
+	})
+
+	t.Run("replacing the id with itself is allowed", func(t *testing.T) {
+		_, err := patchUser(t, PatchOperation{Op: "replace", Value: map[string]interface{}{"id": "u1", "userName": "b"}})
+		assert.NoError(t, err)
+	})
+}
+
+func TestApplyPatch_GroupMembers(t *testing.T) {
+	current := &models.SCIMGroup{
+		ID:          "g1",
+		DisplayName: "Admins",
+		Members:     []models.SCIMGroupMember{{Value: "a", Type: "User"}, {Value: "b", Type: "User"}},
+	}
+	apply := func(operations ...PatchOperation) *models.SCIMGroup {
+		var patched models.SCIMGroup
+		require.NoError(t, applyPatch(current, groupAttributes, operations, &patched))
+		return &patched
+	}
+	values := func(g *models.SCIMGroup) []string {
+		var out []string
+		for _, m := range g.Members {
+			out = append(out, m.Value)
+		}
+		return out
+	}
+
+	// Okta adds members with an array and skips ones already present
+	g := apply(PatchOperation{Op: "add", Path: "members", Value: []interface{}{
+		map[string]interface{}{"value": "b"},
+		map[string]interface{}{"value": "c"},
+	}})
+	assert.Equal(t, []string{"a", "b", "c"}, values(g))
+
+	// Okta removes by filter, Entra ID by value list
+	g = apply(PatchOperation{Op: "remove", Path: `members[value eq "a"]`})
+	assert.Equal(t, []string{"b"}, values(g))
+	g = apply(PatchOperation{Op: "Remove", Path: "members", Value: []interface{}{map[string]interface{}{"value": "b"}}})
+	assert.Equal(t, []string{"a"}, values(g))
+
+	g = apply(PatchOperation{Op: "replace", Path: "members", Value: []interface{}{map[string]interface{}{"value": "z"}}})
+	assert.Equal(t, []string{"z"}, values(g))
+
+	g = apply(PatchOperation{Op: "remove", Path: "members"})
+	assert.Empty(t, g.Members)
+	assert.Equal(t, "Admins", g.DisplayName)
+}
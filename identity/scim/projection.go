@@ -0,0 +1,160 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// alwaysReturned are the attributes returned regardless of attributes and
+// excludedAttributes (RFC 7643 section 7, "returned" is "always")
+var alwaysReturned = map[string]bool{"id": true, "schemas": true}
+
+// Project applies the attributes and excludedAttributes query parameters
+// (RFC 7644 section 3.4.2.5) to a resource. Attribute names may carry a
+// schema URN and may name sub-attributes, e.g. "name.givenName";
+// unknown names are ignored. The resource is returned unchanged when both
+// lists are empty.
+func Project(resource interface{}, attributes, excludedAttributes []string) (interface{}, error) {
+	if len(attributes) == 0 && len(excludedAttributes) == 0 {
+		return resource, nil
+	}
+
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode resource: %w", err)
+	}
+
+	if len(attributes) > 0 {
+		projected := make(map[string]interface{})
+		for key, value := range doc {
+			if alwaysReturned[key] {
+				projected[key] = value
+			}
+		}
+		for _, attr := range attributes {
+			key, value, sub, ok := lookupProjected(doc, attr)
+			if !ok {
+				continue
+			}
+			if sub == "" {
+				projected[key] = value
+				continue
+			}
+			projected[key] = mergeSubAttribute(projected[key], value, sub)
+		}
+		doc = projected
+	}
+
+	for _, attr := range excludedAttributes {
+		key, value, sub, ok := lookupProjected(doc, attr)
+		if !ok || alwaysReturned[key] {
+			continue
+		}
+		if sub == "" {
+			delete(doc, key)
+			continue
+		}
+		removeSubAttribute(value, sub)
+	}
+
+	return doc, nil
+}
+
+// ParseAttributeList splits a comma-separated attributes or excludedAttributes parameter
+func ParseAttributeList(value string) []string {
+	var attributes []string
+	for _, attr := range strings.Split(value, ",") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			attributes = append(attributes, attr)
+		}
+	}
+	return attributes
+}
+
+// lookupProjected finds the top-level attribute a path names in a decoded
+// resource and returns its key, its value and the sub-attribute named, if any
+func lookupProjected(doc map[string]interface{}, attr string) (string, interface{}, string, bool) {
+	name, sub := splitProjectedAttribute(attr)
+	if key, value, ok := findKey(doc, name); ok {
+		return key, value, sub, true
+	}
+	// A schema extension named in full splits like one of its attributes
+	if _, _, ok := splitExtensionURN(attr); ok {
+		if key, value, ok := findKey(doc, name+":"+sub); ok {
+			return key, value, "", true
+		}
+	}
+	return "", nil, "", false
+}
+
+// splitProjectedAttribute returns the top-level attribute and sub-attribute
+// of a path. Attributes of an extension schema are sub-attributes of the
+// schema URN.
+func splitProjectedAttribute(attr string) (string, string) {
+	if schema, rest, ok := splitExtensionURN(attr); ok {
+		return schema, strings.ToLower(rest)
+	}
+	attr, err := normalizeAttribute(attr)
+	if err != nil {
+		return "", ""
+	}
+	if i := strings.Index(attr, "."); i > 0 {
+		return attr[:i], attr[i+1:]
+	}
+	return attr, ""
+}
+
+// findKey looks up a lower-cased attribute name in a decoded resource
+func findKey(doc map[string]interface{}, name string) (string, interface{}, bool) {
+	for key, value := range doc {
+		if strings.ToLower(key) == name {
+			return key, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// mergeSubAttribute copies one sub-attribute of source, a complex or
+// multi-valued complex value, into the projection built so far
+func mergeSubAttribute(projected, source interface{}, sub string) interface{} {
+	switch src := source.(type) {
+	case map[string]interface{}:
+		out, _ := projected.(map[string]interface{})
+		if out == nil {
+			out = make(map[string]interface{})
+		}
+		if key, value, ok := findKey(src, sub); ok {
+			out[key] = value
+		}
+		return out
+	case []interface{}:
+		out, _ := projected.([]interface{})
+		if len(out) != len(src) {
+			out = make([]interface{}, len(src))
+		}
+		for i, element := range src {
+			out[i] = mergeSubAttribute(out[i], element, sub)
+		}
+		return out
+	}
+	return projected
+}
+
+// removeSubAttribute deletes a sub-attribute from a complex or multi-valued complex value
+func removeSubAttribute(value interface{}, sub string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if key, _, ok := findKey(v, sub); ok {
+			delete(v, key)
+		}
+	case []interface{}:
+		for _, element := range v {
+			removeSubAttribute(element, sub)
+		}
+	}
+}
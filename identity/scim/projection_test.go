@@ -0,0 +1,58 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProject(t *testing.T) {
+	user := testSCIMUser()
+
+	unchanged, err := Project(user, nil, nil)
+	require.NoError(t, err)
+	assert.Same(t, user, unchanged)
+
+	projected, err := Project(user, []string{"userName", "urn:ietf:params:scim:schemas:core:2.0:User:name.givenName", "emails.value", "unknown"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		"id":       "u1",
+		"userName": "bjensen",
+		"name":     map[string]interface{}{"givenName": "Barbara"},
+		"emails":   []interface{}{map[string]interface{}{"value": "bjensen@example.com"}},
+	}, projected)
+
+	projected, err = Project(user, nil, []string{"emails.type", "name", "id", "meta"})
+	require.NoError(t, err)
+	doc := projected.(map[string]interface{})
+	assert.NotContains(t, doc, "name")
+	assert.NotContains(t, doc, "meta")
+	assert.Equal(t, "u1", doc["id"], "id is always returned")
+	assert.Equal(t, []interface{}{map[string]interface{}{"value": "bjensen@example.com", "primary": true}}, doc["emails"])
+}
+
+func TestParseAttributeList(t *testing.T) {
+	assert.Equal(t, []string{"userName", "name.givenName"}, ParseAttributeList(" userName, name.givenName,,"))
+	assert.Nil(t, ParseAttributeList(""))
+}
+
+func TestResourceVersion(t *testing.T) {
+	a := testSCIMUser()
+	b := testSCIMUser()
+	b.Meta = models.SCIMMeta{ResourceType: "User", Location: "/scim/v2/Users/u1"}
+
+	version := resourceVersion(a)
+	assert.Regexp(t, `^W/"[0-9a-f]{16}"$`, version)
+	assert.Equal(t, version, resourceVersion(b), "meta does not affect the version")
+
+	b.UserName = "other"
+	assert.NotEqual(t, version, resourceVersion(b))
+
+	assert.True(t, ETagMatches(version, version))
+	assert.True(t, ETagMatches(`"x", `+version[2:], version), "weak comparison over a list")
+	assert.True(t, ETagMatches("*", version))
+	assert.False(t, ETagMatches(`W/"0000000000000000"`, version))
+}
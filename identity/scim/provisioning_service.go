@@ -3,11 +3,11 @@ package scim
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/google/uuid"
+	"github.com/arauth-identity/iam/identity/audit"
 	"github.com/arauth-identity/iam/identity/models"
-	"github.com/arauth-identity/iam/identity/role"
+	"github.com/arauth-identity/iam/identity/group"
 	"github.com/arauth-identity/iam/identity/user"
 	"github.com/arauth-identity/iam/storage/interfaces"
 )
@@ -15,30 +15,43 @@ import (
 // ProvisioningService provides SCIM 2.0 provisioning
 type ProvisioningService struct {
 	userService    user.ServiceInterface
-	roleService    role.ServiceInterface
+	groupService   group.ServiceInterface
 	userRepo       interfaces.UserRepository
-	roleRepo       interfaces.RoleRepository
-	tenantID       uuid.UUID // Tenant ID from token context
+	schemaService  SchemaServiceInterface
+	auditService   audit.ServiceInterface
+	txRunner       interfaces.TxRunner
 }
 
-// NewProvisioningService creates a new SCIM provisioning service
+// NewProvisioningService creates a new SCIM provisioning service. The service
+// is shared by all tenants: the tenant is passed with every call and the
+// calling SCIM token travels in the request context (see WithCaller).
+// schemaService may be nil, in which case users have no tenant-defined extensions;
+// auditService may be nil, in which case changes are not audited;
+// txRunner may be nil, in which case atomic bulk requests are rejected.
 func NewProvisioningService(
 	userService user.ServiceInterface,
-	roleService role.ServiceInterface,
+	groupService group.ServiceInterface,
 	userRepo interfaces.UserRepository,
-	roleRepo interfaces.RoleRepository,
+	schemaService SchemaServiceInterface,
+	auditService audit.ServiceInterface,
+	txRunner interfaces.TxRunner,
 ) ProvisioningServiceInterface {
 	return &ProvisioningService{
-		userService: userService,
-		roleService: roleService,
-		userRepo:    userRepo,
-		roleRepo:    roleRepo,
+		userService:   userService,
+		groupService:  groupService,
+		userRepo:      userRepo,
+		schemaService: schemaService,
+		auditService:  auditService,
+		txRunner:      txRunner,
 	}
 }
 
-// SetTenantID sets the tenant ID for the service (from token context)
-func (s *ProvisioningService) SetTenantID(tenantID uuid.UUID) {
-	s.tenantID = tenantID
+// withinTx runs fn in a transaction, or directly without a transaction runner
+func (s *ProvisioningService) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.txRunner == nil {
+		return fn(ctx)
+	}
+	return s.txRunner.WithinTx(ctx, fn)
 }
 
 // CreateUser creates a user from SCIM User resource
@@ -71,6 +84,16 @@ func (s *ProvisioningService) CreateUser(ctx context.Context, tenantID uuid.UUID
 		password = generateRandomPassword()
 	}
 
+	// Schema extensions are stored in metadata
+	extensions, err := s.tenantExtensions(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := s.extensionMetadata(ctx, tenantID, uuid.Nil, nil, scimUser, extensions)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create user request
 	createReq := &user.CreateUserRequest{
 		TenantID:  tenantID,
@@ -80,6 +103,7 @@ func (s *ProvisioningService) CreateUser(ctx context.Context, tenantID uuid.UUID
 		FirstName: &firstName,
 		LastName:  &lastName,
 		Status:    mapSCIMActiveToStatus(scimUser.Active),
+		Metadata:  metadata,
 	}
 
 	// Create user
@@ -87,9 +111,10 @@ func (s *ProvisioningService) CreateUser(ctx context.Context, tenantID uuid.UUID
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	s.logChange(ctx, tenantID, models.EventTypeUserCreated, "user", createdUser.ID, createdUser.Username)
 
 	// Convert to SCIM format
-	return s.userToSCIM(createdUser, tenantID), nil
+	return s.userToSCIM(createdUser, extensions), nil
 }
 
 // GetUser retrieves a user by ID and converts to SCIM format
@@ -109,7 +134,29 @@ func (s *ProvisioningService) GetUser(ctx context.Context, tenantID uuid.UUID, u
 		return nil, fmt.Errorf("user not found")
 	}
 
-	return s.userToSCIM(u, tenantID), nil
+	return s.toSCIMWithExtensions(ctx, u, tenantID)
+}
+
+// CheckUserVersion locks a user and returns a precondition failed error
+// unless ifMatch matches its current version. It must run in the
+// transaction that writes the user.
+func (s *ProvisioningService) CheckUserVersion(ctx context.Context, tenantID uuid.UUID, userID, ifMatch string) error {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format")
+	}
+	if err := s.userRepo.LockByID(ctx, userUUID); err != nil {
+		return err
+	}
+
+	current, err := s.GetUser(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if !ETagMatches(ifMatch, current.Meta.Version) {
+		return fmt.Errorf("precondition failed: version %s does not match %s", ifMatch, current.Meta.Version)
+	}
+	return nil
 }
 
 // GetUserByExternalID retrieves a user by external ID
@@ -121,7 +168,7 @@ func (s *ProvisioningService) GetUserByExternalID(ctx context.Context, tenantID
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	return s.userToSCIM(u, tenantID), nil
+	return s.toSCIMWithExtensions(ctx, u, tenantID)
 }
 
 // GetUserByUserName retrieves a user by username
@@ -131,29 +178,14 @@ func (s *ProvisioningService) GetUserByUserName(ctx context.Context, tenantID uu
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	return s.userToSCIM(u, tenantID), nil
+	return s.toSCIMWithExtensions(ctx, u, tenantID)
 }
 
 // ListUsers lists users with SCIM filters
 func (s *ProvisioningService) ListUsers(ctx context.Context, tenantID uuid.UUID, filters *UserFilters) ([]*models.SCIMUser, int, error) {
-	// Convert SCIM filters to internal filters
-	internalFilters := &interfaces.UserFilters{
-		Page:     (filters.StartIndex / filters.Count) + 1,
-		PageSize: filters.Count,
-	}
-
-	// Parse SCIM filter if provided
-	if filters.Filter != "" {
-		// Simple filter parsing (can be enhanced)
-		// For now, support: userName eq "value", email eq "value"
-		if strings.HasPrefix(filters.Filter, "userName eq ") {
-			userName := strings.Trim(strings.TrimPrefix(filters.Filter, "userName eq "), "\"")
-			u, err := s.userService.GetByUsername(ctx, userName, tenantID)
-			if err != nil {
-				return []*models.SCIMUser{}, 0, nil
-			}
-			return []*models.SCIMUser{s.userToSCIM(u, tenantID)}, 1, nil
-		}
+	internalFilters, err := userListFilters(filters)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	users, err := s.userService.List(ctx, tenantID, internalFilters)
@@ -166,14 +198,44 @@ func (s *ProvisioningService) ListUsers(ctx context.Context, tenantID uuid.UUID,
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
+	extensions, err := s.tenantExtensions(ctx, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
 	scimUsers := make([]*models.SCIMUser, len(users))
 	for i, u := range users {
-		scimUsers[i] = s.userToSCIM(u, tenantID)
+		scimUsers[i] = s.userToSCIM(u, extensions)
 	}
 
 	return scimUsers, total, nil
 }
 
+// userListFilters translates SCIM list parameters into repository filters
+func userListFilters(filters *UserFilters) (*interfaces.UserFilters, error) {
+	internalFilters := &interfaces.UserFilters{
+		Page:     1,
+		PageSize: filters.Count,
+		Offset:   startOffset(filters.StartIndex),
+	}
+
+	var err error
+	if filters.Filter != "" {
+		if internalFilters.Filter, err = userFilterAttributes.parse(filters.Filter); err != nil {
+			return nil, err
+		}
+	}
+	if filters.SortBy != "" {
+		if internalFilters.SortBy, err = userFilterAttributes.sortField(filters.SortBy); err != nil {
+			return nil, err
+		}
+	}
+	if internalFilters.SortDescending, err = parseSortOrder(filters.SortOrder); err != nil {
+		return nil, err
+	}
+
+	return internalFilters, nil
+}
+
 // UpdateUser updates a user from SCIM User resource
 func (s *ProvisioningService) UpdateUser(ctx context.Context, tenantID uuid.UUID, userID string, scimUser *models.SCIMUser) (*models.SCIMUser, error) {
 	userUUID, err := uuid.Parse(userID)
@@ -226,13 +288,42 @@ func (s *ProvisioningService) UpdateUser(ctx context.Context, tenantID uuid.UUID
 		// For now, skip password updates via SCIM
 	}
 
+	// Schema extensions replace the metadata keys they own
+	extensions, err := s.tenantExtensions(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if updateReq.Metadata, err = s.extensionMetadata(ctx, tenantID, userUUID, existingUser.Metadata, scimUser, extensions); err != nil {
+		return nil, err
+	}
+
 	// Update user
 	updatedUser, err := s.userService.Update(ctx, userUUID, updateReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
+	s.logChange(ctx, tenantID, models.EventTypeUserUpdated, "user", updatedUser.ID, updatedUser.Username)
 
-	return s.userToSCIM(updatedUser, tenantID), nil
+	return s.userToSCIM(updatedUser, extensions), nil
+}
+
+// PatchUser applies RFC 7644 PATCH operations to a user
+func (s *ProvisioningService) PatchUser(ctx context.Context, tenantID uuid.UUID, userID string, req *PatchRequest) (*models.SCIMUser, error) {
+	current, err := s.GetUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := s.tenantExtensions(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched models.SCIMUser
+	if err := applyPatch(current, userPatchAttributes(extensions), req.Operations, &patched); err != nil {
+		return nil, err
+	}
+
+	return s.UpdateUser(ctx, tenantID, userID, &patched)
 }
 
 // DeleteUser deletes a user
@@ -252,183 +343,359 @@ func (s *ProvisioningService) DeleteUser(ctx context.Context, tenantID uuid.UUID
 		return fmt.Errorf("user not found")
 	}
 
-	return s.userService.Delete(ctx, userUUID)
+	if err := s.userService.Delete(ctx, userUUID); err != nil {
+		return err
+	}
+	s.logChange(ctx, tenantID, models.EventTypeUserDeleted, "user", existingUser.ID, existingUser.Username)
+	return nil
 }
 
 // CreateGroup creates a group from SCIM Group resource
 func (s *ProvisioningService) CreateGroup(ctx context.Context, tenantID uuid.UUID, scimGroup *models.SCIMGroup) (*models.SCIMGroup, error) {
-	// Create role request
-	createReq := &role.CreateRoleRequest{
-		TenantID: tenantID,
-		Name:     scimGroup.DisplayName,
-		Description: &scimGroup.Description,
+	if scimGroup.DisplayName == "" {
+		return nil, fmt.Errorf("displayName is required")
+	}
+
+	// Resolve members up front so a bad member doesn't leave a half-created group
+	userIDs, groupIDs, err := s.resolveMembers(ctx, tenantID, scimGroup.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	createReq := &group.CreateGroupRequest{
+		TenantID:    tenantID,
+		Name:        scimGroup.DisplayName,
+		Description: optionalString(scimGroup.Description),
+		ExternalID:  optionalString(scimGroup.ExternalID),
 	}
 
-	// Create role
-	createdRole, err := s.roleService.Create(ctx, createReq)
+	createdGroup, err := s.groupService.Create(ctx, createReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create group: %w", err)
 	}
 
-	// Assign members (users) to role
-	for _, member := range scimGroup.Members {
-		if member.Type == "User" || member.Type == "" {
-			userUUID, err := uuid.Parse(member.Value)
-			if err == nil {
-				_ = s.roleService.AssignRoleToUser(ctx, userUUID, createdRole.ID)
-			}
+	if len(userIDs) > 0 || len(groupIDs) > 0 {
+		if err := s.groupService.SetMembers(ctx, createdGroup.ID, userIDs, groupIDs); err != nil {
+			return nil, fmt.Errorf("failed to set group members: %w", err)
 		}
 	}
+	s.logChange(ctx, tenantID, models.EventTypeGroupCreated, "group", createdGroup.ID, createdGroup.Name)
 
-	return s.roleToSCIM(createdRole, tenantID), nil
+	return s.groupToSCIM(ctx, createdGroup)
 }
 
 // GetGroup retrieves a group by ID
 func (s *ProvisioningService) GetGroup(ctx context.Context, tenantID uuid.UUID, groupID string) (*models.SCIMGroup, error) {
-	roleUUID, err := uuid.Parse(groupID)
+	g, err := s.getTenantGroup(ctx, tenantID, groupID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid group ID format")
+		return nil, err
 	}
 
-	r, err := s.roleService.GetByID(ctx, roleUUID)
+	return s.groupToSCIM(ctx, g)
+}
+
+// CheckGroupVersion locks a group and returns a precondition failed error
+// unless ifMatch matches its current version. It must run in the
+// transaction that writes the group.
+func (s *ProvisioningService) CheckGroupVersion(ctx context.Context, tenantID uuid.UUID, groupID, ifMatch string) error {
+	groupUUID, err := uuid.Parse(groupID)
 	if err != nil {
-		return nil, fmt.Errorf("group not found: %w", err)
+		return fmt.Errorf("invalid group ID format")
 	}
-
-	// Verify tenant ownership
-	if r.TenantID != tenantID {
-		return nil, fmt.Errorf("group not found")
+	if err := s.groupService.Lock(ctx, groupUUID); err != nil {
+		return err
 	}
 
-	return s.roleToSCIM(r, tenantID), nil
+	current, err := s.GetGroup(ctx, tenantID, groupID)
+	if err != nil {
+		return err
+	}
+	if !ETagMatches(ifMatch, current.Meta.Version) {
+		return fmt.Errorf("precondition failed: version %s does not match %s", ifMatch, current.Meta.Version)
+	}
+	return nil
 }
 
 // GetGroupByExternalID retrieves a group by external ID
 func (s *ProvisioningService) GetGroupByExternalID(ctx context.Context, tenantID uuid.UUID, externalID string) (*models.SCIMGroup, error) {
-	// For now, use externalID as name
-	// In production, add external_id field to roles
-	roles, err := s.roleService.List(ctx, tenantID, &interfaces.RoleFilters{})
+	g, err := s.groupService.GetByExternalID(ctx, tenantID, externalID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list groups: %w", err)
-	}
-
-	for _, r := range roles {
-		if r.Name == externalID {
-			return s.roleToSCIM(r, tenantID), nil
-		}
+		return nil, fmt.Errorf("group not found: %w", err)
 	}
 
-	return nil, fmt.Errorf("group not found")
+	return s.groupToSCIM(ctx, g)
 }
 
-// ListGroups lists groups
+// ListGroups lists groups with SCIM filters
 func (s *ProvisioningService) ListGroups(ctx context.Context, tenantID uuid.UUID, filters *GroupFilters) ([]*models.SCIMGroup, int, error) {
-	roles, err := s.roleService.List(ctx, tenantID, &interfaces.RoleFilters{})
+	internalFilters, err := groupListFilters(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	groups, err := s.groupService.List(ctx, tenantID, internalFilters)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list groups: %w", err)
 	}
 
-	scimGroups := make([]*models.SCIMGroup, len(roles))
-	for i, r := range roles {
-		scimGroups[i] = s.roleToSCIM(r, tenantID)
+	total, err := s.groupService.Count(ctx, tenantID, internalFilters)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count groups: %w", err)
+	}
+
+	scimGroups := make([]*models.SCIMGroup, len(groups))
+	for i, g := range groups {
+		scimGroups[i], err = s.groupToSCIM(ctx, g)
+		if err != nil {
+			return nil, 0, err
+		}
 	}
 
-	return scimGroups, len(scimGroups), nil
+	return scimGroups, total, nil
 }
 
-// UpdateGroup updates a group
-func (s *ProvisioningService) UpdateGroup(ctx context.Context, tenantID uuid.UUID, groupID string, scimGroup *models.SCIMGroup) (*models.SCIMGroup, error) {
-	roleUUID, err := uuid.Parse(groupID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid group ID format")
+// groupListFilters translates SCIM list parameters into repository filters
+func groupListFilters(filters *GroupFilters) (*interfaces.GroupFilters, error) {
+	internalFilters := &interfaces.GroupFilters{
+		Page:     1,
+		PageSize: filters.Count,
+		Offset:   startOffset(filters.StartIndex),
 	}
 
-	// Verify tenant ownership
-	existingRole, err := s.roleService.GetByID(ctx, roleUUID)
+	var err error
+	if filters.Filter != "" {
+		if internalFilters.Filter, err = groupFilterAttributes.parse(filters.Filter); err != nil {
+			return nil, err
+		}
+	}
+	if filters.SortBy != "" {
+		if internalFilters.SortBy, err = groupFilterAttributes.sortField(filters.SortBy); err != nil {
+			return nil, err
+		}
+	}
+	if internalFilters.SortDescending, err = parseSortOrder(filters.SortOrder); err != nil {
+		return nil, err
+	}
+
+	return internalFilters, nil
+}
+
+// startOffset converts a 1-based SCIM startIndex into a row offset
+func startOffset(startIndex int) *int {
+	offset := startIndex - 1
+	if offset < 0 {
+		offset = 0
+	}
+	return &offset
+}
+
+// UpdateGroup replaces a group, including its full member list
+func (s *ProvisioningService) UpdateGroup(ctx context.Context, tenantID uuid.UUID, groupID string, scimGroup *models.SCIMGroup) (*models.SCIMGroup, error) {
+	existingGroup, err := s.getTenantGroup(ctx, tenantID, groupID)
 	if err != nil {
-		return nil, fmt.Errorf("group not found: %w", err)
+		return nil, err
 	}
 
-	if existingRole.TenantID != tenantID {
-		return nil, fmt.Errorf("group not found")
+	userIDs, groupIDs, err := s.resolveMembers(ctx, tenantID, scimGroup.Members)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build update request
-	updateReq := &role.UpdateRoleRequest{}
+	updateReq := &group.UpdateGroupRequest{
+		Description: &scimGroup.Description,
+		ExternalID:  &scimGroup.ExternalID,
+	}
 	if scimGroup.DisplayName != "" {
 		updateReq.Name = &scimGroup.DisplayName
 	}
-	if scimGroup.Description != "" {
-		updateReq.Description = &scimGroup.Description
-	}
 
-	// Update role
-	updatedRole, err := s.roleService.Update(ctx, roleUUID, updateReq)
+	updatedGroup, err := s.groupService.Update(ctx, existingGroup.ID, updateReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update group: %w", err)
 	}
 
-	// Update members if provided
-	// TODO: Implement member management
+	// PUT replaces the resource, so members not listed are removed
+	if err := s.groupService.SetMembers(ctx, existingGroup.ID, userIDs, groupIDs); err != nil {
+		return nil, fmt.Errorf("failed to set group members: %w", err)
+	}
+	s.logChange(ctx, tenantID, models.EventTypeGroupUpdated, "group", updatedGroup.ID, updatedGroup.Name)
 
-	return s.roleToSCIM(updatedRole, tenantID), nil
+	return s.groupToSCIM(ctx, updatedGroup)
+}
+
+// PatchGroup applies RFC 7644 PATCH operations to a group. Member changes
+// are applied to the current member list, which then replaces the stored one.
+func (s *ProvisioningService) PatchGroup(ctx context.Context, tenantID uuid.UUID, groupID string, req *PatchRequest) (*models.SCIMGroup, error) {
+	current, err := s.GetGroup(ctx, tenantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched models.SCIMGroup
+	if err := applyPatch(current, groupAttributes, req.Operations, &patched); err != nil {
+		return nil, err
+	}
+
+	return s.UpdateGroup(ctx, tenantID, groupID, &patched)
 }
 
 // DeleteGroup deletes a group
 func (s *ProvisioningService) DeleteGroup(ctx context.Context, tenantID uuid.UUID, groupID string) error {
-	roleUUID, err := uuid.Parse(groupID)
+	existingGroup, err := s.getTenantGroup(ctx, tenantID, groupID)
 	if err != nil {
-		return fmt.Errorf("invalid group ID format")
+		return err
 	}
 
-	// Verify tenant ownership
-	existingRole, err := s.roleService.GetByID(ctx, roleUUID)
+	if err := s.groupService.Delete(ctx, existingGroup.ID); err != nil {
+		return err
+	}
+	s.logChange(ctx, tenantID, models.EventTypeGroupDeleted, "group", existingGroup.ID, existingGroup.Name)
+	return nil
+}
+
+// logChange records an audit event for a change made through SCIM,
+// attributed to the SCIM token of the request
+func (s *ProvisioningService) logChange(ctx context.Context, tenantID uuid.UUID, eventType, targetType string, targetID uuid.UUID, identifier string) {
+	caller, ok := CallerFromContext(ctx)
+	if s.auditService == nil || !ok {
+		return
+	}
+	event := &models.AuditEvent{
+		EventType: eventType,
+		Actor: models.AuditActor{
+			UserID:        caller.Token.ID,
+			Username:      caller.Token.Name,
+			PrincipalType: string(models.PrincipalTypeService),
+		},
+		Target: &models.AuditTarget{
+			Type:       targetType,
+			ID:         targetID,
+			Identifier: identifier,
+		},
+		TenantID:  &tenantID,
+		SourceIP:  caller.SourceIP,
+		UserAgent: caller.UserAgent,
+		Metadata: map[string]interface{}{
+			"source":        "scim",
+			"scim_token_id": caller.Token.ID.String(),
+		},
+		Result: models.ResultSuccess,
+	}
+	event.Flatten()
+	_ = s.auditService.LogEvent(ctx, event)
+}
+
+// getTenantGroup loads a group by its SCIM id, hiding groups of other tenants
+func (s *ProvisioningService) getTenantGroup(ctx context.Context, tenantID uuid.UUID, groupID string) (*models.Group, error) {
+	groupUUID, err := uuid.Parse(groupID)
 	if err != nil {
-		return fmt.Errorf("group not found: %w", err)
+		return nil, fmt.Errorf("invalid group ID format")
 	}
 
-	if existingRole.TenantID != tenantID {
-		return fmt.Errorf("group not found")
+	g, err := s.groupService.GetByID(ctx, groupUUID)
+	if err != nil {
+		return nil, fmt.Errorf("group not found: %w", err)
 	}
 
-	return s.roleService.Delete(ctx, roleUUID)
+	// Verify tenant ownership
+	if g.TenantID != tenantID {
+		return nil, fmt.Errorf("group not found")
+	}
+
+	return g, nil
 }
 
-// BulkCreate handles bulk operations
-func (s *ProvisioningService) BulkCreate(ctx context.Context, tenantID uuid.UUID, operations []BulkOperation) (*BulkResponse, error) {
-	results := make([]BulkOperationResult, len(operations))
+// resolveMembers splits SCIM members into user and group IDs. Members without
+// a type are treated as users when a user with that ID exists, otherwise as groups.
+func (s *ProvisioningService) resolveMembers(ctx context.Context, tenantID uuid.UUID, members []models.SCIMGroupMember) ([]uuid.UUID, []uuid.UUID, error) {
+	var userIDs, groupIDs []uuid.UUID
 
-	for i, op := range operations {
-		result := BulkOperationResult{
-			Method: op.Method,
-			BulkID: op.BulkID,
+	for _, member := range members {
+		id, err := uuid.Parse(member.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid member value %q", member.Value)
 		}
 
-		switch op.Method {
-		case "POST":
-			// Handle POST operations
-			// This would need to parse the path and data
-			result.Status = "201"
-		case "PUT", "PATCH":
-			result.Status = "200"
-		case "DELETE":
-			result.Status = "204"
-		default:
-			result.Status = "400"
+		memberType := member.Type
+		if memberType == "" {
+			memberType = "User"
+			if u, err := s.userRepo.GetByID(ctx, id); err != nil || u.TenantID == nil || *u.TenantID != tenantID {
+				memberType = "Group"
+			}
 		}
 
-		results[i] = result
+		switch memberType {
+		case "User":
+			userIDs = append(userIDs, id)
+		case "Group":
+			groupIDs = append(groupIDs, id)
+		default:
+			return nil, nil, fmt.Errorf("invalid member type %q", member.Type)
+		}
 	}
 
-	return &BulkResponse{
-		Schemas:    []string{"urn:ietf:params:scim:api:messages:2.0:BulkResponse"},
-		Operations: results,
-	}, nil
+	return userIDs, groupIDs, nil
 }
 
 // Helper functions
 
-func (s *ProvisioningService) userToSCIM(u *models.User, tenantID uuid.UUID) *models.SCIMUser {
+// tenantExtensions returns the tenant's extension schemas
+func (s *ProvisioningService) tenantExtensions(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMSchemaExtension, error) {
+	if s.schemaService == nil {
+		return nil, nil
+	}
+	return s.schemaService.List(ctx, tenantID)
+}
+
+// toSCIMWithExtensions converts a user to SCIM format with the tenant's extensions
+func (s *ProvisioningService) toSCIMWithExtensions(ctx context.Context, u *models.User, tenantID uuid.UUID) (*models.SCIMUser, error) {
+	extensions, err := s.tenantExtensions(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.userToSCIM(u, extensions), nil
+}
+
+// extensionMetadata returns the user's metadata with the enterprise and
+// tenant-defined extension values of scimUser written over it. userID is
+// uuid.Nil for a user being created.
+func (s *ProvisioningService) extensionMetadata(ctx context.Context, tenantID, userID uuid.UUID, current map[string]interface{}, scimUser *models.SCIMUser, extensions []*models.SCIMSchemaExtension) (map[string]interface{}, error) {
+	metadata := make(map[string]interface{}, len(current))
+	for k, v := range current {
+		metadata[k] = v
+	}
+
+	if scimUser.EnterpriseUser != nil && scimUser.EnterpriseUser.Manager != nil {
+		managerID := scimUser.EnterpriseUser.Manager.Value
+		if managerID != "" && managerID != metadata[models.UserMetadataManagerID] {
+			if err := s.checkManager(ctx, tenantID, userID, managerID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	applyEnterprise(metadata, scimUser.EnterpriseUser)
+
+	if err := applyExtensions(metadata, scimUser.Extensions, extensions); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// checkManager verifies that a manager reference names another user of the tenant
+func (s *ProvisioningService) checkManager(ctx context.Context, tenantID, userID uuid.UUID, managerID string) error {
+	id, err := uuid.Parse(managerID)
+	if err != nil || id == userID {
+		return fmt.Errorf("invalid value: manager %s is not another user of this tenant", managerID)
+	}
+	manager, err := s.userRepo.GetByID(ctx, id)
+	if err != nil || manager.TenantID == nil || *manager.TenantID != tenantID {
+		return fmt.Errorf("invalid value: manager %s is not another user of this tenant", managerID)
+	}
+	return nil
+}
+
+func (s *ProvisioningService) userToSCIM(u *models.User, extensions []*models.SCIMSchemaExtension) *models.SCIMUser {
 	scimUser := &models.SCIMUser{
 		Schemas:    []string{"urn:ietf:params:scim:schemas:core:2.0:User"},
 		ID:         u.ID.String(),
@@ -441,6 +708,7 @@ func (s *ProvisioningService) userToSCIM(u *models.User, tenantID uuid.UUID) *mo
 		Emails: []models.SCIMEmail{
 			{
 				Value:   u.Email,
+				Type:    "work",
 				Primary: true,
 			},
 		},
@@ -448,6 +716,7 @@ func (s *ProvisioningService) userToSCIM(u *models.User, tenantID uuid.UUID) *mo
 			ResourceType: "User",
 			Created:      u.CreatedAt,
 			LastModified: u.UpdatedAt,
+			Location:     "/scim/v2/Users/" + u.ID.String(),
 		},
 	}
 
@@ -457,25 +726,66 @@ func (s *ProvisioningService) userToSCIM(u *models.User, tenantID uuid.UUID) *mo
 		scimUser.Name.Formatted = scimUser.DisplayName
 	}
 
+	// Schema extensions, stored in metadata
+	if scimUser.EnterpriseUser = enterpriseFromMetadata(u.Metadata); scimUser.EnterpriseUser != nil {
+		scimUser.Schemas = append(scimUser.Schemas, models.SCIMEnterpriseUserSchema)
+	}
+	scimUser.Extensions = extensionsFromMetadata(u.Metadata, extensions)
+	for _, ext := range extensions {
+		if _, ok := scimUser.Extensions[ext.SchemaURN]; ok {
+			scimUser.Schemas = append(scimUser.Schemas, ext.SchemaURN)
+		}
+	}
+
+	scimUser.Meta.Version = resourceVersion(scimUser)
 	return scimUser
 }
 
-func (s *ProvisioningService) roleToSCIM(r *models.Role, tenantID uuid.UUID) *models.SCIMGroup {
+// groupToSCIM converts a group and its direct members to a SCIM Group resource
+func (s *ProvisioningService) groupToSCIM(ctx context.Context, g *models.Group) (*models.SCIMGroup, error) {
 	scimGroup := &models.SCIMGroup{
 		Schemas:     []string{"urn:ietf:params:scim:schemas:core:2.0:Group"},
-		ID:          r.ID.String(),
-		DisplayName: r.Name,
-		Description: getStringValue(r.Description),
+		ID:          g.ID.String(),
+		ExternalID:  getStringValue(g.ExternalID),
+		DisplayName: g.Name,
+		Description: getStringValue(g.Description),
+		Members:     []models.SCIMGroupMember{},
 		Meta: models.SCIMMeta{
 			ResourceType: "Group",
-			Created:      r.CreatedAt,
-			LastModified: r.UpdatedAt,
+			Created:      g.CreatedAt,
+			LastModified: g.UpdatedAt,
+			Location:     "/scim/v2/Groups/" + g.ID.String(),
 		},
 	}
 
-	// TODO: Populate members from role assignments
+	users, err := s.groupService.ListMembers(ctx, g.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+	for _, m := range users {
+		scimGroup.Members = append(scimGroup.Members, models.SCIMGroupMember{
+			Value:   m.UserID.String(),
+			Ref:     "/scim/v2/Users/" + m.UserID.String(),
+			Display: m.Username,
+			Type:    "User",
+		})
+	}
+
+	nested, err := s.groupService.ListMemberGroups(ctx, g.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list member groups: %w", err)
+	}
+	for _, mg := range nested {
+		scimGroup.Members = append(scimGroup.Members, models.SCIMGroupMember{
+			Value:   mg.ID.String(),
+			Ref:     "/scim/v2/Groups/" + mg.ID.String(),
+			Display: mg.Name,
+			Type:    "Group",
+		})
+	}
 
-	return scimGroup
+	scimGroup.Meta.Version = resourceVersion(scimGroup)
+	return scimGroup, nil
 }
 
 func mapSCIMActiveToStatus(active bool) string {
@@ -496,6 +806,13 @@ func getStringValue(s *string) string {
 	return *s
 }
 
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func generateRandomPassword() string {
 	// Generate a secure random password
 	// In production, this should be a proper random password generator
@@ -2,6 +2,7 @@ package scim
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/arauth-identity/iam/identity/models"
 	"github.com/google/uuid"
@@ -16,6 +17,7 @@ type ProvisioningServiceInterface interface {
 	GetUserByUserName(ctx context.Context, tenantID uuid.UUID, userName string) (*models.SCIMUser, error)
 	ListUsers(ctx context.Context, tenantID uuid.UUID, filters *UserFilters) ([]*models.SCIMUser, int, error)
 	UpdateUser(ctx context.Context, tenantID uuid.UUID, userID string, scimUser *models.SCIMUser) (*models.SCIMUser, error)
+	PatchUser(ctx context.Context, tenantID uuid.UUID, userID string, req *PatchRequest) (*models.SCIMUser, error)
 	DeleteUser(ctx context.Context, tenantID uuid.UUID, userID string) error
 
 	// Group provisioning
@@ -24,38 +26,73 @@ type ProvisioningServiceInterface interface {
 	GetGroupByExternalID(ctx context.Context, tenantID uuid.UUID, externalID string) (*models.SCIMGroup, error)
 	ListGroups(ctx context.Context, tenantID uuid.UUID, filters *GroupFilters) ([]*models.SCIMGroup, int, error)
 	UpdateGroup(ctx context.Context, tenantID uuid.UUID, groupID string, scimGroup *models.SCIMGroup) (*models.SCIMGroup, error)
+	PatchGroup(ctx context.Context, tenantID uuid.UUID, groupID string, req *PatchRequest) (*models.SCIMGroup, error)
 	DeleteGroup(ctx context.Context, tenantID uuid.UUID, groupID string) error
 
+	// Version checks, which lock the resource until the context's transaction
+	// ends so that the version still holds when the resource is written
+	CheckUserVersion(ctx context.Context, tenantID uuid.UUID, userID, ifMatch string) error
+	CheckGroupVersion(ctx context.Context, tenantID uuid.UUID, groupID, ifMatch string) error
+
 	// Bulk operations
-	BulkCreate(ctx context.Context, tenantID uuid.UUID, operations []BulkOperation) (*BulkResponse, error)
+	Bulk(ctx context.Context, tenantID uuid.UUID, req *BulkRequest) (*BulkResponse, error)
 }
 
 // UserFilters defines filters for listing users
 type UserFilters struct {
 	Filter     string // SCIM filter expression
-	StartIndex int
+	SortBy     string // SCIM attribute to sort by
+	SortOrder  string // "ascending" (default) or "descending"
+	StartIndex int    // 1-based
 	Count      int
 }
 
 // GroupFilters defines filters for listing groups
 type GroupFilters struct {
 	Filter     string // SCIM filter expression
-	StartIndex int
+	SortBy     string // SCIM attribute to sort by
+	SortOrder  string // "ascending" (default) or "descending"
+	StartIndex int    // 1-based
 	Count      int
 }
 
+// PatchRequest represents an RFC 7644 PATCH request
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations" binding:"required,min=1"`
+}
+
+// PatchOperation represents one operation of a PATCH request
+type PatchOperation struct {
+	Op    string      `json:"op" binding:"required"` // add, replace or remove, in any case
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// BulkRequest represents an RFC 7644 bulk request
+type BulkRequest struct {
+	Schemas      []string        `json:"schemas"`
+	FailOnErrors int             `json:"failOnErrors,omitempty"` // Stop after this many errors; 0 processes everything
+	Operations   []BulkOperation `json:"Operations" binding:"required"`
+	// Atomic runs every operation in one transaction, which rolls back when
+	// one fails (see Bulk). It is not part of the SCIM message; the handler
+	// sets it from the atomic query parameter.
+	Atomic bool `json:"-"`
+}
+
 // BulkOperation represents a bulk operation
 type BulkOperation struct {
-	Method string      `json:"method"` // POST, PUT, PATCH, DELETE
-	Path   string      `json:"path"`
-	BulkID string      `json:"bulkId,omitempty"`
-	Data   interface{} `json:"data,omitempty"`
+	Method  string          `json:"method"` // POST, PUT, PATCH, DELETE
+	Path    string          `json:"path"`
+	BulkID  string          `json:"bulkId,omitempty"`
+	Version string          `json:"version,omitempty"` // ETag the resource must match
+	Data    json.RawMessage `json:"data,omitempty"`
 }
 
 // BulkResponse represents a bulk operation response
 type BulkResponse struct {
-	Schemas      []string           `json:"schemas"`
-	Operations   []BulkOperationResult `json:"Operations"`
+	Schemas    []string              `json:"schemas"`
+	Operations []BulkOperationResult `json:"Operations"`
 }
 
 // BulkOperationResult represents the result of a bulk operation
@@ -63,8 +100,7 @@ type BulkOperationResult struct {
 	Location string      `json:"location,omitempty"`
 	Method   string      `json:"method"`
 	BulkID   string      `json:"bulkId,omitempty"`
+	Version  string      `json:"version,omitempty"`
 	Status   string      `json:"status"`
 	Response interface{} `json:"response,omitempty"`
 }
-
-
@@ -0,0 +1,240 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/group"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/user"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGroupService keeps groups and their direct members in memory
+type stubGroupService struct {
+	group.ServiceInterface
+	groups       map[uuid.UUID]*models.Group
+	users        map[uuid.UUID][]uuid.UUID
+	memberGroups map[uuid.UUID][]uuid.UUID
+	locked       []uuid.UUID
+}
+
+func newStubGroupService() *stubGroupService {
+	return &stubGroupService{
+		groups:       map[uuid.UUID]*models.Group{},
+		users:        map[uuid.UUID][]uuid.UUID{},
+		memberGroups: map[uuid.UUID][]uuid.UUID{},
+	}
+}
+
+func (s *stubGroupService) Create(ctx context.Context, req *group.CreateGroupRequest) (*models.Group, error) {
+	g := &models.Group{ID: uuid.New(), TenantID: req.TenantID, Name: req.Name, ExternalID: req.ExternalID}
+	s.groups[g.ID] = g
+	return g, nil
+}
+
+func (s *stubGroupService) GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	if g, ok := s.groups[id]; ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("group not found")
+}
+
+func (s *stubGroupService) Lock(ctx context.Context, id uuid.UUID) error {
+	if _, ok := s.groups[id]; !ok {
+		return fmt.Errorf("group not found")
+	}
+	s.locked = append(s.locked, id)
+	return nil
+}
+
+func (s *stubGroupService) Update(ctx context.Context, id uuid.UUID, req *group.UpdateGroupRequest) (*models.Group, error) {
+	g := s.groups[id]
+	if req.Name != nil {
+		g.Name = *req.Name
+	}
+	return g, nil
+}
+
+func (s *stubGroupService) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(s.groups, id)
+	return nil
+}
+
+func (s *stubGroupService) SetMembers(ctx context.Context, groupID uuid.UUID, userIDs, memberGroupIDs []uuid.UUID) error {
+	s.users[groupID] = userIDs
+	s.memberGroups[groupID] = memberGroupIDs
+	return nil
+}
+
+func (s *stubGroupService) ListMembers(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMember, error) {
+	var members []*models.GroupMember
+	for _, id := range s.users[groupID] {
+		members = append(members, &models.GroupMember{GroupID: groupID, UserID: id, Username: "user-" + id.String()[:8]})
+	}
+	return members, nil
+}
+
+func (s *stubGroupService) ListMemberGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	var groups []*models.Group
+	for _, id := range s.memberGroups[groupID] {
+		groups = append(groups, s.groups[id])
+	}
+	return groups, nil
+}
+
+// stubUserRepository serves a fixed set of users
+type stubUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *stubUserRepository) LockByID(ctx context.Context, id uuid.UUID) error {
+	_, err := r.GetByID(ctx, id)
+	return err
+}
+
+func TestProvisioningService_GroupMembersRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	user := &models.User{ID: uuid.New(), TenantID: &tenantID}
+	groups := newStubGroupService()
+	service := NewProvisioningService(nil, groups, &stubUserRepository{users: map[uuid.UUID]*models.User{user.ID: user}}, nil, nil, nil)
+
+	team, err := service.CreateGroup(ctx, tenantID, &models.SCIMGroup{DisplayName: "team"})
+	require.NoError(t, err)
+
+	// The untyped member resolves to a user, the typed one to a nested group
+	created, err := service.CreateGroup(ctx, tenantID, &models.SCIMGroup{
+		DisplayName: "department",
+		ExternalID:  "dept-1",
+		Members: []models.SCIMGroupMember{
+			{Value: user.ID.String()},
+			{Value: team.ID, Type: "Group"},
+		},
+	})
+	require.NoError(t, err)
+
+	fetched, err := service.GetGroup(ctx, tenantID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "dept-1", fetched.ExternalID)
+	require.Len(t, fetched.Members, 2)
+	assert.Equal(t, user.ID.String(), fetched.Members[0].Value)
+	assert.Equal(t, "User", fetched.Members[0].Type)
+	assert.Equal(t, "/scim/v2/Users/"+user.ID.String(), fetched.Members[0].Ref)
+	assert.Equal(t, team.ID, fetched.Members[1].Value)
+	assert.Equal(t, "Group", fetched.Members[1].Type)
+	assert.Equal(t, "team", fetched.Members[1].Display)
+
+	// Groups of other tenants are not visible
+	_, err = service.GetGroup(ctx, uuid.New(), created.ID)
+	assert.Error(t, err)
+}
+
+// recordingUserService records the repository filters it is listed with
+type recordingUserService struct {
+	user.ServiceInterface
+	filters *interfaces.UserFilters
+}
+
+func (s *recordingUserService) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.UserFilters) ([]*models.User, error) {
+	s.filters = filters
+	return nil, nil
+}
+
+func (s *recordingUserService) Count(ctx context.Context, tenantID uuid.UUID, filters *interfaces.UserFilters) (int, error) {
+	return 0, nil
+}
+
+func TestProvisioningService_ListUsers_TranslatesParameters(t *testing.T) {
+	users := &recordingUserService{}
+	service := NewProvisioningService(users, nil, nil, nil, nil, nil)
+
+	_, _, err := service.ListUsers(context.Background(), uuid.New(), &UserFilters{
+		Filter:     `userName sw "j" and not (active eq true)`,
+		SortBy:     "meta.created",
+		SortOrder:  "descending",
+		StartIndex: 11,
+		Count:      10,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, users.filters.Filter)
+	assert.Equal(t, interfaces.FilterAnd, users.filters.Filter.Op)
+	assert.Equal(t, interfaces.UserFieldCreatedAt, users.filters.SortBy)
+	assert.True(t, users.filters.SortDescending)
+	assert.Equal(t, 10, *users.filters.Offset, "startIndex is 1-based")
+	assert.Equal(t, 10, users.filters.PageSize)
+
+	_, _, err = service.ListUsers(context.Background(), uuid.New(), &UserFilters{Filter: `userName eq`, StartIndex: 1, Count: 10})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid filter")
+}
+
+func TestProvisioningService_PatchGroup(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	alice := &models.User{ID: uuid.New(), TenantID: &tenantID}
+	bob := &models.User{ID: uuid.New(), TenantID: &tenantID}
+	groups := newStubGroupService()
+	service := NewProvisioningService(nil, groups, &stubUserRepository{users: map[uuid.UUID]*models.User{alice.ID: alice, bob.ID: bob}}, nil, nil, nil)
+
+	created, err := service.CreateGroup(ctx, tenantID, &models.SCIMGroup{
+		DisplayName: "team",
+		Members:     []models.SCIMGroupMember{{Value: alice.ID.String()}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.Meta.Version)
+
+	patched, err := service.PatchGroup(ctx, tenantID, created.ID, &PatchRequest{Operations: []PatchOperation{
+		{Op: "add", Path: "members", Value: []interface{}{map[string]interface{}{"value": bob.ID.String()}}},
+		{Op: "remove", Path: `members[value eq "` + alice.ID.String() + `"]`},
+		{Op: "replace", Path: "displayName", Value: "squad"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "squad", patched.DisplayName)
+	require.Len(t, patched.Members, 1)
+	assert.Equal(t, bob.ID.String(), patched.Members[0].Value)
+	assert.NotEqual(t, created.Meta.Version, patched.Meta.Version)
+
+	_, err = service.PatchGroup(ctx, tenantID, created.ID, &PatchRequest{Operations: []PatchOperation{
+		{Op: "replace", Path: "meta.version", Value: "x"},
+	}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mutability")
+}
+
+func TestProvisioningService_CheckGroupVersion(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	groups := newStubGroupService()
+	service := NewProvisioningService(nil, groups, &stubUserRepository{users: map[uuid.UUID]*models.User{}}, nil, nil, nil)
+
+	created, err := service.CreateGroup(ctx, tenantID, &models.SCIMGroup{DisplayName: "team"})
+	require.NoError(t, err)
+
+	require.NoError(t, service.CheckGroupVersion(ctx, tenantID, created.ID, created.Meta.Version))
+
+	err = service.CheckGroupVersion(ctx, tenantID, created.ID, `W/"stale"`)
+	require.Error(t, err)
+	status, _ := ErrorStatus(err, 400)
+	assert.Equal(t, 412, status)
+
+	// Every check locks the group
+	assert.Equal(t, []uuid.UUID{uuid.MustParse(created.ID), uuid.MustParse(created.ID)}, groups.locked)
+
+	err = service.CheckGroupVersion(ctx, uuid.New(), created.ID, created.Meta.Version)
+	require.Error(t, err)
+	status, _ = ErrorStatus(err, 400)
+	assert.Equal(t, 404, status)
+}
@@ -0,0 +1,210 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// MaxSchemaAttributes is the most attributes one extension schema may define
+const MaxSchemaAttributes = 50
+
+var (
+	// schemaURNPattern accepts URNs such as "urn:example:scim:schemas:extension:acme:1.0:User"
+	schemaURNPattern = regexp.MustCompile(`^urn:[A-Za-z0-9][A-Za-z0-9-]*(:[A-Za-z0-9()+,\-.=@;$_!*'%/?#]+)+$`)
+
+	// attributeNamePattern is ATTRNAME from RFC 7643 section 2.1
+	attributeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+	attributeTypes = map[string]bool{
+		models.SCIMTypeString:    true,
+		models.SCIMTypeBoolean:   true,
+		models.SCIMTypeInteger:   true,
+		models.SCIMTypeDecimal:   true,
+		models.SCIMTypeDateTime:  true,
+		models.SCIMTypeReference: true,
+	}
+
+	attributeMutabilities = map[string]bool{
+		models.SCIMMutabilityReadWrite: true,
+		models.SCIMMutabilityReadOnly:  true,
+		models.SCIMMutabilityImmutable: true,
+		models.SCIMMutabilityWriteOnly: true,
+	}
+)
+
+// SchemaService manages tenant-defined extension schemas of the SCIM User resource
+type SchemaService struct {
+	schemaRepo interfaces.SCIMSchemaRepository
+}
+
+// NewSchemaService creates a new SCIM extension schema service
+func NewSchemaService(schemaRepo interfaces.SCIMSchemaRepository) SchemaServiceInterface {
+	return &SchemaService{schemaRepo: schemaRepo}
+}
+
+// Create registers an extension schema
+func (s *SchemaService) Create(ctx context.Context, req *CreateSchemaRequest) (*models.SCIMSchemaExtension, error) {
+	extension := &models.SCIMSchemaExtension{
+		TenantID:    req.TenantID,
+		SchemaURN:   strings.TrimSpace(req.Schema),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Required:    req.Required,
+		Attributes:  req.Attributes,
+		CreatedBy:   req.CreatedBy,
+	}
+	if err := s.validate(ctx, extension); err != nil {
+		return nil, err
+	}
+
+	if err := s.schemaRepo.Create(ctx, extension); err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return nil, fmt.Errorf("SCIM extension schema %s already exists", extension.SchemaURN)
+		}
+		return nil, fmt.Errorf("failed to create SCIM extension schema: %w", err)
+	}
+
+	return extension, nil
+}
+
+// GetByID retrieves an extension schema within a tenant
+func (s *SchemaService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMSchemaExtension, error) {
+	extension, err := s.schemaRepo.GetByID(ctx, id)
+	if err != nil || extension.TenantID != tenantID {
+		return nil, fmt.Errorf("SCIM extension schema not found")
+	}
+	return extension, nil
+}
+
+// List retrieves a tenant's extension schemas
+func (s *SchemaService) List(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMSchemaExtension, error) {
+	extensions, err := s.schemaRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SCIM extension schemas: %w", err)
+	}
+	return extensions, nil
+}
+
+// Update updates an extension schema. Values of attributes it no longer
+// defines stay in user metadata but are no longer returned over SCIM.
+func (s *SchemaService) Update(ctx context.Context, tenantID, id uuid.UUID, req *UpdateSchemaRequest) (*models.SCIMSchemaExtension, error) {
+	extension, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		extension.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		extension.Description = req.Description
+	}
+	if req.Required != nil {
+		extension.Required = *req.Required
+	}
+	if req.Attributes != nil {
+		extension.Attributes = req.Attributes
+	}
+	if err := s.validate(ctx, extension); err != nil {
+		return nil, err
+	}
+
+	if err := s.schemaRepo.Update(ctx, extension); err != nil {
+		return nil, fmt.Errorf("failed to update SCIM extension schema: %w", err)
+	}
+
+	return extension, nil
+}
+
+// Delete deletes an extension schema. Its values stay in user metadata.
+func (s *SchemaService) Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMSchemaExtension, error) {
+	extension, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.schemaRepo.Delete(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to delete SCIM extension schema: %w", err)
+	}
+	return extension, nil
+}
+
+// validate checks an extension schema and fills in attribute defaults.
+// Attribute names become metadata keys, so they must not clash with the
+// enterprise extension, the attributes ABAC derives from the user, or the
+// attributes of the tenant's other extension schemas.
+func (s *SchemaService) validate(ctx context.Context, extension *models.SCIMSchemaExtension) error {
+	urn := strings.ToLower(extension.SchemaURN)
+	if !schemaURNPattern.MatchString(extension.SchemaURN) {
+		return fmt.Errorf("invalid schema URN %q", extension.SchemaURN)
+	}
+	if strings.HasPrefix(urn, "urn:ietf:params:scim:") {
+		return fmt.Errorf("schema URN %s is reserved for SCIM standard schemas", extension.SchemaURN)
+	}
+	if extension.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(extension.Attributes) == 0 {
+		return fmt.Errorf("at least one attribute is required")
+	}
+	if len(extension.Attributes) > MaxSchemaAttributes {
+		return fmt.Errorf("a schema may define at most %d attributes", MaxSchemaAttributes)
+	}
+
+	others, err := s.schemaRepo.ListByTenant(ctx, extension.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list SCIM extension schemas: %w", err)
+	}
+	taken := make(map[string]string)
+	for _, other := range others {
+		if other.ID == extension.ID {
+			continue
+		}
+		if strings.ToLower(other.SchemaURN) == urn {
+			return fmt.Errorf("SCIM extension schema %s already exists", extension.SchemaURN)
+		}
+		for _, attr := range other.Attributes {
+			taken[strings.ToLower(attr.Name)] = other.SchemaURN
+		}
+	}
+
+	seen := make(map[string]bool, len(extension.Attributes))
+	for i := range extension.Attributes {
+		attr := &extension.Attributes[i]
+		name := strings.ToLower(attr.Name)
+		switch {
+		case !attributeNamePattern.MatchString(attr.Name):
+			return fmt.Errorf("invalid attribute name %q", attr.Name)
+		case reservedAttributeNames[name]:
+			return fmt.Errorf("attribute name %q is reserved", attr.Name)
+		case taken[name] != "":
+			return fmt.Errorf("attribute %q is already defined by schema %s", attr.Name, taken[name])
+		case seen[name]:
+			return fmt.Errorf("attribute %q is defined more than once", attr.Name)
+		}
+		seen[name] = true
+
+		if !attributeTypes[attr.Type] {
+			return fmt.Errorf("attribute %q has unsupported type %q", attr.Name, attr.Type)
+		}
+		if attr.Mutability == "" {
+			attr.Mutability = models.SCIMMutabilityReadWrite
+		}
+		if !attributeMutabilities[attr.Mutability] {
+			return fmt.Errorf("attribute %q has invalid mutability %q", attr.Name, attr.Mutability)
+		}
+		if len(attr.CanonicalValues) > 0 && attr.Type != models.SCIMTypeString {
+			return fmt.Errorf("attribute %q: canonical values are only supported for strings", attr.Name)
+		}
+		if attr.Required && attr.Mutability == models.SCIMMutabilityReadOnly {
+			return fmt.Errorf("attribute %q cannot be both required and read-only", attr.Name)
+		}
+	}
+
+	return nil
+}
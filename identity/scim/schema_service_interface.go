@@ -0,0 +1,37 @@
+package scim
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// SchemaServiceInterface defines the interface for tenant-defined SCIM extension schemas
+type SchemaServiceInterface interface {
+	Create(ctx context.Context, req *CreateSchemaRequest) (*models.SCIMSchemaExtension, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMSchemaExtension, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMSchemaExtension, error)
+	Update(ctx context.Context, tenantID, id uuid.UUID, req *UpdateSchemaRequest) (*models.SCIMSchemaExtension, error)
+	Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMSchemaExtension, error)
+}
+
+// CreateSchemaRequest represents a request to register an extension schema
+type CreateSchemaRequest struct {
+	TenantID    uuid.UUID                    `json:"-"`
+	CreatedBy   *uuid.UUID                   `json:"-"`
+	Schema      string                       `json:"schema" binding:"required,max=255"`
+	Name        string                       `json:"name" binding:"required,min=1,max=255"`
+	Description *string                      `json:"description,omitempty"`
+	Required    bool                         `json:"required,omitempty"`
+	Attributes  []models.SCIMSchemaAttribute `json:"attributes" binding:"required,min=1"`
+}
+
+// UpdateSchemaRequest represents a request to update an extension schema.
+// Attributes, when given, replace the schema's attributes.
+type UpdateSchemaRequest struct {
+	Name        *string                      `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string                      `json:"description,omitempty"`
+	Required    *bool                        `json:"required,omitempty"`
+	Attributes  []models.SCIMSchemaAttribute `json:"attributes,omitempty" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,133 @@
+package scim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySchemaRepository is an in-memory interfaces.SCIMSchemaRepository
+type memorySchemaRepository struct {
+	extensions []*models.SCIMSchemaExtension
+}
+
+func (r *memorySchemaRepository) Create(ctx context.Context, extension *models.SCIMSchemaExtension) error {
+	extension.ID = uuid.New()
+	r.extensions = append(r.extensions, extension)
+	return nil
+}
+
+func (r *memorySchemaRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMSchemaExtension, error) {
+	for _, ext := range r.extensions {
+		if ext.ID == id {
+			return ext, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (r *memorySchemaRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMSchemaExtension, error) {
+	var out []*models.SCIMSchemaExtension
+	for _, ext := range r.extensions {
+		if ext.TenantID == tenantID {
+			out = append(out, ext)
+		}
+	}
+	return out, nil
+}
+
+func (r *memorySchemaRepository) Update(ctx context.Context, extension *models.SCIMSchemaExtension) error {
+	return nil
+}
+
+func (r *memorySchemaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	for i, ext := range r.extensions {
+		if ext.ID == id {
+			r.extensions = append(r.extensions[:i], r.extensions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestSchemaService_Create(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	service := NewSchemaService(&memorySchemaRepository{})
+
+	created, err := service.Create(ctx, &CreateSchemaRequest{
+		TenantID:   tenantID,
+		Schema:     "urn:acme:scim:schemas:extension:hr:1.0:User",
+		Name:       "HR",
+		Attributes: []models.SCIMSchemaAttribute{{Name: "badgeNumber", Type: models.SCIMTypeString}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.SCIMMutabilityReadWrite, created.Attributes[0].Mutability, "mutability defaults to readWrite")
+
+	tests := []struct {
+		name       string
+		schema     string
+		attributes []models.SCIMSchemaAttribute
+		wantErr    string
+	}{
+		{"invalid URN", "acme:hr", []models.SCIMSchemaAttribute{{Name: "a", Type: "string"}}, "invalid schema URN"},
+		{"reserved URN", "urn:ietf:params:scim:schemas:extension:acme:User", []models.SCIMSchemaAttribute{{Name: "a", Type: "string"}}, "reserved for SCIM"},
+		{"duplicate URN", "urn:ACME:scim:schemas:extension:hr:1.0:User", []models.SCIMSchemaAttribute{{Name: "a", Type: "string"}}, "already exists"},
+		{"invalid attribute name", "urn:acme:ext", []models.SCIMSchemaAttribute{{Name: "1st", Type: "string"}}, "invalid attribute name"},
+		{"reserved attribute name", "urn:acme:ext", []models.SCIMSchemaAttribute{{Name: "Department", Type: "string"}}, "is reserved"},
+		{"attribute of another schema", "urn:acme:ext", []models.SCIMSchemaAttribute{{Name: "BadgeNumber", Type: "string"}}, "already defined by schema"},
+		{"duplicate attribute", "urn:acme:ext", []models.SCIMSchemaAttribute{{Name: "a", Type: "string"}, {Name: "A", Type: "string"}}, "more than once"},
+		{"unsupported type", "urn:acme:ext", []models.SCIMSchemaAttribute{{Name: "a", Type: "complex"}}, "unsupported type"},
+		{"invalid mutability", "urn:acme:ext", []models.SCIMSchemaAttribute{{Name: "a", Type: "string", Mutability: "sometimes"}}, "invalid mutability"},
+		{"canonical values on integer", "urn:acme:ext", []models.SCIMSchemaAttribute{{Name: "a", Type: "integer", CanonicalValues: []string{"1"}}}, "only supported for strings"},
+		{"required read-only", "urn:acme:ext", []models.SCIMSchemaAttribute{{Name: "a", Type: "string", Required: true, Mutability: "readOnly"}}, "required and read-only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Create(ctx, &CreateSchemaRequest{TenantID: tenantID, Schema: tt.schema, Name: "Ext", Attributes: tt.attributes})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	// Other tenants are unaffected by this tenant's URNs and attribute names
+	_, err = service.Create(ctx, &CreateSchemaRequest{
+		TenantID:   uuid.New(),
+		Schema:     "urn:acme:scim:schemas:extension:hr:1.0:User",
+		Name:       "HR",
+		Attributes: []models.SCIMSchemaAttribute{{Name: "badgeNumber", Type: models.SCIMTypeString}},
+	})
+	assert.NoError(t, err)
+}
+
+func TestSchemaService_UpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	service := NewSchemaService(&memorySchemaRepository{})
+
+	created, err := service.Create(ctx, &CreateSchemaRequest{
+		TenantID:   tenantID,
+		Schema:     "urn:acme:ext",
+		Name:       "Acme",
+		Attributes: []models.SCIMSchemaAttribute{{Name: "badgeNumber", Type: models.SCIMTypeString}},
+	})
+	require.NoError(t, err)
+
+	required := true
+	updated, err := service.Update(ctx, tenantID, created.ID, &UpdateSchemaRequest{Required: &required})
+	require.NoError(t, err, "an update may keep its own attribute names")
+	assert.True(t, updated.Required)
+
+	_, err = service.Update(ctx, uuid.New(), created.ID, &UpdateSchemaRequest{Required: &required})
+	assert.EqualError(t, err, "SCIM extension schema not found")
+
+	_, err = service.Delete(ctx, tenantID, created.ID)
+	require.NoError(t, err)
+	list, err := service.List(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/arauth-identity/iam/identity/models"
@@ -15,6 +16,22 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// DefaultTokenLifetime is how long a token is valid when it is created without an expiry
+const DefaultTokenLifetime = 365 * 24 * time.Hour
+
+// validScopes are the scopes a token can be granted. "users" and "groups"
+// grant read and write access to their resource type and "*" grants
+// everything; they predate the per-access scopes.
+var validScopes = map[string]bool{
+	"users.read":   true,
+	"users.write":  true,
+	"groups.read":  true,
+	"groups.write": true,
+	"users":        true,
+	"groups":       true,
+	"*":            true,
+}
+
 // TokenService provides SCIM token management
 type TokenService struct {
 	tokenRepo interfaces.SCIMTokenRepository
@@ -59,6 +76,23 @@ func verifyToken(token, hash string) bool {
 
 // CreateToken creates a new SCIM token
 func (s *TokenService) CreateToken(ctx context.Context, tenantID uuid.UUID, req *CreateTokenRequest) (*models.SCIMToken, string, error) {
+	if err := validateScopes(req.Scopes); err != nil {
+		return nil, "", err
+	}
+	if err := validateAllowedIPs(req.AllowedIPs); err != nil {
+		return nil, "", err
+	}
+
+	// Tokens always expire
+	expiresAt := time.Now().Add(DefaultTokenLifetime)
+	if req.ExpiresAt != nil {
+		parsed, err := parseExpiry(*req.ExpiresAt)
+		if err != nil {
+			return nil, "", err
+		}
+		expiresAt = parsed
+	}
+
 	// Generate token
 	plaintextToken, err := generateToken()
 	if err != nil {
@@ -74,20 +108,6 @@ func (s *TokenService) CreateToken(ctx context.Context, tenantID uuid.UUID, req
 	// Create lookup hash (SHA256 for fast lookup)
 	lookupHash := hashTokenForLookup(plaintextToken)
 
-	// Parse expires_at if provided
-	var expiresAt *time.Time
-	if req.ExpiresAt != nil {
-		parsed, err := time.Parse(time.RFC3339, *req.ExpiresAt)
-		if err != nil {
-			return nil, "", fmt.Errorf("invalid expires_at format: %w", err)
-		}
-		expiresAt = &parsed
-	}
-
-	// Get actor from context (if available)
-	var createdBy *uuid.UUID
-	// TODO: Extract from context if available
-
 	token := &models.SCIMToken{
 		ID:         uuid.New(),
 		TenantID:   tenantID,
@@ -95,8 +115,9 @@ func (s *TokenService) CreateToken(ctx context.Context, tenantID uuid.UUID, req
 		TokenHash:  tokenHash,
 		LookupHash: lookupHash,
 		Scopes:     req.Scopes,
-		ExpiresAt:  expiresAt,
-		CreatedBy:  createdBy,
+		AllowedIPs: req.AllowedIPs,
+		ExpiresAt:  &expiresAt,
+		CreatedBy:  req.CreatedBy,
 	}
 
 	if err := s.tokenRepo.Create(ctx, token); err != nil {
@@ -106,9 +127,14 @@ func (s *TokenService) CreateToken(ctx context.Context, tenantID uuid.UUID, req
 	return token, plaintextToken, nil
 }
 
-// GetToken retrieves a SCIM token by ID
-func (s *TokenService) GetToken(ctx context.Context, id uuid.UUID) (*models.SCIMToken, error) {
-	return s.tokenRepo.GetByID(ctx, id)
+// GetToken retrieves a tenant's SCIM token by ID. Tokens of other tenants
+// are reported as not found.
+func (s *TokenService) GetToken(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMToken, error) {
+	token, err := s.tokenRepo.GetByID(ctx, id)
+	if err != nil || token.TenantID != tenantID || token.IsDeleted() {
+		return nil, fmt.Errorf("token not found")
+	}
+	return token, nil
 }
 
 // ListTokens lists SCIM tokens for a tenant
@@ -116,12 +142,12 @@ func (s *TokenService) ListTokens(ctx context.Context, tenantID uuid.UUID) ([]*m
 	return s.tokenRepo.List(ctx, tenantID)
 }
 
-// UpdateToken updates a SCIM token
-func (s *TokenService) UpdateToken(ctx context.Context, id uuid.UUID, req *UpdateTokenRequest) (*models.SCIMToken, error) {
+// UpdateToken updates a tenant's SCIM token
+func (s *TokenService) UpdateToken(ctx context.Context, tenantID, id uuid.UUID, req *UpdateTokenRequest) (*models.SCIMToken, error) {
 	// Get existing token
-	token, err := s.tokenRepo.GetByID(ctx, id)
+	token, err := s.GetToken(ctx, tenantID, id)
 	if err != nil {
-		return nil, fmt.Errorf("token not found: %w", err)
+		return nil, err
 	}
 
 	// Update fields if provided
@@ -133,13 +159,23 @@ func (s *TokenService) UpdateToken(ctx context.Context, id uuid.UUID, req *Updat
 		if len(req.Scopes) == 0 {
 			return nil, fmt.Errorf("at least one scope is required")
 		}
+		if err := validateScopes(req.Scopes); err != nil {
+			return nil, err
+		}
 		token.Scopes = req.Scopes
 	}
 
+	if req.AllowedIPs != nil {
+		if err := validateAllowedIPs(req.AllowedIPs); err != nil {
+			return nil, err
+		}
+		token.AllowedIPs = req.AllowedIPs
+	}
+
 	if req.ExpiresAt != nil {
-		parsed, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		parsed, err := parseExpiry(*req.ExpiresAt)
 		if err != nil {
-			return nil, fmt.Errorf("invalid expires_at format: %w", err)
+			return nil, err
 		}
 		token.ExpiresAt = &parsed
 	}
@@ -152,8 +188,11 @@ func (s *TokenService) UpdateToken(ctx context.Context, id uuid.UUID, req *Updat
 	return token, nil
 }
 
-// DeleteToken deletes a SCIM token
-func (s *TokenService) DeleteToken(ctx context.Context, id uuid.UUID) error {
+// DeleteToken deletes a tenant's SCIM token
+func (s *TokenService) DeleteToken(ctx context.Context, tenantID, id uuid.UUID) error {
+	if _, err := s.GetToken(ctx, tenantID, id); err != nil {
+		return err
+	}
 	return s.tokenRepo.Delete(ctx, id)
 }
 
@@ -189,12 +228,12 @@ func (s *TokenService) ValidateToken(ctx context.Context, tokenString string) (*
 	return token, nil
 }
 
-// RotateToken rotates a SCIM token, returning the new plaintext token
-func (s *TokenService) RotateToken(ctx context.Context, id uuid.UUID) (*models.SCIMToken, string, error) {
+// RotateToken rotates a tenant's SCIM token, returning the new plaintext token
+func (s *TokenService) RotateToken(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMToken, string, error) {
 	// Get existing token
-	token, err := s.tokenRepo.GetByID(ctx, id)
+	token, err := s.GetToken(ctx, tenantID, id)
 	if err != nil {
-		return nil, "", fmt.Errorf("token not found: %w", err)
+		return nil, "", err
 	}
 
 	// Generate new token
@@ -227,3 +266,38 @@ func (s *TokenService) RotateToken(ctx context.Context, id uuid.UUID) (*models.S
 
 	return token, plaintextToken, nil
 }
+
+// validateScopes checks that every scope is one a token can be granted
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !validScopes[scope] {
+			return fmt.Errorf("invalid scope %q: valid scopes are users.read, users.write, groups.read, groups.write, users, groups and *", scope)
+		}
+	}
+	return nil
+}
+
+// validateAllowedIPs checks that every allowlist entry is an IP address or CIDR range
+func validateAllowedIPs(allowed []string) error {
+	for _, entry := range allowed {
+		if _, _, err := net.ParseCIDR(entry); err == nil {
+			continue
+		}
+		if net.ParseIP(entry) == nil {
+			return fmt.Errorf("invalid allowed_ips entry %q: expected an IP address or CIDR range", entry)
+		}
+	}
+	return nil
+}
+
+// parseExpiry parses an expiry time, which must be in the future
+func parseExpiry(value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expires_at format: %w", err)
+	}
+	if !parsed.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("expires_at must be in the future")
+	}
+	return parsed, nil
+}
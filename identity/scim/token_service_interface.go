@@ -12,20 +12,20 @@ type TokenServiceInterface interface {
 	// CreateToken creates a new SCIM token
 	CreateToken(ctx context.Context, tenantID uuid.UUID, req *CreateTokenRequest) (*models.SCIMToken, string, error) // Returns token and plaintext token
 
-	// GetToken retrieves a SCIM token by ID
-	GetToken(ctx context.Context, id uuid.UUID) (*models.SCIMToken, error)
+	// GetToken retrieves a tenant's SCIM token by ID
+	GetToken(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMToken, error)
 
 	// ListTokens lists SCIM tokens for a tenant
 	ListTokens(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMToken, error)
 
-	// UpdateToken updates a SCIM token
-	UpdateToken(ctx context.Context, id uuid.UUID, req *UpdateTokenRequest) (*models.SCIMToken, error)
+	// UpdateToken updates a tenant's SCIM token
+	UpdateToken(ctx context.Context, tenantID, id uuid.UUID, req *UpdateTokenRequest) (*models.SCIMToken, error)
 
-	// DeleteToken deletes a SCIM token
-	DeleteToken(ctx context.Context, id uuid.UUID) error
+	// DeleteToken deletes a tenant's SCIM token
+	DeleteToken(ctx context.Context, tenantID, id uuid.UUID) error
 
-	// RotateToken rotates a SCIM token, returning the new plaintext token
-	RotateToken(ctx context.Context, id uuid.UUID) (*models.SCIMToken, string, error)
+	// RotateToken rotates a tenant's SCIM token, returning the new plaintext token
+	RotateToken(ctx context.Context, tenantID, id uuid.UUID) (*models.SCIMToken, string, error)
 
 	// ValidateToken validates a SCIM token and returns the token if valid
 	ValidateToken(ctx context.Context, tokenString string) (*models.SCIMToken, error)
@@ -33,14 +33,17 @@ type TokenServiceInterface interface {
 
 // CreateTokenRequest represents a request to create a SCIM token
 type CreateTokenRequest struct {
-	Name      string   `json:"name" binding:"required"`
-	Scopes    []string `json:"scopes" binding:"required,min=1"`
-	ExpiresAt *string  `json:"expires_at,omitempty"` // ISO 8601 format
+	Name       string     `json:"name" binding:"required"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1"`
+	AllowedIPs []string   `json:"allowed_ips,omitempty"` // IP addresses and CIDR ranges
+	ExpiresAt  *string    `json:"expires_at,omitempty"`  // ISO 8601 format; defaults to DefaultTokenLifetime from now
+	CreatedBy  *uuid.UUID `json:"-"`
 }
 
 // UpdateTokenRequest represents a request to update a SCIM token
 type UpdateTokenRequest struct {
-	Name      *string  `json:"name,omitempty"`
-	Scopes    []string `json:"scopes,omitempty"`
-	ExpiresAt *string  `json:"expires_at,omitempty"` // ISO 8601 format
+	Name       *string  `json:"name,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+	AllowedIPs []string `json:"allowed_ips,omitempty"` // An empty list removes the allowlist
+	ExpiresAt  *string  `json:"expires_at,omitempty"`  // ISO 8601 format
 }
@@ -3,10 +3,12 @@ package scim
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/arauth-identity/iam/identity/models"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTokenService_CreateToken(t *testing.T) {
@@ -38,11 +40,13 @@ func TestTokenService_CreateToken(t *testing.T) {
 func TestTokenService_RotateToken(t *testing.T) {
 	mockRepo := &MockSCIMTokenRepository{}
 	service := NewTokenService(mockRepo)
+	tenantID := uuid.New()
 
 	t.Run("success", func(t *testing.T) {
 		tokenID := uuid.New()
 		existingToken := &models.SCIMToken{
 			ID:        tokenID,
+			TenantID:  tenantID,
 			Name:      "Old Token",
 			TokenHash: "old-hash",
 		}
@@ -61,7 +65,7 @@ func TestTokenService_RotateToken(t *testing.T) {
 			return nil
 		}
 
-		rotatedToken, plaintext, err := service.RotateToken(context.Background(), tokenID)
+		rotatedToken, plaintext, err := service.RotateToken(context.Background(), tenantID, tokenID)
 		assert.NoError(t, err)
 		assert.NotNil(t, rotatedToken)
 		assert.NotEmpty(t, plaintext)
@@ -74,8 +78,83 @@ func TestTokenService_RotateToken(t *testing.T) {
 			return nil, assert.AnError
 		}
 
-		_, _, err := service.RotateToken(context.Background(), tokenID)
+		_, _, err := service.RotateToken(context.Background(), tenantID, tokenID)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "token not found")
 	})
 }
+
+func TestTokenService_CreateToken_Restrictions(t *testing.T) {
+	mockRepo := &MockSCIMTokenRepository{}
+	service := NewTokenService(mockRepo)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	token, _, err := service.CreateToken(ctx, tenantID, &CreateTokenRequest{
+		Name:       "Okta",
+		Scopes:     []string{"users.read", "users.write"},
+		AllowedIPs: []string{"203.0.113.0/24", "2001:db8::1"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, token.ExpiresAt, "tokens always expire")
+	assert.WithinDuration(t, time.Now().Add(DefaultTokenLifetime), *token.ExpiresAt, time.Minute)
+	assert.Equal(t, []string{"203.0.113.0/24", "2001:db8::1"}, token.AllowedIPs)
+
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		name    string
+		req     CreateTokenRequest
+		wantErr string
+	}{
+		{"unknown scope", CreateTokenRequest{Scopes: []string{"users.delete"}}, `invalid scope "users.delete"`},
+		{"bad allowlist entry", CreateTokenRequest{Scopes: []string{"users"}, AllowedIPs: []string{"10.0.0.0/33"}}, `invalid allowed_ips entry "10.0.0.0/33"`},
+		{"expiry in the past", CreateTokenRequest{Scopes: []string{"users"}, ExpiresAt: &past}, "expires_at must be in the future"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Name = tt.name
+			_, _, err := service.CreateToken(ctx, tenantID, &tt.req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTokenService_TenantBound(t *testing.T) {
+	ownerID := uuid.New()
+	token := &models.SCIMToken{ID: uuid.New(), TenantID: ownerID, Name: "Okta", Scopes: []string{"users"}}
+	var deleted, updated bool
+	mockRepo := &MockSCIMTokenRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.SCIMToken, error) {
+			copied := *token
+			return &copied, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			deleted = true
+			return nil
+		},
+		UpdateFunc: func(ctx context.Context, token *models.SCIMToken) error {
+			updated = true
+			return nil
+		},
+	}
+	service := NewTokenService(mockRepo)
+	ctx := context.Background()
+	otherTenant := uuid.New()
+
+	_, err := service.GetToken(ctx, otherTenant, token.ID)
+	assert.EqualError(t, err, "token not found")
+	_, _, err = service.RotateToken(ctx, otherTenant, token.ID)
+	assert.EqualError(t, err, "token not found")
+	_, err = service.UpdateToken(ctx, otherTenant, token.ID, &UpdateTokenRequest{Scopes: []string{"*"}})
+	assert.EqualError(t, err, "token not found")
+	assert.EqualError(t, service.DeleteToken(ctx, otherTenant, token.ID), "token not found")
+	assert.False(t, deleted)
+	assert.False(t, updated)
+
+	restricted, err := service.UpdateToken(ctx, ownerID, token.ID, &UpdateTokenRequest{AllowedIPs: []string{"10.0.0.1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, restricted.AllowedIPs)
+	assert.NoError(t, service.DeleteToken(ctx, ownerID, token.ID))
+	assert.True(t, deleted)
+}
@@ -0,0 +1,42 @@
+package scim
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// resourceVersion returns the weak ETag of a resource: a hash of its JSON
+// form without meta, so it changes exactly when the representation does
+func resourceVersion(resource interface{}) string {
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return ""
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return ""
+	}
+	delete(doc, "meta")
+	// Maps marshal with sorted keys, so equal resources hash equally
+	raw, _ = json.Marshal(doc)
+	sum := sha256.Sum256(raw)
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// ETagMatches reports whether an If-Match or If-None-Match header value
+// matches version. The header may be "*" or a comma-separated list of ETags,
+// compared weakly.
+func ETagMatches(header, version string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return version != ""
+	}
+	want := strings.TrimPrefix(version, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
@@ -10,8 +10,10 @@ import (
 type Session struct {
 	ID              uuid.UUID  `json:"id"`
 	UserID          uuid.UUID  `json:"user_id"`
+	TenantID        uuid.UUID  `json:"-"`
 	Username        string     `json:"username"`
 	DeviceInfo      string     `json:"device_info,omitempty"`
+	UserAgent       string     `json:"user_agent,omitempty"`
 	IPAddress       string     `json:"ip_address,omitempty"`
 	CreatedAt       time.Time  `json:"created_at"`
 	ExpiresAt       time.Time  `json:"expires_at"`
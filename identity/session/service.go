@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/google/uuid"
@@ -37,6 +38,7 @@ func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID, tenantID u
 	}
 
 	// Filter by tenant and map to sessions
+	now := time.Now()
 	sessions := make([]*Session, 0)
 	for _, token := range tokens {
 		// Tenant isolation: only include tokens for the specified tenant
@@ -44,8 +46,8 @@ func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID, tenantID u
 			continue
 		}
 
-		// Skip revoked tokens
-		if token.RevokedAt != nil {
+		// Skip revoked and expired tokens
+		if token.RevokedAt != nil || now.After(token.ExpiresAt) {
 			continue
 		}
 
@@ -58,14 +60,21 @@ func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID, tenantID u
 
 // GetSessionByID retrieves a session by its ID
 func (s *Service) GetSessionByID(ctx context.Context, sessionID uuid.UUID) (*Session, error) {
-	// Get the refresh token by ID
-	// Note: RefreshTokenRepository doesn't have GetByID, so we need to get by user and filter
-	// This is a limitation we'll work around for now
-	// In production, you'd add GetByID to the repository interface
-
-	// For now, we'll return an error indicating this needs implementation
-	// The handler will need to verify ownership differently
-	return nil, fmt.Errorf("GetSessionByID not yet implemented - use ListSessions and filter")
+	token, err := s.refreshTokenRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	if token.RevokedAt != nil {
+		return nil, fmt.Errorf("session not found: session has been revoked")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.mapTokenToSession(token, user.Username), nil
 }
 
 // RevokeSession revokes a session by its ID
@@ -83,9 +92,14 @@ func (s *Service) mapTokenToSession(token *interfaces.RefreshToken, username str
 	session := &Session{
 		ID:              token.ID,
 		UserID:          token.UserID,
+		TenantID:        token.TenantID,
 		Username:        username,
+		DeviceInfo:      token.DeviceInfo,
+		UserAgent:       token.UserAgent,
+		IPAddress:       token.IPAddress,
 		CreatedAt:       token.CreatedAt,
 		ExpiresAt:       token.ExpiresAt,
+		LastUsedAt:      token.LastUsedAt,
 		RememberMe:      token.RememberMe,
 		MFAVerified:     token.MFAVerified,
 		IsImpersonation: false, // Default to false
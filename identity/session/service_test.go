@@ -22,6 +22,14 @@ func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *interfac
 	return args.Error(0)
 }
 
+func (m *MockRefreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.RefreshToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*interfaces.RefreshToken), args.Error(1)
+}
+
 func (m *MockRefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*interfaces.RefreshToken, error) {
 	args := m.Called(ctx, tokenHash)
 	if args.Get(0) == nil {
@@ -76,6 +84,11 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) LockByID(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Create(ctx context.Context, user *models.User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
@@ -86,6 +99,11 @@ func (m *MockUserRepository) Update(ctx context.Context, user *models.User) erro
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	args := m.Called(ctx, id, loginAt)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -261,3 +279,103 @@ func TestRevokeSession(t *testing.T) {
 	assert.NoError(t, err)
 	mockRefreshTokenRepo.AssertExpectations(t)
 }
+
+// TestListSessions_DeviceMetadata tests that device metadata is exposed on sessions
+func TestListSessions_DeviceMetadata(t *testing.T) {
+	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+
+	userID := uuid.New()
+	tenantID := uuid.New()
+	now := time.Now()
+	lastUsed := now.Add(-5 * time.Minute)
+
+	tokens := []*interfaces.RefreshToken{
+		{
+			ID:         uuid.New(),
+			UserID:     userID,
+			TenantID:   tenantID,
+			IPAddress:  "203.0.113.7",
+			UserAgent:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) Chrome/120.0.0.0 Safari/537.36",
+			DeviceInfo: "Chrome 120 on macOS",
+			LastUsedAt: &lastUsed,
+			CreatedAt:  now,
+			ExpiresAt:  now.Add(24 * time.Hour),
+		},
+		{
+			ID:        uuid.New(),
+			UserID:    userID,
+			TenantID:  tenantID,
+			CreatedAt: now.Add(-48 * time.Hour),
+			ExpiresAt: now.Add(-24 * time.Hour), // Expired - should be filtered out
+		},
+	}
+
+	mockRefreshTokenRepo.On("GetByUserID", mock.Anything, userID).Return(tokens, nil)
+	mockUserRepo.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID, Username: "testuser"}, nil)
+
+	service := NewService(mockRefreshTokenRepo, mockUserRepo)
+
+	sessions, err := service.ListSessions(context.Background(), userID, tenantID)
+
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "203.0.113.7", sessions[0].IPAddress)
+	assert.Equal(t, "Chrome 120 on macOS", sessions[0].DeviceInfo)
+	assert.Equal(t, tokens[0].UserAgent, sessions[0].UserAgent)
+	assert.Equal(t, &lastUsed, sessions[0].LastUsedAt)
+}
+
+// TestGetSessionByID tests retrieving a single session
+func TestGetSessionByID(t *testing.T) {
+	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+
+	userID := uuid.New()
+	tenantID := uuid.New()
+	sessionID := uuid.New()
+	now := time.Now()
+
+	mockRefreshTokenRepo.On("GetByID", mock.Anything, sessionID).Return(&interfaces.RefreshToken{
+		ID:        sessionID,
+		UserID:    userID,
+		TenantID:  tenantID,
+		IPAddress: "198.51.100.1",
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}, nil)
+	mockUserRepo.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID, Username: "testuser"}, nil)
+
+	service := NewService(mockRefreshTokenRepo, mockUserRepo)
+
+	session, err := service.GetSessionByID(context.Background(), sessionID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, sessionID, session.ID)
+	assert.Equal(t, tenantID, session.TenantID)
+	assert.Equal(t, "198.51.100.1", session.IPAddress)
+}
+
+// TestGetSessionByID_Revoked tests that revoked sessions are not returned
+func TestGetSessionByID_Revoked(t *testing.T) {
+	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+
+	sessionID := uuid.New()
+	now := time.Now()
+
+	mockRefreshTokenRepo.On("GetByID", mock.Anything, sessionID).Return(&interfaces.RefreshToken{
+		ID:        sessionID,
+		UserID:    uuid.New(),
+		RevokedAt: &now,
+		ExpiresAt: now.Add(time.Hour),
+	}, nil)
+
+	service := NewService(mockRefreshTokenRepo, mockUserRepo)
+
+	session, err := service.GetSessionByID(context.Background(), sessionID)
+
+	assert.Error(t, err)
+	assert.Nil(t, session)
+	mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,398 @@
+package sod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arauth-identity/iam/identity/group"
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/role"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// Service manages static separation-of-duties rules and checks role
+// assignments against them. It satisfies role.AssignmentChecker.
+type Service struct {
+	ruleRepo  interfaces.SoDRuleRepository
+	roleRepo  interfaces.RoleRepository
+	groupRepo interfaces.GroupRepository
+}
+
+// NewService creates a new separation-of-duties service.
+// groupRepo may be nil, in which case only roles assigned directly to users count.
+func NewService(ruleRepo interfaces.SoDRuleRepository, roleRepo interfaces.RoleRepository, groupRepo interfaces.GroupRepository) *Service {
+	return &Service{
+		ruleRepo:  ruleRepo,
+		roleRepo:  roleRepo,
+		groupRepo: groupRepo,
+	}
+}
+
+// CreateRuleRequest represents a request to create a rule
+type CreateRuleRequest struct {
+	TenantID    uuid.UUID   `json:"-"`
+	CreatedBy   *uuid.UUID  `json:"-"`
+	Name        string      `json:"name" binding:"required,min=1,max=255"`
+	Description *string     `json:"description,omitempty"`
+	RoleIDs     []uuid.UUID `json:"role_ids" binding:"required,min=2"`
+	MaxRoles    int         `json:"max_roles,omitempty" binding:"omitempty,min=1"` // Defaults to 1
+}
+
+// UpdateRuleRequest represents a request to update a rule
+type UpdateRuleRequest struct {
+	Name        *string     `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string     `json:"description,omitempty"`
+	RoleIDs     []uuid.UUID `json:"role_ids,omitempty" binding:"omitempty,min=2"`
+	MaxRoles    *int        `json:"max_roles,omitempty" binding:"omitempty,min=1"`
+}
+
+// Create creates a rule. Users who already break it are not touched; they
+// show up in Violations.
+func (s *Service) Create(ctx context.Context, req *CreateRuleRequest) (*models.SoDRule, error) {
+	rule := &models.SoDRule{
+		TenantID:    req.TenantID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		RoleIDs:     req.RoleIDs,
+		MaxRoles:    req.MaxRoles,
+		CreatedBy:   req.CreatedBy,
+	}
+	if rule.MaxRoles == 0 {
+		rule.MaxRoles = 1
+	}
+	if err := s.validate(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return nil, fmt.Errorf("separation of duties rule %q already exists", rule.Name)
+		}
+		return nil, fmt.Errorf("failed to create separation of duties rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetByID retrieves a rule within a tenant
+func (s *Service) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SoDRule, error) {
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil || rule.TenantID != tenantID {
+		return nil, fmt.Errorf("separation of duties rule not found")
+	}
+	return rule, nil
+}
+
+// List retrieves a tenant's rules
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID) ([]*models.SoDRule, error) {
+	rules, err := s.ruleRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list separation of duties rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Update updates a rule
+func (s *Service) Update(ctx context.Context, tenantID, id uuid.UUID, req *UpdateRuleRequest) (*models.SoDRule, error) {
+	rule, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		rule.Description = req.Description
+	}
+	if req.RoleIDs != nil {
+		rule.RoleIDs = req.RoleIDs
+	}
+	if req.MaxRoles != nil {
+		rule.MaxRoles = *req.MaxRoles
+	}
+	if err := s.validate(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return nil, fmt.Errorf("separation of duties rule %q already exists", rule.Name)
+		}
+		return nil, fmt.Errorf("failed to update separation of duties rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// Delete deletes a rule
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SoDRule, error) {
+	rule, err := s.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Delete(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to delete separation of duties rule: %w", err)
+	}
+	return rule, nil
+}
+
+// LockAssignments makes other checks in the tenant wait until the context's
+// transaction ends. Roles, groups and inheritance all reach users, so the
+// lock covers the whole tenant. A tenant without rules is not locked.
+func (s *Service) LockAssignments(ctx context.Context, tenantID uuid.UUID) error {
+	rules, err := s.ruleRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list separation of duties rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return s.ruleRepo.LockTenant(ctx, tenantID)
+}
+
+// CheckAssignment returns an error if giving the user roleIDs, on top of the
+// roles they already hold directly, through groups or by inheritance, would
+// break one of the tenant's rules. The roles' own parent roles count as
+// added. Only rules covering a newly added role are checked, so a user who
+// already breaks a rule can still be given unrelated roles. userID may be
+// uuid.Nil for a user who does not exist yet.
+//
+// In a tenant with rules the check takes the LockAssignments lock before
+// reading what the user holds, so it must run in the transaction that makes
+// the assignment. Concurrent assignments then wait for it to commit and are
+// checked against it.
+func (s *Service) CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	rules, err := s.ruleRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list separation of duties rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	if err := s.ruleRepo.LockTenant(ctx, tenantID); err != nil {
+		return err
+	}
+
+	held, err := s.heldRoles(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+
+	// A new role brings every role it inherits from
+	added, err := s.expandRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return err
+	}
+	adding := make(map[uuid.UUID]bool, len(added))
+	for _, id := range added {
+		if !held[id] {
+			adding[id] = true
+		}
+	}
+	if len(adding) == 0 {
+		return nil
+	}
+
+	for _, rule := range rules {
+		count, added := 0, false
+		for _, id := range rule.RoleIDs {
+			if held[id] || adding[id] {
+				count++
+			}
+			if adding[id] {
+				added = true
+			}
+		}
+		if added && count > rule.MaxRoles {
+			return fmt.Errorf("separation of duties violation: rule %q allows at most %d of its roles", rule.Name, rule.MaxRoles)
+		}
+	}
+
+	return nil
+}
+
+// CheckInheritance returns an error if making roleID inherit from parentRoleID
+// would make one of roleID's holders break a rule, counting users who hold it
+// directly, through groups or through a role inheriting from it. Like
+// CheckAssignment, it must run in the transaction that adds the parent.
+func (s *Service) CheckInheritance(ctx context.Context, tenantID, roleID, parentRoleID uuid.UUID) error {
+	rules, err := s.ruleRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list separation of duties rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	if err := s.ruleRepo.LockTenant(ctx, tenantID); err != nil {
+		return err
+	}
+
+	holders, err := s.ruleRepo.ListRoleHolders(ctx, tenantID, []uuid.UUID{roleID})
+	if err != nil {
+		return fmt.Errorf("failed to list role holders: %w", err)
+	}
+
+	checked := make(map[uuid.UUID]bool, len(holders))
+	for _, holder := range holders {
+		if checked[holder.UserID] {
+			continue
+		}
+		checked[holder.UserID] = true
+		if err := s.CheckAssignment(ctx, tenantID, holder.UserID, []uuid.UUID{parentRoleID}); err != nil {
+			return fmt.Errorf("%w (held by %s through role inheritance)", err, holder.Username)
+		}
+	}
+
+	return nil
+}
+
+// Violations lists users who hold more of a rule's roles than it allows, for one
+// rule or, when ruleID is nil, for every rule of the tenant. These are holdings
+// that predate the rule, since new assignments are checked.
+func (s *Service) Violations(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID) ([]*models.SoDViolation, error) {
+	var rules []*models.SoDRule
+	if ruleID != nil {
+		rule, err := s.GetByID(ctx, tenantID, *ruleID)
+		if err != nil {
+			return nil, err
+		}
+		rules = []*models.SoDRule{rule}
+	} else {
+		var err error
+		if rules, err = s.List(ctx, tenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	violations := make([]*models.SoDViolation, 0)
+	for _, rule := range rules {
+		holders, err := s.ruleRepo.ListRoleHolders(ctx, tenantID, rule.RoleIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list role holders: %w", err)
+		}
+
+		// Holders come ordered by username; keep that order in the report
+		byUser := make(map[uuid.UUID]*models.SoDViolation)
+		var order []uuid.UUID
+		for _, holder := range holders {
+			v, ok := byUser[holder.UserID]
+			if !ok {
+				v = &models.SoDViolation{
+					RuleID:   rule.ID,
+					RuleName: rule.Name,
+					MaxRoles: rule.MaxRoles,
+					UserID:   holder.UserID,
+					Username: holder.Username,
+				}
+				byUser[holder.UserID] = v
+				order = append(order, holder.UserID)
+			}
+			v.RoleIDs = append(v.RoleIDs, holder.RoleID)
+		}
+		for _, userID := range order {
+			if v := byUser[userID]; len(v.RoleIDs) > rule.MaxRoles {
+				violations = append(violations, v)
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// validate normalises a rule's role set and checks it against the tenant
+func (s *Service) validate(ctx context.Context, rule *models.SoDRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(rule.RoleIDs))
+	roleIDs := make([]uuid.UUID, 0, len(rule.RoleIDs))
+	for _, id := range rule.RoleIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		r, err := s.roleRepo.GetByID(ctx, id)
+		if err != nil || r.TenantID != rule.TenantID {
+			return fmt.Errorf("role %s not found", id)
+		}
+		roleIDs = append(roleIDs, id)
+	}
+	rule.RoleIDs = roleIDs
+
+	if len(rule.RoleIDs) < 2 {
+		return fmt.Errorf("a rule needs at least two distinct roles")
+	}
+	if rule.MaxRoles < 1 || rule.MaxRoles >= len(rule.RoleIDs) {
+		return fmt.Errorf("max_roles must be between 1 and %d", len(rule.RoleIDs)-1)
+	}
+
+	return nil
+}
+
+// heldRoles returns the IDs of the tenant roles a user holds directly, through
+// groups or by inheritance
+func (s *Service) heldRoles(ctx context.Context, tenantID, userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	held := make(map[uuid.UUID]bool)
+	if userID == uuid.Nil {
+		return held, nil
+	}
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	if s.groupRepo != nil {
+		groups, err := group.UserGroups(ctx, s.groupRepo, userID)
+		if err != nil {
+			return nil, err
+		}
+		groupRoles, err := group.GroupRoles(ctx, s.groupRepo, groups)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, groupRoles...)
+	}
+
+	roles, err = role.ExpandRoles(ctx, s.roleRepo, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range roles {
+		if r.TenantID == tenantID {
+			held[r.ID] = true
+		}
+	}
+
+	return held, nil
+}
+
+// expandRoleIDs returns roleIDs followed by every role they inherit from.
+// Roles that can't be loaded are kept as they are; the caller validates them.
+func (s *Service) expandRoleIDs(ctx context.Context, roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	roles := make([]*models.Role, 0, len(roleIDs))
+	var unknown []uuid.UUID
+	for _, id := range roleIDs {
+		r, err := s.roleRepo.GetByID(ctx, id)
+		if err != nil {
+			unknown = append(unknown, id)
+			continue
+		}
+		roles = append(roles, r)
+	}
+
+	expanded, err := role.ExpandRoles(ctx, s.roleRepo, roles)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(expanded)+len(unknown))
+	for _, r := range expanded {
+		ids = append(ids, r.ID)
+	}
+	return append(ids, unknown...), nil
+}
@@ -0,0 +1,20 @@
+package sod
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// ServiceInterface defines the interface for separation-of-duties operations
+type ServiceInterface interface {
+	Create(ctx context.Context, req *CreateRuleRequest) (*models.SoDRule, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.SoDRule, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]*models.SoDRule, error)
+	Update(ctx context.Context, tenantID, id uuid.UUID, req *UpdateRuleRequest) (*models.SoDRule, error)
+	Delete(ctx context.Context, tenantID, id uuid.UUID) (*models.SoDRule, error)
+	CheckAssignment(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) error
+	CheckInheritance(ctx context.Context, tenantID, roleID, parentRoleID uuid.UUID) error
+	Violations(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID) ([]*models.SoDViolation, error)
+}
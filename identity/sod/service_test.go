@@ -0,0 +1,435 @@
+package sod
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/role"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTxKey carries a memoryTx in a context
+type memoryTxKey struct{}
+
+// memoryTx is a transaction of memoryTxRunner. Locks taken in it are
+// released when it ends.
+type memoryTx struct {
+	held    map[uuid.UUID]bool
+	release []func()
+}
+
+// memoryTxRunner runs functions in a memoryTx
+type memoryTxRunner struct{}
+
+func (memoryTxRunner) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(memoryTxKey{}).(*memoryTx); ok {
+		return fn(ctx)
+	}
+	tx := &memoryTx{held: make(map[uuid.UUID]bool)}
+	defer func() {
+		for _, release := range tx.release {
+			release()
+		}
+	}()
+	return fn(context.WithValue(ctx, memoryTxKey{}, tx))
+}
+
+// memoryRuleRepository is an in-memory SoDRuleRepository
+type memoryRuleRepository struct {
+	rules   map[uuid.UUID]*models.SoDRule
+	holders []*interfaces.SoDRoleHolder
+
+	mu    sync.Mutex
+	locks map[uuid.UUID]*sync.Mutex
+}
+
+func newMemoryRuleRepository() *memoryRuleRepository {
+	return &memoryRuleRepository{rules: make(map[uuid.UUID]*models.SoDRule), locks: make(map[uuid.UUID]*sync.Mutex)}
+}
+
+// LockTenant holds the tenant's lock until the memoryTx in ctx ends. Like an
+// advisory transaction lock, it is released right away without one.
+func (r *memoryRuleRepository) LockTenant(ctx context.Context, tenantID uuid.UUID) error {
+	tx, ok := ctx.Value(memoryTxKey{}).(*memoryTx)
+	if !ok || tx.held[tenantID] {
+		return nil
+	}
+	r.mu.Lock()
+	lock, ok := r.locks[tenantID]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.locks[tenantID] = lock
+	}
+	r.mu.Unlock()
+
+	lock.Lock()
+	tx.held[tenantID] = true
+	tx.release = append(tx.release, lock.Unlock)
+	return nil
+}
+
+func (r *memoryRuleRepository) Create(ctx context.Context, rule *models.SoDRule) error {
+	for _, existing := range r.rules {
+		if existing.TenantID == rule.TenantID && existing.Name == rule.Name {
+			return fmt.Errorf("duplicate key value violates unique constraint")
+		}
+	}
+	rule.ID = uuid.New()
+	copied := *rule
+	r.rules[rule.ID] = &copied
+	return nil
+}
+
+func (r *memoryRuleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SoDRule, error) {
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil, fmt.Errorf("separation of duties rule not found")
+	}
+	copied := *rule
+	return &copied, nil
+}
+
+func (r *memoryRuleRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SoDRule, error) {
+	var out []*models.SoDRule
+	for _, rule := range r.rules {
+		if rule.TenantID == tenantID {
+			copied := *rule
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRuleRepository) Update(ctx context.Context, rule *models.SoDRule) error {
+	copied := *rule
+	r.rules[rule.ID] = &copied
+	return nil
+}
+
+func (r *memoryRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.rules, id)
+	return nil
+}
+
+func (r *memoryRuleRepository) ListRoleHolders(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) ([]*interfaces.SoDRoleHolder, error) {
+	wanted := make(map[uuid.UUID]bool)
+	for _, id := range roleIDs {
+		wanted[id] = true
+	}
+	var out []*interfaces.SoDRoleHolder
+	for _, h := range r.holders {
+		if wanted[h.RoleID] {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// stubRoleRepository keeps roles, direct assignments and inheritance in memory
+type stubRoleRepository struct {
+	interfaces.RoleRepository
+	roles     map[uuid.UUID]*models.Role
+	userRoles map[uuid.UUID][]*models.Role
+	parents   map[uuid.UUID][]*models.Role
+
+	mu       sync.Mutex
+	onAssign func() // Called before an assignment is stored
+}
+
+func (r *stubRoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	role, ok := r.roles[id]
+	if !ok {
+		return nil, fmt.Errorf("role not found")
+	}
+	return role, nil
+}
+
+func (r *stubRoleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.userRoles[userID], nil
+}
+
+func (r *stubRoleRepository) AssignRoleToUser(ctx context.Context, userID, roleID uuid.UUID) error {
+	if r.onAssign != nil {
+		r.onAssign()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.userRoles[userID] = append(r.userRoles[userID], r.roles[roleID])
+	return nil
+}
+
+func (r *stubRoleRepository) GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	return r.parents[roleID], nil
+}
+
+// stubGroupRepository grants roles to users through one level of groups
+type stubGroupRepository struct {
+	interfaces.GroupRepository
+	userGroups map[uuid.UUID][]*models.Group
+	groupRoles map[uuid.UUID][]*models.Role
+}
+
+func (r *stubGroupRepository) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]*models.Group, error) {
+	return r.userGroups[userID], nil
+}
+
+func (r *stubGroupRepository) GetParentGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	return nil, nil
+}
+
+func (r *stubGroupRepository) GetGroupRoles(ctx context.Context, groupID uuid.UUID) ([]*models.Role, error) {
+	return r.groupRoles[groupID], nil
+}
+
+type fixture struct {
+	service  *Service
+	rules    *memoryRuleRepository
+	roles    *stubRoleRepository
+	groups   *stubGroupRepository
+	tenantID uuid.UUID
+	creator  *models.Role
+	approver *models.Role
+	auditor  *models.Role
+	viewer   *models.Role
+}
+
+func newFixture() *fixture {
+	tenantID := uuid.New()
+	newRole := func(name string) *models.Role {
+		return &models.Role{ID: uuid.New(), TenantID: tenantID, Name: name}
+	}
+	f := &fixture{
+		rules:    newMemoryRuleRepository(),
+		groups:   &stubGroupRepository{userGroups: map[uuid.UUID][]*models.Group{}, groupRoles: map[uuid.UUID][]*models.Role{}},
+		tenantID: tenantID,
+		creator:  newRole("payments_creator"),
+		approver: newRole("payments_approver"),
+		auditor:  newRole("payments_auditor"),
+		viewer:   newRole("viewer"),
+	}
+	f.roles = &stubRoleRepository{
+		roles:     map[uuid.UUID]*models.Role{},
+		userRoles: map[uuid.UUID][]*models.Role{},
+		parents:   map[uuid.UUID][]*models.Role{},
+	}
+	for _, r := range []*models.Role{f.creator, f.approver, f.auditor, f.viewer} {
+		f.roles.roles[r.ID] = r
+	}
+	f.service = NewService(f.rules, f.roles, f.groups)
+	return f
+}
+
+func (f *fixture) paymentsRule(t *testing.T) *models.SoDRule {
+	t.Helper()
+	rule, err := f.service.Create(context.Background(), &CreateRuleRequest{
+		TenantID: f.tenantID,
+		Name:     "payments",
+		RoleIDs:  []uuid.UUID{f.creator.ID, f.approver.ID},
+	})
+	require.NoError(t, err)
+	return rule
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	rule := f.paymentsRule(t)
+	assert.Equal(t, 1, rule.MaxRoles)
+
+	tests := []struct {
+		name string
+		req  *CreateRuleRequest
+		want string
+	}{
+		{"duplicate roles", &CreateRuleRequest{TenantID: f.tenantID, Name: "x", RoleIDs: []uuid.UUID{f.creator.ID, f.creator.ID}}, "at least two distinct roles"},
+		{"max too high", &CreateRuleRequest{TenantID: f.tenantID, Name: "x", RoleIDs: []uuid.UUID{f.creator.ID, f.approver.ID}, MaxRoles: 2}, "max_roles must be between 1 and 1"},
+		{"other tenant", &CreateRuleRequest{TenantID: uuid.New(), Name: "x", RoleIDs: []uuid.UUID{f.creator.ID, f.approver.ID}}, "not found"},
+		{"duplicate name", &CreateRuleRequest{TenantID: f.tenantID, Name: "payments", RoleIDs: []uuid.UUID{f.creator.ID, f.auditor.ID}}, "already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Create(ctx, tt.req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestService_CheckAssignment(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// No rules, nothing to check
+	require.NoError(t, f.service.CheckAssignment(ctx, f.tenantID, userID, []uuid.UUID{f.creator.ID, f.approver.ID}))
+
+	f.paymentsRule(t)
+	f.roles.userRoles[userID] = []*models.Role{f.creator}
+
+	err := f.service.CheckAssignment(ctx, f.tenantID, userID, []uuid.UUID{f.approver.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `separation of duties violation: rule "payments"`)
+
+	assert.NoError(t, f.service.CheckAssignment(ctx, f.tenantID, userID, []uuid.UUID{f.viewer.ID}))
+	assert.NoError(t, f.service.CheckAssignment(ctx, f.tenantID, userID, []uuid.UUID{f.creator.ID}), "re-assigning a held role")
+
+	// A new user is only checked against the roles being given
+	err = f.service.CheckAssignment(ctx, f.tenantID, uuid.Nil, []uuid.UUID{f.creator.ID, f.approver.ID})
+	require.Error(t, err)
+}
+
+func TestService_CheckAssignment_GroupRoles(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	f.paymentsRule(t)
+
+	userID := uuid.New()
+	finance := &models.Group{ID: uuid.New(), TenantID: f.tenantID, Name: "finance"}
+	f.groups.userGroups[userID] = []*models.Group{finance}
+	f.groups.groupRoles[finance.ID] = []*models.Role{f.approver}
+
+	err := f.service.CheckAssignment(ctx, f.tenantID, userID, []uuid.UUID{f.creator.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+}
+
+func TestService_CheckAssignment_ExistingViolation(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	f.paymentsRule(t)
+
+	// Held both before the rule existed: unrelated roles can still be given
+	userID := uuid.New()
+	f.roles.userRoles[userID] = []*models.Role{f.creator, f.approver}
+	assert.NoError(t, f.service.CheckAssignment(ctx, f.tenantID, userID, []uuid.UUID{f.viewer.ID}))
+}
+
+func TestService_CheckAssignment_InheritedRoles(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	f.paymentsRule(t)
+
+	// A role inheriting from approver counts as approver, whether it is held...
+	lead := &models.Role{ID: uuid.New(), TenantID: f.tenantID, Name: "finance_lead"}
+	f.roles.roles[lead.ID] = lead
+	f.roles.parents[lead.ID] = []*models.Role{f.approver}
+
+	userID := uuid.New()
+	f.roles.userRoles[userID] = []*models.Role{lead}
+	err := f.service.CheckAssignment(ctx, f.tenantID, userID, []uuid.UUID{f.creator.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+
+	// ...or being given
+	other := uuid.New()
+	f.roles.userRoles[other] = []*models.Role{f.creator}
+	err = f.service.CheckAssignment(ctx, f.tenantID, other, []uuid.UUID{lead.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+}
+
+func TestService_CheckAssignment_SerializesConcurrentAssignments(t *testing.T) {
+	f := newFixture()
+	f.paymentsRule(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	roles := role.NewService(f.roles, nil, nil, f.service)
+	roles.SetTxRunner(memoryTxRunner{})
+
+	// The first assignment stalls between its check and its write until the
+	// second one is under way
+	assigning := make(chan struct{})
+	var once sync.Once
+	f.roles.onAssign = func() {
+		once.Do(func() {
+			close(assigning)
+			time.Sleep(50 * time.Millisecond)
+		})
+	}
+
+	errs := make(chan error, 2)
+	go func() { errs <- roles.AssignRoleToUser(ctx, userID, f.creator.ID) }()
+	<-assigning
+	go func() { errs <- roles.AssignRoleToUser(ctx, userID, f.approver.ID) }()
+
+	var failed []error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			failed = append(failed, err)
+		}
+	}
+	require.Len(t, failed, 1, "only one of two conflicting assignments may succeed")
+	assert.Contains(t, failed[0].Error(), "separation of duties violation")
+
+	held, err := f.roles.GetUserRoles(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, held, 1)
+	assert.Equal(t, f.creator.ID, held[0].ID)
+}
+
+func TestService_CheckInheritance(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	lead := &models.Role{ID: uuid.New(), TenantID: f.tenantID, Name: "finance_lead"}
+	f.roles.roles[lead.ID] = lead
+	alice := uuid.New()
+	f.roles.userRoles[alice] = []*models.Role{lead, f.creator}
+	f.rules.holders = []*interfaces.SoDRoleHolder{{UserID: alice, Username: "alice", RoleID: lead.ID}}
+
+	// No rules, nothing to check
+	require.NoError(t, f.service.CheckInheritance(ctx, f.tenantID, lead.ID, f.approver.ID))
+
+	f.paymentsRule(t)
+	err := f.service.CheckInheritance(ctx, f.tenantID, lead.ID, f.approver.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "separation of duties violation")
+	assert.Contains(t, err.Error(), "alice")
+
+	assert.NoError(t, f.service.CheckInheritance(ctx, f.tenantID, lead.ID, f.viewer.ID))
+}
+
+func TestService_Violations(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	rule := f.paymentsRule(t)
+
+	alice, bob := uuid.New(), uuid.New()
+	f.rules.holders = []*interfaces.SoDRoleHolder{
+		{UserID: alice, Username: "alice", RoleID: f.creator.ID},
+		{UserID: alice, Username: "alice", RoleID: f.approver.ID},
+		{UserID: bob, Username: "bob", RoleID: f.creator.ID},
+	}
+
+	violations, err := f.service.Violations(ctx, f.tenantID, nil)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, alice, violations[0].UserID)
+	assert.Equal(t, rule.ID, violations[0].RuleID)
+	assert.ElementsMatch(t, []uuid.UUID{f.creator.ID, f.approver.ID}, violations[0].RoleIDs)
+
+	// Loosening the rule clears the violation
+	three := []uuid.UUID{f.creator.ID, f.approver.ID, f.auditor.ID}
+	maxRoles := 2
+	_, err = f.service.Update(ctx, f.tenantID, rule.ID, &UpdateRuleRequest{RoleIDs: three, MaxRoles: &maxRoles})
+	require.NoError(t, err)
+
+	violations, err = f.service.Violations(ctx, f.tenantID, &rule.ID)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	_, err = f.service.Violations(ctx, uuid.New(), &rule.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rule not found")
+}
@@ -7,9 +7,20 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/identity/permission"
 	"github.com/arauth-identity/iam/storage/interfaces"
 )
 
+// ownerWildcardKey identifies the *:* permission granted to tenant_owner
+const ownerWildcardKey = "tenant.all"
+
+// permissionName names a predefined permission of a tenant. Permission names
+// are unique across tenants, so the key is suffixed with the tenant ID, the
+// same as for permissions that migrations add to existing tenants.
+func permissionName(key string, tenantID uuid.UUID) string {
+	return key + "." + tenantID.String()
+}
+
 // Initializer handles initialization of predefined roles and permissions for new tenants
 type Initializer struct {
 	roleRepo       interfaces.RoleRepository
@@ -71,6 +82,9 @@ func (i *Initializer) createPredefinedPermissions(ctx context.Context, tenantID
 		action      string
 		description string
 	}{
+		// Wildcard grant for tenant_owner; covers permissions added later too
+		{ownerWildcardKey, permission.Wildcard, permission.Wildcard, "All permissions in the tenant"},
+
 		// User Management
 		{"tenant.users.create", "tenant.users", "create", "Create new users"},
 		{"tenant.users.read", "tenant.users", "read", "View users"},
@@ -84,6 +98,7 @@ func (i *Initializer) createPredefinedPermissions(ctx context.Context, tenantID
 		{"tenant.roles.update", "tenant.roles", "update", "Update roles"},
 		{"tenant.roles.delete", "tenant.roles", "delete", "Delete roles"},
 		{"tenant.roles.manage", "tenant.roles", "manage", "Full role management"},
+		{"roles.approve", "roles", "approve", "Approve or deny just-in-time role elevation requests"},
 
 		// Permission Management (only for tenant_owner by default)
 		{"tenant.permissions.create", "tenant.permissions", "create", "Create permissions"},
@@ -99,6 +114,27 @@ func (i *Initializer) createPredefinedPermissions(ctx context.Context, tenantID
 		// Audit & Logs
 		{"tenant.audit.read", "tenant.audit", "read", "View audit logs"},
 
+		// Access Reviews
+		{"access_reviews.read", "access_reviews", "read", "View access review campaigns and reports"},
+		{"access_reviews.manage", "access_reviews", "manage", "Run access review campaigns"},
+
+		// Separation of Duties
+		{"sod_rules.read", "sod_rules", "read", "View separation-of-duties rules and violations"},
+		{"sod_rules.manage", "sod_rules", "manage", "Manage separation-of-duties rules"},
+
+		// SCIM
+		{"scim_schemas.read", "scim_schemas", "read", "View SCIM extension schemas"},
+		{"scim_schemas.manage", "scim_schemas", "manage", "Manage SCIM extension schemas"},
+		{"scim_connectors.read", "scim_connectors", "read", "View outbound SCIM connectors and their sync status"},
+		{"scim_connectors.manage", "scim_connectors", "manage", "Manage outbound SCIM connectors"},
+
+		// Webhooks
+		{"webhooks.read", "webhooks", "read", "View webhooks and their deliveries"},
+		{"webhooks.manage", "webhooks", "manage", "Manage webhooks, send test events and redeliver events"},
+
+		// Authorization Support
+		{"authz.explain", "authz", "explain", "Explain authorization decisions and simulate access changes"},
+
 		// Admin Access
 		{"tenant.admin.access", "tenant.admin", "access", "Access admin dashboard"},
 	}
@@ -126,6 +162,7 @@ func (i *Initializer) createPredefinedPermissions(ctx context.Context, tenantID
 		desc := def.description
 		permission := &models.Permission{
 			TenantID:    tenantID,
+			Name:        permissionName(def.key, tenantID),
 			Resource:    def.resource,
 			Action:      def.action,
 			Description: &desc,
@@ -205,6 +242,12 @@ func (i *Initializer) assignPermissionsToRoles(ctx context.Context, roles map[st
 		"tenant.settings.read", "tenant.settings.update",
 		"tenant.audit.read",
 		"tenant.admin.access",
+		"access_reviews.read", "access_reviews.manage",
+		"sod_rules.read", "sod_rules.manage",
+		"scim_schemas.read", "scim_schemas.manage",
+		"scim_connectors.read", "scim_connectors.manage",
+		"webhooks.read", "webhooks.manage",
+		"authz.explain",
 	}
 	for _, permKey := range adminPermissions {
 		if perm, exists := permissions[permKey]; exists {
@@ -224,6 +267,12 @@ func (i *Initializer) assignPermissionsToRoles(ctx context.Context, roles map[st
 		"tenant.permissions.read",
 		"tenant.audit.read",
 		"tenant.admin.access",
+		"access_reviews.read",
+		"sod_rules.read",
+		"scim_schemas.read",
+		"scim_connectors.read",
+		"webhooks.read",
+		"authz.explain",
 	}
 	for _, permKey := range auditorPermissions {
 		if perm, exists := permissions[permKey]; exists {
@@ -238,9 +287,9 @@ func (i *Initializer) assignPermissionsToRoles(ctx context.Context, roles map[st
 	return nil
 }
 
-// AttachAllPermissionsToTenantOwner ensures tenant_owner has all current tenant permissions
-// This should be called when new permissions are created to maintain the invariant:
-// "tenant_owner always has all tenant permissions"
+// AttachAllPermissionsToTenantOwner ensures tenant_owner has all current and future tenant permissions.
+// It does so through a single *:* grant, so it only needs to run once per tenant; it is kept
+// idempotent so tenants initialized before wildcard permissions existed are brought up to date.
 func (i *Initializer) AttachAllPermissionsToTenantOwner(ctx context.Context, tenantID uuid.UUID) error {
 	// Get tenant_owner role
 	tenantOwnerRole, err := i.roleRepo.GetByName(ctx, tenantID, "tenant_owner")
@@ -248,26 +297,44 @@ func (i *Initializer) AttachAllPermissionsToTenantOwner(ctx context.Context, ten
 		return fmt.Errorf("tenant_owner role not found: %w", err)
 	}
 
-	// Get all permissions for this tenant
+	wildcard := permission.Wildcard
 	filters := &interfaces.PermissionFilters{
-		Page:     1,
-		PageSize: 1000, // Get all permissions
+		Resource: &wildcard,
+		Action:   &wildcard,
 	}
-	allPermissions, err := i.permissionRepo.List(ctx, tenantID, filters)
+	existing, err := i.permissionRepo.List(ctx, tenantID, filters)
 	if err != nil {
 		return fmt.Errorf("failed to list tenant permissions: %w", err)
 	}
 
-	// Assign all permissions to tenant_owner
-	for _, perm := range allPermissions {
-		if err := i.permissionRepo.AssignPermissionToRole(ctx, tenantOwnerRole.ID, perm.ID); err != nil {
-			// Ignore duplicate assignment errors (idempotent)
-			if !strings.Contains(err.Error(), "already exists") && !strings.Contains(err.Error(), "duplicate") {
-				return fmt.Errorf("failed to assign permission %s to tenant_owner: %w", perm.Resource+":"+perm.Action, err)
-			}
+	var allPermissions *models.Permission
+	for _, perm := range existing {
+		if perm.TenantID == tenantID {
+			allPermissions = perm
+			break
+		}
+	}
+
+	if allPermissions == nil {
+		desc := "All permissions in the tenant"
+		allPermissions = &models.Permission{
+			TenantID:    tenantID,
+			Name:        permissionName(ownerWildcardKey, tenantID),
+			Resource:    permission.Wildcard,
+			Action:      permission.Wildcard,
+			Description: &desc,
+		}
+		if err := i.permissionRepo.Create(ctx, allPermissions); err != nil {
+			return fmt.Errorf("failed to create permission %s: %w", ownerWildcardKey, err)
+		}
+	}
+
+	if err := i.permissionRepo.AssignPermissionToRole(ctx, tenantOwnerRole.ID, allPermissions.ID); err != nil {
+		// Ignore duplicate assignment errors (idempotent)
+		if !strings.Contains(err.Error(), "already exists") && !strings.Contains(err.Error(), "duplicate") {
+			return fmt.Errorf("failed to assign permission %s to tenant_owner: %w", ownerWildcardKey, err)
 		}
 	}
 
 	return nil
 }
-
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/arauth-identity/iam/identity/credential"
 	"github.com/arauth-identity/iam/identity/models"
@@ -28,6 +29,11 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) LockByID(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) GetByUsername(ctx context.Context, username string, tenantID uuid.UUID) (*models.User, error) {
 	args := m.Called(ctx, username, tenantID)
 	if args.Get(0) == nil {
@@ -49,6 +55,11 @@ func (m *MockUserRepository) Update(ctx context.Context, user *models.User) erro
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	args := m.Called(ctx, id, loginAt)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -135,6 +146,14 @@ func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *interfac
 	return args.Error(0)
 }
 
+func (m *MockRefreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.RefreshToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*interfaces.RefreshToken), args.Error(1)
+}
+
 func (m *MockRefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*interfaces.RefreshToken, error) {
 	args := m.Called(ctx, tokenHash)
 	if args.Get(0) == nil {
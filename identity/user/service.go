@@ -344,6 +344,16 @@ func isValidEmail(email string) bool {
 
 // ChangePassword changes a user's password and revokes all active sessions
 func (s *Service) ChangePassword(ctx context.Context, userID uuid.UUID, newPassword string) error {
+	return s.ChangePasswordWithPolicy(ctx, userID, newPassword, s.passwordValidator)
+}
+
+// ChangePasswordWithPolicy changes a user's password, validating it against the given policy
+// (typically the tenant's password policy), and revokes all active sessions
+func (s *Service) ChangePasswordWithPolicy(ctx context.Context, userID uuid.UUID, newPassword string, policy *password.Validator) error {
+	if policy == nil {
+		policy = s.passwordValidator
+	}
+
 	// 1. Get user to validate password against email (complexity check)
 	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
@@ -354,7 +364,7 @@ func (s *Service) ChangePassword(ctx context.Context, userID uuid.UUID, newPassw
 	if newPassword == "" {
 		return fmt.Errorf("password is required")
 	}
-	if err := s.passwordValidator.Validate(newPassword, user.Email); err != nil {
+	if err := policy.Validate(newPassword, user.Email); err != nil {
 		return fmt.Errorf("password validation failed: %w", err)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/security/password"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"github.com/google/uuid"
 )
@@ -23,4 +24,7 @@ type ServiceInterface interface {
 
 	// ChangePassword changes a user's password and revokes all active sessions
 	ChangePassword(ctx context.Context, userID uuid.UUID, newPassword string) error
+
+	// ChangePasswordWithPolicy is ChangePassword validated against a specific password policy
+	ChangePasswordWithPolicy(ctx context.Context, userID uuid.UUID, newPassword string, policy *password.Validator) error
 }
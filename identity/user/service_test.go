@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/arauth-identity/iam/identity/credential"
 	"github.com/arauth-identity/iam/identity/models"
@@ -28,6 +29,15 @@ func (m *FakeUserRepository) Create(ctx context.Context, user *models.User) erro
 	return nil
 }
 
+func (m *FakeUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	user, ok := m.users[id]
+	if !ok {
+		return assert.AnError
+	}
+	user.LastLoginAt = &loginAt
+	return nil
+}
+
 func (m *FakeUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	user, ok := m.users[id]
 	if !ok {
@@ -36,6 +46,13 @@ func (m *FakeUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return user, nil
 }
 
+func (m *FakeUserRepository) LockByID(ctx context.Context, id uuid.UUID) error {
+	if _, ok := m.users[id]; !ok {
+		return assert.AnError
+	}
+	return nil
+}
+
 func (m *FakeUserRepository) GetByUsername(ctx context.Context, username string, tenantID uuid.UUID) (*models.User, error) {
 	for _, user := range m.users {
 		if user.Username == username && user.TenantID != nil && *user.TenantID == tenantID {
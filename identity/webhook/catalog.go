@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// EventTypeTest is sent by the "send test event" action. It is never emitted
+// by the audit log, so webhooks don't need to subscribe to it.
+const EventTypeTest = "webhook.test"
+
+// EventDefinition describes an event type webhooks can subscribe to
+type EventDefinition struct {
+	Type        string `json:"type"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+}
+
+// eventCatalog lists the audit events delivered to tenant webhooks.
+// System events without a tenant are never delivered.
+var eventCatalog = []EventDefinition{
+	{models.EventTypeUserCreated, "user", "A user was created"},
+	{models.EventTypeUserUpdated, "user", "A user's profile or attributes changed"},
+	{models.EventTypeUserDeleted, "user", "A user was deleted"},
+	{models.EventTypeUserLocked, "user", "A user was locked"},
+	{models.EventTypeUserUnlocked, "user", "A user was unlocked"},
+	{models.EventTypeUserActivated, "user", "A user was activated"},
+	{models.EventTypeUserDisabled, "user", "A user was disabled"},
+	{models.EventTypeUserImpersonated, "user", "An administrator started impersonating a user"},
+	{models.EventTypeUserImpersonationEnded, "user", "An impersonation session ended"},
+
+	{models.EventTypeRoleAssigned, "role", "A role was assigned to a user"},
+	{models.EventTypeRoleRemoved, "role", "A role was removed from a user"},
+	{models.EventTypeRoleCreated, "role", "A role was created"},
+	{models.EventTypeRoleUpdated, "role", "A role was updated"},
+	{models.EventTypeRoleDeleted, "role", "A role was deleted"},
+	{models.EventTypeRoleElevationRequested, "role", "A user requested a time-bound role"},
+	{models.EventTypeRoleElevationApproved, "role", "A role elevation request was approved"},
+	{models.EventTypeRoleElevationDenied, "role", "A role elevation request was denied"},
+	{models.EventTypeRoleElevationCancelled, "role", "A role elevation request was cancelled"},
+	{models.EventTypeRoleAssignmentExpired, "role", "A time-bound role assignment expired"},
+
+	{models.EventTypePermissionAssigned, "permission", "A permission was assigned to a role"},
+	{models.EventTypePermissionRemoved, "permission", "A permission was removed from a role"},
+	{models.EventTypePermissionCreated, "permission", "A permission was created"},
+	{models.EventTypePermissionUpdated, "permission", "A permission was updated"},
+	{models.EventTypePermissionDeleted, "permission", "A permission was deleted"},
+
+	{models.EventTypeGroupCreated, "group", "A group was created"},
+	{models.EventTypeGroupUpdated, "group", "A group was updated"},
+	{models.EventTypeGroupDeleted, "group", "A group was deleted"},
+	{models.EventTypeGroupMemberAdded, "group", "A user was added to a group"},
+	{models.EventTypeGroupMemberRemoved, "group", "A user was removed from a group"},
+	{models.EventTypeGroupRoleAssigned, "group", "A role was assigned to a group"},
+	{models.EventTypeGroupRoleRemoved, "group", "A role was removed from a group"},
+
+	{models.EventTypeAccessReviewCreated, "access_review", "An access review campaign was created"},
+	{models.EventTypeAccessReviewDecided, "access_review", "A reviewer decided on an access review item"},
+	{models.EventTypeAccessReviewClosed, "access_review", "An access review campaign was closed"},
+	{models.EventTypeAccessReviewCancelled, "access_review", "An access review campaign was cancelled"},
+	{models.EventTypeAccessReviewRevoked, "access_review", "Access was revoked as the result of a review"},
+	{models.EventTypeAccessReviewExported, "access_review", "An access review report was exported"},
+
+	{models.EventTypeSoDRuleCreated, "sod_rule", "A separation-of-duties rule was created"},
+	{models.EventTypeSoDRuleUpdated, "sod_rule", "A separation-of-duties rule was updated"},
+	{models.EventTypeSoDRuleDeleted, "sod_rule", "A separation-of-duties rule was deleted"},
+
+	{models.EventTypePolicyCreated, "policy", "A policy was created"},
+	{models.EventTypePolicyUpdated, "policy", "A policy was updated"},
+	{models.EventTypePolicyDeleted, "policy", "A policy was deleted"},
+
+	{models.EventTypeRelationNamespaceUpdated, "relation", "A relationship namespace was created or updated"},
+	{models.EventTypeRelationNamespaceDeleted, "relation", "A relationship namespace was deleted"},
+	{models.EventTypeRelationTuplesWritten, "relation", "Relationship tuples were written or deleted"},
+
+	{models.EventTypeMFAEnrolled, "mfa", "A user enrolled in MFA"},
+	{models.EventTypeMFAChallengeCreated, "mfa", "An MFA challenge was issued"},
+	{models.EventTypeMFAVerified, "mfa", "An MFA challenge was verified"},
+	{models.EventTypeMFADisabled, "mfa", "A user disabled MFA"},
+	{models.EventTypeMFAReset, "mfa", "A user's MFA was reset"},
+
+	{models.EventTypeLoginSuccess, "login", "A user logged in"},
+	{models.EventTypeLoginFailure, "login", "A login attempt failed"},
+	{models.EventTypeTokenIssued, "token", "A token was issued"},
+	{models.EventTypeTokenRevoked, "token", "A token was revoked"},
+
+	{models.EventTypeTenantUpdated, "tenant", "The tenant was updated"},
+	{models.EventTypeTenantSuspended, "tenant", "The tenant was suspended"},
+	{models.EventTypeTenantResumed, "tenant", "The tenant was resumed"},
+	{models.EventTypeTenantSettingsUpdated, "tenant", "The tenant's settings changed"},
+
+	{models.EventTypeOAuthScopeCreated, "oauth_scope", "An OAuth scope was created"},
+	{models.EventTypeOAuthScopeUpdated, "oauth_scope", "An OAuth scope was updated"},
+	{models.EventTypeOAuthScopeDeleted, "oauth_scope", "An OAuth scope was deleted"},
+
+	{models.EventTypeSCIMTokenUpdated, "scim_token", "A SCIM token's settings changed"},
+	{models.EventTypeSCIMSchemaCreated, "scim_schema", "A SCIM extension schema was created"},
+	{models.EventTypeSCIMSchemaUpdated, "scim_schema", "A SCIM extension schema was updated"},
+	{models.EventTypeSCIMSchemaDeleted, "scim_schema", "A SCIM extension schema was deleted"},
+	{models.EventTypeSCIMConnectorCreated, "scim_connector", "An outbound SCIM connector was created"},
+	{models.EventTypeSCIMConnectorUpdated, "scim_connector", "An outbound SCIM connector was updated"},
+	{models.EventTypeSCIMConnectorDeleted, "scim_connector", "An outbound SCIM connector was deleted"},
+	{models.EventTypeSCIMConnectorReconciled, "scim_connector", "A full reconciliation of a SCIM connector was queued"},
+
+	{models.EventTypeWebhookCreated, "webhook", "A webhook was created"},
+	{models.EventTypeWebhookUpdated, "webhook", "A webhook was updated"},
+	{models.EventTypeWebhookDeleted, "webhook", "A webhook was deleted"},
+	{models.EventTypeWebhookPaused, "webhook", "A webhook was paused"},
+	{models.EventTypeWebhookResumed, "webhook", "A webhook was resumed"},
+	{models.EventTypeWebhookDisabled, "webhook", "A webhook was disabled after repeated delivery failures"},
+}
+
+// EventCatalog returns the event types webhooks can subscribe to
+func EventCatalog() []EventDefinition {
+	catalog := make([]EventDefinition, len(eventCatalog))
+	copy(catalog, eventCatalog)
+	return catalog
+}
+
+// validateSubscriptions checks that every subscription is "*", a category
+// wildcard such as "user.*", or an event type from the catalog
+func validateSubscriptions(events []string) error {
+	if len(events) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+	for _, pattern := range events {
+		if !knownSubscription(pattern) {
+			return fmt.Errorf("unknown event type: %s", pattern)
+		}
+	}
+	return nil
+}
+
+// knownSubscription reports whether a pattern matches at least one catalog event
+func knownSubscription(pattern string) bool {
+	if pattern == models.EventTypeWildcard {
+		return true
+	}
+	if strings.Contains(strings.TrimSuffix(pattern, "*"), "*") {
+		return false
+	}
+	for _, def := range eventCatalog {
+		if models.MatchesEventType(pattern, def.Type) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,25 +4,57 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/pkg/webhooks"
 	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const (
+	// DefaultFailureThreshold is how many delivery attempts in a row may fail
+	// before a webhook is disabled. Each delivery is attempted up to five
+	// times, so this is roughly four undeliverable events.
+	DefaultFailureThreshold = 20
+
+	// DefaultSecretOverlap is how long a replaced secret keeps signing
+	// requests unless the rotation asks otherwise
+	DefaultSecretOverlap = 24 * time.Hour
+
+	// MaxSecretOverlap caps how long a replaced secret keeps signing requests
+	MaxSecretOverlap = 7 * 24 * time.Hour
+)
+
 // DispatcherInterface defines the interface for webhook delivery
 type DispatcherInterface interface {
-	Deliver(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}, eventID *uuid.UUID) error
+	// Deliver sends an event and records the delivery, scheduling retries on failure
+	Deliver(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}, eventID *uuid.UUID) (*models.WebhookDelivery, error)
+
+	// Ping sends a single test event and records the delivery without retries
+	Ping(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}) (*models.WebhookDelivery, error)
+
+	// Attempt sends a queued delivery and updates its record
+	Attempt(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) error
+}
+
+// EventLogger records audit events. audit.ServiceInterface satisfies it; the
+// audit service is built on top of this one, so it is set after construction.
+type EventLogger interface {
+	LogEvent(ctx context.Context, event *models.AuditEvent) error
 }
 
 // Service provides webhook functionality
 type Service struct {
-	webhookRepo    interfaces.WebhookRepository
-	deliveryRepo   interfaces.WebhookDeliveryRepository
-	dispatcher     DispatcherInterface
-	logger         *zap.Logger
+	webhookRepo      interfaces.WebhookRepository
+	deliveryRepo     interfaces.WebhookDeliveryRepository
+	dispatcher       DispatcherInterface
+	logger           *zap.Logger
+	eventLogger      EventLogger
+	failureThreshold int
 }
 
 // NewService creates a new webhook service
@@ -31,36 +63,67 @@ func NewService(
 	deliveryRepo interfaces.WebhookDeliveryRepository,
 	dispatcher DispatcherInterface,
 	logger *zap.Logger,
-) ServiceInterface {
+) *Service {
 	return &Service{
-		webhookRepo:  webhookRepo,
-		deliveryRepo: deliveryRepo,
-		dispatcher:   dispatcher,
-		logger:       logger,
+		webhookRepo:      webhookRepo,
+		deliveryRepo:     deliveryRepo,
+		dispatcher:       dispatcher,
+		logger:           logger,
+		failureThreshold: DefaultFailureThreshold,
 	}
 }
 
+// SetEventLogger sets where webhook.disabled events are recorded when a
+// failing webhook is disabled. Those events are delivered to the tenant's
+// other webhooks like any other audit event.
+func (s *Service) SetEventLogger(eventLogger EventLogger) {
+	s.eventLogger = eventLogger
+}
+
 // CreateWebhook creates a new webhook
 func (s *Service) CreateWebhook(ctx context.Context, tenantID uuid.UUID, req *CreateWebhookRequest) (*models.Webhook, error) {
-	// Validate events
-	if len(req.Events) == 0 {
-		return nil, fmt.Errorf("at least one event type is required")
+	if err := validateSubscriptions(req.Events); err != nil {
+		return nil, err
+	}
+
+	algorithm := req.SigningAlgorithm
+	if algorithm == "" {
+		algorithm = models.WebhookSigningHMAC
 	}
 
-	// Generate secret if not provided (should be provided, but generate as fallback)
-	secret := req.Secret
-	if secret == "" {
-		secret = generateSecret()
+	var secret string
+	if algorithm == models.WebhookSigningEd25519 {
+		if req.Secret != "" {
+			return nil, fmt.Errorf("invalid secret: ed25519 webhooks are signed with a generated key")
+		}
+		key, err := webhooks.GenerateSigningKey()
+		if err != nil {
+			return nil, err
+		}
+		secret = key
+	} else {
+		// Generate secret if not provided (should be provided, but generate as fallback)
+		secret = req.Secret
+		if secret == "" {
+			secret = generateSecret()
+		}
+		if err := validateSecret(secret); err != nil {
+			return nil, err
+		}
 	}
 
 	w := &models.Webhook{
-		ID:       uuid.New(),
-		TenantID: tenantID,
-		Name:     req.Name,
-		URL:      req.URL,
-		Secret:   secret,
-		Enabled:  req.Enabled,
-		Events:   req.Events,
+		ID:               uuid.New(),
+		TenantID:         tenantID,
+		Name:             req.Name,
+		URL:              req.URL,
+		Secret:           secret,
+		SigningAlgorithm: algorithm,
+		Enabled:          req.Enabled == nil || *req.Enabled,
+		Events:           req.Events,
+	}
+	if !w.Enabled {
+		pause(w)
 	}
 
 	if err := s.webhookRepo.Create(ctx, w); err != nil {
@@ -68,20 +131,20 @@ func (s *Service) CreateWebhook(ctx context.Context, tenantID uuid.UUID, req *Cr
 	}
 
 	// Don't return secret in response
-	w.Secret = ""
+	redact(w)
 
 	return w, nil
 }
 
-// GetWebhook retrieves a webhook by ID
-func (s *Service) GetWebhook(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
-	w, err := s.webhookRepo.GetByID(ctx, id)
+// GetWebhook retrieves a tenant's webhook by ID
+func (s *Service) GetWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error) {
+	w, err := s.getOwned(ctx, tenantID, id)
 	if err != nil {
-		return nil, fmt.Errorf("webhook not found: %w", err)
+		return nil, err
 	}
 
 	// Don't return secret
-	w.Secret = ""
+	redact(w)
 
 	return w, nil
 }
@@ -95,17 +158,17 @@ func (s *Service) GetWebhooksByTenant(ctx context.Context, tenantID uuid.UUID) (
 
 	// Don't return secrets
 	for _, w := range webhooks {
-		w.Secret = ""
+		redact(w)
 	}
 
 	return webhooks, nil
 }
 
-// UpdateWebhook updates a webhook
-func (s *Service) UpdateWebhook(ctx context.Context, id uuid.UUID, req *UpdateWebhookRequest) (*models.Webhook, error) {
-	w, err := s.webhookRepo.GetByID(ctx, id)
+// UpdateWebhook updates a webhook. Setting enabled pauses or resumes it.
+func (s *Service) UpdateWebhook(ctx context.Context, tenantID, id uuid.UUID, req *UpdateWebhookRequest) (*models.Webhook, error) {
+	w, err := s.getOwned(ctx, tenantID, id)
 	if err != nil {
-		return nil, fmt.Errorf("webhook not found: %w", err)
+		return nil, err
 	}
 
 	if req.Name != nil {
@@ -114,15 +177,21 @@ func (s *Service) UpdateWebhook(ctx context.Context, id uuid.UUID, req *UpdateWe
 	if req.URL != nil {
 		w.URL = *req.URL
 	}
-	if req.Secret != nil {
-		w.Secret = *req.Secret
+	if req.Secret != nil || req.RotateKey {
+		if err := rotateSecret(w, req); err != nil {
+			return nil, err
+		}
 	}
-	if req.Enabled != nil {
-		w.Enabled = *req.Enabled
+	if req.Enabled != nil && *req.Enabled != w.Enabled {
+		if *req.Enabled {
+			resume(w)
+		} else {
+			pause(w)
+		}
 	}
 	if req.Events != nil {
-		if len(req.Events) == 0 {
-			return nil, fmt.Errorf("at least one event type is required")
+		if err := validateSubscriptions(req.Events); err != nil {
+			return nil, err
 		}
 		w.Events = req.Events
 	}
@@ -130,20 +199,48 @@ func (s *Service) UpdateWebhook(ctx context.Context, id uuid.UUID, req *UpdateWe
 	if err := s.webhookRepo.Update(ctx, w); err != nil {
 		return nil, fmt.Errorf("failed to update webhook: %w", err)
 	}
+	if !w.Enabled {
+		s.abandonQueued(ctx, w.ID)
+	}
 
 	// Don't return secret
-	w.Secret = ""
+	redact(w)
 
 	return w, nil
 }
 
 // DeleteWebhook deletes a webhook
-func (s *Service) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
-	return s.webhookRepo.Delete(ctx, id)
+func (s *Service) DeleteWebhook(ctx context.Context, tenantID, id uuid.UUID) error {
+	if _, err := s.getOwned(ctx, tenantID, id); err != nil {
+		return err
+	}
+	if err := s.webhookRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.abandonQueued(ctx, id)
+	return nil
+}
+
+// PauseWebhook stops deliveries to a webhook until it is resumed.
+// Deliveries queued for it are abandoned.
+func (s *Service) PauseWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error) {
+	enabled := false
+	return s.UpdateWebhook(ctx, tenantID, id, &UpdateWebhookRequest{Enabled: &enabled})
 }
 
-// GetDeliveriesByWebhook retrieves deliveries for a webhook
-func (s *Service) GetDeliveriesByWebhook(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+// ResumeWebhook re-enables a paused webhook, or one disabled after
+// repeated failures, and resets its failure count
+func (s *Service) ResumeWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error) {
+	enabled := true
+	return s.UpdateWebhook(ctx, tenantID, id, &UpdateWebhookRequest{Enabled: &enabled})
+}
+
+// GetDeliveriesByWebhook retrieves deliveries for a tenant's webhook
+func (s *Service) GetDeliveriesByWebhook(ctx context.Context, tenantID, webhookID uuid.UUID, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+	if _, err := s.getOwned(ctx, tenantID, webhookID); err != nil {
+		return nil, 0, err
+	}
+
 	if limit <= 0 {
 		limit = 50
 	}
@@ -154,41 +251,314 @@ func (s *Service) GetDeliveriesByWebhook(ctx context.Context, webhookID uuid.UUI
 	return s.deliveryRepo.GetByWebhookID(ctx, webhookID, limit, offset)
 }
 
-// GetDeliveryByID retrieves a delivery by ID
-func (s *Service) GetDeliveryByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
-	return s.deliveryRepo.GetByID(ctx, id)
+// GetDeliveryByID retrieves a delivery made to a tenant's webhook
+func (s *Service) GetDeliveryByID(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	_, delivery, err := s.getOwnedDelivery(ctx, tenantID, webhookID, deliveryID)
+	return delivery, err
 }
 
-// TriggerWebhook triggers webhook delivery for an event
-func (s *Service) TriggerWebhook(ctx context.Context, tenantID uuid.UUID, eventType string, payload map[string]interface{}, eventID *uuid.UUID) error {
-	// Get all enabled webhooks subscribed to this event type
-	webhooks, err := s.webhookRepo.GetByEventType(ctx, tenantID, eventType)
+// SendTestEvent sends a webhook.test event and waits for the result. Test
+// events are sent to paused and disabled webhooks too, so an endpoint can be
+// checked before it is resumed, and they don't count towards its health.
+func (s *Service) SendTestEvent(ctx context.Context, tenantID, id uuid.UUID) (*models.WebhookDelivery, error) {
+	w, err := s.getOwned(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"webhook_id": w.ID.String(),
+		"tenant_id":  w.TenantID.String(),
+		"message":    "This is a test event",
+	}
+	return s.dispatcher.Ping(ctx, w, EventTypeTest, payload)
+}
+
+// Redeliver sends the event of a past delivery again as a new delivery and
+// waits for the result of the first attempt
+func (s *Service) Redeliver(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	w, original, err := s.getOwnedDelivery(ctx, tenantID, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if original.EventType == EventTypeTest {
+		return s.dispatcher.Ping(ctx, w, original.EventType, original.Payload)
+	}
+
+	delivery, err := s.dispatcher.Deliver(ctx, w, original.EventType, original.Payload, original.EventID)
+	if delivery != nil {
+		s.recordResult(ctx, w, delivery)
+	}
+	return delivery, err
+}
+
+// HandleEvent queues an audit event for delivery to each of the tenant's
+// enabled webhooks subscribed to it. It implements outbox.Handler; an event
+// handled again is not queued twice. System events are never delivered.
+func (s *Service) HandleEvent(ctx context.Context, event *models.AuditEvent) error {
+	if event.TenantID == nil {
+		return nil
+	}
+
+	webhooks, err := s.webhookRepo.GetByEventType(ctx, *event.TenantID, event.EventType)
 	if err != nil {
 		return fmt.Errorf("failed to get webhooks: %w", err)
 	}
 
-		// Deliver to each webhook asynchronously
-		for _, w := range webhooks {
-			go func(webhook *models.Webhook) {
-			// Create a new context for async operation
-			asyncCtx := context.Background()
-			if err := s.dispatcher.Deliver(asyncCtx, webhook, eventType, payload, eventID); err != nil {
-				s.logger.Error("Failed to deliver webhook",
-					zap.String("webhook_id", webhook.ID.String()),
-					zap.String("event_type", eventType),
-					zap.Error(err),
-				)
+	var payload map[string]interface{}
+	for _, w := range webhooks {
+		if !w.Subscribes(event.EventType) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = eventToPayload(event); err != nil {
+				return err
 			}
-		}(w)
+		}
+
+		now := time.Now()
+		eventID := event.ID
+		delivery := &models.WebhookDelivery{
+			ID:          uuid.New(),
+			WebhookID:   w.ID,
+			EventID:     &eventID,
+			EventType:   event.EventType,
+			Payload:     payload,
+			Status:      models.DeliveryStatusPending,
+			NextRetryAt: &now,
+		}
+		if _, err := s.deliveryRepo.Enqueue(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// eventToPayload converts an audit event to a webhook payload map
+func eventToPayload(event *models.AuditEvent) (map[string]interface{}, error) {
+	// Marshal event to JSON and unmarshal to map to get all fields
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize event: %w", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(eventJSON, &payload); err != nil {
+		return nil, fmt.Errorf("failed to deserialize event: %w", err)
+	}
+
+	return payload, nil
+}
+
+// recordResult updates the webhook's failure count after a delivery attempt
+// and disables it once too many attempts in a row have failed. It reports
+// whether the webhook was disabled.
+func (s *Service) recordResult(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) bool {
+	success := delivery.Status == models.DeliveryStatusSuccess
+	failures, err := s.webhookRepo.RecordDeliveryResult(ctx, w.ID, success)
+	if err != nil {
+		s.logger.Error("Failed to record webhook delivery result",
+			zap.String("webhook_id", w.ID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+	if success || failures < s.failureThreshold {
+		return false
+	}
+
+	disabled, err := s.webhookRepo.Disable(ctx, w.ID, models.WebhookDisabledFailures)
+	if err != nil {
+		s.logger.Error("Failed to disable failing webhook",
+			zap.String("webhook_id", w.ID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+	if !disabled {
+		return false
+	}
+	s.abandonQueued(ctx, w.ID)
+
+	s.logger.Warn("Disabled webhook after repeated delivery failures",
+		zap.String("webhook_id", w.ID.String()),
+		zap.String("tenant_id", w.TenantID.String()),
+		zap.Int("consecutive_failures", failures),
+	)
+	s.notifyDisabled(ctx, w, delivery, failures)
+	return true
+}
+
+// abandonQueued gives up on the deliveries queued for a webhook that no
+// longer receives events
+func (s *Service) abandonQueued(ctx context.Context, id uuid.UUID) {
+	if _, err := s.deliveryRepo.AbandonQueued(ctx, id); err != nil {
+		s.logger.Error("Failed to abandon queued webhook deliveries",
+			zap.String("webhook_id", id.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// notifyDisabled records a webhook.disabled audit event for a webhook the
+// system disabled. The webhook itself stands in as the actor.
+func (s *Service) notifyDisabled(ctx context.Context, w *models.Webhook, lastDelivery *models.WebhookDelivery, failures int) {
+	if s.eventLogger == nil {
+		return
 	}
 
+	metadata := map[string]interface{}{
+		"reason":               models.WebhookDisabledFailures,
+		"url":                  w.URL,
+		"consecutive_failures": failures,
+		"last_delivery_id":     lastDelivery.ID.String(),
+	}
+	if lastDelivery.HTTPStatusCode != nil {
+		metadata["last_http_status_code"] = *lastDelivery.HTTPStatusCode
+	}
+
+	tenantID := w.TenantID
+	event := &models.AuditEvent{
+		EventType: models.EventTypeWebhookDisabled,
+		Actor: models.AuditActor{
+			UserID:        w.ID,
+			Username:      "system",
+			PrincipalType: string(models.PrincipalTypeSystem),
+		},
+		Target: &models.AuditTarget{
+			Type:       "webhook",
+			ID:         w.ID,
+			Identifier: w.Name,
+		},
+		TenantID: &tenantID,
+		Metadata: metadata,
+		Result:   models.ResultSuccess,
+	}
+	event.Flatten()
+	if err := s.eventLogger.LogEvent(ctx, event); err != nil {
+		s.logger.Error("Failed to record webhook.disabled event", zap.Error(err))
+	}
+}
+
+// getOwned loads a webhook, hiding webhooks of other tenants
+func (s *Service) getOwned(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error) {
+	w, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("webhook not found: %w", err)
+	}
+	if w.TenantID != tenantID {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return w, nil
+}
+
+// getOwnedDelivery loads a delivery and the tenant's webhook it was made to
+func (s *Service) getOwnedDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.Webhook, *models.WebhookDelivery, error) {
+	w, err := s.getOwned(ctx, tenantID, webhookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	delivery, err := s.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("delivery not found: %w", err)
+	}
+	if delivery.WebhookID != w.ID {
+		return nil, nil, fmt.Errorf("delivery not found")
+	}
+	return w, delivery, nil
+}
+
+// rotateSecret replaces the webhook's signing secret. The replaced secret
+// keeps signing requests for the requested overlap, so receivers can move
+// to the new secret without rejecting deliveries.
+func rotateSecret(w *models.Webhook, req *UpdateWebhookRequest) error {
+	var secret string
+	if w.SigningAlgorithm == models.WebhookSigningEd25519 {
+		if req.Secret != nil {
+			return fmt.Errorf("invalid secret: ed25519 webhooks are signed with a generated key; use rotate_key")
+		}
+		key, err := webhooks.GenerateSigningKey()
+		if err != nil {
+			return err
+		}
+		secret = key
+	} else {
+		if req.Secret == nil {
+			return fmt.Errorf("invalid rotate_key: hmac-sha256 webhooks are rotated by setting a new secret")
+		}
+		if err := validateSecret(*req.Secret); err != nil {
+			return err
+		}
+		secret = *req.Secret
+	}
+
+	overlap := DefaultSecretOverlap
+	if req.SecretOverlapSeconds != nil {
+		overlap = time.Duration(*req.SecretOverlapSeconds) * time.Second
+	}
+	if overlap > MaxSecretOverlap {
+		return fmt.Errorf("invalid secret_overlap_seconds: at most %d", int(MaxSecretOverlap.Seconds()))
+	}
+
+	w.PreviousSecret = nil
+	w.PreviousSecretExpiresAt = nil
+	if overlap > 0 && secret != w.Secret {
+		previous := w.Secret
+		expiresAt := time.Now().Add(overlap)
+		w.PreviousSecret = &previous
+		w.PreviousSecretExpiresAt = &expiresAt
+	}
+	w.Secret = secret
 	return nil
 }
 
+// validateSecret checks that an HMAC secret can sign requests
+func validateSecret(secret string) error {
+	signer, err := webhooks.NewSigner(secret)
+	if err != nil {
+		return fmt.Errorf("invalid secret: %w", err)
+	}
+	if signer.PublicKey() != "" {
+		return fmt.Errorf("invalid secret: signing keys are only used by ed25519 webhooks")
+	}
+	return nil
+}
+
+// redact removes secrets from a webhook returned to the API. Webhooks
+// signed with Ed25519 show the public key receivers verify with instead.
+func redact(w *models.Webhook) {
+	if w.SigningAlgorithm == models.WebhookSigningEd25519 {
+		if signer, err := webhooks.NewSigner(w.Secret); err == nil {
+			w.PublicKey = signer.PublicKey()
+		}
+	}
+	w.Secret = ""
+	w.PreviousSecret = nil
+}
+
+// pause disables a webhook on an administrator's request
+func pause(w *models.Webhook) {
+	now := time.Now()
+	reason := models.WebhookDisabledPaused
+	w.Enabled = false
+	w.DisabledReason = &reason
+	w.DisabledAt = &now
+}
+
+// resume re-enables a webhook with a clean failure count
+func resume(w *models.Webhook) {
+	w.Enabled = true
+	w.DisabledReason = nil
+	w.DisabledAt = nil
+	w.ConsecutiveFailures = 0
+}
+
 // generateSecret generates a random secret
 func generateSecret() string {
 	b := make([]byte, 32)
 	rand.Read(b)
 	return base64.URLEncoding.EncodeToString(b)
 }
-
@@ -3,42 +3,53 @@ package webhook
 import (
 	"context"
 
-	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
 )
 
-// ServiceInterface defines the interface for webhook service operations
+// ServiceInterface defines the interface for webhook service operations.
+// Webhooks and deliveries of other tenants are reported as not found.
 type ServiceInterface interface {
 	// Webhook Management
 	CreateWebhook(ctx context.Context, tenantID uuid.UUID, req *CreateWebhookRequest) (*models.Webhook, error)
-	GetWebhook(ctx context.Context, id uuid.UUID) (*models.Webhook, error)
+	GetWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error)
 	GetWebhooksByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.Webhook, error)
-	UpdateWebhook(ctx context.Context, id uuid.UUID, req *UpdateWebhookRequest) (*models.Webhook, error)
-	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	UpdateWebhook(ctx context.Context, tenantID, id uuid.UUID, req *UpdateWebhookRequest) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, tenantID, id uuid.UUID) error
+	PauseWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error)
+	ResumeWebhook(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error)
 
 	// Delivery Management
-	GetDeliveriesByWebhook(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*models.WebhookDelivery, int, error)
-	GetDeliveryByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error)
-
-	// Trigger webhook delivery (called by audit service)
-	TriggerWebhook(ctx context.Context, tenantID uuid.UUID, eventType string, payload map[string]interface{}, eventID *uuid.UUID) error
+	GetDeliveriesByWebhook(ctx context.Context, tenantID, webhookID uuid.UUID, limit, offset int) ([]*models.WebhookDelivery, int, error)
+	GetDeliveryByID(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	SendTestEvent(ctx context.Context, tenantID, id uuid.UUID) (*models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
 }
 
-// CreateWebhookRequest represents a request to create a webhook
+// CreateWebhookRequest represents a request to create a webhook.
+// Events are event types from the catalog, category wildcards such as
+// "user.*", or "*" for every event. Webhooks are enabled unless enabled is false.
+// Requests are signed with the HMAC secret, or with a generated Ed25519 key
+// when signing_algorithm is ed25519; the webhook then shows its public key.
 type CreateWebhookRequest struct {
-	Name    string   `json:"name" binding:"required"`
-	URL     string   `json:"url" binding:"required,url"`
-	Secret  string   `json:"secret" binding:"required,min=32"` // Minimum 32 characters for security
-	Enabled bool     `json:"enabled"`
-	Events  []string `json:"events" binding:"required,min=1"`
+	Name             string   `json:"name" binding:"required"`
+	URL              string   `json:"url" binding:"required,url"`
+	Secret           string   `json:"secret" binding:"required_unless=SigningAlgorithm ed25519,omitempty,min=32"` // Minimum 32 characters for security
+	SigningAlgorithm string   `json:"signing_algorithm,omitempty" binding:"omitempty,oneof=hmac-sha256 ed25519"`
+	Enabled          *bool    `json:"enabled,omitempty"`
+	Events           []string `json:"events" binding:"required,min=1"`
 }
 
-// UpdateWebhookRequest represents a request to update a webhook
+// UpdateWebhookRequest represents a request to update a webhook.
+// Setting secret, or rotate_key for Ed25519 webhooks, rotates the signing
+// secret; the old one keeps signing for secret_overlap_seconds (24 hours
+// by default, 0 to stop at once).
 type UpdateWebhookRequest struct {
-	Name    *string   `json:"name,omitempty"`
-	URL     *string   `json:"url,omitempty"`
-	Secret  *string   `json:"secret,omitempty"`
-	Enabled *bool     `json:"enabled,omitempty"`
-	Events  []string  `json:"events,omitempty"`
+	Name                 *string  `json:"name,omitempty"`
+	URL                  *string  `json:"url,omitempty" binding:"omitempty,url"`
+	Secret               *string  `json:"secret,omitempty" binding:"omitempty,min=32"`
+	RotateKey            bool     `json:"rotate_key,omitempty"`
+	SecretOverlapSeconds *int     `json:"secret_overlap_seconds,omitempty" binding:"omitempty,min=0"`
+	Enabled              *bool    `json:"enabled,omitempty"`
+	Events               []string `json:"events,omitempty"`
 }
-
@@ -0,0 +1,607 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryWebhookRepository keeps webhooks in memory. GetByEventType returns
+// every enabled webhook of the tenant, so subscription matching in the
+// service is exercised on its own.
+type memoryWebhookRepository struct {
+	interfaces.WebhookRepository
+	mu         sync.Mutex
+	webhooks   map[uuid.UUID]*models.Webhook
+	leases     map[uuid.UUID]time.Time
+	deliveries *memoryDeliveryRepository
+}
+
+func newMemoryWebhookRepository(deliveries *memoryDeliveryRepository) *memoryWebhookRepository {
+	return &memoryWebhookRepository{
+		webhooks:   map[uuid.UUID]*models.Webhook{},
+		leases:     map[uuid.UUID]time.Time{},
+		deliveries: deliveries,
+	}
+}
+
+func (r *memoryWebhookRepository) Create(ctx context.Context, w *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *w
+	r.webhooks[w.ID] = &stored
+	return nil
+}
+
+func (r *memoryWebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	found := *w
+	return &found, nil
+}
+
+func (r *memoryWebhookRepository) GetByEventType(ctx context.Context, tenantID uuid.UUID, eventType string) ([]*models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*models.Webhook
+	for _, w := range r.webhooks {
+		if w.TenantID == tenantID && w.Enabled {
+			copied := *w
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryWebhookRepository) Update(ctx context.Context, w *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *w
+	r.webhooks[w.ID] = &stored
+	return nil
+}
+
+func (r *memoryWebhookRepository) RecordDeliveryResult(ctx context.Context, id uuid.UUID, success bool) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w := r.webhooks[id]
+	if success {
+		w.ConsecutiveFailures = 0
+	} else {
+		w.ConsecutiveFailures++
+	}
+	return w.ConsecutiveFailures, nil
+}
+
+func (r *memoryWebhookRepository) Disable(ctx context.Context, id uuid.UUID, reason string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w := r.webhooks[id]
+	if !w.Enabled {
+		return false, nil
+	}
+	now := time.Now()
+	w.Enabled = false
+	w.DisabledReason = &reason
+	w.DisabledAt = &now
+	return true, nil
+}
+
+func (r *memoryWebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.webhooks, id)
+	return nil
+}
+
+func (r *memoryWebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var claimed []*models.Webhook
+	for id, w := range r.webhooks {
+		if len(claimed) == limit {
+			break
+		}
+		if !w.Enabled || r.leases[id].After(now) {
+			continue
+		}
+		head, _ := r.deliveries.NextQueued(ctx, id)
+		if head == nil || head.NextRetryAt.After(now) {
+			continue
+		}
+		r.leases[id] = now.Add(lease)
+		copied := *w
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (r *memoryWebhookRepository) Release(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.leases, id)
+	return nil
+}
+
+func (r *memoryWebhookRepository) leased(id uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.leases[id]
+	return ok
+}
+
+// memoryDeliveryRepository keeps deliveries in memory, in the order they
+// were created
+type memoryDeliveryRepository struct {
+	interfaces.WebhookDeliveryRepository
+	mu         sync.Mutex
+	deliveries map[uuid.UUID]*models.WebhookDelivery
+	order      []uuid.UUID
+}
+
+func newMemoryDeliveryRepository() *memoryDeliveryRepository {
+	return &memoryDeliveryRepository{deliveries: map[uuid.UUID]*models.WebhookDelivery{}}
+}
+
+func (r *memoryDeliveryRepository) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.deliveries[delivery.ID]; !ok {
+		r.order = append(r.order, delivery.ID)
+	}
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+func (r *memoryDeliveryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, fmt.Errorf("delivery not found")
+	}
+	found := *delivery
+	return &found, nil
+}
+
+func (r *memoryDeliveryRepository) Enqueue(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	r.mu.Lock()
+	for _, existing := range r.deliveries {
+		if existing.WebhookID == delivery.WebhookID && existing.EventID != nil && *existing.EventID == *delivery.EventID {
+			r.mu.Unlock()
+			return false, nil
+		}
+	}
+	r.mu.Unlock()
+	return true, r.Create(ctx, delivery)
+}
+
+// queued returns a webhook's pending and retrying deliveries, oldest first
+func (r *memoryDeliveryRepository) queued(webhookID uuid.UUID) []*models.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var queued []*models.WebhookDelivery
+	for _, id := range r.order {
+		delivery := r.deliveries[id]
+		if delivery.WebhookID != webhookID {
+			continue
+		}
+		if delivery.Status == models.DeliveryStatusPending || delivery.Status == models.DeliveryStatusRetrying {
+			found := *delivery
+			queued = append(queued, &found)
+		}
+	}
+	return queued
+}
+
+func (r *memoryDeliveryRepository) NextQueued(ctx context.Context, webhookID uuid.UUID) (*models.WebhookDelivery, error) {
+	queued := r.queued(webhookID)
+	if len(queued) == 0 {
+		return nil, nil
+	}
+	return queued[0], nil
+}
+
+func (r *memoryDeliveryRepository) AbandonQueued(ctx context.Context, webhookID uuid.UUID) (int, error) {
+	queued := r.queued(webhookID)
+	for _, delivery := range queued {
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.NextRetryAt = nil
+		if err := r.Update(ctx, delivery); err != nil {
+			return 0, err
+		}
+	}
+	return len(queued), nil
+}
+
+func (r *memoryDeliveryRepository) Update(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.Create(ctx, delivery)
+}
+
+// fakeDispatcher records what was sent and answers with a fixed outcome.
+// Failed deliveries are due for retry immediately.
+type fakeDispatcher struct {
+	mu           sync.Mutex
+	deliveryRepo *memoryDeliveryRepository
+	fail         bool
+	sent         []string
+}
+
+func (d *fakeDispatcher) Deliver(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}, eventID *uuid.UUID) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     w.ID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		AttemptNumber: 1,
+	}
+	d.attempt(w, delivery, true)
+	return delivery, d.deliveryRepo.Create(ctx, delivery)
+}
+
+func (d *fakeDispatcher) Ping(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     w.ID,
+		EventType:     eventType,
+		Payload:       payload,
+		AttemptNumber: 1,
+	}
+	d.attempt(w, delivery, false)
+	return delivery, d.deliveryRepo.Create(ctx, delivery)
+}
+
+func (d *fakeDispatcher) Attempt(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) error {
+	delivery.AttemptNumber++
+	d.attempt(w, delivery, true)
+	return d.deliveryRepo.Update(ctx, delivery)
+}
+
+func (d *fakeDispatcher) attempt(w *models.Webhook, delivery *models.WebhookDelivery, retry bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sent = append(d.sent, w.Name+" "+delivery.EventType)
+	delivery.NextRetryAt = nil
+	switch {
+	case !d.fail:
+		delivery.Status = models.DeliveryStatusSuccess
+	case retry:
+		now := time.Now()
+		delivery.Status = models.DeliveryStatusRetrying
+		delivery.NextRetryAt = &now
+	default:
+		delivery.Status = models.DeliveryStatusFailed
+	}
+}
+
+func (d *fakeDispatcher) sentCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.sent)
+}
+
+// recordingEventLogger keeps the audit events logged through it
+type recordingEventLogger struct {
+	mu     sync.Mutex
+	events []*models.AuditEvent
+}
+
+func (l *recordingEventLogger) LogEvent(ctx context.Context, event *models.AuditEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+	return nil
+}
+
+func newTestService() (*Service, *memoryWebhookRepository, *fakeDispatcher) {
+	deliveries := newMemoryDeliveryRepository()
+	webhooks := newMemoryWebhookRepository(deliveries)
+	dispatcher := &fakeDispatcher{deliveryRepo: deliveries}
+	return NewService(webhooks, deliveries, dispatcher, zap.NewNop()), webhooks, dispatcher
+}
+
+func createTestWebhook(t *testing.T, service *Service, tenantID uuid.UUID, name string, events ...string) *models.Webhook {
+	w, err := service.CreateWebhook(context.Background(), tenantID, &CreateWebhookRequest{
+		Name:   name,
+		URL:    "https://example.com/" + name,
+		Secret: "12345678901234567890123456789012",
+		Events: events,
+	})
+	require.NoError(t, err)
+	return w
+}
+
+func TestMatchesEventType(t *testing.T) {
+	assert.True(t, models.MatchesEventType("user.created", "user.created"))
+	assert.True(t, models.MatchesEventType("*", "group.member.added"))
+	assert.True(t, models.MatchesEventType("user.*", "user.created"))
+	assert.True(t, models.MatchesEventType("user.*", "user.impersonation.ended"))
+	assert.True(t, models.MatchesEventType("group.member.*", "group.member.removed"))
+	assert.False(t, models.MatchesEventType("user.*", "users.created"))
+	assert.False(t, models.MatchesEventType("user*", "user.created"), "wildcards stop at a dot")
+	assert.False(t, models.MatchesEventType("user.created", "user.updated"))
+}
+
+func TestCreateWebhook_ValidatesSubscriptions(t *testing.T) {
+	service, _, _ := newTestService()
+	tenantID := uuid.New()
+
+	for _, events := range [][]string{{"user.exploded"}, {"nothing.*"}, {"user.*.created"}} {
+		_, err := service.CreateWebhook(context.Background(), tenantID, &CreateWebhookRequest{
+			Name:   "invalid",
+			URL:    "https://example.com/hook",
+			Secret: "12345678901234567890123456789012",
+			Events: events,
+		})
+		require.Error(t, err, events)
+		assert.Contains(t, err.Error(), "unknown event type")
+	}
+
+	w := createTestWebhook(t, service, tenantID, "valid", "user.*", "group.member.added", "*")
+	assert.True(t, w.Enabled, "webhooks are enabled unless asked otherwise")
+	assert.Empty(t, w.Secret, "secrets are never returned")
+}
+
+func TestUpdateWebhook_RotatesSecretWithOverlap(t *testing.T) {
+	service, webhooks, _ := newTestService()
+	ctx := context.Background()
+	tenantID := uuid.New()
+	w := createTestWebhook(t, service, tenantID, "users", "user.*")
+	assert.Equal(t, models.WebhookSigningHMAC, w.SigningAlgorithm)
+
+	newSecret := "abcdefghijklmnopqrstuvwxyz012345"
+	updated, err := service.UpdateWebhook(ctx, tenantID, w.ID, &UpdateWebhookRequest{Secret: &newSecret})
+	require.NoError(t, err)
+	require.NotNil(t, updated.PreviousSecretExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(DefaultSecretOverlap), *updated.PreviousSecretExpiresAt, time.Minute)
+	assert.Empty(t, updated.Secret)
+	assert.Nil(t, updated.PreviousSecret, "secrets are never returned")
+
+	stored, _ := webhooks.GetByID(ctx, w.ID)
+	assert.Equal(t, []string{newSecret, "12345678901234567890123456789012"}, stored.SigningSecrets(time.Now()))
+
+	// Without an overlap the old secret stops signing at once
+	immediate := 0
+	otherSecret := "ABCDEFGHIJKLMNOPQRSTUVWXYZ012345"
+	_, err = service.UpdateWebhook(ctx, tenantID, w.ID, &UpdateWebhookRequest{Secret: &otherSecret, SecretOverlapSeconds: &immediate})
+	require.NoError(t, err)
+	stored, _ = webhooks.GetByID(ctx, w.ID)
+	assert.Equal(t, []string{otherSecret}, stored.SigningSecrets(time.Now()))
+
+	_, err = service.UpdateWebhook(ctx, tenantID, w.ID, &UpdateWebhookRequest{RotateKey: true})
+	assert.ErrorContains(t, err, "invalid rotate_key")
+	signingKey := "whsk_" + strings.Repeat("A", 43) + "="
+	_, err = service.UpdateWebhook(ctx, tenantID, w.ID, &UpdateWebhookRequest{Secret: &signingKey})
+	assert.ErrorContains(t, err, "invalid secret")
+}
+
+func TestCreateWebhook_Ed25519(t *testing.T) {
+	service, webhooks, _ := newTestService()
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	w, err := service.CreateWebhook(ctx, tenantID, &CreateWebhookRequest{
+		Name:             "signed",
+		URL:              "https://example.com/signed",
+		SigningAlgorithm: models.WebhookSigningEd25519,
+		Events:           []string{"user.*"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, w.Secret)
+	assert.True(t, strings.HasPrefix(w.PublicKey, "whpk_"))
+
+	// Rotating the key changes the public key; the old key keeps signing
+	rotated, err := service.UpdateWebhook(ctx, tenantID, w.ID, &UpdateWebhookRequest{RotateKey: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, w.PublicKey, rotated.PublicKey)
+	stored, _ := webhooks.GetByID(ctx, w.ID)
+	assert.Len(t, stored.SigningSecrets(time.Now()), 2)
+
+	secret := "12345678901234567890123456789012"
+	_, err = service.UpdateWebhook(ctx, tenantID, w.ID, &UpdateWebhookRequest{Secret: &secret})
+	assert.ErrorContains(t, err, "invalid secret")
+}
+
+func TestHandleEvent_QueuesForMatchingSubscriptions(t *testing.T) {
+	service, webhooks, dispatcher := newTestService()
+	ctx := context.Background()
+	tenantID := uuid.New()
+	users := createTestWebhook(t, service, tenantID, "users", "user.*")
+	groups := createTestWebhook(t, service, tenantID, "groups", "group.created")
+	everything := createTestWebhook(t, service, tenantID, "everything", "*")
+
+	event := newTestEvent(tenantID, models.EventTypeUserCreated)
+	require.NoError(t, service.HandleEvent(ctx, event))
+	require.NoError(t, service.HandleEvent(ctx, event), "events relayed again are not queued twice")
+	require.NoError(t, service.HandleEvent(ctx, &models.AuditEvent{ID: uuid.New(), EventType: models.EventTypeUserCreated}),
+		"system events are not delivered")
+
+	deliveries := webhooks.deliveries
+	assert.Len(t, deliveries.queued(users.ID), 1)
+	assert.Empty(t, deliveries.queued(groups.ID))
+	require.Len(t, deliveries.queued(everything.ID), 1)
+	queued := deliveries.queued(everything.ID)[0]
+	assert.Equal(t, event.ID, *queued.EventID)
+	assert.Equal(t, event.ID.String(), queued.Payload["id"])
+	assert.Zero(t, dispatcher.sentCount(), "deliveries are sent by the workers")
+
+	deliverQueued(t, service)
+	assert.ElementsMatch(t, []string{"users user.created", "everything user.created"}, dispatcher.sent)
+	assert.Empty(t, deliveries.queued(users.ID))
+	assert.Empty(t, deliveries.queued(everything.ID))
+}
+
+func TestRunWorkers_DeliversInOrderPerEndpoint(t *testing.T) {
+	service, webhooks, dispatcher := newTestService()
+	tenantID := uuid.New()
+	w := createTestWebhook(t, service, tenantID, "users", "user.*")
+
+	eventTypes := []string{models.EventTypeUserCreated, models.EventTypeUserUpdated, models.EventTypeUserDeleted}
+	for _, eventType := range eventTypes {
+		require.NoError(t, service.HandleEvent(context.Background(), newTestEvent(tenantID, eventType)))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.RunWorkers(ctx, 2, 5*time.Millisecond)
+	}()
+	require.Eventually(t, func() bool { return dispatcher.sentCount() == len(eventTypes) }, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, []string{"users user.created", "users user.updated", "users user.deleted"}, dispatcher.sent)
+	assert.False(t, webhooks.leased(w.ID), "workers release the endpoints they drained")
+}
+
+func TestService_TenantIsolation(t *testing.T) {
+	service, _, _ := newTestService()
+	ctx := context.Background()
+	tenantID := uuid.New()
+	otherTenantID := uuid.New()
+	w := createTestWebhook(t, service, tenantID, "users", "user.*")
+	other := createTestWebhook(t, service, otherTenantID, "users", "user.*")
+
+	_, err := service.GetWebhook(ctx, otherTenantID, w.ID)
+	assert.ErrorContains(t, err, "webhook not found")
+	assert.ErrorContains(t, service.DeleteWebhook(ctx, otherTenantID, w.ID), "webhook not found")
+	_, err = service.SendTestEvent(ctx, otherTenantID, w.ID)
+	assert.ErrorContains(t, err, "webhook not found")
+
+	// A delivery can only be read or redelivered through the webhook it was made to
+	ping, err := service.SendTestEvent(ctx, otherTenantID, other.ID)
+	require.NoError(t, err)
+	_, err = service.GetDeliveryByID(ctx, tenantID, w.ID, ping.ID)
+	assert.ErrorContains(t, err, "delivery not found")
+	_, err = service.Redeliver(ctx, tenantID, w.ID, ping.ID)
+	assert.ErrorContains(t, err, "delivery not found")
+}
+
+func TestService_PauseResume(t *testing.T) {
+	service, webhooks, dispatcher := newTestService()
+	ctx := context.Background()
+	tenantID := uuid.New()
+	w := createTestWebhook(t, service, tenantID, "users", "user.*")
+
+	// A delivery is queued when the webhook is paused
+	require.NoError(t, service.HandleEvent(ctx, newTestEvent(tenantID, models.EventTypeUserCreated)))
+
+	paused, err := service.PauseWebhook(ctx, tenantID, w.ID)
+	require.NoError(t, err)
+	assert.False(t, paused.Enabled)
+	require.NotNil(t, paused.DisabledReason)
+	assert.Equal(t, models.WebhookDisabledPaused, *paused.DisabledReason)
+
+	assert.Empty(t, webhooks.deliveries.queued(w.ID), "deliveries to paused webhooks are abandoned")
+	deliverQueued(t, service)
+	assert.Zero(t, dispatcher.sentCount())
+
+	resumed, err := service.ResumeWebhook(ctx, tenantID, w.ID)
+	require.NoError(t, err)
+	assert.True(t, resumed.Enabled)
+	assert.Nil(t, resumed.DisabledReason)
+	stored, _ := webhooks.GetByID(ctx, w.ID)
+	assert.Zero(t, stored.ConsecutiveFailures)
+}
+
+func TestService_DisablesFailingWebhook(t *testing.T) {
+	service, webhooks, dispatcher := newTestService()
+	eventLogger := &recordingEventLogger{}
+	service.SetEventLogger(eventLogger)
+	service.failureThreshold = 3
+	ctx := context.Background()
+	tenantID := uuid.New()
+	w := createTestWebhook(t, service, tenantID, "users", "user.*")
+
+	// Test events don't count towards a webhook's health
+	dispatcher.fail = true
+	for i := 0; i < 5; i++ {
+		mustPing(t, service, tenantID, w.ID)
+	}
+	stored, _ := webhooks.GetByID(ctx, w.ID)
+	assert.True(t, stored.Enabled)
+	assert.Zero(t, stored.ConsecutiveFailures)
+
+	// A success resets the count
+	first := mustDeliver(t, webhooks, dispatcher, w.ID)
+	_, err := service.Redeliver(ctx, tenantID, w.ID, first.ID)
+	require.NoError(t, err)
+	stored, _ = webhooks.GetByID(ctx, w.ID)
+	assert.Equal(t, 1, stored.ConsecutiveFailures)
+	dispatcher.fail = false
+	deliverQueued(t, service)
+	stored, _ = webhooks.GetByID(ctx, w.ID)
+	assert.Zero(t, stored.ConsecutiveFailures)
+
+	// Failed attempts in a row disable the webhook once, abandoning its queue
+	dispatcher.fail = true
+	require.NoError(t, service.HandleEvent(ctx, newTestEvent(tenantID, models.EventTypeUserCreated)))
+	require.NoError(t, service.HandleEvent(ctx, newTestEvent(tenantID, models.EventTypeUserUpdated)))
+	deliverQueued(t, service)
+
+	stored, _ = webhooks.GetByID(ctx, w.ID)
+	assert.False(t, stored.Enabled)
+	require.NotNil(t, stored.DisabledReason)
+	assert.Equal(t, models.WebhookDisabledFailures, *stored.DisabledReason)
+	assert.Empty(t, webhooks.deliveries.queued(w.ID))
+
+	require.Len(t, eventLogger.events, 1)
+	event := eventLogger.events[0]
+	assert.Equal(t, models.EventTypeWebhookDisabled, event.EventType)
+	assert.Equal(t, tenantID, *event.TenantID)
+	assert.Equal(t, w.ID, event.Target.ID)
+	assert.Equal(t, string(models.PrincipalTypeSystem), event.Actor.PrincipalType)
+	assert.NoError(t, event.Validate())
+}
+
+// deliverQueued drains every endpoint with a due delivery once, as a worker would
+func deliverQueued(t *testing.T, service *Service) {
+	claimed, err := service.webhookRepo.ClaimDue(context.Background(), DefaultWorkers, claimLease)
+	require.NoError(t, err)
+	for _, w := range claimed {
+		service.drain(context.Background(), w)
+	}
+}
+
+func newTestEvent(tenantID uuid.UUID, eventType string) *models.AuditEvent {
+	return &models.AuditEvent{
+		ID:        uuid.New(),
+		EventType: eventType,
+		TenantID:  &tenantID,
+		Result:    models.ResultSuccess,
+	}
+}
+
+// mustDeliver records a delivery of a user.created event without going
+// through the service, so it doesn't count towards the webhook's health
+func mustDeliver(t *testing.T, webhooks *memoryWebhookRepository, dispatcher *fakeDispatcher, id uuid.UUID) *models.WebhookDelivery {
+	w, err := webhooks.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	delivery, err := dispatcher.Deliver(context.Background(), w, models.EventTypeUserCreated, map[string]interface{}{}, nil)
+	require.NoError(t, err)
+	return delivery
+}
+
+func mustPing(t *testing.T, service *Service, tenantID, id uuid.UUID) *models.WebhookDelivery {
+	delivery, err := service.SendTestEvent(context.Background(), tenantID, id)
+	require.NoError(t, err)
+	return delivery
+}
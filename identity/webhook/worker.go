@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultWorkers is how many endpoints each replica delivers to at once
+	DefaultWorkers = 8
+
+	// DefaultPollInterval is how often idle workers check for queued deliveries
+	DefaultPollInterval = time.Second
+
+	// claimLease is how long a worker has an endpoint to itself
+	claimLease = 5 * time.Minute
+
+	// leaseMargin is the lease time a worker leaves unused, so an attempt in
+	// flight never outlives the lease
+	leaseMargin = time.Minute
+)
+
+// RunWorkers delivers queued deliveries until ctx is cancelled, then waits
+// for attempts in flight to finish. Each worker leases one endpoint and
+// sends its deliveries one at a time, oldest first, so an endpoint sees
+// events in order. At most workers endpoints are served at once; deliveries
+// for the rest stay queued in the database.
+func (s *Service) RunWorkers(ctx context.Context, workers int, interval time.Duration) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	busy := 0
+	done := make(chan struct{}, workers)
+	for {
+		if busy < workers {
+			claimed, err := s.webhookRepo.ClaimDue(ctx, workers-busy, claimLease)
+			if err != nil && ctx.Err() == nil {
+				s.logger.Error("Failed to claim webhooks for delivery", zap.Error(err))
+			}
+			for _, w := range claimed {
+				busy++
+				metrics.WebhookWorkersBusy.Inc()
+				wg.Add(1)
+				go func(w *models.Webhook) {
+					defer wg.Done()
+					defer func() { done <- struct{}{} }()
+					defer metrics.WebhookWorkersBusy.Dec()
+					s.drain(ctx, w)
+				}(w)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			busy--
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain sends a leased webhook's queued deliveries in order until its queue
+// is empty, its oldest delivery is waiting for a retry, or the lease is
+// about to run out. The lease is released afterwards.
+func (s *Service) drain(ctx context.Context, w *models.Webhook) {
+	// Attempts in flight finish during shutdown, keeping their results
+	attemptCtx := context.WithoutCancel(ctx)
+	defer func() {
+		if err := s.webhookRepo.Release(attemptCtx, w.ID); err != nil {
+			s.logger.Error("Failed to release webhook", zap.String("webhook_id", w.ID.String()), zap.Error(err))
+		}
+	}()
+
+	deadline := time.Now().Add(claimLease - leaseMargin)
+	for ctx.Err() == nil && time.Now().Before(deadline) {
+		delivery, err := s.deliveryRepo.NextQueued(ctx, w.ID)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("Failed to get queued webhook delivery",
+					zap.String("webhook_id", w.ID.String()),
+					zap.Error(err),
+				)
+			}
+			return
+		}
+		if delivery == nil || (delivery.NextRetryAt != nil && delivery.NextRetryAt.After(time.Now())) {
+			return
+		}
+
+		if err := s.dispatcher.Attempt(attemptCtx, w, delivery); err != nil {
+			s.logger.Error("Failed to record webhook delivery attempt",
+				zap.String("delivery_id", delivery.ID.String()),
+				zap.Error(err),
+			)
+			return
+		}
+		metrics.WebhookDeliveryAttemptsTotal.WithLabelValues(string(delivery.Status)).Inc()
+		if s.recordResult(attemptCtx, w, delivery) {
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package auditchain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type actionMetadata struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+func newEvent(tenantID *uuid.UUID) *models.AuditEvent {
+	return &models.AuditEvent{
+		ID:        uuid.New(),
+		EventType: models.EventTypeUserUpdated,
+		Actor: models.AuditActor{
+			UserID:        uuid.New(),
+			Username:      "admin",
+			PrincipalType: "TENANT",
+		},
+		Target: &models.AuditTarget{
+			Type:       "user",
+			ID:         uuid.New(),
+			Identifier: "jdoe",
+		},
+		Timestamp: time.Now(),
+		SourceIP:  "2001:DB8::1",
+		UserAgent: "test-agent",
+		TenantID:  tenantID,
+		Metadata: map[string]interface{}{
+			"fields": []string{"email", "name"},
+			"detail": actionMetadata{Reason: "<sync>", Count: 2},
+		},
+		Result:    models.ResultSuccess,
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestHash_SurvivesStorageRoundTrip(t *testing.T) {
+	tenantID := uuid.New()
+	event := newEvent(&tenantID)
+	Normalize(event)
+	require.NoError(t, Link(event, 1, GenesisHash))
+
+	assert.Equal(t, "2001:db8::1", event.SourceIP)
+
+	// Decoding the JSON export gives the same values a database read does:
+	// generic metadata, float64 numbers and times in another location
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	var stored models.AuditEvent
+	require.NoError(t, json.Unmarshal(data, &stored))
+	stored.Timestamp = stored.Timestamp.In(time.FixedZone("UTC+2", 2*60*60))
+
+	hash, err := Hash(&stored)
+	require.NoError(t, err)
+	assert.Equal(t, event.Hash, hash)
+}
+
+func TestHash_CoversEventFields(t *testing.T) {
+	event := newEvent(nil)
+	Normalize(event)
+	require.NoError(t, Link(event, 1, GenesisHash))
+
+	changes := map[string]func(e *models.AuditEvent){
+		"event type": func(e *models.AuditEvent) { e.EventType = models.EventTypeUserDeleted },
+		"actor":      func(e *models.AuditEvent) { e.Actor.Username = "someone-else" },
+		"target":     func(e *models.AuditEvent) { e.Target = nil },
+		"timestamp":  func(e *models.AuditEvent) { e.Timestamp = e.Timestamp.Add(time.Second) },
+		"metadata":   func(e *models.AuditEvent) { e.Metadata = nil },
+		"result":     func(e *models.AuditEvent) { e.Result = models.ResultFailure },
+		"tenant":     func(e *models.AuditEvent) { id := uuid.New(); e.TenantID = &id },
+		"seq":        func(e *models.AuditEvent) { e.Seq = 2 },
+		"prev hash":  func(e *models.AuditEvent) { e.PrevHash = e.Hash },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			modified := *event
+			change(&modified)
+			hash, err := Hash(&modified)
+			require.NoError(t, err)
+			assert.NotEqual(t, event.Hash, hash)
+		})
+	}
+}
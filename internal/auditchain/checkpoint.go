@@ -0,0 +1,133 @@
+package auditchain
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// checkpointVersion prefixes the signed checkpoint statement
+const checkpointVersion = "arauth-audit-checkpoint/v1"
+
+var (
+	// ErrUnknownKey is returned when a checkpoint was signed by a key that
+	// is not in the key set, e.g. one replaced by a key rotation
+	ErrUnknownKey = errors.New("checkpoint signed by an unknown key")
+
+	// ErrBadSignature is returned when a checkpoint's signature doesn't match
+	ErrBadSignature = errors.New("checkpoint signature is invalid")
+)
+
+// KeyID returns the thumbprint identifying a checkpoint signing key
+func KeyID(pub *rsa.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SignCheckpoint signs a checkpoint's chain, seq, hash and creation time
+// with key (RS256) and sets its KeyID and Signature
+func SignCheckpoint(key *rsa.PrivateKey, cp *models.AuditCheckpoint) error {
+	cp.CreatedAt = cp.CreatedAt.UTC().Truncate(time.Microsecond)
+	digest := checkpointDigest(cp)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return fmt.Errorf("failed to sign checkpoint: %w", err)
+	}
+	cp.KeyID = KeyID(&key.PublicKey)
+	cp.Signature = base64.StdEncoding.EncodeToString(signature)
+	return nil
+}
+
+// checkpointDigest hashes the statement a checkpoint signs
+func checkpointDigest(cp *models.AuditCheckpoint) [32]byte {
+	statement := fmt.Sprintf("%s\n%s\n%d\n%s\n%s", checkpointVersion, cp.ChainID, cp.Seq, cp.Hash,
+		cp.CreatedAt.UTC().Format(time.RFC3339Nano))
+	return sha256.Sum256([]byte(statement))
+}
+
+// KeySet holds the public keys checkpoints are verified with, by key ID
+type KeySet map[string]*rsa.PublicKey
+
+// NewKeySet creates a key set; nil keys are skipped
+func NewKeySet(keys ...*rsa.PublicKey) KeySet {
+	set := KeySet{}
+	for _, key := range keys {
+		if key != nil {
+			set[KeyID(key)] = key
+		}
+	}
+	return set
+}
+
+// VerifyCheckpoint checks a checkpoint's signature
+func (k KeySet) VerifyCheckpoint(cp *models.AuditCheckpoint) error {
+	key, ok := k[cp.KeyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+	signature, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil {
+		return ErrBadSignature
+	}
+	digest := checkpointDigest(cp)
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// ParsePublicKey reads an RSA public key from PEM. A private key or a
+// certificate is accepted too, and its public key is used.
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return &k.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("key is not an RSA key")
+	}
+}
@@ -0,0 +1,198 @@
+package auditchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// Problem kinds reported by a Verifier
+const (
+	ProblemGap                = "gap"                 // Events are missing from the chain
+	ProblemModified           = "modified"            // An event no longer matches its hash
+	ProblemBrokenLink         = "broken_link"         // An event doesn't follow the previous one
+	ProblemTruncated          = "truncated"           // Events are missing from the end of the chain
+	ProblemHeadMismatch       = "head_mismatch"       // The chain head doesn't match the last event
+	ProblemUnchained          = "unchained"           // Events were stored outside the chain
+	ProblemCheckpointMismatch = "checkpoint_mismatch" // An event doesn't match a signed checkpoint
+	ProblemBadSignature       = "bad_signature"       // A checkpoint's signature is invalid
+	ProblemUnknownKey         = "unknown_key"         // A checkpoint can't be verified with the given keys
+)
+
+// MaxProblems caps the problems listed in a report; the rest are counted
+const MaxProblems = 100
+
+// Problem is an inconsistency found in a chain
+type Problem struct {
+	Kind    string     `json:"kind"`
+	Seq     int64      `json:"seq,omitempty"`
+	EventID *uuid.UUID `json:"event_id,omitempty"`
+	Detail  string     `json:"detail"`
+}
+
+// Report is the result of verifying one chain
+type Report struct {
+	ChainID             uuid.UUID  `json:"chain_id"`
+	TenantID            *uuid.UUID `json:"tenant_id,omitempty"`
+	Valid               bool       `json:"valid"`
+	EventsChecked       int64      `json:"events_checked"`
+	FirstSeq            int64      `json:"first_seq,omitempty"`
+	LastSeq             int64      `json:"last_seq,omitempty"`
+	HeadSeq             int64      `json:"head_seq,omitempty"`
+	CheckpointsVerified int        `json:"checkpoints_verified"`
+	UnchainedEvents     int64      `json:"unchained_events,omitempty"`
+	Problems            []Problem  `json:"problems,omitempty"`
+	ProblemsOmitted     int        `json:"problems_omitted,omitempty"`
+}
+
+// Verifier checks one chain. Events must be added in sequence order.
+//
+// A partial verifier checks an export that may leave events out, e.g.
+// because it was filtered: events and checkpoints are still checked, but
+// missing events are not reported.
+type Verifier struct {
+	report      *Report
+	partial     bool
+	checkpoints map[int64]*models.AuditCheckpoint
+	lastSeq     int64
+	lastHash    string
+	failed      bool
+}
+
+// NewVerifier creates a verifier for a tenant's chain (nil for the system
+// chain). The checkpoints' signatures are checked against keys up front.
+func NewVerifier(tenantID *uuid.UUID, checkpoints []*models.AuditCheckpoint, keys KeySet, partial bool) *Verifier {
+	v := &Verifier{
+		report: &Report{
+			ChainID:  models.AuditChainID(tenantID),
+			TenantID: tenantID,
+		},
+		partial:     partial,
+		checkpoints: make(map[int64]*models.AuditCheckpoint, len(checkpoints)),
+		lastHash:    GenesisHash,
+	}
+
+	for _, cp := range checkpoints {
+		err := keys.VerifyCheckpoint(cp)
+		switch {
+		case errors.Is(err, ErrUnknownKey):
+			// Not proof of tampering: the key may have been rotated
+			v.addProblem(ProblemUnknownKey, cp.Seq, nil, fmt.Sprintf("checkpoint is signed by key %s", cp.KeyID))
+		case err != nil:
+			v.addProblem(ProblemBadSignature, cp.Seq, nil, "checkpoint signature is invalid")
+		default:
+			v.checkpoints[cp.Seq] = cp
+			v.report.CheckpointsVerified++
+		}
+	}
+	return v
+}
+
+// Add checks the next event in the chain
+func (v *Verifier) Add(e *models.AuditEvent) {
+	if e.Seq == 0 {
+		// Recorded before chaining was introduced
+		v.report.UnchainedEvents++
+		return
+	}
+
+	v.report.EventsChecked++
+	if v.report.FirstSeq == 0 {
+		v.report.FirstSeq = e.Seq
+	}
+	id := e.ID
+
+	expected := v.lastSeq + 1
+	switch {
+	case e.Seq < expected:
+		v.addProblem(ProblemBrokenLink, e.Seq, &id, fmt.Sprintf("sequence number %d appears again", e.Seq))
+	case e.Seq > expected:
+		if !v.partial {
+			v.addProblem(ProblemGap, e.Seq, &id, fmt.Sprintf("events %d to %d are missing", expected, e.Seq-1))
+		}
+	case e.PrevHash != v.lastHash:
+		v.addProblem(ProblemBrokenLink, e.Seq, &id, "previous hash doesn't match the previous event")
+	}
+
+	hash, err := Hash(e)
+	if err != nil {
+		v.addProblem(ProblemModified, e.Seq, &id, err.Error())
+	} else if hash != e.Hash {
+		v.addProblem(ProblemModified, e.Seq, &id, "event doesn't match its hash")
+	}
+
+	if cp, ok := v.checkpoints[e.Seq]; ok && cp.Hash != hash {
+		v.addProblem(ProblemCheckpointMismatch, e.Seq, &id, fmt.Sprintf("event doesn't match the checkpoint signed at %s", cp.CreatedAt.UTC().Format("2006-01-02T15:04:05Z")))
+	}
+
+	if e.Seq > v.lastSeq {
+		// Link to the stored hash so one modified event is reported once
+		v.lastSeq = e.Seq
+		v.lastHash = e.Hash
+	}
+}
+
+// UnchainedEvents reports events stored without a place in the chain
+// after the chain was started
+func (v *Verifier) UnchainedEvents(n int64) {
+	if n == 0 {
+		return
+	}
+	v.report.UnchainedEvents += n
+	v.addProblem(ProblemUnchained, 0, nil, fmt.Sprintf("%d events were stored outside the chain", n))
+}
+
+// Finish completes the report. head is the chain's stored head, or nil
+// when it is not known, e.g. for an export.
+func (v *Verifier) Finish(head *models.AuditChain) *Report {
+	v.report.LastSeq = v.lastSeq
+
+	if !v.partial {
+		if head != nil {
+			v.report.HeadSeq = head.Seq
+			switch {
+			case head.Seq > v.lastSeq:
+				v.addProblem(ProblemTruncated, head.Seq, nil, fmt.Sprintf("chain head is at %d but the last event is %d", head.Seq, v.lastSeq))
+			case head.Seq < v.lastSeq:
+				v.addProblem(ProblemHeadMismatch, v.lastSeq, nil, fmt.Sprintf("events exist past the chain head at %d", head.Seq))
+			case head.Seq > 0 && head.Hash != v.lastHash:
+				v.addProblem(ProblemHeadMismatch, head.Seq, nil, "chain head hash doesn't match the last event")
+			}
+		}
+
+		seqs := make([]int64, 0, len(v.checkpoints))
+		for seq := range v.checkpoints {
+			if seq > v.lastSeq {
+				seqs = append(seqs, seq)
+			}
+		}
+		sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+		for _, seq := range seqs {
+			v.addProblem(ProblemTruncated, seq, nil, fmt.Sprintf("a checkpoint was signed at %d but the last event is %d", seq, v.lastSeq))
+		}
+	}
+
+	v.report.Valid = !v.failed
+	return v.report
+}
+
+// addProblem records a problem; every kind except an unknown key makes
+// the chain invalid
+func (v *Verifier) addProblem(kind string, seq int64, eventID *uuid.UUID, detail string) {
+	if kind != ProblemUnknownKey {
+		v.failed = true
+	}
+	if len(v.report.Problems) >= MaxProblems {
+		v.report.ProblemsOmitted++
+		return
+	}
+	v.report.Problems = append(v.report.Problems, Problem{
+		Kind:    kind,
+		Seq:     seq,
+		EventID: eventID,
+		Detail:  detail,
+	})
+}
@@ -0,0 +1,183 @@
+package auditchain
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildChain appends n events to a tenant's chain like the repository does
+func buildChain(t *testing.T, tenantID *uuid.UUID, n int) ([]*models.AuditEvent, *models.AuditChain) {
+	t.Helper()
+	head := &models.AuditChain{ChainID: models.AuditChainID(tenantID), TenantID: tenantID, Hash: GenesisHash}
+	events := make([]*models.AuditEvent, 0, n)
+	for i := 0; i < n; i++ {
+		event := newEvent(tenantID)
+		Normalize(event)
+		require.NoError(t, Link(event, head.Seq+1, head.Hash))
+		head.Seq, head.Hash = event.Seq, event.Hash
+		events = append(events, event)
+	}
+	return events, head
+}
+
+func checkpoint(t *testing.T, key *rsa.PrivateKey, event *models.AuditEvent) *models.AuditCheckpoint {
+	t.Helper()
+	cp := &models.AuditCheckpoint{
+		ID:        uuid.New(),
+		ChainID:   models.AuditChainID(event.TenantID),
+		TenantID:  event.TenantID,
+		Seq:       event.Seq,
+		Hash:      event.Hash,
+		CreatedAt: time.Now(),
+	}
+	require.NoError(t, SignCheckpoint(key, cp))
+	return cp
+}
+
+func verify(tenantID *uuid.UUID, events []*models.AuditEvent, head *models.AuditChain, checkpoints []*models.AuditCheckpoint, keys KeySet, partial bool) *Report {
+	v := NewVerifier(tenantID, checkpoints, keys, partial)
+	for _, event := range events {
+		v.Add(event)
+	}
+	return v.Finish(head)
+}
+
+func problemKinds(report *Report) []string {
+	var kinds []string
+	for _, p := range report.Problems {
+		kinds = append(kinds, p.Kind)
+	}
+	return kinds
+}
+
+func TestVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys := NewKeySet(&key.PublicKey)
+	tenantID := uuid.New()
+
+	t.Run("intact chain", func(t *testing.T) {
+		events, head := buildChain(t, &tenantID, 5)
+		cps := []*models.AuditCheckpoint{checkpoint(t, key, events[2]), checkpoint(t, key, events[4])}
+
+		report := verify(&tenantID, events, head, cps, keys, false)
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Problems)
+		assert.Equal(t, int64(5), report.EventsChecked)
+		assert.Equal(t, int64(5), report.LastSeq)
+		assert.Equal(t, 2, report.CheckpointsVerified)
+	})
+
+	t.Run("modified event", func(t *testing.T) {
+		events, head := buildChain(t, &tenantID, 5)
+		events[2].Result = models.ResultDenied
+
+		report := verify(&tenantID, events, head, nil, keys, false)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{ProblemModified}, problemKinds(report))
+		assert.Equal(t, int64(3), report.Problems[0].Seq)
+	})
+
+	t.Run("modified event with recomputed hash", func(t *testing.T) {
+		events, head := buildChain(t, &tenantID, 5)
+		events[2].Result = models.ResultDenied
+		require.NoError(t, Link(events[2], events[2].Seq, events[2].PrevHash))
+
+		report := verify(&tenantID, events, head, nil, keys, false)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{ProblemBrokenLink}, problemKinds(report))
+		assert.Equal(t, int64(4), report.Problems[0].Seq)
+	})
+
+	t.Run("rebuilt chain is caught by a checkpoint", func(t *testing.T) {
+		events, _ := buildChain(t, &tenantID, 5)
+		cps := []*models.AuditCheckpoint{checkpoint(t, key, events[4])}
+
+		// Someone without the key rewrites an event and every hash after it
+		events[1].Result = models.ResultDenied
+		head := &models.AuditChain{Seq: 1, Hash: events[0].Hash}
+		for _, event := range events[1:] {
+			require.NoError(t, Link(event, head.Seq+1, head.Hash))
+			head.Seq, head.Hash = event.Seq, event.Hash
+		}
+
+		report := verify(&tenantID, events, head, cps, keys, false)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{ProblemCheckpointMismatch}, problemKinds(report))
+	})
+
+	t.Run("deleted event", func(t *testing.T) {
+		events, head := buildChain(t, &tenantID, 5)
+		events = append(events[:2], events[3:]...)
+
+		report := verify(&tenantID, events, head, nil, keys, false)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{ProblemGap}, problemKinds(report))
+		assert.Contains(t, report.Problems[0].Detail, "events 3 to 3")
+	})
+
+	t.Run("deleted tail", func(t *testing.T) {
+		events, head := buildChain(t, &tenantID, 5)
+		cps := []*models.AuditCheckpoint{checkpoint(t, key, events[4])}
+
+		report := verify(&tenantID, events[:3], head, cps, keys, false)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{ProblemTruncated, ProblemTruncated}, problemKinds(report))
+	})
+
+	t.Run("forged checkpoint", func(t *testing.T) {
+		events, head := buildChain(t, &tenantID, 3)
+		cp := checkpoint(t, key, events[2])
+		cp.Seq = 2
+
+		report := verify(&tenantID, events, head, []*models.AuditCheckpoint{cp}, keys, false)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{ProblemBadSignature}, problemKinds(report))
+	})
+
+	t.Run("checkpoint from another key", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		events, head := buildChain(t, &tenantID, 3)
+
+		report := verify(&tenantID, events, head, []*models.AuditCheckpoint{checkpoint(t, other, events[2])}, keys, false)
+		assert.True(t, report.Valid)
+		assert.Equal(t, []string{ProblemUnknownKey}, problemKinds(report))
+		assert.Equal(t, 0, report.CheckpointsVerified)
+	})
+
+	t.Run("partial export skips missing events", func(t *testing.T) {
+		events, _ := buildChain(t, &tenantID, 6)
+		cps := []*models.AuditCheckpoint{checkpoint(t, key, events[5])}
+		exported := []*models.AuditEvent{events[1], events[3], events[4]}
+
+		report := verify(&tenantID, exported, nil, cps, keys, true)
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Problems)
+
+		exported[1].Error = "edited"
+		report = verify(&tenantID, exported, nil, cps, keys, true)
+		assert.Equal(t, []string{ProblemModified}, problemKinds(report))
+	})
+
+	t.Run("events stored outside the chain", func(t *testing.T) {
+		events, head := buildChain(t, &tenantID, 2)
+		v := NewVerifier(&tenantID, nil, keys, false)
+		for _, event := range events {
+			v.Add(event)
+		}
+		v.UnchainedEvents(3)
+
+		report := v.Finish(head)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{ProblemUnchained}, problemKinds(report))
+		assert.Equal(t, int64(3), report.UnchainedEvents)
+	})
+}
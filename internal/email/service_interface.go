@@ -14,6 +14,9 @@ type ServiceInterface interface {
 
 	// SendWelcomeEmail sends a welcome email to a new user
 	SendWelcomeEmail(ctx context.Context, to string, username string) error
+
+	// SendAccessReviewReminder reminds a reviewer of access review items awaiting their decision
+	SendAccessReviewReminder(ctx context.Context, to string, campaignName string, pendingItems int, dueAt string) error
 }
 
 // NoOpEmailService is a no-op implementation for development/testing
@@ -43,3 +46,8 @@ func (s *NoOpEmailService) SendWelcomeEmail(ctx context.Context, to string, user
 	return nil
 }
 
+// SendAccessReviewReminder logs the access review reminder (no-op)
+func (s *NoOpEmailService) SendAccessReviewReminder(ctx context.Context, to string, campaignName string, pendingItems int, dueAt string) error {
+	// No-op: In production, this would send an actual email
+	return nil
+}
@@ -0,0 +1,287 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/metrics"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultPollInterval is how often each replica checks for due jobs
+const DefaultPollInterval = 5 * time.Second
+
+const (
+	// leaseGrace is added to a job's timeout for its lease, leaving time to
+	// record the run after the job's context has been cancelled
+	leaseGrace = 30 * time.Second
+
+	// completeTimeout bounds recording a run, which happens even while
+	// shutting down
+	completeTimeout = 10 * time.Second
+)
+
+// Func runs a job once and returns the number of items it processed
+type Func func(ctx context.Context) (int, error)
+
+// SchedulerInterface exposes job status to the API
+type SchedulerInterface interface {
+	// List retrieves all jobs, including jobs registered by other versions
+	List(ctx context.Context) ([]*models.ScheduledJob, error)
+
+	// Get retrieves a job by name
+	Get(ctx context.Context, name string) (*models.ScheduledJob, error)
+
+	// Runs retrieves a job's most recent runs, newest first
+	Runs(ctx context.Context, name string, limit int) ([]*models.JobRun, error)
+
+	// Trigger makes a job due immediately; the next replica to poll runs it.
+	// A job that is running cannot be triggered.
+	Trigger(ctx context.Context, name string) error
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	timeout  time.Duration
+	run      Func
+}
+
+// Scheduler runs registered jobs on their schedules. Every replica runs a
+// scheduler with the same jobs; a replica runs a job only after leasing its
+// row in the job table, so each run happens on exactly one replica.
+type Scheduler struct {
+	repo   interfaces.JobRepository
+	logger *zap.Logger
+	runner string
+
+	mu         sync.Mutex
+	jobs       map[string]*job
+	registered bool
+	wg         sync.WaitGroup
+}
+
+// NewScheduler creates a new job scheduler
+func NewScheduler(repo interfaces.JobRepository, logger *zap.Logger) *Scheduler {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return &Scheduler{
+		repo:   repo,
+		logger: logger,
+		runner: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Register adds a job. A run is cancelled once it exceeds timeout. Jobs must
+// be registered before Run; registering a name twice, or a schedule that
+// never matches, panics.
+func (s *Scheduler) Register(name string, schedule Schedule, timeout time.Duration, run Func) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[name]; exists {
+		panic(fmt.Sprintf("jobs: job %q registered twice", name))
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		panic(fmt.Sprintf("jobs: schedule %q of job %q never matches", schedule, name))
+	}
+	s.jobs[name] = &job{name: name, schedule: schedule, timeout: timeout, run: run}
+}
+
+// Run polls for due jobs every interval until ctx is cancelled, then waits
+// for the jobs it started to finish
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer s.wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RunDue(ctx); err != nil {
+				s.logger.Error("Failed to run due jobs", zap.Error(err))
+			}
+			s.refreshMetrics(ctx)
+		}
+	}
+}
+
+// RunDue starts every due job this replica can lease and returns how many
+// were started. Jobs are stored on the first call, and again after a
+// failure to store them.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	if err := s.syncJobs(ctx); err != nil {
+		return 0, err
+	}
+
+	names := s.names()
+	started := 0
+	for {
+		claimed, err := s.repo.ClaimDue(ctx, names, s.runner, leaseGrace)
+		if err != nil {
+			return started, err
+		}
+		if claimed == nil {
+			return started, nil
+		}
+
+		s.mu.Lock()
+		j := s.jobs[claimed.Name]
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.execute(ctx, j)
+		}()
+		started++
+	}
+}
+
+// List retrieves all jobs
+func (s *Scheduler) List(ctx context.Context) ([]*models.ScheduledJob, error) {
+	return s.repo.List(ctx)
+}
+
+// Get retrieves a job by name
+func (s *Scheduler) Get(ctx context.Context, name string) (*models.ScheduledJob, error) {
+	return s.repo.GetByName(ctx, name)
+}
+
+// Runs retrieves a job's most recent runs
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]*models.JobRun, error) {
+	if _, err := s.repo.GetByName(ctx, name); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRuns(ctx, name, limit)
+}
+
+// Trigger makes a job due immediately
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	return s.repo.TriggerNow(ctx, name)
+}
+
+// syncJobs stores the registered jobs in the job table once
+func (s *Scheduler) syncJobs(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.registered {
+		return nil
+	}
+	now := time.Now()
+	for _, j := range s.jobs {
+		err := s.repo.Register(ctx, &models.ScheduledJob{
+			Name:           j.name,
+			Schedule:       j.schedule.String(),
+			TimeoutSeconds: int(j.timeout.Seconds()),
+			NextRunAt:      j.schedule.Next(now),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register job %s: %w", j.name, err)
+		}
+	}
+	s.registered = true
+	return nil
+}
+
+func (s *Scheduler) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// execute runs a leased job and records the run, releasing the lease
+func (s *Scheduler) execute(ctx context.Context, j *job) {
+	runCtx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	started := time.Now()
+	processed, err := s.safeRun(runCtx, j)
+	finished := time.Now()
+
+	run := &models.JobRun{
+		JobName:    j.name,
+		Runner:     s.runner,
+		Status:     models.JobRunSucceeded,
+		Processed:  processed,
+		StartedAt:  started,
+		FinishedAt: finished,
+		DurationMs: finished.Sub(started).Milliseconds(),
+	}
+	if err != nil {
+		msg := err.Error()
+		run.Status = models.JobRunFailed
+		run.Error = &msg
+		s.logger.Error("Background job failed",
+			zap.String("job", j.name),
+			zap.Int("processed", processed),
+			zap.Error(err),
+		)
+	} else if processed > 0 {
+		s.logger.Info("Background job completed",
+			zap.String("job", j.name),
+			zap.Int("processed", processed),
+			zap.Duration("duration", finished.Sub(started)),
+		)
+	}
+
+	metrics.JobRunsTotal.WithLabelValues(j.name, run.Status).Inc()
+	metrics.JobRunDuration.WithLabelValues(j.name).Observe(finished.Sub(started).Seconds())
+	metrics.JobItemsProcessedTotal.WithLabelValues(j.name).Add(float64(processed))
+
+	// Record the run even when shutting down, so the lease is released
+	// instead of blocking the job until it expires
+	completeCtx, cancelComplete := context.WithTimeout(context.WithoutCancel(ctx), completeTimeout)
+	defer cancelComplete()
+	if err := s.repo.Complete(completeCtx, run, j.schedule.Next(finished)); err != nil {
+		s.logger.Error("Failed to record background job run",
+			zap.String("job", j.name),
+			zap.Error(err),
+		)
+	}
+}
+
+// safeRun runs a job, turning a panic into an error so that one broken job
+// does not take down the replica
+func (s *Scheduler) safeRun(ctx context.Context, j *job) (processed int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return j.run(ctx)
+}
+
+// refreshMetrics exports the shared job state as gauges
+func (s *Scheduler) refreshMetrics(ctx context.Context) {
+	jobs, err := s.repo.List(ctx)
+	if err != nil {
+		return
+	}
+	for _, j := range jobs {
+		metrics.JobNextRunTimestamp.WithLabelValues(j.Name).Set(float64(j.NextRunAt.Unix()))
+		metrics.JobConsecutiveFailures.WithLabelValues(j.Name).Set(float64(j.ConsecutiveFailures))
+		if j.LastSuccessAt != nil {
+			metrics.JobLastSuccessTimestamp.WithLabelValues(j.Name).Set(float64(j.LastSuccessAt.Unix()))
+		}
+	}
+}
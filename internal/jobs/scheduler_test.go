@@ -0,0 +1,264 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryJobRepository mirrors the leasing rules of the Postgres repository
+type memoryJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]*models.ScheduledJob
+	runs []*models.JobRun
+}
+
+func newMemoryJobRepository() *memoryJobRepository {
+	return &memoryJobRepository{jobs: make(map[string]*models.ScheduledJob)}
+}
+
+func (r *memoryJobRepository) Register(ctx context.Context, job *models.ScheduledJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.jobs[job.Name]
+	if !ok {
+		job.Enabled = true
+		stored := *job
+		r.jobs[job.Name] = &stored
+		return nil
+	}
+	if existing.Schedule != job.Schedule {
+		existing.NextRunAt = job.NextRunAt
+	}
+	existing.Schedule = job.Schedule
+	existing.TimeoutSeconds = job.TimeoutSeconds
+	*job = *existing
+	return nil
+}
+
+func (r *memoryJobRepository) ClaimDue(ctx context.Context, names []string, runner string, grace time.Duration) (*models.ScheduledJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, name := range names {
+		job, ok := r.jobs[name]
+		if !ok || !job.Enabled || job.NextRunAt.After(now) || job.Running(now) {
+			continue
+		}
+		until := now.Add(time.Duration(job.TimeoutSeconds)*time.Second + grace)
+		job.LockedBy = &runner
+		job.LockedUntil = &until
+		claimed := *job
+		return &claimed, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryJobRepository) Complete(ctx context.Context, run *models.JobRun, nextRunAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.runs = append(r.runs, run)
+	job, ok := r.jobs[run.JobName]
+	if !ok || job.LockedBy == nil || *job.LockedBy != run.Runner {
+		return nil
+	}
+	job.LockedBy = nil
+	job.LockedUntil = nil
+	job.NextRunAt = nextRunAt
+	job.LastStatus = &run.Status
+	job.LastError = run.Error
+	job.TotalRuns++
+	if run.Status == models.JobRunSucceeded {
+		job.ConsecutiveFailures = 0
+	} else {
+		job.ConsecutiveFailures++
+		job.TotalFailures++
+	}
+	return nil
+}
+
+func (r *memoryJobRepository) List(ctx context.Context) ([]*models.ScheduledJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var jobs []*models.ScheduledJob
+	for _, job := range r.jobs {
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs, nil
+}
+
+func (r *memoryJobRepository) GetByName(ctx context.Context, name string) (*models.ScheduledJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("scheduled job not found")
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (r *memoryJobRepository) ListRuns(ctx context.Context, name string, limit int) ([]*models.JobRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var runs []*models.JobRun
+	for i := len(r.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		if r.runs[i].JobName == name {
+			runs = append(runs, r.runs[i])
+		}
+	}
+	return runs, nil
+}
+
+func (r *memoryJobRepository) TriggerNow(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[name]
+	if !ok {
+		return fmt.Errorf("scheduled job not found")
+	}
+	if job.Running(time.Now()) {
+		return fmt.Errorf("scheduled job is already running")
+	}
+	job.NextRunAt = time.Now()
+	return nil
+}
+
+func (r *memoryJobRepository) DeleteRunsBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+func TestScheduler_RunsDueJobOnOneReplica(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryJobRepository()
+
+	var calls int32
+	release := make(chan struct{})
+	replicas := make([]*Scheduler, 3)
+	for i := range replicas {
+		replicas[i] = NewScheduler(repo, zap.NewNop())
+		replicas[i].Register("cleanup", Every(time.Hour), time.Minute, func(ctx context.Context) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return 7, nil
+		})
+		_, err := replicas[i].RunDue(ctx)
+		require.NoError(t, err)
+	}
+	assert.Zero(t, atomic.LoadInt32(&calls), "a new job waits for its first scheduled time")
+
+	require.NoError(t, replicas[0].Trigger(ctx, "cleanup"))
+
+	started := 0
+	for _, replica := range replicas {
+		n, err := replica.RunDue(ctx)
+		require.NoError(t, err)
+		started += n
+	}
+	assert.Equal(t, 1, started)
+	assert.Error(t, replicas[1].Trigger(ctx, "cleanup"), "a running job cannot be triggered")
+
+	close(release)
+	for _, replica := range replicas {
+		replica.wg.Wait()
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	job, err := replicas[2].Get(ctx, "cleanup")
+	require.NoError(t, err)
+	assert.Nil(t, job.LockedBy)
+	assert.Equal(t, int64(1), job.TotalRuns)
+	assert.True(t, job.NextRunAt.After(time.Now().Add(59*time.Minute)))
+
+	runs, err := replicas[2].Runs(ctx, "cleanup", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.JobRunSucceeded, runs[0].Status)
+	assert.Equal(t, 7, runs[0].Processed)
+	assert.Equal(t, replicas[0].runner, runs[0].Runner)
+}
+
+func TestScheduler_RecordsFailuresAndPanics(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryJobRepository()
+	scheduler := NewScheduler(repo, zap.NewNop())
+	scheduler.Register("failing", Every(time.Hour), time.Minute, func(ctx context.Context) (int, error) {
+		return 2, errors.New("database unavailable")
+	})
+	scheduler.Register("panicking", Every(time.Hour), time.Minute, func(ctx context.Context) (int, error) {
+		panic("nil map")
+	})
+
+	_, err := scheduler.RunDue(ctx)
+	require.NoError(t, err)
+	require.NoError(t, scheduler.Trigger(ctx, "failing"))
+	require.NoError(t, scheduler.Trigger(ctx, "panicking"))
+
+	started, err := scheduler.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, started)
+	scheduler.wg.Wait()
+
+	failing, err := scheduler.Get(ctx, "failing")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobRunFailed, *failing.LastStatus)
+	assert.Equal(t, "database unavailable", *failing.LastError)
+	assert.Equal(t, 1, failing.ConsecutiveFailures)
+
+	panicking, err := scheduler.Get(ctx, "panicking")
+	require.NoError(t, err)
+	assert.Contains(t, *panicking.LastError, "job panicked: nil map")
+	assert.Nil(t, panicking.LockedBy, "the lease is released after a panic")
+}
+
+func TestScheduler_CancelsRunAtTimeout(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryJobRepository()
+	scheduler := NewScheduler(repo, zap.NewNop())
+	scheduler.Register("slow", Every(time.Hour), 10*time.Millisecond, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	_, err := scheduler.RunDue(ctx)
+	require.NoError(t, err)
+	require.NoError(t, scheduler.Trigger(ctx, "slow"))
+	_, err = scheduler.RunDue(ctx)
+	require.NoError(t, err)
+	scheduler.wg.Wait()
+
+	runs, err := scheduler.Runs(ctx, "slow", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.JobRunFailed, runs[0].Status)
+	assert.Contains(t, *runs[0].Error, "deadline exceeded")
+}
+
+func TestScheduler_RegisterTwicePanics(t *testing.T) {
+	scheduler := NewScheduler(newMemoryJobRepository(), zap.NewNop())
+	noop := func(ctx context.Context) (int, error) { return 0, nil }
+	scheduler.Register("cleanup", Every(time.Hour), time.Minute, noop)
+
+	assert.Panics(t, func() {
+		scheduler.Register("cleanup", Every(time.Hour), time.Minute, noop)
+	})
+}
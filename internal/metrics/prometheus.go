@@ -120,5 +120,85 @@ var (
 		},
 		[]string{"scope"},
 	)
-)
 
+	// Background job metrics. Run counters are per replica; the gauges are
+	// read from the shared job table, so every replica reports the same values.
+	JobRunsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "job_runs_total",
+			Help: "Total number of background job runs",
+		},
+		[]string{"job", "status"},
+	)
+
+	JobRunDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "job_run_duration_seconds",
+			Help:    "Background job run duration in seconds",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 900},
+		},
+		[]string{"job"},
+	)
+
+	JobItemsProcessedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "job_items_processed_total",
+			Help: "Total number of items processed by background jobs",
+		},
+		[]string{"job"},
+	)
+
+	JobLastSuccessTimestamp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "job_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful run of a background job",
+		},
+		[]string{"job"},
+	)
+
+	JobNextRunTimestamp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "job_next_run_timestamp_seconds",
+			Help: "Unix time a background job is next due",
+		},
+		[]string{"job"},
+	)
+
+	JobConsecutiveFailures = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "job_consecutive_failures",
+			Help: "Failed runs of a background job since its last successful run",
+		},
+		[]string{"job"},
+	)
+
+	OutboxEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_total",
+			Help: "Total number of outbox events handled by the relay",
+		},
+		[]string{"status"},
+	)
+
+	OutboxLagSeconds = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_lag_seconds",
+			Help: "Age of the oldest pending outbox event in seconds",
+		},
+	)
+
+	WebhookDeliveryAttemptsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_delivery_attempts_total",
+			Help: "Total number of webhook delivery attempts",
+		},
+		[]string{"status"},
+	)
+
+	WebhookWorkersBusy = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "webhook_workers_busy",
+			Help: "Number of webhook workers currently delivering to an endpoint",
+		},
+	)
+)
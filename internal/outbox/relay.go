@@ -0,0 +1,205 @@
+// Package outbox relays audit events from the transactional outbox to the
+// components that react to them, such as webhooks and SCIM connectors.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/metrics"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"go.uber.org/zap"
+)
+
+// DefaultPollInterval is how often the relay checks for new events
+const DefaultPollInterval = time.Second
+
+const (
+	// batchSize is how many events are read per query
+	batchSize = 100
+
+	// maxAttempts is how often an event is tried before it is given up on
+	maxAttempts = 12
+
+	// baseBackoff and maxBackoff bound the delay between attempts
+	baseBackoff = 5 * time.Second
+	maxBackoff  = 10 * time.Minute
+)
+
+// Handler reacts to an audit event. Events are delivered at least once, so
+// handlers must be idempotent: an event is handled again when the relay
+// stops before recording it, or when another handler failed on it.
+type Handler interface {
+	HandleEvent(ctx context.Context, event *models.AuditEvent) error
+}
+
+// Relay hands outbox events to every handler in the order they were
+// written. Only one replica relays at a time. When an event fails, the
+// tenant's later events wait until it succeeds or is given up on.
+type Relay struct {
+	repo     interfaces.OutboxRepository
+	handlers []Handler
+	logger   *zap.Logger
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(repo interfaces.OutboxRepository, logger *zap.Logger, handlers ...Handler) *Relay {
+	return &Relay{
+		repo:     repo,
+		handlers: handlers,
+		logger:   logger,
+	}
+}
+
+// Run relays due events every interval until ctx is cancelled
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.ProcessDue(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Error("Failed to relay outbox events", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ProcessDue relays every due event and returns how many were handled.
+// It does nothing while another replica is relaying.
+func (r *Relay) ProcessDue(ctx context.Context) (int, error) {
+	processed := 0
+	_, err := r.repo.WithRelayLock(ctx, func(ctx context.Context) error {
+		defer r.refreshLag(ctx)
+
+		for {
+			events, err := r.repo.ListDue(ctx, batchSize)
+			if err != nil {
+				return err
+			}
+
+			blocked := make(map[string]bool)
+			for _, event := range events {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				// Keep a tenant's events in order behind one that just failed
+				key := tenantKey(event)
+				if blocked[key] {
+					continue
+				}
+				status, err := r.relay(ctx, event)
+				if err != nil {
+					return err
+				}
+				switch status {
+				case models.OutboxStatusProcessed:
+					processed++
+				case models.OutboxStatusPending:
+					blocked[key] = true
+				}
+			}
+
+			if len(events) < batchSize {
+				return nil
+			}
+		}
+	})
+	return processed, err
+}
+
+// relay hands one event to every handler, records the outcome and returns
+// the event's new status
+func (r *Relay) relay(ctx context.Context, event *models.OutboxEvent) (string, error) {
+	handleErr := r.handle(ctx, event)
+	if handleErr == nil {
+		if err := r.repo.MarkProcessed(ctx, event.ID); err != nil {
+			return "", err
+		}
+		metrics.OutboxEventsTotal.WithLabelValues(models.OutboxStatusProcessed).Inc()
+		return models.OutboxStatusProcessed, nil
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	attempt := event.Attempts + 1
+	dead := attempt >= maxAttempts
+	if err := r.repo.MarkFailed(ctx, event.ID, handleErr.Error(), time.Now().Add(backoff(attempt)), dead); err != nil {
+		return "", err
+	}
+
+	if dead {
+		metrics.OutboxEventsTotal.WithLabelValues(models.OutboxStatusDead).Inc()
+		r.logger.Error("Giving up on outbox event",
+			zap.Int64("outbox_id", event.ID),
+			zap.String("event_type", event.EventType),
+			zap.Int("attempts", attempt),
+			zap.Error(handleErr))
+		return models.OutboxStatusDead, nil
+	}
+
+	metrics.OutboxEventsTotal.WithLabelValues("failed").Inc()
+	r.logger.Warn("Failed to handle outbox event",
+		zap.Int64("outbox_id", event.ID),
+		zap.String("event_type", event.EventType),
+		zap.Int("attempts", attempt),
+		zap.Error(handleErr))
+	return models.OutboxStatusPending, nil
+}
+
+// handle runs every handler, even after one fails
+func (r *Relay) handle(ctx context.Context, event *models.OutboxEvent) error {
+	var auditEvent models.AuditEvent
+	if err := json.Unmarshal(event.Payload, &auditEvent); err != nil {
+		return fmt.Errorf("failed to decode outbox event: %w", err)
+	}
+
+	var errs []error
+	for _, handler := range r.handlers {
+		if err := handler.HandleEvent(ctx, &auditEvent); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refreshLag reports how long the oldest pending event has been waiting
+func (r *Relay) refreshLag(ctx context.Context) {
+	oldest, err := r.repo.OldestPending(ctx)
+	if err != nil {
+		return
+	}
+	if oldest == nil {
+		metrics.OutboxLagSeconds.Set(0)
+		return
+	}
+	metrics.OutboxLagSeconds.Set(time.Since(*oldest).Seconds())
+}
+
+// backoff returns the delay before the given attempt is retried
+func backoff(attempt int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// tenantKey groups events that must be handled in order
+func tenantKey(event *models.OutboxEvent) string {
+	if event.TenantID == nil {
+		return ""
+	}
+	return event.TenantID.String()
+}
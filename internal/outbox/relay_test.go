@@ -0,0 +1,269 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryOutboxRepository mirrors the ordering rules of the Postgres repository
+type memoryOutboxRepository struct {
+	mu     sync.Mutex
+	locked bool
+	events []*models.OutboxEvent
+}
+
+func (r *memoryOutboxRepository) add(t *testing.T, tenantID *uuid.UUID, eventType string) *models.OutboxEvent {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	auditEvent := &models.AuditEvent{ID: uuid.New(), EventType: eventType, TenantID: tenantID}
+	payload, err := json.Marshal(auditEvent)
+	require.NoError(t, err)
+
+	event := &models.OutboxEvent{
+		ID:            int64(len(r.events) + 1),
+		EventID:       auditEvent.ID,
+		TenantID:      tenantID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+		CreatedAt:     time.Now(),
+	}
+	r.events = append(r.events, event)
+	return event
+}
+
+func (r *memoryOutboxRepository) WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	r.mu.Lock()
+	if r.locked {
+		r.mu.Unlock()
+		return false, nil
+	}
+	r.locked = true
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.locked = false
+		r.mu.Unlock()
+	}()
+	return true, fn(ctx)
+}
+
+func (r *memoryOutboxRepository) ListDue(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	waiting := make(map[string]bool)
+	var due []*models.OutboxEvent
+	for _, event := range r.events {
+		if event.Status != models.OutboxStatusPending {
+			continue
+		}
+		key := tenantKey(event)
+		if event.NextAttemptAt.After(now) {
+			waiting[key] = true
+			continue
+		}
+		if waiting[key] || len(due) == limit {
+			continue
+		}
+		copied := *event
+		due = append(due, &copied)
+	}
+	return due, nil
+}
+
+func (r *memoryOutboxRepository) find(id int64) *models.OutboxEvent {
+	for _, event := range r.events {
+		if event.ID == id {
+			return event
+		}
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) MarkProcessed(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event := r.find(id)
+	now := time.Now()
+	event.Status = models.OutboxStatusProcessed
+	event.Attempts++
+	event.ProcessedAt = &now
+	return nil
+}
+
+func (r *memoryOutboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event := r.find(id)
+	event.Attempts++
+	event.LastError = &lastError
+	event.NextAttemptAt = nextAttemptAt
+	if dead {
+		now := time.Now()
+		event.Status = models.OutboxStatusDead
+		event.ProcessedAt = &now
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) OldestPending(ctx context.Context) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range r.events {
+		if event.Status == models.OutboxStatusPending {
+			createdAt := event.CreatedAt
+			return &createdAt, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryOutboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+// makeDue lets a failed event be retried immediately
+func (r *memoryOutboxRepository) makeDue(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.find(id).NextAttemptAt = time.Now()
+}
+
+// recordingHandler records the events it sees and fails those in fail
+type recordingHandler struct {
+	mu   sync.Mutex
+	seen []string
+	fail map[string]bool
+}
+
+func (h *recordingHandler) HandleEvent(ctx context.Context, event *models.AuditEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seen = append(h.seen, event.EventType)
+	if h.fail[event.EventType] {
+		return errors.New("consumer unavailable")
+	}
+	return nil
+}
+
+func TestRelay_HandsEventsToEveryHandlerInOrder(t *testing.T) {
+	repo := &memoryOutboxRepository{}
+	tenantID := uuid.New()
+	repo.add(t, &tenantID, models.EventTypeUserCreated)
+	repo.add(t, nil, models.EventTypeTenantCreated)
+	repo.add(t, &tenantID, models.EventTypeUserUpdated)
+
+	first, second := &recordingHandler{}, &recordingHandler{}
+	relay := NewRelay(repo, zap.NewNop(), first, second)
+
+	processed, err := relay.ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, processed)
+
+	want := []string{models.EventTypeUserCreated, models.EventTypeTenantCreated, models.EventTypeUserUpdated}
+	assert.Equal(t, want, first.seen)
+	assert.Equal(t, want, second.seen)
+	for _, event := range repo.events {
+		assert.Equal(t, models.OutboxStatusProcessed, event.Status)
+	}
+
+	processed, err = relay.ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+}
+
+func TestRelay_FailureHoldsBackTenantsLaterEvents(t *testing.T) {
+	repo := &memoryOutboxRepository{}
+	tenantA, tenantB := uuid.New(), uuid.New()
+	failing := repo.add(t, &tenantA, models.EventTypeUserCreated)
+	repo.add(t, &tenantA, models.EventTypeUserUpdated)
+	repo.add(t, &tenantB, models.EventTypeRoleCreated)
+
+	handler := &recordingHandler{fail: map[string]bool{models.EventTypeUserCreated: true}}
+	relay := NewRelay(repo, zap.NewNop(), handler)
+
+	processed, err := relay.ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, []string{models.EventTypeUserCreated, models.EventTypeRoleCreated}, handler.seen)
+	assert.Equal(t, 1, failing.Attempts)
+	assert.True(t, failing.NextAttemptAt.After(time.Now()))
+	require.NotNil(t, failing.LastError)
+	assert.Contains(t, *failing.LastError, "consumer unavailable")
+
+	// Nothing of tenant A moves until its first event is retried
+	processed, err = relay.ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+
+	handler.fail = nil
+	repo.makeDue(failing.ID)
+	processed, err = relay.ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, []string{
+		models.EventTypeUserCreated, models.EventTypeRoleCreated,
+		models.EventTypeUserCreated, models.EventTypeUserUpdated,
+	}, handler.seen)
+}
+
+func TestRelay_GivesUpAfterMaxAttempts(t *testing.T) {
+	repo := &memoryOutboxRepository{}
+	tenantID := uuid.New()
+	failing := repo.add(t, &tenantID, models.EventTypeUserCreated)
+	next := repo.add(t, &tenantID, models.EventTypeUserUpdated)
+
+	handler := &recordingHandler{fail: map[string]bool{models.EventTypeUserCreated: true}}
+	relay := NewRelay(repo, zap.NewNop(), handler)
+
+	for i := 0; i < maxAttempts-1; i++ {
+		_, err := relay.ProcessDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, models.OutboxStatusPending, failing.Status)
+		repo.makeDue(failing.ID)
+	}
+
+	processed, err := relay.ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed, "the tenant's next event is no longer held back")
+	assert.Equal(t, models.OutboxStatusDead, failing.Status)
+	assert.Equal(t, maxAttempts, failing.Attempts)
+	assert.Equal(t, models.OutboxStatusProcessed, next.Status)
+}
+
+func TestRelay_SkipsWhileAnotherRelayHoldsTheLock(t *testing.T) {
+	repo := &memoryOutboxRepository{locked: true}
+	repo.add(t, nil, models.EventTypeTenantCreated)
+
+	handler := &recordingHandler{}
+	processed, err := NewRelay(repo, zap.NewNop(), handler).ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+	assert.Empty(t, handler.seen)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, baseBackoff, backoff(1))
+	assert.Equal(t, 2*baseBackoff, backoff(2))
+	assert.Equal(t, maxBackoff, backoff(maxAttempts))
+}
@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
@@ -27,6 +28,11 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) LockByID(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) GetByUsername(ctx context.Context, username string, tenantID uuid.UUID) (*models.User, error) {
 	args := m.Called(ctx, username, tenantID)
 	if args.Get(0) == nil {
@@ -48,6 +54,11 @@ func (m *MockUserRepository) Update(ctx context.Context, u *models.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	args := m.Called(ctx, id, loginAt)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
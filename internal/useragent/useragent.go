@@ -0,0 +1,184 @@
+package useragent
+
+import (
+	"strings"
+)
+
+// Device types reported by Parse
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeBot     = "bot"
+	DeviceTypeOther   = "other"
+)
+
+// Info is a coarse description of the client behind a User-Agent header.
+// It is intended for display in session lists, not for feature detection.
+type Info struct {
+	Browser        string `json:"browser,omitempty"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	OS             string `json:"os,omitempty"`
+	DeviceType     string `json:"device_type,omitempty"`
+}
+
+// String returns a short human readable summary, e.g. "Chrome 120 on macOS"
+func (i Info) String() string {
+	browser := i.Browser
+	if browser != "" && i.BrowserVersion != "" {
+		browser += " " + i.BrowserVersion
+	}
+
+	switch {
+	case browser != "" && i.OS != "":
+		return browser + " on " + i.OS
+	case browser != "":
+		return browser
+	case i.OS != "":
+		return i.OS
+	default:
+		return "Unknown device"
+	}
+}
+
+// browserTokens is checked in order; more specific products must come first
+// because most browsers also advertise "Chrome" and/or "Safari".
+var browserTokens = []struct {
+	token string
+	name  string
+}{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"PostmanRuntime/", "Postman"},
+	{"curl/", "curl"},
+	{"Go-http-client/", "Go HTTP client"},
+	{"okhttp/", "OkHttp"},
+	{"python-requests/", "Python Requests"},
+}
+
+// nonBrowserClients are API clients rather than interactive browsers
+var nonBrowserClients = map[string]bool{
+	"Postman":         true,
+	"curl":            true,
+	"Go HTTP client":  true,
+	"OkHttp":          true,
+	"Python Requests": true,
+}
+
+// Parse extracts browser, OS and device type from a User-Agent header.
+// Unknown or empty values yield a zero Info rather than an error.
+func Parse(ua string) Info {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return Info{}
+	}
+
+	info := Info{
+		OS: parseOS(ua),
+	}
+	info.Browser, info.BrowserVersion = parseBrowser(ua)
+	info.DeviceType = parseDeviceType(ua, info.Browser)
+
+	return info
+}
+
+func parseBrowser(ua string) (name, version string) {
+	for _, bt := range browserTokens {
+		if idx := strings.Index(ua, bt.token); idx >= 0 {
+			return bt.name, majorVersion(ua[idx+len(bt.token):])
+		}
+	}
+
+	// Safari is identified by "Version/x" together with "Safari/"
+	if strings.Contains(ua, "Safari/") {
+		if idx := strings.Index(ua, "Version/"); idx >= 0 {
+			return "Safari", majorVersion(ua[idx+len("Version/"):])
+		}
+		return "Safari", ""
+	}
+
+	return "", ""
+}
+
+func parseOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "iPad"):
+		return withVersion("iPadOS", versionAfter(ua, "CPU OS "))
+	case strings.Contains(ua, "iPhone"):
+		return withVersion("iOS", versionAfter(ua, "iPhone OS "))
+	case strings.Contains(ua, "Android"):
+		return withVersion("Android", versionAfter(ua, "Android "))
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	default:
+		return ""
+	}
+}
+
+func parseDeviceType(ua, browser string) string {
+	lower := strings.ToLower(ua)
+	switch {
+	case strings.Contains(lower, "bot"), strings.Contains(lower, "crawler"), strings.Contains(lower, "spider"):
+		return DeviceTypeBot
+	case nonBrowserClients[browser]:
+		return DeviceTypeOther
+	case strings.Contains(ua, "iPad"), strings.Contains(ua, "Tablet"),
+		strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile"):
+		return DeviceTypeTablet
+	case strings.Contains(ua, "Mobi"), strings.Contains(ua, "iPhone"):
+		return DeviceTypeMobile
+	case browser == "":
+		return DeviceTypeOther
+	default:
+		return DeviceTypeDesktop
+	}
+}
+
+// versionAfter returns the major.minor version following marker, accepting
+// both "." and "_" as separators (iOS uses underscores)
+func versionAfter(ua, marker string) string {
+	idx := strings.Index(ua, marker)
+	if idx < 0 {
+		return ""
+	}
+	rest := ua[idx+len(marker):]
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.' && r != '_'
+	})
+	if end >= 0 {
+		rest = rest[:end]
+	}
+	parts := strings.FieldsFunc(rest, func(r rune) bool { return r == '.' || r == '_' })
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, ".")
+}
+
+func majorVersion(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
+
+func withVersion(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + " " + version
+}
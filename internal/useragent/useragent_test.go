@@ -0,0 +1,73 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		ua      string
+		want    Info
+		summary string
+	}{
+		{
+			name:    "Chrome on macOS",
+			ua:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want:    Info{Browser: "Chrome", BrowserVersion: "120", OS: "macOS", DeviceType: DeviceTypeDesktop},
+			summary: "Chrome 120 on macOS",
+		},
+		{
+			name:    "Edge on Windows",
+			ua:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			want:    Info{Browser: "Edge", BrowserVersion: "120", OS: "Windows", DeviceType: DeviceTypeDesktop},
+			summary: "Edge 120 on Windows",
+		},
+		{
+			name:    "Firefox on Linux",
+			ua:      "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			want:    Info{Browser: "Firefox", BrowserVersion: "121", OS: "Linux", DeviceType: DeviceTypeDesktop},
+			summary: "Firefox 121 on Linux",
+		},
+		{
+			name:    "Safari on iPhone",
+			ua:      "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			want:    Info{Browser: "Safari", BrowserVersion: "17", OS: "iOS 17.1", DeviceType: DeviceTypeMobile},
+			summary: "Safari 17 on iOS 17.1",
+		},
+		{
+			name: "Chrome on Android tablet",
+			ua:   "Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want: Info{Browser: "Chrome", BrowserVersion: "120", OS: "Android 14", DeviceType: DeviceTypeTablet},
+		},
+		{
+			name:    "curl",
+			ua:      "curl/8.4.0",
+			want:    Info{Browser: "curl", BrowserVersion: "8", DeviceType: DeviceTypeOther},
+			summary: "curl 8",
+		},
+		{
+			name: "crawler",
+			ua:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: Info{DeviceType: DeviceTypeBot},
+		},
+		{
+			name:    "empty",
+			ua:      "",
+			want:    Info{},
+			summary: "Unknown device",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.ua)
+			assert.Equal(t, tt.want, got)
+			if tt.summary != "" {
+				assert.Equal(t, tt.summary, got.String())
+			}
+		})
+	}
+}
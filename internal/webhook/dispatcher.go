@@ -3,17 +3,17 @@ package webhook
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/pkg/webhooks"
 	"github.com/arauth-identity/iam/storage/interfaces"
 	"go.uber.org/zap"
 )
@@ -43,47 +43,23 @@ func NewDispatcher(
 	}
 }
 
-// Deliver sends a webhook payload to the specified URL
-func (d *Dispatcher) Deliver(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}, eventID *uuid.UUID) error {
-	// Create webhook payload
-	webhookPayload := models.WebhookPayload{
-		ID:        uuid.New().String(),
-		EventType: eventType,
-		Timestamp: time.Now(),
-		Data:      payload,
-	}
-
-	// Marshal payload
-	payloadJSON, err := json.Marshal(webhookPayload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	// Sign payload with HMAC-SHA256
-	signature := d.signPayload(payloadJSON, w.Secret)
-
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(payloadJSON))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+// maxResponseBody caps how much of an endpoint's response is kept on a delivery
+const maxResponseBody = 4096
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-Signature", signature)
-	req.Header.Set("X-Webhook-Event", eventType)
-	req.Header.Set("X-Webhook-ID", webhookPayload.ID)
-
-	// Send request
-	resp, err := d.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+// Deliver sends an event to a webhook and records the delivery. Failed
+// deliveries are scheduled for retry with exponential backoff.
+func (d *Dispatcher) Deliver(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}, eventID *uuid.UUID) (*models.WebhookDelivery, error) {
+	return d.deliver(ctx, w, eventType, payload, eventID, true)
+}
 
-	// Read response body
-	responseBody, _ := io.ReadAll(resp.Body)
+// Ping sends a single test event to a webhook and records the delivery.
+// Failed pings are not retried.
+func (d *Dispatcher) Ping(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}) (*models.WebhookDelivery, error) {
+	return d.deliver(ctx, w, eventType, payload, nil, false)
+}
 
-	// Create delivery record
+// deliver creates a delivery record for a first attempt
+func (d *Dispatcher) deliver(ctx context.Context, w *models.Webhook, eventType string, payload map[string]interface{}, eventID *uuid.UUID, retry bool) (*models.WebhookDelivery, error) {
 	delivery := &models.WebhookDelivery{
 		ID:            uuid.New(),
 		WebhookID:     w.ID,
@@ -91,160 +67,127 @@ func (d *Dispatcher) Deliver(ctx context.Context, w *models.Webhook, eventType s
 		EventType:     eventType,
 		Payload:       payload,
 		Status:        models.DeliveryStatusPending,
-		HTTPStatusCode: &resp.StatusCode,
-		AttemptNumber:  1,
+		AttemptNumber: 1,
 	}
+	d.attempt(ctx, w, delivery, retry)
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		// Success
-		delivery.Status = models.DeliveryStatusSuccess
-		now := time.Now()
-		delivery.DeliveredAt = &now
-	} else {
-		// Failed
-		delivery.Status = models.DeliveryStatusFailed
-		responseBodyStr := string(responseBody)
-		delivery.ResponseBody = &responseBodyStr
-
-		// Schedule retry if not exceeded max retries
-		if delivery.AttemptNumber < d.maxRetries {
-			delivery.Status = models.DeliveryStatusRetrying
-			nextRetry := time.Now().Add(d.calculateBackoff(delivery.AttemptNumber))
-			delivery.NextRetryAt = &nextRetry
-		}
-	}
-
-	// Save delivery record
 	if err := d.deliveryRepo.Create(ctx, delivery); err != nil {
-		d.logger.Error("Failed to create delivery record", zap.Error(err))
+		return delivery, fmt.Errorf("failed to create delivery record: %w", err)
 	}
+	return delivery, nil
+}
 
+// Attempt sends a queued delivery and updates its record. A failed attempt
+// leaves the delivery queued for another attempt until retries run out.
+func (d *Dispatcher) Attempt(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) error {
+	delivery.AttemptNumber++
+	d.attempt(ctx, w, delivery, true)
+
+	if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
 	return nil
 }
 
-// RetryFailedDeliveries retries failed webhook deliveries
-func (d *Dispatcher) RetryFailedDeliveries(ctx context.Context, webhookRepo interfaces.WebhookRepository) error {
-	// Get pending retries
-	before := time.Now()
-	deliveries, err := d.deliveryRepo.GetPendingRetries(ctx, before)
-	if err != nil {
-		return fmt.Errorf("failed to get pending retries: %w", err)
+// attempt posts the delivery to the webhook and records the outcome on it.
+// The payload and webhook-id are the delivery ID, so retries of one delivery
+// carry the same ID and receivers can discard duplicates.
+func (d *Dispatcher) attempt(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery, retry bool) {
+	statusCode, body, err := d.send(ctx, w, delivery)
+	delivery.HTTPStatusCode = statusCode
+	delivery.ResponseBody = nil
+	if body != "" {
+		delivery.ResponseBody = &body
 	}
 
-	for _, delivery := range deliveries {
-		// Get webhook
-		w, err := webhookRepo.GetByID(ctx, delivery.WebhookID)
-		if err != nil {
-			d.logger.Error("Failed to get webhook for retry", zap.Error(err))
-			continue
-		}
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.DeliveryStatusSuccess
+		delivery.DeliveredAt = &now
+		delivery.NextRetryAt = nil
+		return
+	}
 
-		// Skip if webhook is disabled
-		if !w.Enabled {
-			delivery.Status = models.DeliveryStatusFailed
-			delivery.NextRetryAt = nil
-			if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
-				d.logger.Error("Failed to update delivery", zap.Error(err))
-			}
-			continue
-		}
+	d.logger.Debug("Webhook delivery attempt failed",
+		zap.String("webhook_id", w.ID.String()),
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.Int("attempt", delivery.AttemptNumber),
+		zap.Error(err),
+	)
+	if delivery.ResponseBody == nil {
+		msg := err.Error()
+		delivery.ResponseBody = &msg
+	}
 
-		// Retry delivery
-		delivery.AttemptNumber++
+	// Schedule retry if not exceeded max retries
+	if retry && delivery.AttemptNumber < d.maxRetries {
 		delivery.Status = models.DeliveryStatusRetrying
+		nextRetry := time.Now().Add(d.calculateBackoff(delivery.AttemptNumber))
+		delivery.NextRetryAt = &nextRetry
+		return
+	}
+	delivery.Status = models.DeliveryStatusFailed
+	delivery.NextRetryAt = nil
+}
 
-		// Marshal payload
-		webhookPayload := models.WebhookPayload{
-			ID:        uuid.New().String(),
-			EventType: delivery.EventType,
-			Timestamp: time.Now(),
-			Data:      delivery.Payload,
-		}
-
-		payloadJSON, err := json.Marshal(webhookPayload)
-		if err != nil {
-			delivery.Status = models.DeliveryStatusFailed
-			delivery.NextRetryAt = nil
-			if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
-				d.logger.Error("Failed to update delivery", zap.Error(err))
-			}
-			continue
-		}
-
-		// Sign payload
-		signature := d.signPayload(payloadJSON, w.Secret)
-
-		// Create HTTP request
-		req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(payloadJSON))
-		if err != nil {
-			delivery.Status = models.DeliveryStatusFailed
-			delivery.NextRetryAt = nil
-			if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
-				d.logger.Error("Failed to update delivery", zap.Error(err))
-			}
-			continue
-		}
+// send posts a payload signed following the Standard Webhooks scheme. It
+// returns an error for transport failures and non-2xx responses.
+func (d *Dispatcher) send(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) (*int, string, error) {
+	now := time.Now()
+	webhookPayload := models.WebhookPayload{
+		ID:        delivery.ID.String(),
+		EventType: delivery.EventType,
+		Timestamp: now,
+		Data:      delivery.Payload,
+	}
 
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("X-Webhook-Signature", signature)
-		req.Header.Set("X-Webhook-Event", delivery.EventType)
-		req.Header.Set("X-Webhook-ID", webhookPayload.ID)
+	payloadJSON, err := json.Marshal(webhookPayload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
 
-		// Send request
-		resp, err := d.httpClient.Do(req)
-		if err != nil {
-			// Schedule next retry
-			if delivery.AttemptNumber < d.maxRetries {
-				nextRetry := time.Now().Add(d.calculateBackoff(delivery.AttemptNumber))
-				delivery.NextRetryAt = &nextRetry
-			} else {
-				delivery.Status = models.DeliveryStatusFailed
-				delivery.NextRetryAt = nil
-			}
-			if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
-				d.logger.Error("Failed to update delivery", zap.Error(err))
-			}
-			continue
-		}
-		defer resp.Body.Close()
+	signature, err := d.sign(w, webhookPayload.ID, now, payloadJSON)
+	if err != nil {
+		return nil, "", err
+	}
 
-		// Read response
-		responseBody, _ := io.ReadAll(resp.Body)
-		responseBodyStr := string(responseBody)
-		delivery.HTTPStatusCode = &resp.StatusCode
-		delivery.ResponseBody = &responseBodyStr
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(payloadJSON))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
 
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			// Success
-			delivery.Status = models.DeliveryStatusSuccess
-			now := time.Now()
-			delivery.DeliveredAt = &now
-			delivery.NextRetryAt = nil
-		} else {
-			// Failed - schedule next retry
-			if delivery.AttemptNumber < d.maxRetries {
-				delivery.Status = models.DeliveryStatusRetrying
-				nextRetry := time.Now().Add(d.calculateBackoff(delivery.AttemptNumber))
-				delivery.NextRetryAt = &nextRetry
-			} else {
-				delivery.Status = models.DeliveryStatusFailed
-				delivery.NextRetryAt = nil
-			}
-		}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.HeaderID, webhookPayload.ID)
+	req.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(webhooks.HeaderSignature, signature)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
 
-		if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
-			d.logger.Error("Failed to update delivery", zap.Error(err))
-		}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to send request: %w", err)
 	}
+	defer resp.Body.Close()
 
-	return nil
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	statusCode := resp.StatusCode
+	if statusCode < 200 || statusCode >= 300 {
+		return &statusCode, string(responseBody), fmt.Errorf("endpoint returned HTTP %d", statusCode)
+	}
+	return &statusCode, string(responseBody), nil
 }
 
-// signPayload signs a payload with HMAC-SHA256
-func (d *Dispatcher) signPayload(payload []byte, secret string) string {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	return hex.EncodeToString(mac.Sum(nil))
+// sign returns the webhook-signature header: a signature with the current
+// secret and, while a rotation overlaps, one with the previous secret
+func (d *Dispatcher) sign(w *models.Webhook, id string, timestamp time.Time, payload []byte) (string, error) {
+	var signatures []string
+	for _, secret := range w.SigningSecrets(timestamp) {
+		signer, err := webhooks.NewSigner(secret)
+		if err != nil {
+			return "", fmt.Errorf("failed to load signing secret: %w", err)
+		}
+		signatures = append(signatures, signer.Sign(id, timestamp, payload))
+	}
+	return strings.Join(signatures, " "), nil
 }
 
 // calculateBackoff calculates exponential backoff delay
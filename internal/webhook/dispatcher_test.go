@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/pkg/webhooks"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// stubDeliveryRepository keeps the last delivery written
+type stubDeliveryRepository struct {
+	interfaces.WebhookDeliveryRepository
+	created []*models.WebhookDelivery
+	updated []*models.WebhookDelivery
+}
+
+func (r *stubDeliveryRepository) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.created = append(r.created, delivery)
+	return nil
+}
+
+func (r *stubDeliveryRepository) Update(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.updated = append(r.updated, delivery)
+	return nil
+}
+
+func TestDispatcher_RecordsUnreachableEndpoints(t *testing.T) {
+	repo := &stubDeliveryRepository{}
+	dispatcher := NewDispatcher(repo, zap.NewNop())
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	w := &models.Webhook{ID: uuid.New(), URL: server.URL, Secret: "secret"}
+	delivery, err := dispatcher.Deliver(context.Background(), w, models.EventTypeUserCreated, map[string]interface{}{}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, repo.created, 1, "transport failures are recorded too")
+	assert.Equal(t, models.DeliveryStatusRetrying, delivery.Status)
+	assert.NotNil(t, delivery.NextRetryAt)
+	assert.Nil(t, delivery.HTTPStatusCode)
+	require.NotNil(t, delivery.ResponseBody)
+	assert.Contains(t, *delivery.ResponseBody, "failed to send request")
+
+	ping, err := dispatcher.Ping(context.Background(), w, "webhook.test", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryStatusFailed, ping.Status, "test events are not retried")
+	assert.Nil(t, ping.NextRetryAt)
+}
+
+func TestDispatcher_RetryKeepsPayloadID(t *testing.T) {
+	var ids []string
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var payload models.WebhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		ids = append(ids, payload.ID)
+		assert.Equal(t, payload.ID, r.Header.Get(webhooks.HeaderID))
+		rw.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	repo := &stubDeliveryRepository{}
+	dispatcher := NewDispatcher(repo, zap.NewNop())
+	w := &models.Webhook{ID: uuid.New(), URL: server.URL, Secret: "secret"}
+
+	delivery, err := dispatcher.Deliver(context.Background(), w, models.EventTypeUserCreated, map[string]interface{}{"id": "1"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryStatusRetrying, delivery.Status)
+	require.NotNil(t, delivery.HTTPStatusCode)
+	assert.Equal(t, http.StatusInternalServerError, *delivery.HTTPStatusCode)
+
+	status = http.StatusNoContent
+	require.NoError(t, dispatcher.Attempt(context.Background(), w, delivery))
+	assert.Equal(t, models.DeliveryStatusSuccess, delivery.Status)
+	assert.Equal(t, 2, delivery.AttemptNumber)
+	assert.Nil(t, delivery.NextRetryAt)
+
+	require.Len(t, ids, 2)
+	assert.Equal(t, delivery.ID.String(), ids[0])
+	assert.Equal(t, ids[0], ids[1], "receivers can discard duplicate attempts")
+}
+
+func TestDispatcher_SignsWithCurrentAndPreviousSecret(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	dispatcher := NewDispatcher(&stubDeliveryRepository{}, zap.NewNop())
+	oldSecret := "old-secret-old-secret-old-secret"
+	expiresAt := time.Now().Add(time.Hour)
+	w := &models.Webhook{
+		ID:                      uuid.New(),
+		URL:                     server.URL,
+		Secret:                  "new-secret-new-secret-new-secret",
+		PreviousSecret:          &oldSecret,
+		PreviousSecretExpiresAt: &expiresAt,
+	}
+
+	_, err := dispatcher.Deliver(context.Background(), w, models.EventTypeUserCreated, map[string]interface{}{}, nil)
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(header.Get(webhooks.HeaderSignature)), 2)
+	for _, secret := range []string{w.Secret, oldSecret} {
+		verifier, err := webhooks.NewVerifier(secret)
+		require.NoError(t, err)
+		assert.NoError(t, verifier.Verify(header, body))
+	}
+
+	// Once the overlap has ended only the current secret signs
+	expiresAt = time.Now().Add(-time.Minute)
+	_, err = dispatcher.Deliver(context.Background(), w, models.EventTypeUserCreated, map[string]interface{}{}, nil)
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(header.Get(webhooks.HeaderSignature)), 1)
+	verifier, err := webhooks.NewVerifier(oldSecret)
+	require.NoError(t, err)
+	assert.ErrorIs(t, verifier.Verify(header, body), webhooks.ErrNoMatchingSignature)
+}
+
+func TestDispatcher_SignsWithEd25519(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	signingKey, err := webhooks.GenerateSigningKey()
+	require.NoError(t, err)
+	signer, err := webhooks.NewSigner(signingKey)
+	require.NoError(t, err)
+
+	dispatcher := NewDispatcher(&stubDeliveryRepository{}, zap.NewNop())
+	w := &models.Webhook{ID: uuid.New(), URL: server.URL, Secret: signingKey, SigningAlgorithm: models.WebhookSigningEd25519}
+	delivery, err := dispatcher.Deliver(context.Background(), w, models.EventTypeUserCreated, map[string]interface{}{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryStatusSuccess, delivery.Status)
+
+	verifier, err := webhooks.NewVerifier(signer.PublicKey())
+	require.NoError(t, err)
+	assert.NoError(t, verifier.Verify(header, body))
+}
@@ -0,0 +1,7 @@
+-- Rollback: Remove device/session metadata from refresh tokens
+
+ALTER TABLE refresh_tokens
+DROP COLUMN IF EXISTS last_used_at,
+DROP COLUMN IF EXISTS device_info,
+DROP COLUMN IF EXISTS user_agent,
+DROP COLUMN IF EXISTS ip_address;
@@ -0,0 +1,13 @@
+-- Migration: Record device/session metadata on refresh tokens
+-- Each refresh token represents a session; these columns back the session list
+
+ALTER TABLE refresh_tokens
+ADD COLUMN ip_address VARCHAR(45),
+ADD COLUMN user_agent TEXT,
+ADD COLUMN device_info VARCHAR(255),
+ADD COLUMN last_used_at TIMESTAMP;
+
+COMMENT ON COLUMN refresh_tokens.ip_address IS 'Client IP address the token was issued to';
+COMMENT ON COLUMN refresh_tokens.user_agent IS 'Raw User-Agent header the token was issued to';
+COMMENT ON COLUMN refresh_tokens.device_info IS 'Parsed device summary, e.g. "Chrome 120 on macOS"';
+COMMENT ON COLUMN refresh_tokens.last_used_at IS 'Last time the session was used (issued or rotated)';
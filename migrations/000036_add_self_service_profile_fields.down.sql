@@ -0,0 +1,3 @@
+-- Rollback: Remove self-service profile field configuration
+
+ALTER TABLE tenant_settings DROP COLUMN IF EXISTS self_service_profile_fields;
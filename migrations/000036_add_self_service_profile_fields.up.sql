@@ -0,0 +1,7 @@
+-- Migration: Let tenant admins choose which profile fields users may edit themselves
+-- Used by the /api/v1/me self-service API
+
+ALTER TABLE tenant_settings
+ADD COLUMN self_service_profile_fields TEXT[] NOT NULL DEFAULT '{first_name,last_name}';
+
+COMMENT ON COLUMN tenant_settings.self_service_profile_fields IS 'Profile fields users may update via /me (username, email, first_name, last_name)';
@@ -0,0 +1,16 @@
+-- Rollback: Drop MFA factors table
+-- Restore the single-secret column from each user's oldest verified factor
+
+UPDATE users u
+SET mfa_secret_encrypted = f.secret_encrypted
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, secret_encrypted
+    FROM mfa_factors
+    WHERE verified = true
+    ORDER BY user_id, created_at
+) f
+WHERE u.id = f.user_id;
+
+COMMENT ON COLUMN users.mfa_secret_encrypted IS NULL;
+
+DROP TABLE IF EXISTS mfa_factors;
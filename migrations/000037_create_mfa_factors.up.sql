@@ -0,0 +1,30 @@
+-- Migration: Create MFA factors table
+-- Users can enroll several named factors; the single users.mfa_secret_encrypted
+-- column is superseded and only kept for rollback
+
+CREATE TABLE mfa_factors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL DEFAULT 'totp',
+    name VARCHAR(255) NOT NULL,
+    secret_encrypted TEXT NOT NULL,
+    verified BOOLEAN NOT NULL DEFAULT false,
+    verified_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT mfa_factors_user_name_unique UNIQUE (user_id, name)
+);
+
+CREATE INDEX idx_mfa_factors_user_id ON mfa_factors(user_id);
+
+-- Carry over existing TOTP enrollments
+INSERT INTO mfa_factors (user_id, type, name, secret_encrypted, verified, verified_at, created_at, updated_at)
+SELECT id, 'totp', 'Authenticator app', mfa_secret_encrypted, mfa_enabled,
+       CASE WHEN mfa_enabled THEN NOW() END, NOW(), NOW()
+FROM users
+WHERE mfa_secret_encrypted IS NOT NULL;
+
+COMMENT ON TABLE mfa_factors IS 'Enrolled MFA factors; a user may have several named factors';
+COMMENT ON COLUMN mfa_factors.verified IS 'Factor becomes usable for login after its first successful verification';
+COMMENT ON COLUMN users.mfa_secret_encrypted IS 'Deprecated: superseded by mfa_factors';
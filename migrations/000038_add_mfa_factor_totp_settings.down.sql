@@ -0,0 +1,6 @@
+-- Rollback: Remove per-factor TOTP settings
+
+ALTER TABLE mfa_factors
+    DROP COLUMN IF EXISTS period,
+    DROP COLUMN IF EXISTS digits,
+    DROP COLUMN IF EXISTS algorithm;
@@ -0,0 +1,12 @@
+-- Migration: Store TOTP settings per MFA factor
+-- Factors keep validating with the parameters they were enrolled with when the
+-- configured defaults change
+
+ALTER TABLE mfa_factors
+    ADD COLUMN algorithm VARCHAR(10) NOT NULL DEFAULT 'SHA1',
+    ADD COLUMN digits INTEGER NOT NULL DEFAULT 6,
+    ADD COLUMN period INTEGER NOT NULL DEFAULT 30;
+
+COMMENT ON COLUMN mfa_factors.algorithm IS 'TOTP HMAC algorithm the factor was enrolled with (SHA1, SHA256, SHA512)';
+COMMENT ON COLUMN mfa_factors.digits IS 'TOTP code length the factor was enrolled with';
+COMMENT ON COLUMN mfa_factors.period IS 'TOTP time step in seconds the factor was enrolled with';
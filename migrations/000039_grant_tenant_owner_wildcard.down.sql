@@ -0,0 +1,5 @@
+-- Rollback: Remove the tenant_owner wildcard permission
+-- Role assignments are removed by ON DELETE CASCADE
+
+DELETE FROM permissions
+WHERE resource = '*' AND action = '*' AND tenant_id IS NOT NULL;
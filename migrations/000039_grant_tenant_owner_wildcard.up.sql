@@ -0,0 +1,16 @@
+-- Migration: Grant tenant_owner a wildcard permission
+-- tenant_owner holds *:* instead of every permission listed one by one, so
+-- permissions added later are covered without re-attaching them
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT DISTINCT r.tenant_id, 'tenant.all.' || r.tenant_id, 'All permissions in the tenant', '*', '*', NOW(), NOW()
+FROM roles r
+WHERE r.name = 'tenant_owner' AND r.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.tenant_id = r.tenant_id AND p.resource = '*' AND p.action = '*'
+WHERE r.name = 'tenant_owner' AND r.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_role_parents_parent_role_id;
+DROP INDEX IF EXISTS idx_role_parents_role_id;
+DROP TABLE IF EXISTS role_parents;
@@ -0,0 +1,14 @@
+-- Migration: Role inheritance
+-- A role inherits every permission of its parent roles (and their parents).
+-- The graph must stay acyclic; cycles are rejected by the role service.
+CREATE TABLE role_parents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    parent_role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(role_id, parent_role_id),
+    CHECK (role_id <> parent_role_id)
+);
+
+CREATE INDEX idx_role_parents_role_id ON role_parents(role_id);
+CREATE INDEX idx_role_parents_parent_role_id ON role_parents(parent_role_id);
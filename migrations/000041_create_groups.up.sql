@@ -0,0 +1,49 @@
+-- Migration: Groups
+-- Tenant-scoped groups of users. Groups can be nested (a member group's users
+-- are members of the containing group) and roles granted to a group reach
+-- every direct and nested member.
+CREATE TABLE groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    external_id VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_groups_tenant_name ON groups(tenant_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_groups_tenant_external_id ON groups(tenant_id, external_id) WHERE deleted_at IS NULL AND external_id IS NOT NULL;
+CREATE INDEX idx_groups_tenant_id ON groups(tenant_id);
+
+CREATE TABLE group_members (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(group_id, user_id)
+);
+
+CREATE INDEX idx_group_members_user_id ON group_members(user_id);
+
+CREATE TABLE group_member_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    member_group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(group_id, member_group_id),
+    CHECK (group_id <> member_group_id)
+);
+
+CREATE INDEX idx_group_member_groups_member ON group_member_groups(member_group_id);
+
+CREATE TABLE group_roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(group_id, role_id)
+);
+
+CREATE INDEX idx_group_roles_role_id ON group_roles(role_id);
@@ -0,0 +1,20 @@
+-- Migration: Conditional access policies (ABAC)
+-- Policies refine role-based grants with conditions over subject, resource and
+-- request attributes. A matching deny policy overrides any grant; when allow
+-- policies exist for a permission at least one of them must hold.
+CREATE TABLE policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    effect VARCHAR(10) NOT NULL CHECK (effect IN ('allow', 'deny')),
+    resource VARCHAR(255) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    condition TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(tenant_id, name)
+);
+
+CREATE INDEX idx_policies_tenant_enabled ON policies(tenant_id) WHERE enabled;
@@ -0,0 +1,37 @@
+-- Migration: Relationship tuples
+-- Zanzibar-style relationships between objects and subjects
+-- ("document:7#viewer@user:alice", "folder:42#viewer@group:eng#member").
+-- Each tenant defines its object namespaces and how their relations are
+-- computed from one another; every write bumps the tenant's revision, which
+-- consistency tokens refer to.
+CREATE TABLE relation_namespaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    config JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(tenant_id, name)
+);
+
+CREATE TABLE relation_tuples (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    namespace VARCHAR(64) NOT NULL,
+    object_id VARCHAR(255) NOT NULL,
+    relation VARCHAR(64) NOT NULL,
+    subject_namespace VARCHAR(64) NOT NULL,
+    subject_id VARCHAR(255) NOT NULL,
+    subject_relation VARCHAR(64) NOT NULL DEFAULT '', -- Empty for a direct subject
+    revision BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, namespace, object_id, relation, subject_namespace, subject_id, subject_relation)
+);
+
+-- Reverse lookups: which objects does a subject relate to
+CREATE INDEX idx_relation_tuples_subject ON relation_tuples(tenant_id, subject_namespace, subject_id, subject_relation);
+
+CREATE TABLE relation_revisions (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    revision BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
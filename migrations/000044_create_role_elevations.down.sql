@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE resource = 'roles' AND action = 'approve' AND tenant_id IS NOT NULL;
+
+DROP TABLE IF EXISTS role_elevation_requests;
+
+DROP INDEX IF EXISTS idx_user_roles_expires_at;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS elevation_request_id;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,35 @@
+-- Migration: Time-bound role assignments and just-in-time elevation
+-- A user role assignment may carry an expiry; expired assignments stop counting
+-- immediately and are swept up in the background. Users can request a role for a
+-- limited time, and an approver holding roles:approve grants or denies it.
+ALTER TABLE user_roles ADD COLUMN expires_at TIMESTAMP;
+ALTER TABLE user_roles ADD COLUMN elevation_request_id UUID;
+
+CREATE INDEX idx_user_roles_expires_at ON user_roles(expires_at) WHERE expires_at IS NOT NULL;
+
+CREATE TABLE role_elevation_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    duration_seconds INTEGER NOT NULL CHECK (duration_seconds > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'denied', 'cancelled', 'expired')),
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP,
+    decision_comment TEXT,
+    expires_at TIMESTAMP, -- Set on approval
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- At most one open request per user and role
+CREATE UNIQUE INDEX idx_role_elevation_requests_pending ON role_elevation_requests(user_id, role_id) WHERE status = 'pending';
+CREATE INDEX idx_role_elevation_requests_tenant_status ON role_elevation_requests(tenant_id, status);
+
+-- roles:approve for existing tenants; tenant_owner already holds it through *:*
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'roles.approve.' || t.id, 'Approve or deny just-in-time role elevation requests', 'roles', 'approve', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
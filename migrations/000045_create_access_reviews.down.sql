@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE resource = 'access_reviews' AND tenant_id IS NOT NULL;
+
+DROP TABLE IF EXISTS access_review_items;
+DROP TABLE IF EXISTS access_review_campaigns;
+
+ALTER TABLE roles DROP COLUMN IF EXISTS owner_id;
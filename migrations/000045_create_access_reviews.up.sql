@@ -0,0 +1,73 @@
+-- Migration: Access review (recertification) campaigns
+-- A campaign snapshots who holds what, routes each item to a reviewer, collects
+-- keep/revoke decisions and applies the revocations when it is closed.
+
+-- The user accountable for a role; reviews it in role-scoped campaigns
+ALTER TABLE roles ADD COLUMN owner_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE access_review_campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    scope_type VARCHAR(20) NOT NULL CHECK (scope_type IN ('role', 'user', 'oauth_client')),
+    scope_ids UUID[] NOT NULL DEFAULT '{}', -- Empty means everything of scope_type in the tenant
+    reviewer_type VARCHAR(20) NOT NULL CHECK (reviewer_type IN ('role_owner', 'manager', 'users')),
+    fallback_reviewer_ids UUID[] NOT NULL DEFAULT '{}', -- Review items with no owner or manager
+    default_decision VARCHAR(10) NOT NULL DEFAULT 'keep' CHECK (default_decision IN ('keep', 'revoke')),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'closed', 'cancelled')),
+    due_at TIMESTAMP,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    closed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMP,
+    last_reminded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_access_review_campaigns_tenant_status ON access_review_campaigns(tenant_id, status);
+
+CREATE TABLE access_review_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES access_review_campaigns(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    item_type VARCHAR(20) NOT NULL CHECK (item_type IN ('user_role', 'oauth_client')),
+    -- Snapshot of the access under review; ids are not foreign keys so the
+    -- record survives the user, role or client being deleted
+    user_id UUID,
+    role_id UUID,
+    client_id UUID,
+    subject VARCHAR(255) NOT NULL, -- Username or client name
+    access VARCHAR(255) NOT NULL,  -- Role name or client scopes
+    reviewer_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    decision VARCHAR(10) CHECK (decision IN ('keep', 'revoke')),
+    comment TEXT,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP,
+    applied_at TIMESTAMP,
+    apply_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_access_review_items_campaign ON access_review_items(campaign_id);
+CREATE INDEX idx_access_review_items_reviewer ON access_review_items(reviewer_id) WHERE decision IS NULL;
+
+-- access_reviews:read and access_reviews:manage for existing tenants
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'access_reviews.read.' || t.id, 'View access review campaigns and reports', 'access_reviews', 'read', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'access_reviews.manage.' || t.id, 'Run access review campaigns', 'access_reviews', 'manage', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+-- tenant_admin runs campaigns and tenant_auditor reads them; tenant_owner holds *:*
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.tenant_id = r.tenant_id AND p.resource = 'access_reviews'
+WHERE r.deleted_at IS NULL
+  AND (r.name = 'tenant_admin' OR (r.name = 'tenant_auditor' AND p.action = 'read'))
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE resource = 'sod_rules' AND tenant_id IS NOT NULL;
+
+DROP TABLE IF EXISTS sod_rules;
@@ -0,0 +1,37 @@
+-- Migration: Separation-of-duties rules
+-- A rule names a set of mutually exclusive roles and how many of them one user
+-- may hold (directly or through groups). Rules are checked when roles are
+-- assigned; holdings that predate a rule are reported as violations.
+CREATE TABLE sod_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    role_ids UUID[] NOT NULL,
+    max_roles INTEGER NOT NULL DEFAULT 1 CHECK (max_roles >= 1),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (cardinality(role_ids) > max_roles)
+);
+
+CREATE UNIQUE INDEX idx_sod_rules_tenant_name ON sod_rules(tenant_id, name);
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'sod_rules.read.' || t.id, 'View separation-of-duties rules and violations', 'sod_rules', 'read', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'sod_rules.manage.' || t.id, 'Manage separation-of-duties rules', 'sod_rules', 'manage', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+-- tenant_admin manages rules and tenant_auditor reads them; tenant_owner holds *:*
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.tenant_id = r.tenant_id AND p.resource = 'sod_rules'
+WHERE r.deleted_at IS NULL
+  AND (r.name = 'tenant_admin' OR (r.name = 'tenant_auditor' AND p.action = 'read'))
+ON CONFLICT DO NOTHING;
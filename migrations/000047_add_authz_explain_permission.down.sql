@@ -0,0 +1 @@
+DELETE FROM permissions WHERE resource = 'authz' AND action = 'explain' AND tenant_id IS NOT NULL;
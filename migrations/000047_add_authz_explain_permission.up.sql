@@ -0,0 +1,17 @@
+-- Migration: authz:explain permission
+-- Guards the explain and what-if endpoints. Both are read-only: explain shows
+-- the roles, permissions and policies behind a decision, and what-if computes
+-- access changes without applying them.
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'authz.explain.' || t.id, 'Explain authorization decisions and simulate access changes', 'authz', 'explain', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+-- tenant_admin and tenant_auditor both get it; tenant_owner holds *:*
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.tenant_id = r.tenant_id AND p.resource = 'authz' AND p.action = 'explain'
+WHERE r.deleted_at IS NULL
+  AND r.name IN ('tenant_admin', 'tenant_auditor')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE resource = 'scim_schemas' AND tenant_id IS NOT NULL;
+
+DROP TABLE IF EXISTS scim_schema_extensions;
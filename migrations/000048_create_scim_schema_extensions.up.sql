@@ -0,0 +1,36 @@
+-- Migration: Tenant-defined SCIM extension schemas
+-- An extension adds attributes to the SCIM User resource under its own schema
+-- URN. Attribute values are stored in users.metadata under the attribute name.
+CREATE TABLE scim_schema_extensions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    schema_urn VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    attributes JSONB NOT NULL DEFAULT '[]',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_scim_schema_extensions_tenant_urn ON scim_schema_extensions(tenant_id, lower(schema_urn));
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'scim_schemas.read.' || t.id, 'View SCIM extension schemas', 'scim_schemas', 'read', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'scim_schemas.manage.' || t.id, 'Manage SCIM extension schemas', 'scim_schemas', 'manage', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+-- tenant_admin manages schemas and tenant_auditor reads them; tenant_owner holds *:*
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.tenant_id = r.tenant_id AND p.resource = 'scim_schemas'
+WHERE r.deleted_at IS NULL
+  AND (r.name = 'tenant_admin' OR (r.name = 'tenant_auditor' AND p.action = 'read'))
+ON CONFLICT DO NOTHING;
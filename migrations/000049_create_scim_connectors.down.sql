@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE resource = 'scim_connectors' AND tenant_id IS NOT NULL;
+
+DROP TABLE IF EXISTS scim_remote_resources;
+DROP TABLE IF EXISTS scim_sync_operations;
+DROP TABLE IF EXISTS scim_connectors;
@@ -0,0 +1,77 @@
+-- Migration: Outbound SCIM connectors
+-- A connector pushes a tenant's users and groups to a downstream SCIM service.
+-- Changes are queued per connector and retried with backoff.
+CREATE TABLE scim_connectors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    base_url VARCHAR(2048) NOT NULL,
+    auth_type VARCHAR(20) NOT NULL CHECK (auth_type IN ('bearer', 'oauth2')),
+    token_url VARCHAR(2048),
+    client_id VARCHAR(255),
+    scopes JSONB NOT NULL DEFAULT '[]',
+    credential_encrypted TEXT NOT NULL,
+    user_mapping JSONB NOT NULL DEFAULT '{}',
+    sync_groups BOOLEAN NOT NULL DEFAULT FALSE,
+    deprovision_action VARCHAR(20) NOT NULL DEFAULT 'delete' CHECK (deprovision_action IN ('delete', 'deactivate')),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_sync_at TIMESTAMP,
+    last_reconciled_at TIMESTAMP,
+    last_error TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_scim_connectors_tenant_name ON scim_connectors(tenant_id, lower(name));
+CREATE INDEX idx_scim_connectors_enabled ON scim_connectors(enabled) WHERE enabled;
+
+CREATE TABLE scim_sync_operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    connector_id UUID NOT NULL REFERENCES scim_connectors(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    resource_type VARCHAR(20) NOT NULL CHECK (resource_type IN ('User', 'Group')),
+    resource_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('upsert', 'delete')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+-- At most one pending operation per resource; later changes replace it
+CREATE UNIQUE INDEX idx_scim_sync_operations_pending
+    ON scim_sync_operations(connector_id, resource_type, resource_id) WHERE status = 'pending';
+CREATE INDEX idx_scim_sync_operations_due ON scim_sync_operations(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_scim_sync_operations_connector ON scim_sync_operations(connector_id, created_at DESC);
+
+CREATE TABLE scim_remote_resources (
+    connector_id UUID NOT NULL REFERENCES scim_connectors(id) ON DELETE CASCADE,
+    resource_type VARCHAR(20) NOT NULL,
+    local_id UUID NOT NULL,
+    remote_id VARCHAR(255) NOT NULL,
+    synced_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (connector_id, resource_type, local_id)
+);
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'scim_connectors.read.' || t.id, 'View outbound SCIM connectors and their sync status', 'scim_connectors', 'read', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'scim_connectors.manage.' || t.id, 'Manage outbound SCIM connectors', 'scim_connectors', 'manage', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+-- tenant_admin manages connectors and tenant_auditor reads them; tenant_owner holds *:*
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.tenant_id = r.tenant_id AND p.resource = 'scim_connectors'
+WHERE r.deleted_at IS NULL
+  AND (r.name = 'tenant_admin' OR (r.name = 'tenant_auditor' AND p.action = 'read'))
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+-- Migration: Restrict SCIM tokens by client address and require an expiry
+-- allowed_ips holds IP addresses and CIDR ranges; an empty array allows any address.
+ALTER TABLE scim_tokens ADD COLUMN allowed_ips TEXT[] NOT NULL DEFAULT '{}';
+
+-- Tokens created without an expiry get one a year from now
+UPDATE scim_tokens SET expires_at = CURRENT_TIMESTAMP + INTERVAL '365 days'
+WHERE expires_at IS NULL AND deleted_at IS NULL;
+
+COMMENT ON COLUMN scim_tokens.allowed_ips IS 'IP addresses and CIDR ranges the token may be used from; empty allows any';
+COMMENT ON COLUMN scim_tokens.scopes IS 'Array of SCIM scopes (users.read, users.write, groups.read, groups.write; users, groups and * grant read and write)';
@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE resource = 'webhooks' AND tenant_id IS NOT NULL;
+
+ALTER TABLE webhooks DROP COLUMN IF EXISTS disabled_at;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS disabled_reason;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS consecutive_failures;
@@ -0,0 +1,30 @@
+-- Migration: Track webhook endpoint health and permissions for managing webhooks
+-- consecutive_failures counts failed attempts since the last successful delivery.
+-- A webhook is disabled automatically once it reaches the failure threshold;
+-- disabled_reason records why a webhook stopped receiving events.
+ALTER TABLE webhooks ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE webhooks ADD COLUMN disabled_reason VARCHAR(255);
+ALTER TABLE webhooks ADD COLUMN disabled_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN webhooks.events IS 'Event types this webhook subscribes to (e.g., user.created); user.* matches a category and * matches every event';
+COMMENT ON COLUMN webhooks.consecutive_failures IS 'Failed delivery attempts since the last successful delivery';
+COMMENT ON COLUMN webhooks.disabled_reason IS 'Why the webhook is disabled: paused by an administrator or too many failed deliveries';
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'webhooks.read.' || t.id, 'View webhooks and their deliveries', 'webhooks', 'read', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (tenant_id, name, description, resource, action, created_at, updated_at)
+SELECT t.id, 'webhooks.manage.' || t.id, 'Manage webhooks, send test events and redeliver events', 'webhooks', 'manage', NOW(), NOW()
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+-- tenant_admin manages webhooks and tenant_auditor reads them; tenant_owner holds *:*
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.tenant_id = r.tenant_id AND p.resource = 'webhooks'
+WHERE r.deleted_at IS NULL
+  AND (r.name = 'tenant_admin' OR (r.name = 'tenant_auditor' AND p.action = 'read'))
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+-- Rollback: Drop durable background jobs
+DELETE FROM system_permissions WHERE resource = 'jobs';
+
+DROP TABLE IF EXISTS job_runs;
+DROP TABLE IF EXISTS scheduled_jobs;
@@ -0,0 +1,59 @@
+-- Migration: Durable background jobs
+-- Every replica registers the same jobs; a replica runs a job only after
+-- claiming its row with a lease, so each run happens on exactly one replica.
+-- A replica that dies mid-run loses the job once locked_until passes.
+CREATE TABLE scheduled_jobs (
+    name VARCHAR(100) PRIMARY KEY,
+    schedule VARCHAR(100) NOT NULL,
+    timeout_seconds INTEGER NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_by VARCHAR(255),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_started_at TIMESTAMP WITH TIME ZONE,
+    last_finished_at TIMESTAMP WITH TIME ZONE,
+    last_status VARCHAR(20),
+    last_error TEXT,
+    last_duration_ms BIGINT,
+    last_success_at TIMESTAMP WITH TIME ZONE,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    total_runs BIGINT NOT NULL DEFAULT 0,
+    total_failures BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scheduled_jobs_due ON scheduled_jobs(next_run_at) WHERE enabled;
+
+CREATE TABLE job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL REFERENCES scheduled_jobs(name) ON DELETE CASCADE,
+    runner VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    processed INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_ms BIGINT NOT NULL
+);
+
+CREATE INDEX idx_job_runs_job_started ON job_runs(job_name, started_at DESC);
+CREATE INDEX idx_job_runs_started ON job_runs(started_at);
+
+COMMENT ON COLUMN scheduled_jobs.schedule IS 'Cron expression (minute hour day month weekday), @hourly/@daily/@weekly/@monthly or @every <duration>';
+COMMENT ON COLUMN scheduled_jobs.locked_by IS 'Replica running the job; the lease expires at locked_until';
+
+INSERT INTO system_permissions (resource, action, description) VALUES
+    ('jobs', 'read', 'View background jobs and their run history'),
+    ('jobs', 'run', 'Trigger background jobs to run immediately')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- system_owner holds every permission, system_admin runs jobs and
+-- system_auditor views them
+INSERT INTO system_role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM system_roles r
+JOIN system_permissions p ON p.resource = 'jobs'
+WHERE r.name IN ('system_owner', 'system_admin')
+   OR (r.name = 'system_auditor' AND p.action = 'read')
+ON CONFLICT DO NOTHING;
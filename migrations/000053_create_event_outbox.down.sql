@@ -0,0 +1,5 @@
+-- Rollback: Drop the audit event outbox
+DROP INDEX IF EXISTS idx_webhook_deliveries_queue;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS delivery_locked_until;
+
+DROP TABLE IF EXISTS outbox_events;
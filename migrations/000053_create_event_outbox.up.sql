@@ -0,0 +1,38 @@
+-- Migration: Transactional outbox for audit events
+-- Every audit event is written to outbox_events in the same transaction as
+-- the event itself. Changes made through the API share that transaction, so
+-- their event is published only if the change commits. A single relay,
+-- elected with an advisory lock, hands pending events to consumers (webhook
+-- fan-out, SCIM connectors) in order and retries failures, so an event is
+-- delivered at least once even if the process dies right after storing it.
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL,
+    tenant_id UUID,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processed', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events(id) WHERE status = 'pending';
+CREATE INDEX idx_outbox_events_processed_at ON outbox_events(processed_at) WHERE status <> 'pending';
+
+COMMENT ON COLUMN outbox_events.status IS 'pending until every consumer has handled the event; dead after too many failed attempts';
+
+-- Webhook deliveries double as a per-endpoint queue: a delivery is queued as
+-- pending, and an endpoint's deliveries are sent oldest first by one worker
+-- at a time, which holds the webhook's delivery lease.
+ALTER TABLE webhooks ADD COLUMN delivery_locked_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_webhook_deliveries_queue ON webhook_deliveries(webhook_id, created_at, id)
+    WHERE status IN ('pending', 'retrying');
+
+COMMENT ON COLUMN webhook_deliveries.next_retry_at IS 'When the next attempt is due while the delivery is pending or retrying';
+
+-- Delivery retries are sent by the webhook delivery workers
+DELETE FROM scheduled_jobs WHERE name = 'webhooks.retry';
@@ -0,0 +1,6 @@
+-- Rollback: Remove Standard Webhooks signing settings
+ALTER TABLE webhooks DROP COLUMN IF EXISTS previous_secret_expires_at;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS previous_secret;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS signing_algorithm;
+
+COMMENT ON COLUMN webhooks.secret IS 'Secret used to sign webhook payloads (HMAC-SHA256)';
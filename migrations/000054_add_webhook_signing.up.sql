@@ -0,0 +1,13 @@
+-- Migration: Standard Webhooks signing with secret rotation
+-- Requests are signed over "<webhook-id>.<webhook-timestamp>.<body>" with
+-- HMAC-SHA256, or with Ed25519 for webhooks created with that algorithm.
+-- After a secret is rotated, the previous secret keeps signing alongside the
+-- new one until previous_secret_expires_at, so receivers can switch over
+-- without missing deliveries.
+ALTER TABLE webhooks ADD COLUMN signing_algorithm VARCHAR(20) NOT NULL DEFAULT 'hmac-sha256'
+    CHECK (signing_algorithm IN ('hmac-sha256', 'ed25519'));
+ALTER TABLE webhooks ADD COLUMN previous_secret VARCHAR(255);
+ALTER TABLE webhooks ADD COLUMN previous_secret_expires_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN webhooks.secret IS 'HMAC-SHA256 secret, or whsk_ Ed25519 signing key, used to sign webhook requests';
+COMMENT ON COLUMN webhooks.previous_secret IS 'Secret replaced by the last rotation; also signs requests until previous_secret_expires_at';
@@ -0,0 +1,9 @@
+-- Rollback: Remove the audit hash chain
+DROP TABLE IF EXISTS audit_checkpoints;
+
+DROP INDEX IF EXISTS idx_audit_events_chain;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS hash;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS seq;
+
+DROP TABLE IF EXISTS audit_chains;
@@ -0,0 +1,45 @@
+-- Migration: Tamper-evident audit log
+-- Each tenant's audit events (and the system events, which have no tenant)
+-- form a hash chain: an event's hash covers its fields, its position in the
+-- chain and the previous event's hash, so editing, deleting or reordering a
+-- stored event breaks the chain. audit_chains holds each chain's head and is
+-- locked while an event is appended. Checkpoints sign a chain head with the
+-- token signing key, so the chain cannot be silently rebuilt from scratch
+-- by someone without that key.
+CREATE TABLE audit_chains (
+    chain_id UUID PRIMARY KEY,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL DEFAULT 0,
+    hash VARCHAR(64) NOT NULL,
+    checkpoint_seq BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN audit_chains.chain_id IS 'The tenant ID, or the nil UUID for system events';
+COMMENT ON COLUMN audit_chains.seq IS 'Sequence number of the last event in the chain';
+COMMENT ON COLUMN audit_chains.checkpoint_seq IS 'Sequence number covered by the latest signed checkpoint';
+
+ALTER TABLE audit_events ADD COLUMN seq BIGINT;
+ALTER TABLE audit_events ADD COLUMN prev_hash VARCHAR(64);
+ALTER TABLE audit_events ADD COLUMN hash VARCHAR(64);
+
+-- Events recorded before this migration are not chained and keep NULLs
+CREATE UNIQUE INDEX idx_audit_events_chain ON audit_events(tenant_id, seq) WHERE seq IS NOT NULL;
+
+COMMENT ON COLUMN audit_events.seq IS 'Position in the tenant''s audit chain, starting at 1';
+COMMENT ON COLUMN audit_events.hash IS 'SHA-256 over prev_hash, seq and the event fields (hex)';
+
+CREATE TABLE audit_checkpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chain_id UUID NOT NULL REFERENCES audit_chains(chain_id) ON DELETE CASCADE,
+    tenant_id UUID,
+    seq BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    key_id VARCHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (chain_id, seq)
+);
+
+COMMENT ON COLUMN audit_checkpoints.signature IS 'RS256 signature over the chain ID, seq, hash and created_at (base64)';
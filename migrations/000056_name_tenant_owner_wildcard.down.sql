@@ -0,0 +1,2 @@
+-- Rollback: Nothing to undo
+-- The names set by the up migration are the ones new tenants get as well
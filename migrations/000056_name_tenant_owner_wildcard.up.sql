@@ -0,0 +1,9 @@
+-- Migration: Name every tenant_owner wildcard permission the same way
+-- Tenants created after 000039 got their *:* permission from the tenant
+-- initializer, which left its name empty. Give those the name 000039 uses,
+-- 'tenant.all.<tenant_id>', so the grant has one identity for every tenant.
+
+UPDATE permissions
+SET name = 'tenant.all.' || tenant_id, updated_at = NOW()
+WHERE resource = '*' AND action = '*' AND tenant_id IS NOT NULL
+  AND name <> 'tenant.all.' || tenant_id;
@@ -0,0 +1,256 @@
+// Package webhooks signs and verifies webhook requests following the
+// Standard Webhooks scheme (https://www.standardwebhooks.com). Receivers can
+// import it to check that a request was sent by the identity server and is
+// not a replay:
+//
+//	verifier, err := webhooks.NewVerifier(os.Getenv("WEBHOOK_SECRET"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	http.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
+//		body, _ := io.ReadAll(r.Body)
+//		if err := verifier.Verify(r.Header, body); err != nil {
+//			http.Error(w, "invalid signature", http.StatusUnauthorized)
+//			return
+//		}
+//		// Retries carry the same webhook-id; skip IDs already handled
+//	})
+//
+// The secret is the webhook's HMAC secret, or the whpk_ public key shown for
+// webhooks signed with Ed25519. While a secret is being rotated, requests
+// carry a signature for the old and the new secret, so receivers can switch
+// at any time during the overlap.
+package webhooks
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers carrying the message ID, send time and signatures
+const (
+	HeaderID        = "webhook-id"
+	HeaderTimestamp = "webhook-timestamp"
+	HeaderSignature = "webhook-signature"
+)
+
+// Key prefixes. A secret without a prefix is used as raw bytes.
+const (
+	SecretPrefix     = "whsec_" // Base64-encoded HMAC-SHA256 key
+	SigningKeyPrefix = "whsk_"  // Base64-encoded Ed25519 private key
+	PublicKeyPrefix  = "whpk_"  // Base64-encoded Ed25519 public key
+)
+
+// DefaultTolerance is how far a request's timestamp may be from the
+// receiver's clock
+const DefaultTolerance = 5 * time.Minute
+
+// Signature versions
+const (
+	versionHMAC    = "v1"
+	versionEd25519 = "v1a"
+)
+
+// Verification errors
+var (
+	ErrMissingHeaders      = errors.New("webhooks: missing webhook-id, webhook-timestamp or webhook-signature header")
+	ErrInvalidTimestamp    = errors.New("webhooks: invalid webhook-timestamp header")
+	ErrTimestampOutOfRange = errors.New("webhooks: webhook-timestamp is too old or too new")
+	ErrNoMatchingSignature = errors.New("webhooks: no matching signature")
+)
+
+// Signer signs requests with one secret
+type Signer struct {
+	hmacKey    []byte
+	privateKey ed25519.PrivateKey
+}
+
+// NewSigner creates a signer for an HMAC secret or a whsk_ signing key
+func NewSigner(secret string) (*Signer, error) {
+	if encoded, ok := strings.CutPrefix(secret, SigningKeyPrefix); ok {
+		privateKey, err := decodePrivateKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return &Signer{privateKey: privateKey}, nil
+	}
+	if strings.HasPrefix(secret, PublicKeyPrefix) {
+		return nil, fmt.Errorf("webhooks: a public key cannot sign")
+	}
+
+	key, err := hmacKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{hmacKey: key}, nil
+}
+
+// Sign returns the versioned signature of a request, such as "v1,<base64>"
+func (s *Signer) Sign(id string, timestamp time.Time, body []byte) string {
+	content := signedContent(id, timestamp.Unix(), body)
+	if s.privateKey != nil {
+		return versionEd25519 + "," + base64.StdEncoding.EncodeToString(ed25519.Sign(s.privateKey, content))
+	}
+	return versionHMAC + "," + base64.StdEncoding.EncodeToString(computeHMAC(s.hmacKey, content))
+}
+
+// PublicKey returns the whpk_ key receivers verify Ed25519 signatures with,
+// or "" for HMAC secrets
+func (s *Signer) PublicKey() string {
+	if s.privateKey == nil {
+		return ""
+	}
+	publicKey := s.privateKey.Public().(ed25519.PublicKey)
+	return PublicKeyPrefix + base64.StdEncoding.EncodeToString(publicKey)
+}
+
+// GenerateSigningKey returns a new whsk_ Ed25519 signing key
+func GenerateSigningKey() (string, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("webhooks: failed to generate signing key: %w", err)
+	}
+	return SigningKeyPrefix + base64.StdEncoding.EncodeToString(privateKey.Seed()), nil
+}
+
+// Verifier checks request signatures against one secret
+type Verifier struct {
+	hmacKey   []byte
+	publicKey ed25519.PublicKey
+	tolerance time.Duration
+}
+
+// NewVerifier creates a verifier for an HMAC secret or a whpk_ public key
+func NewVerifier(secret string) (*Verifier, error) {
+	if encoded, ok := strings.CutPrefix(secret, PublicKeyPrefix); ok {
+		publicKey, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(publicKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("webhooks: invalid public key")
+		}
+		return &Verifier{publicKey: publicKey, tolerance: DefaultTolerance}, nil
+	}
+	if strings.HasPrefix(secret, SigningKeyPrefix) {
+		return nil, fmt.Errorf("webhooks: verify with the public key, not the signing key")
+	}
+
+	key, err := hmacKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{hmacKey: key, tolerance: DefaultTolerance}, nil
+}
+
+// WithTolerance returns a copy of the verifier accepting timestamps up to
+// tolerance away from the current time
+func (v *Verifier) WithTolerance(tolerance time.Duration) *Verifier {
+	copied := *v
+	copied.tolerance = tolerance
+	return &copied
+}
+
+// Verify checks that a request is fresh and signed with the verifier's
+// secret. body must be the raw request body.
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	return v.VerifyAt(header, body, time.Now())
+}
+
+// VerifyAt is Verify with the given current time
+func (v *Verifier) VerifyAt(header http.Header, body []byte, now time.Time) error {
+	id := header.Get(HeaderID)
+	timestamp := header.Get(HeaderTimestamp)
+	signatures := header.Get(HeaderSignature)
+	if id == "" || timestamp == "" || signatures == "" {
+		return ErrMissingHeaders
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	sentAt := time.Unix(unix, 0)
+	if sentAt.Before(now.Add(-v.tolerance)) || sentAt.After(now.Add(v.tolerance)) {
+		return ErrTimestampOutOfRange
+	}
+
+	content := signedContent(id, unix, body)
+	for _, signature := range strings.Fields(signatures) {
+		version, encoded, ok := strings.Cut(signature, ",")
+		if !ok {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		switch {
+		case version == versionHMAC && v.hmacKey != nil:
+			if subtle.ConstantTimeCompare(decoded, computeHMAC(v.hmacKey, content)) == 1 {
+				return nil
+			}
+		case version == versionEd25519 && v.publicKey != nil:
+			if ed25519.Verify(v.publicKey, content, decoded) {
+				return nil
+			}
+		}
+	}
+	return ErrNoMatchingSignature
+}
+
+// signedContent is what gets signed: "<id>.<timestamp>.<body>"
+func signedContent(id string, timestamp int64, body []byte) []byte {
+	content := make([]byte, 0, len(id)+len(body)+22)
+	content = append(content, id...)
+	content = append(content, '.')
+	content = strconv.AppendInt(content, timestamp, 10)
+	content = append(content, '.')
+	return append(content, body...)
+}
+
+func computeHMAC(key, content []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	return mac.Sum(nil)
+}
+
+// hmacKey decodes a whsec_ secret, or uses any other secret as raw bytes
+func hmacKey(secret string) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(secret, SecretPrefix)
+	if !ok {
+		if secret == "" {
+			return nil, fmt.Errorf("webhooks: secret is empty")
+		}
+		return []byte(secret), nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("webhooks: invalid whsec_ secret")
+	}
+	return key, nil
+}
+
+// decodePrivateKey accepts an Ed25519 seed or a full private key
+func decodePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: invalid signing key")
+	}
+	switch len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	default:
+		return nil, fmt.Errorf("webhooks: invalid signing key")
+	}
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedHeader(id string, timestamp time.Time, signatures ...string) http.Header {
+	header := http.Header{}
+	header.Set(HeaderID, id)
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	header.Set(HeaderSignature, strings.Join(signatures, " "))
+	return header
+}
+
+func TestSigner_MatchesStandardWebhooksExample(t *testing.T) {
+	signer, err := NewSigner("whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw")
+	require.NoError(t, err)
+
+	signature := signer.Sign("msg_p5jXN8AQM9LWM0D4loKWxJek", time.Unix(1614265330, 0), []byte(`{"test": 2432232314}`))
+	assert.Equal(t, "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=", signature)
+	assert.Empty(t, signer.PublicKey())
+}
+
+func TestVerifier_HMAC(t *testing.T) {
+	secret := "12345678901234567890123456789012"
+	signer, err := NewSigner(secret)
+	require.NoError(t, err)
+	verifier, err := NewVerifier(secret)
+	require.NoError(t, err)
+
+	now := time.Now()
+	body := []byte(`{"event_type":"user.created"}`)
+	header := signedHeader("msg_1", now, signer.Sign("msg_1", now, body))
+	assert.NoError(t, verifier.VerifyAt(header, body, now))
+
+	assert.ErrorIs(t, verifier.VerifyAt(header, []byte(`{"event_type":"user.deleted"}`), now), ErrNoMatchingSignature)
+	assert.ErrorIs(t, verifier.VerifyAt(header, body, now.Add(DefaultTolerance+time.Minute)), ErrTimestampOutOfRange,
+		"replays of old requests are rejected")
+	assert.NoError(t, verifier.WithTolerance(time.Hour).VerifyAt(header, body, now.Add(DefaultTolerance+time.Minute)))
+
+	// The ID and timestamp are signed too
+	assert.ErrorIs(t, verifier.VerifyAt(signedHeader("msg_2", now, signer.Sign("msg_1", now, body)), body, now), ErrNoMatchingSignature)
+	assert.ErrorIs(t, verifier.VerifyAt(signedHeader("msg_1", now.Add(time.Second), signer.Sign("msg_1", now, body)), body, now), ErrNoMatchingSignature)
+
+	assert.ErrorIs(t, verifier.VerifyAt(http.Header{}, body, now), ErrMissingHeaders)
+	header.Set(HeaderTimestamp, "yesterday")
+	assert.ErrorIs(t, verifier.VerifyAt(header, body, now), ErrInvalidTimestamp)
+}
+
+func TestVerifier_AcceptsAnyListedSignature(t *testing.T) {
+	oldSigner, err := NewSigner("old-secret-old-secret-old-secret")
+	require.NoError(t, err)
+	newSigner, err := NewSigner("new-secret-new-secret-new-secret")
+	require.NoError(t, err)
+
+	now := time.Now()
+	body := []byte(`{}`)
+	header := signedHeader("msg_1", now, newSigner.Sign("msg_1", now, body), oldSigner.Sign("msg_1", now, body))
+
+	for _, secret := range []string{"old-secret-old-secret-old-secret", "new-secret-new-secret-new-secret"} {
+		verifier, err := NewVerifier(secret)
+		require.NoError(t, err)
+		assert.NoError(t, verifier.VerifyAt(header, body, now), "receivers can switch secrets during a rotation")
+	}
+
+	other, err := NewVerifier("other-secret-other-secret-other!")
+	require.NoError(t, err)
+	assert.ErrorIs(t, other.VerifyAt(header, body, now), ErrNoMatchingSignature)
+}
+
+func TestVerifier_Ed25519(t *testing.T) {
+	signingKey, err := GenerateSigningKey()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(signingKey, SigningKeyPrefix))
+
+	signer, err := NewSigner(signingKey)
+	require.NoError(t, err)
+	publicKey := signer.PublicKey()
+	require.True(t, strings.HasPrefix(publicKey, PublicKeyPrefix))
+
+	verifier, err := NewVerifier(publicKey)
+	require.NoError(t, err)
+
+	now := time.Now()
+	body := []byte(`{"event_type":"user.created"}`)
+	signature := signer.Sign("msg_1", now, body)
+	assert.True(t, strings.HasPrefix(signature, "v1a,"))
+	assert.NoError(t, verifier.VerifyAt(signedHeader("msg_1", now, signature), body, now))
+	assert.ErrorIs(t, verifier.VerifyAt(signedHeader("msg_1", now, signature), []byte(`{}`), now), ErrNoMatchingSignature)
+
+	// An HMAC verifier never accepts an Ed25519 signature, and vice versa
+	hmacVerifier, err := NewVerifier(signingKey[len(SigningKeyPrefix):])
+	require.NoError(t, err)
+	assert.ErrorIs(t, hmacVerifier.VerifyAt(signedHeader("msg_1", now, signature), body, now), ErrNoMatchingSignature)
+
+	_, err = NewVerifier(signingKey)
+	assert.Error(t, err, "the signing key stays with the sender")
+	_, err = NewSigner(publicKey)
+	assert.Error(t, err)
+}
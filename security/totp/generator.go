@@ -3,6 +3,7 @@ package totp
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/binary"
 	"fmt"
 	"image/png"
@@ -11,26 +12,162 @@ import (
 
 	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
 )
 
+const (
+	// DefaultPeriod is the default TOTP time step in seconds
+	DefaultPeriod = 30
+	// DefaultDigits is the default TOTP code length
+	DefaultDigits = 6
+	// DefaultSkew is the default number of time steps accepted either side of now
+	DefaultSkew = 1
+	// DefaultAlgorithm is the default TOTP HMAC algorithm
+	DefaultAlgorithm = "SHA1"
+)
+
+// Settings are the parameters a TOTP factor was enrolled with.
+// They are stored per factor so that changing the configured defaults does not
+// break existing enrollments.
+type Settings struct {
+	Algorithm string
+	Digits    int
+	Period    int
+}
+
+// Options configures a Generator. Unset algorithm, digits and period fall back
+// to the defaults; a skew of 0 accepts only the current time step.
+type Options struct {
+	Algorithm string
+	Digits    int
+	Period    int
+	Skew      int
+	Logger    *zap.Logger
+}
+
 // Generator provides TOTP generation functionality
 type Generator struct {
-	issuer string
+	issuer   string
+	settings Settings
+	skew     int
+	logger   *zap.Logger
 }
 
-// NewGenerator creates a new TOTP generator
+// NewGenerator creates a new TOTP generator with default settings
 func NewGenerator(issuer string) *Generator {
-	return &Generator{issuer: issuer}
+	return NewGeneratorWithOptions(issuer, Options{Skew: DefaultSkew})
+}
+
+// NewGeneratorWithOptions creates a new TOTP generator using the given options
+func NewGeneratorWithOptions(issuer string, opts Options) *Generator {
+	g := &Generator{
+		issuer: issuer,
+		settings: Settings{
+			Algorithm: opts.Algorithm,
+			Digits:    opts.Digits,
+			Period:    opts.Period,
+		},
+		skew:   opts.Skew,
+		logger: opts.Logger,
+	}
+	g.settings = g.resolve(Settings{})
+	if g.skew < 0 {
+		g.skew = 0
+	}
+	if g.logger == nil {
+		g.logger = zap.NewNop()
+	}
+	return g
+}
+
+// Settings returns the settings new factors are enrolled with
+func (g *Generator) Settings() Settings {
+	return g.settings
+}
+
+// Skew returns the number of time steps accepted either side of now
+func (g *Generator) Skew() int {
+	return g.skew
+}
+
+// resolve fills unset fields of s from the generator's settings, falling back to the defaults
+func (g *Generator) resolve(s Settings) Settings {
+	if s.Algorithm == "" {
+		s.Algorithm = g.settings.Algorithm
+	}
+	if s.Algorithm == "" {
+		s.Algorithm = DefaultAlgorithm
+	}
+	s.Algorithm = strings.ToUpper(s.Algorithm)
+	if s.Digits <= 0 {
+		s.Digits = g.settings.Digits
+	}
+	if s.Digits <= 0 {
+		s.Digits = DefaultDigits
+	}
+	if s.Period <= 0 {
+		s.Period = g.settings.Period
+	}
+	if s.Period <= 0 {
+		s.Period = DefaultPeriod
+	}
+	return s
+}
+
+// ValidateSettings checks that s describes parameters authenticator apps support
+func ValidateSettings(s Settings) error {
+	if _, err := parseAlgorithm(s.Algorithm); err != nil {
+		return err
+	}
+	if s.Digits != 6 && s.Digits != 8 {
+		return fmt.Errorf("unsupported TOTP digits: %d (must be 6 or 8)", s.Digits)
+	}
+	if s.Period < 15 || s.Period > 120 {
+		return fmt.Errorf("unsupported TOTP period: %d (must be between 15 and 120 seconds)", s.Period)
+	}
+	return nil
+}
+
+// parseAlgorithm maps an algorithm name to its otp constant
+func parseAlgorithm(name string) (otp.Algorithm, error) {
+	switch strings.ToUpper(name) {
+	case "SHA1":
+		return otp.AlgorithmSHA1, nil
+	case "SHA256":
+		return otp.AlgorithmSHA256, nil
+	case "SHA512":
+		return otp.AlgorithmSHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported TOTP algorithm: %s", name)
+	}
+}
+
+// validateOpts converts settings to the options used by the otp library
+func validateOpts(s Settings) (totp.ValidateOpts, error) {
+	algorithm, err := parseAlgorithm(s.Algorithm)
+	if err != nil {
+		return totp.ValidateOpts{}, err
+	}
+	return totp.ValidateOpts{
+		Period:    uint(s.Period),
+		Digits:    otp.Digits(s.Digits),
+		Algorithm: algorithm,
+	}, nil
 }
 
 // GenerateSecret generates a new TOTP secret
 func (g *Generator) GenerateSecret(accountName string) (string, error) {
+	algorithm, err := parseAlgorithm(g.settings.Algorithm)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
 	key, err := totp.Generate(totp.GenerateOpts{
 		Issuer:      g.issuer,
 		AccountName: accountName,
-		Period:      30,
-		Digits:      otp.DigitsSix,
-		Algorithm:   otp.AlgorithmSHA1,
+		Period:      uint(g.settings.Period),
+		Digits:      otp.Digits(g.settings.Digits),
+		Algorithm:   algorithm,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to generate TOTP secret: %w", err)
@@ -44,8 +181,8 @@ func (g *Generator) GenerateSecret(accountName string) (string, error) {
 func (g *Generator) GenerateQRCode(accountName string, secret string) ([]byte, error) {
 	// Create a TOTP key from the existing secret using the otpauth URL format
 	// This ensures the QR code contains all the necessary information
-	key, err := otp.NewKeyFromURL(fmt.Sprintf("otpauth://totp/%s:%s?secret=%s&issuer=%s&period=30&digits=6&algorithm=SHA1",
-		g.issuer, accountName, secret, g.issuer))
+	key, err := otp.NewKeyFromURL(fmt.Sprintf("otpauth://totp/%s:%s?secret=%s&issuer=%s&period=%d&digits=%d&algorithm=%s",
+		g.issuer, accountName, secret, g.issuer, g.settings.Period, g.settings.Digits, g.settings.Algorithm))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TOTP key from secret: %w", err)
 	}
@@ -64,29 +201,53 @@ func (g *Generator) GenerateQRCode(accountName string, secret string) ([]byte, e
 	return buf.Bytes(), nil
 }
 
-// Validate validates a TOTP code
-// Uses ValidateCustom with a time window of ±1 period (30 seconds) to account for clock skew
+// Validate validates a TOTP code against the generator's settings
 // The secret should be a base32-encoded string
 func (g *Generator) Validate(secret string, code string) bool {
-	// Trim whitespace from code
+	_, ok := g.ValidateStep(secret, code, Settings{})
+	return ok
+}
+
+// ValidateStep validates a TOTP code enrolled with the given settings and returns
+// the time step it matched, so callers can reject a code that was already used.
+// Unset settings fall back to the generator's. Codes from up to skew steps either
+// side of now are accepted to tolerate clock drift.
+func (g *Generator) ValidateStep(secret string, code string, settings Settings) (int64, bool) {
+	return g.validateAt(secret, code, settings, time.Now())
+}
+
+// validateAt is ValidateStep at a fixed time
+func (g *Generator) validateAt(secret string, code string, settings Settings, now time.Time) (int64, bool) {
 	code = strings.TrimSpace(code)
-	
-	// Use ValidateCustom to explicitly set time window tolerance
-	// This allows codes from the previous and next time windows to be valid
-	// The default Validate uses ±1 period, but we make it explicit here
-	// Increase skew to 2 to be more tolerant of clock differences
-	valid, err := totp.ValidateCustom(code, secret, time.Now(), totp.ValidateOpts{
-		Period:    30,
-		Skew:      2, // Allow ±2 periods (60 seconds) clock skew for better tolerance
-		Digits:    otp.DigitsSix,
-		Algorithm: otp.AlgorithmSHA1,
-	})
+	settings = g.resolve(settings)
+
+	opts, err := validateOpts(settings)
 	if err != nil {
-		// Log error for debugging (in production, you might want to use a logger)
-		fmt.Printf("TOTP validation error: %v (secret length: %d, code: %s)\n", err, len(secret), code)
-		return false
+		g.logger.Warn("TOTP validation failed", zap.Error(err))
+		return 0, false
 	}
-	return valid
+	if len(code) != settings.Digits {
+		return 0, false
+	}
+
+	period := int64(settings.Period)
+	current := now.Unix() / period
+	for step := current - int64(g.skew); step <= current+int64(g.skew); step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*period, 0), opts)
+		if err != nil {
+			// Never log the submitted code or the secret
+			g.logger.Warn("TOTP validation failed",
+				zap.Error(err),
+				zap.Int("secret_length", len(secret)),
+			)
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
 }
 
 // GenerateRecoveryCodes generates recovery codes for MFA
@@ -104,7 +265,7 @@ func (g *Generator) GenerateRecoveryCodes(count int) ([]string, error) {
 func generateRandomCode(length int) string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, length)
-	
+
 	// Generate random bytes
 	randomBytes := make([]byte, 4)
 	for i := range b {
@@ -118,7 +279,6 @@ func generateRandomCode(length int) string {
 		idx := binary.BigEndian.Uint32(randomBytes) % uint32(len(charset))
 		b[i] = charset[idx]
 	}
-	
+
 	return string(b)
 }
-
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,3 +56,64 @@ func TestGenerator_ValidateCode_InvalidSecret(t *testing.T) {
 	assert.False(t, valid)
 }
 
+
+func TestGenerator_ValidateStep_HonorsSettings(t *testing.T) {
+	generator := NewGeneratorWithOptions("TestApp", Options{
+		Algorithm: "SHA256",
+		Digits:    8,
+		Period:    60,
+		Skew:      1,
+	})
+
+	secret, err := generator.GenerateSecret("test@example.com")
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	code, err := totp.GenerateCodeCustom(secret, now, totp.ValidateOpts{
+		Period:    60,
+		Digits:    otp.DigitsEight,
+		Algorithm: otp.AlgorithmSHA256,
+	})
+	require.NoError(t, err)
+
+	step, ok := generator.validateAt(secret, code, Settings{}, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/60, step)
+
+	// A factor enrolled with the old defaults keeps validating with its own settings
+	legacyCode, err := totp.GenerateCode(secret, now)
+	require.NoError(t, err)
+	_, ok = generator.validateAt(secret, legacyCode, Settings{}, now)
+	assert.False(t, ok)
+	_, ok = generator.validateAt(secret, legacyCode, Settings{Algorithm: "SHA1", Digits: 6, Period: 30}, now)
+	assert.True(t, ok)
+}
+
+func TestGenerator_ValidateStep_Skew(t *testing.T) {
+	secret, err := NewGenerator("TestApp").GenerateSecret("test@example.com")
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	previous, err := totp.GenerateCode(secret, now.Add(-30*time.Second))
+	require.NoError(t, err)
+	older, err := totp.GenerateCode(secret, now.Add(-60*time.Second))
+	require.NoError(t, err)
+
+	strict := NewGeneratorWithOptions("TestApp", Options{Skew: 0})
+	_, ok := strict.validateAt(secret, previous, Settings{}, now)
+	assert.False(t, ok)
+
+	lenient := NewGeneratorWithOptions("TestApp", Options{Skew: 1})
+	step, ok := lenient.validateAt(secret, previous, Settings{}, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30-1, step)
+	_, ok = lenient.validateAt(secret, older, Settings{}, now)
+	assert.False(t, ok)
+}
+
+func TestValidateSettings(t *testing.T) {
+	assert.NoError(t, ValidateSettings(Settings{Algorithm: "SHA512", Digits: 8, Period: 30}))
+	assert.Error(t, ValidateSettings(Settings{Algorithm: "MD5", Digits: 6, Period: 30}))
+	assert.Error(t, ValidateSettings(Settings{Algorithm: "SHA1", Digits: 7, Period: 30}))
+	assert.Error(t, ValidateSettings(Settings{Algorithm: "SHA1", Digits: 6, Period: 5}))
+}
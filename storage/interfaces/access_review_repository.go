@@ -0,0 +1,58 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// AccessReviewRepository defines the interface for access review data access
+type AccessReviewRepository interface {
+	// CreateCampaign creates a campaign together with its snapshot of items
+	CreateCampaign(ctx context.Context, campaign *models.AccessReviewCampaign, items []*models.AccessReviewItem) error
+
+	// GetCampaign retrieves a campaign by ID
+	GetCampaign(ctx context.Context, id uuid.UUID) (*models.AccessReviewCampaign, error)
+
+	// ListCampaigns retrieves a tenant's campaigns, newest first, optionally by status
+	ListCampaigns(ctx context.Context, tenantID uuid.UUID, status *string) ([]*models.AccessReviewCampaign, error)
+
+	// ListActiveCampaigns retrieves active campaigns across all tenants
+	ListActiveCampaigns(ctx context.Context) ([]*models.AccessReviewCampaign, error)
+
+	// UpdateCampaign stores a campaign's status, closing and reminder fields
+	UpdateCampaign(ctx context.Context, campaign *models.AccessReviewCampaign) error
+
+	// GetItem retrieves a review item by ID
+	GetItem(ctx context.Context, id uuid.UUID) (*models.AccessReviewItem, error)
+
+	// ListItems retrieves a campaign's items
+	ListItems(ctx context.Context, campaignID uuid.UUID, filters *AccessReviewItemFilters) ([]*models.AccessReviewItem, error)
+
+	// ListReviewerItems retrieves the undecided items assigned to a reviewer in active campaigns
+	ListReviewerItems(ctx context.Context, tenantID, reviewerID uuid.UUID) ([]*models.AccessReviewItem, error)
+
+	// UpdateItem stores an item's reviewer, decision and application fields
+	UpdateItem(ctx context.Context, item *models.AccessReviewItem) error
+
+	// ListRoleAssignments retrieves current user role assignments in a tenant for a
+	// snapshot, limited to the given roles and users when those are non-empty
+	ListRoleAssignments(ctx context.Context, tenantID uuid.UUID, roleIDs, userIDs []uuid.UUID) ([]*AccessReviewAssignment, error)
+}
+
+// AccessReviewItemFilters represents filters for access review item queries
+type AccessReviewItemFilters struct {
+	ReviewerID *uuid.UUID
+	Decision   *string // "keep", "revoke" or "none" for undecided items
+}
+
+// AccessReviewAssignment is a user role assignment as seen by an access review snapshot
+type AccessReviewAssignment struct {
+	UserID      uuid.UUID
+	Username    string
+	ManagerID   *uuid.UUID // From the user's metadata.manager_id
+	RoleID      uuid.UUID
+	RoleName    string
+	RoleOwnerID *uuid.UUID
+}
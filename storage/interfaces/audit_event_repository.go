@@ -18,6 +18,27 @@ type AuditEventRepository interface {
 
 	// GetEvent retrieves an audit event by ID
 	GetEvent(ctx context.Context, eventID uuid.UUID) (*models.AuditEvent, error)
+
+	// ListChain returns up to limit events of a tenant's chain (nil for the
+	// system chain) with a sequence number above afterSeq, in sequence order
+	ListChain(ctx context.Context, tenantID *uuid.UUID, afterSeq int64, limit int) ([]*models.AuditEvent, error)
+
+	// CountUnchained counts a tenant's events stored without a sequence
+	// number since the given time
+	CountUnchained(ctx context.Context, tenantID *uuid.UUID, since time.Time) (int64, error)
+
+	// GetChain returns the head of a tenant's chain
+	GetChain(ctx context.Context, tenantID *uuid.UUID) (*models.AuditChain, error)
+
+	// ListChains returns the heads of all chains
+	ListChains(ctx context.Context) ([]*models.AuditChain, error)
+
+	// CreateCheckpoint stores a signed checkpoint and records it on the chain
+	CreateCheckpoint(ctx context.Context, checkpoint *models.AuditCheckpoint) error
+
+	// ListCheckpoints returns up to limit checkpoints of a tenant's chain
+	// with a sequence number of at least fromSeq, in sequence order
+	ListCheckpoints(ctx context.Context, tenantID *uuid.UUID, fromSeq int64, limit int) ([]*models.AuditCheckpoint, error)
 }
 
 // AuditEventFilters represents filters for audit event queries
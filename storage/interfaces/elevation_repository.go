@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// ElevationRequestRepository defines the interface for role elevation request data access
+type ElevationRequestRepository interface {
+	// Create creates a new elevation request
+	Create(ctx context.Context, req *models.ElevationRequest) error
+
+	// GetByID retrieves an elevation request by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ElevationRequest, error)
+
+	// Transition stores a request's new status and decision fields, provided it is
+	// still in the from status. Returns an error if another change got there first.
+	Transition(ctx context.Context, req *models.ElevationRequest, from string) error
+
+	// List retrieves a tenant's elevation requests, newest first
+	List(ctx context.Context, tenantID uuid.UUID, filters *ElevationRequestFilters) ([]*models.ElevationRequest, error)
+}
+
+// ElevationRequestFilters represents filters for elevation request queries
+type ElevationRequestFilters struct {
+	Status *string
+	UserID *uuid.UUID
+	RoleID *uuid.UUID
+}
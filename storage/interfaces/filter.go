@@ -0,0 +1,58 @@
+package interfaces
+
+// FilterOp is an operator in a FilterExpr
+type FilterOp string
+
+// Filter operators. And, Or and Not combine Children; True and False are
+// constants; the rest compare Field with Value (Present takes no value).
+const (
+	FilterAnd            FilterOp = "and"
+	FilterOr             FilterOp = "or"
+	FilterNot            FilterOp = "not"
+	FilterTrue           FilterOp = "true"
+	FilterFalse          FilterOp = "false"
+	FilterEqual          FilterOp = "eq"
+	FilterNotEqual       FilterOp = "ne"
+	FilterContains       FilterOp = "co"
+	FilterStartsWith     FilterOp = "sw"
+	FilterEndsWith       FilterOp = "ew"
+	FilterGreater        FilterOp = "gt"
+	FilterGreaterOrEqual FilterOp = "ge"
+	FilterLess           FilterOp = "lt"
+	FilterLessOrEqual    FilterOp = "le"
+	FilterPresent        FilterOp = "pr"
+)
+
+// FilterExpr is a boolean filter over a resource's fields, built by the
+// identity layer (e.g. from a SCIM filter) and translated by each repository
+// into its own query language. Field is one of the logical field names the
+// repository documents, never a raw column.
+type FilterExpr struct {
+	Op       FilterOp
+	Field    string
+	Value    interface{}   // string, bool, float64 or nil
+	Children []*FilterExpr // Operands of and/or, or the single operand of not
+}
+
+// Logical filter and sort fields for users
+const (
+	UserFieldID          = "id"
+	UserFieldUsername    = "username"
+	UserFieldEmail       = "email"
+	UserFieldFirstName   = "first_name"
+	UserFieldLastName    = "last_name"
+	UserFieldDisplayName = "display_name" // first_name and last_name joined by a space
+	UserFieldStatus      = "status"
+	UserFieldCreatedAt   = "created_at"
+	UserFieldUpdatedAt   = "updated_at"
+)
+
+// Logical filter and sort fields for groups
+const (
+	GroupFieldID         = "id"
+	GroupFieldName       = "name"
+	GroupFieldExternalID = "external_id"
+	GroupFieldMember     = "member" // ID of a direct user or group member; eq and ne only
+	GroupFieldCreatedAt  = "created_at"
+	GroupFieldUpdatedAt  = "updated_at"
+)
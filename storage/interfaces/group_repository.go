@@ -0,0 +1,83 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// GroupRepository defines the interface for group data access
+type GroupRepository interface {
+	// Create creates a new group
+	Create(ctx context.Context, group *models.Group) error
+
+	// GetByID retrieves a group by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error)
+
+	// LockByID locks a group until the context's transaction ends, so that
+	// what is read about it meanwhile still holds when it is written
+	LockByID(ctx context.Context, id uuid.UUID) error
+
+	// GetByName retrieves a group by name and tenant ID
+	GetByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Group, error)
+
+	// GetByExternalID retrieves a group by the identifier assigned by an external IdP
+	GetByExternalID(ctx context.Context, tenantID uuid.UUID, externalID string) (*models.Group, error)
+
+	// Update updates an existing group
+	Update(ctx context.Context, group *models.Group) error
+
+	// Delete soft deletes a group and removes its memberships and role grants
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// List retrieves a list of groups with filters
+	List(ctx context.Context, tenantID uuid.UUID, filters *GroupFilters) ([]*models.Group, error)
+
+	// Count returns the number of groups matching the filters
+	Count(ctx context.Context, tenantID uuid.UUID, filters *GroupFilters) (int, error)
+
+	// AddMember adds a user to a group
+	AddMember(ctx context.Context, groupID, userID uuid.UUID) error
+
+	// RemoveMember removes a user from a group
+	RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error
+
+	// ListMembers retrieves the users directly in a group
+	ListMembers(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMember, error)
+
+	// GetUserGroups retrieves the groups a user is directly a member of
+	GetUserGroups(ctx context.Context, userID uuid.UUID) ([]*models.Group, error)
+
+	// AddMemberGroup nests a group inside another group
+	AddMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error
+
+	// RemoveMemberGroup removes a nested group from a group
+	RemoveMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error
+
+	// ListMemberGroups retrieves the groups directly nested in a group
+	ListMemberGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error)
+
+	// GetParentGroups retrieves the groups a group is directly nested in
+	GetParentGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error)
+
+	// AssignRole grants a role to a group
+	AssignRole(ctx context.Context, groupID, roleID uuid.UUID) error
+
+	// RemoveRole revokes a role from a group
+	RemoveRole(ctx context.Context, groupID, roleID uuid.UUID) error
+
+	// GetGroupRoles retrieves the roles granted directly to a group
+	GetGroupRoles(ctx context.Context, groupID uuid.UUID) ([]*models.Role, error)
+}
+
+// GroupFilters represents filters for group queries
+type GroupFilters struct {
+	Search         *string
+	Filter         *FilterExpr // Over the GroupField* fields
+	SortBy         string      // A GroupField* field; defaults to name
+	SortDescending bool
+	Page           int
+	PageSize       int
+	Offset         *int // Overrides Page for callers that page by position
+}
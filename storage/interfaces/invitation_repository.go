@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/arauth-identity/iam/identity/models"
 	"github.com/google/uuid"
@@ -32,6 +33,9 @@ type InvitationRepository interface {
 
 	// Delete soft-deletes an invitation
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	// DeleteExpired soft-deletes unaccepted invitations that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
 }
 
 // InvitationFilters defines filters for listing invitations
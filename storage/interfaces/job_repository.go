@@ -0,0 +1,42 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// JobRepository defines the interface for scheduled job state shared by all
+// replicas
+type JobRepository interface {
+	// Register creates a job, or updates the schedule and timeout of an existing
+	// one. A job whose schedule changed is rescheduled to job.NextRunAt; otherwise
+	// its next run, enabled flag and history are kept.
+	Register(ctx context.Context, job *models.ScheduledJob) error
+
+	// ClaimDue leases one enabled job among names that is due and not leased by
+	// another replica, for its timeout plus grace. Rows locked by a concurrent
+	// claim are skipped. Returns nil when no job is due.
+	ClaimDue(ctx context.Context, names []string, runner string, grace time.Duration) (*models.ScheduledJob, error)
+
+	// Complete records a run. If run.Runner still holds the job's lease, the
+	// lease is released and the job's next run is set to nextRunAt.
+	Complete(ctx context.Context, run *models.JobRun, nextRunAt time.Time) error
+
+	// List retrieves all jobs ordered by name
+	List(ctx context.Context) ([]*models.ScheduledJob, error)
+
+	// GetByName retrieves a job by name
+	GetByName(ctx context.Context, name string) (*models.ScheduledJob, error)
+
+	// ListRuns retrieves a job's most recent runs, newest first
+	ListRuns(ctx context.Context, name string, limit int) ([]*models.JobRun, error)
+
+	// TriggerNow makes a job due immediately. Returns an error if a replica
+	// holds the job's lease.
+	TriggerNow(ctx context.Context, name string) error
+
+	// DeleteRunsBefore removes runs started before the given time
+	DeleteRunsBefore(ctx context.Context, before time.Time) (int, error)
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,5 +20,49 @@ type MFARecoveryCodeRepository interface {
 
 	// DeleteRecoveryCodes deletes all recovery codes for a user
 	DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error
+
+	// CountRemaining returns the number of unused recovery codes for a user
+	CountRemaining(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// MFAFactor represents an enrolled MFA factor
+type MFAFactor struct {
+	ID              uuid.UUID  `db:"id"`
+	UserID          uuid.UUID  `db:"user_id"`
+	Type            string     `db:"type"`
+	Name            string     `db:"name"`
+	SecretEncrypted string     `db:"secret_encrypted"`
+	Algorithm       string     `db:"algorithm"` // TOTP HMAC algorithm, e.g. SHA1
+	Digits          int        `db:"digits"`
+	Period          int        `db:"period"` // TOTP time step in seconds
+	Verified        bool       `db:"verified"`
+	VerifiedAt      *time.Time `db:"verified_at"`
+	LastUsedAt      *time.Time `db:"last_used_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+}
+
+// MFAFactorRepository defines the interface for MFA factor storage
+type MFAFactorRepository interface {
+	// Create creates a new factor
+	Create(ctx context.Context, factor *MFAFactor) error
+
+	// GetByID retrieves a factor by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*MFAFactor, error)
+
+	// ListByUserID retrieves all factors for a user, oldest first
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*MFAFactor, error)
+
+	// Update updates a factor's name, verification state and last use
+	Update(ctx context.Context, factor *MFAFactor) error
+
+	// Delete deletes a factor
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// DeleteUnverified deletes a user's pending (never verified) factors
+	DeleteUnverified(ctx context.Context, userID uuid.UUID) error
+
+	// DeleteByUserID deletes all factors for a user
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
 }
 
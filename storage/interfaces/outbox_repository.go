@@ -0,0 +1,36 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+)
+
+// OutboxRepository defines the interface for relaying outbox events.
+// Events are added by AuditEventRepository.Create.
+type OutboxRepository interface {
+	// WithRelayLock runs fn while holding the cluster-wide relay lock and
+	// reports whether it ran. It returns false without running fn when
+	// another relay holds the lock. The lock is released if the process dies.
+	WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+
+	// ListDue retrieves up to limit pending events that are due, oldest first.
+	// Events of a tenant that has an earlier event waiting for a retry are
+	// left out, so each tenant's events are relayed in order.
+	ListDue(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+
+	// MarkProcessed marks an event as handled by every consumer
+	MarkProcessed(ctx context.Context, id int64) error
+
+	// MarkFailed records a failed attempt. The event is retried at nextAttemptAt,
+	// or given up on if dead is set.
+	MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error
+
+	// OldestPending returns when the oldest pending event was written, or nil
+	// if there is none
+	OldestPending(ctx context.Context) (*time.Time, error)
+
+	// DeleteProcessedBefore removes processed and dead events older than the given time
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// PolicyRepository defines the interface for conditional access policy data access
+type PolicyRepository interface {
+	// Create creates a new policy
+	Create(ctx context.Context, policy *models.Policy) error
+
+	// GetByID retrieves a policy by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Policy, error)
+
+	// GetByName retrieves a policy by name and tenant ID
+	GetByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Policy, error)
+
+	// Update updates an existing policy
+	Update(ctx context.Context, policy *models.Policy) error
+
+	// Delete deletes a policy
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// List retrieves a tenant's policies, optionally only the enabled ones
+	List(ctx context.Context, tenantID uuid.UUID, enabledOnly bool) ([]*models.Policy, error)
+}
@@ -17,8 +17,13 @@ type RefreshToken struct {
 	RevokedAt   *time.Time `db:"revoked_at"`
 	RememberMe  bool       `db:"remember_me"`
 	MFAVerified bool       `db:"mfa_verified"`
-	CreatedAt   time.Time  `db:"created_at"`
-	UpdatedAt   time.Time  `db:"updated_at"`
+	// Device/session metadata captured when the token is issued or rotated
+	IPAddress  string     `db:"ip_address"`
+	UserAgent  string     `db:"user_agent"`
+	DeviceInfo string     `db:"device_info"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
 }
 
 // RefreshTokenRepository defines operations for refresh tokens
@@ -26,6 +31,9 @@ type RefreshTokenRepository interface {
 	// Create creates a new refresh token
 	Create(ctx context.Context, token *RefreshToken) error
 
+	// GetByID retrieves a refresh token by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*RefreshToken, error)
+
 	// GetByTokenHash retrieves a refresh token by its hash
 	GetByTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
 
@@ -0,0 +1,49 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// RelationTupleFilter selects relationship tuples. Empty fields match anything.
+type RelationTupleFilter struct {
+	Namespace        string
+	ObjectID         string
+	Relation         string
+	SubjectNamespace string
+	SubjectID        string
+	SubjectRelation  *string // Nil matches any; empty matches direct subjects only
+	Limit            int
+}
+
+// RelationRepository defines the interface for relationship tuple and namespace data access
+type RelationRepository interface {
+	// UpsertNamespace creates or replaces a tenant's namespace definition and returns
+	// the tenant revision of the change
+	UpsertNamespace(ctx context.Context, namespace *models.RelationNamespace) (int64, error)
+
+	// GetNamespace retrieves a namespace definition by name
+	GetNamespace(ctx context.Context, tenantID uuid.UUID, name string) (*models.RelationNamespace, error)
+
+	// ListNamespaces retrieves all namespace definitions of a tenant
+	ListNamespaces(ctx context.Context, tenantID uuid.UUID) ([]*models.RelationNamespace, error)
+
+	// DeleteNamespace deletes a namespace definition together with its tuples and
+	// returns the tenant revision of the deletion
+	DeleteNamespace(ctx context.Context, tenantID uuid.UUID, name string) (int64, error)
+
+	// WriteTuples atomically inserts and deletes tuples and returns the new tenant
+	// revision. Inserting an existing tuple or deleting a missing one is not an error.
+	WriteTuples(ctx context.Context, tenantID uuid.UUID, writes, deletes []*models.RelationTuple) (int64, error)
+
+	// ListTuples retrieves a tenant's tuples matching a filter
+	ListTuples(ctx context.Context, tenantID uuid.UUID, filter RelationTupleFilter) ([]*models.RelationTuple, error)
+
+	// ListObjectIDs retrieves the distinct object IDs of a namespace that have tuples, up to limit
+	ListObjectIDs(ctx context.Context, tenantID uuid.UUID, namespace string, limit int) ([]string, error)
+
+	// CurrentRevision returns the tenant's latest revision, 0 before the first write
+	CurrentRevision(ctx context.Context, tenantID uuid.UUID) (int64, error)
+}
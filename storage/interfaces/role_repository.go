@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
@@ -27,14 +28,36 @@ type RoleRepository interface {
 	// List retrieves a list of roles with filters
 	List(ctx context.Context, tenantID uuid.UUID, filters *RoleFilters) ([]*models.Role, error)
 
-	// GetUserRoles retrieves all roles for a user
+	// GetUserRoles retrieves all roles for a user, skipping expired assignments
 	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error)
 
-	// AssignRoleToUser assigns a role to a user
+	// AssignRoleToUser assigns a role to a user permanently
 	AssignRoleToUser(ctx context.Context, userID, roleID uuid.UUID) error
 
 	// RemoveRoleFromUser removes a role from a user
 	RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error
+
+	// AssignRoleToUserWithExpiry assigns a role that lapses at ExpiresAt.
+	// An existing permanent assignment is left permanent.
+	AssignRoleToUserWithExpiry(ctx context.Context, assignment *models.UserRoleAssignment) error
+
+	// GetUserRoleAssignments retrieves the unexpired role assignments of a user
+	GetUserRoleAssignments(ctx context.Context, userID uuid.UUID) ([]*models.UserRoleAssignment, error)
+
+	// DeleteExpiredRoleAssignments removes assignments that expired before the given time and returns them
+	DeleteExpiredRoleAssignments(ctx context.Context, before time.Time) ([]*models.UserRoleAssignment, error)
+
+	// GetParentRoles retrieves the roles a role directly inherits from
+	GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error)
+
+	// GetChildRoles retrieves the roles that directly inherit from a role
+	GetChildRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error)
+
+	// AddParentRole makes a role inherit from a parent role
+	AddParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error
+
+	// RemoveParentRole removes an inheritance link between two roles
+	RemoveParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error
 }
 
 // RoleFilters represents filters for role queries
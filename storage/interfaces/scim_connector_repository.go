@@ -0,0 +1,80 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// SCIMConnectorRepository defines the interface for outbound SCIM connector data access
+type SCIMConnectorRepository interface {
+	// Create creates a new connector
+	Create(ctx context.Context, connector *models.SCIMConnector) error
+
+	// GetByID retrieves a connector by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMConnector, error)
+
+	// ListByTenant retrieves a tenant's connectors
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMConnector, error)
+
+	// ListEnabled retrieves the enabled connectors of all tenants
+	ListEnabled(ctx context.Context) ([]*models.SCIMConnector, error)
+
+	// Update updates an existing connector's settings
+	Update(ctx context.Context, connector *models.SCIMConnector) error
+
+	// UpdateSyncState records the outcome of the latest sync and reconciliation
+	UpdateSyncState(ctx context.Context, connector *models.SCIMConnector) error
+
+	// Delete deletes a connector along with its queue and remote resource links
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// SCIMSyncOperationFilters filters a connector's sync operations
+type SCIMSyncOperationFilters struct {
+	Status *string
+	Limit  int
+}
+
+// SCIMSyncRepository defines the interface for the outbound SCIM retry queue
+// and the links between local and remote resources
+type SCIMSyncRepository interface {
+	// Enqueue adds an operation to a connector's queue. A pending operation on
+	// the same resource is replaced, so bursts of changes are pushed once.
+	Enqueue(ctx context.Context, op *models.SCIMSyncOperation) error
+
+	// ClaimDue returns up to limit pending operations due at now and defers
+	// them by lease so that concurrent workers do not process them twice
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.SCIMSyncOperation, error)
+
+	// Update records the outcome of an attempt, unless the operation has been
+	// re-enqueued since it was claimed
+	Update(ctx context.Context, op *models.SCIMSyncOperation) error
+
+	// List retrieves a connector's operations, most recent first
+	List(ctx context.Context, connectorID uuid.UUID, filters *SCIMSyncOperationFilters) ([]*models.SCIMSyncOperation, error)
+
+	// CountByStatus counts a connector's operations by status. Pending
+	// operations that have been attempted are counted as "retrying".
+	CountByStatus(ctx context.Context, connectorID uuid.UUID) (map[string]int, error)
+
+	// RetryFailed moves a connector's failed operations back to pending
+	RetryFailed(ctx context.Context, connectorID uuid.UUID) (int, error)
+
+	// DeleteCompletedBefore removes succeeded operations completed before the given time
+	DeleteCompletedBefore(ctx context.Context, before time.Time) (int, error)
+
+	// GetRemote retrieves the remote resource linked to a local resource
+	GetRemote(ctx context.Context, connectorID uuid.UUID, resourceType string, localID uuid.UUID) (*models.SCIMRemoteResource, error)
+
+	// ListRemote retrieves all remote resources of a type linked by a connector
+	ListRemote(ctx context.Context, connectorID uuid.UUID, resourceType string) ([]*models.SCIMRemoteResource, error)
+
+	// SaveRemote creates or replaces the link of a local resource
+	SaveRemote(ctx context.Context, remote *models.SCIMRemoteResource) error
+
+	// DeleteRemote removes the link of a local resource
+	DeleteRemote(ctx context.Context, connectorID uuid.UUID, resourceType string, localID uuid.UUID) error
+}
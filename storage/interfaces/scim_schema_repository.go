@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// SCIMSchemaRepository defines the interface for tenant-defined SCIM extension schema data access
+type SCIMSchemaRepository interface {
+	// Create creates a new extension schema
+	Create(ctx context.Context, extension *models.SCIMSchemaExtension) error
+
+	// GetByID retrieves an extension schema by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMSchemaExtension, error)
+
+	// ListByTenant retrieves a tenant's extension schemas
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMSchemaExtension, error)
+
+	// Update updates an existing extension schema
+	Update(ctx context.Context, extension *models.SCIMSchemaExtension) error
+
+	// Delete deletes an extension schema
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,44 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/google/uuid"
+)
+
+// SoDRuleRepository defines the interface for separation-of-duties rule data access
+type SoDRuleRepository interface {
+	// Create creates a new rule
+	Create(ctx context.Context, rule *models.SoDRule) error
+
+	// GetByID retrieves a rule by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SoDRule, error)
+
+	// ListByTenant retrieves a tenant's rules
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SoDRule, error)
+
+	// Update updates an existing rule
+	Update(ctx context.Context, rule *models.SoDRule) error
+
+	// Delete deletes a rule
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// LockTenant serializes a tenant's role assignments, so that each is
+	// checked against the rules with the others already in place. The lock
+	// is held until the context's transaction ends.
+	LockTenant(ctx context.Context, tenantID uuid.UUID) error
+
+	// ListRoleHolders retrieves the users of a tenant holding any of the given roles,
+	// directly (unexpired assignments), through direct or nested group membership, or
+	// by holding a role that inherits from it. A user holding a role several ways is
+	// listed once for it.
+	ListRoleHolders(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) ([]*SoDRoleHolder, error)
+}
+
+// SoDRoleHolder is a user holding a role, as seen by a violations report
+type SoDRoleHolder struct {
+	UserID   uuid.UUID
+	Username string
+	RoleID   uuid.UUID
+}
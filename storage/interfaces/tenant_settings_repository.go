@@ -28,6 +28,8 @@ type TenantSettings struct {
 	MFARequired                      bool      `db:"mfa_required"`
 	RateLimitRequests                int       `db:"rate_limit_requests"`
 	RateLimitWindowSeconds           int       `db:"rate_limit_window_seconds"`
+	// Self-service settings
+	SelfServiceProfileFields         []string  `db:"self_service_profile_fields"` // Profile fields users may edit via /me
 }
 
 // TenantSettingsRepository defines operations for tenant settings
@@ -0,0 +1,11 @@
+package interfaces
+
+import "context"
+
+// TxRunner runs a function in a database transaction. Repository calls made
+// with the function's context join the transaction, which commits if the
+// function returns nil and rolls back otherwise. Within a context that
+// already carries a transaction, the function runs in a savepoint.
+type TxRunner interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
@@ -15,6 +16,10 @@ type UserRepository interface {
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 
+	// LockByID locks a user until the context's transaction ends, so that
+	// what is read about it meanwhile still holds when it is written
+	LockByID(ctx context.Context, id uuid.UUID) error
+
 	// GetByUsername retrieves a user by username and tenant ID
 	GetByUsername(ctx context.Context, username string, tenantID uuid.UUID) (*models.User, error)
 
@@ -30,6 +35,9 @@ type UserRepository interface {
 	// Update updates an existing user
 	Update(ctx context.Context, u *models.User) error
 
+	// UpdateLastLogin records a successful login without touching other user fields
+	UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error
+
 	// Delete soft deletes a user
 	Delete(ctx context.Context, id uuid.UUID) error
 
@@ -48,9 +56,13 @@ type UserRepository interface {
 
 // UserFilters represents filters for user queries
 type UserFilters struct {
-	Status   *string
-	Search   *string // Search in username, email, first_name, last_name
-	Page     int
-	PageSize int
+	Status         *string
+	Search         *string     // Search in username, email, first_name, last_name
+	Filter         *FilterExpr // Over the UserField* fields
+	SortBy         string      // A UserField* field; defaults to newest first
+	SortDescending bool
+	Page           int
+	PageSize       int
+	Offset         *int // Overrides Page for callers that page by position
 }
 
@@ -22,12 +22,27 @@ type WebhookRepository interface {
 	// GetEnabledByTenantID retrieves all enabled webhooks for a tenant
 	GetEnabledByTenantID(ctx context.Context, tenantID uuid.UUID) ([]*models.Webhook, error)
 
-	// GetByEventType retrieves all enabled webhooks subscribed to an event type
+	// GetByEventType retrieves all enabled webhooks subscribed to an event type,
+	// including through wildcard subscriptions
 	GetByEventType(ctx context.Context, tenantID uuid.UUID, eventType string) ([]*models.Webhook, error)
 
 	// Update updates an existing webhook
 	Update(ctx context.Context, webhook *models.Webhook) error
 
+	// RecordDeliveryResult resets or increments the consecutive failure count
+	// and returns the new count
+	RecordDeliveryResult(ctx context.Context, id uuid.UUID, success bool) (int, error)
+
+	// Disable disables an enabled webhook, reporting whether it was enabled
+	Disable(ctx context.Context, id uuid.UUID, reason string) (bool, error)
+
+	// ClaimDue leases up to limit enabled webhooks whose oldest queued
+	// delivery is due, so only the lease holder delivers to each endpoint
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.Webhook, error)
+
+	// Release ends a webhook's delivery lease
+	Release(ctx context.Context, id uuid.UUID) error
+
 	// Delete soft deletes a webhook
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -43,8 +58,17 @@ type WebhookDeliveryRepository interface {
 	// GetByWebhookID retrieves all deliveries for a webhook
 	GetByWebhookID(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*models.WebhookDelivery, int, error)
 
-	// GetPendingRetries retrieves all deliveries that need to be retried
-	GetPendingRetries(ctx context.Context, before time.Time) ([]*models.WebhookDelivery, error)
+	// Enqueue queues a delivery unless the webhook already has a delivery
+	// for the same event, and reports whether it was queued
+	Enqueue(ctx context.Context, delivery *models.WebhookDelivery) (bool, error)
+
+	// NextQueued retrieves a webhook's oldest pending or retrying delivery,
+	// or nil if its queue is empty
+	NextQueued(ctx context.Context, webhookID uuid.UUID) (*models.WebhookDelivery, error)
+
+	// AbandonQueued marks a webhook's pending and retrying deliveries as failed
+	// and returns how many there were
+	AbandonQueued(ctx context.Context, webhookID uuid.UUID) (int, error)
 
 	// Update updates a webhook delivery record
 	Update(ctx context.Context, delivery *models.WebhookDelivery) error
@@ -0,0 +1,449 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// accessReviewRepository implements AccessReviewRepository for PostgreSQL
+type accessReviewRepository struct {
+	db *sql.DB
+}
+
+// NewAccessReviewRepository creates a new PostgreSQL access review repository
+func NewAccessReviewRepository(db *sql.DB) interfaces.AccessReviewRepository {
+	return &accessReviewRepository{db: db}
+}
+
+const accessReviewCampaignColumns = `id, tenant_id, name, description, scope_type, scope_ids, reviewer_type,
+	fallback_reviewer_ids, default_decision, status, due_at, created_by, closed_by, closed_at,
+	last_reminded_at, created_at, updated_at`
+
+const accessReviewItemColumns = `id, campaign_id, tenant_id, item_type, user_id, role_id, client_id, subject,
+	access, reviewer_id, decision, comment, decided_by, decided_at, applied_at, apply_error, created_at`
+
+// CreateCampaign creates a campaign together with its snapshot of items
+func (r *accessReviewRepository) CreateCampaign(ctx context.Context, campaign *models.AccessReviewCampaign, items []*models.AccessReviewItem) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if campaign.ID == uuid.Nil {
+		campaign.ID = uuid.New()
+	}
+	campaign.CreatedAt = now
+	campaign.UpdatedAt = now
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO access_review_campaigns (id, tenant_id, name, description, scope_type, scope_ids, reviewer_type,
+			fallback_reviewer_ids, default_decision, status, due_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`,
+		campaign.ID, campaign.TenantID, campaign.Name, campaign.Description, campaign.ScopeType,
+		pq.Array(uuidStrings(campaign.ScopeIDs)), campaign.ReviewerType,
+		pq.Array(uuidStrings(campaign.FallbackReviewerIDs)), campaign.DefaultDecision, campaign.Status,
+		campaign.DueAt, campaign.CreatedBy, campaign.CreatedAt, campaign.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create access review campaign: %w", err)
+	}
+
+	for _, item := range items {
+		if item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+		item.CampaignID = campaign.ID
+		item.TenantID = campaign.TenantID
+		item.CreatedAt = now
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO access_review_items (id, campaign_id, tenant_id, item_type, user_id, role_id, client_id,
+				subject, access, reviewer_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`,
+			item.ID, item.CampaignID, item.TenantID, item.ItemType, item.UserID, item.RoleID, item.ClientID,
+			item.Subject, item.Access, item.ReviewerID, item.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create access review item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit access review campaign: %w", err)
+	}
+
+	return nil
+}
+
+// GetCampaign retrieves a campaign by ID
+func (r *accessReviewRepository) GetCampaign(ctx context.Context, id uuid.UUID) (*models.AccessReviewCampaign, error) {
+	query := `SELECT ` + accessReviewCampaignColumns + ` FROM access_review_campaigns WHERE id = $1`
+
+	campaign, err := scanAccessReviewCampaign(querier(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("access review campaign not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access review campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+// ListCampaigns retrieves a tenant's campaigns, newest first, optionally by status
+func (r *accessReviewRepository) ListCampaigns(ctx context.Context, tenantID uuid.UUID, status *string) ([]*models.AccessReviewCampaign, error) {
+	query := `SELECT ` + accessReviewCampaignColumns + ` FROM access_review_campaigns WHERE tenant_id = $1`
+	args := []interface{}{tenantID}
+	if status != nil {
+		query += ` AND status = $2`
+		args = append(args, *status)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	return r.queryCampaigns(ctx, query, args...)
+}
+
+// ListActiveCampaigns retrieves active campaigns across all tenants
+func (r *accessReviewRepository) ListActiveCampaigns(ctx context.Context) ([]*models.AccessReviewCampaign, error) {
+	query := `SELECT ` + accessReviewCampaignColumns + ` FROM access_review_campaigns WHERE status = $1 ORDER BY created_at`
+	return r.queryCampaigns(ctx, query, models.AccessReviewStatusActive)
+}
+
+// UpdateCampaign stores a campaign's status, closing and reminder fields
+func (r *accessReviewRepository) UpdateCampaign(ctx context.Context, campaign *models.AccessReviewCampaign) error {
+	query := `
+		UPDATE access_review_campaigns
+		SET status = $2, closed_by = $3, closed_at = $4, last_reminded_at = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	campaign.UpdatedAt = time.Now()
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		campaign.ID, campaign.Status, campaign.ClosedBy, campaign.ClosedAt,
+		campaign.LastRemindedAt, campaign.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update access review campaign: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("access review campaign not found")
+	}
+
+	return nil
+}
+
+// GetItem retrieves a review item by ID
+func (r *accessReviewRepository) GetItem(ctx context.Context, id uuid.UUID) (*models.AccessReviewItem, error) {
+	query := `SELECT ` + accessReviewItemColumns + ` FROM access_review_items WHERE id = $1`
+
+	item, err := scanAccessReviewItem(querier(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("access review item not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access review item: %w", err)
+	}
+
+	return item, nil
+}
+
+// ListItems retrieves a campaign's items
+func (r *accessReviewRepository) ListItems(ctx context.Context, campaignID uuid.UUID, filters *interfaces.AccessReviewItemFilters) ([]*models.AccessReviewItem, error) {
+	query := `SELECT ` + accessReviewItemColumns + ` FROM access_review_items WHERE campaign_id = $1`
+	args := []interface{}{campaignID}
+	argPos := 2
+
+	if filters != nil {
+		if filters.ReviewerID != nil {
+			query += fmt.Sprintf(" AND reviewer_id = $%d", argPos)
+			args = append(args, *filters.ReviewerID)
+			argPos++
+		}
+		if filters.Decision != nil {
+			if *filters.Decision == "none" {
+				query += " AND decision IS NULL"
+			} else {
+				query += fmt.Sprintf(" AND decision = $%d", argPos)
+				args = append(args, *filters.Decision)
+			}
+		}
+	}
+	query += ` ORDER BY subject, access`
+
+	return r.queryItems(ctx, query, args...)
+}
+
+// ListReviewerItems retrieves the undecided items assigned to a reviewer in active campaigns
+func (r *accessReviewRepository) ListReviewerItems(ctx context.Context, tenantID, reviewerID uuid.UUID) ([]*models.AccessReviewItem, error) {
+	query := `SELECT ` + accessReviewItemColumns + ` FROM access_review_items
+		WHERE tenant_id = $1 AND reviewer_id = $2 AND decision IS NULL
+		  AND campaign_id IN (SELECT id FROM access_review_campaigns WHERE status = $3)
+		ORDER BY subject, access
+	`
+	return r.queryItems(ctx, query, tenantID, reviewerID, models.AccessReviewStatusActive)
+}
+
+// UpdateItem stores an item's reviewer, decision and application fields
+func (r *accessReviewRepository) UpdateItem(ctx context.Context, item *models.AccessReviewItem) error {
+	query := `
+		UPDATE access_review_items
+		SET reviewer_id = $2, decision = $3, comment = $4, decided_by = $5, decided_at = $6,
+			applied_at = $7, apply_error = $8
+		WHERE id = $1
+	`
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		item.ID, item.ReviewerID, item.Decision, item.Comment, item.DecidedBy,
+		item.DecidedAt, item.AppliedAt, item.ApplyError,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update access review item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("access review item not found")
+	}
+
+	return nil
+}
+
+// ListRoleAssignments retrieves current user role assignments in a tenant for a snapshot
+func (r *accessReviewRepository) ListRoleAssignments(ctx context.Context, tenantID uuid.UUID, roleIDs, userIDs []uuid.UUID) ([]*interfaces.AccessReviewAssignment, error) {
+	query := `
+		SELECT u.id, u.username, u.metadata->>'manager_id', r.id, r.name, r.owner_id
+		FROM user_roles ur
+		INNER JOIN users u ON u.id = ur.user_id
+		INNER JOIN roles r ON r.id = ur.role_id
+		WHERE r.tenant_id = $1 AND r.deleted_at IS NULL AND u.deleted_at IS NULL
+		  AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+	`
+	args := []interface{}{tenantID}
+	if len(roleIDs) > 0 {
+		args = append(args, pq.Array(uuidStrings(roleIDs)))
+		query += fmt.Sprintf(" AND r.id = ANY($%d::uuid[])", len(args))
+	}
+	if len(userIDs) > 0 {
+		args = append(args, pq.Array(uuidStrings(userIDs)))
+		query += fmt.Sprintf(" AND u.id = ANY($%d::uuid[])", len(args))
+	}
+	query += ` ORDER BY u.username, r.name`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*interfaces.AccessReviewAssignment
+	for rows.Next() {
+		assignment := &interfaces.AccessReviewAssignment{}
+		var managerID sql.NullString
+		var ownerID uuid.NullUUID
+
+		if err := rows.Scan(
+			&assignment.UserID, &assignment.Username, &managerID,
+			&assignment.RoleID, &assignment.RoleName, &ownerID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan role assignment: %w", err)
+		}
+
+		// Ignore a manager_id that is not a UUID; the item falls back to other reviewers
+		if managerID.Valid {
+			if parsed, err := uuid.Parse(managerID.String); err == nil {
+				assignment.ManagerID = &parsed
+			}
+		}
+		if ownerID.Valid {
+			assignment.RoleOwnerID = &ownerID.UUID
+		}
+
+		assignments = append(assignments, assignment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// queryCampaigns runs a query returning campaign rows
+func (r *accessReviewRepository) queryCampaigns(ctx context.Context, query string, args ...interface{}) ([]*models.AccessReviewCampaign, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access review campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	var campaigns []*models.AccessReviewCampaign
+	for rows.Next() {
+		campaign, err := scanAccessReviewCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan access review campaign: %w", err)
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating access review campaigns: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+// queryItems runs a query returning item rows
+func (r *accessReviewRepository) queryItems(ctx context.Context, query string, args ...interface{}) ([]*models.AccessReviewItem, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access review items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.AccessReviewItem
+	for rows.Next() {
+		item, err := scanAccessReviewItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan access review item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating access review items: %w", err)
+	}
+
+	return items, nil
+}
+
+// scanAccessReviewCampaign scans a row selected with accessReviewCampaignColumns
+func scanAccessReviewCampaign(row rowScanner) (*models.AccessReviewCampaign, error) {
+	campaign := &models.AccessReviewCampaign{}
+	var description sql.NullString
+	var scopeIDs, fallbackReviewerIDs pq.StringArray
+	var dueAt, closedAt, lastRemindedAt sql.NullTime
+	var createdBy, closedBy uuid.NullUUID
+
+	err := row.Scan(
+		&campaign.ID, &campaign.TenantID, &campaign.Name, &description, &campaign.ScopeType,
+		&scopeIDs, &campaign.ReviewerType, &fallbackReviewerIDs, &campaign.DefaultDecision,
+		&campaign.Status, &dueAt, &createdBy, &closedBy, &closedAt, &lastRemindedAt,
+		&campaign.CreatedAt, &campaign.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign.ScopeIDs = parseUUIDs(scopeIDs)
+	campaign.FallbackReviewerIDs = parseUUIDs(fallbackReviewerIDs)
+	if description.Valid {
+		campaign.Description = &description.String
+	}
+	if dueAt.Valid {
+		campaign.DueAt = &dueAt.Time
+	}
+	if createdBy.Valid {
+		campaign.CreatedBy = &createdBy.UUID
+	}
+	if closedBy.Valid {
+		campaign.ClosedBy = &closedBy.UUID
+	}
+	if closedAt.Valid {
+		campaign.ClosedAt = &closedAt.Time
+	}
+	if lastRemindedAt.Valid {
+		campaign.LastRemindedAt = &lastRemindedAt.Time
+	}
+
+	return campaign, nil
+}
+
+// scanAccessReviewItem scans a row selected with accessReviewItemColumns
+func scanAccessReviewItem(row rowScanner) (*models.AccessReviewItem, error) {
+	item := &models.AccessReviewItem{}
+	var userID, roleID, clientID, reviewerID, decidedBy uuid.NullUUID
+	var decision, comment, applyError sql.NullString
+	var decidedAt, appliedAt sql.NullTime
+
+	err := row.Scan(
+		&item.ID, &item.CampaignID, &item.TenantID, &item.ItemType, &userID, &roleID, &clientID,
+		&item.Subject, &item.Access, &reviewerID, &decision, &comment, &decidedBy, &decidedAt,
+		&appliedAt, &applyError, &item.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID.Valid {
+		item.UserID = &userID.UUID
+	}
+	if roleID.Valid {
+		item.RoleID = &roleID.UUID
+	}
+	if clientID.Valid {
+		item.ClientID = &clientID.UUID
+	}
+	if reviewerID.Valid {
+		item.ReviewerID = &reviewerID.UUID
+	}
+	if decidedBy.Valid {
+		item.DecidedBy = &decidedBy.UUID
+	}
+	if decision.Valid {
+		item.Decision = &decision.String
+	}
+	if comment.Valid {
+		item.Comment = &comment.String
+	}
+	if applyError.Valid {
+		item.ApplyError = &applyError.String
+	}
+	if decidedAt.Valid {
+		item.DecidedAt = &decidedAt.Time
+	}
+	if appliedAt.Valid {
+		item.AppliedAt = &appliedAt.Time
+	}
+
+	return item, nil
+}
+
+// uuidStrings converts UUIDs for a PostgreSQL array parameter
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
+// parseUUIDs converts a scanned PostgreSQL UUID array, skipping invalid entries
+func parseUUIDs(values pq.StringArray) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(values))
+	for _, v := range values {
+		if id, err := uuid.Parse(v); err == nil {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/internal/auditchain"
 	"github.com/arauth-identity/iam/storage/interfaces"
 )
 
@@ -23,7 +25,16 @@ func NewAuditEventRepository(db *sql.DB) interfaces.AuditEventRepository {
 	return &auditEventRepository{db: db}
 }
 
-// Create creates a new audit event
+const auditEventColumns = `id, event_type, actor_user_id, actor_username, actor_principal_type,
+	target_type, target_id, target_identifier, timestamp, source_ip,
+	user_agent, tenant_id, metadata, result, error, created_at, seq, prev_hash, hash`
+
+const auditCheckpointColumns = `id, chain_id, tenant_id, seq, hash, key_id, signature, created_at`
+
+// Create creates a new audit event. Within a request's transaction the
+// event is appended to its chain just before the transaction commits, so
+// the chain head is only locked for the end of the transaction and not
+// while the rest of the request runs.
 func (r *auditEventRepository) Create(ctx context.Context, event *models.AuditEvent) error {
 	// Flatten the event for database storage
 	event.Flatten()
@@ -37,6 +48,9 @@ func (r *auditEventRepository) Create(ctx context.Context, event *models.AuditEv
 	if event.CreatedAt.IsZero() {
 		event.CreatedAt = time.Now()
 	}
+	// The hash covers the expanded fields, which is what a read returns
+	event.Expand()
+	auditchain.Normalize(event)
 
 	var metadataJSON []byte
 	if event.Metadata != nil {
@@ -47,15 +61,54 @@ func (r *auditEventRepository) Create(ctx context.Context, event *models.AuditEv
 		}
 	}
 
+	deferred := beforeCommit(ctx, func(ctx context.Context) error {
+		return r.append(ctx, querier(ctx, r.db), event, metadataJSON)
+	})
+	if deferred {
+		return nil
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.append(ctx, tx, event, metadataJSON); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit event: %w", err)
+	}
+
+	return nil
+}
+
+// append adds an event to its tenant's chain within tx. The event and its
+// outbox entry are written together so consumers never miss a recorded
+// event, even if the process dies right after.
+func (r *auditEventRepository) append(ctx context.Context, tx dbtx, event *models.AuditEvent, metadataJSON []byte) error {
+	// Appending to the tenant's chain locks its head until the transaction
+	// ends, so the tenant's events get consecutive sequence numbers
+	head, err := lockAuditChain(ctx, tx, event.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := auditchain.Link(event, head.Seq+1, head.Hash); err != nil {
+		return fmt.Errorf("failed to hash audit event: %w", err)
+	}
+
 	query := `
 		INSERT INTO audit_events (
 			id, event_type, actor_user_id, actor_username, actor_principal_type,
 			target_type, target_id, target_identifier, timestamp, source_ip,
-			user_agent, tenant_id, metadata, result, error, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			user_agent, tenant_id, metadata, result, error, created_at,
+			seq, prev_hash, hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		event.ID,
 		event.EventType,
 		event.ActorUserID,
@@ -72,16 +125,27 @@ func (r *auditEventRepository) Create(ctx context.Context, event *models.AuditEv
 		event.Result,
 		event.Error,
 		event.CreatedAt,
+		event.Seq,
+		event.PrevHash,
+		event.Hash,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create audit event: %w", err)
 	}
 
+	_, err = tx.ExecContext(ctx, `
+		UPDATE audit_chains SET seq = $2, hash = $3, updated_at = NOW()
+		WHERE chain_id = $1
+	`, head.ChainID, event.Seq, event.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to update audit chain: %w", err)
+	}
+
 	// Expand back for consistency
 	event.Expand()
 
-	return nil
+	return insertOutboxEvent(ctx, tx, event)
 }
 
 // QueryEvents retrieves audit events with filters
@@ -149,7 +213,7 @@ func (r *auditEventRepository) QueryEvents(ctx context.Context, filters *interfa
 	// Count total records
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM audit_events %s", whereClause)
 	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	err := querier(ctx, r.db).QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
 	}
@@ -157,19 +221,16 @@ func (r *auditEventRepository) QueryEvents(ctx context.Context, filters *interfa
 	// Query with pagination
 	offset := (filters.Page - 1) * filters.PageSize
 	query := fmt.Sprintf(`
-		SELECT 
-			id, event_type, actor_user_id, actor_username, actor_principal_type,
-			target_type, target_id, target_identifier, timestamp, source_ip,
-			user_agent, tenant_id, metadata, result, error, created_at
+		SELECT %s
 		FROM audit_events
 		%s
 		ORDER BY timestamp DESC
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
+	`, auditEventColumns, whereClause, argIndex, argIndex+1)
 
 	args = append(args, filters.PageSize, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query audit events: %w", err)
 	}
@@ -177,83 +238,255 @@ func (r *auditEventRepository) QueryEvents(ctx context.Context, filters *interfa
 
 	var events []*models.AuditEvent
 	for rows.Next() {
-		event := &models.AuditEvent{}
-		var metadataJSON []byte
-		var sourceIP sql.NullString
-		var userAgent sql.NullString
-		var errorMsg sql.NullString
-
-		err := rows.Scan(
-			&event.ID,
-			&event.EventType,
-			&event.ActorUserID,
-			&event.ActorUsername,
-			&event.ActorPrincipalType,
-			&event.TargetType,
-			&event.TargetID,
-			&event.TargetIdentifier,
-			&event.Timestamp,
-			&sourceIP,
-			&userAgent,
-			&event.TenantID,
-			&metadataJSON,
-			&event.Result,
-			&errorMsg,
-			&event.CreatedAt,
-		)
+		event, err := scanAuditEvent(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan audit event: %w", err)
+			return nil, 0, err
 		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating audit events: %w", err)
+	}
 
-		// Expand the event
-		event.Expand()
+	return events, total, nil
+}
+
+// GetEvent retrieves an audit event by ID
+func (r *auditEventRepository) GetEvent(ctx context.Context, eventID uuid.UUID) (*models.AuditEvent, error) {
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events WHERE id = $1`
 
-		// Set nullable fields
-		if sourceIP.Valid {
-			event.SourceIP = sourceIP.String
+	event, err := scanAuditEvent(querier(ctx, r.db).QueryRowContext(ctx, query, eventID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("audit event not found: %w", err)
 		}
-		if userAgent.Valid {
-			event.UserAgent = userAgent.String
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ListChain retrieves a chain's events after afterSeq in sequence order
+func (r *auditEventRepository) ListChain(ctx context.Context, tenantID *uuid.UUID, afterSeq int64, limit int) ([]*models.AuditEvent, error) {
+	condition, args := auditTenantCondition(tenantID)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM audit_events
+		WHERE %s AND seq > $%d
+		ORDER BY seq
+		LIMIT $%d
+	`, auditEventColumns, condition, len(args)+1, len(args)+2)
+	args = append(args, afterSeq, limit)
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.AuditEvent
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
 		}
-		if errorMsg.Valid {
-			event.Error = errorMsg.String
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit chain: %w", err)
+	}
+	return events, nil
+}
+
+// CountUnchained counts a tenant's events without a sequence number
+func (r *auditEventRepository) CountUnchained(ctx context.Context, tenantID *uuid.UUID, since time.Time) (int64, error) {
+	condition, args := auditTenantCondition(tenantID)
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) FROM audit_events
+		WHERE %s AND seq IS NULL AND created_at >= $%d
+	`, condition, len(args)+1)
+	args = append(args, since)
+
+	var count int64
+	if err := querier(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unchained audit events: %w", err)
+	}
+	return count, nil
+}
+
+// GetChain retrieves the head of a tenant's chain
+func (r *auditEventRepository) GetChain(ctx context.Context, tenantID *uuid.UUID) (*models.AuditChain, error) {
+	query := `
+		SELECT chain_id, tenant_id, seq, hash, checkpoint_seq, created_at, updated_at
+		FROM audit_chains
+		WHERE chain_id = $1
+	`
+	chain, err := scanAuditChain(querier(ctx, r.db).QueryRowContext(ctx, query, models.AuditChainID(tenantID)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("audit chain not found: %w", err)
 		}
+		return nil, err
+	}
+	return chain, nil
+}
+
+// ListChains retrieves the heads of all chains
+func (r *auditEventRepository) ListChains(ctx context.Context) ([]*models.AuditChain, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, `
+		SELECT chain_id, tenant_id, seq, hash, checkpoint_seq, created_at, updated_at
+		FROM audit_chains
+		ORDER BY chain_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit chains: %w", err)
+	}
+	defer rows.Close()
 
-		// Parse metadata
-		if len(metadataJSON) > 0 {
-			if err := json.Unmarshal(metadataJSON, &event.Metadata); err != nil {
-				return nil, 0, fmt.Errorf("failed to unmarshal metadata: %w", err)
-			}
+	var chains []*models.AuditChain
+	for rows.Next() {
+		chain, err := scanAuditChain(rows)
+		if err != nil {
+			return nil, err
 		}
+		chains = append(chains, chain)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit chains: %w", err)
+	}
+	return chains, nil
+}
 
-		events = append(events, event)
+// CreateCheckpoint stores a signed checkpoint
+func (r *auditEventRepository) CreateCheckpoint(ctx context.Context, checkpoint *models.AuditCheckpoint) error {
+	if checkpoint.ID == uuid.Nil {
+		checkpoint.ID = uuid.New()
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_checkpoints (`+auditCheckpointColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		checkpoint.ID,
+		checkpoint.ChainID,
+		checkpoint.TenantID,
+		checkpoint.Seq,
+		checkpoint.Hash,
+		checkpoint.KeyID,
+		checkpoint.Signature,
+		checkpoint.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit checkpoint: %w", err)
 	}
 
+	_, err = tx.ExecContext(ctx, `
+		UPDATE audit_chains SET checkpoint_seq = GREATEST(checkpoint_seq, $2)
+		WHERE chain_id = $1
+	`, checkpoint.ChainID, checkpoint.Seq)
+	if err != nil {
+		return fmt.Errorf("failed to update audit chain: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ListCheckpoints retrieves a chain's checkpoints from fromSeq in sequence order
+func (r *auditEventRepository) ListCheckpoints(ctx context.Context, tenantID *uuid.UUID, fromSeq int64, limit int) ([]*models.AuditCheckpoint, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, `
+		SELECT `+auditCheckpointColumns+`
+		FROM audit_checkpoints
+		WHERE chain_id = $1 AND seq >= $2
+		ORDER BY seq
+		LIMIT $3
+	`, models.AuditChainID(tenantID), fromSeq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []*models.AuditCheckpoint
+	for rows.Next() {
+		cp := &models.AuditCheckpoint{}
+		if err := rows.Scan(&cp.ID, &cp.ChainID, &cp.TenantID, &cp.Seq, &cp.Hash, &cp.KeyID, &cp.Signature, &cp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit checkpoint: %w", err)
+		}
+		checkpoints = append(checkpoints, cp)
+	}
 	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating audit events: %w", err)
+		return nil, fmt.Errorf("error iterating audit checkpoints: %w", err)
 	}
+	return checkpoints, nil
+}
 
-	return events, total, nil
+// lockAuditChain locks a tenant's chain head within tx, starting the
+// chain if this is the tenant's first event
+func lockAuditChain(ctx context.Context, tx dbtx, tenantID *uuid.UUID) (*models.AuditChain, error) {
+	chainID := models.AuditChainID(tenantID)
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_chains (chain_id, tenant_id, hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (chain_id) DO NOTHING
+	`, chainID, tenantID, auditchain.GenesisHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start audit chain: %w", err)
+	}
+
+	head := &models.AuditChain{ChainID: chainID, TenantID: tenantID}
+	err = tx.QueryRowContext(ctx, `
+		SELECT seq, hash FROM audit_chains WHERE chain_id = $1 FOR UPDATE
+	`, chainID).Scan(&head.Seq, &head.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+	return head, nil
 }
 
-// GetEvent retrieves an audit event by ID
-func (r *auditEventRepository) GetEvent(ctx context.Context, eventID uuid.UUID) (*models.AuditEvent, error) {
-	query := `
-		SELECT 
-			id, event_type, actor_user_id, actor_username, actor_principal_type,
-			target_type, target_id, target_identifier, timestamp, source_ip,
-			user_agent, tenant_id, metadata, result, error, created_at
-		FROM audit_events
-		WHERE id = $1
-	`
+// auditTenantCondition matches a tenant's events, or the system events
+// when tenantID is nil. Its argument, if any, is $1.
+func auditTenantCondition(tenantID *uuid.UUID) (string, []interface{}) {
+	if tenantID == nil {
+		return "tenant_id IS NULL", nil
+	}
+	return "tenant_id = $1", []interface{}{*tenantID}
+}
 
+// scanAuditChain scans an audit chain head
+func scanAuditChain(row rowScanner) (*models.AuditChain, error) {
+	chain := &models.AuditChain{}
+	err := row.Scan(&chain.ChainID, &chain.TenantID, &chain.Seq, &chain.Hash, &chain.CheckpointSeq,
+		&chain.CreatedAt, &chain.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan audit chain: %w", err)
+	}
+	return chain, nil
+}
+
+// scanAuditEvent scans a row selected with auditEventColumns
+func scanAuditEvent(row rowScanner) (*models.AuditEvent, error) {
 	event := &models.AuditEvent{}
 	var metadataJSON []byte
 	var sourceIP sql.NullString
 	var userAgent sql.NullString
 	var errorMsg sql.NullString
+	var seq sql.NullInt64
+	var prevHash sql.NullString
+	var hash sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, eventID).Scan(
+	err := row.Scan(
 		&event.ID,
 		&event.EventType,
 		&event.ActorUserID,
@@ -270,12 +503,15 @@ func (r *auditEventRepository) GetEvent(ctx context.Context, eventID uuid.UUID)
 		&event.Result,
 		&errorMsg,
 		&event.CreatedAt,
+		&seq,
+		&prevHash,
+		&hash,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("audit event not found: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to get audit event: %w", err)
+		return nil, fmt.Errorf("failed to scan audit event: %w", err)
 	}
 
 	// Expand the event
@@ -291,6 +527,9 @@ func (r *auditEventRepository) GetEvent(ctx context.Context, eventID uuid.UUID)
 	if errorMsg.Valid {
 		event.Error = errorMsg.String
 	}
+	event.Seq = seq.Int64
+	event.PrevHash = prevHash.String
+	event.Hash = hash.String
 
 	// Parse metadata
 	if len(metadataJSON) > 0 {
@@ -301,4 +540,3 @@ func (r *auditEventRepository) GetEvent(ctx context.Context, eventID uuid.UUID)
 
 	return event, nil
 }
-
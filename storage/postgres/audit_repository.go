@@ -42,7 +42,7 @@ func (r *auditRepository) Create(ctx context.Context, log *interfaces.AuditLog)
 		metadataJSON, _ = json.Marshal(log.Metadata)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		log.ID, log.TenantID, log.UserID, log.Action, log.Resource,
 		log.ResourceID, log.IPAddress, log.UserAgent, log.Status,
 		log.Message, metadataJSON, log.CreatedAt,
@@ -69,7 +69,7 @@ func (r *auditRepository) GetByID(ctx context.Context, id uuid.UUID) (*interface
 	var resourceID sql.NullString
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&log.ID, &log.TenantID, &userID, &log.Action, &log.Resource,
 		&resourceID, &log.IPAddress, &log.UserAgent, &log.Status,
 		&log.Message, &metadataJSON, &log.CreatedAt,
@@ -162,7 +162,7 @@ func (r *auditRepository) List(ctx context.Context, tenantID uuid.UUID, filters
 	query += " ORDER BY created_at DESC LIMIT $" + fmt.Sprintf("%d", argPos) + " OFFSET $" + fmt.Sprintf("%d", argPos+1)
 	args = append(args, filters.PageSize, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list audit logs: %w", err)
 	}
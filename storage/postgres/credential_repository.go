@@ -45,7 +45,7 @@ func (r *credentialRepository) Create(ctx context.Context, cred *credential.Cred
 		cred.PasswordChangedAt = now
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		cred.ID, cred.UserID, cred.PasswordHash, cred.PasswordChangedAt,
 		cred.PasswordExpiresAt, cred.FailedLoginAttempts, cred.LockedUntil,
 		cred.CreatedAt, cred.UpdatedAt,
@@ -71,7 +71,7 @@ func (r *credentialRepository) GetByUserID(ctx context.Context, userID uuid.UUID
 	cred := &credential.Credential{}
 	var passwordExpiresAt, lockedUntil sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(
 		&cred.ID, &cred.UserID, &cred.PasswordHash, &cred.PasswordChangedAt,
 		&passwordExpiresAt, &cred.FailedLoginAttempts, &lockedUntil,
 		&cred.CreatedAt, &cred.UpdatedAt,
@@ -106,7 +106,7 @@ func (r *credentialRepository) Update(ctx context.Context, cred *credential.Cred
 
 	cred.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		cred.UserID, cred.PasswordHash, cred.PasswordChangedAt,
 		cred.PasswordExpiresAt, cred.FailedLoginAttempts, cred.LockedUntil,
 		cred.UpdatedAt,
@@ -123,7 +123,7 @@ func (r *credentialRepository) Update(ctx context.Context, cred *credential.Cred
 func (r *credentialRepository) Delete(ctx context.Context, userID uuid.UUID) error {
 	query := `DELETE FROM credentials WHERE user_id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, userID)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete credential: %w", err)
 	}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// elevationRequestRepository implements ElevationRequestRepository for PostgreSQL
+type elevationRequestRepository struct {
+	db *sql.DB
+}
+
+// NewElevationRequestRepository creates a new PostgreSQL elevation request repository
+func NewElevationRequestRepository(db *sql.DB) interfaces.ElevationRequestRepository {
+	return &elevationRequestRepository{db: db}
+}
+
+const elevationRequestColumns = `id, tenant_id, user_id, role_id, reason, duration_seconds, status,
+	decided_by, decided_at, decision_comment, expires_at, created_at, updated_at`
+
+// Create creates a new elevation request
+func (r *elevationRequestRepository) Create(ctx context.Context, req *models.ElevationRequest) error {
+	query := `
+		INSERT INTO role_elevation_requests (id, tenant_id, user_id, role_id, reason, duration_seconds, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	now := time.Now()
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	if req.Status == "" {
+		req.Status = models.ElevationStatusPending
+	}
+	req.CreatedAt = now
+	req.UpdatedAt = now
+
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		req.ID, req.TenantID, req.UserID, req.RoleID, req.Reason,
+		req.DurationSeconds, req.Status, req.CreatedAt, req.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create elevation request: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an elevation request by ID
+func (r *elevationRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ElevationRequest, error) {
+	query := `SELECT ` + elevationRequestColumns + ` FROM role_elevation_requests WHERE id = $1`
+
+	req, err := scanElevationRequest(querier(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("elevation request not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get elevation request: %w", err)
+	}
+
+	return req, nil
+}
+
+// Transition stores a request's new status and decision fields if it is still in the from status
+func (r *elevationRequestRepository) Transition(ctx context.Context, req *models.ElevationRequest, from string) error {
+	query := `
+		UPDATE role_elevation_requests
+		SET status = $3, decided_by = $4, decided_at = $5, decision_comment = $6, expires_at = $7, updated_at = $8
+		WHERE id = $1 AND status = $2
+	`
+
+	req.UpdatedAt = time.Now()
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		req.ID, from, req.Status, req.DecidedBy, req.DecidedAt,
+		req.DecisionComment, req.ExpiresAt, req.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update elevation request: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("elevation request is no longer %s", from)
+	}
+
+	return nil
+}
+
+// List retrieves a tenant's elevation requests, newest first
+func (r *elevationRequestRepository) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.ElevationRequestFilters) ([]*models.ElevationRequest, error) {
+	query := `SELECT ` + elevationRequestColumns + ` FROM role_elevation_requests WHERE tenant_id = $1`
+	args := []interface{}{tenantID}
+	argPos := 2
+
+	if filters != nil {
+		if filters.Status != nil {
+			query += fmt.Sprintf(" AND status = $%d", argPos)
+			args = append(args, *filters.Status)
+			argPos++
+		}
+		if filters.UserID != nil {
+			query += fmt.Sprintf(" AND user_id = $%d", argPos)
+			args = append(args, *filters.UserID)
+			argPos++
+		}
+		if filters.RoleID != nil {
+			query += fmt.Sprintf(" AND role_id = $%d", argPos)
+			args = append(args, *filters.RoleID)
+		}
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list elevation requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*models.ElevationRequest
+	for rows.Next() {
+		req, err := scanElevationRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan elevation request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating elevation requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// scanElevationRequest scans a row selected with elevationRequestColumns
+func scanElevationRequest(row rowScanner) (*models.ElevationRequest, error) {
+	req := &models.ElevationRequest{}
+	var decidedBy uuid.NullUUID
+	var decidedAt, expiresAt sql.NullTime
+	var decisionComment sql.NullString
+
+	err := row.Scan(
+		&req.ID, &req.TenantID, &req.UserID, &req.RoleID, &req.Reason,
+		&req.DurationSeconds, &req.Status, &decidedBy, &decidedAt,
+		&decisionComment, &expiresAt, &req.CreatedAt, &req.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if decidedBy.Valid {
+		req.DecidedBy = &decidedBy.UUID
+	}
+	if decidedAt.Valid {
+		req.DecidedAt = &decidedAt.Time
+	}
+	if decisionComment.Valid {
+		req.DecisionComment = &decisionComment.String
+	}
+	if expiresAt.Valid {
+		req.ExpiresAt = &expiresAt.Time
+	}
+
+	return req, nil
+}
@@ -50,7 +50,7 @@ func (r *identityProviderRepository) Create(ctx context.Context, provider *feder
 	provider.CreatedAt = now
 	provider.UpdatedAt = now
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = querier(ctx, r.db).ExecContext(ctx, query,
 		provider.ID,
 		provider.TenantID,
 		provider.Name,
@@ -78,7 +78,7 @@ func (r *identityProviderRepository) GetByID(ctx context.Context, id uuid.UUID)
 	var configJSON, attrMappingJSON []byte
 
 	var deletedAt sql.NullTime
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&provider.ID,
 		&provider.TenantID,
 		&provider.Name,
@@ -126,7 +126,7 @@ func (r *identityProviderRepository) GetByTenantID(ctx context.Context, tenantID
 	`
 
 	var providers []*federation.IdentityProvider
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +187,7 @@ func (r *identityProviderRepository) GetByName(ctx context.Context, tenantID uui
 	var configJSON, attrMappingJSON []byte
 	var deletedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, name).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, name).Scan(
 		&provider.ID,
 		&provider.TenantID,
 		&provider.Name,
@@ -248,7 +248,7 @@ func (r *identityProviderRepository) Update(ctx context.Context, provider *feder
 
 	provider.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
 		provider.ID,
 		provider.Name,
 		provider.Type,
@@ -282,7 +282,7 @@ func (r *identityProviderRepository) Delete(ctx context.Context, id uuid.UUID) e
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -333,7 +333,7 @@ func (r *federatedIdentityRepository) Create(ctx context.Context, identity *fede
 	identity.CreatedAt = now
 	identity.UpdatedAt = now
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		identity.ID,
 		identity.UserID,
 		identity.ProviderID,
@@ -362,7 +362,7 @@ func (r *federatedIdentityRepository) GetByID(ctx context.Context, id uuid.UUID)
 	var attributesJSON []byte
 	var verifiedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&identity.ID,
 		&identity.UserID,
 		&identity.ProviderID,
@@ -406,7 +406,7 @@ func (r *federatedIdentityRepository) GetByUserID(ctx context.Context, userID uu
 	`
 
 	var identities []*federation.FederatedIdentity
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -463,7 +463,7 @@ func (r *federatedIdentityRepository) GetByProviderAndExternalID(ctx context.Con
 	var attributesJSON []byte
 	var verifiedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, providerID, externalID).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, providerID, externalID).Scan(
 		&identity.ID,
 		&identity.UserID,
 		&identity.ProviderID,
@@ -507,7 +507,7 @@ func (r *federatedIdentityRepository) GetByProviderID(ctx context.Context, provi
 	`
 
 	var identities []*federation.FederatedIdentity
-	rows, err := r.db.QueryContext(ctx, query, providerID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, providerID)
 	if err != nil {
 		return nil, err
 	}
@@ -571,7 +571,7 @@ func (r *federatedIdentityRepository) Update(ctx context.Context, identity *fede
 
 	identity.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
 		identity.ID,
 		identity.ExternalID,
 		attributesJSON,
@@ -601,7 +601,7 @@ func (r *federatedIdentityRepository) Update(ctx context.Context, identity *fede
 func (r *federatedIdentityRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM federated_identities WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -620,7 +620,7 @@ func (r *federatedIdentityRepository) Delete(ctx context.Context, id uuid.UUID)
 
 // SetPrimary sets a federated identity as primary (and unsets others for the user)
 func (r *federatedIdentityRepository) SetPrimary(ctx context.Context, userID uuid.UUID, identityID uuid.UUID) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/storage/interfaces"
+)
+
+// columnKind says how values of a filter column are compared
+type columnKind int
+
+const (
+	columnText       columnKind = iota // Case-sensitive text
+	columnTextFold                     // Case-insensitive text
+	columnTime                         // Timestamp; values are RFC 3339 strings
+	columnMembership                   // EXISTS subquery whose %[1]s placeholders take the value; eq and ne only
+)
+
+// filterColumn maps a logical filter field onto SQL
+type filterColumn struct {
+	expr     string
+	kind     columnKind
+	sortable bool
+}
+
+// filterBuilder translates a FilterExpr into a SQL condition, appending its
+// arguments after those already bound
+type filterBuilder struct {
+	columns map[string]filterColumn
+	args    []interface{}
+}
+
+// buildFilterClause returns " AND (<condition>)" for expr, or "" when expr is nil,
+// and the arguments with the condition's own appended
+func buildFilterClause(expr *interfaces.FilterExpr, columns map[string]filterColumn, args []interface{}) (string, []interface{}, error) {
+	if expr == nil {
+		return "", args, nil
+	}
+	b := &filterBuilder{columns: columns, args: args}
+	condition, err := b.build(expr)
+	if err != nil {
+		return "", nil, err
+	}
+	return " AND (" + condition + ")", b.args, nil
+}
+
+// buildOrderBy returns the ORDER BY column for a logical sort field, or fallback when none is given
+func buildOrderBy(sortBy string, descending bool, columns map[string]filterColumn, fallback string) (string, error) {
+	if sortBy == "" {
+		return fallback, nil
+	}
+	col, ok := columns[sortBy]
+	if !ok || !col.sortable {
+		return "", fmt.Errorf("cannot sort by %s", sortBy)
+	}
+	direction := "ASC"
+	if descending {
+		direction = "DESC"
+	}
+	return col.expr + " " + direction + " NULLS LAST", nil
+}
+
+func (b *filterBuilder) bind(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *filterBuilder) build(expr *interfaces.FilterExpr) (string, error) {
+	switch expr.Op {
+	case interfaces.FilterTrue:
+		return "TRUE", nil
+	case interfaces.FilterFalse:
+		return "FALSE", nil
+	case interfaces.FilterAnd, interfaces.FilterOr:
+		if len(expr.Children) == 0 {
+			return "", fmt.Errorf("%s needs operands", expr.Op)
+		}
+		parts := make([]string, 0, len(expr.Children))
+		for _, child := range expr.Children {
+			part, err := b.build(child)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, "("+part+")")
+		}
+		return strings.Join(parts, " "+strings.ToUpper(string(expr.Op))+" "), nil
+	case interfaces.FilterNot:
+		if len(expr.Children) != 1 {
+			return "", fmt.Errorf("not needs one operand")
+		}
+		part, err := b.build(expr.Children[0])
+		if err != nil {
+			return "", err
+		}
+		// A NULL column makes the inner condition NULL; treat that as not matching
+		return "NOT COALESCE((" + part + "), FALSE)", nil
+	}
+
+	col, ok := b.columns[expr.Field]
+	if !ok {
+		return "", fmt.Errorf("unsupported filter field %s", expr.Field)
+	}
+	if expr.Op == interfaces.FilterPresent {
+		if col.kind == columnMembership {
+			return "", fmt.Errorf("operator pr is not supported for %s", expr.Field)
+		}
+		if col.kind == columnTime {
+			return col.expr + " IS NOT NULL", nil
+		}
+		return "(" + col.expr + " IS NOT NULL AND " + col.expr + " <> '')", nil
+	}
+
+	// eq null and ne null are absence and presence
+	if expr.Value == nil {
+		present, err := b.build(&interfaces.FilterExpr{Op: interfaces.FilterPresent, Field: expr.Field})
+		if err != nil {
+			return "", err
+		}
+		switch expr.Op {
+		case interfaces.FilterEqual:
+			return "NOT (" + present + ")", nil
+		case interfaces.FilterNotEqual:
+			return present, nil
+		}
+		return "", fmt.Errorf("operator %s needs a value", expr.Op)
+	}
+
+	switch col.kind {
+	case columnMembership:
+		return b.membership(col, expr)
+	case columnTime:
+		return b.timeComparison(col, expr)
+	default:
+		return b.textComparison(col, expr)
+	}
+}
+
+func (b *filterBuilder) textComparison(col filterColumn, expr *interfaces.FilterExpr) (string, error) {
+	value, ok := expr.Value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be compared with a string", expr.Field)
+	}
+
+	column, like := col.expr, "LIKE"
+	if col.kind == columnTextFold {
+		like = "ILIKE"
+	}
+
+	var pattern string
+	switch expr.Op {
+	case interfaces.FilterContains:
+		pattern = "%" + escapeLike(value) + "%"
+	case interfaces.FilterStartsWith:
+		pattern = escapeLike(value) + "%"
+	case interfaces.FilterEndsWith:
+		pattern = "%" + escapeLike(value)
+	}
+	if pattern != "" {
+		return column + " " + like + " " + b.bind(pattern), nil
+	}
+
+	operator, err := sqlComparison(expr.Op)
+	if err != nil {
+		return "", err
+	}
+	placeholder := b.bind(value)
+	if col.kind == columnTextFold {
+		column, placeholder = "LOWER("+column+")", "LOWER("+placeholder+")"
+	}
+	if expr.Op == interfaces.FilterNotEqual {
+		return "(" + col.expr + " IS NULL OR " + column + " <> " + placeholder + ")", nil
+	}
+	return column + " " + operator + " " + placeholder, nil
+}
+
+func (b *filterBuilder) timeComparison(col filterColumn, expr *interfaces.FilterExpr) (string, error) {
+	raw, ok := expr.Value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be compared with a date-time string", expr.Field)
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return "", fmt.Errorf("%s must be compared with an RFC 3339 date-time", expr.Field)
+	}
+	operator, err := sqlComparison(expr.Op)
+	if err != nil {
+		return "", fmt.Errorf("operator %s is not supported for %s", expr.Op, expr.Field)
+	}
+	return col.expr + " " + operator + " " + b.bind(value), nil
+}
+
+func (b *filterBuilder) membership(col filterColumn, expr *interfaces.FilterExpr) (string, error) {
+	value, ok := expr.Value.(string)
+	if !ok || (expr.Op != interfaces.FilterEqual && expr.Op != interfaces.FilterNotEqual) {
+		return "", fmt.Errorf("%s only supports eq and ne with a string", expr.Field)
+	}
+	condition := fmt.Sprintf(col.expr, b.bind(value))
+	if expr.Op == interfaces.FilterNotEqual {
+		return "NOT " + condition, nil
+	}
+	return condition, nil
+}
+
+// sqlComparison returns the SQL operator for an equality or ordering filter operator
+func sqlComparison(op interfaces.FilterOp) (string, error) {
+	switch op {
+	case interfaces.FilterEqual:
+		return "=", nil
+	case interfaces.FilterNotEqual:
+		return "<>", nil
+	case interfaces.FilterGreater:
+		return ">", nil
+	case interfaces.FilterGreaterOrEqual:
+		return ">=", nil
+	case interfaces.FilterLess:
+		return "<", nil
+	case interfaces.FilterLessOrEqual:
+		return "<=", nil
+	}
+	return "", fmt.Errorf("unsupported filter operator %s", op)
+}
+
+// escapeLike escapes LIKE wildcards so a value matches literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFilterClause(t *testing.T) {
+	expr := &interfaces.FilterExpr{Op: interfaces.FilterAnd, Children: []*interfaces.FilterExpr{
+		{Op: interfaces.FilterContains, Field: interfaces.UserFieldEmail, Value: "50%_off"},
+		{Op: interfaces.FilterNot, Children: []*interfaces.FilterExpr{
+			{Op: interfaces.FilterEqual, Field: interfaces.UserFieldUsername, Value: "Bob"},
+		}},
+		{Op: interfaces.FilterGreater, Field: interfaces.UserFieldCreatedAt, Value: "2024-01-01T00:00:00Z"},
+	}}
+
+	clause, args, err := buildFilterClause(expr, userFilterColumns, []interface{}{"tenant"})
+	require.NoError(t, err)
+	assert.Equal(t, ` AND ((email ILIKE $2) AND (NOT COALESCE((LOWER(username) = LOWER($3)), FALSE)) AND (created_at > $4))`, clause)
+	assert.Equal(t, []interface{}{"tenant", `%50\%\_off%`, "Bob", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, args)
+}
+
+func TestBuildFilterClause_PresenceAndMembership(t *testing.T) {
+	clause, args, err := buildFilterClause(&interfaces.FilterExpr{Op: interfaces.FilterOr, Children: []*interfaces.FilterExpr{
+		{Op: interfaces.FilterEqual, Field: interfaces.GroupFieldExternalID, Value: nil},
+		{Op: interfaces.FilterNotEqual, Field: interfaces.GroupFieldMember, Value: "u1"},
+	}}, groupFilterColumns, []interface{}{"tenant"})
+	require.NoError(t, err)
+	assert.Contains(t, clause, "NOT ((g.external_id IS NOT NULL AND g.external_id <> ''))")
+	assert.Contains(t, clause, "NOT (EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id::text = $2)")
+	assert.Contains(t, clause, "gmg.member_group_id::text = $2))")
+	assert.Len(t, args, 2)
+}
+
+func TestBuildFilterClause_Errors(t *testing.T) {
+	for _, expr := range []*interfaces.FilterExpr{
+		{Op: interfaces.FilterEqual, Field: "password_hash", Value: "x"},
+		{Op: interfaces.FilterEqual, Field: interfaces.UserFieldUsername, Value: 5.0},
+		{Op: interfaces.FilterContains, Field: interfaces.UserFieldCreatedAt, Value: "2024-01-01T00:00:00Z"},
+		{Op: interfaces.FilterGreater, Field: interfaces.UserFieldCreatedAt, Value: "yesterday"},
+		{Op: interfaces.FilterAnd},
+	} {
+		_, _, err := buildFilterClause(expr, userFilterColumns, nil)
+		assert.Error(t, err, "%+v", expr)
+	}
+}
+
+func TestBuildOrderBy(t *testing.T) {
+	orderBy, err := buildOrderBy("", false, userFilterColumns, "created_at DESC")
+	require.NoError(t, err)
+	assert.Equal(t, "created_at DESC", orderBy)
+
+	orderBy, err = buildOrderBy(interfaces.UserFieldLastName, true, userFilterColumns, "")
+	require.NoError(t, err)
+	assert.Equal(t, "last_name DESC NULLS LAST", orderBy)
+
+	_, err = buildOrderBy(interfaces.UserFieldStatus, false, userFilterColumns, "")
+	assert.Error(t, err)
+	_, err = buildOrderBy(interfaces.GroupFieldMember, false, groupFilterColumns, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,501 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// groupRepository implements GroupRepository for PostgreSQL
+type groupRepository struct {
+	db *sql.DB
+}
+
+// NewGroupRepository creates a new PostgreSQL group repository
+func NewGroupRepository(db *sql.DB) interfaces.GroupRepository {
+	return &groupRepository{db: db}
+}
+
+const groupColumns = `g.id, g.tenant_id, g.name, g.description, g.external_id, g.created_at, g.updated_at, g.deleted_at`
+
+// Create creates a new group
+func (r *groupRepository) Create(ctx context.Context, group *models.Group) error {
+	query := `
+		INSERT INTO groups (id, tenant_id, name, description, external_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	now := time.Now()
+	if group.ID == uuid.Nil {
+		group.ID = uuid.New()
+	}
+	if group.CreatedAt.IsZero() {
+		group.CreatedAt = now
+	}
+	if group.UpdatedAt.IsZero() {
+		group.UpdatedAt = now
+	}
+
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		group.ID, group.TenantID, group.Name, group.Description, group.ExternalID,
+		group.CreatedAt, group.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a group by ID
+func (r *groupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM groups g WHERE g.id = $1 AND g.deleted_at IS NULL`
+	return r.getOne(ctx, query, id)
+}
+
+// LockByID locks a group row until the context's transaction ends. It takes
+// the lock an UPDATE takes, so rows referencing the group can still be inserted.
+func (r *groupRepository) LockByID(ctx context.Context, id uuid.UUID) error {
+	query := `SELECT id FROM groups WHERE id = $1 AND deleted_at IS NULL FOR NO KEY UPDATE`
+
+	var locked uuid.UUID
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("group not found: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock group: %w", err)
+	}
+	return nil
+}
+
+// GetByName retrieves a group by name and tenant ID
+func (r *groupRepository) GetByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM groups g WHERE g.tenant_id = $1 AND g.name = $2 AND g.deleted_at IS NULL`
+	return r.getOne(ctx, query, tenantID, name)
+}
+
+// GetByExternalID retrieves a group by the identifier assigned by an external IdP
+func (r *groupRepository) GetByExternalID(ctx context.Context, tenantID uuid.UUID, externalID string) (*models.Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM groups g WHERE g.tenant_id = $1 AND g.external_id = $2 AND g.deleted_at IS NULL`
+	return r.getOne(ctx, query, tenantID, externalID)
+}
+
+// Update updates an existing group
+func (r *groupRepository) Update(ctx context.Context, group *models.Group) error {
+	query := `
+		UPDATE groups
+		SET name = $2, description = $3, external_id = $4, updated_at = $5
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	group.UpdatedAt = time.Now()
+
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		group.ID, group.Name, group.Description, group.ExternalID, group.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a group and removes its memberships and role grants
+func (r *groupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE groups
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("group not found")
+	}
+
+	// Soft-deleted groups keep their row, so clear relationships explicitly
+	cleanup := []string{
+		`DELETE FROM group_members WHERE group_id = $1`,
+		`DELETE FROM group_member_groups WHERE group_id = $1 OR member_group_id = $1`,
+		`DELETE FROM group_roles WHERE group_id = $1`,
+	}
+	for _, stmt := range cleanup {
+		if _, err := tx.ExecContext(ctx, stmt, id); err != nil {
+			return fmt.Errorf("failed to remove group relationships: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit group deletion: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves a list of groups with filters
+func (r *groupRepository) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) ([]*models.Group, error) {
+	if filters == nil {
+		filters = &interfaces.GroupFilters{
+			Page:     1,
+			PageSize: 20,
+		}
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 || filters.PageSize > 100 {
+		filters.PageSize = 20
+	}
+
+	offset := (filters.Page - 1) * filters.PageSize
+	if filters.Offset != nil {
+		offset = *filters.Offset
+	}
+
+	where, args, err := groupFilterClause(tenantID, filters)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err := buildOrderBy(filters.SortBy, filters.SortDescending, groupFilterColumns, "g.name")
+	if err != nil {
+		return nil, fmt.Errorf("invalid group sort: %w", err)
+	}
+
+	argPos := len(args) + 1
+	query := `SELECT ` + groupColumns + ` FROM groups g` + where +
+		fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, argPos, argPos+1)
+	args = append(args, filters.PageSize, offset)
+
+	return r.queryGroups(ctx, query, args...)
+}
+
+// Count returns the number of groups matching the filters
+func (r *groupRepository) Count(ctx context.Context, tenantID uuid.UUID, filters *interfaces.GroupFilters) (int, error) {
+	where, args, err := groupFilterClause(tenantID, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := querier(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM groups g`+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count groups: %w", err)
+	}
+
+	return count, nil
+}
+
+// groupFilterColumns maps the logical group filter fields onto columns.
+// A member matches direct user members and direct member groups.
+var groupFilterColumns = map[string]filterColumn{
+	interfaces.GroupFieldID:         {expr: "g.id::text", kind: columnText, sortable: true},
+	interfaces.GroupFieldName:       {expr: "g.name", kind: columnTextFold, sortable: true},
+	interfaces.GroupFieldExternalID: {expr: "g.external_id", kind: columnText, sortable: true},
+	interfaces.GroupFieldMember: {
+		expr: `(EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id::text = %[1]s)` +
+			` OR EXISTS (SELECT 1 FROM group_member_groups gmg WHERE gmg.group_id = g.id AND gmg.member_group_id::text = %[1]s))`,
+		kind: columnMembership,
+	},
+	interfaces.GroupFieldCreatedAt: {expr: "g.created_at", kind: columnTime, sortable: true},
+	interfaces.GroupFieldUpdatedAt: {expr: "g.updated_at", kind: columnTime, sortable: true},
+}
+
+// groupFilterClause builds the WHERE clause shared by List and Count
+func groupFilterClause(tenantID uuid.UUID, filters *interfaces.GroupFilters) (string, []interface{}, error) {
+	where := ` WHERE g.tenant_id = $1 AND g.deleted_at IS NULL`
+	args := []interface{}{tenantID}
+	if filters == nil {
+		return where, args, nil
+	}
+
+	if filters.Search != nil {
+		where += ` AND (g.name ILIKE $2 OR g.description ILIKE $2)`
+		args = append(args, "%"+*filters.Search+"%")
+	}
+
+	filterClause, args, err := buildFilterClause(filters.Filter, groupFilterColumns, args)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid group filter: %w", err)
+	}
+
+	return where + filterClause, args, nil
+}
+
+// AddMember adds a user to a group
+func (r *groupRepository) AddMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	query := `
+		INSERT INTO group_members (id, group_id, user_id, created_at)
+		VALUES (gen_random_uuid(), $1, $2, NOW())
+		ON CONFLICT (group_id, user_id) DO NOTHING
+	`
+
+	if _, err := querier(ctx, r.db).ExecContext(ctx, query, groupID, userID); err != nil {
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes a user from a group
+func (r *groupRepository) RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	return r.deleteLink(ctx, `DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`, groupID, userID, "group member")
+}
+
+// ListMembers retrieves the users directly in a group
+func (r *groupRepository) ListMembers(ctx context.Context, groupID uuid.UUID) ([]*models.GroupMember, error) {
+	query := `
+		SELECT gm.group_id, u.id, u.username, u.email, gm.created_at
+		FROM group_members gm
+		INNER JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.username
+	`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*models.GroupMember
+	for rows.Next() {
+		m := &models.GroupMember{}
+		if err := rows.Scan(&m.GroupID, &m.UserID, &m.Username, &m.Email, &m.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating group members: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetUserGroups retrieves the groups a user is directly a member of
+func (r *groupRepository) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]*models.Group, error) {
+	query := `
+		SELECT ` + groupColumns + `
+		FROM groups g
+		INNER JOIN group_members gm ON gm.group_id = g.id
+		WHERE gm.user_id = $1 AND g.deleted_at IS NULL
+		ORDER BY g.name
+	`
+	return r.queryGroups(ctx, query, userID)
+}
+
+// AddMemberGroup nests a group inside another group
+func (r *groupRepository) AddMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error {
+	query := `
+		INSERT INTO group_member_groups (id, group_id, member_group_id, created_at)
+		VALUES (gen_random_uuid(), $1, $2, NOW())
+		ON CONFLICT (group_id, member_group_id) DO NOTHING
+	`
+
+	if _, err := querier(ctx, r.db).ExecContext(ctx, query, groupID, memberGroupID); err != nil {
+		return fmt.Errorf("failed to add member group: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMemberGroup removes a nested group from a group
+func (r *groupRepository) RemoveMemberGroup(ctx context.Context, groupID, memberGroupID uuid.UUID) error {
+	return r.deleteLink(ctx, `DELETE FROM group_member_groups WHERE group_id = $1 AND member_group_id = $2`, groupID, memberGroupID, "member group")
+}
+
+// ListMemberGroups retrieves the groups directly nested in a group
+func (r *groupRepository) ListMemberGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	query := `
+		SELECT ` + groupColumns + `
+		FROM groups g
+		INNER JOIN group_member_groups gmg ON gmg.member_group_id = g.id
+		WHERE gmg.group_id = $1 AND g.deleted_at IS NULL
+		ORDER BY g.name
+	`
+	return r.queryGroups(ctx, query, groupID)
+}
+
+// GetParentGroups retrieves the groups a group is directly nested in
+func (r *groupRepository) GetParentGroups(ctx context.Context, groupID uuid.UUID) ([]*models.Group, error) {
+	query := `
+		SELECT ` + groupColumns + `
+		FROM groups g
+		INNER JOIN group_member_groups gmg ON gmg.group_id = g.id
+		WHERE gmg.member_group_id = $1 AND g.deleted_at IS NULL
+		ORDER BY g.name
+	`
+	return r.queryGroups(ctx, query, groupID)
+}
+
+// AssignRole grants a role to a group
+func (r *groupRepository) AssignRole(ctx context.Context, groupID, roleID uuid.UUID) error {
+	query := `
+		INSERT INTO group_roles (id, group_id, role_id, created_at)
+		VALUES (gen_random_uuid(), $1, $2, NOW())
+		ON CONFLICT (group_id, role_id) DO NOTHING
+	`
+
+	if _, err := querier(ctx, r.db).ExecContext(ctx, query, groupID, roleID); err != nil {
+		return fmt.Errorf("failed to assign role to group: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveRole revokes a role from a group
+func (r *groupRepository) RemoveRole(ctx context.Context, groupID, roleID uuid.UUID) error {
+	return r.deleteLink(ctx, `DELETE FROM group_roles WHERE group_id = $1 AND role_id = $2`, groupID, roleID, "group role assignment")
+}
+
+// GetGroupRoles retrieves the roles granted directly to a group
+func (r *groupRepository) GetGroupRoles(ctx context.Context, groupID uuid.UUID) ([]*models.Role, error) {
+	query := `
+		SELECT r.id, r.tenant_id, r.name, r.description, r.is_system, r.created_at, r.updated_at, r.deleted_at, r.owner_id
+		FROM roles r
+		INNER JOIN group_roles gr ON gr.role_id = r.id
+		WHERE gr.group_id = $1 AND r.deleted_at IS NULL
+		ORDER BY r.name
+	`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		role := &models.Role{}
+		var description sql.NullString
+		var deletedAt sql.NullTime
+		var ownerID uuid.NullUUID
+
+		err := rows.Scan(
+			&role.ID, &role.TenantID, &role.Name, &description,
+			&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt, &ownerID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+
+		if description.Valid {
+			role.Description = &description.String
+		}
+		if deletedAt.Valid {
+			role.DeletedAt = &deletedAt.Time
+		}
+		if ownerID.Valid {
+			role.OwnerID = &ownerID.UUID
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// getOne runs a query expected to return a single group row
+func (r *groupRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Group, error) {
+	group, err := scanGroup(querier(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	return group, nil
+}
+
+// queryGroups runs a query returning group rows
+func (r *groupRepository) queryGroups(ctx context.Context, query string, args ...interface{}) ([]*models.Group, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*models.Group
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// deleteLink removes a relationship row, reporting an error when it did not exist
+func (r *groupRepository) deleteLink(ctx context.Context, query string, a, b uuid.UUID, what string) error {
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, a, b)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s: %w", what, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s not found", what)
+	}
+
+	return nil
+}
+
+// scanGroup scans a row selected with groupColumns
+func scanGroup(row rowScanner) (*models.Group, error) {
+	group := &models.Group{}
+	var description, externalID sql.NullString
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&group.ID, &group.TenantID, &group.Name, &description, &externalID,
+		&group.CreatedAt, &group.UpdatedAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		group.Description = &description.String
+	}
+	if externalID.Valid {
+		group.ExternalID = &externalID.String
+	}
+	if deletedAt.Valid {
+		group.DeletedAt = &deletedAt.Time
+	}
+
+	return group, nil
+}
@@ -55,7 +55,7 @@ func (r *ImpersonationRepository) Create(ctx context.Context, session *models.Im
 		tokenJTIStr = session.TokenJTI.String()
 	}
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = querier(ctx, r.db).ExecContext(ctx, query,
 		session.ID,
 		session.ImpersonatorID,
 		session.TargetUserID,
@@ -90,7 +90,7 @@ func (r *ImpersonationRepository) GetByID(ctx context.Context, id uuid.UUID) (*m
 	var endedAt sql.NullTime
 	var tokenJTIStr sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&session.ID,
 		&session.ImpersonatorID,
 		&session.TargetUserID,
@@ -145,7 +145,7 @@ func (r *ImpersonationRepository) GetByTokenJTI(ctx context.Context, tokenJTI uu
 	var endedAt sql.NullTime
 	var tokenJTIStr sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, tokenJTI.String()).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tokenJTI.String()).Scan(
 		&session.ID,
 		&session.ImpersonatorID,
 		&session.TargetUserID,
@@ -196,7 +196,7 @@ func (r *ImpersonationRepository) GetActiveByImpersonator(ctx context.Context, i
 		ORDER BY started_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, impersonatorID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, impersonatorID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query impersonation sessions: %w", err)
 	}
@@ -260,7 +260,7 @@ func (r *ImpersonationRepository) GetActiveByTarget(ctx context.Context, targetU
 		ORDER BY started_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, targetUserID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, targetUserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query impersonation sessions: %w", err)
 	}
@@ -321,7 +321,7 @@ func (r *ImpersonationRepository) EndSession(ctx context.Context, id uuid.UUID)
 		WHERE id = $1 AND ended_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to end impersonation session: %w", err)
 	}
@@ -382,7 +382,7 @@ func (r *ImpersonationRepository) List(ctx context.Context, filters *interfaces.
 		args = append(args, filters.PageSize, offset)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query impersonation sessions: %w", err)
 	}
@@ -56,7 +56,7 @@ func (r *InvitationRepository) Create(ctx context.Context, invitation *models.Us
 		metadataJSON = []byte("{}") // TODO: Use proper JSON marshalling
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		invitation.ID,
 		invitation.TenantID,
 		invitation.Email,
@@ -91,7 +91,7 @@ func (r *InvitationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mode
 	var acceptedBy sql.NullString
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&invitation.ID,
 		&invitation.TenantID,
 		&invitation.Email,
@@ -156,7 +156,7 @@ func (r *InvitationRepository) GetByTokenHash(ctx context.Context, tokenHash str
 	var acceptedBy sql.NullString
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
 		&invitation.ID,
 		&invitation.TenantID,
 		&invitation.Email,
@@ -221,7 +221,7 @@ func (r *InvitationRepository) GetByEmail(ctx context.Context, tenantID uuid.UUI
 	var acceptedBy sql.NullString
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, email).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, email).Scan(
 		&invitation.ID,
 		&invitation.TenantID,
 		&invitation.Email,
@@ -313,7 +313,7 @@ func (r *InvitationRepository) List(ctx context.Context, tenantID uuid.UUID, fil
 		args = append(args, filters.PageSize, offset)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query invitations: %w", err)
 	}
@@ -406,7 +406,7 @@ func (r *InvitationRepository) Count(ctx context.Context, tenantID uuid.UUID, fi
 	}
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count invitations: %w", err)
 	}
@@ -449,7 +449,7 @@ func (r *InvitationRepository) Update(ctx context.Context, invitation *models.Us
 		acceptedBy = *invitation.AcceptedBy
 	}
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
 		invitation.Email,
 		invitation.ExpiresAt,
 		acceptedAt,
@@ -484,7 +484,7 @@ func (r *InvitationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete invitation: %w", err)
 	}
@@ -501,3 +501,24 @@ func (r *InvitationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+
+// DeleteExpired soft-deletes unaccepted invitations that expired before the given time
+func (r *InvitationRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		UPDATE user_invitations
+		SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE expires_at < $1 AND accepted_at IS NULL AND deleted_at IS NULL
+	`
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired invitations: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// jobRepository implements JobRepository for PostgreSQL
+type jobRepository struct {
+	db *sql.DB
+}
+
+// NewJobRepository creates a new PostgreSQL scheduled job repository
+func NewJobRepository(db *sql.DB) interfaces.JobRepository {
+	return &jobRepository{db: db}
+}
+
+const scheduledJobColumns = `name, schedule, timeout_seconds, enabled, next_run_at, locked_by, locked_until,
+	last_started_at, last_finished_at, last_status, last_error, last_duration_ms, last_success_at,
+	consecutive_failures, total_runs, total_failures, created_at, updated_at`
+
+const jobRunColumns = `id, job_name, runner, status, processed, error, started_at, finished_at, duration_ms`
+
+// Register creates a job or updates its schedule and timeout. Every replica
+// registers its jobs at startup, so a changed schedule takes effect on the
+// first replica running the new version.
+func (r *jobRepository) Register(ctx context.Context, job *models.ScheduledJob) error {
+	query := `
+		INSERT INTO scheduled_jobs (name, schedule, timeout_seconds, enabled, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, TRUE, $4, NOW(), NOW())
+		ON CONFLICT (name) DO UPDATE SET
+			next_run_at = CASE WHEN scheduled_jobs.schedule = EXCLUDED.schedule
+				THEN scheduled_jobs.next_run_at ELSE EXCLUDED.next_run_at END,
+			schedule = EXCLUDED.schedule,
+			timeout_seconds = EXCLUDED.timeout_seconds,
+			updated_at = NOW()
+		RETURNING ` + scheduledJobColumns
+
+	registered, err := scanScheduledJob(querier(ctx, r.db).QueryRowContext(ctx, query,
+		job.Name, job.Schedule, job.TimeoutSeconds, job.NextRunAt,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to register scheduled job: %w", err)
+	}
+	*job = *registered
+
+	return nil
+}
+
+// ClaimDue leases the most overdue job among names. The lease is computed
+// from the database clock so that replicas with skewed clocks agree on it.
+func (r *jobRepository) ClaimDue(ctx context.Context, names []string, runner string, grace time.Duration) (*models.ScheduledJob, error) {
+	query := `
+		UPDATE scheduled_jobs
+		SET locked_by = $2,
+			locked_until = NOW() + make_interval(secs => timeout_seconds + $3::float8),
+			updated_at = NOW()
+		WHERE name = (
+			SELECT name FROM scheduled_jobs
+			WHERE enabled AND next_run_at <= NOW() AND name = ANY($1)
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY next_run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduledJobColumns
+
+	job, err := scanScheduledJob(querier(ctx, r.db).QueryRowContext(ctx, query, pq.Array(names), runner, grace.Seconds()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim scheduled job: %w", err)
+	}
+
+	return job, nil
+}
+
+// Complete records a run and, if the runner still holds the lease, updates
+// the job's status and releases it. A runner whose lease expired mid-run
+// only adds the run to the history.
+func (r *jobRepository) Complete(ctx context.Context, run *models.JobRun, nextRunAt time.Time) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO job_runs (`+jobRunColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, run.ID, run.JobName, run.Runner, run.Status, run.Processed, run.Error, run.StartedAt, run.FinishedAt, run.DurationMs)
+	if err != nil {
+		return fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	succeeded := run.Status == models.JobRunSucceeded
+	_, err = tx.ExecContext(ctx, `
+		UPDATE scheduled_jobs
+		SET locked_by = NULL, locked_until = NULL, next_run_at = $3,
+			last_started_at = $4, last_finished_at = $5, last_status = $6, last_error = $7, last_duration_ms = $8,
+			last_success_at = CASE WHEN $9 THEN $5 ELSE last_success_at END,
+			consecutive_failures = CASE WHEN $9 THEN 0 ELSE consecutive_failures + 1 END,
+			total_runs = total_runs + 1,
+			total_failures = total_failures + CASE WHEN $9 THEN 0 ELSE 1 END,
+			updated_at = NOW()
+		WHERE name = $1 AND locked_by = $2
+	`, run.JobName, run.Runner, nextRunAt, run.StartedAt, run.FinishedAt, run.Status, run.Error, run.DurationMs, succeeded)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves all jobs ordered by name
+func (r *jobRepository) List(ctx context.Context) ([]*models.ScheduledJob, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, `SELECT `+scheduledJobColumns+` FROM scheduled_jobs ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.ScheduledJob
+	for rows.Next() {
+		job, err := scanScheduledJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// GetByName retrieves a job by name
+func (r *jobRepository) GetByName(ctx context.Context, name string) (*models.ScheduledJob, error) {
+	job, err := scanScheduledJob(querier(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+scheduledJobColumns+` FROM scheduled_jobs WHERE name = $1`, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("scheduled job not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListRuns retrieves a job's most recent runs, newest first
+func (r *jobRepository) ListRuns(ctx context.Context, name string, limit int) ([]*models.JobRun, error) {
+	query := `SELECT ` + jobRunColumns + ` FROM job_runs WHERE job_name = $1 ORDER BY started_at DESC LIMIT $2`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*models.JobRun
+	for rows.Next() {
+		run := &models.JobRun{}
+		err := rows.Scan(&run.ID, &run.JobName, &run.Runner, &run.Status, &run.Processed, &run.Error,
+			&run.StartedAt, &run.FinishedAt, &run.DurationMs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// TriggerNow makes a job due immediately, unless a replica is running it
+func (r *jobRepository) TriggerNow(ctx context.Context, name string) error {
+	result, err := querier(ctx, r.db).ExecContext(ctx, `
+		UPDATE scheduled_jobs SET next_run_at = NOW(), updated_at = NOW()
+		WHERE name = $1 AND (locked_until IS NULL OR locked_until < NOW())
+	`, name)
+	if err != nil {
+		return fmt.Errorf("failed to trigger scheduled job: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if count == 0 {
+		if _, err := r.GetByName(ctx, name); err != nil {
+			return err
+		}
+		return fmt.Errorf("scheduled job is already running")
+	}
+
+	return nil
+}
+
+// DeleteRunsBefore removes runs started before the given time
+func (r *jobRepository) DeleteRunsBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := querier(ctx, r.db).ExecContext(ctx, `DELETE FROM job_runs WHERE started_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete job runs: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(count), nil
+}
+
+// scanScheduledJob scans a row selected with scheduledJobColumns
+func scanScheduledJob(row rowScanner) (*models.ScheduledJob, error) {
+	job := &models.ScheduledJob{}
+	err := row.Scan(
+		&job.Name, &job.Schedule, &job.TimeoutSeconds, &job.Enabled, &job.NextRunAt, &job.LockedBy, &job.LockedUntil,
+		&job.LastStartedAt, &job.LastFinishedAt, &job.LastStatus, &job.LastError, &job.LastDurationMs, &job.LastSuccessAt,
+		&job.ConsecutiveFailures, &job.TotalRuns, &job.TotalFailures, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// mfaFactorRepository implements MFAFactorRepository for PostgreSQL
+type mfaFactorRepository struct {
+	db *sql.DB
+}
+
+// NewMFAFactorRepository creates a new PostgreSQL MFA factor repository
+func NewMFAFactorRepository(db *sql.DB) interfaces.MFAFactorRepository {
+	return &mfaFactorRepository{db: db}
+}
+
+const mfaFactorColumns = `id, user_id, type, name, secret_encrypted, algorithm, digits, period,
+		       verified, verified_at, last_used_at, created_at, updated_at`
+
+// scanMFAFactor scans a row selected with mfaFactorColumns
+func scanMFAFactor(row rowScanner) (*interfaces.MFAFactor, error) {
+	var f interfaces.MFAFactor
+	var verifiedAt, lastUsedAt sql.NullTime
+
+	if err := row.Scan(
+		&f.ID, &f.UserID, &f.Type, &f.Name, &f.SecretEncrypted, &f.Algorithm, &f.Digits, &f.Period,
+		&f.Verified, &verifiedAt, &lastUsedAt, &f.CreatedAt, &f.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if verifiedAt.Valid {
+		f.VerifiedAt = &verifiedAt.Time
+	}
+	if lastUsedAt.Valid {
+		f.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &f, nil
+}
+
+// Create creates a new factor
+func (r *mfaFactorRepository) Create(ctx context.Context, factor *interfaces.MFAFactor) error {
+	if factor.ID == uuid.Nil {
+		factor.ID = uuid.New()
+	}
+	now := time.Now()
+	factor.CreatedAt = now
+	factor.UpdatedAt = now
+
+	query := `
+		INSERT INTO mfa_factors (
+			id, user_id, type, name, secret_encrypted, algorithm, digits, period,
+			verified, verified_at, last_used_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		factor.ID, factor.UserID, factor.Type, factor.Name, factor.SecretEncrypted,
+		factor.Algorithm, factor.Digits, factor.Period,
+		factor.Verified, factor.VerifiedAt, factor.LastUsedAt, factor.CreatedAt, factor.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create MFA factor: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a factor by ID
+func (r *mfaFactorRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.MFAFactor, error) {
+	query := `SELECT ` + mfaFactorColumns + ` FROM mfa_factors WHERE id = $1`
+
+	factor, err := scanMFAFactor(querier(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("MFA factor not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MFA factor: %w", err)
+	}
+
+	return factor, nil
+}
+
+// ListByUserID retrieves all factors for a user, oldest first
+func (r *mfaFactorRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*interfaces.MFAFactor, error) {
+	query := `SELECT ` + mfaFactorColumns + ` FROM mfa_factors WHERE user_id = $1 ORDER BY created_at ASC`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MFA factors: %w", err)
+	}
+	defer rows.Close()
+
+	var factors []*interfaces.MFAFactor
+	for rows.Next() {
+		factor, err := scanMFAFactor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan MFA factor: %w", err)
+		}
+		factors = append(factors, factor)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating MFA factors: %w", err)
+	}
+
+	return factors, nil
+}
+
+// Update updates a factor's name, verification state and last use
+func (r *mfaFactorRepository) Update(ctx context.Context, factor *interfaces.MFAFactor) error {
+	factor.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE mfa_factors
+		SET name = $2, verified = $3, verified_at = $4, last_used_at = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		factor.ID, factor.Name, factor.Verified, factor.VerifiedAt, factor.LastUsedAt, factor.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update MFA factor: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("MFA factor not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a factor
+func (r *mfaFactorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM mfa_factors WHERE id = $1`
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete MFA factor: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("MFA factor not found")
+	}
+
+	return nil
+}
+
+// DeleteUnverified deletes a user's pending (never verified) factors
+func (r *mfaFactorRepository) DeleteUnverified(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM mfa_factors WHERE user_id = $1 AND verified = false`
+
+	if _, err := querier(ctx, r.db).ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete pending MFA factors: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByUserID deletes all factors for a user
+func (r *mfaFactorRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM mfa_factors WHERE user_id = $1`
+
+	if _, err := querier(ctx, r.db).ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete MFA factors: %w", err)
+	}
+
+	return nil
+}
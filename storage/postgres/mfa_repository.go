@@ -40,7 +40,7 @@ func (r *mfaRecoveryCodeRepository) CreateRecoveryCodes(ctx context.Context, use
 		hash := sha256.Sum256([]byte(code))
 		codeHash := hex.EncodeToString(hash[:])
 
-		_, err := r.db.ExecContext(ctx, query, userID, codeHash)
+		_, err := querier(ctx, r.db).ExecContext(ctx, query, userID, codeHash)
 		if err != nil {
 			return fmt.Errorf("failed to create recovery code: %w", err)
 		}
@@ -58,7 +58,7 @@ func (r *mfaRecoveryCodeRepository) GetRecoveryCodes(ctx context.Context, userID
 	`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&count)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recovery codes: %w", err)
 	}
@@ -83,7 +83,7 @@ func (r *mfaRecoveryCodeRepository) VerifyAndDeleteRecoveryCode(ctx context.Cont
 	`
 
 	var id uuid.UUID
-	err := r.db.QueryRowContext(ctx, query, userID, codeHash).Scan(&id)
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, userID, codeHash).Scan(&id)
 	if err == sql.ErrNoRows {
 		return false, nil // Code not found or already used
 	}
@@ -98,7 +98,7 @@ func (r *mfaRecoveryCodeRepository) VerifyAndDeleteRecoveryCode(ctx context.Cont
 func (r *mfaRecoveryCodeRepository) DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error {
 	query := `DELETE FROM mfa_recovery_codes WHERE user_id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, userID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete recovery codes: %w", err)
 	}
@@ -106,3 +106,18 @@ func (r *mfaRecoveryCodeRepository) DeleteRecoveryCodes(ctx context.Context, use
 	return nil
 }
 
+
+// CountRemaining returns the number of unused recovery codes for a user
+func (r *mfaRecoveryCodeRepository) CountRemaining(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM mfa_recovery_codes
+		WHERE user_id = $1 AND used_at IS NULL
+	`
+
+	var count int
+	if err := querier(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
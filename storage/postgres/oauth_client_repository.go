@@ -31,7 +31,7 @@ func (r *OAuthClientRepository) Create(ctx context.Context, client *interfaces.O
 		RETURNING created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(
+	err := querier(ctx, r.db).QueryRowContext(
 		ctx, query,
 		client.ID,
 		client.TenantID,
@@ -65,7 +65,7 @@ func (r *OAuthClientRepository) GetByID(ctx context.Context, id uuid.UUID) (*int
 	`
 
 	client := &interfaces.OAuthClient{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&client.ID,
 		&client.TenantID,
 		&client.Name,
@@ -103,7 +103,7 @@ func (r *OAuthClientRepository) GetByClientID(ctx context.Context, clientID stri
 	`
 
 	client := &interfaces.OAuthClient{}
-	err := r.db.QueryRowContext(ctx, query, clientID).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, clientID).Scan(
 		&client.ID,
 		&client.TenantID,
 		&client.Name,
@@ -141,7 +141,7 @@ func (r *OAuthClientRepository) ListByTenant(ctx context.Context, tenantID uuid.
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list oauth clients: %w", err)
 	}
@@ -190,7 +190,7 @@ func (r *OAuthClientRepository) Update(ctx context.Context, client *interfaces.O
 		RETURNING updated_at
 	`
 
-	err := r.db.QueryRowContext(
+	err := querier(ctx, r.db).QueryRowContext(
 		ctx, query,
 		client.Name,
 		client.ClientSecretHash,
@@ -218,7 +218,7 @@ func (r *OAuthClientRepository) Update(ctx context.Context, client *interfaces.O
 func (r *OAuthClientRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM oauth_clients WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete oauth client: %w", err)
 	}
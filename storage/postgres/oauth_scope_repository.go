@@ -42,7 +42,7 @@ func (r *OAuthScopeRepository) Create(ctx context.Context, scope *models.OAuthSc
 		scope.UpdatedAt = now
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		scope.ID,
 		scope.TenantID,
 		scope.Name,
@@ -73,7 +73,7 @@ func (r *OAuthScopeRepository) GetByID(ctx context.Context, id uuid.UUID) (*mode
 	var permissions pq.StringArray
 	var deletedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&scope.ID,
 		&scope.TenantID,
 		&scope.Name,
@@ -113,7 +113,7 @@ func (r *OAuthScopeRepository) GetByName(ctx context.Context, tenantID uuid.UUID
 	var permissions pq.StringArray
 	var deletedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, name).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, name).Scan(
 		&scope.ID,
 		&scope.TenantID,
 		&scope.Name,
@@ -165,7 +165,7 @@ func (r *OAuthScopeRepository) List(ctx context.Context, tenantID uuid.UUID, fil
 		args = append(args, filters.PageSize, offset)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query OAuth scopes: %w", err)
 	}
@@ -215,7 +215,7 @@ func (r *OAuthScopeRepository) Update(ctx context.Context, scope *models.OAuthSc
 
 	scope.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
 		scope.Name,
 		scope.Description,
 		pq.Array(scope.Permissions),
@@ -248,7 +248,7 @@ func (r *OAuthScopeRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete OAuth scope: %w", err)
 	}
@@ -275,7 +275,7 @@ func (r *OAuthScopeRepository) GetDefaultScopes(ctx context.Context, tenantID uu
 		ORDER BY name ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query default OAuth scopes: %w", err)
 	}
@@ -331,7 +331,7 @@ func (r *OAuthScopeRepository) GetScopesByPermissions(ctx context.Context, tenan
 		ORDER BY name ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID, pq.Array(permissions))
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID, pq.Array(permissions))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query OAuth scopes by permissions: %w", err)
 	}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+)
+
+// outboxRelayLockKey identifies the advisory lock held by the active relay
+const outboxRelayLockKey int64 = 0x6f7574626f78 // "outbox"
+
+// outboxRepository implements OutboxRepository for PostgreSQL
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new PostgreSQL outbox repository
+func NewOutboxRepository(db *sql.DB) interfaces.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+const outboxEventColumns = `id, event_id, tenant_id, event_type, payload, status, attempts, next_attempt_at,
+	last_error, created_at, processed_at`
+
+// insertOutboxEvent adds an audit event to the outbox within tx
+func insertOutboxEvent(ctx context.Context, tx dbtx, event *models.AuditEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox event: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO outbox_events (event_id, tenant_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+	`, event.ID, event.TenantID, event.EventType, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+
+	return nil
+}
+
+// WithRelayLock runs fn while holding a session-level advisory lock. The
+// lock lives on a dedicated connection, so it is released when that
+// connection ends even if the process dies without unlocking.
+func (r *outboxRepository) WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, outboxRelayLockKey).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to acquire outbox relay lock: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		_, err := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, outboxRelayLockKey)
+		if err != nil {
+			// Never return a connection that may still hold the lock to the pool
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return true, fn(ctx)
+}
+
+// ListDue retrieves pending events that are due, oldest first, skipping
+// events queued behind a tenant's event that is waiting for a retry
+func (r *outboxRepository) ListDue(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	query := `
+		SELECT ` + outboxEventColumns + `
+		FROM outbox_events o
+		WHERE o.status = 'pending' AND o.next_attempt_at <= NOW()
+		  AND NOT EXISTS (
+			SELECT 1 FROM outbox_events p
+			WHERE p.status = 'pending' AND p.id < o.id AND p.next_attempt_at > NOW()
+			  AND p.tenant_id IS NOT DISTINCT FROM o.tenant_id
+		  )
+		ORDER BY o.id
+		LIMIT $1
+	`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.OutboxEvent
+	for rows.Next() {
+		event := &models.OutboxEvent{}
+		var payload []byte
+		err := rows.Scan(&event.ID, &event.EventID, &event.TenantID, &event.EventType, &payload, &event.Status,
+			&event.Attempts, &event.NextAttemptAt, &event.LastError, &event.CreatedAt, &event.ProcessedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// MarkProcessed marks an event as handled by every consumer
+func (r *outboxRepository) MarkProcessed(ctx context.Context, id int64) error {
+	_, err := querier(ctx, r.db).ExecContext(ctx, `
+		UPDATE outbox_events
+		SET status = 'processed', attempts = attempts + 1, last_error = NULL, processed_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event processed: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt and schedules the next one
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	_, err := querier(ctx, r.db).ExecContext(ctx, `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3,
+			status = CASE WHEN $4 THEN 'dead' ELSE status END,
+			processed_at = CASE WHEN $4 THEN NOW() ELSE processed_at END
+		WHERE id = $1
+	`, id, lastError, nextAttemptAt, dead)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+// OldestPending returns when the oldest pending event was written
+func (r *outboxRepository) OldestPending(ctx context.Context) (*time.Time, error) {
+	var oldest sql.NullTime
+	err := querier(ctx, r.db).QueryRowContext(ctx,
+		`SELECT MIN(created_at) FROM outbox_events WHERE status = 'pending'`).Scan(&oldest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oldest outbox event: %w", err)
+	}
+	if !oldest.Valid {
+		return nil, nil
+	}
+	return &oldest.Time, nil
+}
+
+// DeleteProcessedBefore removes processed and dead events older than the given time
+func (r *outboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := querier(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM outbox_events WHERE status <> 'pending' AND processed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete outbox events: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(count), nil
+}
@@ -44,7 +44,7 @@ func (r *permissionRepository) Create(ctx context.Context, permission *models.Pe
 			INSERT INTO permissions (id, tenant_id, name, description, resource, action, created_at, updated_at)
 			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		`
-		_, err = r.db.ExecContext(ctx, query,
+		_, err = querier(ctx, r.db).ExecContext(ctx, query,
 			permission.ID, permission.TenantID, permission.Name, permission.Description,
 			permission.Resource, permission.Action, permission.CreatedAt, permission.UpdatedAt,
 		)
@@ -54,7 +54,7 @@ func (r *permissionRepository) Create(ctx context.Context, permission *models.Pe
 			INSERT INTO permissions (id, name, description, resource, action, created_at)
 			VALUES ($1, $2, $3, $4, $5, $6)
 		`
-		_, err = r.db.ExecContext(ctx, query,
+		_, err = querier(ctx, r.db).ExecContext(ctx, query,
 			permission.ID, permission.Name, permission.Description,
 			permission.Resource, permission.Action, permission.CreatedAt,
 		)
@@ -85,7 +85,7 @@ func (r *permissionRepository) GetByID(ctx context.Context, id uuid.UUID) (*mode
 	var updatedAt sql.NullTime
 	var tenantIDStr string
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&permission.ID, &tenantIDStr, &permission.Name, &description,
 		&permission.Resource, &permission.Action, &permission.CreatedAt, &updatedAt, &deletedAt,
 	)
@@ -100,7 +100,7 @@ func (r *permissionRepository) GetByID(ctx context.Context, id uuid.UUID) (*mode
 			FROM permissions
 			WHERE id = $1
 		`
-		err = r.db.QueryRowContext(ctx, query, id).Scan(
+		err = querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 			&permission.ID, &permission.Name, &description,
 			&permission.Resource, &permission.Action, &permission.CreatedAt,
 		)
@@ -149,7 +149,7 @@ func (r *permissionRepository) GetByName(ctx context.Context, tenantID uuid.UUID
 	permission := &models.Permission{}
 	var description sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, name).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, name).Scan(
 		&permission.ID, &permission.Name, &description,
 		&permission.Resource, &permission.Action, &permission.CreatedAt,
 	)
@@ -177,7 +177,7 @@ func (r *permissionRepository) Update(ctx context.Context, permission *models.Pe
 		WHERE id = $1
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		permission.ID, permission.Name, permission.Description,
 		permission.Resource, permission.Action,
 	)
@@ -193,7 +193,7 @@ func (r *permissionRepository) Update(ctx context.Context, permission *models.Pe
 func (r *permissionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM permissions WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete permission: %w", err)
 	}
@@ -269,7 +269,7 @@ func (r *permissionRepository) List(ctx context.Context, tenantID uuid.UUID, fil
 	query += fmt.Sprintf(" ORDER BY resource, action LIMIT $%d OFFSET $%d", argPos, argPos+1)
 	args = append(args, filters.PageSize, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		// Fallback: try without tenant_id column (for old schema)
 		query = `
@@ -309,7 +309,7 @@ func (r *permissionRepository) List(ctx context.Context, tenantID uuid.UUID, fil
 		query += fmt.Sprintf(" ORDER BY resource, action LIMIT $%d OFFSET $%d", argPos, argPos+1)
 		args = append(args, filters.PageSize, offset)
 
-		rows, err = r.db.QueryContext(ctx, query, args...)
+		rows, err = querier(ctx, r.db).QueryContext(ctx, query, args...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list permissions: %w", err)
 		}
@@ -381,7 +381,7 @@ func (r *permissionRepository) GetRolePermissions(ctx context.Context, roleID uu
 		WHERE rp.role_id = $1
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, roleID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, roleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get role permissions: %w", err)
 	}
@@ -422,7 +422,7 @@ func (r *permissionRepository) AssignPermissionToRole(ctx context.Context, roleI
 		ON CONFLICT (role_id, permission_id) DO NOTHING
 	`
 
-	_, err := r.db.ExecContext(ctx, query, roleID, permissionID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, roleID, permissionID)
 	if err != nil {
 		return fmt.Errorf("failed to assign permission to role: %w", err)
 	}
@@ -434,7 +434,7 @@ func (r *permissionRepository) AssignPermissionToRole(ctx context.Context, roleI
 func (r *permissionRepository) RemovePermissionFromRole(ctx context.Context, roleID, permissionID uuid.UUID) error {
 	query := `DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, roleID, permissionID)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, roleID, permissionID)
 	if err != nil {
 		return fmt.Errorf("failed to remove permission from role: %w", err)
 	}
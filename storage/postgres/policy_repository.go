@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// policyRepository implements PolicyRepository for PostgreSQL
+type policyRepository struct {
+	db *sql.DB
+}
+
+// NewPolicyRepository creates a new PostgreSQL policy repository
+func NewPolicyRepository(db *sql.DB) interfaces.PolicyRepository {
+	return &policyRepository{db: db}
+}
+
+const policyColumns = `id, tenant_id, name, description, effect, resource, action, condition, enabled, created_at, updated_at`
+
+// Create creates a new policy
+func (r *policyRepository) Create(ctx context.Context, policy *models.Policy) error {
+	query := `
+		INSERT INTO policies (id, tenant_id, name, description, effect, resource, action, condition, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	now := time.Now()
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	if policy.CreatedAt.IsZero() {
+		policy.CreatedAt = now
+	}
+	if policy.UpdatedAt.IsZero() {
+		policy.UpdatedAt = now
+	}
+
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		policy.ID, policy.TenantID, policy.Name, policy.Description, policy.Effect,
+		policy.Resource, policy.Action, policy.Condition, policy.Enabled,
+		policy.CreatedAt, policy.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create policy: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a policy by ID
+func (r *policyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM policies WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+// GetByName retrieves a policy by name and tenant ID
+func (r *policyRepository) GetByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM policies WHERE tenant_id = $1 AND name = $2`
+	return r.getOne(ctx, query, tenantID, name)
+}
+
+// Update updates an existing policy
+func (r *policyRepository) Update(ctx context.Context, policy *models.Policy) error {
+	query := `
+		UPDATE policies
+		SET name = $2, description = $3, effect = $4, resource = $5, action = $6,
+			condition = $7, enabled = $8, updated_at = $9
+		WHERE id = $1
+	`
+
+	policy.UpdatedAt = time.Now()
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		policy.ID, policy.Name, policy.Description, policy.Effect, policy.Resource,
+		policy.Action, policy.Condition, policy.Enabled, policy.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("policy not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a policy
+func (r *policyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := querier(ctx, r.db).ExecContext(ctx, `DELETE FROM policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("policy not found")
+	}
+
+	return nil
+}
+
+// List retrieves a tenant's policies, optionally only the enabled ones
+func (r *policyRepository) List(ctx context.Context, tenantID uuid.UUID, enabledOnly bool) ([]*models.Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM policies WHERE tenant_id = $1`
+	if enabledOnly {
+		query += ` AND enabled`
+	}
+	query += ` ORDER BY name`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*models.Policy
+	for rows.Next() {
+		policy, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// getOne runs a query expected to return a single policy row
+func (r *policyRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Policy, error) {
+	policy, err := scanPolicy(querier(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// scanPolicy scans a row selected with policyColumns
+func scanPolicy(row rowScanner) (*models.Policy, error) {
+	policy := &models.Policy{}
+	var description sql.NullString
+
+	err := row.Scan(
+		&policy.ID, &policy.TenantID, &policy.Name, &description, &policy.Effect,
+		&policy.Resource, &policy.Action, &policy.Condition, &policy.Enabled,
+		&policy.CreatedAt, &policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		policy.Description = &description.String
+	}
+
+	return policy, nil
+}
@@ -20,13 +20,19 @@ func NewRefreshTokenRepository(db *sql.DB) interfaces.RefreshTokenRepository {
 	return &refreshTokenRepository{db: db}
 }
 
+// refreshTokenColumns is the column list shared by all refresh token SELECTs
+const refreshTokenColumns = `id, user_id, tenant_id, token_hash, expires_at, revoked_at,
+		       remember_me, mfa_verified, ip_address, user_agent, device_info, last_used_at,
+		       created_at, updated_at`
+
 // Create creates a new refresh token
 func (r *refreshTokenRepository) Create(ctx context.Context, token *interfaces.RefreshToken) error {
 	query := `
 		INSERT INTO refresh_tokens (
 			id, user_id, tenant_id, token_hash, expires_at, revoked_at,
-			remember_me, mfa_verified, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			remember_me, mfa_verified, ip_address, user_agent, device_info, last_used_at,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	now := time.Now()
@@ -48,9 +54,10 @@ func (r *refreshTokenRepository) Create(ctx context.Context, token *interfaces.R
 		tenantIDValue = nil
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		token.ID, token.UserID, tenantIDValue, token.TokenHash,
 		token.ExpiresAt, token.RevokedAt, token.RememberMe, token.MFAVerified,
+		nullString(token.IPAddress), nullString(token.UserAgent), nullString(token.DeviceInfo), token.LastUsedAt,
 		token.CreatedAt, token.UpdatedAt,
 	)
 
@@ -61,33 +68,34 @@ func (r *refreshTokenRepository) Create(ctx context.Context, token *interfaces.R
 	return nil
 }
 
-// GetByTokenHash retrieves a refresh token by its hash
-func (r *refreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*interfaces.RefreshToken, error) {
+// GetByID retrieves a refresh token by its ID
+func (r *refreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*interfaces.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, tenant_id, token_hash, expires_at, revoked_at,
-		       remember_me, mfa_verified, created_at, updated_at
+		SELECT ` + refreshTokenColumns + `
 		FROM refresh_tokens
-		WHERE token_hash = $1
+		WHERE id = $1
 	`
 
-	token := &interfaces.RefreshToken{}
-	var revokedAt sql.NullTime
-	var tenantID sql.NullString
+	token, err := scanRefreshToken(querier(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("refresh token not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
 
-	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
-		&token.ID, &token.UserID, &tenantID, &token.TokenHash,
-		&token.ExpiresAt, &revokedAt, &token.RememberMe, &token.MFAVerified,
-		&token.CreatedAt, &token.UpdatedAt,
-	)
+	return token, nil
+}
 
-	// Handle nullable tenant_id
-	if tenantID.Valid {
-		parsedTenantID, err := uuid.Parse(tenantID.String)
-		if err == nil {
-			token.TenantID = parsedTenantID
-		}
-	}
+// GetByTokenHash retrieves a refresh token by its hash
+func (r *refreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*interfaces.RefreshToken, error) {
+	query := `
+		SELECT ` + refreshTokenColumns + `
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
 
+	token, err := scanRefreshToken(querier(ctx, r.db).QueryRowContext(ctx, query, tokenHash))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("refresh token not found: %w", err)
 	}
@@ -95,24 +103,19 @@ func (r *refreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash s
 		return nil, fmt.Errorf("failed to get refresh token: %w", err)
 	}
 
-	if revokedAt.Valid {
-		token.RevokedAt = &revokedAt.Time
-	}
-
 	return token, nil
 }
 
 // GetByUserID retrieves all active refresh tokens for a user
 func (r *refreshTokenRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*interfaces.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, tenant_id, token_hash, expires_at, revoked_at,
-		       remember_me, mfa_verified, created_at, updated_at
+		SELECT ` + refreshTokenColumns + `
 		FROM refresh_tokens
 		WHERE user_id = $1 AND (revoked_at IS NULL OR revoked_at > NOW())
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get refresh tokens: %w", err)
 	}
@@ -120,31 +123,10 @@ func (r *refreshTokenRepository) GetByUserID(ctx context.Context, userID uuid.UU
 
 	var tokens []*interfaces.RefreshToken
 	for rows.Next() {
-		token := &interfaces.RefreshToken{}
-		var revokedAt sql.NullTime
-		var tenantID sql.NullString
-
-		err := rows.Scan(
-			&token.ID, &token.UserID, &tenantID, &token.TokenHash,
-			&token.ExpiresAt, &revokedAt, &token.RememberMe, &token.MFAVerified,
-			&token.CreatedAt, &token.UpdatedAt,
-		)
-
-		// Handle nullable tenant_id
-		if tenantID.Valid {
-			parsedTenantID, err := uuid.Parse(tenantID.String)
-			if err == nil {
-				token.TenantID = parsedTenantID
-			}
-		}
+		token, err := scanRefreshToken(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan refresh token: %w", err)
 		}
-
-		if revokedAt.Valid {
-			token.RevokedAt = &revokedAt.Time
-		}
-
 		tokens = append(tokens, token)
 	}
 
@@ -155,6 +137,46 @@ func (r *refreshTokenRepository) GetByUserID(ctx context.Context, userID uuid.UU
 	return tokens, nil
 }
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRefreshToken scans a row selected with refreshTokenColumns
+func scanRefreshToken(row rowScanner) (*interfaces.RefreshToken, error) {
+	token := &interfaces.RefreshToken{}
+	var revokedAt, lastUsedAt sql.NullTime
+	var tenantID, ipAddress, userAgent, deviceInfo sql.NullString
+
+	err := row.Scan(
+		&token.ID, &token.UserID, &tenantID, &token.TokenHash,
+		&token.ExpiresAt, &revokedAt, &token.RememberMe, &token.MFAVerified,
+		&ipAddress, &userAgent, &deviceInfo, &lastUsedAt,
+		&token.CreatedAt, &token.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle nullable tenant_id
+	if tenantID.Valid {
+		if parsedTenantID, err := uuid.Parse(tenantID.String); err == nil {
+			token.TenantID = parsedTenantID
+		}
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	token.IPAddress = ipAddress.String
+	token.UserAgent = userAgent.String
+	token.DeviceInfo = deviceInfo.String
+
+	return token, nil
+}
+
 // Revoke revokes a refresh token
 func (r *refreshTokenRepository) Revoke(ctx context.Context, tokenID uuid.UUID) error {
 	query := `
@@ -163,7 +185,7 @@ func (r *refreshTokenRepository) Revoke(ctx context.Context, tokenID uuid.UUID)
 		WHERE id = $1 AND revoked_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, tokenID)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, tokenID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke refresh token: %w", err)
 	}
@@ -188,7 +210,7 @@ func (r *refreshTokenRepository) RevokeByTokenHash(ctx context.Context, tokenHas
 		WHERE token_hash = $1 AND revoked_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, tokenHash)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, tokenHash)
 	if err != nil {
 		return fmt.Errorf("failed to revoke refresh token: %w", err)
 	}
@@ -213,7 +235,7 @@ func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uu
 		WHERE user_id = $1 AND revoked_at IS NULL
 	`
 
-	_, err := r.db.ExecContext(ctx, query, userID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, userID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke all refresh tokens: %w", err)
 	}
@@ -230,7 +252,7 @@ func (r *refreshTokenRepository) RevokeByClientID(ctx context.Context, clientID
 		WHERE client_id = $1 AND revoked_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, clientID)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, clientID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to revoke tokens by client ID: %w", err)
 	}
@@ -250,10 +272,15 @@ func (r *refreshTokenRepository) DeleteExpired(ctx context.Context) error {
 		WHERE expires_at < NOW()
 	`
 
-	_, err := r.db.ExecContext(ctx, query)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
 	}
 
 	return nil
 }
+
+// nullString maps an empty string to SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,329 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// relationRepository implements RelationRepository for PostgreSQL
+type relationRepository struct {
+	db *sql.DB
+}
+
+// NewRelationRepository creates a new PostgreSQL relationship tuple repository
+func NewRelationRepository(db *sql.DB) interfaces.RelationRepository {
+	return &relationRepository{db: db}
+}
+
+const relationTupleColumns = `tenant_id, namespace, object_id, relation, subject_namespace, subject_id, subject_relation, revision, created_at`
+
+// UpsertNamespace creates or replaces a tenant's namespace definition
+func (r *relationRepository) UpsertNamespace(ctx context.Context, namespace *models.RelationNamespace) (int64, error) {
+	configJSON, err := json.Marshal(namespace.Config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal namespace config: %w", err)
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if namespace.ID == uuid.Nil {
+		namespace.ID = uuid.New()
+	}
+	now := time.Now()
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO relation_namespaces (id, tenant_id, name, config, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (tenant_id, name) DO UPDATE SET config = EXCLUDED.config, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`, namespace.ID, namespace.TenantID, namespace.Name, configJSON, now).
+		Scan(&namespace.ID, &namespace.CreatedAt, &namespace.UpdatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save namespace: %w", err)
+	}
+
+	revision, err := nextRelationRevision(ctx, tx, namespace.TenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit namespace: %w", err)
+	}
+
+	return revision, nil
+}
+
+// GetNamespace retrieves a namespace definition by name
+func (r *relationRepository) GetNamespace(ctx context.Context, tenantID uuid.UUID, name string) (*models.RelationNamespace, error) {
+	query := `
+		SELECT id, tenant_id, name, config, created_at, updated_at
+		FROM relation_namespaces
+		WHERE tenant_id = $1 AND name = $2
+	`
+
+	namespace, err := scanRelationNamespace(querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("namespace not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	return namespace, nil
+}
+
+// ListNamespaces retrieves all namespace definitions of a tenant
+func (r *relationRepository) ListNamespaces(ctx context.Context, tenantID uuid.UUID) ([]*models.RelationNamespace, error) {
+	query := `
+		SELECT id, tenant_id, name, config, created_at, updated_at
+		FROM relation_namespaces
+		WHERE tenant_id = $1
+		ORDER BY name
+	`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	defer rows.Close()
+
+	var namespaces []*models.RelationNamespace
+	for rows.Next() {
+		namespace, err := scanRelationNamespace(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan namespace: %w", err)
+		}
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces, rows.Err()
+}
+
+// DeleteNamespace deletes a namespace definition together with its tuples
+func (r *relationRepository) DeleteNamespace(ctx context.Context, tenantID uuid.UUID, name string) (int64, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM relation_namespaces WHERE tenant_id = $1 AND name = $2`, tenantID, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete namespace: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("namespace not found")
+	}
+
+	// Tuples of the namespace, and tuples naming its objects as subjects, are meaningless without it
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM relation_tuples
+		WHERE tenant_id = $1 AND (namespace = $2 OR subject_namespace = $2)
+	`, tenantID, name); err != nil {
+		return 0, fmt.Errorf("failed to delete namespace tuples: %w", err)
+	}
+
+	revision, err := nextRelationRevision(ctx, tx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit namespace deletion: %w", err)
+	}
+
+	return revision, nil
+}
+
+// WriteTuples atomically inserts and deletes tuples and returns the new tenant revision
+func (r *relationRepository) WriteTuples(ctx context.Context, tenantID uuid.UUID, writes, deletes []*models.RelationTuple) (int64, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Taking the revision first locks the tenant's revision row, so concurrent
+	// writes to a tenant commit in revision order
+	revision, err := nextRelationRevision(ctx, tx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, t := range deletes {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM relation_tuples
+			WHERE tenant_id = $1 AND namespace = $2 AND object_id = $3 AND relation = $4
+				AND subject_namespace = $5 AND subject_id = $6 AND subject_relation = $7
+		`, tenantID, t.Namespace, t.ObjectID, t.Relation, t.SubjectNamespace, t.SubjectID, t.SubjectRelation); err != nil {
+			return 0, fmt.Errorf("failed to delete tuple: %w", err)
+		}
+	}
+
+	now := time.Now()
+	for _, t := range writes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO relation_tuples (`+relationTupleColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT DO NOTHING
+		`, tenantID, t.Namespace, t.ObjectID, t.Relation, t.SubjectNamespace, t.SubjectID, t.SubjectRelation, revision, now); err != nil {
+			return 0, fmt.Errorf("failed to write tuple: %w", err)
+		}
+		t.TenantID = tenantID
+		t.Revision = revision
+		t.CreatedAt = now
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit tuples: %w", err)
+	}
+
+	return revision, nil
+}
+
+// ListTuples retrieves a tenant's tuples matching a filter
+func (r *relationRepository) ListTuples(ctx context.Context, tenantID uuid.UUID, filter interfaces.RelationTupleFilter) ([]*models.RelationTuple, error) {
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{tenantID}
+	add := func(column, value string) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if filter.Namespace != "" {
+		add("namespace", filter.Namespace)
+	}
+	if filter.ObjectID != "" {
+		add("object_id", filter.ObjectID)
+	}
+	if filter.Relation != "" {
+		add("relation", filter.Relation)
+	}
+	if filter.SubjectNamespace != "" {
+		add("subject_namespace", filter.SubjectNamespace)
+	}
+	if filter.SubjectID != "" {
+		add("subject_id", filter.SubjectID)
+	}
+	if filter.SubjectRelation != nil {
+		add("subject_relation", *filter.SubjectRelation)
+	}
+
+	query := `SELECT ` + relationTupleColumns + ` FROM relation_tuples WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY namespace, object_id, relation, subject_namespace, subject_id, subject_relation`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tuples: %w", err)
+	}
+	defer rows.Close()
+
+	var tuples []*models.RelationTuple
+	for rows.Next() {
+		t := &models.RelationTuple{}
+		if err := rows.Scan(
+			&t.TenantID, &t.Namespace, &t.ObjectID, &t.Relation,
+			&t.SubjectNamespace, &t.SubjectID, &t.SubjectRelation, &t.Revision, &t.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan tuple: %w", err)
+		}
+		tuples = append(tuples, t)
+	}
+
+	return tuples, rows.Err()
+}
+
+// ListObjectIDs retrieves the distinct object IDs of a namespace that have tuples
+func (r *relationRepository) ListObjectIDs(ctx context.Context, tenantID uuid.UUID, namespace string, limit int) ([]string, error) {
+	query := `
+		SELECT DISTINCT object_id
+		FROM relation_tuples
+		WHERE tenant_id = $1 AND namespace = $2
+		ORDER BY object_id
+		LIMIT $3
+	`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID, namespace, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan object id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// CurrentRevision returns the tenant's latest revision
+func (r *relationRepository) CurrentRevision(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var revision int64
+	err := querier(ctx, r.db).QueryRowContext(ctx, `SELECT revision FROM relation_revisions WHERE tenant_id = $1`, tenantID).Scan(&revision)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get relation revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// nextRelationRevision increments the tenant's revision within a transaction
+func nextRelationRevision(ctx context.Context, tx dbtx, tenantID uuid.UUID) (int64, error) {
+	var revision int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO relation_revisions (tenant_id, revision, updated_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET revision = relation_revisions.revision + 1, updated_at = NOW()
+		RETURNING revision
+	`, tenantID).Scan(&revision)
+	if err != nil {
+		return 0, fmt.Errorf("failed to advance relation revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// scanRelationNamespace scans a namespace row
+func scanRelationNamespace(row rowScanner) (*models.RelationNamespace, error) {
+	namespace := &models.RelationNamespace{}
+	var configJSON []byte
+	if err := row.Scan(
+		&namespace.ID, &namespace.TenantID, &namespace.Name, &configJSON,
+		&namespace.CreatedAt, &namespace.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(configJSON, &namespace.Config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal namespace config: %w", err)
+	}
+
+	return namespace, nil
+}
@@ -24,8 +24,8 @@ func NewRoleRepository(db *sql.DB) interfaces.RoleRepository {
 // Create creates a new role
 func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
 	query := `
-		INSERT INTO roles (id, tenant_id, name, description, is_system, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO roles (id, tenant_id, name, description, is_system, owner_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	now := time.Now()
@@ -39,9 +39,9 @@ func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
 		role.UpdatedAt = now
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		role.ID, role.TenantID, role.Name, role.Description,
-		role.IsSystem, role.CreatedAt, role.UpdatedAt,
+		role.IsSystem, role.OwnerID, role.CreatedAt, role.UpdatedAt,
 	)
 
 	if err != nil {
@@ -54,7 +54,7 @@ func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
 // GetByID retrieves a role by ID
 func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
 	query := `
-		SELECT id, tenant_id, name, description, is_system, created_at, updated_at, deleted_at
+		SELECT id, tenant_id, name, description, is_system, created_at, updated_at, deleted_at, owner_id
 		FROM roles
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -62,10 +62,11 @@ func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Rol
 	role := &models.Role{}
 	var description sql.NullString
 	var deletedAt sql.NullTime
+	var ownerID uuid.NullUUID
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&role.ID, &role.TenantID, &role.Name, &description,
-		&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt,
+		&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt, &ownerID,
 	)
 
 	if err == sql.ErrNoRows {
@@ -81,6 +82,9 @@ func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Rol
 	if deletedAt.Valid {
 		role.DeletedAt = &deletedAt.Time
 	}
+	if ownerID.Valid {
+		role.OwnerID = &ownerID.UUID
+	}
 
 	return role, nil
 }
@@ -88,7 +92,7 @@ func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Rol
 // GetByName retrieves a role by name and tenant ID
 func (r *roleRepository) GetByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Role, error) {
 	query := `
-		SELECT id, tenant_id, name, description, is_system, created_at, updated_at, deleted_at
+		SELECT id, tenant_id, name, description, is_system, created_at, updated_at, deleted_at, owner_id
 		FROM roles
 		WHERE tenant_id = $1 AND name = $2 AND deleted_at IS NULL
 	`
@@ -96,10 +100,11 @@ func (r *roleRepository) GetByName(ctx context.Context, tenantID uuid.UUID, name
 	role := &models.Role{}
 	var description sql.NullString
 	var deletedAt sql.NullTime
+	var ownerID uuid.NullUUID
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, name).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, name).Scan(
 		&role.ID, &role.TenantID, &role.Name, &description,
-		&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt,
+		&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt, &ownerID,
 	)
 
 	if err == sql.ErrNoRows {
@@ -115,6 +120,9 @@ func (r *roleRepository) GetByName(ctx context.Context, tenantID uuid.UUID, name
 	if deletedAt.Valid {
 		role.DeletedAt = &deletedAt.Time
 	}
+	if ownerID.Valid {
+		role.OwnerID = &ownerID.UUID
+	}
 
 	return role, nil
 }
@@ -123,14 +131,14 @@ func (r *roleRepository) GetByName(ctx context.Context, tenantID uuid.UUID, name
 func (r *roleRepository) Update(ctx context.Context, role *models.Role) error {
 	query := `
 		UPDATE roles
-		SET name = $2, description = $3, updated_at = $4
+		SET name = $2, description = $3, owner_id = $4, updated_at = $5
 		WHERE id = $1 AND deleted_at IS NULL AND is_system = false
 	`
 
 	role.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(ctx, query,
-		role.ID, role.Name, role.Description, role.UpdatedAt,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		role.ID, role.Name, role.Description, role.OwnerID, role.UpdatedAt,
 	)
 
 	if err != nil {
@@ -144,7 +152,7 @@ func (r *roleRepository) Update(ctx context.Context, role *models.Role) error {
 func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Check if role is system role (cannot be deleted)
 	var isSystem bool
-	err := r.db.QueryRowContext(ctx, "SELECT is_system FROM roles WHERE id = $1", id).Scan(&isSystem)
+	err := querier(ctx, r.db).QueryRowContext(ctx, "SELECT is_system FROM roles WHERE id = $1", id).Scan(&isSystem)
 	if err == nil && isSystem {
 		return fmt.Errorf("cannot delete system role")
 	}
@@ -155,7 +163,7 @@ func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete role: %w", err)
 	}
@@ -169,6 +177,13 @@ func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("role not found")
 	}
 
+	// Soft-deleted roles keep their row, so drop inheritance links explicitly
+	// rather than relying on ON DELETE CASCADE
+	_, err = querier(ctx, r.db).ExecContext(ctx, "DELETE FROM role_parents WHERE role_id = $1 OR parent_role_id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to remove role inheritance: %w", err)
+	}
+
 	return nil
 }
 
@@ -191,7 +206,7 @@ func (r *roleRepository) List(ctx context.Context, tenantID uuid.UUID, filters *
 	offset := (filters.Page - 1) * filters.PageSize
 
 	query := `
-		SELECT id, tenant_id, name, description, is_system, created_at, updated_at, deleted_at
+		SELECT id, tenant_id, name, description, is_system, created_at, updated_at, deleted_at, owner_id
 		FROM roles
 		WHERE tenant_id = $1 AND deleted_at IS NULL
 	`
@@ -214,7 +229,7 @@ func (r *roleRepository) List(ctx context.Context, tenantID uuid.UUID, filters *
 	query += " ORDER BY created_at DESC LIMIT $" + fmt.Sprintf("%d", argPos) + " OFFSET $" + fmt.Sprintf("%d", argPos+1)
 	args = append(args, filters.PageSize, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list roles: %w", err)
 	}
@@ -225,10 +240,11 @@ func (r *roleRepository) List(ctx context.Context, tenantID uuid.UUID, filters *
 		role := &models.Role{}
 		var description sql.NullString
 		var deletedAt sql.NullTime
+		var ownerID uuid.NullUUID
 
 		err := rows.Scan(
 			&role.ID, &role.TenantID, &role.Name, &description,
-			&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt,
+			&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt, &ownerID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan role: %w", err)
@@ -240,6 +256,9 @@ func (r *roleRepository) List(ctx context.Context, tenantID uuid.UUID, filters *
 		if deletedAt.Valid {
 			role.DeletedAt = &deletedAt.Time
 		}
+		if ownerID.Valid {
+			role.OwnerID = &ownerID.UUID
+		}
 
 		roles = append(roles, role)
 	}
@@ -254,13 +273,14 @@ func (r *roleRepository) List(ctx context.Context, tenantID uuid.UUID, filters *
 // GetUserRoles retrieves all roles for a user
 func (r *roleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error) {
 	query := `
-		SELECT r.id, r.tenant_id, r.name, r.description, r.is_system, r.created_at, r.updated_at, r.deleted_at
+		SELECT r.id, r.tenant_id, r.name, r.description, r.is_system, r.created_at, r.updated_at, r.deleted_at, r.owner_id
 		FROM roles r
 		INNER JOIN user_roles ur ON r.id = ur.role_id
 		WHERE ur.user_id = $1 AND r.deleted_at IS NULL
+		  AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
@@ -271,10 +291,11 @@ func (r *roleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 		role := &models.Role{}
 		var description sql.NullString
 		var deletedAt sql.NullTime
+		var ownerID uuid.NullUUID
 
 		err := rows.Scan(
 			&role.ID, &role.TenantID, &role.Name, &description,
-			&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt,
+			&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt, &ownerID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan role: %w", err)
@@ -286,6 +307,9 @@ func (r *roleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 		if deletedAt.Valid {
 			role.DeletedAt = &deletedAt.Time
 		}
+		if ownerID.Valid {
+			role.OwnerID = &ownerID.UUID
+		}
 
 		roles = append(roles, role)
 	}
@@ -297,15 +321,16 @@ func (r *roleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 	return roles, nil
 }
 
-// AssignRoleToUser assigns a role to a user
+// AssignRoleToUser assigns a role to a user permanently, clearing any expiry
 func (r *roleRepository) AssignRoleToUser(ctx context.Context, userID, roleID uuid.UUID) error {
 	query := `
 		INSERT INTO user_roles (id, user_id, role_id, assigned_at)
 		VALUES (gen_random_uuid(), $1, $2, NOW())
-		ON CONFLICT (user_id, role_id) DO NOTHING
+		ON CONFLICT (user_id, role_id) DO UPDATE
+		SET expires_at = NULL, elevation_request_id = NULL
 	`
 
-	_, err := r.db.ExecContext(ctx, query, userID, roleID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, userID, roleID)
 	if err != nil {
 		return fmt.Errorf("failed to assign role to user: %w", err)
 	}
@@ -317,7 +342,7 @@ func (r *roleRepository) AssignRoleToUser(ctx context.Context, userID, roleID uu
 func (r *roleRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error {
 	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, userID, roleID)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, userID, roleID)
 	if err != nil {
 		return fmt.Errorf("failed to remove role from user: %w", err)
 	}
@@ -334,3 +359,224 @@ func (r *roleRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID
 	return nil
 }
 
+// AssignRoleToUserWithExpiry assigns a role that lapses at the assignment's expiry.
+// A permanent assignment stays permanent; otherwise the later expiry wins.
+func (r *roleRepository) AssignRoleToUserWithExpiry(ctx context.Context, assignment *models.UserRoleAssignment) error {
+	query := `
+		INSERT INTO user_roles (id, user_id, role_id, assigned_at, assigned_by, expires_at, elevation_request_id)
+		VALUES (gen_random_uuid(), $1, $2, NOW(), $3, $4, $5)
+		ON CONFLICT (user_id, role_id) DO UPDATE
+		SET expires_at = CASE
+				WHEN user_roles.expires_at IS NULL THEN NULL
+				WHEN user_roles.expires_at > NOW() THEN GREATEST(user_roles.expires_at, EXCLUDED.expires_at)
+				ELSE EXCLUDED.expires_at
+			END,
+			elevation_request_id = CASE
+				WHEN user_roles.expires_at IS NULL THEN user_roles.elevation_request_id
+				ELSE EXCLUDED.elevation_request_id
+			END,
+			assigned_by = CASE
+				WHEN user_roles.expires_at IS NULL THEN user_roles.assigned_by
+				ELSE EXCLUDED.assigned_by
+			END
+	`
+
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		assignment.UserID, assignment.Role.ID, assignment.AssignedBy,
+		assignment.ExpiresAt, assignment.ElevationRequestID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to assign role to user: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserRoleAssignments retrieves the unexpired role assignments of a user
+func (r *roleRepository) GetUserRoleAssignments(ctx context.Context, userID uuid.UUID) ([]*models.UserRoleAssignment, error) {
+	query := `
+		SELECT ur.user_id, ur.assigned_at, ur.assigned_by, ur.expires_at, ur.elevation_request_id,
+		       r.id, r.tenant_id, r.name, r.description, r.is_system, r.created_at, r.updated_at, r.deleted_at, r.owner_id
+		FROM user_roles ur
+		INNER JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1 AND r.deleted_at IS NULL
+		  AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+		ORDER BY r.name
+	`
+
+	return r.queryAssignments(ctx, query, userID)
+}
+
+// DeleteExpiredRoleAssignments removes assignments that expired before the given time and returns them
+func (r *roleRepository) DeleteExpiredRoleAssignments(ctx context.Context, before time.Time) ([]*models.UserRoleAssignment, error) {
+	query := `
+		DELETE FROM user_roles ur
+		USING roles r
+		WHERE r.id = ur.role_id AND ur.expires_at IS NOT NULL AND ur.expires_at <= $1
+		RETURNING ur.user_id, ur.assigned_at, ur.assigned_by, ur.expires_at, ur.elevation_request_id,
+		          r.id, r.tenant_id, r.name, r.description, r.is_system, r.created_at, r.updated_at, r.deleted_at, r.owner_id
+	`
+
+	return r.queryAssignments(ctx, query, before)
+}
+
+// queryAssignments runs a query returning assignment columns followed by full role rows
+func (r *roleRepository) queryAssignments(ctx context.Context, query string, args ...interface{}) ([]*models.UserRoleAssignment, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*models.UserRoleAssignment
+	for rows.Next() {
+		assignment := &models.UserRoleAssignment{Role: &models.Role{}}
+		role := assignment.Role
+		var assignedBy, elevationRequestID uuid.NullUUID
+		var expiresAt, deletedAt sql.NullTime
+		var ownerID uuid.NullUUID
+		var description sql.NullString
+
+		err := rows.Scan(
+			&assignment.UserID, &assignment.AssignedAt, &assignedBy, &expiresAt, &elevationRequestID,
+			&role.ID, &role.TenantID, &role.Name, &description,
+			&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt, &ownerID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role assignment: %w", err)
+		}
+
+		if assignedBy.Valid {
+			assignment.AssignedBy = &assignedBy.UUID
+		}
+		if expiresAt.Valid {
+			assignment.ExpiresAt = &expiresAt.Time
+		}
+		if elevationRequestID.Valid {
+			assignment.ElevationRequestID = &elevationRequestID.UUID
+		}
+		if description.Valid {
+			role.Description = &description.String
+		}
+		if deletedAt.Valid {
+			role.DeletedAt = &deletedAt.Time
+		}
+		if ownerID.Valid {
+			role.OwnerID = &ownerID.UUID
+		}
+
+		assignments = append(assignments, assignment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// GetParentRoles retrieves the roles a role directly inherits from
+func (r *roleRepository) GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	query := `
+		SELECT r.id, r.tenant_id, r.name, r.description, r.is_system, r.created_at, r.updated_at, r.deleted_at, r.owner_id
+		FROM roles r
+		INNER JOIN role_parents rp ON r.id = rp.parent_role_id
+		WHERE rp.role_id = $1 AND r.deleted_at IS NULL
+		ORDER BY r.name
+	`
+
+	return r.queryRoles(ctx, query, roleID)
+}
+
+// GetChildRoles retrieves the roles that directly inherit from a role
+func (r *roleRepository) GetChildRoles(ctx context.Context, roleID uuid.UUID) ([]*models.Role, error) {
+	query := `
+		SELECT r.id, r.tenant_id, r.name, r.description, r.is_system, r.created_at, r.updated_at, r.deleted_at, r.owner_id
+		FROM roles r
+		INNER JOIN role_parents rp ON r.id = rp.role_id
+		WHERE rp.parent_role_id = $1 AND r.deleted_at IS NULL
+		ORDER BY r.name
+	`
+
+	return r.queryRoles(ctx, query, roleID)
+}
+
+// AddParentRole makes a role inherit from a parent role
+func (r *roleRepository) AddParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	query := `
+		INSERT INTO role_parents (id, role_id, parent_role_id, created_at)
+		VALUES (gen_random_uuid(), $1, $2, NOW())
+		ON CONFLICT (role_id, parent_role_id) DO NOTHING
+	`
+
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, roleID, parentRoleID)
+	if err != nil {
+		return fmt.Errorf("failed to add parent role: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveParentRole removes an inheritance link between two roles
+func (r *roleRepository) RemoveParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
+	query := `DELETE FROM role_parents WHERE role_id = $1 AND parent_role_id = $2`
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, roleID, parentRoleID)
+	if err != nil {
+		return fmt.Errorf("failed to remove parent role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("parent role link not found")
+	}
+
+	return nil
+}
+
+// queryRoles runs a query returning full role rows
+func (r *roleRepository) queryRoles(ctx context.Context, query string, args ...interface{}) ([]*models.Role, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		role := &models.Role{}
+		var description sql.NullString
+		var deletedAt sql.NullTime
+		var ownerID uuid.NullUUID
+
+		err := rows.Scan(
+			&role.ID, &role.TenantID, &role.Name, &description,
+			&role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &deletedAt, &ownerID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+
+		if description.Valid {
+			role.Description = &description.String
+		}
+		if deletedAt.Valid {
+			role.DeletedAt = &deletedAt.Time
+		}
+		if ownerID.Valid {
+			role.OwnerID = &ownerID.UUID
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roles: %w", err)
+	}
+
+	return roles, nil
+}
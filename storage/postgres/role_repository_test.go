@@ -170,3 +170,44 @@ func TestRoleRepository_List(t *testing.T) {
 	assert.GreaterOrEqual(t, len(roles), 3)
 }
 
+
+func TestRoleRepository_ParentRoles(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRoleRepository(db)
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	// Create test tenant first
+	err := createTestTenant(ctx, db, tenantID)
+	require.NoError(t, err)
+
+	viewer := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "Viewer"}
+	editor := &models.Role{ID: uuid.New(), TenantID: tenantID, Name: "Editor"}
+	require.NoError(t, repo.Create(ctx, viewer))
+	require.NoError(t, repo.Create(ctx, editor))
+
+	require.NoError(t, repo.AddParentRole(ctx, editor.ID, viewer.ID))
+	// Adding the same link twice is a no-op
+	require.NoError(t, repo.AddParentRole(ctx, editor.ID, viewer.ID))
+
+	parents, err := repo.GetParentRoles(ctx, editor.ID)
+	require.NoError(t, err)
+	require.Len(t, parents, 1)
+	assert.Equal(t, viewer.ID, parents[0].ID)
+
+	children, err := repo.GetChildRoles(ctx, viewer.ID)
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, editor.ID, children[0].ID)
+
+	// Deleting the parent removes the link
+	require.NoError(t, repo.Delete(ctx, viewer.ID))
+	parents, err = repo.GetParentRoles(ctx, editor.ID)
+	require.NoError(t, err)
+	assert.Empty(t, parents)
+
+	err = repo.RemoveParentRole(ctx, editor.ID, viewer.ID)
+	assert.Error(t, err)
+}
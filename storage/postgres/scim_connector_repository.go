@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// scimConnectorRepository implements SCIMConnectorRepository for PostgreSQL
+type scimConnectorRepository struct {
+	db *sql.DB
+}
+
+// NewSCIMConnectorRepository creates a new PostgreSQL outbound SCIM connector repository
+func NewSCIMConnectorRepository(db *sql.DB) interfaces.SCIMConnectorRepository {
+	return &scimConnectorRepository{db: db}
+}
+
+const scimConnectorColumns = `id, tenant_id, name, base_url, auth_type, token_url, client_id, scopes, credential_encrypted,
+	user_mapping, sync_groups, deprovision_action, enabled, last_sync_at, last_reconciled_at, last_error,
+	created_by, created_at, updated_at`
+
+// Create creates a new connector
+func (r *scimConnectorRepository) Create(ctx context.Context, connector *models.SCIMConnector) error {
+	query := `
+		INSERT INTO scim_connectors (id, tenant_id, name, base_url, auth_type, token_url, client_id, scopes,
+			credential_encrypted, user_mapping, sync_groups, deprovision_action, enabled, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	scopesJSON, mappingJSON, err := encodeSCIMConnector(connector)
+	if err != nil {
+		return err
+	}
+
+	if connector.ID == uuid.Nil {
+		connector.ID = uuid.New()
+	}
+	now := time.Now()
+	connector.CreatedAt = now
+	connector.UpdatedAt = now
+
+	_, err = querier(ctx, r.db).ExecContext(ctx, query,
+		connector.ID, connector.TenantID, connector.Name, connector.BaseURL, connector.AuthType,
+		connector.TokenURL, connector.ClientID, scopesJSON, connector.CredentialEncrypted, mappingJSON,
+		connector.SyncGroups, connector.DeprovisionAction, connector.Enabled, connector.CreatedBy,
+		connector.CreatedAt, connector.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create SCIM connector: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a connector by ID
+func (r *scimConnectorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMConnector, error) {
+	query := `SELECT ` + scimConnectorColumns + ` FROM scim_connectors WHERE id = $1`
+
+	connector, err := scanSCIMConnector(querier(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("SCIM connector not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SCIM connector: %w", err)
+	}
+
+	return connector, nil
+}
+
+// ListByTenant retrieves a tenant's connectors
+func (r *scimConnectorRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMConnector, error) {
+	query := `SELECT ` + scimConnectorColumns + ` FROM scim_connectors WHERE tenant_id = $1 ORDER BY name`
+	return r.list(ctx, query, tenantID)
+}
+
+// ListEnabled retrieves the enabled connectors of all tenants
+func (r *scimConnectorRepository) ListEnabled(ctx context.Context) ([]*models.SCIMConnector, error) {
+	query := `SELECT ` + scimConnectorColumns + ` FROM scim_connectors WHERE enabled ORDER BY created_at`
+	return r.list(ctx, query)
+}
+
+func (r *scimConnectorRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.SCIMConnector, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SCIM connectors: %w", err)
+	}
+	defer rows.Close()
+
+	var connectors []*models.SCIMConnector
+	for rows.Next() {
+		connector, err := scanSCIMConnector(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan SCIM connector: %w", err)
+		}
+		connectors = append(connectors, connector)
+	}
+
+	return connectors, rows.Err()
+}
+
+// Update updates an existing connector's settings
+func (r *scimConnectorRepository) Update(ctx context.Context, connector *models.SCIMConnector) error {
+	query := `
+		UPDATE scim_connectors
+		SET name = $2, base_url = $3, auth_type = $4, token_url = $5, client_id = $6, scopes = $7,
+			credential_encrypted = $8, user_mapping = $9, sync_groups = $10, deprovision_action = $11,
+			enabled = $12, updated_at = $13
+		WHERE id = $1
+	`
+
+	scopesJSON, mappingJSON, err := encodeSCIMConnector(connector)
+	if err != nil {
+		return err
+	}
+	connector.UpdatedAt = time.Now()
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		connector.ID, connector.Name, connector.BaseURL, connector.AuthType, connector.TokenURL,
+		connector.ClientID, scopesJSON, connector.CredentialEncrypted, mappingJSON, connector.SyncGroups,
+		connector.DeprovisionAction, connector.Enabled, connector.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update SCIM connector: %w", err)
+	}
+
+	return requireSCIMConnectorRow(result)
+}
+
+// UpdateSyncState records the outcome of the latest sync and reconciliation
+func (r *scimConnectorRepository) UpdateSyncState(ctx context.Context, connector *models.SCIMConnector) error {
+	query := `
+		UPDATE scim_connectors
+		SET last_sync_at = $2, last_reconciled_at = $3, last_error = $4
+		WHERE id = $1
+	`
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		connector.ID, connector.LastSyncAt, connector.LastReconciledAt, connector.LastError,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update SCIM connector sync state: %w", err)
+	}
+
+	return requireSCIMConnectorRow(result)
+}
+
+// Delete deletes a connector; its queue and remote resource links cascade
+func (r *scimConnectorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := querier(ctx, r.db).ExecContext(ctx, `DELETE FROM scim_connectors WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete SCIM connector: %w", err)
+	}
+
+	return requireSCIMConnectorRow(result)
+}
+
+func requireSCIMConnectorRow(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("SCIM connector not found")
+	}
+	return nil
+}
+
+func encodeSCIMConnector(connector *models.SCIMConnector) ([]byte, []byte, error) {
+	scopes := connector.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	scopesJSON, err := json.Marshal(scopes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode connector scopes: %w", err)
+	}
+	mapping := connector.UserMapping
+	if mapping == nil {
+		mapping = map[string]string{}
+	}
+	mappingJSON, err := json.Marshal(mapping)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode connector user mapping: %w", err)
+	}
+	return scopesJSON, mappingJSON, nil
+}
+
+func scanSCIMConnector(row rowScanner) (*models.SCIMConnector, error) {
+	connector := &models.SCIMConnector{}
+	var scopesJSON, mappingJSON []byte
+	var lastSyncAt, lastReconciledAt sql.NullTime
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&connector.ID, &connector.TenantID, &connector.Name, &connector.BaseURL, &connector.AuthType,
+		&connector.TokenURL, &connector.ClientID, &scopesJSON, &connector.CredentialEncrypted, &mappingJSON,
+		&connector.SyncGroups, &connector.DeprovisionAction, &connector.Enabled, &lastSyncAt,
+		&lastReconciledAt, &connector.LastError, &createdBy, &connector.CreatedAt, &connector.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(scopesJSON, &connector.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode connector scopes: %w", err)
+	}
+	if err := json.Unmarshal(mappingJSON, &connector.UserMapping); err != nil {
+		return nil, fmt.Errorf("failed to decode connector user mapping: %w", err)
+	}
+	if lastSyncAt.Valid {
+		connector.LastSyncAt = &lastSyncAt.Time
+	}
+	if lastReconciledAt.Valid {
+		connector.LastReconciledAt = &lastReconciledAt.Time
+	}
+	if createdBy.Valid {
+		connector.CreatedBy = &createdBy.UUID
+	}
+
+	return connector, nil
+}
@@ -26,9 +26,9 @@ func NewSCIMTokenRepository(db *sql.DB) interfaces.SCIMTokenRepository {
 func (r *SCIMTokenRepository) Create(ctx context.Context, token *models.SCIMToken) error {
 	query := `
 		INSERT INTO scim_tokens (
-			id, tenant_id, name, token_hash, lookup_hash, scopes, expires_at,
+			id, tenant_id, name, token_hash, lookup_hash, scopes, allowed_ips, expires_at,
 			created_by, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	now := time.Now()
@@ -42,13 +42,14 @@ func (r *SCIMTokenRepository) Create(ctx context.Context, token *models.SCIMToke
 		token.UpdatedAt = now
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		token.ID,
 		token.TenantID,
 		token.Name,
 		token.TokenHash,
 		token.LookupHash,
 		pq.Array(token.Scopes),
+		pq.Array(allowedIPs(token.AllowedIPs)),
 		token.ExpiresAt,
 		token.CreatedBy,
 		token.CreatedAt,
@@ -65,24 +66,25 @@ func (r *SCIMTokenRepository) Create(ctx context.Context, token *models.SCIMToke
 // GetByID retrieves a SCIM token by ID
 func (r *SCIMTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMToken, error) {
 	query := `
-		SELECT id, tenant_id, name, token_hash, lookup_hash, scopes, expires_at,
+		SELECT id, tenant_id, name, token_hash, lookup_hash, scopes, allowed_ips, expires_at,
 		       last_used_at, created_by, created_at, updated_at, deleted_at
 		FROM scim_tokens
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	token := &models.SCIMToken{}
-	var scopes pq.StringArray
+	var scopes, allowed pq.StringArray
 	var expiresAt, lastUsedAt, deletedAt sql.NullTime
 	var createdBy sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&token.ID,
 		&token.TenantID,
 		&token.Name,
 		&token.TokenHash,
 		&token.LookupHash,
 		&scopes,
+		&allowed,
 		&expiresAt,
 		&lastUsedAt,
 		&createdBy,
@@ -99,6 +101,7 @@ func (r *SCIMTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 	}
 
 	token.Scopes = []string(scopes)
+	token.AllowedIPs = []string(allowed)
 	if expiresAt.Valid {
 		token.ExpiresAt = &expiresAt.Time
 	}
@@ -120,24 +123,25 @@ func (r *SCIMTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 // GetByLookupHash retrieves a SCIM token by its lookup hash (SHA256)
 func (r *SCIMTokenRepository) GetByLookupHash(ctx context.Context, lookupHash string) (*models.SCIMToken, error) {
 	query := `
-		SELECT id, tenant_id, name, token_hash, lookup_hash, scopes, expires_at,
+		SELECT id, tenant_id, name, token_hash, lookup_hash, scopes, allowed_ips, expires_at,
 		       last_used_at, created_by, created_at, updated_at, deleted_at
 		FROM scim_tokens
 		WHERE lookup_hash = $1 AND deleted_at IS NULL
 	`
 
 	token := &models.SCIMToken{}
-	var scopes pq.StringArray
+	var scopes, allowed pq.StringArray
 	var expiresAt, lastUsedAt, deletedAt sql.NullTime
 	var createdBy sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, lookupHash).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, lookupHash).Scan(
 		&token.ID,
 		&token.TenantID,
 		&token.Name,
 		&token.TokenHash,
 		&token.LookupHash,
 		&scopes,
+		&allowed,
 		&expiresAt,
 		&lastUsedAt,
 		&createdBy,
@@ -154,6 +158,7 @@ func (r *SCIMTokenRepository) GetByLookupHash(ctx context.Context, lookupHash st
 	}
 
 	token.Scopes = []string(scopes)
+	token.AllowedIPs = []string(allowed)
 	if expiresAt.Valid {
 		token.ExpiresAt = &expiresAt.Time
 	}
@@ -175,14 +180,14 @@ func (r *SCIMTokenRepository) GetByLookupHash(ctx context.Context, lookupHash st
 // List lists SCIM tokens for a tenant
 func (r *SCIMTokenRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMToken, error) {
 	query := `
-		SELECT id, tenant_id, name, token_hash, lookup_hash, scopes, expires_at,
+		SELECT id, tenant_id, name, token_hash, lookup_hash, scopes, allowed_ips, expires_at,
 		       last_used_at, created_by, created_at, updated_at, deleted_at
 		FROM scim_tokens
 		WHERE tenant_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query SCIM tokens: %w", err)
 	}
@@ -191,7 +196,7 @@ func (r *SCIMTokenRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*
 	var tokens []*models.SCIMToken
 	for rows.Next() {
 		token := &models.SCIMToken{}
-		var scopes pq.StringArray
+		var scopes, allowed pq.StringArray
 		var expiresAt, lastUsedAt, deletedAt sql.NullTime
 		var createdBy sql.NullString
 
@@ -202,6 +207,7 @@ func (r *SCIMTokenRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*
 			&token.TokenHash,
 			&token.LookupHash,
 			&scopes,
+			&allowed,
 			&expiresAt,
 			&lastUsedAt,
 			&createdBy,
@@ -215,6 +221,8 @@ func (r *SCIMTokenRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*
 		}
 
 		token.Scopes = []string(scopes)
+		token.AllowedIPs = []string(allowed)
+	token.AllowedIPs = []string(allowed)
 		if expiresAt.Valid {
 			token.ExpiresAt = &expiresAt.Time
 		}
@@ -240,16 +248,20 @@ func (r *SCIMTokenRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*
 func (r *SCIMTokenRepository) Update(ctx context.Context, token *models.SCIMToken) error {
 	query := `
 		UPDATE scim_tokens
-		SET name = $1, scopes = $2, expires_at = $3, updated_at = $4
-		WHERE id = $5 AND deleted_at IS NULL
+		SET name = $1, scopes = $2, allowed_ips = $3, expires_at = $4,
+		    token_hash = $5, lookup_hash = $6, updated_at = $7
+		WHERE id = $8 AND deleted_at IS NULL
 	`
 
 	token.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
 		token.Name,
 		pq.Array(token.Scopes),
+		pq.Array(allowedIPs(token.AllowedIPs)),
 		token.ExpiresAt,
+		token.TokenHash,
+		token.LookupHash,
 		token.UpdatedAt,
 		token.ID,
 	)
@@ -278,7 +290,7 @@ func (r *SCIMTokenRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete SCIM token: %w", err)
 	}
@@ -303,7 +315,7 @@ func (r *SCIMTokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID)
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to update last used timestamp: %w", err)
 	}
@@ -311,3 +323,10 @@ func (r *SCIMTokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID)
 	return nil
 }
 
+// allowedIPs stores a missing allowlist as an empty array
+func allowedIPs(ips []string) []string {
+	if ips == nil {
+		return []string{}
+	}
+	return ips
+}
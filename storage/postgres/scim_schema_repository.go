@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// scimSchemaRepository implements SCIMSchemaRepository for PostgreSQL
+type scimSchemaRepository struct {
+	db *sql.DB
+}
+
+// NewSCIMSchemaRepository creates a new PostgreSQL SCIM extension schema repository
+func NewSCIMSchemaRepository(db *sql.DB) interfaces.SCIMSchemaRepository {
+	return &scimSchemaRepository{db: db}
+}
+
+const scimSchemaColumns = `id, tenant_id, schema_urn, name, description, required, attributes, created_by, created_at, updated_at`
+
+// Create creates a new extension schema
+func (r *scimSchemaRepository) Create(ctx context.Context, extension *models.SCIMSchemaExtension) error {
+	query := `
+		INSERT INTO scim_schema_extensions (id, tenant_id, schema_urn, name, description, required, attributes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	attributesJSON, err := json.Marshal(extension.Attributes)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema attributes: %w", err)
+	}
+
+	if extension.ID == uuid.Nil {
+		extension.ID = uuid.New()
+	}
+	now := time.Now()
+	extension.CreatedAt = now
+	extension.UpdatedAt = now
+
+	_, err = querier(ctx, r.db).ExecContext(ctx, query,
+		extension.ID, extension.TenantID, extension.SchemaURN, extension.Name, extension.Description,
+		extension.Required, attributesJSON, extension.CreatedBy, extension.CreatedAt, extension.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create SCIM extension schema: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an extension schema by ID
+func (r *scimSchemaRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMSchemaExtension, error) {
+	query := `SELECT ` + scimSchemaColumns + ` FROM scim_schema_extensions WHERE id = $1`
+
+	extension, err := scanSCIMSchemaExtension(querier(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("SCIM extension schema not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SCIM extension schema: %w", err)
+	}
+
+	return extension, nil
+}
+
+// ListByTenant retrieves a tenant's extension schemas
+func (r *scimSchemaRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SCIMSchemaExtension, error) {
+	query := `SELECT ` + scimSchemaColumns + ` FROM scim_schema_extensions WHERE tenant_id = $1 ORDER BY schema_urn`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SCIM extension schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var extensions []*models.SCIMSchemaExtension
+	for rows.Next() {
+		extension, err := scanSCIMSchemaExtension(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan SCIM extension schema: %w", err)
+		}
+		extensions = append(extensions, extension)
+	}
+
+	return extensions, rows.Err()
+}
+
+// Update updates an existing extension schema. The schema URN cannot change.
+func (r *scimSchemaRepository) Update(ctx context.Context, extension *models.SCIMSchemaExtension) error {
+	query := `
+		UPDATE scim_schema_extensions
+		SET name = $2, description = $3, required = $4, attributes = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	attributesJSON, err := json.Marshal(extension.Attributes)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema attributes: %w", err)
+	}
+	extension.UpdatedAt = time.Now()
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		extension.ID, extension.Name, extension.Description, extension.Required, attributesJSON, extension.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update SCIM extension schema: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("SCIM extension schema not found")
+	}
+
+	return nil
+}
+
+// Delete deletes an extension schema. Values stored in user metadata are kept.
+func (r *scimSchemaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := querier(ctx, r.db).ExecContext(ctx, `DELETE FROM scim_schema_extensions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete SCIM extension schema: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("SCIM extension schema not found")
+	}
+
+	return nil
+}
+
+func scanSCIMSchemaExtension(row rowScanner) (*models.SCIMSchemaExtension, error) {
+	extension := &models.SCIMSchemaExtension{}
+	var attributesJSON []byte
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&extension.ID, &extension.TenantID, &extension.SchemaURN, &extension.Name, &extension.Description,
+		&extension.Required, &attributesJSON, &createdBy, &extension.CreatedAt, &extension.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(attributesJSON, &extension.Attributes); err != nil {
+		return nil, fmt.Errorf("failed to decode schema attributes: %w", err)
+	}
+	if createdBy.Valid {
+		extension.CreatedBy = &createdBy.UUID
+	}
+
+	return extension, nil
+}
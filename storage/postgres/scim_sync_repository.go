@@ -0,0 +1,329 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+)
+
+// scimSyncRepository implements SCIMSyncRepository for PostgreSQL
+type scimSyncRepository struct {
+	db *sql.DB
+}
+
+// NewSCIMSyncRepository creates a new PostgreSQL outbound SCIM queue repository
+func NewSCIMSyncRepository(db *sql.DB) interfaces.SCIMSyncRepository {
+	return &scimSyncRepository{db: db}
+}
+
+const scimSyncOperationColumns = `id, connector_id, tenant_id, resource_type, resource_id, action, status, attempts,
+	next_attempt_at, last_error, created_at, updated_at, completed_at`
+
+// Enqueue adds an operation to a connector's queue, replacing a pending
+// operation on the same resource. A replaced operation keeps its attempt
+// count but becomes due immediately.
+func (r *scimSyncRepository) Enqueue(ctx context.Context, op *models.SCIMSyncOperation) error {
+	query := `
+		INSERT INTO scim_sync_operations (id, connector_id, tenant_id, resource_type, resource_id, action, status,
+			attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending', 0, $7, $7, $7)
+		ON CONFLICT (connector_id, resource_type, resource_id) WHERE status = 'pending'
+		DO UPDATE SET action = EXCLUDED.action, next_attempt_at = EXCLUDED.next_attempt_at, updated_at = EXCLUDED.updated_at
+		RETURNING ` + scimSyncOperationColumns
+
+	if op.ID == uuid.Nil {
+		op.ID = uuid.New()
+	}
+	now := time.Now()
+
+	queued, err := scanSCIMSyncOperation(querier(ctx, r.db).QueryRowContext(ctx, query,
+		op.ID, op.ConnectorID, op.TenantID, op.ResourceType, op.ResourceID, op.Action, now,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to enqueue SCIM sync operation: %w", err)
+	}
+	*op = *queued
+
+	return nil
+}
+
+// ClaimDue returns pending operations that are due and defers them by lease.
+// Rows locked by another worker are skipped.
+func (r *scimSyncRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.SCIMSyncOperation, error) {
+	query := `
+		UPDATE scim_sync_operations
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM scim_sync_operations
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scimSyncOperationColumns
+
+	return r.query(ctx, query, now, now.Add(lease), limit)
+}
+
+// Update records the outcome of an attempt. Enqueue bumps updated_at, so an
+// operation re-enqueued while it was being processed is left pending.
+func (r *scimSyncRepository) Update(ctx context.Context, op *models.SCIMSyncOperation) error {
+	query := `
+		UPDATE scim_sync_operations
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, updated_at = $6, completed_at = $7
+		WHERE id = $1 AND updated_at = $8
+	`
+
+	claimedAt := op.UpdatedAt
+	op.UpdatedAt = time.Now()
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		op.ID, op.Status, op.Attempts, op.NextAttemptAt, op.LastError, op.UpdatedAt, op.CompletedAt, claimedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update SCIM sync operation: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves a connector's operations, most recent first
+func (r *scimSyncRepository) List(ctx context.Context, connectorID uuid.UUID, filters *interfaces.SCIMSyncOperationFilters) ([]*models.SCIMSyncOperation, error) {
+	query := `SELECT ` + scimSyncOperationColumns + ` FROM scim_sync_operations WHERE connector_id = $1`
+	args := []interface{}{connectorID}
+
+	limit := 100
+	if filters != nil {
+		if filters.Status != nil {
+			args = append(args, *filters.Status)
+			query += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+		if filters.Limit > 0 {
+			limit = filters.Limit
+		}
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY updated_at DESC LIMIT $%d", len(args))
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *scimSyncRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.SCIMSyncOperation, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SCIM sync operations: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []*models.SCIMSyncOperation
+	for rows.Next() {
+		op, err := scanSCIMSyncOperation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan SCIM sync operation: %w", err)
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, rows.Err()
+}
+
+// CountByStatus counts a connector's operations by status
+func (r *scimSyncRepository) CountByStatus(ctx context.Context, connectorID uuid.UUID) (map[string]int, error) {
+	query := `
+		SELECT CASE WHEN status = 'pending' AND attempts > 0 THEN 'retrying' ELSE status END, COUNT(*)
+		FROM scim_sync_operations
+		WHERE connector_id = $1
+		GROUP BY 1
+	`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, connectorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count SCIM sync operations: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan SCIM sync operation count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// RetryFailed moves a connector's failed operations back to pending. A failed
+// operation whose resource already has a pending one is dropped instead.
+func (r *scimSyncRepository) RetryFailed(ctx context.Context, connectorID uuid.UUID) (int, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM scim_sync_operations f
+		WHERE f.connector_id = $1 AND f.status = 'failed'
+		  AND EXISTS (
+			SELECT 1 FROM scim_sync_operations p
+			WHERE p.connector_id = f.connector_id AND p.resource_type = f.resource_type
+			  AND p.resource_id = f.resource_id AND p.status = 'pending'
+		  )
+	`, connectorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to drop superseded SCIM sync operations: %w", err)
+	}
+
+	// Keep only the latest failed operation per resource
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM scim_sync_operations f
+		WHERE f.connector_id = $1 AND f.status = 'failed'
+		  AND EXISTS (
+			SELECT 1 FROM scim_sync_operations n
+			WHERE n.connector_id = f.connector_id AND n.resource_type = f.resource_type
+			  AND n.resource_id = f.resource_id AND n.status = 'failed'
+			  AND (n.updated_at, n.id) > (f.updated_at, f.id)
+		  )
+	`, connectorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to drop superseded SCIM sync operations: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE scim_sync_operations
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW(), completed_at = NULL
+		WHERE connector_id = $1 AND status = 'failed'
+	`, connectorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retry SCIM sync operations: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(count), nil
+}
+
+// DeleteCompletedBefore removes succeeded operations completed before the given time
+func (r *scimSyncRepository) DeleteCompletedBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := querier(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM scim_sync_operations WHERE status = 'succeeded' AND completed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete completed SCIM sync operations: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(count), nil
+}
+
+// GetRemote retrieves the remote resource linked to a local resource
+func (r *scimSyncRepository) GetRemote(ctx context.Context, connectorID uuid.UUID, resourceType string, localID uuid.UUID) (*models.SCIMRemoteResource, error) {
+	query := `
+		SELECT connector_id, resource_type, local_id, remote_id, synced_at
+		FROM scim_remote_resources
+		WHERE connector_id = $1 AND resource_type = $2 AND local_id = $3
+	`
+
+	remote := &models.SCIMRemoteResource{}
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, connectorID, resourceType, localID).Scan(
+		&remote.ConnectorID, &remote.ResourceType, &remote.LocalID, &remote.RemoteID, &remote.SyncedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("remote resource not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote resource: %w", err)
+	}
+
+	return remote, nil
+}
+
+// ListRemote retrieves all remote resources of a type linked by a connector
+func (r *scimSyncRepository) ListRemote(ctx context.Context, connectorID uuid.UUID, resourceType string) ([]*models.SCIMRemoteResource, error) {
+	query := `
+		SELECT connector_id, resource_type, local_id, remote_id, synced_at
+		FROM scim_remote_resources
+		WHERE connector_id = $1 AND resource_type = $2
+	`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, connectorID, resourceType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote resources: %w", err)
+	}
+	defer rows.Close()
+
+	var remotes []*models.SCIMRemoteResource
+	for rows.Next() {
+		remote := &models.SCIMRemoteResource{}
+		if err := rows.Scan(&remote.ConnectorID, &remote.ResourceType, &remote.LocalID, &remote.RemoteID, &remote.SyncedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan remote resource: %w", err)
+		}
+		remotes = append(remotes, remote)
+	}
+
+	return remotes, rows.Err()
+}
+
+// SaveRemote creates or replaces the link of a local resource
+func (r *scimSyncRepository) SaveRemote(ctx context.Context, remote *models.SCIMRemoteResource) error {
+	query := `
+		INSERT INTO scim_remote_resources (connector_id, resource_type, local_id, remote_id, synced_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (connector_id, resource_type, local_id)
+		DO UPDATE SET remote_id = EXCLUDED.remote_id, synced_at = EXCLUDED.synced_at
+	`
+
+	remote.SyncedAt = time.Now()
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		remote.ConnectorID, remote.ResourceType, remote.LocalID, remote.RemoteID, remote.SyncedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save remote resource: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRemote removes the link of a local resource
+func (r *scimSyncRepository) DeleteRemote(ctx context.Context, connectorID uuid.UUID, resourceType string, localID uuid.UUID) error {
+	_, err := querier(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM scim_remote_resources WHERE connector_id = $1 AND resource_type = $2 AND local_id = $3`,
+		connectorID, resourceType, localID)
+	if err != nil {
+		return fmt.Errorf("failed to delete remote resource: %w", err)
+	}
+	return nil
+}
+
+func scanSCIMSyncOperation(row rowScanner) (*models.SCIMSyncOperation, error) {
+	op := &models.SCIMSyncOperation{}
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&op.ID, &op.ConnectorID, &op.TenantID, &op.ResourceType, &op.ResourceID, &op.Action, &op.Status,
+		&op.Attempts, &op.NextAttemptAt, &op.LastError, &op.CreatedAt, &op.UpdatedAt, &completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		op.CompletedAt = &completedAt.Time
+	}
+
+	return op, nil
+}
@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/arauth-identity/iam/identity/models"
+	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// sodRuleRepository implements SoDRuleRepository for PostgreSQL
+type sodRuleRepository struct {
+	db *sql.DB
+}
+
+// NewSoDRuleRepository creates a new PostgreSQL separation-of-duties rule repository
+func NewSoDRuleRepository(db *sql.DB) interfaces.SoDRuleRepository {
+	return &sodRuleRepository{db: db}
+}
+
+const sodRuleColumns = `id, tenant_id, name, description, role_ids, max_roles, created_by, created_at, updated_at`
+
+// Create creates a new rule
+func (r *sodRuleRepository) Create(ctx context.Context, rule *models.SoDRule) error {
+	query := `
+		INSERT INTO sod_rules (id, tenant_id, name, description, role_ids, max_roles, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
+		rule.ID, rule.TenantID, rule.Name, rule.Description, pq.Array(uuidStrings(rule.RoleIDs)),
+		rule.MaxRoles, rule.CreatedBy, rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create separation of duties rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a rule by ID
+func (r *sodRuleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SoDRule, error) {
+	query := `SELECT ` + sodRuleColumns + ` FROM sod_rules WHERE id = $1`
+
+	rule, err := scanSoDRule(querier(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("separation of duties rule not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get separation of duties rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// ListByTenant retrieves a tenant's rules
+func (r *sodRuleRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*models.SoDRule, error) {
+	query := `SELECT ` + sodRuleColumns + ` FROM sod_rules WHERE tenant_id = $1 ORDER BY name`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list separation of duties rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.SoDRule
+	for rows.Next() {
+		rule, err := scanSoDRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan separation of duties rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// Update updates an existing rule
+func (r *sodRuleRepository) Update(ctx context.Context, rule *models.SoDRule) error {
+	query := `
+		UPDATE sod_rules
+		SET name = $2, description = $3, role_ids = $4, max_roles = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	rule.UpdatedAt = time.Now()
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		rule.ID, rule.Name, rule.Description, pq.Array(uuidStrings(rule.RoleIDs)), rule.MaxRoles, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update separation of duties rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("separation of duties rule not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a rule
+func (r *sodRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := querier(ctx, r.db).ExecContext(ctx, `DELETE FROM sod_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete separation of duties rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("separation of duties rule not found")
+	}
+
+	return nil
+}
+
+// LockTenant takes a transaction-level advisory lock on the tenant's rules
+func (r *sodRuleRepository) LockTenant(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := querier(ctx, r.db).ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended('sod:' || $1::text, 0))`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to lock separation of duties rules: %w", err)
+	}
+	return nil
+}
+
+// ListRoleHolders retrieves the users of a tenant holding any of the given roles.
+// Group membership is followed through nested groups up to the same depth as
+// group.MaxNestingDepth, and role inheritance up to role.MaxHierarchyDepth.
+func (r *sodRuleRepository) ListRoleHolders(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) ([]*interfaces.SoDRoleHolder, error) {
+	query := `
+		WITH RECURSIVE memberships(user_id, group_id, depth) AS (
+			SELECT gm.user_id, gm.group_id, 0
+			FROM group_members gm
+			INNER JOIN groups g ON g.id = gm.group_id
+			WHERE g.tenant_id = $1 AND g.deleted_at IS NULL
+			UNION
+			SELECT m.user_id, gmg.group_id, m.depth + 1
+			FROM memberships m
+			INNER JOIN group_member_groups gmg ON gmg.member_group_id = m.group_id
+			INNER JOIN groups g ON g.id = gmg.group_id
+			WHERE g.deleted_at IS NULL AND m.depth < 10
+		),
+		holdings(user_id, role_id, depth) AS (
+			SELECT ur.user_id, ur.role_id, 0
+			FROM user_roles ur
+			INNER JOIN roles r ON r.id = ur.role_id
+			WHERE r.tenant_id = $1 AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+			UNION
+			SELECT m.user_id, gr.role_id, 0
+			FROM memberships m
+			INNER JOIN group_roles gr ON gr.group_id = m.group_id
+			UNION
+			SELECT h.user_id, rp.parent_role_id, h.depth + 1
+			FROM holdings h
+			INNER JOIN role_parents rp ON rp.role_id = h.role_id
+			INNER JOIN roles r ON r.id = rp.parent_role_id
+			WHERE r.tenant_id = $1 AND r.deleted_at IS NULL AND h.depth < 10
+		)
+		SELECT DISTINCT u.id, u.username, h.role_id
+		FROM holdings h
+		INNER JOIN users u ON u.id = h.user_id
+		INNER JOIN roles r ON r.id = h.role_id
+		WHERE h.role_id = ANY($2::uuid[])
+		  AND u.tenant_id = $1 AND u.deleted_at IS NULL
+		  AND r.tenant_id = $1 AND r.deleted_at IS NULL
+		ORDER BY u.username
+	`
+
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID, pq.Array(uuidStrings(roleIDs)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role holders: %w", err)
+	}
+	defer rows.Close()
+
+	var holders []*interfaces.SoDRoleHolder
+	for rows.Next() {
+		holder := &interfaces.SoDRoleHolder{}
+		if err := rows.Scan(&holder.UserID, &holder.Username, &holder.RoleID); err != nil {
+			return nil, fmt.Errorf("failed to scan role holder: %w", err)
+		}
+		holders = append(holders, holder)
+	}
+
+	return holders, rows.Err()
+}
+
+func scanSoDRule(row rowScanner) (*models.SoDRule, error) {
+	rule := &models.SoDRule{}
+	var roleIDs pq.StringArray
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&rule.ID, &rule.TenantID, &rule.Name, &rule.Description, &roleIDs,
+		&rule.MaxRoles, &createdBy, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.RoleIDs = parseUUIDs(roleIDs)
+	if createdBy.Valid {
+		rule.CreatedBy = &createdBy.UUID
+	}
+
+	return rule, nil
+}
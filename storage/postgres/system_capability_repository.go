@@ -35,7 +35,7 @@ func (r *systemCapabilityRepository) GetByKey(ctx context.Context, key string) (
 	var description sql.NullString
 	var updatedBy sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, key).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, key).Scan(
 		&capability.CapabilityKey,
 		&capability.Enabled,
 		&defaultValue,
@@ -75,7 +75,7 @@ func (r *systemCapabilityRepository) GetAll(ctx context.Context) ([]*models.Syst
 		ORDER BY capability_key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get system capabilities: %w", err)
 	}
@@ -132,7 +132,7 @@ func (r *systemCapabilityRepository) GetEnabled(ctx context.Context) ([]*models.
 		ORDER BY capability_key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get enabled system capabilities: %w", err)
 	}
@@ -192,7 +192,7 @@ func (r *systemCapabilityRepository) Create(ctx context.Context, capability *mod
 		defaultValueJSON = string(capability.DefaultValue)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		capability.CapabilityKey,
 		capability.Enabled,
 		defaultValueJSON,
@@ -221,7 +221,7 @@ func (r *systemCapabilityRepository) Update(ctx context.Context, capability *mod
 		defaultValueJSON = string(capability.DefaultValue)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		capability.CapabilityKey,
 		capability.Enabled,
 		defaultValueJSON,
@@ -241,7 +241,7 @@ func (r *systemCapabilityRepository) Update(ctx context.Context, capability *mod
 func (r *systemCapabilityRepository) Delete(ctx context.Context, key string) error {
 	query := `DELETE FROM system_capabilities WHERE capability_key = $1`
 
-	_, err := r.db.ExecContext(ctx, query, key)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, key)
 	if err != nil {
 		return fmt.Errorf("failed to delete system capability: %w", err)
 	}
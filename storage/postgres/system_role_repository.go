@@ -26,7 +26,7 @@ func (r *SystemRoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*inte
 	          WHERE id = $1`
 	
 	var role interfaces.SystemRole
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&role.ID,
 		&role.Name,
 		&role.Description,
@@ -49,7 +49,7 @@ func (r *SystemRoleRepository) GetByName(ctx context.Context, name string) (*int
 	          WHERE name = $1`
 	
 	var role interfaces.SystemRole
-	err := r.db.QueryRowContext(ctx, query, name).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, name).Scan(
 		&role.ID,
 		&role.Name,
 		&role.Description,
@@ -71,7 +71,7 @@ func (r *SystemRoleRepository) List(ctx context.Context) ([]*interfaces.SystemRo
 	          FROM system_roles 
 	          ORDER BY name`
 	
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list system roles: %w", err)
 	}
@@ -107,7 +107,7 @@ func (r *SystemRoleRepository) GetUserSystemRoles(ctx context.Context, userID uu
 	          WHERE usr.user_id = $1
 	          ORDER BY sr.name`
 	
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user system roles: %w", err)
 	}
@@ -141,7 +141,7 @@ func (r *SystemRoleRepository) AssignRoleToUser(ctx context.Context, userID, rol
 	          VALUES ($1, $2, $3, NOW())
 	          ON CONFLICT (user_id, role_id) DO NOTHING`
 	
-	_, err := r.db.ExecContext(ctx, query, userID, roleID, assignedBy)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, userID, roleID, assignedBy)
 	if err != nil {
 		return fmt.Errorf("failed to assign system role to user: %w", err)
 	}
@@ -153,7 +153,7 @@ func (r *SystemRoleRepository) RemoveRoleFromUser(ctx context.Context, userID, r
 	query := `DELETE FROM user_system_roles 
 	          WHERE user_id = $1 AND role_id = $2`
 	
-	_, err := r.db.ExecContext(ctx, query, userID, roleID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, userID, roleID)
 	if err != nil {
 		return fmt.Errorf("failed to remove system role from user: %w", err)
 	}
@@ -168,7 +168,7 @@ func (r *SystemRoleRepository) GetRolePermissions(ctx context.Context, roleID uu
 	          WHERE srp.role_id = $1
 	          ORDER BY sp.resource, sp.action`
 	
-	rows, err := r.db.QueryContext(ctx, query, roleID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, roleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get system role permissions: %w", err)
 	}
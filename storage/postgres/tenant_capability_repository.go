@@ -34,7 +34,7 @@ func (r *tenantCapabilityRepository) GetByTenantIDAndKey(ctx context.Context, te
 	var value sql.NullString
 	var configuredBy sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, key).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, key).Scan(
 		&capability.TenantID,
 		&capability.CapabilityKey,
 		&capability.Enabled,
@@ -72,7 +72,7 @@ func (r *tenantCapabilityRepository) GetByTenantID(ctx context.Context, tenantID
 		ORDER BY capability_key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant capabilities: %w", err)
 	}
@@ -125,7 +125,7 @@ func (r *tenantCapabilityRepository) GetEnabledByTenantID(ctx context.Context, t
 		ORDER BY capability_key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get enabled tenant capabilities: %w", err)
 	}
@@ -181,7 +181,7 @@ func (r *tenantCapabilityRepository) Create(ctx context.Context, capability *mod
 		valueJSON = string(capability.Value)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		capability.TenantID,
 		capability.CapabilityKey,
 		capability.Enabled,
@@ -210,7 +210,7 @@ func (r *tenantCapabilityRepository) Update(ctx context.Context, capability *mod
 		valueJSON = string(capability.Value)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		capability.TenantID,
 		capability.CapabilityKey,
 		capability.Enabled,
@@ -230,7 +230,7 @@ func (r *tenantCapabilityRepository) Update(ctx context.Context, capability *mod
 func (r *tenantCapabilityRepository) Delete(ctx context.Context, tenantID uuid.UUID, key string) error {
 	query := `DELETE FROM tenant_capabilities WHERE tenant_id = $1 AND capability_key = $2`
 
-	_, err := r.db.ExecContext(ctx, query, tenantID, key)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, tenantID, key)
 	if err != nil {
 		return fmt.Errorf("failed to delete tenant capability: %w", err)
 	}
@@ -242,7 +242,7 @@ func (r *tenantCapabilityRepository) Delete(ctx context.Context, tenantID uuid.U
 func (r *tenantCapabilityRepository) DeleteByTenantID(ctx context.Context, tenantID uuid.UUID) error {
 	query := `DELETE FROM tenant_capabilities WHERE tenant_id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, tenantID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to delete tenant capabilities: %w", err)
 	}
@@ -34,7 +34,7 @@ func (r *tenantFeatureEnablementRepository) GetByTenantIDAndKey(ctx context.Cont
 	var configuration sql.NullString
 	var enabledBy sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, key).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, key).Scan(
 		&enablement.TenantID,
 		&enablement.FeatureKey,
 		&enablement.Enabled,
@@ -72,7 +72,7 @@ func (r *tenantFeatureEnablementRepository) GetByTenantID(ctx context.Context, t
 		ORDER BY feature_key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant feature enablements: %w", err)
 	}
@@ -125,7 +125,7 @@ func (r *tenantFeatureEnablementRepository) GetEnabledByTenantID(ctx context.Con
 		ORDER BY feature_key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get enabled tenant feature enablements: %w", err)
 	}
@@ -181,7 +181,7 @@ func (r *tenantFeatureEnablementRepository) Create(ctx context.Context, enableme
 		configJSON = string(enablement.Configuration)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		enablement.TenantID,
 		enablement.FeatureKey,
 		enablement.Enabled,
@@ -210,7 +210,7 @@ func (r *tenantFeatureEnablementRepository) Update(ctx context.Context, enableme
 		configJSON = string(enablement.Configuration)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		enablement.TenantID,
 		enablement.FeatureKey,
 		enablement.Enabled,
@@ -230,7 +230,7 @@ func (r *tenantFeatureEnablementRepository) Update(ctx context.Context, enableme
 func (r *tenantFeatureEnablementRepository) Delete(ctx context.Context, tenantID uuid.UUID, key string) error {
 	query := `DELETE FROM tenant_feature_enablement WHERE tenant_id = $1 AND feature_key = $2`
 
-	_, err := r.db.ExecContext(ctx, query, tenantID, key)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, tenantID, key)
 	if err != nil {
 		return fmt.Errorf("failed to delete tenant feature enablement: %w", err)
 	}
@@ -242,7 +242,7 @@ func (r *tenantFeatureEnablementRepository) Delete(ctx context.Context, tenantID
 func (r *tenantFeatureEnablementRepository) DeleteByTenantID(ctx context.Context, tenantID uuid.UUID) error {
 	query := `DELETE FROM tenant_feature_enablement WHERE tenant_id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, tenantID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to delete tenant feature enablements: %w", err)
 	}
@@ -50,7 +50,7 @@ func (r *tenantRepository) Create(ctx context.Context, tenant *models.Tenant) er
 		metadataJSON = nil
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		tenant.ID, tenant.Name, tenant.Domain, tenant.Status,
 		metadataJSON, tenant.CreatedAt, tenant.UpdatedAt,
 	)
@@ -74,7 +74,7 @@ func (r *tenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.T
 	var metadataJSON []byte
 	var deletedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&tenant.ID, &tenant.Name, &tenant.Domain, &tenant.Status,
 		&metadataJSON, &tenant.CreatedAt, &tenant.UpdatedAt, &deletedAt,
 	)
@@ -108,7 +108,7 @@ func (r *tenantRepository) GetByDomain(ctx context.Context, domain string) (*mod
 	var metadataJSON []byte
 	var deletedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, domain).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, domain).Scan(
 		&tenant.ID, &tenant.Name, &tenant.Domain, &tenant.Status,
 		&metadataJSON, &tenant.CreatedAt, &tenant.UpdatedAt, &deletedAt,
 	)
@@ -147,7 +147,7 @@ func (r *tenantRepository) Update(ctx context.Context, tenant *models.Tenant) er
 		metadataJSON = nil
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		tenant.ID, tenant.Name, tenant.Domain, tenant.Status,
 		metadataJSON, tenant.UpdatedAt,
 	)
@@ -167,7 +167,7 @@ func (r *tenantRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete tenant: %w", err)
 	}
@@ -226,7 +226,7 @@ func (r *tenantRepository) List(ctx context.Context, filters *interfaces.TenantF
 	query += " ORDER BY created_at DESC LIMIT $" + fmt.Sprintf("%d", argPos) + " OFFSET $" + fmt.Sprintf("%d", argPos+1)
 	args = append(args, filters.PageSize, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tenants: %w", err)
 	}
@@ -69,8 +69,8 @@ func (r *cachedTenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 		return nil, err
 	}
 
-	// Store in cache
-	if tenant != nil {
+	// Store in cache, unless the read may see changes that are later rolled back
+	if tenant != nil && !inTx(ctx) {
 		_ = r.cache.Set(ctx, cacheKey, tenant, r.cacheTTL) // Ignore cache errors
 		// Also cache by domain
 		if tenant.Domain != "" {
@@ -98,8 +98,8 @@ func (r *cachedTenantRepository) GetByDomain(ctx context.Context, domain string)
 		return nil, err
 	}
 
-	// Store in cache
-	if tenant != nil {
+	// Store in cache, unless the read may see changes that are later rolled back
+	if tenant != nil && !inTx(ctx) {
 		_ = r.cache.Set(ctx, cacheKey, tenant, r.cacheTTL) // Ignore cache errors
 		// Also cache by ID
 		_ = r.cache.Set(ctx, r.cacheKey("id", tenant.ID.String()), tenant, r.cacheTTL) // Ignore cache errors
@@ -164,5 +164,13 @@ func (r *cachedTenantRepository) invalidateTenantCache(ctx context.Context, tena
 	for _, key := range keys {
 		_ = r.cache.Delete(ctx, key) // Ignore cache errors
 	}
+	// A concurrent read may cache the old tenant before the change commits
+	if inTx(ctx) {
+		afterCommit(ctx, func() {
+			for _, key := range keys {
+				_ = r.cache.Delete(ctx, key) // Ignore cache errors
+			}
+		})
+	}
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/arauth-identity/iam/storage/interfaces"
+	"github.com/lib/pq"
 )
 
 // tenantSettingsRepository implements TenantSettingsRepository for PostgreSQL
@@ -28,14 +29,15 @@ func (r *tenantSettingsRepository) GetByTenantID(ctx context.Context, tenantID u
 		       remember_me_access_token_ttl_minutes, token_rotation_enabled,
 		       require_mfa_for_extended_sessions, min_password_length, require_uppercase,
 		       require_lowercase, require_numbers, require_special_chars, password_expiry_days,
-		       mfa_required, rate_limit_requests, rate_limit_window_seconds
+		       mfa_required, rate_limit_requests, rate_limit_window_seconds,
+		       self_service_profile_fields
 		FROM tenant_settings
 		WHERE tenant_id = $1
 	`
 
 	settings := &interfaces.TenantSettings{}
 	var passwordExpiryDays sql.NullInt64
-	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID).Scan(
 		&settings.ID, &settings.TenantID, &settings.AccessTokenTTLMinutes,
 		&settings.RefreshTokenTTLDays, &settings.IDTokenTTLMinutes,
 		&settings.RememberMeEnabled, &settings.RememberMeRefreshTokenTTLDays,
@@ -44,6 +46,7 @@ func (r *tenantSettingsRepository) GetByTenantID(ctx context.Context, tenantID u
 		&settings.RequireUppercase, &settings.RequireLowercase, &settings.RequireNumbers,
 		&settings.RequireSpecialChars, &passwordExpiryDays, &settings.MFARequired,
 		&settings.RateLimitRequests, &settings.RateLimitWindowSeconds,
+		pq.Array(&settings.SelfServiceProfileFields),
 	)
 	
 	if err == nil && passwordExpiryDays.Valid {
@@ -70,8 +73,9 @@ func (r *tenantSettingsRepository) Create(ctx context.Context, settings *interfa
 			remember_me_access_token_ttl_minutes, token_rotation_enabled,
 			require_mfa_for_extended_sessions, min_password_length, require_uppercase,
 			require_lowercase, require_numbers, require_special_chars, password_expiry_days,
-			mfa_required, rate_limit_requests, rate_limit_window_seconds, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+			mfa_required, rate_limit_requests, rate_limit_window_seconds, self_service_profile_fields,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 	`
 
 	now := time.Now()
@@ -89,8 +93,11 @@ func (r *tenantSettingsRepository) Create(ctx context.Context, settings *interfa
 	if settings.RateLimitWindowSeconds == 0 {
 		settings.RateLimitWindowSeconds = 60
 	}
+	if settings.SelfServiceProfileFields == nil {
+		settings.SelfServiceProfileFields = []string{"first_name", "last_name"}
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		settings.ID, settings.TenantID, settings.AccessTokenTTLMinutes,
 		settings.RefreshTokenTTLDays, settings.IDTokenTTLMinutes,
 		settings.RememberMeEnabled, settings.RememberMeRefreshTokenTTLDays,
@@ -98,7 +105,8 @@ func (r *tenantSettingsRepository) Create(ctx context.Context, settings *interfa
 		settings.RequireMFAForExtendedSessions, settings.MinPasswordLength,
 		settings.RequireUppercase, settings.RequireLowercase, settings.RequireNumbers,
 		settings.RequireSpecialChars, settings.PasswordExpiryDays, settings.MFARequired,
-		settings.RateLimitRequests, settings.RateLimitWindowSeconds, now, now,
+		settings.RateLimitRequests, settings.RateLimitWindowSeconds,
+		pq.Array(settings.SelfServiceProfileFields), now, now,
 	)
 
 	if err != nil {
@@ -119,11 +127,11 @@ func (r *tenantSettingsRepository) Update(ctx context.Context, settings *interfa
 		    min_password_length = $10, require_uppercase = $11, require_lowercase = $12,
 		    require_numbers = $13, require_special_chars = $14, password_expiry_days = $15,
 		    mfa_required = $16, rate_limit_requests = $17, rate_limit_window_seconds = $18,
-		    updated_at = $19
+		    self_service_profile_fields = COALESCE($19, self_service_profile_fields), updated_at = $20
 		WHERE tenant_id = $1
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		settings.TenantID, settings.AccessTokenTTLMinutes, settings.RefreshTokenTTLDays,
 		settings.IDTokenTTLMinutes, settings.RememberMeEnabled,
 		settings.RememberMeRefreshTokenTTLDays, settings.RememberMeAccessTokenTTLMinutes,
@@ -131,7 +139,7 @@ func (r *tenantSettingsRepository) Update(ctx context.Context, settings *interfa
 		settings.MinPasswordLength, settings.RequireUppercase, settings.RequireLowercase,
 		settings.RequireNumbers, settings.RequireSpecialChars, settings.PasswordExpiryDays,
 		settings.MFARequired, settings.RateLimitRequests, settings.RateLimitWindowSeconds,
-		time.Now(),
+		pq.Array(settings.SelfServiceProfileFields), time.Now(),
 	)
 
 	if err != nil {
@@ -145,7 +153,7 @@ func (r *tenantSettingsRepository) Update(ctx context.Context, settings *interfa
 func (r *tenantSettingsRepository) Delete(ctx context.Context, tenantID uuid.UUID) error {
 	query := `DELETE FROM tenant_settings WHERE tenant_id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, tenantID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to delete tenant settings: %w", err)
 	}
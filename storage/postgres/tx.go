@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// dbtx is what repositories query through: the database, or the
+// transaction carried by the request context
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txContextKey carries a sharedTx in a context
+type txContextKey struct{}
+
+// sharedTx is a transaction joined by every repository call made with its
+// context
+type sharedTx struct {
+	tx *sql.Tx
+
+	mu           sync.Mutex
+	savepoints   int
+	beforeCommit []func(ctx context.Context) error
+	afterCommit  []func()
+}
+
+// TxManager runs work in a transaction that repositories join through the
+// context, so changes made by several repositories commit or roll back
+// together
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager creates a new transaction manager
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx runs fn with a context carrying a new transaction and commits it
+// if fn returns nil. Repository calls made with that context run in the
+// transaction, and transactions they start become savepoints in it. Within
+// a context that already carries a transaction, fn runs in a savepoint that
+// is rolled back if fn fails.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*sharedTx); ok {
+		nested, err := beginTx(ctx, m.db)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer nested.Rollback()
+
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		if err := nested.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	shared := &sharedTx{tx: tx}
+	txCtx := context.WithValue(ctx, txContextKey{}, shared)
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+	// Hooks may add hooks of their own
+	for i := 0; i < len(shared.beforeCommit); i++ {
+		if err := shared.beforeCommit[i](txCtx); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, f := range shared.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// querier returns the context's transaction, or db when there is none
+func querier(ctx context.Context, db *sql.DB) dbtx {
+	if shared, ok := ctx.Value(txContextKey{}).(*sharedTx); ok {
+		return shared.tx
+	}
+	return db
+}
+
+// inTx reports whether ctx carries a transaction whose changes may still
+// be rolled back
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txContextKey{}).(*sharedTx)
+	return ok
+}
+
+// beforeCommit runs f in the context's transaction just before it commits,
+// and reports whether there is such a transaction. An error from f rolls
+// the transaction back. f is dropped if the transaction, or the savepoint
+// it was added in, rolls back.
+func beforeCommit(ctx context.Context, f func(ctx context.Context) error) bool {
+	shared, ok := ctx.Value(txContextKey{}).(*sharedTx)
+	if !ok {
+		return false
+	}
+	shared.mu.Lock()
+	shared.beforeCommit = append(shared.beforeCommit, f)
+	shared.mu.Unlock()
+	return true
+}
+
+// afterCommit runs f once the context's transaction commits, or right away
+// when there is none. f is dropped if the transaction, or the savepoint it
+// was added in, rolls back.
+func afterCommit(ctx context.Context, f func()) {
+	shared, ok := ctx.Value(txContextKey{}).(*sharedTx)
+	if !ok {
+		f()
+		return
+	}
+	shared.mu.Lock()
+	shared.afterCommit = append(shared.afterCommit, f)
+	shared.mu.Unlock()
+}
+
+// txn is a repository's own transaction. Within a shared transaction it is
+// a savepoint, so a failed repository call undoes only its own changes.
+type txn struct {
+	dbtx
+	commit   func() error
+	rollback func() error
+	done     bool
+}
+
+// Commit commits the transaction or releases the savepoint
+func (t *txn) Commit() error {
+	if err := t.commit(); err != nil {
+		return err
+	}
+	t.done = true
+	return nil
+}
+
+// Rollback rolls the transaction back, or back to the savepoint. It does
+// nothing after a successful Commit.
+func (t *txn) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	return t.rollback()
+}
+
+// beginTx starts a repository transaction, as a savepoint when ctx carries
+// a shared transaction
+func beginTx(ctx context.Context, db *sql.DB) (*txn, error) {
+	shared, ok := ctx.Value(txContextKey{}).(*sharedTx)
+	if !ok {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &txn{dbtx: tx, commit: tx.Commit, rollback: tx.Rollback}, nil
+	}
+
+	shared.mu.Lock()
+	shared.savepoints++
+	name := fmt.Sprintf("repository_%d", shared.savepoints)
+	// Hooks added after the savepoint go when it is rolled back
+	before, after := len(shared.beforeCommit), len(shared.afterCommit)
+	shared.mu.Unlock()
+
+	if _, err := shared.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &txn{
+		dbtx: shared.tx,
+		commit: func() error {
+			_, err := shared.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+			return err
+		},
+		rollback: func() error {
+			shared.mu.Lock()
+			shared.beforeCommit = shared.beforeCommit[:min(before, len(shared.beforeCommit))]
+			shared.afterCommit = shared.afterCommit[:min(after, len(shared.afterCommit))]
+			shared.mu.Unlock()
+			_, err := shared.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			return err
+		},
+	}, nil
+}
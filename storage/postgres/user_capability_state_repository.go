@@ -35,7 +35,7 @@ func (r *userCapabilityStateRepository) GetByUserIDAndKey(ctx context.Context, u
 	var enrolledAt sql.NullTime
 	var lastUsedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, userID, key).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, userID, key).Scan(
 		&state.UserID,
 		&state.CapabilityKey,
 		&state.Enrolled,
@@ -73,7 +73,7 @@ func (r *userCapabilityStateRepository) GetByUserID(ctx context.Context, userID
 		ORDER BY capability_key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user capability states: %w", err)
 	}
@@ -127,7 +127,7 @@ func (r *userCapabilityStateRepository) GetEnrolledByUserID(ctx context.Context,
 		ORDER BY capability_key
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get enrolled user capability states: %w", err)
 	}
@@ -189,7 +189,7 @@ func (r *userCapabilityStateRepository) Create(ctx context.Context, state *model
 		state.EnrolledAt = &now
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		state.UserID,
 		state.CapabilityKey,
 		state.Enrolled,
@@ -223,7 +223,7 @@ func (r *userCapabilityStateRepository) Update(ctx context.Context, state *model
 		state.EnrolledAt = &now
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		state.UserID,
 		state.CapabilityKey,
 		state.Enrolled,
@@ -243,7 +243,7 @@ func (r *userCapabilityStateRepository) Update(ctx context.Context, state *model
 func (r *userCapabilityStateRepository) Delete(ctx context.Context, userID uuid.UUID, key string) error {
 	query := `DELETE FROM user_capability_state WHERE user_id = $1 AND capability_key = $2`
 
-	_, err := r.db.ExecContext(ctx, query, userID, key)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, userID, key)
 	if err != nil {
 		return fmt.Errorf("failed to delete user capability state: %w", err)
 	}
@@ -255,7 +255,7 @@ func (r *userCapabilityStateRepository) Delete(ctx context.Context, userID uuid.
 func (r *userCapabilityStateRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
 	query := `DELETE FROM user_capability_state WHERE user_id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, userID)
+	_, err := querier(ctx, r.db).ExecContext(ctx, query, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete user capability states: %w", err)
 	}
@@ -57,7 +57,7 @@ func (r *userRepository) Create(ctx context.Context, u *models.User) error {
 		metadataJSON = nil
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		u.ID, u.TenantID, u.PrincipalType, u.Username, u.Email, u.FirstName, u.LastName,
 		u.Status, u.MFAEnabled, u.MFASecretEncrypted,
 		metadataJSON, // metadata as JSONB
@@ -88,7 +88,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	var principalType string
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&u.ID, &tenantID, &principalType, &u.Username, &u.Email,
 		&firstName, &lastName, &u.Status, &u.MFAEnabled,
 		&mfaSecret, &lastLoginAt, &metadataJSON, &u.CreatedAt,
@@ -133,6 +133,22 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	return u, nil
 }
 
+// LockByID locks a user row until the context's transaction ends. It takes
+// the lock an UPDATE takes, so rows referencing the user can still be inserted.
+func (r *userRepository) LockByID(ctx context.Context, id uuid.UUID) error {
+	query := `SELECT id FROM users WHERE id = $1 AND deleted_at IS NULL FOR NO KEY UPDATE`
+
+	var locked uuid.UUID
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user not found: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	return nil
+}
+
 // GetByUsername retrieves a user by username and tenant ID
 func (r *userRepository) GetByUsername(ctx context.Context, username string, tenantID uuid.UUID) (*models.User, error) {
 	query := `
@@ -150,7 +166,7 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string, ten
 	var principalType string
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, username).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, username).Scan(
 		&u.ID, &tenantIDStr, &principalType, &u.Username, &u.Email,
 		&firstName, &lastName, &u.Status, &u.MFAEnabled,
 		&mfaSecret, &lastLoginAt, &metadataJSON, &u.CreatedAt,
@@ -211,7 +227,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string, tenantID
 	var principalType string
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, email).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, tenantID, email).Scan(
 		&u.ID, &tenantIDStr, &principalType, &u.Username, &u.Email,
 		&firstName, &lastName, &u.Status, &u.MFAEnabled,
 		&mfaSecret, &lastLoginAt, &metadataJSON, &u.CreatedAt,
@@ -272,7 +288,7 @@ func (r *userRepository) GetByEmailSystem(ctx context.Context, email string) (*m
 	var principalType string
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, email).Scan(
 		&u.ID, &tenantIDStr, &principalType, &u.Username, &u.Email,
 		&firstName, &lastName, &u.Status, &u.MFAEnabled,
 		&mfaSecret, &lastLoginAt, &metadataJSON, &u.CreatedAt,
@@ -333,7 +349,7 @@ func (r *userRepository) GetSystemUserByUsername(ctx context.Context, username s
 	var principalType string
 	var metadataJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, username).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, username).Scan(
 		&u.ID, &tenantIDStr, &principalType, &u.Username, &u.Email,
 		&firstName, &lastName, &u.Status, &u.MFAEnabled,
 		&mfaSecret, &lastLoginAt, &metadataJSON, &u.CreatedAt,
@@ -396,7 +412,7 @@ func (r *userRepository) Update(ctx context.Context, u *models.User) error {
 		metadataJSON = nil
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		u.ID, u.Username, u.Email, u.FirstName, u.LastName,
 		u.Status, u.MFAEnabled, u.MFASecretEncrypted, u.LastLoginAt,
 		metadataJSON, // metadata as JSONB
@@ -410,6 +426,21 @@ func (r *userRepository) Update(ctx context.Context, u *models.User) error {
 	return nil
 }
 
+// UpdateLastLogin records a successful login without touching other user fields
+func (r *userRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	query := `
+		UPDATE users
+		SET last_login_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	if _, err := querier(ctx, r.db).ExecContext(ctx, query, id, loginAt); err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
+	}
+
+	return nil
+}
+
 // Delete soft deletes a user
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -418,7 +449,7 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -435,6 +466,19 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// userFilterColumns maps the logical user filter fields onto columns
+var userFilterColumns = map[string]filterColumn{
+	interfaces.UserFieldID:          {expr: "id::text", kind: columnText, sortable: true},
+	interfaces.UserFieldUsername:    {expr: "username", kind: columnTextFold, sortable: true},
+	interfaces.UserFieldEmail:       {expr: "email", kind: columnTextFold, sortable: true},
+	interfaces.UserFieldFirstName:   {expr: "first_name", kind: columnTextFold, sortable: true},
+	interfaces.UserFieldLastName:    {expr: "last_name", kind: columnTextFold, sortable: true},
+	interfaces.UserFieldDisplayName: {expr: "TRIM(COALESCE(first_name, '') || ' ' || COALESCE(last_name, ''))", kind: columnTextFold, sortable: true},
+	interfaces.UserFieldStatus:      {expr: "status", kind: columnText},
+	interfaces.UserFieldCreatedAt:   {expr: "created_at", kind: columnTime, sortable: true},
+	interfaces.UserFieldUpdatedAt:   {expr: "updated_at", kind: columnTime, sortable: true},
+}
+
 // List retrieves a list of users with filters
 func (r *userRepository) List(ctx context.Context, tenantID uuid.UUID, filters *interfaces.UserFilters) ([]*models.User, error) {
 	if filters == nil {
@@ -477,10 +521,25 @@ func (r *userRepository) List(ctx context.Context, tenantID uuid.UUID, filters *
 		argPos += 4
 	}
 
-	query += " ORDER BY created_at DESC LIMIT $" + fmt.Sprintf("%d", argPos) + " OFFSET $" + fmt.Sprintf("%d", argPos+1)
+	filterClause, args, err := buildFilterClause(filters.Filter, userFilterColumns, args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user filter: %w", err)
+	}
+	query += filterClause
+	argPos = len(args) + 1
+
+	orderBy, err := buildOrderBy(filters.SortBy, filters.SortDescending, userFilterColumns, "created_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("invalid user sort: %w", err)
+	}
+	if filters.Offset != nil {
+		offset = *filters.Offset
+	}
+
+	query += " ORDER BY " + orderBy + " LIMIT $" + fmt.Sprintf("%d", argPos) + " OFFSET $" + fmt.Sprintf("%d", argPos+1)
 	args = append(args, filters.PageSize, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -561,10 +620,17 @@ func (r *userRepository) Count(ctx context.Context, tenantID uuid.UUID, filters
 			searchPattern := "%" + *filters.Search + "%"
 			args = append(args, searchPattern, searchPattern, searchPattern, searchPattern)
 		}
+
+		filterClause, filterArgs, err := buildFilterClause(filters.Filter, userFilterColumns, args)
+		if err != nil {
+			return 0, fmt.Errorf("invalid user filter: %w", err)
+		}
+		query += filterClause
+		args = filterArgs
 	}
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -617,7 +683,7 @@ func (r *userRepository) ListSystem(ctx context.Context, filters *interfaces.Use
 	query += " ORDER BY created_at DESC LIMIT $" + fmt.Sprintf("%d", argPos) + " OFFSET $" + fmt.Sprintf("%d", argPos+1)
 	args = append(args, filters.PageSize, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list system users: %w", err)
 	}
@@ -701,7 +767,7 @@ func (r *userRepository) CountSystem(ctx context.Context, filters *interfaces.Us
 	}
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count system users: %w", err)
 	}
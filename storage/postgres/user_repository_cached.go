@@ -73,14 +73,19 @@ func (r *cachedUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*mode
 		return nil, err
 	}
 
-	// Store in cache
-	if user != nil {
+	// Store in cache, unless the read may see changes that are later rolled back
+	if user != nil && !inTx(ctx) {
 		_ = r.cache.Set(ctx, cacheKey, user, r.cacheTTL) // Ignore cache errors
 	}
 
 	return user, nil
 }
 
+// LockByID locks a user; locks are never cached
+func (r *cachedUserRepository) LockByID(ctx context.Context, id uuid.UUID) error {
+	return r.repo.LockByID(ctx, id)
+}
+
 // GetByUsername retrieves a user by username with caching
 func (r *cachedUserRepository) GetByUsername(ctx context.Context, username string, tenantID uuid.UUID) (*models.User, error) {
 	cacheKey := r.cacheKey("username", tenantID.String(), username)
@@ -98,8 +103,8 @@ func (r *cachedUserRepository) GetByUsername(ctx context.Context, username strin
 		return nil, err
 	}
 
-	// Store in cache
-	if user != nil {
+	// Store in cache, unless the read may see changes that are later rolled back
+	if user != nil && !inTx(ctx) {
 		_ = r.cache.Set(ctx, cacheKey, user, r.cacheTTL) // Ignore cache errors
 		// Also cache by ID
 		_ = r.cache.Set(ctx, r.cacheKey("id", user.ID.String()), user, r.cacheTTL) // Ignore cache errors
@@ -125,8 +130,8 @@ func (r *cachedUserRepository) GetByEmail(ctx context.Context, email string, ten
 		return nil, err
 	}
 
-	// Store in cache
-	if user != nil {
+	// Store in cache, unless the read may see changes that are later rolled back
+	if user != nil && !inTx(ctx) {
 		_ = r.cache.Set(ctx, cacheKey, user, r.cacheTTL) // Ignore cache errors
 		// Also cache by ID
 		_ = r.cache.Set(ctx, r.cacheKey("id", user.ID.String()), user, r.cacheTTL) // Ignore cache errors
@@ -162,6 +167,27 @@ func (r *cachedUserRepository) Update(ctx context.Context, user *models.User) er
 	return nil
 }
 
+// UpdateLastLogin records a successful login and invalidates the cached user
+func (r *cachedUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	// Get user to invalidate cache
+	user, _ := r.repo.GetByID(ctx, id)
+
+	if err := r.repo.UpdateLastLogin(ctx, id, loginAt); err != nil {
+		return err
+	}
+
+	// Invalidate cache
+	if user != nil {
+		var tenantID uuid.UUID
+		if user.TenantID != nil {
+			tenantID = *user.TenantID
+		}
+		r.invalidateUserCache(ctx, id, tenantID, user.Username, user.Email)
+	}
+
+	return nil
+}
+
 // Delete soft deletes a user
 func (r *cachedUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Get user to invalidate cache
@@ -232,5 +258,13 @@ func (r *cachedUserRepository) invalidateUserCache(ctx context.Context, userID u
 	for _, key := range keys {
 		_ = r.cache.Delete(ctx, key) // Ignore cache errors
 	}
+	// A concurrent read may cache the old user before the change commits
+	if inTx(ctx) {
+		afterCommit(ctx, func() {
+			for _, key := range keys {
+				_ = r.cache.Delete(ctx, key) // Ignore cache errors
+			}
+		})
+	}
 }
 
@@ -23,14 +23,21 @@ func NewWebhookRepository(db *sql.DB) interfaces.WebhookRepository {
 	return &webhookRepository{db: db}
 }
 
+// webhookColumns is the column list read by scanWebhook
+const webhookColumns = `id, tenant_id, name, url, secret, enabled, events,
+		       signing_algorithm, previous_secret, previous_secret_expires_at,
+		       consecutive_failures, disabled_reason, disabled_at,
+		       created_at, updated_at, deleted_at`
+
 // Create creates a new webhook
 func (r *webhookRepository) Create(ctx context.Context, w *models.Webhook) error {
 	query := `
 		INSERT INTO webhooks (
 			id, tenant_id, name, url, secret, enabled, events,
-			created_at, updated_at
+			disabled_reason, disabled_at, created_at, updated_at,
+			signing_algorithm
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		)
 	`
 
@@ -38,7 +45,7 @@ func (r *webhookRepository) Create(ctx context.Context, w *models.Webhook) error
 	w.CreatedAt = now
 	w.UpdatedAt = now
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := querier(ctx, r.db).ExecContext(ctx, query,
 		w.ID,
 		w.TenantID,
 		w.Name,
@@ -46,8 +53,11 @@ func (r *webhookRepository) Create(ctx context.Context, w *models.Webhook) error
 		w.Secret,
 		w.Enabled,
 		pq.Array(w.Events),
+		w.DisabledReason,
+		w.DisabledAt,
 		w.CreatedAt,
 		w.UpdatedAt,
+		w.SigningAlgorithm,
 	)
 
 	return err
@@ -56,29 +66,12 @@ func (r *webhookRepository) Create(ctx context.Context, w *models.Webhook) error
 // GetByID retrieves a webhook by ID
 func (r *webhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
 	query := `
-		SELECT id, tenant_id, name, url, secret, enabled, events,
-		       created_at, updated_at, deleted_at
+		SELECT ` + webhookColumns + `
 		FROM webhooks
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	var w models.Webhook
-	var events pq.StringArray
-	var deletedAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&w.ID,
-		&w.TenantID,
-		&w.Name,
-		&w.URL,
-		&w.Secret,
-		&w.Enabled,
-		&events,
-		&w.CreatedAt,
-		&w.UpdatedAt,
-		&deletedAt,
-	)
-
+	w, err := scanWebhook(querier(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("webhook not found: %w", err)
 	}
@@ -86,162 +79,51 @@ func (r *webhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 		return nil, fmt.Errorf("failed to get webhook: %w", err)
 	}
 
-	w.Events = []string(events)
-	if deletedAt.Valid {
-		w.DeletedAt = &deletedAt.Time
-	}
-
-	return &w, nil
+	return w, nil
 }
 
 // GetByTenantID retrieves all webhooks for a tenant
 func (r *webhookRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID) ([]*models.Webhook, error) {
 	query := `
-		SELECT id, tenant_id, name, url, secret, enabled, events,
-		       created_at, updated_at, deleted_at
+		SELECT ` + webhookColumns + `
 		FROM webhooks
 		WHERE tenant_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query webhooks: %w", err)
-	}
-	defer rows.Close()
-
-	var webhooks []*models.Webhook
-	for rows.Next() {
-		var w models.Webhook
-		var events pq.StringArray
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&w.ID,
-			&w.TenantID,
-			&w.Name,
-			&w.URL,
-			&w.Secret,
-			&w.Enabled,
-			&events,
-			&w.CreatedAt,
-			&w.UpdatedAt,
-			&deletedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan webhook: %w", err)
-		}
-
-		w.Events = []string(events)
-		if deletedAt.Valid {
-			w.DeletedAt = &deletedAt.Time
-		}
-
-		webhooks = append(webhooks, &w)
-	}
-
-	return webhooks, rows.Err()
+	return r.queryWebhooks(ctx, query, tenantID)
 }
 
 // GetEnabledByTenantID retrieves all enabled webhooks for a tenant
 func (r *webhookRepository) GetEnabledByTenantID(ctx context.Context, tenantID uuid.UUID) ([]*models.Webhook, error) {
 	query := `
-		SELECT id, tenant_id, name, url, secret, enabled, events,
-		       created_at, updated_at, deleted_at
+		SELECT ` + webhookColumns + `
 		FROM webhooks
 		WHERE tenant_id = $1 AND enabled = true AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query webhooks: %w", err)
-	}
-	defer rows.Close()
-
-	var webhooks []*models.Webhook
-	for rows.Next() {
-		var w models.Webhook
-		var events pq.StringArray
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&w.ID,
-			&w.TenantID,
-			&w.Name,
-			&w.URL,
-			&w.Secret,
-			&w.Enabled,
-			&events,
-			&w.CreatedAt,
-			&w.UpdatedAt,
-			&deletedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan webhook: %w", err)
-		}
-
-		w.Events = []string(events)
-		if deletedAt.Valid {
-			w.DeletedAt = &deletedAt.Time
-		}
-
-		webhooks = append(webhooks, &w)
-	}
-
-	return webhooks, rows.Err()
+	return r.queryWebhooks(ctx, query, tenantID)
 }
 
-// GetByEventType retrieves all enabled webhooks subscribed to an event type
+// GetByEventType retrieves all enabled webhooks subscribed to an event type,
+// either directly, through a category wildcard such as user.*, or through *
 func (r *webhookRepository) GetByEventType(ctx context.Context, tenantID uuid.UUID, eventType string) ([]*models.Webhook, error) {
 	query := `
-		SELECT id, tenant_id, name, url, secret, enabled, events,
-		       created_at, updated_at, deleted_at
+		SELECT ` + webhookColumns + `
 		FROM webhooks
-		WHERE tenant_id = $1 
-		  AND enabled = true 
+		WHERE tenant_id = $1
+		  AND enabled = true
 		  AND deleted_at IS NULL
-		  AND $2 = ANY(events)
+		  AND EXISTS (
+		      SELECT 1 FROM unnest(events) AS e
+		      WHERE e = $2 OR e = '*'
+		         OR (e LIKE '%.*' AND starts_with($2, left(e, -1)))
+		  )
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID, eventType)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query webhooks: %w", err)
-	}
-	defer rows.Close()
-
-	var webhooks []*models.Webhook
-	for rows.Next() {
-		var w models.Webhook
-		var events pq.StringArray
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&w.ID,
-			&w.TenantID,
-			&w.Name,
-			&w.URL,
-			&w.Secret,
-			&w.Enabled,
-			&events,
-			&w.CreatedAt,
-			&w.UpdatedAt,
-			&deletedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan webhook: %w", err)
-		}
-
-		w.Events = []string(events)
-		if deletedAt.Valid {
-			w.DeletedAt = &deletedAt.Time
-		}
-
-		webhooks = append(webhooks, &w)
-	}
-
-	return webhooks, rows.Err()
+	return r.queryWebhooks(ctx, query, tenantID, eventType)
 }
 
 // Update updates an existing webhook
@@ -249,20 +131,26 @@ func (r *webhookRepository) Update(ctx context.Context, w *models.Webhook) error
 	query := `
 		UPDATE webhooks
 		SET name = $2, url = $3, secret = $4, enabled = $5, events = $6,
-		    updated_at = $7
+		    consecutive_failures = $7, disabled_reason = $8, disabled_at = $9,
+		    updated_at = $10, previous_secret = $11, previous_secret_expires_at = $12
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	w.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
 		w.ID,
 		w.Name,
 		w.URL,
 		w.Secret,
 		w.Enabled,
 		pq.Array(w.Events),
+		w.ConsecutiveFailures,
+		w.DisabledReason,
+		w.DisabledAt,
 		w.UpdatedAt,
+		w.PreviousSecret,
+		w.PreviousSecretExpiresAt,
 	)
 
 	if err != nil {
@@ -281,6 +169,156 @@ func (r *webhookRepository) Update(ctx context.Context, w *models.Webhook) error
 	return nil
 }
 
+// RecordDeliveryResult resets the failure count after a successful delivery
+// attempt or increments it after a failed one, and returns the new count
+func (r *webhookRepository) RecordDeliveryResult(ctx context.Context, id uuid.UUID, success bool) (int, error) {
+	query := `
+		UPDATE webhooks
+		SET consecutive_failures = CASE WHEN $2 THEN 0 ELSE consecutive_failures + 1 END
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING consecutive_failures
+	`
+
+	var failures int
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id, success).Scan(&failures)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("webhook not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record delivery result: %w", err)
+	}
+
+	return failures, nil
+}
+
+// Disable disables an enabled webhook with the given reason. It reports
+// whether the webhook was enabled, so concurrent callers disable it once.
+func (r *webhookRepository) Disable(ctx context.Context, id uuid.UUID, reason string) (bool, error) {
+	query := `
+		UPDATE webhooks
+		SET enabled = false, disabled_reason = $2, disabled_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND enabled = true AND deleted_at IS NULL
+	`
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to disable webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ClaimDue leases enabled webhooks whose oldest queued delivery is due.
+// Webhooks leased by another worker, or locked by a concurrent claim, are skipped.
+func (r *webhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.Webhook, error) {
+	query := `
+		UPDATE webhooks
+		SET delivery_locked_until = NOW() + make_interval(secs => $2::float8)
+		WHERE id IN (
+			SELECT w.id
+			FROM webhooks w
+			JOIN LATERAL (
+				SELECT COALESCE(d.next_retry_at, d.created_at) AS due_at
+				FROM webhook_deliveries d
+				WHERE d.webhook_id = w.id AND d.status IN ('pending', 'retrying')
+				ORDER BY d.created_at, d.id
+				LIMIT 1
+			) head ON TRUE
+			WHERE w.enabled = true AND w.deleted_at IS NULL
+			  AND (w.delivery_locked_until IS NULL OR w.delivery_locked_until < NOW())
+			  AND head.due_at <= NOW()
+			ORDER BY head.due_at
+			LIMIT $1
+			FOR UPDATE OF w SKIP LOCKED
+		)
+		RETURNING ` + webhookColumns
+
+	return r.queryWebhooks(ctx, query, limit, lease.Seconds())
+}
+
+// Release ends a webhook's delivery lease
+func (r *webhookRepository) Release(ctx context.Context, id uuid.UUID) error {
+	_, err := querier(ctx, r.db).ExecContext(ctx, `UPDATE webhooks SET delivery_locked_until = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to release webhook: %w", err)
+	}
+	return nil
+}
+
+// queryWebhooks runs a query selecting webhookColumns
+func (r *webhookRepository) queryWebhooks(ctx context.Context, query string, args ...interface{}) ([]*models.Webhook, error) {
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*models.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// scanWebhook scans a row selected with webhookColumns
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var w models.Webhook
+	var events pq.StringArray
+	var disabledReason, previousSecret sql.NullString
+	var disabledAt, deletedAt, previousSecretExpiresAt sql.NullTime
+
+	err := row.Scan(
+		&w.ID,
+		&w.TenantID,
+		&w.Name,
+		&w.URL,
+		&w.Secret,
+		&w.Enabled,
+		&events,
+		&w.SigningAlgorithm,
+		&previousSecret,
+		&previousSecretExpiresAt,
+		&w.ConsecutiveFailures,
+		&disabledReason,
+		&disabledAt,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Events = []string(events)
+	if previousSecret.Valid {
+		w.PreviousSecret = &previousSecret.String
+	}
+	if previousSecretExpiresAt.Valid {
+		w.PreviousSecretExpiresAt = &previousSecretExpiresAt.Time
+	}
+	if disabledReason.Valid {
+		w.DisabledReason = &disabledReason.String
+	}
+	if disabledAt.Valid {
+		w.DisabledAt = &disabledAt.Time
+	}
+	if deletedAt.Valid {
+		w.DeletedAt = &deletedAt.Time
+	}
+
+	return &w, nil
+}
+
 // Delete soft deletes a webhook
 func (r *webhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -289,7 +327,7 @@ func (r *webhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete webhook: %w", err)
 	}
@@ -337,7 +375,7 @@ func (r *webhookDeliveryRepository) Create(ctx context.Context, delivery *models
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = querier(ctx, r.db).ExecContext(ctx, query,
 		delivery.ID,
 		delivery.WebhookID,
 		delivery.EventID,
@@ -374,7 +412,7 @@ func (r *webhookDeliveryRepository) GetByID(ctx context.Context, id uuid.UUID) (
 	var nextRetryAt sql.NullTime
 	var deliveredAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := querier(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&delivery.ID,
 		&delivery.WebhookID,
 		&eventID,
@@ -433,7 +471,7 @@ func (r *webhookDeliveryRepository) GetByWebhookID(ctx context.Context, webhookI
 	// Get total count
 	countQuery := `SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = $1`
 	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, webhookID).Scan(&total)
+	err := querier(ctx, r.db).QueryRowContext(ctx, countQuery, webhookID).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count deliveries: %w", err)
 	}
@@ -449,7 +487,7 @@ func (r *webhookDeliveryRepository) GetByWebhookID(ctx context.Context, webhookI
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, webhookID, limit, offset)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, webhookID, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query deliveries: %w", err)
 	}
@@ -467,36 +505,97 @@ func (r *webhookDeliveryRepository) GetByWebhookID(ctx context.Context, webhookI
 	return deliveries, total, rows.Err()
 }
 
-// GetPendingRetries retrieves all deliveries that need to be retried
-func (r *webhookDeliveryRepository) GetPendingRetries(ctx context.Context, before time.Time) ([]*models.WebhookDelivery, error) {
+// Enqueue queues a delivery unless the webhook already has a delivery for
+// the same event, which happens when an outbox event is relayed again
+func (r *webhookDeliveryRepository) Enqueue(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, webhook_id, event_id, event_type, payload, status,
+			attempt_number, next_retry_at, created_at, updated_at
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		WHERE NOT EXISTS (
+			SELECT 1 FROM webhook_deliveries
+			WHERE webhook_id = $2::uuid AND event_id = $3::uuid
+		)
+	`
+
+	now := time.Now()
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+
+	payloadJSON, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query,
+		delivery.ID,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.EventType,
+		payloadJSON,
+		delivery.Status,
+		delivery.AttemptNumber,
+		delivery.NextRetryAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to queue delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// NextQueued retrieves a webhook's oldest pending or retrying delivery
+func (r *webhookDeliveryRepository) NextQueued(ctx context.Context, webhookID uuid.UUID) (*models.WebhookDelivery, error) {
 	query := `
 		SELECT id, webhook_id, event_id, event_type, payload, status,
 		       http_status_code, response_body, attempt_number, next_retry_at,
 		       delivered_at, created_at, updated_at
 		FROM webhook_deliveries
-		WHERE status IN ('failed', 'retrying')
-		  AND next_retry_at IS NOT NULL
-		  AND next_retry_at <= $1
-		ORDER BY next_retry_at ASC
-		LIMIT 100
+		WHERE webhook_id = $1 AND status IN ('pending', 'retrying')
+		ORDER BY created_at, id
+		LIMIT 1
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, before)
+	rows, err := querier(ctx, r.db).QueryContext(ctx, query, webhookID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query pending retries: %w", err)
+		return nil, fmt.Errorf("failed to query queued delivery: %w", err)
 	}
 	defer rows.Close()
 
-	var deliveries []*models.WebhookDelivery
-	for rows.Next() {
-		delivery, err := r.scanDelivery(rows)
-		if err != nil {
-			return nil, err
-		}
-		deliveries = append(deliveries, delivery)
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	return r.scanDelivery(rows)
+}
+
+// AbandonQueued marks a webhook's pending and retrying deliveries as failed
+func (r *webhookDeliveryRepository) AbandonQueued(ctx context.Context, webhookID uuid.UUID) (int, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'failed', next_retry_at = NULL, updated_at = NOW()
+		WHERE webhook_id = $1 AND status IN ('pending', 'retrying')
+	`
+
+	result, err := querier(ctx, r.db).ExecContext(ctx, query, webhookID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to abandon queued deliveries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
-	return deliveries, rows.Err()
+	return int(rowsAffected), nil
 }
 
 // Update updates a webhook delivery record
@@ -519,7 +618,7 @@ func (r *webhookDeliveryRepository) Update(ctx context.Context, delivery *models
 	// Re-marshal payload to ensure it's stored (though we don't update it)
 	_ = payloadJSON
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = querier(ctx, r.db).ExecContext(ctx, query,
 		delivery.ID,
 		delivery.Status,
 		delivery.HTTPStatusCode,